@@ -18,14 +18,20 @@ limitations under the License.
 package main
 
 import (
+	"encoding/json"
 	"flag"
+	"fmt"
 	"io/ioutil"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"golang.org/x/net/context"
 	"vitess.io/vitess/go/vt/binlog"
 	"vitess.io/vitess/go/vt/dbconfigs"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/mysqlctl"
+	querypb "vitess.io/vitess/go/vt/proto/query"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/tableacl"
@@ -44,7 +50,8 @@ var (
 	tableACLConfig               = flag.String("table-acl-config", "", "path to table access checker config file; send SIGHUP to reload this file")
 	tableACLConfigReloadInterval = flag.Duration("table-acl-config-reload-interval", 0, "Ticker to reload ACLs. Duration flag, format e.g.: 30s. Default: do not reload")
 	tabletPath                   = flag.String("tablet-path", "", "tablet alias")
-	tabletConfig                 = flag.String("tablet_config", "", "YAML file config for tablet")
+	tabletConfig                 = flag.String("tablet_config", "", "YAML file config for tablet; POST to /debug/config/reload_file to reload the fields that can be changed at runtime from this file. SIGHUP only reloads -table-acl-config")
+	selfTest                     = flag.Bool("self_test", false, "if set, vttablet opens its query service components against the local mysql as a master would, prints the outcome of each step as JSON on stdout and exits, without registering in the topo or serving queries; it exits with an error if a step fails")
 
 	tm *tabletmanager.TabletManager
 )
@@ -86,6 +93,9 @@ func main() {
 	if err != nil {
 		log.Exitf("failed to parse -tablet-path: %v", err)
 	}
+	if *selfTest {
+		runSelfTest(qsc, tablet, config, mysqld)
+	}
 	tm = &tabletmanager.TabletManager{
 		BatchCtx:            context.Background(),
 		TopoServer:          ts,
@@ -111,14 +121,34 @@ func main() {
 	servenv.RunDefault()
 }
 
+// runSelfTest runs the self test of qsc, prints its report on
+// stdout and exits. The exit status is non-zero if it failed.
+func runSelfTest(qsc *tabletserver.TabletServer, tablet *topodatapb.Tablet, config *tabletenv.TabletConfig, mysqld mysqlctl.MysqlDaemon) {
+	dbcfgs := config.DB.Clone()
+	dbcfgs.DBName = topoproto.TabletDbName(tablet)
+	target := querypb.Target{
+		Keyspace:   tablet.Keyspace,
+		Shard:      tablet.Shard,
+		TabletType: topodatapb.TabletType_MASTER,
+	}
+	if err := qsc.InitDBConfig(target, dbcfgs, mysqld); err != nil {
+		log.Exitf("self test failed: %v", err)
+	}
+	report, err := qsc.SelfTest()
+	if report != nil {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(data))
+	}
+	if err != nil {
+		log.Exit(err)
+	}
+	os.Exit(0)
+}
+
 func initConfig(tabletAlias *topodatapb.TabletAlias) (*tabletenv.TabletConfig, *mysqlctl.Mycnf) {
 	tabletenv.Init()
 	// Load current config after tabletenv.Init, because it changes it.
 	config := tabletenv.NewCurrentConfig()
-	if err := config.Verify(); err != nil {
-		log.Exitf("invalid config: %v", err)
-	}
-
 	if *tabletConfig != "" {
 		bytes, err := ioutil.ReadFile(*tabletConfig)
 		if err != nil {
@@ -128,6 +158,10 @@ func initConfig(tabletAlias *topodatapb.TabletAlias) (*tabletenv.TabletConfig, *
 			log.Exitf("error parsing config file %s: %v", bytes, err)
 		}
 	}
+	// The config file overrides the flags: verify the result.
+	if err := config.Verify(); err != nil {
+		log.Exitf("invalid config: %v", err)
+	}
 	gotBytes, _ := yaml2.Marshal(config)
 	log.Infof("Loaded config file %s successfully:\n%s", *tabletConfig, gotBytes)
 
@@ -170,7 +204,32 @@ func createTabletServer(config *tabletenv.TabletConfig, ts *topo.Server, tabletA
 		qsc.Register()
 		addStatusParts(qsc)
 	})
+	if config.GracePeriods.LameduckOnTermSeconds.Get() != 0 {
+		servenv.OnTermSync(func() { stopServiceOnTerm(qsc) })
+	}
 	servenv.OnClose(qsc.StopService)
 	qsc.InitACL(*tableACLConfig, *enforceTableACLConfig, *tableACLConfigReloadInterval)
+	qsc.SetConfigFile(*tabletConfig)
 	return qsc
 }
+
+// stopServiceOnTerm stops the service after the lameduck of
+// -lameduck_on_term, which a second SIGTERM ends early.
+func stopServiceOnTerm(qsc *tabletserver.TabletServer) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	skip := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-sigChan:
+			log.Info("Received a second SIGTERM, ending the lameduck")
+			close(skip)
+		case <-done:
+		}
+	}()
+	qsc.StopServiceOnTerm(skip)
+}
@@ -89,6 +89,33 @@ func (history *History) Records() []interface{} {
 	return records
 }
 
+// Resize changes the maximum length of the history. If it shrinks,
+// the oldest records are dropped. The length must be at least 1.
+func (history *History) Resize(length int) {
+	history.mu.Lock()
+	defer history.mu.Unlock()
+
+	kept := history.length
+	if kept > length {
+		kept = length
+	}
+	records := make([]interface{}, length)
+	for i := 0; i < kept; i++ {
+		// Copy the kept records from the oldest to the newest.
+		records[i] = history.records[(history.next-kept+i+len(history.records))%len(history.records)]
+	}
+	history.records = records
+	history.length = kept
+	history.next = kept % length
+}
+
+// Cap returns the maximum length of the history.
+func (history *History) Cap() int {
+	history.mu.Lock()
+	defer history.mu.Unlock()
+	return len(history.records)
+}
+
 // Latest returns the record most recently passed to Add(),
 // regardless of whether it was actually added or dropped as a duplicate.
 func (history *History) Latest() interface{} {
@@ -17,6 +17,7 @@ limitations under the License.
 package history
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -107,3 +108,32 @@ type mod10 int
 func (m mod10) IsDuplicate(other interface{}) bool {
 	return m%10 == other.(mod10)%10
 }
+
+func TestResize(t *testing.T) {
+	q := New(4)
+	for i := 0; i < 6; i++ {
+		q.Add(i)
+	}
+
+	q.Resize(2)
+	if got, want := q.Cap(), 2; got != want {
+		t.Errorf("q.Cap() = %v, want %v", got, want)
+	}
+	if got, want := fmt.Sprint(q.Records()), "[5 4]"; got != want {
+		t.Errorf("q.Records() = %v, want %v", got, want)
+	}
+	q.Add(6)
+	if got, want := fmt.Sprint(q.Records()), "[6 5]"; got != want {
+		t.Errorf("q.Records() = %v, want %v", got, want)
+	}
+
+	q.Resize(3)
+	q.Add(7)
+	if got, want := fmt.Sprint(q.Records()), "[7 6 5]"; got != want {
+		t.Errorf("q.Records() = %v, want %v", got, want)
+	}
+	q.Add(8)
+	if got, want := fmt.Sprint(q.Records()), "[8 7 6]"; got != want {
+		t.Errorf("q.Records() = %v, want %v", got, want)
+	}
+}
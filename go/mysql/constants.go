@@ -298,6 +298,7 @@ const (
 	ERNoSuchTable           = 1146
 	ERNonExistingTableGrant = 1147
 	ERKeyDoesNotExist       = 1176
+	ERViewInvalid           = 1356
 
 	// permissions
 	ERDBAccessDenied            = 1044
@@ -35,6 +35,13 @@ const (
 
 	// BaseShowPrimary is the base query for fetching primary key info.
 	BaseShowPrimary = "SELECT table_name, column_name FROM information_schema.key_column_usage WHERE table_schema=database() AND constraint_name='PRIMARY' ORDER BY table_name, ordinal_position"
+
+	// BaseShowPrimaryForTables is the query for fetching the primary key info
+	// of a list of tables. It must be formatted with the quoted table names.
+	BaseShowPrimaryForTables = "SELECT table_name, column_name FROM information_schema.key_column_usage WHERE table_schema=database() AND constraint_name='PRIMARY' AND table_name IN (%s) ORDER BY table_name, ordinal_position"
+
+	// BaseShowViews is the base query for fetching view definitions.
+	BaseShowViews = "SELECT table_name, view_definition FROM information_schema.views WHERE table_schema = database()"
 )
 
 // BaseShowTablesFields contains the fields returned by a BaseShowTables or a BaseShowTablesForTable command.
@@ -114,3 +121,20 @@ func ShowPrimaryRow(tableName, colName string) []sqltypes.Value {
 		sqltypes.MakeTrusted(sqltypes.VarChar, []byte(colName)),
 	}
 }
+
+// ShowViewsFields contains the fields for a BaseShowViews.
+var ShowViewsFields = []*querypb.Field{{
+	Name: "table_name",
+	Type: sqltypes.VarChar,
+}, {
+	Name: "view_definition",
+	Type: sqltypes.Text,
+}}
+
+// ShowViewsRow returns a row for a view definition.
+func ShowViewsRow(viewName, definition string) []sqltypes.Value {
+	return []sqltypes.Value{
+		sqltypes.MakeTrusted(sqltypes.VarChar, []byte(viewName)),
+		sqltypes.MakeTrusted(sqltypes.Text, []byte(definition)),
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package timer
+
+import "time"
+
+// Clock is the source of time of the code that waits for
+// timeouts. It allows tests to control the passage of time.
+// See the fakeclock package for an implementation for tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After waits for the duration to elapse and then sends
+	// the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+	// NewTimer creates a new OneShot that sends the current
+	// time on its channel after at least duration d.
+	NewTimer(d time.Duration) OneShot
+}
+
+// OneShot is a single event timer created by a Clock.
+// It behaves like time.Timer.
+type OneShot interface {
+	// C returns the channel on which the time is delivered.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing. It returns false
+	// if the timer already fired or was stopped.
+	Stop() bool
+}
+
+// RealClock is the Clock that uses the time package.
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+// Now is part of the Clock interface.
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// After is part of the Clock interface.
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// NewTimer is part of the Clock interface.
+func (realClock) NewTimer(d time.Duration) OneShot {
+	return realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+// C is part of the OneShot interface.
+func (rt realTimer) C() <-chan time.Time {
+	return rt.Timer.C
+}
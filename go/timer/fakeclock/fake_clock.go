@@ -0,0 +1,139 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fakeclock provides a timer.Clock for tests, where
+// time only passes when the test advances it.
+package fakeclock
+
+import (
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/timer"
+)
+
+// Clock is a timer.Clock whose time is controlled by the test.
+// The timers it creates fire when Advance moves the time past
+// their deadline.
+//
+// Code under test typically creates its timers in goroutines.
+// Use BlockUntil to wait for them to be created before advancing
+// the time, otherwise they'll only fire on the next Advance.
+type Clock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*fakeTimer
+}
+
+var _ timer.Clock = (*Clock)(nil)
+
+type fakeTimer struct {
+	clock    *Clock
+	deadline time.Time
+	c        chan time.Time
+}
+
+// New creates a Clock set to now.
+func New(now time.Time) *Clock {
+	fc := &Clock{now: now}
+	fc.cond = sync.NewCond(&fc.mu)
+	return fc
+}
+
+// Now is part of the timer.Clock interface.
+func (fc *Clock) Now() time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.now
+}
+
+// After is part of the timer.Clock interface.
+func (fc *Clock) After(d time.Duration) <-chan time.Time {
+	return fc.NewTimer(d).C()
+}
+
+// NewTimer is part of the timer.Clock interface.
+func (fc *Clock) NewTimer(d time.Duration) timer.OneShot {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	ft := &fakeTimer{
+		clock:    fc,
+		deadline: fc.now.Add(d),
+		c:        make(chan time.Time, 1),
+	}
+	if d <= 0 {
+		ft.c <- fc.now
+		return ft
+	}
+	fc.timers = append(fc.timers, ft)
+	fc.cond.Broadcast()
+	return ft
+}
+
+// Advance moves the time forward by d, and fires
+// the timers whose deadline was reached.
+func (fc *Clock) Advance(d time.Duration) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.now = fc.now.Add(d)
+	pending := fc.timers[:0]
+	for _, ft := range fc.timers {
+		if ft.deadline.After(fc.now) {
+			pending = append(pending, ft)
+			continue
+		}
+		ft.c <- fc.now
+	}
+	fc.timers = pending
+	fc.cond.Broadcast()
+}
+
+// BlockUntil waits until at least n timers are pending.
+func (fc *Clock) BlockUntil(n int) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	for len(fc.timers) < n {
+		fc.cond.Wait()
+	}
+}
+
+// Pending returns the number of timers that have not fired yet.
+func (fc *Clock) Pending() int {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return len(fc.timers)
+}
+
+// C is part of the timer.OneShot interface.
+func (ft *fakeTimer) C() <-chan time.Time {
+	return ft.c
+}
+
+// Stop is part of the timer.OneShot interface.
+func (ft *fakeTimer) Stop() bool {
+	fc := ft.clock
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	for i, cur := range fc.timers {
+		if cur == ft {
+			fc.timers = append(fc.timers[:i], fc.timers[i+1:]...)
+			fc.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
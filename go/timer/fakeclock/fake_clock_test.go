@@ -0,0 +1,97 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakeclock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"vitess.io/vitess/go/sync2"
+	"vitess.io/vitess/go/timer"
+)
+
+var start = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func fired(ch <-chan time.Time) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestClockAdvance(t *testing.T) {
+	fc := New(start)
+	assert.Equal(t, start, fc.Now())
+
+	t1 := fc.NewTimer(time.Second)
+	t2 := fc.After(2 * time.Second)
+	assert.Equal(t, 2, fc.Pending())
+
+	fc.Advance(999 * time.Millisecond)
+	assert.False(t, fired(t1.C()))
+	fc.Advance(time.Millisecond)
+	assert.Equal(t, start.Add(time.Second), <-t1.C())
+	assert.False(t, fired(t2))
+	assert.False(t, t1.Stop())
+
+	fc.Advance(time.Hour)
+	assert.Equal(t, start.Add(time.Hour+time.Second), <-t2)
+	assert.Zero(t, fc.Pending())
+
+	// Timers that are already due fire right away.
+	assert.True(t, fired(fc.After(0)))
+}
+
+func TestClockStop(t *testing.T) {
+	fc := New(start)
+	t1 := fc.NewTimer(time.Second)
+	assert.True(t, t1.Stop())
+	assert.False(t, t1.Stop())
+	fc.Advance(time.Second)
+	assert.False(t, fired(t1.C()))
+}
+
+func TestClockBlockUntil(t *testing.T) {
+	fc := New(start)
+	ch := make(chan time.Time, 1)
+	go func() {
+		ch <- <-fc.After(time.Second)
+	}()
+	fc.BlockUntil(1)
+	fc.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Second), <-ch)
+}
+
+func TestTimerWithClock(t *testing.T) {
+	fc := New(start)
+	var calls sync2.AtomicInt32
+	tm := timer.NewTimerWithClock(time.Minute, fc)
+	tm.Start(func() { calls.Add(1) })
+	defer tm.Stop()
+
+	for i := 1; i <= 3; i++ {
+		fc.BlockUntil(1)
+		fc.Advance(time.Minute)
+		for calls.Get() != int32(i) {
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
@@ -57,6 +57,7 @@ will react only to an explicit Trigger or Stop.
 */
 type Timer struct {
 	interval sync2.AtomicDuration
+	clock    Clock
 
 	// state management
 	mu      sync.Mutex
@@ -68,8 +69,15 @@ type Timer struct {
 
 // NewTimer creates a new Timer object
 func NewTimer(interval time.Duration) *Timer {
+	return NewTimerWithClock(interval, RealClock)
+}
+
+// NewTimerWithClock creates a new Timer object that
+// measures the intervals with the specified clock.
+func NewTimerWithClock(interval time.Duration, clock Clock) *Timer {
 	tm := &Timer{
-		msg: make(chan typeAction),
+		clock: clock,
+		msg:   make(chan typeAction),
 	}
 	tm.interval.Set(interval)
 	return tm
@@ -87,13 +95,13 @@ func (tm *Timer) Start(keephouse func()) {
 }
 
 func (tm *Timer) run(keephouse func()) {
-	var timer *time.Timer
+	var timer OneShot
 	for {
 		var ch <-chan time.Time
 		interval := tm.interval.Get()
 		if interval > 0 {
-			timer = time.NewTimer(interval)
-			ch = timer.C
+			timer = tm.clock.NewTimer(interval)
+			ch = timer.C()
 		}
 		select {
 		case action := <-tm.msg:
@@ -137,7 +145,7 @@ func (tm *Timer) Trigger() {
 // TriggerAfter waits for the specified duration and triggers the next event.
 func (tm *Timer) TriggerAfter(duration time.Duration) {
 	go func() {
-		time.Sleep(duration)
+		<-tm.clock.After(duration)
 		tm.Trigger()
 	}()
 }
@@ -171,7 +171,7 @@ func NewStreamer(cp dbconfigs.Connector, se *schema.Engine, clientCharset *binlo
 func (bls *Streamer) Stream(ctx context.Context) (err error) {
 	// Ensure se is Open. If vttablet came up in a non_serving role,
 	// the schema engine may not have been initialized.
-	if err := bls.se.Open(); err != nil {
+	if err := bls.se.Open(ctx); err != nil {
 		return err
 	}
 	stopPos := bls.startPos
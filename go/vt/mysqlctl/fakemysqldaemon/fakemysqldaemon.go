@@ -154,6 +154,8 @@ type FakeMysqlDaemon struct {
 	SemiSyncMasterEnabled bool
 	// SemiSyncReplicaEnabled represents the state of rpl_semi_sync_slave_enabled.
 	SemiSyncReplicaEnabled bool
+	// SemiSyncMasterClients is returned by SemiSyncClients.
+	SemiSyncMasterClients uint32
 
 	// TimeoutHook is a func that can be called at the beginning of any method to fake a timeout.
 	// all a test needs to do is make it { return context.DeadlineExceeded }
@@ -529,3 +531,8 @@ func (fmd *FakeMysqlDaemon) SemiSyncReplicationStatus() (bool, error) {
 	// The fake assumes the status worked.
 	return fmd.SemiSyncReplicaEnabled, nil
 }
+
+// SemiSyncClients is part of the MysqlDaemon interface.
+func (fmd *FakeMysqlDaemon) SemiSyncClients() (uint32, error) {
+	return fmd.SemiSyncMasterClients, nil
+}
@@ -51,6 +51,7 @@ type MysqlDaemon interface {
 	SetSemiSyncEnabled(master, replica bool) error
 	SemiSyncEnabled() (master, replica bool)
 	SemiSyncReplicationStatus() (bool, error)
+	SemiSyncClients() (uint32, error)
 
 	// reparenting related methods
 	ResetReplication(ctx context.Context) error
@@ -510,3 +510,20 @@ func (mysqld *Mysqld) SemiSyncReplicationStatus() (bool, error) {
 	}
 	return false, nil
 }
+
+// SemiSyncClients returns the number of semi-sync replicas
+// connected to the master.
+func (mysqld *Mysqld) SemiSyncClients() (uint32, error) {
+	qr, err := mysqld.FetchSuperQuery(context.TODO(), "SHOW STATUS LIKE 'Rpl_semi_sync_master_clients'")
+	if err != nil {
+		return 0, err
+	}
+	if len(qr.Rows) != 1 {
+		return 0, errors.New("no Rpl_semi_sync_master_clients variable in mysql")
+	}
+	clients, err := evalengine.ToUint64(qr.Rows[0][1])
+	if err != nil {
+		return 0, err
+	}
+	return uint32(clients), nil
+}
@@ -1406,12 +1406,15 @@ func (m *VEvent) GetLastPKEvent() *LastPKEvent {
 }
 
 type MinimalTable struct {
-	Name                 string         `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Fields               []*query.Field `protobuf:"bytes,2,rep,name=fields,proto3" json:"fields,omitempty"`
-	PKColumns            []int64        `protobuf:"varint,3,rep,packed,name=p_k_columns,json=pKColumns,proto3" json:"p_k_columns,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
-	XXX_unrecognized     []byte         `json:"-"`
-	XXX_sizecache        int32          `json:"-"`
+	Name      string         `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Fields    []*query.Field `protobuf:"bytes,2,rep,name=fields,proto3" json:"fields,omitempty"`
+	PKColumns []int64        `protobuf:"varint,3,rep,packed,name=p_k_columns,json=pKColumns,proto3" json:"p_k_columns,omitempty"`
+	// view_definition is the select statement of a view.
+	// It's empty for tables.
+	ViewDefinition       string   `protobuf:"bytes,4,opt,name=view_definition,json=viewDefinition,proto3" json:"view_definition,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *MinimalTable) Reset()         { *m = MinimalTable{} }
@@ -1460,6 +1463,13 @@ func (m *MinimalTable) GetPKColumns() []int64 {
 	return nil
 }
 
+func (m *MinimalTable) GetViewDefinition() string {
+	if m != nil {
+		return m.ViewDefinition
+	}
+	return ""
+}
+
 type MinimalSchema struct {
 	Tables               []*MinimalTable `protobuf:"bytes,1,rep,name=tables,proto3" json:"tables,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
@@ -2019,125 +2029,126 @@ func init() {
 func init() { proto.RegisterFile("binlogdata.proto", fileDescriptor_5fd02bcb2e350dad) }
 
 var fileDescriptor_5fd02bcb2e350dad = []byte{
-	// 1914 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xdc, 0x58, 0x4b, 0x73, 0xe3, 0xc6,
-	0x11, 0x5e, 0xbe, 0xc9, 0x06, 0x45, 0x41, 0xa3, 0x47, 0x98, 0x2d, 0xdb, 0x25, 0xa3, 0xb2, 0x5e,
-	0x59, 0x55, 0xa1, 0x1c, 0x26, 0xde, 0x5c, 0x62, 0x3b, 0x7c, 0x60, 0xb5, 0x5c, 0xf1, 0xa1, 0x1d,
-	0x62, 0xb5, 0x2e, 0x5f, 0x50, 0x58, 0x70, 0x24, 0x21, 0x02, 0x08, 0x2c, 0x30, 0x94, 0xcc, 0x1f,
-	0x90, 0xaa, 0xdc, 0xf3, 0x2b, 0x72, 0xce, 0x31, 0xc9, 0x35, 0xf9, 0x13, 0xb9, 0xe6, 0x94, 0x5f,
-	0x90, 0x5b, 0x6a, 0x1e, 0x78, 0x69, 0xed, 0x95, 0xd6, 0x55, 0x39, 0xc4, 0x17, 0xd6, 0x4c, 0x4f,
-	0x77, 0x4f, 0xbf, 0xbe, 0x46, 0x73, 0x40, 0x7d, 0xed, 0x2c, 0x5d, 0xff, 0x62, 0x61, 0x51, 0xab,
-	0x13, 0x84, 0x3e, 0xf5, 0x11, 0xa4, 0x94, 0x87, 0xca, 0x35, 0x0d, 0x03, 0x5b, 0x1c, 0x3c, 0x54,
-	0xde, 0xac, 0x48, 0xb8, 0x96, 0x9b, 0x16, 0xf5, 0x03, 0x3f, 0x95, 0xd2, 0x26, 0x50, 0x1b, 0x5c,
-	0x5a, 0x61, 0x44, 0x28, 0xda, 0x83, 0xaa, 0xed, 0x3a, 0x64, 0x49, 0xdb, 0x85, 0xfd, 0xc2, 0x41,
-	0x05, 0xcb, 0x1d, 0x42, 0x50, 0xb6, 0xfd, 0xe5, 0xb2, 0x5d, 0xe4, 0x54, 0xbe, 0x66, 0xbc, 0x11,
-	0x09, 0xaf, 0x49, 0xd8, 0x2e, 0x09, 0x5e, 0xb1, 0xd3, 0xfe, 0x55, 0x82, 0xad, 0x3e, 0xb7, 0xc3,
-	0x08, 0xad, 0x65, 0x64, 0xd9, 0xd4, 0xf1, 0x97, 0xe8, 0x18, 0x20, 0xa2, 0x16, 0x25, 0x1e, 0x59,
-	0xd2, 0xa8, 0x5d, 0xd8, 0x2f, 0x1d, 0x28, 0xdd, 0xc7, 0x9d, 0x8c, 0x07, 0x6f, 0x89, 0x74, 0xe6,
-	0x31, 0x3f, 0xce, 0x88, 0xa2, 0x2e, 0x28, 0xe4, 0x9a, 0x2c, 0xa9, 0x49, 0xfd, 0x2b, 0xb2, 0x6c,
-	0x97, 0xf7, 0x0b, 0x07, 0x4a, 0x77, 0xab, 0x23, 0x1c, 0xd4, 0xd9, 0x89, 0xc1, 0x0e, 0x30, 0x90,
-	0x64, 0xfd, 0xf0, 0xef, 0x45, 0x68, 0x24, 0xda, 0xd0, 0x18, 0xea, 0xb6, 0x45, 0xc9, 0x85, 0x1f,
-	0xae, 0xb9, 0x9b, 0xad, 0xee, 0x67, 0xf7, 0x34, 0xa4, 0x33, 0x90, 0x72, 0x38, 0xd1, 0x80, 0x7e,
-	0x0e, 0x35, 0x5b, 0x44, 0x8f, 0x47, 0x47, 0xe9, 0x6e, 0x67, 0x95, 0xc9, 0xc0, 0xe2, 0x98, 0x07,
-	0xa9, 0x50, 0x8a, 0xde, 0xb8, 0x3c, 0x64, 0x4d, 0xcc, 0x96, 0xda, 0x9f, 0x0a, 0x50, 0x8f, 0xf5,
-	0xa2, 0x6d, 0xd8, 0xec, 0x8f, 0xcd, 0x97, 0x53, 0xac, 0x0f, 0x66, 0xc7, 0xd3, 0xd1, 0x37, 0xfa,
-	0x50, 0x7d, 0x80, 0x9a, 0x50, 0xef, 0x8f, 0xcd, 0xbe, 0x7e, 0x3c, 0x9a, 0xaa, 0x05, 0xb4, 0x01,
-	0x8d, 0xfe, 0xd8, 0x1c, 0xcc, 0x26, 0x93, 0x91, 0xa1, 0x16, 0xd1, 0x26, 0x28, 0xfd, 0xb1, 0x89,
-	0x67, 0xe3, 0x71, 0xbf, 0x37, 0x38, 0x51, 0x4b, 0x68, 0x17, 0xb6, 0xfa, 0x63, 0x73, 0x38, 0x19,
-	0x9b, 0x43, 0xfd, 0x14, 0xeb, 0x83, 0x9e, 0xa1, 0x0f, 0xd5, 0x32, 0x02, 0xa8, 0x32, 0xf2, 0x70,
-	0xac, 0x56, 0xe4, 0x7a, 0xae, 0x1b, 0x6a, 0x55, 0xaa, 0x1b, 0x4d, 0xe7, 0x3a, 0x36, 0xd4, 0x9a,
-	0xdc, 0xbe, 0x3c, 0x1d, 0xf6, 0x0c, 0x5d, 0xad, 0xcb, 0xed, 0x50, 0x1f, 0xeb, 0x86, 0xae, 0x36,
-	0x9e, 0x97, 0xeb, 0x45, 0xb5, 0xf4, 0xbc, 0x5c, 0x2f, 0xa9, 0x65, 0xed, 0x8f, 0x05, 0xd8, 0x9d,
-	0xd3, 0x90, 0x58, 0xde, 0x09, 0x59, 0x63, 0x6b, 0x79, 0x41, 0x30, 0x79, 0xb3, 0x22, 0x11, 0x45,
-	0x0f, 0xa1, 0x1e, 0xf8, 0x91, 0xc3, 0x62, 0xc7, 0x03, 0xdc, 0xc0, 0xc9, 0x1e, 0x1d, 0x41, 0xe3,
-	0x8a, 0xac, 0xcd, 0x90, 0xf1, 0xcb, 0x80, 0xa1, 0x4e, 0x52, 0x90, 0x89, 0xa6, 0xfa, 0x95, 0x5c,
-	0x65, 0xe3, 0x5b, 0xba, 0x3b, 0xbe, 0xda, 0x39, 0xec, 0xdd, 0x36, 0x2a, 0x0a, 0xfc, 0x65, 0x44,
-	0xd0, 0x18, 0x90, 0x10, 0x34, 0x69, 0x9a, 0x5b, 0x6e, 0x9f, 0xd2, 0xfd, 0xf0, 0x9d, 0x05, 0x80,
-	0xb7, 0x5e, 0xdf, 0x26, 0x69, 0xdf, 0xc2, 0xb6, 0xb8, 0xc7, 0xb0, 0x5e, 0xbb, 0x24, 0xba, 0x8f,
-	0xeb, 0x7b, 0x50, 0xa5, 0x9c, 0xb9, 0x5d, 0xdc, 0x2f, 0x1d, 0x34, 0xb0, 0xdc, 0xbd, 0xaf, 0x87,
-	0x0b, 0xd8, 0xc9, 0xdf, 0xfc, 0x3f, 0xf1, 0xef, 0x57, 0x50, 0xc6, 0x2b, 0x97, 0xa0, 0x1d, 0xa8,
-	0x78, 0x16, 0xb5, 0x2f, 0xa5, 0x37, 0x62, 0xc3, 0x5c, 0x39, 0x77, 0x5c, 0x4a, 0x42, 0x9e, 0xc2,
-	0x06, 0x96, 0x3b, 0xed, 0xcf, 0x05, 0xa8, 0x3e, 0xe5, 0x4b, 0xf4, 0x09, 0x54, 0xc2, 0x15, 0x73,
-	0x56, 0x60, 0x5d, 0xcd, 0x5a, 0xc0, 0x34, 0x63, 0x71, 0x8c, 0x46, 0xd0, 0x3a, 0x77, 0x88, 0xbb,
-	0xe0, 0xd0, 0x9d, 0xf8, 0x0b, 0x51, 0x15, 0xad, 0xee, 0xc7, 0x59, 0x01, 0xa1, 0xb3, 0xf3, 0x34,
-	0xc7, 0x88, 0x6f, 0x09, 0x6a, 0x4f, 0xa0, 0x95, 0xe7, 0x60, 0x70, 0xd2, 0x31, 0x36, 0x67, 0x53,
-	0x73, 0x32, 0x9a, 0x4f, 0x7a, 0xc6, 0xe0, 0x99, 0xfa, 0x80, 0x23, 0x46, 0x9f, 0x1b, 0xa6, 0xfe,
-	0xf4, 0xe9, 0x0c, 0x1b, 0x6a, 0x41, 0xfb, 0x77, 0x11, 0x9a, 0x22, 0x28, 0x73, 0x7f, 0x15, 0xda,
-	0x84, 0x65, 0xf1, 0x8a, 0xac, 0xa3, 0xc0, 0xb2, 0x49, 0x9c, 0xc5, 0x78, 0xcf, 0x02, 0x12, 0x5d,
-	0x5a, 0xe1, 0x42, 0x7a, 0x2e, 0x36, 0xe8, 0x73, 0x50, 0x78, 0x36, 0xa9, 0x49, 0xd7, 0x01, 0xe1,
-	0x79, 0x6c, 0x75, 0x77, 0xd2, 0xc2, 0xe6, 0xb9, 0xa2, 0xc6, 0x3a, 0x20, 0x18, 0x68, 0xb2, 0xce,
-	0xa3, 0xa1, 0x7c, 0x0f, 0x34, 0xa4, 0x35, 0x54, 0xc9, 0xd5, 0xd0, 0x61, 0x92, 0x90, 0xaa, 0xd4,
-	0xf2, 0x56, 0xf4, 0xe2, 0x24, 0xa1, 0x0e, 0x54, 0xfd, 0xa5, 0xb9, 0x58, 0xb8, 0xed, 0x1a, 0x37,
-	0xf3, 0x27, 0x59, 0xde, 0xd9, 0x72, 0x38, 0x1c, 0xf7, 0x44, 0x59, 0x54, 0xfc, 0xe5, 0x70, 0xe1,
-	0xa2, 0x47, 0xd0, 0x22, 0xdf, 0x52, 0x12, 0x2e, 0x2d, 0xd7, 0xf4, 0xd6, 0xac, 0x7b, 0xd5, 0xb9,
-	0xeb, 0x1b, 0x31, 0x75, 0xc2, 0x88, 0xe8, 0x13, 0xd8, 0x8c, 0xa8, 0x1f, 0x98, 0xd6, 0x39, 0x25,
-	0xa1, 0x69, 0xfb, 0xc1, 0xba, 0xdd, 0xd8, 0x2f, 0x1c, 0xd4, 0xf1, 0x06, 0x23, 0xf7, 0x18, 0x75,
-	0xe0, 0x07, 0x6b, 0xed, 0x05, 0x34, 0xb0, 0x7f, 0x33, 0xb8, 0xe4, 0xfe, 0x68, 0x50, 0x7d, 0x4d,
-	0xce, 0xfd, 0x90, 0xc8, 0x42, 0x05, 0xd9, 0xc8, 0xb1, 0x7f, 0x83, 0xe5, 0x09, 0xda, 0x87, 0x0a,
-	0xd7, 0x29, 0xdb, 0x45, 0x96, 0x45, 0x1c, 0x68, 0x16, 0xd4, 0xb1, 0x7f, 0xc3, 0xd3, 0x8e, 0x3e,
-	0x04, 0x11, 0x60, 0x73, 0x69, 0x79, 0x71, 0xf6, 0x1a, 0x9c, 0x32, 0xb5, 0x3c, 0x82, 0x9e, 0x80,
-	0x12, 0xfa, 0x37, 0xa6, 0xcd, 0xaf, 0x17, 0x48, 0x54, 0xba, 0xbb, 0xb9, 0xe2, 0x8c, 0x8d, 0xc3,
-	0x10, 0xc6, 0xcb, 0x48, 0x7b, 0x01, 0x90, 0xd6, 0xd6, 0x5d, 0x97, 0xfc, 0x8c, 0x65, 0x83, 0xb8,
-	0x8b, 0x58, 0x7f, 0x53, 0x9a, 0xcc, 0x35, 0x60, 0x79, 0xa6, 0xfd, 0xa1, 0x00, 0x8d, 0x39, 0xab,
-	0x9e, 0x63, 0xea, 0x2c, 0x7e, 0x40, 0xcd, 0x21, 0x28, 0x5f, 0x50, 0x67, 0xc1, 0x8b, 0xad, 0x81,
-	0xf9, 0x1a, 0x7d, 0x1e, 0x1b, 0x16, 0x98, 0x57, 0x51, 0xbb, 0xcc, 0x6f, 0xcf, 0xe5, 0x97, 0x17,
-	0xe2, 0xd8, 0x8a, 0xe8, 0xe9, 0x09, 0xae, 0x73, 0xd6, 0xd3, 0x93, 0x48, 0xfb, 0x0a, 0x2a, 0x67,
-	0xdc, 0x8a, 0x27, 0xa0, 0x70, 0xe5, 0x26, 0xd3, 0x16, 0x63, 0x37, 0x17, 0x9e, 0xc4, 0x62, 0x0c,
-	0x51, 0xbc, 0x8c, 0xb4, 0x1e, 0x6c, 0x9c, 0x48, 0x6b, 0x39, 0xc3, 0xfb, 0xbb, 0xa3, 0xfd, 0xb5,
-	0x08, 0xb5, 0xe7, 0xfe, 0x8a, 0x15, 0x14, 0x6a, 0x41, 0xd1, 0x59, 0x70, 0xb9, 0x12, 0x2e, 0x3a,
-	0x0b, 0xf4, 0x5b, 0x68, 0x79, 0xce, 0x45, 0x68, 0xb1, 0xb2, 0x14, 0x08, 0x13, 0x4d, 0xe2, 0xa7,
-	0x59, 0xcb, 0x26, 0x31, 0x07, 0x87, 0xd9, 0x86, 0x97, 0xdd, 0x66, 0x80, 0x53, 0xca, 0x01, 0xe7,
-	0x11, 0xb4, 0x5c, 0xdf, 0xb6, 0x5c, 0x33, 0x69, 0xdb, 0x65, 0x51, 0xdc, 0x9c, 0x7a, 0x1a, 0xf7,
-	0xee, 0x5b, 0x71, 0xa9, 0xdc, 0x33, 0x2e, 0xe8, 0x0b, 0x68, 0x06, 0x56, 0x48, 0x1d, 0xdb, 0x09,
-	0x2c, 0x36, 0xf8, 0x54, 0xb9, 0x60, 0xce, 0xec, 0x5c, 0xdc, 0x70, 0x8e, 0x1d, 0x7d, 0x0a, 0x6a,
-	0xc4, 0x5b, 0x92, 0x79, 0xe3, 0x87, 0x57, 0xe7, 0xae, 0x7f, 0x13, 0xb5, 0x6b, 0xdc, 0xfe, 0x4d,
-	0x41, 0x7f, 0x15, 0x93, 0xb5, 0xbf, 0x94, 0xa0, 0x7a, 0x26, 0xaa, 0xf3, 0x10, 0xca, 0x3c, 0x46,
-	0x62, 0xb8, 0xd9, 0xcb, 0x5e, 0x26, 0x38, 0x78, 0x80, 0x38, 0x0f, 0xfa, 0x00, 0x1a, 0xd4, 0xf1,
-	0x48, 0x44, 0x2d, 0x2f, 0xe0, 0x41, 0x2d, 0xe1, 0x94, 0xf0, 0x9d, 0x25, 0xf6, 0x01, 0x34, 0x92,
-	0x71, 0x4c, 0x06, 0x2b, 0x25, 0xa0, 0x5f, 0x40, 0x83, 0xe1, 0x8b, 0x0f, 0x5f, 0xed, 0x0a, 0x07,
-	0xec, 0xce, 0x2d, 0x74, 0x71, 0x13, 0x70, 0x3d, 0x8c, 0x11, 0xfb, 0x6b, 0x50, 0x38, 0x22, 0xa4,
-	0x90, 0x68, 0x60, 0x7b, 0xf9, 0x06, 0x16, 0x23, 0x0f, 0x43, 0xda, 0xf3, 0xd1, 0x63, 0xa8, 0x5c,
-	0x73, 0xf3, 0x6a, 0x72, 0x08, 0xcc, 0x3a, 0xca, 0x53, 0x21, 0xce, 0xd9, 0x17, 0xf6, 0x77, 0xa2,
-	0xb2, 0x78, 0xeb, 0xba, 0xf5, 0x85, 0x95, 0x45, 0x87, 0x63, 0x1e, 0x36, 0xa3, 0x2d, 0x3c, 0x97,
-	0x77, 0xaf, 0x06, 0x66, 0x4b, 0xf4, 0x31, 0x34, 0xed, 0x55, 0x18, 0xf2, 0xb1, 0xd3, 0xf1, 0x48,
-	0x7b, 0x87, 0x07, 0x4a, 0x91, 0x34, 0xc3, 0xf1, 0x08, 0xfa, 0x0d, 0xb4, 0x5c, 0x2b, 0xa2, 0x0c,
-	0x78, 0xd2, 0x91, 0x5d, 0x7e, 0x55, 0x0e, 0x7d, 0x02, 0x78, 0xc2, 0x13, 0xc5, 0x4d, 0x37, 0xda,
-	0x25, 0x34, 0x27, 0xce, 0xd2, 0xf1, 0x2c, 0x97, 0x03, 0x94, 0x05, 0x3e, 0xd3, 0x5a, 0xf8, 0xfa,
-	0x7e, 0x5d, 0x05, 0x7d, 0x04, 0x0a, 0x33, 0xc1, 0xf6, 0xdd, 0x95, 0xb7, 0x14, 0xd5, 0x5e, 0xc2,
-	0x8d, 0xe0, 0x64, 0x20, 0x08, 0x0c, 0xa9, 0xf2, 0xa6, 0xb9, 0x7d, 0x49, 0x3c, 0x0b, 0x7d, 0x96,
-	0x20, 0x43, 0xa0, 0xbd, 0x9d, 0xc7, 0x54, 0x6a, 0x54, 0x8c, 0x19, 0xed, 0x1f, 0x45, 0x68, 0x9d,
-	0x89, 0x19, 0x24, 0x9e, 0x7b, 0xbe, 0x82, 0x6d, 0x72, 0x7e, 0x4e, 0x6c, 0xea, 0x5c, 0x13, 0xd3,
-	0xb6, 0x5c, 0x97, 0x84, 0xa6, 0x44, 0xb0, 0xd2, 0xdd, 0xec, 0x88, 0xff, 0x22, 0x03, 0x4e, 0x1f,
-	0x0d, 0xf1, 0x56, 0xc2, 0x2b, 0x49, 0x0b, 0xa4, 0xc3, 0xb6, 0xe3, 0x79, 0x64, 0xe1, 0x58, 0x34,
-	0xab, 0x40, 0xb4, 0xfc, 0x5d, 0xe9, 0xe9, 0x99, 0x71, 0x6c, 0x51, 0x92, 0xaa, 0x49, 0x24, 0x12,
-	0x35, 0x8f, 0x98, 0x33, 0xe1, 0x45, 0x32, 0x4a, 0x6d, 0x48, 0x49, 0x83, 0x13, 0xb1, 0x3c, 0xcc,
-	0x8d, 0x69, 0xe5, 0x5b, 0x63, 0x5a, 0xfa, 0x29, 0xad, 0xdc, 0xf9, 0x29, 0xfd, 0x12, 0x36, 0x45,
-	0xbb, 0x8d, 0x53, 0x1f, 0x23, 0xfc, 0x7b, 0x7b, 0x6e, 0x93, 0xa6, 0x9b, 0x48, 0xfb, 0x02, 0x36,
-	0x93, 0x40, 0xca, 0x31, 0xee, 0x10, 0xaa, 0xbc, 0x7c, 0xe2, 0x74, 0xa0, 0xb7, 0xe1, 0x8b, 0x25,
-	0x87, 0xf6, 0xfb, 0x22, 0xa0, 0x58, 0xde, 0xbf, 0x89, 0xfe, 0x4f, 0x93, 0xb1, 0x03, 0x15, 0x4e,
-	0x97, 0x99, 0x10, 0x1b, 0x16, 0x07, 0x16, 0xd4, 0xe0, 0x2a, 0x49, 0x83, 0x10, 0x7e, 0xc1, 0x7e,
-	0x31, 0x89, 0x56, 0x2e, 0xc5, 0x92, 0x43, 0xfb, 0x5b, 0x01, 0xb6, 0x73, 0x71, 0x90, 0xb1, 0x4c,
-	0x11, 0x53, 0x78, 0x07, 0x62, 0x0e, 0xa0, 0x1e, 0x5c, 0xbd, 0x03, 0x59, 0xc9, 0xe9, 0x77, 0xb6,
-	0xc3, 0x8f, 0xa0, 0x1c, 0xb2, 0xb6, 0x2c, 0xbe, 0xb5, 0xd9, 0xe1, 0x84, 0xd3, 0xd9, 0x84, 0x93,
-	0xf3, 0x23, 0x37, 0xe1, 0x48, 0xfb, 0x1d, 0x50, 0x32, 0x9d, 0x81, 0xb5, 0x92, 0x7c, 0x55, 0xc9,
-	0xd4, 0x7d, 0x6f, 0x51, 0x29, 0x99, 0xa2, 0x62, 0xfd, 0xd9, 0xf6, 0xbd, 0xc0, 0x25, 0x94, 0x88,
-	0x94, 0xd5, 0x71, 0x4a, 0xd0, 0xbe, 0x06, 0x25, 0x23, 0x79, 0xd7, 0x20, 0x93, 0x26, 0xa1, 0x74,
-	0x67, 0x12, 0xfe, 0x59, 0x80, 0xdd, 0xb4, 0x98, 0x57, 0x2e, 0xfd, 0x51, 0xd5, 0xa3, 0x16, 0xc2,
-	0xde, 0x6d, 0xef, 0xde, 0xab, 0xca, 0x7e, 0x40, 0xed, 0x1c, 0x7e, 0x09, 0x4a, 0x66, 0x1e, 0x67,
-	0x7f, 0xdb, 0x47, 0xc7, 0xd3, 0x19, 0xd6, 0xd5, 0x07, 0xa8, 0x0e, 0xe5, 0xb9, 0x31, 0x3b, 0x55,
-	0x0b, 0x6c, 0xa5, 0x7f, 0xad, 0x0f, 0xc4, 0x53, 0x00, 0x5b, 0x99, 0x92, 0xa9, 0x74, 0xf8, 0x9f,
-	0x02, 0x40, 0xfa, 0xc5, 0x47, 0x0a, 0xd4, 0x5e, 0x4e, 0x4f, 0xa6, 0xb3, 0x57, 0x53, 0xa1, 0xe0,
-	0xd8, 0x18, 0x0d, 0xd5, 0x02, 0x6a, 0x40, 0x45, 0xbc, 0x2d, 0x14, 0xd9, 0x0d, 0xf2, 0x61, 0xa1,
-	0x84, 0x9a, 0x50, 0x4f, 0x5e, 0x15, 0xca, 0xa8, 0x06, 0xa5, 0xe4, 0xed, 0x40, 0x3e, 0x16, 0x54,
-	0x99, 0x42, 0xac, 0x9f, 0x8e, 0x7b, 0x03, 0x5d, 0xad, 0xb1, 0x83, 0xe4, 0xd9, 0x00, 0xa0, 0x1a,
-	0xbf, 0x19, 0x30, 0xc9, 0xb9, 0x6e, 0xa8, 0xc0, 0xee, 0x99, 0x19, 0xcf, 0x74, 0xac, 0x2a, 0x8c,
-	0x86, 0x67, 0xaf, 0xd4, 0x26, 0xa3, 0x3d, 0x1d, 0xe9, 0xe3, 0xa1, 0xba, 0x81, 0x36, 0xa0, 0xf1,
-	0x4c, 0xef, 0x61, 0xa3, 0xaf, 0xf7, 0x0c, 0xb5, 0xc5, 0x4e, 0xce, 0xb8, 0x81, 0x9b, 0xec, 0x9a,
-	0xe7, 0xb3, 0x97, 0x78, 0xda, 0x1b, 0xab, 0x2a, 0xdb, 0x9c, 0xe9, 0x78, 0x3e, 0x9a, 0x4d, 0xd5,
-	0x2d, 0x76, 0xcf, 0xb8, 0x37, 0x37, 0x4e, 0x4f, 0x54, 0xc4, 0xe4, 0xe7, 0xbd, 0x33, 0xfd, 0x74,
-	0x36, 0x9a, 0x1a, 0xea, 0xf6, 0xe1, 0x63, 0xf6, 0x9d, 0xcb, 0x4e, 0x80, 0x00, 0x55, 0xa3, 0xd7,
-	0x1f, 0xeb, 0x73, 0xf5, 0x01, 0x5b, 0xcf, 0x9f, 0xf5, 0xf0, 0x70, 0xae, 0x16, 0xfa, 0x9f, 0x7e,
-	0xf3, 0xf8, 0xda, 0xa1, 0x24, 0x8a, 0x3a, 0x8e, 0x7f, 0x24, 0x56, 0x47, 0x17, 0xfe, 0xd1, 0x35,
-	0x3d, 0xe2, 0xcf, 0x63, 0x47, 0x29, 0xe6, 0x5e, 0x57, 0x39, 0xe5, 0x97, 0xff, 0x0d, 0x00, 0x00,
-	0xff, 0xff, 0x23, 0xf6, 0xf5, 0x62, 0x7a, 0x13, 0x00, 0x00,
+	// 1929 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xdc, 0x58, 0x4b, 0x73, 0x1b, 0xc7,
+	0x11, 0xd6, 0xe2, 0x8d, 0x5e, 0x12, 0x5c, 0x0e, 0x1f, 0x41, 0x54, 0xb6, 0x8b, 0xde, 0x8a, 0x2c,
+	0x9a, 0x55, 0x01, 0x1d, 0x24, 0x56, 0x2e, 0xb1, 0x1d, 0x3c, 0x56, 0x14, 0x44, 0x3c, 0xa8, 0xc1,
+	0x8a, 0x72, 0xf9, 0xb2, 0xb5, 0x04, 0x86, 0xe4, 0x86, 0xfb, 0xd2, 0xee, 0x80, 0x34, 0x7e, 0x40,
+	0xaa, 0x72, 0x4d, 0xe5, 0x57, 0xe4, 0x9c, 0x63, 0x92, 0x6b, 0xf2, 0x27, 0x72, 0xcd, 0x29, 0xbf,
+	0x20, 0xb7, 0xd4, 0x3c, 0xf6, 0x01, 0xd2, 0x16, 0x29, 0x57, 0xe5, 0x90, 0x5c, 0x50, 0x33, 0x3d,
+	0xdd, 0x3d, 0xfd, 0xf8, 0xba, 0xb7, 0x31, 0xa0, 0x9d, 0x39, 0xbe, 0x1b, 0x5c, 0xcc, 0x6d, 0x6a,
+	0xb7, 0xc2, 0x28, 0xa0, 0x01, 0x82, 0x8c, 0xf2, 0x58, 0xbd, 0xa6, 0x51, 0x38, 0x13, 0x07, 0x8f,
+	0xd5, 0xb7, 0x0b, 0x12, 0x2d, 0xe5, 0xa6, 0x41, 0x83, 0x30, 0xc8, 0xa4, 0xf4, 0x11, 0x54, 0x7b,
+	0x97, 0x76, 0x14, 0x13, 0x8a, 0x76, 0xa1, 0x32, 0x73, 0x1d, 0xe2, 0xd3, 0xa6, 0xb2, 0xa7, 0xec,
+	0x97, 0xb1, 0xdc, 0x21, 0x04, 0xa5, 0x59, 0xe0, 0xfb, 0xcd, 0x02, 0xa7, 0xf2, 0x35, 0xe3, 0x8d,
+	0x49, 0x74, 0x4d, 0xa2, 0x66, 0x51, 0xf0, 0x8a, 0x9d, 0xfe, 0xcf, 0x22, 0x6c, 0x76, 0xb9, 0x1d,
+	0x66, 0x64, 0xfb, 0xb1, 0x3d, 0xa3, 0x4e, 0xe0, 0xa3, 0x23, 0x80, 0x98, 0xda, 0x94, 0x78, 0xc4,
+	0xa7, 0x71, 0x53, 0xd9, 0x2b, 0xee, 0xab, 0xed, 0xa7, 0xad, 0x9c, 0x07, 0x77, 0x44, 0x5a, 0xd3,
+	0x84, 0x1f, 0xe7, 0x44, 0x51, 0x1b, 0x54, 0x72, 0x4d, 0x7c, 0x6a, 0xd1, 0xe0, 0x8a, 0xf8, 0xcd,
+	0xd2, 0x9e, 0xb2, 0xaf, 0xb6, 0x37, 0x5b, 0xc2, 0x41, 0x83, 0x9d, 0x98, 0xec, 0x00, 0x03, 0x49,
+	0xd7, 0x8f, 0xff, 0x56, 0x80, 0x7a, 0xaa, 0x0d, 0x0d, 0xa1, 0x36, 0xb3, 0x29, 0xb9, 0x08, 0xa2,
+	0x25, 0x77, 0xb3, 0xd1, 0xfe, 0xec, 0x81, 0x86, 0xb4, 0x7a, 0x52, 0x0e, 0xa7, 0x1a, 0xd0, 0x4f,
+	0xa1, 0x3a, 0x13, 0xd1, 0xe3, 0xd1, 0x51, 0xdb, 0x5b, 0x79, 0x65, 0x32, 0xb0, 0x38, 0xe1, 0x41,
+	0x1a, 0x14, 0xe3, 0xb7, 0x2e, 0x0f, 0xd9, 0x1a, 0x66, 0x4b, 0xfd, 0x8f, 0x0a, 0xd4, 0x12, 0xbd,
+	0x68, 0x0b, 0x36, 0xba, 0x43, 0xeb, 0xf5, 0x18, 0x1b, 0xbd, 0xc9, 0xd1, 0x78, 0xf0, 0x8d, 0xd1,
+	0xd7, 0x1e, 0xa1, 0x35, 0xa8, 0x75, 0x87, 0x56, 0xd7, 0x38, 0x1a, 0x8c, 0x35, 0x05, 0xad, 0x43,
+	0xbd, 0x3b, 0xb4, 0x7a, 0x93, 0xd1, 0x68, 0x60, 0x6a, 0x05, 0xb4, 0x01, 0x6a, 0x77, 0x68, 0xe1,
+	0xc9, 0x70, 0xd8, 0xed, 0xf4, 0x8e, 0xb5, 0x22, 0xda, 0x81, 0xcd, 0xee, 0xd0, 0xea, 0x8f, 0x86,
+	0x56, 0xdf, 0x38, 0xc1, 0x46, 0xaf, 0x63, 0x1a, 0x7d, 0xad, 0x84, 0x00, 0x2a, 0x8c, 0xdc, 0x1f,
+	0x6a, 0x65, 0xb9, 0x9e, 0x1a, 0xa6, 0x56, 0x91, 0xea, 0x06, 0xe3, 0xa9, 0x81, 0x4d, 0xad, 0x2a,
+	0xb7, 0xaf, 0x4f, 0xfa, 0x1d, 0xd3, 0xd0, 0x6a, 0x72, 0xdb, 0x37, 0x86, 0x86, 0x69, 0x68, 0xf5,
+	0x97, 0xa5, 0x5a, 0x41, 0x2b, 0xbe, 0x2c, 0xd5, 0x8a, 0x5a, 0x49, 0xff, 0x83, 0x02, 0x3b, 0x53,
+	0x1a, 0x11, 0xdb, 0x3b, 0x26, 0x4b, 0x6c, 0xfb, 0x17, 0x04, 0x93, 0xb7, 0x0b, 0x12, 0x53, 0xf4,
+	0x18, 0x6a, 0x61, 0x10, 0x3b, 0x2c, 0x76, 0x3c, 0xc0, 0x75, 0x9c, 0xee, 0xd1, 0x21, 0xd4, 0xaf,
+	0xc8, 0xd2, 0x8a, 0x18, 0xbf, 0x0c, 0x18, 0x6a, 0xa5, 0x80, 0x4c, 0x35, 0xd5, 0xae, 0xe4, 0x2a,
+	0x1f, 0xdf, 0xe2, 0xfd, 0xf1, 0xd5, 0xcf, 0x61, 0xf7, 0xb6, 0x51, 0x71, 0x18, 0xf8, 0x31, 0x41,
+	0x43, 0x40, 0x42, 0xd0, 0xa2, 0x59, 0x6e, 0xb9, 0x7d, 0x6a, 0xfb, 0xc3, 0x77, 0x02, 0x00, 0x6f,
+	0x9e, 0xdd, 0x26, 0xe9, 0xdf, 0xc2, 0x96, 0xb8, 0xc7, 0xb4, 0xcf, 0x5c, 0x12, 0x3f, 0xc4, 0xf5,
+	0x5d, 0xa8, 0x50, 0xce, 0xdc, 0x2c, 0xec, 0x15, 0xf7, 0xeb, 0x58, 0xee, 0xde, 0xd7, 0xc3, 0x39,
+	0x6c, 0xaf, 0xde, 0xfc, 0x5f, 0xf1, 0xef, 0x17, 0x50, 0xc2, 0x0b, 0x97, 0xa0, 0x6d, 0x28, 0x7b,
+	0x36, 0x9d, 0x5d, 0x4a, 0x6f, 0xc4, 0x86, 0xb9, 0x72, 0xee, 0xb8, 0x94, 0x44, 0x3c, 0x85, 0x75,
+	0x2c, 0x77, 0xfa, 0x9f, 0x14, 0xa8, 0x3c, 0xe7, 0x4b, 0xf4, 0x09, 0x94, 0xa3, 0x85, 0x4b, 0x92,
+	0x5a, 0xd7, 0xf2, 0x16, 0x30, 0xcd, 0x58, 0x1c, 0xa3, 0x01, 0x34, 0xce, 0x1d, 0xe2, 0xce, 0x79,
+	0xe9, 0x8e, 0x82, 0xb9, 0x40, 0x45, 0xa3, 0xfd, 0x71, 0x5e, 0x40, 0xe8, 0x6c, 0x3d, 0x5f, 0x61,
+	0xc4, 0xb7, 0x04, 0xf5, 0x67, 0xd0, 0x58, 0xe5, 0x60, 0xe5, 0x64, 0x60, 0x6c, 0x4d, 0xc6, 0xd6,
+	0x68, 0x30, 0x1d, 0x75, 0xcc, 0xde, 0x0b, 0xed, 0x11, 0xaf, 0x18, 0x63, 0x6a, 0x5a, 0xc6, 0xf3,
+	0xe7, 0x13, 0x6c, 0x6a, 0x8a, 0xfe, 0xaf, 0x02, 0xac, 0x89, 0xa0, 0x4c, 0x83, 0x45, 0x34, 0x23,
+	0x2c, 0x8b, 0x57, 0x64, 0x19, 0x87, 0xf6, 0x8c, 0x24, 0x59, 0x4c, 0xf6, 0x2c, 0x20, 0xf1, 0xa5,
+	0x1d, 0xcd, 0xa5, 0xe7, 0x62, 0x83, 0x3e, 0x07, 0x95, 0x67, 0x93, 0x5a, 0x74, 0x19, 0x12, 0x9e,
+	0xc7, 0x46, 0x7b, 0x3b, 0x03, 0x36, 0xcf, 0x15, 0x35, 0x97, 0x21, 0xc1, 0x40, 0xd3, 0xf5, 0x6a,
+	0x35, 0x94, 0x1e, 0x50, 0x0d, 0x19, 0x86, 0xca, 0x2b, 0x18, 0x3a, 0x48, 0x13, 0x52, 0x91, 0x5a,
+	0xee, 0x44, 0x2f, 0x49, 0x12, 0x6a, 0x41, 0x25, 0xf0, 0xad, 0xf9, 0xdc, 0x6d, 0x56, 0xb9, 0x99,
+	0x3f, 0xca, 0xf3, 0x4e, 0xfc, 0x7e, 0x7f, 0xd8, 0x11, 0xb0, 0x28, 0x07, 0x7e, 0x7f, 0xee, 0xa2,
+	0x27, 0xd0, 0x20, 0xdf, 0x52, 0x12, 0xf9, 0xb6, 0x6b, 0x79, 0x4b, 0xd6, 0xbd, 0x6a, 0xdc, 0xf5,
+	0xf5, 0x84, 0x3a, 0x62, 0x44, 0xf4, 0x09, 0x6c, 0xc4, 0x34, 0x08, 0x2d, 0xfb, 0x9c, 0x92, 0xc8,
+	0x9a, 0x05, 0xe1, 0xb2, 0x59, 0xdf, 0x53, 0xf6, 0x6b, 0x78, 0x9d, 0x91, 0x3b, 0x8c, 0xda, 0x0b,
+	0xc2, 0xa5, 0xfe, 0x0a, 0xea, 0x38, 0xb8, 0xe9, 0x5d, 0x72, 0x7f, 0x74, 0xa8, 0x9c, 0x91, 0xf3,
+	0x20, 0x22, 0x12, 0xa8, 0x20, 0x1b, 0x39, 0x0e, 0x6e, 0xb0, 0x3c, 0x41, 0x7b, 0x50, 0xe6, 0x3a,
+	0x9b, 0x85, 0x3b, 0x2c, 0xe2, 0x40, 0xb7, 0xa1, 0x86, 0x83, 0x1b, 0x9e, 0x76, 0xf4, 0x21, 0x88,
+	0x00, 0x5b, 0xbe, 0xed, 0x25, 0xd9, 0xab, 0x73, 0xca, 0xd8, 0xf6, 0x08, 0x7a, 0x06, 0x6a, 0x14,
+	0xdc, 0x58, 0x33, 0x7e, 0xbd, 0xa8, 0x44, 0xb5, 0xbd, 0xb3, 0x02, 0xce, 0xc4, 0x38, 0x0c, 0x51,
+	0xb2, 0x8c, 0xf5, 0x57, 0x00, 0x19, 0xb6, 0xee, 0xbb, 0xe4, 0x27, 0x2c, 0x1b, 0xc4, 0x9d, 0x27,
+	0xfa, 0xd7, 0xa4, 0xc9, 0x5c, 0x03, 0x96, 0x67, 0xfa, 0xef, 0x14, 0xa8, 0x4f, 0x19, 0x7a, 0x8e,
+	0xa8, 0x33, 0xff, 0x01, 0x98, 0x43, 0x50, 0xba, 0xa0, 0xce, 0x9c, 0x83, 0xad, 0x8e, 0xf9, 0x1a,
+	0x7d, 0x9e, 0x18, 0x16, 0x5a, 0x57, 0x71, 0xb3, 0xc4, 0x6f, 0x5f, 0xc9, 0x2f, 0x07, 0xe2, 0xd0,
+	0x8e, 0xe9, 0xc9, 0x31, 0xae, 0x71, 0xd6, 0x93, 0xe3, 0x58, 0xff, 0x0a, 0xca, 0xa7, 0xdc, 0x8a,
+	0x67, 0xa0, 0x72, 0xe5, 0x16, 0xd3, 0x96, 0xd4, 0xee, 0x4a, 0x78, 0x52, 0x8b, 0x31, 0xc4, 0xc9,
+	0x32, 0xd6, 0x3b, 0xb0, 0x7e, 0x2c, 0xad, 0xe5, 0x0c, 0xef, 0xef, 0x8e, 0xfe, 0x97, 0x02, 0x54,
+	0x5f, 0x06, 0x0b, 0x06, 0x28, 0xd4, 0x80, 0x82, 0x33, 0xe7, 0x72, 0x45, 0x5c, 0x70, 0xe6, 0xe8,
+	0xd7, 0xd0, 0xf0, 0x9c, 0x8b, 0xc8, 0x66, 0xb0, 0x14, 0x15, 0x26, 0x9a, 0xc4, 0x8f, 0xf3, 0x96,
+	0x8d, 0x12, 0x0e, 0x5e, 0x66, 0xeb, 0x5e, 0x7e, 0x9b, 0x2b, 0x9c, 0xe2, 0x4a, 0xe1, 0x3c, 0x81,
+	0x86, 0x1b, 0xcc, 0x6c, 0xd7, 0x4a, 0xdb, 0x76, 0x49, 0x80, 0x9b, 0x53, 0x4f, 0x24, 0xf1, 0x76,
+	0x5c, 0xca, 0x0f, 0x8c, 0x0b, 0xfa, 0x02, 0xd6, 0x42, 0x3b, 0xa2, 0xce, 0xcc, 0x09, 0x6d, 0x36,
+	0xf8, 0x54, 0xb8, 0xe0, 0x8a, 0xd9, 0x2b, 0x71, 0xc3, 0x2b, 0xec, 0xe8, 0x53, 0xd0, 0x62, 0xde,
+	0x92, 0xac, 0x9b, 0x20, 0xba, 0x3a, 0x77, 0x83, 0x9b, 0xb8, 0x59, 0xe5, 0xf6, 0x6f, 0x08, 0xfa,
+	0x9b, 0x84, 0xac, 0xff, 0xb9, 0x08, 0x95, 0x53, 0x81, 0xce, 0x03, 0x28, 0xf1, 0x18, 0x89, 0xe1,
+	0x66, 0x37, 0x7f, 0x99, 0xe0, 0xe0, 0x01, 0xe2, 0x3c, 0xe8, 0x03, 0xa8, 0x53, 0xc7, 0x23, 0x31,
+	0xb5, 0xbd, 0x90, 0x07, 0xb5, 0x88, 0x33, 0xc2, 0x77, 0x42, 0xec, 0x03, 0xa8, 0xa7, 0xe3, 0x98,
+	0x0c, 0x56, 0x46, 0x40, 0x3f, 0x83, 0x3a, 0xab, 0x2f, 0x3e, 0x7c, 0x35, 0xcb, 0xbc, 0x60, 0xb7,
+	0x6f, 0x55, 0x17, 0x37, 0x01, 0xd7, 0x22, 0xb9, 0x42, 0xbf, 0x04, 0x95, 0x57, 0x84, 0x14, 0x12,
+	0x0d, 0x6c, 0x77, 0xb5, 0x81, 0x25, 0x95, 0x87, 0x21, 0xeb, 0xf9, 0xe8, 0x29, 0x94, 0xaf, 0xb9,
+	0x79, 0x55, 0x39, 0x04, 0xe6, 0x1d, 0xe5, 0xa9, 0x10, 0xe7, 0xec, 0x0b, 0xfb, 0x1b, 0x81, 0xac,
+	0x66, 0xed, 0xee, 0x17, 0x56, 0x82, 0x0e, 0x27, 0x3c, 0x6c, 0x46, 0x9b, 0x7b, 0x2e, 0xef, 0x5e,
+	0x75, 0xcc, 0x96, 0xe8, 0x63, 0x58, 0x9b, 0x2d, 0xa2, 0x88, 0x8f, 0x9d, 0x8e, 0x47, 0x9a, 0xdb,
+	0x3c, 0x50, 0xaa, 0xa4, 0x99, 0x8e, 0x47, 0xd0, 0xaf, 0xa0, 0xe1, 0xda, 0x31, 0x65, 0x85, 0x27,
+	0x1d, 0xd9, 0xd9, 0x53, 0x6e, 0x57, 0x9f, 0x28, 0x3c, 0xe1, 0x89, 0xea, 0x66, 0x1b, 0xfd, 0xf7,
+	0x0a, 0xac, 0x8d, 0x1c, 0xdf, 0xf1, 0x6c, 0x97, 0x57, 0x28, 0x8b, 0x7c, 0xae, 0xb7, 0x94, 0xfc,
+	0x07, 0xb7, 0x15, 0xf4, 0x11, 0xa8, 0xcc, 0x86, 0x59, 0xe0, 0x2e, 0x3c, 0x5f, 0xc0, 0xbd, 0x88,
+	0xeb, 0xe1, 0x71, 0x4f, 0x10, 0xd0, 0x53, 0xd8, 0xb8, 0x76, 0xc8, 0x8d, 0x35, 0x27, 0xe7, 0x8e,
+	0x9f, 0x87, 0x7c, 0x83, 0x91, 0xfb, 0x29, 0x95, 0xd5, 0xb4, 0x34, 0x69, 0x3a, 0xbb, 0x24, 0x9e,
+	0x8d, 0x3e, 0x4b, 0x6b, 0x48, 0xf4, 0x85, 0xe6, 0x6a, 0xf5, 0x65, 0xd6, 0x27, 0xd5, 0xa5, 0xff,
+	0xbd, 0x00, 0x8d, 0x53, 0x31, 0xad, 0x24, 0x13, 0xd2, 0x57, 0xb0, 0x45, 0xce, 0xcf, 0xc9, 0x8c,
+	0x3a, 0xd7, 0xc4, 0x9a, 0xd9, 0xae, 0x4b, 0x22, 0x4b, 0xd6, 0xba, 0xda, 0xde, 0x68, 0x89, 0x7f,
+	0x2d, 0x3d, 0x4e, 0x1f, 0xf4, 0xf1, 0x66, 0xca, 0x2b, 0x49, 0x73, 0x64, 0xc0, 0x96, 0xe3, 0x79,
+	0x64, 0xee, 0xd8, 0x34, 0xaf, 0x40, 0x7c, 0x1c, 0x76, 0x64, 0x48, 0x4e, 0xcd, 0x23, 0x9b, 0x92,
+	0x4c, 0x4d, 0x2a, 0x91, 0xaa, 0x79, 0xc2, 0x9c, 0x89, 0x2e, 0xd2, 0xa1, 0x6b, 0x5d, 0x4a, 0x9a,
+	0x9c, 0x88, 0xe5, 0xe1, 0xca, 0x40, 0x57, 0xba, 0x35, 0xd0, 0x65, 0x1f, 0xdd, 0xf2, 0xbd, 0x1f,
+	0xdd, 0x2f, 0x61, 0x43, 0x34, 0xe6, 0x04, 0x24, 0x49, 0x2f, 0xf8, 0xde, 0xee, 0xbc, 0x46, 0xb3,
+	0x4d, 0xac, 0x7f, 0x01, 0x1b, 0x69, 0x20, 0xe5, 0xc0, 0x77, 0x00, 0x15, 0x0e, 0xb4, 0x24, 0x1d,
+	0xe8, 0x6e, 0xa1, 0x63, 0xc9, 0xa1, 0xff, 0xb6, 0x00, 0x28, 0x91, 0x0f, 0x6e, 0xe2, 0xff, 0xd1,
+	0x64, 0x6c, 0x43, 0x99, 0xd3, 0x65, 0x26, 0xc4, 0x86, 0xc5, 0x81, 0x05, 0x35, 0xbc, 0x4a, 0xd3,
+	0x20, 0x84, 0x5f, 0xb1, 0x5f, 0x4c, 0xe2, 0x85, 0x4b, 0xb1, 0xe4, 0xd0, 0xff, 0xaa, 0xc0, 0xd6,
+	0x4a, 0x1c, 0x64, 0x2c, 0xb3, 0xd2, 0x52, 0xde, 0x51, 0x5a, 0xfb, 0x50, 0x0b, 0xaf, 0xde, 0x51,
+	0x82, 0xe9, 0xe9, 0x77, 0x36, 0xce, 0x8f, 0xa0, 0x14, 0x05, 0x37, 0xc9, 0x57, 0x39, 0x3f, 0xc6,
+	0x70, 0x3a, 0x9b, 0x85, 0x56, 0xfc, 0xc8, 0x73, 0x24, 0xf6, 0x3b, 0xa0, 0xe6, 0x7a, 0x08, 0x6b,
+	0x3a, 0xab, 0xa8, 0x92, 0xa9, 0xfb, 0x5e, 0x50, 0xa9, 0x39, 0x50, 0xb1, 0x4e, 0x3e, 0x0b, 0xbc,
+	0xd0, 0x25, 0x94, 0x88, 0x94, 0xd5, 0x70, 0x46, 0xd0, 0xbf, 0x06, 0x35, 0x27, 0x79, 0xdf, 0xc8,
+	0x93, 0x25, 0xa1, 0x78, 0x6f, 0x12, 0xfe, 0xa1, 0xc0, 0x4e, 0x06, 0xe6, 0x85, 0x4b, 0xff, 0xaf,
+	0xf0, 0xa8, 0x47, 0xb0, 0x7b, 0xdb, 0xbb, 0xf7, 0x42, 0xd9, 0x0f, 0xc0, 0xce, 0xc1, 0x97, 0xa0,
+	0xe6, 0x26, 0x77, 0xf6, 0x07, 0x7f, 0x70, 0x34, 0x9e, 0x60, 0x43, 0x7b, 0x84, 0x6a, 0x50, 0x9a,
+	0x9a, 0x93, 0x13, 0x4d, 0x61, 0x2b, 0xe3, 0x6b, 0xa3, 0x27, 0x1e, 0x0d, 0xd8, 0xca, 0x92, 0x4c,
+	0xc5, 0x83, 0x7f, 0x2b, 0x00, 0xd9, 0x6c, 0x80, 0x54, 0xa8, 0xbe, 0x1e, 0x1f, 0x8f, 0x27, 0x6f,
+	0xc6, 0x42, 0xc1, 0x91, 0x39, 0xe8, 0x6b, 0x0a, 0xaa, 0x43, 0x59, 0xbc, 0x42, 0x14, 0xd8, 0x0d,
+	0xf2, 0x09, 0xa2, 0xc8, 0xde, 0x27, 0xd2, 0xf7, 0x87, 0x12, 0xaa, 0x42, 0x31, 0x7d, 0x65, 0x90,
+	0xcf, 0x0a, 0x15, 0xa6, 0x10, 0x1b, 0x27, 0xc3, 0x4e, 0xcf, 0xd0, 0xaa, 0xec, 0x20, 0x7d, 0x60,
+	0x00, 0xa8, 0x24, 0xaf, 0x0b, 0x4c, 0x92, 0xbd, 0x49, 0x00, 0xbb, 0x67, 0x62, 0xbe, 0x30, 0xb0,
+	0xa6, 0x32, 0x1a, 0x9e, 0xbc, 0xd1, 0xd6, 0x18, 0xed, 0xf9, 0xc0, 0x18, 0xf6, 0xb5, 0x75, 0xf6,
+	0x28, 0xf1, 0xc2, 0xe8, 0x60, 0xb3, 0x6b, 0x74, 0x4c, 0xad, 0xc1, 0x4e, 0x4e, 0xb9, 0x81, 0x1b,
+	0xec, 0x9a, 0x97, 0x93, 0xd7, 0x78, 0xdc, 0x19, 0x6a, 0x1a, 0xdb, 0x9c, 0x1a, 0x78, 0x3a, 0x98,
+	0x8c, 0xb5, 0x4d, 0x76, 0xcf, 0xb0, 0x33, 0x35, 0x4f, 0x8e, 0x35, 0xc4, 0xe4, 0xa7, 0x9d, 0x53,
+	0xe3, 0x64, 0x32, 0x18, 0x9b, 0xda, 0xd6, 0xc1, 0x53, 0xf6, 0x9d, 0xcb, 0xcf, 0x8a, 0x00, 0x15,
+	0xb3, 0xd3, 0x1d, 0x1a, 0x53, 0xed, 0x11, 0x5b, 0x4f, 0x5f, 0x74, 0x70, 0x7f, 0xaa, 0x29, 0xdd,
+	0x4f, 0xbf, 0x79, 0x7a, 0xed, 0x50, 0x12, 0xc7, 0x2d, 0x27, 0x38, 0x14, 0xab, 0xc3, 0x8b, 0xe0,
+	0xf0, 0x9a, 0x1e, 0xf2, 0x87, 0xb4, 0xc3, 0xac, 0xe6, 0xce, 0x2a, 0x9c, 0xf2, 0xf3, 0xff, 0x0c,
+	0x00, 0xc5, 0xf1, 0x86, 0x77, 0xa4, 0x13, 0x00, 0x00,
 }
@@ -857,7 +857,12 @@ type ExecuteOptions struct {
 	TransactionIsolation ExecuteOptions_TransactionIsolation `protobuf:"varint,9,opt,name=transaction_isolation,json=transactionIsolation,proto3,enum=query.ExecuteOptions_TransactionIsolation" json:"transaction_isolation,omitempty"`
 	// skip_query_plan_cache specifies if the query plan should be cached by vitess.
 	// By default all query plans are cached.
-	SkipQueryPlanCache   bool     `protobuf:"varint,10,opt,name=skip_query_plan_cache,json=skipQueryPlanCache,proto3" json:"skip_query_plan_cache,omitempty"`
+	SkipQueryPlanCache bool `protobuf:"varint,10,opt,name=skip_query_plan_cache,json=skipQueryPlanCache,proto3" json:"skip_query_plan_cache,omitempty"`
+	// max_replication_lag_ms is the max replication lag, in milliseconds,
+	// a replica can have to serve the request. A replica that lags more
+	// rejects it with a retryable error. Masters always serve it.
+	// 0 means no limit.
+	MaxReplicationLagMs  int64    `protobuf:"varint,11,opt,name=max_replication_lag_ms,json=maxReplicationLagMs,proto3" json:"max_replication_lag_ms,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -930,6 +935,13 @@ func (m *ExecuteOptions) GetSkipQueryPlanCache() bool {
 	return false
 }
 
+func (m *ExecuteOptions) GetMaxReplicationLagMs() int64 {
+	if m != nil {
+		return m.MaxReplicationLagMs
+	}
+	return 0
+}
+
 // Field describes a single column returned by a query
 type Field struct {
 	// name of the field as returned by mysql C API
@@ -3915,10 +3927,63 @@ type RealtimeStats struct {
 	CpuUsage float64 `protobuf:"fixed64,5,opt,name=cpu_usage,json=cpuUsage,proto3" json:"cpu_usage,omitempty"`
 	// qps is the average QPS (queries per second) rate in the last XX seconds
 	// where XX is usually 60 (See query_service_stats.go).
-	Qps                  float64  `protobuf:"fixed64,6,opt,name=qps,proto3" json:"qps,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Qps float64 `protobuf:"fixed64,6,opt,name=qps,proto3" json:"qps,omitempty"`
+	// query_pool_in_use is the number of connections of the query pool
+	// that were in use when the stats were sampled.
+	QueryPoolInUse int64 `protobuf:"varint,7,opt,name=query_pool_in_use,json=queryPoolInUse,proto3" json:"query_pool_in_use,omitempty"`
+	// query_pool_capacity is the capacity of the query pool.
+	QueryPoolCapacity int64 `protobuf:"varint,8,opt,name=query_pool_capacity,json=queryPoolCapacity,proto3" json:"query_pool_capacity,omitempty"`
+	// transaction_pool_in_use is the number of connections of the
+	// transaction pool that were in use when the stats were sampled.
+	TransactionPoolInUse int64 `protobuf:"varint,9,opt,name=transaction_pool_in_use,json=transactionPoolInUse,proto3" json:"transaction_pool_in_use,omitempty"`
+	// transaction_pool_capacity is the capacity of the transaction pool.
+	TransactionPoolCapacity int64 `protobuf:"varint,10,opt,name=transaction_pool_capacity,json=transactionPoolCapacity,proto3" json:"transaction_pool_capacity,omitempty"`
+	// table_schema_changed contains the names of the tables and views
+	// whose schema changed since the last message. It is only set on
+	// messages sent in response to a schema change.
+	TableSchemaChanged []string `protobuf:"bytes,11,rep,name=table_schema_changed,json=tableSchemaChanged,proto3" json:"table_schema_changed,omitempty"`
+	// transition_status describes the progress of a state transition
+	// that takes time to complete, like draining transactions during
+	// a master demotion. It's empty if there is none.
+	TransitionStatus string `protobuf:"bytes,12,opt,name=transition_status,json=transitionStatus,proto3" json:"transition_status,omitempty"`
+	// role_confidence is populated for masters only. It estimates, in
+	// percent, how likely the tablet is to really be the master of its
+	// shard, from its term start, the agreement of the topo, and the
+	// state of replication.
+	RoleConfidence uint32 `protobuf:"varint,13,opt,name=role_confidence,json=roleConfidence,proto3" json:"role_confidence,omitempty"`
+	// role_degraded is set if role_confidence is below the threshold
+	// configured on the tablet.
+	RoleDegraded bool `protobuf:"varint,14,opt,name=role_degraded,json=roleDegraded,proto3" json:"role_degraded,omitempty"`
+	// promotable is set if the tablet would currently be a viable
+	// target for a reparent. It's refreshed at every health check.
+	Promotable bool `protobuf:"varint,15,opt,name=promotable,proto3" json:"promotable,omitempty"`
+	// promotion_blockers lists the checks that failed if the tablet
+	// isn't promotable.
+	PromotionBlockers []string `protobuf:"bytes,16,rep,name=promotion_blockers,json=promotionBlockers,proto3" json:"promotion_blockers,omitempty"`
+	// lag_source is the source of seconds_behind_master: heartbeat,
+	// replica status, or replica status after a heartbeat failure.
+	// It's empty if no lag was measured.
+	LagSource string `protobuf:"bytes,17,opt,name=lag_source,json=lagSource,proto3" json:"lag_source,omitempty"`
+	// unresolved_prepares is populated for masters only. It's the number
+	// of 2pc transactions of the redo log that are not resolved yet.
+	UnresolvedPrepares int64 `protobuf:"varint,18,opt,name=unresolved_prepares,json=unresolvedPrepares,proto3" json:"unresolved_prepares,omitempty"`
+	// unresolved_prepares_max_age_seconds is the age of the oldest
+	// transaction counted by unresolved_prepares.
+	UnresolvedPreparesMaxAgeSeconds uint32 `protobuf:"varint,19,opt,name=unresolved_prepares_max_age_seconds,json=unresolvedPreparesMaxAgeSeconds,proto3" json:"unresolved_prepares_max_age_seconds,omitempty"`
+	// watcher_state is the state of the replication watcher: running,
+	// stopped while its stream restarts, or n/a if it's closed or
+	// disabled, as it is on masters.
+	WatcherState string `protobuf:"bytes,20,opt,name=watcher_state,json=watcherState,proto3" json:"watcher_state,omitempty"`
+	// watcher_seconds_behind is how far behind mysql the events read
+	// by the replication watcher are. It's only set while it's running.
+	WatcherSecondsBehind int64 `protobuf:"varint,21,opt,name=watcher_seconds_behind,json=watcherSecondsBehind,proto3" json:"watcher_seconds_behind,omitempty"`
+	// annotations are key/value pairs set on the tablet by applications
+	// or operators, e.g. to tell the traffic layer that its caches are
+	// warm. They're opaque to vitess.
+	Annotations          map[string]string `protobuf:"bytes,22,rep,name=annotations,proto3" json:"annotations,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *RealtimeStats) Reset()         { *m = RealtimeStats{} }
@@ -3988,6 +4053,118 @@ func (m *RealtimeStats) GetQps() float64 {
 	return 0
 }
 
+func (m *RealtimeStats) GetQueryPoolInUse() int64 {
+	if m != nil {
+		return m.QueryPoolInUse
+	}
+	return 0
+}
+
+func (m *RealtimeStats) GetQueryPoolCapacity() int64 {
+	if m != nil {
+		return m.QueryPoolCapacity
+	}
+	return 0
+}
+
+func (m *RealtimeStats) GetTransactionPoolInUse() int64 {
+	if m != nil {
+		return m.TransactionPoolInUse
+	}
+	return 0
+}
+
+func (m *RealtimeStats) GetTransactionPoolCapacity() int64 {
+	if m != nil {
+		return m.TransactionPoolCapacity
+	}
+	return 0
+}
+
+func (m *RealtimeStats) GetTableSchemaChanged() []string {
+	if m != nil {
+		return m.TableSchemaChanged
+	}
+	return nil
+}
+
+func (m *RealtimeStats) GetTransitionStatus() string {
+	if m != nil {
+		return m.TransitionStatus
+	}
+	return ""
+}
+
+func (m *RealtimeStats) GetRoleConfidence() uint32 {
+	if m != nil {
+		return m.RoleConfidence
+	}
+	return 0
+}
+
+func (m *RealtimeStats) GetRoleDegraded() bool {
+	if m != nil {
+		return m.RoleDegraded
+	}
+	return false
+}
+
+func (m *RealtimeStats) GetPromotable() bool {
+	if m != nil {
+		return m.Promotable
+	}
+	return false
+}
+
+func (m *RealtimeStats) GetPromotionBlockers() []string {
+	if m != nil {
+		return m.PromotionBlockers
+	}
+	return nil
+}
+
+func (m *RealtimeStats) GetLagSource() string {
+	if m != nil {
+		return m.LagSource
+	}
+	return ""
+}
+
+func (m *RealtimeStats) GetUnresolvedPrepares() int64 {
+	if m != nil {
+		return m.UnresolvedPrepares
+	}
+	return 0
+}
+
+func (m *RealtimeStats) GetUnresolvedPreparesMaxAgeSeconds() uint32 {
+	if m != nil {
+		return m.UnresolvedPreparesMaxAgeSeconds
+	}
+	return 0
+}
+
+func (m *RealtimeStats) GetWatcherState() string {
+	if m != nil {
+		return m.WatcherState
+	}
+	return ""
+}
+
+func (m *RealtimeStats) GetWatcherSecondsBehind() int64 {
+	if m != nil {
+		return m.WatcherSecondsBehind
+	}
+	return 0
+}
+
+func (m *RealtimeStats) GetAnnotations() map[string]string {
+	if m != nil {
+		return m.Annotations
+	}
+	return nil
+}
+
 // AggregateStats contains information about the health of a group of
 // tablets for a Target.  It is used to propagate stats from a vtgate
 // to another, or from the Gateway layer of a vtgate to the routing
@@ -4110,7 +4287,16 @@ type StreamHealthResponse struct {
 	// code uses it to verify that it's talking to the correct tablet and that it
 	// hasn't changed in the meantime e.g. due to tablet restarts where ports or
 	// ips have been reused but assigned differently.
-	TabletAlias          *topodata.TabletAlias `protobuf:"bytes,5,opt,name=tablet_alias,json=tabletAlias,proto3" json:"tablet_alias,omitempty"`
+	TabletAlias *topodata.TabletAlias `protobuf:"bytes,5,opt,name=tablet_alias,json=tabletAlias,proto3" json:"tablet_alias,omitempty"`
+	// also_allow lists the tablet types that the tablet still accepts
+	// queries for in addition to target.tablet_type, typically during
+	// the grace period that follows a promotion to MASTER.
+	AlsoAllow []topodata.TabletType `protobuf:"varint,7,rep,packed,name=also_allow,json=alsoAllow,proto3,enum=topodata.TabletType" json:"also_allow,omitempty"`
+	// accepted_tablet_types lists all the tablet types that the tablet
+	// currently wants queries for: target.tablet_type followed by
+	// also_allow. It's empty while the tablet isn't serving, including
+	// while it's in lameduck, so that routing layers can drain it.
+	AcceptedTabletTypes  []topodata.TabletType `protobuf:"varint,8,rep,packed,name=accepted_tablet_types,json=acceptedTabletTypes,proto3,enum=topodata.TabletType" json:"accepted_tablet_types,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
 	XXX_unrecognized     []byte                `json:"-"`
 	XXX_sizecache        int32                 `json:"-"`
@@ -4176,6 +4362,20 @@ func (m *StreamHealthResponse) GetTabletAlias() *topodata.TabletAlias {
 	return nil
 }
 
+func (m *StreamHealthResponse) GetAlsoAllow() []topodata.TabletType {
+	if m != nil {
+		return m.AlsoAllow
+	}
+	return nil
+}
+
+func (m *StreamHealthResponse) GetAcceptedTabletTypes() []topodata.TabletType {
+	if m != nil {
+		return m.AcceptedTabletTypes
+	}
+	return nil
+}
+
 // TransactionMetadata contains the metadata for a distributed transaction.
 type TransactionMetadata struct {
 	Dtid                 string           `protobuf:"bytes,1,opt,name=dtid,proto3" json:"dtid,omitempty"`
@@ -4308,6 +4508,7 @@ func init() {
 	proto.RegisterType((*ReleaseResponse)(nil), "query.ReleaseResponse")
 	proto.RegisterType((*StreamHealthRequest)(nil), "query.StreamHealthRequest")
 	proto.RegisterType((*RealtimeStats)(nil), "query.RealtimeStats")
+	proto.RegisterMapType((map[string]string)(nil), "query.RealtimeStats.AnnotationsEntry")
 	proto.RegisterType((*AggregateStats)(nil), "query.AggregateStats")
 	proto.RegisterType((*StreamHealthResponse)(nil), "query.StreamHealthResponse")
 	proto.RegisterType((*TransactionMetadata)(nil), "query.TransactionMetadata")
@@ -4316,202 +4517,228 @@ func init() {
 func init() { proto.RegisterFile("query.proto", fileDescriptor_5c6ac9b241082464) }
 
 var fileDescriptor_5c6ac9b241082464 = []byte{
-	// 3145 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x5a, 0x4b, 0x70, 0x1b, 0x47,
-	0x7a, 0xd6, 0xe0, 0x45, 0xe0, 0x07, 0x01, 0x36, 0x9b, 0xa4, 0x04, 0x51, 0x7e, 0xd0, 0x63, 0xcb,
-	0x66, 0x98, 0x84, 0x92, 0x28, 0x59, 0x51, 0x6c, 0x27, 0xd1, 0x10, 0x1c, 0xca, 0x90, 0x80, 0x01,
-	0xd4, 0x18, 0x48, 0x96, 0x2a, 0x55, 0x53, 0x43, 0xa0, 0x05, 0x4e, 0x71, 0x80, 0x81, 0x66, 0x86,
-	0x94, 0x78, 0x53, 0xe2, 0x38, 0xce, 0x3b, 0xce, 0xd3, 0x71, 0x5c, 0x71, 0xa5, 0x2a, 0x87, 0x54,
-	0x2e, 0x39, 0xef, 0x79, 0x0f, 0x3e, 0xec, 0x61, 0xab, 0xf6, 0xb8, 0xbb, 0x87, 0xdd, 0x3d, 0x6c,
-	0xed, 0x9e, 0x5c, 0x5b, 0x7b, 0xd8, 0xc3, 0x1e, 0xb6, 0xb6, 0xfa, 0x31, 0x03, 0x80, 0x84, 0x25,
-	0x5a, 0x5e, 0xd7, 0x96, 0x64, 0xdd, 0xfa, 0x7f, 0xf4, 0xe3, 0xfb, 0xfa, 0x9f, 0xbf, 0x1f, 0xd3,
-	0x90, 0xbf, 0xbb, 0x4b, 0xfd, 0xfd, 0xd5, 0x81, 0xef, 0x85, 0x1e, 0x4e, 0x73, 0x61, 0xb1, 0x18,
-	0x7a, 0x03, 0xaf, 0x63, 0x87, 0xb6, 0x50, 0x2f, 0xe6, 0xf7, 0x42, 0x7f, 0xd0, 0x16, 0x82, 0xfa,
-	0x9e, 0x02, 0x19, 0xd3, 0xf6, 0xbb, 0x34, 0xc4, 0x8b, 0x90, 0xdd, 0xa1, 0xfb, 0xc1, 0xc0, 0x6e,
-	0xd3, 0x92, 0xb2, 0xa4, 0x2c, 0xe7, 0x48, 0x2c, 0xe3, 0x79, 0x48, 0x07, 0xdb, 0xb6, 0xdf, 0x29,
-	0x25, 0xb8, 0x41, 0x08, 0xf8, 0x75, 0xc8, 0x87, 0xf6, 0x96, 0x4b, 0x43, 0x2b, 0xdc, 0x1f, 0xd0,
-	0x52, 0x72, 0x49, 0x59, 0x2e, 0xae, 0xcd, 0xaf, 0xc6, 0xfd, 0x99, 0xdc, 0x68, 0xee, 0x0f, 0x28,
-	0x81, 0x30, 0x2e, 0x63, 0x0c, 0xa9, 0x36, 0x75, 0xdd, 0x52, 0x8a, 0xb7, 0xc5, 0xcb, 0xea, 0x06,
-	0x14, 0x6f, 0x98, 0x57, 0xec, 0x90, 0x96, 0x6d, 0xd7, 0xa5, 0x7e, 0x65, 0x83, 0x0d, 0x67, 0x37,
-	0xa0, 0x7e, 0xdf, 0xee, 0xc5, 0xc3, 0x89, 0x64, 0x7c, 0x1c, 0x32, 0x5d, 0xdf, 0xdb, 0x1d, 0x04,
-	0xa5, 0xc4, 0x52, 0x72, 0x39, 0x47, 0xa4, 0xa4, 0xfe, 0x29, 0x80, 0xbe, 0x47, 0xfb, 0xa1, 0xe9,
-	0xed, 0xd0, 0x3e, 0x7e, 0x0e, 0x72, 0xa1, 0xd3, 0xa3, 0x41, 0x68, 0xf7, 0x06, 0xbc, 0x89, 0x24,
-	0x19, 0x2a, 0x3e, 0x07, 0xd2, 0x22, 0x64, 0x07, 0x5e, 0xe0, 0x84, 0x8e, 0xd7, 0xe7, 0x78, 0x72,
-	0x24, 0x96, 0xd5, 0x3f, 0x86, 0xf4, 0x0d, 0xdb, 0xdd, 0xa5, 0xf8, 0x45, 0x48, 0x71, 0xc0, 0x0a,
-	0x07, 0x9c, 0x5f, 0x15, 0xa4, 0x73, 0x9c, 0xdc, 0xc0, 0xda, 0xde, 0x63, 0x9e, 0xbc, 0xed, 0x69,
-	0x22, 0x04, 0x75, 0x07, 0xa6, 0xd7, 0x9d, 0x7e, 0xe7, 0x86, 0xed, 0x3b, 0x8c, 0x8c, 0xc7, 0x6c,
-	0x06, 0xbf, 0x02, 0x19, 0x5e, 0x08, 0x4a, 0xc9, 0xa5, 0xe4, 0x72, 0x7e, 0x6d, 0x5a, 0x56, 0xe4,
-	0x63, 0x23, 0xd2, 0xa6, 0x7e, 0x53, 0x01, 0x58, 0xf7, 0x76, 0xfb, 0x9d, 0xeb, 0xcc, 0x88, 0x11,
-	0x24, 0x83, 0xbb, 0xae, 0x24, 0x92, 0x15, 0xf1, 0x35, 0x28, 0x6e, 0x39, 0xfd, 0x8e, 0xb5, 0x27,
-	0x87, 0x23, 0xb8, 0xcc, 0xaf, 0xbd, 0x22, 0x9b, 0x1b, 0x56, 0x5e, 0x1d, 0x1d, 0x75, 0xa0, 0xf7,
-	0x43, 0x7f, 0x9f, 0x14, 0xb6, 0x46, 0x75, 0x8b, 0x2d, 0xc0, 0x87, 0x9d, 0x58, 0xa7, 0x3b, 0x74,
-	0x3f, 0xea, 0x74, 0x87, 0xee, 0xe3, 0xdf, 0x19, 0x45, 0x94, 0x5f, 0x9b, 0x8b, 0xfa, 0x1a, 0xa9,
-	0x2b, 0x61, 0xbe, 0x91, 0xb8, 0xa4, 0xa8, 0xdf, 0x48, 0x43, 0x51, 0xbf, 0x4f, 0xdb, 0xbb, 0x21,
-	0xad, 0x0f, 0xd8, 0x1c, 0x04, 0xb8, 0x06, 0x33, 0x4e, 0xbf, 0xed, 0xee, 0x76, 0x68, 0xc7, 0xba,
-	0xe3, 0x50, 0xb7, 0x13, 0xf0, 0x38, 0x2a, 0xc6, 0xe3, 0x1e, 0xf7, 0x5f, 0xad, 0x48, 0xe7, 0x4d,
-	0xee, 0x4b, 0x8a, 0xce, 0x98, 0x8c, 0x57, 0x60, 0xb6, 0xed, 0x3a, 0xb4, 0x1f, 0x5a, 0x77, 0x18,
-	0x5e, 0xcb, 0xf7, 0xee, 0x05, 0xa5, 0xf4, 0x92, 0xb2, 0x9c, 0x25, 0x33, 0xc2, 0xb0, 0xc9, 0xf4,
-	0xc4, 0xbb, 0x17, 0xe0, 0x37, 0x20, 0x7b, 0xcf, 0xf3, 0x77, 0x5c, 0xcf, 0xee, 0x94, 0x32, 0xbc,
-	0xcf, 0x17, 0x26, 0xf7, 0x79, 0x53, 0x7a, 0x91, 0xd8, 0x1f, 0x2f, 0x03, 0x0a, 0xee, 0xba, 0x56,
-	0x40, 0x5d, 0xda, 0x0e, 0x2d, 0xd7, 0xe9, 0x39, 0x61, 0x29, 0xcb, 0x43, 0xb2, 0x18, 0xdc, 0x75,
-	0x9b, 0x5c, 0x5d, 0x65, 0x5a, 0x6c, 0xc1, 0x42, 0xe8, 0xdb, 0xfd, 0xc0, 0x6e, 0xb3, 0xc6, 0x2c,
-	0x27, 0xf0, 0x5c, 0x9b, 0x87, 0x63, 0x8e, 0x77, 0xb9, 0x32, 0xb9, 0x4b, 0x73, 0x58, 0xa5, 0x12,
-	0xd5, 0x20, 0xf3, 0xe1, 0x04, 0x2d, 0x3e, 0x07, 0x0b, 0xc1, 0x8e, 0x33, 0xb0, 0x78, 0x3b, 0xd6,
-	0xc0, 0xb5, 0xfb, 0x56, 0xdb, 0x6e, 0x6f, 0xd3, 0x12, 0x70, 0xd8, 0x98, 0x19, 0xf9, 0xbc, 0x37,
-	0x5c, 0xbb, 0x5f, 0x66, 0x16, 0xf5, 0x4d, 0x28, 0x8e, 0xf3, 0x88, 0x67, 0xa1, 0x60, 0xde, 0x6a,
-	0xe8, 0x96, 0x66, 0x6c, 0x58, 0x86, 0x56, 0xd3, 0xd1, 0x31, 0x5c, 0x80, 0x1c, 0x57, 0xd5, 0x8d,
-	0xea, 0x2d, 0xa4, 0xe0, 0x29, 0x48, 0x6a, 0xd5, 0x2a, 0x4a, 0xa8, 0x97, 0x20, 0x1b, 0x11, 0x82,
-	0x67, 0x20, 0xdf, 0x32, 0x9a, 0x0d, 0xbd, 0x5c, 0xd9, 0xac, 0xe8, 0x1b, 0xe8, 0x18, 0xce, 0x42,
-	0xaa, 0x5e, 0x35, 0x1b, 0x48, 0x11, 0x25, 0xad, 0x81, 0x12, 0xac, 0xe6, 0xc6, 0xba, 0x86, 0x92,
-	0xea, 0xff, 0x2a, 0x30, 0x3f, 0x09, 0x18, 0xce, 0xc3, 0xd4, 0x86, 0xbe, 0xa9, 0xb5, 0xaa, 0x26,
-	0x3a, 0x86, 0xe7, 0x60, 0x86, 0xe8, 0x0d, 0x5d, 0x33, 0xb5, 0xf5, 0xaa, 0x6e, 0x11, 0x5d, 0xdb,
-	0x40, 0x0a, 0xc6, 0x50, 0x64, 0x25, 0xab, 0x5c, 0xaf, 0xd5, 0x2a, 0xa6, 0xa9, 0x6f, 0xa0, 0x04,
-	0x9e, 0x07, 0xc4, 0x75, 0x2d, 0x63, 0xa8, 0x4d, 0x62, 0x04, 0xd3, 0x4d, 0x9d, 0x54, 0xb4, 0x6a,
-	0xe5, 0x36, 0x6b, 0x00, 0xa5, 0xf0, 0x4b, 0xf0, 0x7c, 0xb9, 0x6e, 0x34, 0x2b, 0x4d, 0x53, 0x37,
-	0x4c, 0xab, 0x69, 0x68, 0x8d, 0xe6, 0xdb, 0x75, 0x93, 0xb7, 0x2c, 0xc0, 0xa5, 0x71, 0x11, 0x40,
-	0x6b, 0x99, 0x75, 0xd1, 0x0e, 0xca, 0x5c, 0x4d, 0x65, 0x15, 0x94, 0xb8, 0x9a, 0xca, 0x26, 0x50,
-	0xf2, 0x6a, 0x2a, 0x9b, 0x44, 0x29, 0xf5, 0xc3, 0x04, 0xa4, 0x39, 0x57, 0x2c, 0xdd, 0x8d, 0x24,
-	0x31, 0x5e, 0x8e, 0x3f, 0xfd, 0xc4, 0x43, 0x3e, 0x7d, 0x9e, 0x31, 0x65, 0x12, 0x12, 0x02, 0x3e,
-	0x05, 0x39, 0xcf, 0xef, 0x5a, 0xc2, 0x22, 0xd2, 0x67, 0xd6, 0xf3, 0xbb, 0x3c, 0xcf, 0xb2, 0xd4,
-	0xc5, 0xb2, 0xee, 0x96, 0x1d, 0x50, 0x1e, 0xc1, 0x39, 0x12, 0xcb, 0xf8, 0x24, 0x30, 0x3f, 0x8b,
-	0x8f, 0x23, 0xc3, 0x6d, 0x53, 0x9e, 0xdf, 0x35, 0xd8, 0x50, 0x5e, 0x86, 0x42, 0xdb, 0x73, 0x77,
-	0x7b, 0x7d, 0xcb, 0xa5, 0xfd, 0x6e, 0xb8, 0x5d, 0x9a, 0x5a, 0x52, 0x96, 0x0b, 0x64, 0x5a, 0x28,
-	0xab, 0x5c, 0x87, 0x4b, 0x30, 0xd5, 0xde, 0xb6, 0xfd, 0x80, 0x8a, 0xa8, 0x2d, 0x90, 0x48, 0xe4,
-	0xbd, 0xd2, 0xb6, 0xd3, 0xb3, 0xdd, 0x80, 0x47, 0x68, 0x81, 0xc4, 0x32, 0x03, 0x71, 0xc7, 0xb5,
-	0xbb, 0x01, 0x8f, 0xac, 0x02, 0x11, 0x82, 0xfa, 0x07, 0x90, 0x24, 0xde, 0x3d, 0xd6, 0xa4, 0xe8,
-	0x30, 0x28, 0x29, 0x4b, 0xc9, 0x65, 0x4c, 0x22, 0x91, 0x65, 0x77, 0x99, 0xe0, 0x44, 0xde, 0x8b,
-	0x52, 0xda, 0xc7, 0x0a, 0xe4, 0x79, 0x60, 0x12, 0x1a, 0xec, 0xba, 0x21, 0x4b, 0x84, 0x32, 0x03,
-	0x28, 0x63, 0x89, 0x90, 0xd3, 0x4e, 0xa4, 0x8d, 0xe1, 0x63, 0x1f, 0xb5, 0x65, 0xdf, 0xb9, 0x43,
-	0xdb, 0x21, 0x15, 0xf9, 0x3e, 0x45, 0xa6, 0x99, 0x52, 0x93, 0x3a, 0x46, 0xac, 0xd3, 0x0f, 0xa8,
-	0x1f, 0x5a, 0x4e, 0x87, 0x53, 0x9e, 0x22, 0x59, 0xa1, 0xa8, 0x74, 0xf0, 0x0b, 0x90, 0xe2, 0x69,
-	0x21, 0xc5, 0x7b, 0x01, 0xd9, 0x0b, 0xf1, 0xee, 0x11, 0xae, 0xbf, 0x9a, 0xca, 0xa6, 0x51, 0x46,
-	0x7d, 0x0b, 0xa6, 0xf9, 0xe0, 0x6e, 0xda, 0x7e, 0xdf, 0xe9, 0x77, 0xf9, 0x2a, 0xe7, 0x75, 0xc4,
-	0xb4, 0x17, 0x08, 0x2f, 0x33, 0xcc, 0x3d, 0x1a, 0x04, 0x76, 0x97, 0xca, 0x55, 0x27, 0x12, 0xd5,
-	0xff, 0x4e, 0x42, 0xbe, 0x19, 0xfa, 0xd4, 0xee, 0xf1, 0x05, 0x0c, 0xbf, 0x05, 0x10, 0x84, 0x76,
-	0x48, 0x7b, 0xb4, 0x1f, 0x46, 0xf8, 0x9e, 0x93, 0x3d, 0x8f, 0xf8, 0xad, 0x36, 0x23, 0x27, 0x32,
-	0xe2, 0x8f, 0xd7, 0x20, 0x4f, 0x99, 0xd9, 0x0a, 0xd9, 0x42, 0x28, 0x93, 0xed, 0x6c, 0x94, 0x39,
-	0xe2, 0x15, 0x92, 0x00, 0x8d, 0xcb, 0x8b, 0x9f, 0x24, 0x20, 0x17, 0xb7, 0x86, 0x35, 0xc8, 0xb6,
-	0xed, 0x90, 0x76, 0x3d, 0x7f, 0x5f, 0xae, 0x4f, 0xa7, 0x1f, 0xd6, 0xfb, 0x6a, 0x59, 0x3a, 0x93,
-	0xb8, 0x1a, 0x7e, 0x1e, 0xc4, 0xa2, 0x2f, 0xa2, 0x4e, 0xe0, 0xcd, 0x71, 0x0d, 0x8f, 0xbb, 0x37,
-	0x00, 0x0f, 0x7c, 0xa7, 0x67, 0xfb, 0xfb, 0xd6, 0x0e, 0xdd, 0x8f, 0x72, 0x79, 0x72, 0xc2, 0x4c,
-	0x22, 0xe9, 0x77, 0x8d, 0xee, 0xcb, 0xec, 0x73, 0x69, 0xbc, 0xae, 0x8c, 0x96, 0xc3, 0xf3, 0x33,
-	0x52, 0x93, 0xaf, 0x8e, 0x41, 0xb4, 0x0e, 0xa6, 0x79, 0x60, 0xb1, 0xa2, 0xfa, 0x1a, 0x64, 0xa3,
-	0xc1, 0xe3, 0x1c, 0xa4, 0x75, 0xdf, 0xf7, 0x7c, 0x74, 0x8c, 0x27, 0xa1, 0x5a, 0x55, 0xe4, 0xb1,
-	0x8d, 0x0d, 0x96, 0xc7, 0x7e, 0x94, 0x88, 0x17, 0x23, 0x42, 0xef, 0xee, 0xd2, 0x20, 0xc4, 0x7f,
-	0x02, 0x73, 0x94, 0x87, 0x90, 0xb3, 0x47, 0xad, 0x36, 0xdf, 0xb9, 0xb0, 0x00, 0x52, 0x38, 0xdf,
-	0x33, 0xab, 0x62, 0xa3, 0x15, 0xed, 0x68, 0xc8, 0x6c, 0xec, 0x2b, 0x55, 0x1d, 0xac, 0xc3, 0x9c,
-	0xd3, 0xeb, 0xd1, 0x8e, 0x63, 0x87, 0xa3, 0x0d, 0x88, 0x09, 0x5b, 0x88, 0x16, 0xf6, 0xb1, 0x8d,
-	0x11, 0x99, 0x8d, 0x6b, 0xc4, 0xcd, 0x9c, 0x86, 0x4c, 0xc8, 0x37, 0x71, 0x3c, 0x76, 0xf3, 0x6b,
-	0x85, 0x28, 0xa1, 0x70, 0x25, 0x91, 0x46, 0xfc, 0x1a, 0x88, 0x2d, 0x21, 0x4f, 0x1d, 0xc3, 0x80,
-	0x18, 0xae, 0xf4, 0x44, 0xd8, 0xf1, 0x69, 0x28, 0x8e, 0xad, 0x41, 0x1d, 0x4e, 0x58, 0x92, 0x14,
-	0x46, 0x17, 0x94, 0x0e, 0x3e, 0x03, 0x53, 0x9e, 0x58, 0x7f, 0x78, 0x52, 0x19, 0x8e, 0x78, 0x7c,
-	0x71, 0x22, 0x91, 0x17, 0x7e, 0x11, 0xf2, 0x3e, 0x0d, 0xa8, 0xbf, 0x47, 0x3b, 0xac, 0xd1, 0x29,
-	0xde, 0x28, 0x44, 0xaa, 0x4a, 0x47, 0xfd, 0x23, 0x98, 0x89, 0x29, 0x0e, 0x06, 0x5e, 0x3f, 0xa0,
-	0x78, 0x05, 0x32, 0x3e, 0xff, 0xde, 0x25, 0xad, 0x58, 0xf6, 0x31, 0x92, 0x09, 0x88, 0xf4, 0x50,
-	0x3b, 0x30, 0x23, 0x34, 0x37, 0x9d, 0x70, 0x9b, 0xcf, 0x24, 0x3e, 0x0d, 0x69, 0xca, 0x0a, 0x07,
-	0x26, 0x85, 0x34, 0xca, 0xdc, 0x4e, 0x84, 0x75, 0xa4, 0x97, 0xc4, 0x23, 0x7b, 0xf9, 0x59, 0x02,
-	0xe6, 0xe4, 0x28, 0xd7, 0xed, 0xb0, 0xbd, 0xfd, 0x84, 0x46, 0xc3, 0xef, 0xc2, 0x14, 0xd3, 0x3b,
-	0xf1, 0x97, 0x33, 0x21, 0x1e, 0x22, 0x0f, 0x16, 0x11, 0x76, 0x60, 0x8d, 0x4c, 0xbf, 0xdc, 0x24,
-	0x15, 0xec, 0x60, 0x64, 0x85, 0x9e, 0x10, 0x38, 0x99, 0x47, 0x04, 0xce, 0xd4, 0x51, 0x02, 0x47,
-	0xdd, 0x80, 0xf9, 0x71, 0xc6, 0x65, 0x70, 0xfc, 0x1e, 0x4c, 0x89, 0x49, 0x89, 0x72, 0xe4, 0xa4,
-	0x79, 0x8b, 0x5c, 0xd4, 0x4f, 0x13, 0x30, 0x2f, 0xd3, 0xd7, 0xd7, 0xe3, 0x3b, 0x1e, 0xe1, 0x39,
-	0x7d, 0xa4, 0x0f, 0xf4, 0x68, 0xf3, 0xa7, 0x96, 0x61, 0xe1, 0x00, 0x8f, 0x8f, 0xf1, 0xb1, 0x7e,
-	0xa6, 0xc0, 0xf4, 0x3a, 0xed, 0x3a, 0xfd, 0x27, 0x74, 0x16, 0x46, 0xc8, 0x4d, 0x1d, 0x29, 0x88,
-	0x07, 0x50, 0x90, 0x78, 0x25, 0x5b, 0x87, 0xd9, 0x56, 0x26, 0x7d, 0x2d, 0x97, 0x60, 0x5a, 0x1e,
-	0xb3, 0x6d, 0xd7, 0xb1, 0x83, 0x18, 0xcf, 0x81, 0x73, 0xb6, 0xc6, 0x8c, 0x44, 0x9e, 0xc8, 0xb9,
-	0xa0, 0xfe, 0x58, 0x81, 0x42, 0xd9, 0xeb, 0xf5, 0x9c, 0xf0, 0x09, 0xe5, 0xf8, 0x30, 0x43, 0xa9,
-	0x49, 0xf1, 0x78, 0x0e, 0x8a, 0x11, 0x4c, 0x49, 0xed, 0x81, 0x95, 0x46, 0x39, 0xb4, 0xd2, 0xfc,
-	0x44, 0x81, 0x19, 0xe2, 0xb9, 0xee, 0x96, 0xdd, 0xde, 0x79, 0xba, 0xc9, 0x39, 0x0f, 0x68, 0x08,
-	0xf4, 0xa8, 0xf4, 0xfc, 0x52, 0x81, 0x62, 0xc3, 0xa7, 0x03, 0xdb, 0xa7, 0x4f, 0x35, 0x3b, 0x6c,
-	0x9b, 0xde, 0x09, 0xe5, 0x06, 0x27, 0x47, 0x78, 0x59, 0x9d, 0x85, 0x99, 0x18, 0xbb, 0x20, 0x4c,
-	0xfd, 0x9e, 0x02, 0x0b, 0x22, 0xc4, 0xa4, 0xa5, 0xf3, 0x84, 0xd2, 0x12, 0xe1, 0x4d, 0x8d, 0xe0,
-	0x2d, 0xc1, 0xf1, 0x83, 0xd8, 0x24, 0xec, 0x77, 0x13, 0x70, 0x22, 0x0a, 0x9e, 0x27, 0x1c, 0xf8,
-	0x97, 0x88, 0x87, 0x45, 0x28, 0x1d, 0x26, 0x41, 0x32, 0xf4, 0x41, 0x02, 0x4a, 0x65, 0x9f, 0xda,
-	0x21, 0x1d, 0xd9, 0x07, 0x3d, 0x3d, 0xb1, 0x81, 0xcf, 0xc1, 0xf4, 0xc0, 0xf6, 0x43, 0xa7, 0xed,
-	0x0c, 0x6c, 0x76, 0x14, 0x4d, 0xf3, 0x6d, 0xd6, 0x81, 0x06, 0xc6, 0x5c, 0xd4, 0x53, 0x70, 0x72,
-	0x02, 0x23, 0x92, 0xaf, 0x5f, 0x29, 0x80, 0x9b, 0xa1, 0xed, 0x87, 0x5f, 0x83, 0x75, 0x69, 0x62,
-	0x30, 0x2d, 0xc0, 0xdc, 0x18, 0xfe, 0x51, 0x5e, 0x68, 0xf8, 0xb5, 0x58, 0x92, 0x3e, 0x97, 0x97,
-	0x51, 0xfc, 0x92, 0x97, 0x1f, 0x28, 0xb0, 0x58, 0xf6, 0xc4, 0xe5, 0xe3, 0x53, 0xf9, 0x85, 0xa9,
-	0xcf, 0xc3, 0xa9, 0x89, 0x00, 0x25, 0x01, 0xdf, 0x57, 0xe0, 0x38, 0xa1, 0x76, 0xe7, 0xe9, 0x04,
-	0x7f, 0x1d, 0x4e, 0x1c, 0x02, 0x27, 0xf7, 0x28, 0x17, 0x21, 0xdb, 0xa3, 0xa1, 0xcd, 0x76, 0xb8,
-	0x12, 0xd2, 0x62, 0xd4, 0xee, 0xd0, 0xbb, 0x26, 0x3d, 0x48, 0xec, 0xab, 0xfe, 0x30, 0x01, 0x73,
-	0x7c, 0x9f, 0xfd, 0xec, 0x90, 0x77, 0xa4, 0x5b, 0x98, 0xcc, 0xc1, 0xcd, 0x1f, 0x73, 0x18, 0xf8,
-	0xd4, 0x8a, 0x6e, 0x07, 0xa6, 0xf8, 0x3f, 0x36, 0x18, 0xf8, 0xf4, 0xba, 0xd0, 0xa8, 0xdf, 0x52,
-	0x60, 0x7e, 0x9c, 0xe2, 0xf8, 0x44, 0xf3, 0x9b, 0xbe, 0x6d, 0x99, 0x90, 0x52, 0x92, 0x47, 0x39,
-	0x24, 0xa5, 0x8e, 0x7c, 0x48, 0xfa, 0x76, 0x02, 0x4a, 0xa3, 0x60, 0x9e, 0xdd, 0xe9, 0x8c, 0xdf,
-	0xe9, 0x7c, 0xd1, 0x5b, 0x3e, 0xf5, 0x3b, 0x0a, 0x9c, 0x9c, 0x40, 0xe8, 0x17, 0x0b, 0x91, 0x91,
-	0x9b, 0x9d, 0xc4, 0x23, 0x6f, 0x76, 0xbe, 0xfa, 0x20, 0xf9, 0xae, 0x02, 0xf3, 0x35, 0x71, 0x57,
-	0x2f, 0x6e, 0x3e, 0x9e, 0xdc, 0x1c, 0xcc, 0xaf, 0xe3, 0x53, 0xc3, 0x9f, 0x51, 0x6a, 0x19, 0x16,
-	0x0e, 0x40, 0x7b, 0x8c, 0xdb, 0x9c, 0x5f, 0x28, 0x30, 0x2b, 0x5b, 0xd1, 0x9e, 0xd8, 0xed, 0xcb,
-	0x04, 0x76, 0xf0, 0x0b, 0x90, 0x74, 0x3a, 0xd1, 0xbe, 0x77, 0xfc, 0x5f, 0x3b, 0x33, 0xa8, 0x97,
-	0x01, 0x8f, 0xe2, 0x7e, 0x0c, 0xea, 0x7e, 0x9a, 0x80, 0x05, 0x22, 0xb2, 0xef, 0xb3, 0xff, 0x0b,
-	0x5f, 0xf6, 0xff, 0xc2, 0xc3, 0x17, 0xae, 0x4f, 0xf9, 0x66, 0x6a, 0x9c, 0xea, 0xaf, 0x6e, 0xe9,
-	0x3a, 0xb0, 0xd0, 0x26, 0x0f, 0x2d, 0xb4, 0x8f, 0x9f, 0x8f, 0x3e, 0x4d, 0xc0, 0xa2, 0x04, 0xf2,
-	0x6c, 0xaf, 0x73, 0xf4, 0x88, 0xc8, 0x1c, 0x8a, 0x88, 0x9f, 0x2b, 0x70, 0x6a, 0x22, 0x91, 0xbf,
-	0xf5, 0x1d, 0xcd, 0x81, 0xe8, 0x49, 0x3d, 0x32, 0x7a, 0xd2, 0x47, 0x8e, 0x9e, 0xf7, 0x13, 0x50,
-	0x24, 0xd4, 0xa5, 0x76, 0xf0, 0x94, 0xdf, 0xee, 0x1d, 0xe0, 0x30, 0x7d, 0xe8, 0x9e, 0x73, 0x16,
-	0x66, 0x62, 0x22, 0xe4, 0x81, 0x8b, 0x1f, 0xd0, 0xd9, 0x3a, 0xf8, 0x36, 0xb5, 0xdd, 0x30, 0xda,
-	0x09, 0xaa, 0xff, 0x93, 0x80, 0x02, 0x61, 0x1a, 0xa7, 0x47, 0x9b, 0xa1, 0x1d, 0x06, 0xf8, 0x25,
-	0x98, 0xde, 0xe6, 0x2e, 0xd6, 0x30, 0x42, 0x72, 0x24, 0x2f, 0x74, 0xe2, 0xef, 0xe3, 0x1a, 0x2c,
-	0x04, 0xb4, 0xed, 0xf5, 0x3b, 0x81, 0xb5, 0x45, 0xb7, 0x9d, 0x7e, 0xc7, 0xea, 0xd9, 0x41, 0x48,
-	0x7d, 0x4e, 0x4b, 0x81, 0xcc, 0x49, 0xe3, 0x3a, 0xb7, 0xd5, 0xb8, 0x09, 0x9f, 0x85, 0xf9, 0x2d,
-	0xa7, 0xef, 0x7a, 0x5d, 0x6b, 0xe0, 0xda, 0xfb, 0xd4, 0x0f, 0xac, 0xb6, 0xb7, 0xdb, 0x17, 0x7c,
-	0xa4, 0x09, 0x16, 0xb6, 0x86, 0x30, 0x95, 0x99, 0x05, 0xdf, 0x86, 0x95, 0x89, 0xbd, 0x58, 0x77,
-	0x1c, 0x37, 0xa4, 0x3e, 0xed, 0x58, 0x3e, 0x1d, 0xb8, 0x4e, 0x5b, 0xbc, 0x23, 0x12, 0x44, 0xbd,
-	0x3a, 0xa1, 0xeb, 0x4d, 0xe9, 0x4e, 0x86, 0xde, 0xf8, 0x14, 0xe4, 0xda, 0x83, 0x5d, 0x6b, 0x97,
-	0x3f, 0x5a, 0x60, 0xfc, 0x29, 0x24, 0xdb, 0x1e, 0xec, 0xb6, 0x98, 0x8c, 0x11, 0x24, 0xef, 0x0e,
-	0x44, 0x72, 0x56, 0x08, 0x2b, 0xaa, 0x9f, 0x29, 0x50, 0xd4, 0xba, 0x5d, 0x9f, 0x76, 0xed, 0x50,
-	0xd2, 0x74, 0x16, 0xe6, 0x05, 0x25, 0xfb, 0x96, 0x0c, 0x57, 0x81, 0x47, 0x11, 0x78, 0xa4, 0x4d,
-	0xc4, 0xaa, 0xc0, 0x73, 0x01, 0x8e, 0xef, 0xf6, 0x27, 0xd6, 0x49, 0xf0, 0x3a, 0xf3, 0xb1, 0x75,
-	0xb4, 0xd6, 0x1f, 0xc2, 0xc9, 0xc9, 0x2c, 0xf4, 0x1c, 0xf1, 0x96, 0xaf, 0x40, 0x8e, 0x4f, 0x00,
-	0x5d, 0x73, 0xfa, 0x0f, 0xa9, 0x6a, 0xdf, 0xe7, 0x7c, 0x7d, 0x4e, 0x55, 0xfb, 0xbe, 0xfa, 0x7f,
-	0xf1, 0x3f, 0xc5, 0x28, 0x5c, 0xe2, 0xc4, 0x11, 0x05, 0xb2, 0xf2, 0xb0, 0x40, 0x2e, 0xc1, 0x14,
-	0x0b, 0x46, 0xa7, 0xdf, 0xe5, 0xe0, 0xb2, 0x24, 0x12, 0x71, 0x13, 0x5e, 0x95, 0xd8, 0xe9, 0xfd,
-	0x90, 0xfa, 0x7d, 0xdb, 0x75, 0xf7, 0x2d, 0x71, 0xfd, 0xd8, 0x0f, 0x69, 0xc7, 0x1a, 0xbe, 0x6d,
-	0x14, 0xe9, 0xe3, 0x65, 0xe1, 0xad, 0xc7, 0xce, 0x24, 0xf6, 0x35, 0xe3, 0x57, 0x8f, 0x6f, 0x42,
-	0xd1, 0x97, 0x41, 0x6c, 0x05, 0x6c, 0x7a, 0x64, 0xca, 0x9d, 0x8f, 0x5e, 0x4d, 0x8c, 0x46, 0x38,
-	0x29, 0xf8, 0x63, 0x01, 0xff, 0xd8, 0x09, 0xe7, 0x6a, 0x2a, 0x9b, 0x41, 0x53, 0xea, 0xff, 0x2b,
-	0x30, 0x37, 0xe1, 0xec, 0x1e, 0x5f, 0x0c, 0x28, 0x23, 0xf7, 0x8e, 0xbf, 0x0f, 0x69, 0xfe, 0xa0,
-	0x45, 0x3e, 0x91, 0x3a, 0x71, 0xf8, 0xe8, 0xcf, 0x1f, 0x9f, 0x10, 0xe1, 0xc5, 0xbe, 0x45, 0x8e,
-	0xa9, 0xcd, 0x2f, 0x1e, 0xa3, 0x8c, 0x9a, 0x67, 0x3a, 0x71, 0x17, 0x79, 0xf8, 0x26, 0x33, 0xf5,
-	0xc8, 0x9b, 0xcc, 0x95, 0x7f, 0x4e, 0x42, 0xae, 0xb6, 0xdf, 0xbc, 0xeb, 0x6e, 0xba, 0x76, 0x97,
-	0xbf, 0x0e, 0xa9, 0x35, 0xcc, 0x5b, 0xe8, 0x18, 0x9e, 0x85, 0x82, 0x51, 0x37, 0x2d, 0xa3, 0x55,
-	0xad, 0x5a, 0x9b, 0x55, 0xed, 0x0a, 0x52, 0x30, 0x82, 0xe9, 0x06, 0xa9, 0x58, 0xd7, 0xf4, 0x5b,
-	0x42, 0x93, 0xc0, 0x73, 0x30, 0xd3, 0x32, 0x2a, 0xd7, 0x5b, 0xfa, 0x50, 0x99, 0xc2, 0x0b, 0x30,
-	0x5b, 0x6b, 0x55, 0xcd, 0x4a, 0xa3, 0x3a, 0xa2, 0xce, 0xe2, 0x02, 0xe4, 0xd6, 0xab, 0xf5, 0x75,
-	0x21, 0x22, 0xd6, 0x7e, 0xcb, 0x68, 0x56, 0xae, 0x18, 0xfa, 0x86, 0x50, 0x2d, 0x31, 0xd5, 0x6d,
-	0x9d, 0xd4, 0x37, 0x2b, 0x51, 0x97, 0x97, 0x31, 0x82, 0xfc, 0x7a, 0xc5, 0xd0, 0x88, 0x6c, 0xe5,
-	0x81, 0x82, 0x8b, 0x90, 0xd3, 0x8d, 0x56, 0x4d, 0xca, 0x09, 0x5c, 0x82, 0x39, 0xad, 0x65, 0xd6,
-	0xad, 0x8a, 0x51, 0x26, 0x7a, 0x4d, 0x37, 0x4c, 0x69, 0x49, 0xe1, 0x39, 0x28, 0x9a, 0x95, 0x9a,
-	0xde, 0x34, 0xb5, 0x5a, 0x43, 0x2a, 0xd9, 0x28, 0xb2, 0x4d, 0x3d, 0xf2, 0x41, 0x78, 0x11, 0x16,
-	0x8c, 0xba, 0x25, 0x5f, 0xda, 0x59, 0x37, 0xb4, 0x6a, 0x4b, 0x97, 0xb6, 0x25, 0x7c, 0x02, 0x70,
-	0xdd, 0xb0, 0x5a, 0x8d, 0x0d, 0xcd, 0xd4, 0x2d, 0xa3, 0x7e, 0x53, 0x1a, 0x2e, 0xe3, 0x22, 0x64,
-	0x87, 0x23, 0x78, 0xc0, 0x58, 0x28, 0x34, 0x34, 0x62, 0x0e, 0xc1, 0x3e, 0x78, 0xc0, 0xc8, 0x82,
-	0x2b, 0xa4, 0xde, 0x6a, 0x0c, 0xdd, 0x66, 0x21, 0x2f, 0xc9, 0x92, 0xaa, 0x14, 0x53, 0xad, 0x57,
-	0x8c, 0x72, 0x3c, 0xbe, 0x07, 0xd9, 0xc5, 0x04, 0x52, 0x56, 0x76, 0x20, 0xc5, 0xa7, 0x23, 0x0b,
-	0x29, 0xa3, 0x6e, 0xe8, 0xe8, 0x18, 0x9e, 0x01, 0xa8, 0x34, 0x2b, 0x86, 0xa9, 0x5f, 0x21, 0x5a,
-	0x95, 0xc1, 0xe6, 0x8a, 0x88, 0x40, 0x86, 0x76, 0x1a, 0xa6, 0x2a, 0xcd, 0xcd, 0x6a, 0x5d, 0x33,
-	0x25, 0xcc, 0x4a, 0xf3, 0x7a, 0xab, 0x6e, 0x32, 0x23, 0xc2, 0x79, 0xc8, 0x54, 0x9a, 0xa6, 0xfe,
-	0x8e, 0xc9, 0x70, 0x71, 0x9b, 0x60, 0x15, 0x3d, 0xb8, 0xbc, 0xf2, 0x51, 0x12, 0x52, 0xfc, 0xd1,
-	0x72, 0x01, 0x72, 0x7c, 0xb6, 0xcd, 0x5b, 0x0d, 0xd6, 0x65, 0x0e, 0x52, 0x15, 0xc3, 0xbc, 0x84,
-	0xfe, 0x2c, 0x81, 0x01, 0xd2, 0x2d, 0x5e, 0xfe, 0xf3, 0x0c, 0x2b, 0x57, 0x0c, 0xf3, 0xdc, 0x45,
-	0xf4, 0x6e, 0x82, 0x35, 0xdb, 0x12, 0xc2, 0x5f, 0x44, 0x86, 0xb5, 0x0b, 0xe8, 0xbd, 0xd8, 0xb0,
-	0x76, 0x01, 0xfd, 0x65, 0x64, 0x38, 0xbf, 0x86, 0xde, 0x8f, 0x0d, 0xe7, 0xd7, 0xd0, 0x5f, 0x45,
-	0x86, 0x8b, 0x17, 0xd0, 0x5f, 0xc7, 0x86, 0x8b, 0x17, 0xd0, 0xdf, 0x64, 0x18, 0x16, 0x8e, 0xe4,
-	0xfc, 0x1a, 0xfa, 0xdb, 0x6c, 0x2c, 0x5d, 0xbc, 0x80, 0xfe, 0x2e, 0xcb, 0xe6, 0x3f, 0x9e, 0x55,
-	0xf4, 0xf7, 0x88, 0x0d, 0x93, 0x4d, 0x10, 0xfa, 0x07, 0x5e, 0x64, 0x26, 0xf4, 0x8f, 0x88, 0x61,
-	0x64, 0x5a, 0x2e, 0x7e, 0xc0, 0x2d, 0xb7, 0x74, 0x8d, 0xa0, 0x7f, 0xca, 0x88, 0x87, 0x95, 0xe5,
-	0x4a, 0x4d, 0xab, 0x22, 0xcc, 0x6b, 0x30, 0x56, 0xfe, 0xe5, 0x2c, 0x2b, 0xb2, 0xf0, 0x44, 0xff,
-	0xda, 0x60, 0x1d, 0xde, 0xd0, 0x48, 0xf9, 0x6d, 0x8d, 0xa0, 0x7f, 0x3b, 0xcb, 0x3a, 0xbc, 0xa1,
-	0x11, 0xc9, 0xd7, 0xbf, 0x37, 0x98, 0x23, 0x37, 0x7d, 0x78, 0x96, 0x0d, 0x5a, 0xea, 0xff, 0xa3,
-	0x81, 0xb3, 0x90, 0x5c, 0xaf, 0x98, 0xe8, 0x23, 0xde, 0x1b, 0x0b, 0x51, 0xf4, 0x9f, 0x88, 0x29,
-	0x9b, 0xba, 0x89, 0x3e, 0x66, 0xca, 0xb4, 0xd9, 0x6a, 0x54, 0x75, 0xf4, 0x1c, 0x1b, 0xdc, 0x15,
-	0xbd, 0x5e, 0xd3, 0x4d, 0x72, 0x0b, 0xfd, 0x17, 0x77, 0xbf, 0xda, 0xac, 0x1b, 0xe8, 0x13, 0x84,
-	0x8b, 0x00, 0xfa, 0x3b, 0x0d, 0xa2, 0x37, 0x9b, 0x95, 0xba, 0x81, 0x5e, 0x5c, 0xd9, 0x04, 0x74,
-	0x30, 0x1d, 0x30, 0x00, 0x2d, 0xe3, 0x9a, 0x51, 0xbf, 0x69, 0xa0, 0x63, 0x4c, 0x68, 0x10, 0xbd,
-	0xa1, 0x11, 0x1d, 0x29, 0x18, 0x20, 0x23, 0x9f, 0x6b, 0x26, 0xf0, 0x34, 0x64, 0x49, 0xbd, 0x5a,
-	0x5d, 0xd7, 0xca, 0xd7, 0x50, 0x72, 0xfd, 0x75, 0x98, 0x71, 0xbc, 0xd5, 0x3d, 0x27, 0xa4, 0x41,
-	0x20, 0x9e, 0xc5, 0xdf, 0x56, 0xa5, 0xe4, 0x78, 0x67, 0x44, 0xe9, 0x4c, 0xd7, 0x3b, 0xb3, 0x17,
-	0x9e, 0xe1, 0xd6, 0x33, 0x3c, 0x63, 0x6c, 0x65, 0xb8, 0x70, 0xfe, 0xd7, 0x01, 0x00, 0x00, 0xff,
-	0xff, 0xc2, 0xa3, 0x7f, 0x77, 0x74, 0x2f, 0x00, 0x00,
+	// 3561 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x5b, 0xcb, 0x93, 0x1c, 0xc9,
+	0x59, 0x57, 0xf5, 0x6b, 0xba, 0xbf, 0x9e, 0xee, 0xc9, 0xc9, 0x99, 0x91, 0x4a, 0xb3, 0xde, 0xdd,
+	0x71, 0xaf, 0xd7, 0x2b, 0xcb, 0x78, 0xa4, 0x1d, 0x69, 0x85, 0x58, 0x1b, 0xb3, 0x35, 0x3d, 0x35,
+	0xda, 0x96, 0xfa, 0xa5, 0xec, 0x6e, 0xc9, 0xda, 0x20, 0xa2, 0x22, 0x55, 0x9d, 0xea, 0xa9, 0x98,
+	0xea, 0xaa, 0x56, 0x55, 0xb5, 0xa4, 0xb9, 0x09, 0x8c, 0x31, 0x6f, 0xcc, 0xd3, 0x18, 0x07, 0x0e,
+	0x6e, 0x70, 0xe2, 0x8f, 0xe0, 0xb0, 0x07, 0x0e, 0x44, 0x70, 0x04, 0x0e, 0xc0, 0x81, 0x80, 0x0b,
+	0x86, 0xe0, 0xc0, 0x81, 0x03, 0x41, 0xe4, 0xa3, 0xaa, 0xab, 0x67, 0x7a, 0xa5, 0xb1, 0x8c, 0x83,
+	0x90, 0x76, 0x6f, 0xf9, 0x3d, 0xf2, 0xf1, 0xfd, 0xf2, 0xab, 0xef, 0xcb, 0xce, 0xfc, 0x1a, 0xca,
+	0x0f, 0xa7, 0x2c, 0x38, 0xda, 0x9e, 0x04, 0x7e, 0xe4, 0xe3, 0xbc, 0x20, 0x36, 0xab, 0x91, 0x3f,
+	0xf1, 0x87, 0x34, 0xa2, 0x92, 0xbd, 0x59, 0x7e, 0x14, 0x05, 0x13, 0x5b, 0x12, 0xb5, 0x6f, 0x69,
+	0x50, 0xe8, 0xd3, 0x60, 0xc4, 0x22, 0xbc, 0x09, 0xc5, 0x43, 0x76, 0x14, 0x4e, 0xa8, 0xcd, 0x74,
+	0x6d, 0x4b, 0xbb, 0x50, 0x22, 0x09, 0x8d, 0xd7, 0x21, 0x1f, 0x1e, 0xd0, 0x60, 0xa8, 0x67, 0x84,
+	0x40, 0x12, 0xf8, 0x3d, 0x28, 0x47, 0xf4, 0xbe, 0xcb, 0x22, 0x2b, 0x3a, 0x9a, 0x30, 0x3d, 0xbb,
+	0xa5, 0x5d, 0xa8, 0xee, 0xac, 0x6f, 0x27, 0xf3, 0xf5, 0x85, 0xb0, 0x7f, 0x34, 0x61, 0x04, 0xa2,
+	0xa4, 0x8d, 0x31, 0xe4, 0x6c, 0xe6, 0xba, 0x7a, 0x4e, 0x8c, 0x25, 0xda, 0xb5, 0x3d, 0xa8, 0xde,
+	0xe9, 0xdf, 0xa0, 0x11, 0xab, 0x53, 0xd7, 0x65, 0x41, 0x63, 0x8f, 0x2f, 0x67, 0x1a, 0xb2, 0xc0,
+	0xa3, 0xe3, 0x64, 0x39, 0x31, 0x8d, 0xcf, 0x42, 0x61, 0x14, 0xf8, 0xd3, 0x49, 0xa8, 0x67, 0xb6,
+	0xb2, 0x17, 0x4a, 0x44, 0x51, 0xb5, 0x9f, 0x07, 0x30, 0x1f, 0x31, 0x2f, 0xea, 0xfb, 0x87, 0xcc,
+	0xc3, 0x9f, 0x83, 0x52, 0xe4, 0x8c, 0x59, 0x18, 0xd1, 0xf1, 0x44, 0x0c, 0x91, 0x25, 0x33, 0xc6,
+	0x27, 0x98, 0xb4, 0x09, 0xc5, 0x89, 0x1f, 0x3a, 0x91, 0xe3, 0x7b, 0xc2, 0x9e, 0x12, 0x49, 0xe8,
+	0xda, 0xd7, 0x21, 0x7f, 0x87, 0xba, 0x53, 0x86, 0xdf, 0x84, 0x9c, 0x30, 0x58, 0x13, 0x06, 0x97,
+	0xb7, 0x25, 0xe8, 0xc2, 0x4e, 0x21, 0xe0, 0x63, 0x3f, 0xe2, 0x9a, 0x62, 0xec, 0x65, 0x22, 0x89,
+	0xda, 0x21, 0x2c, 0xef, 0x3a, 0xde, 0xf0, 0x0e, 0x0d, 0x1c, 0x0e, 0xc6, 0x0b, 0x0e, 0x83, 0xbf,
+	0x00, 0x05, 0xd1, 0x08, 0xf5, 0xec, 0x56, 0xf6, 0x42, 0x79, 0x67, 0x59, 0x75, 0x14, 0x6b, 0x23,
+	0x4a, 0x56, 0xfb, 0x4b, 0x0d, 0x60, 0xd7, 0x9f, 0x7a, 0xc3, 0xdb, 0x5c, 0x88, 0x11, 0x64, 0xc3,
+	0x87, 0xae, 0x02, 0x92, 0x37, 0xf1, 0x2d, 0xa8, 0xde, 0x77, 0xbc, 0xa1, 0xf5, 0x48, 0x2d, 0x47,
+	0x62, 0x59, 0xde, 0xf9, 0x82, 0x1a, 0x6e, 0xd6, 0x79, 0x3b, 0xbd, 0xea, 0xd0, 0xf4, 0xa2, 0xe0,
+	0x88, 0x54, 0xee, 0xa7, 0x79, 0x9b, 0x03, 0xc0, 0x27, 0x95, 0xf8, 0xa4, 0x87, 0xec, 0x28, 0x9e,
+	0xf4, 0x90, 0x1d, 0xe1, 0x2f, 0xa5, 0x2d, 0x2a, 0xef, 0xac, 0xc5, 0x73, 0xa5, 0xfa, 0x2a, 0x33,
+	0xdf, 0xcf, 0x5c, 0xd7, 0x6a, 0xff, 0x96, 0x87, 0xaa, 0xf9, 0x84, 0xd9, 0xd3, 0x88, 0x75, 0x26,
+	0x7c, 0x0f, 0x42, 0xdc, 0x82, 0x15, 0xc7, 0xb3, 0xdd, 0xe9, 0x90, 0x0d, 0xad, 0x07, 0x0e, 0x73,
+	0x87, 0xa1, 0xf0, 0xa3, 0x6a, 0xb2, 0xee, 0x79, 0xfd, 0xed, 0x86, 0x52, 0xde, 0x17, 0xba, 0xa4,
+	0xea, 0xcc, 0xd1, 0xf8, 0x22, 0xac, 0xda, 0xae, 0xc3, 0xbc, 0xc8, 0x7a, 0xc0, 0xed, 0xb5, 0x02,
+	0xff, 0x71, 0xa8, 0xe7, 0xb7, 0xb4, 0x0b, 0x45, 0xb2, 0x22, 0x05, 0xfb, 0x9c, 0x4f, 0xfc, 0xc7,
+	0x21, 0x7e, 0x1f, 0x8a, 0x8f, 0xfd, 0xe0, 0xd0, 0xf5, 0xe9, 0x50, 0x2f, 0x88, 0x39, 0xdf, 0x58,
+	0x3c, 0xe7, 0x5d, 0xa5, 0x45, 0x12, 0x7d, 0x7c, 0x01, 0x50, 0xf8, 0xd0, 0xb5, 0x42, 0xe6, 0x32,
+	0x3b, 0xb2, 0x5c, 0x67, 0xec, 0x44, 0x7a, 0x51, 0xb8, 0x64, 0x35, 0x7c, 0xe8, 0xf6, 0x04, 0xbb,
+	0xc9, 0xb9, 0xd8, 0x82, 0x8d, 0x28, 0xa0, 0x5e, 0x48, 0x6d, 0x3e, 0x98, 0xe5, 0x84, 0xbe, 0x4b,
+	0x79, 0x4b, 0x2f, 0x89, 0x29, 0x2f, 0x2e, 0x9e, 0xb2, 0x3f, 0xeb, 0xd2, 0x88, 0x7b, 0x90, 0xf5,
+	0x68, 0x01, 0x17, 0xbf, 0x0b, 0x1b, 0xe1, 0xa1, 0x33, 0xb1, 0xc4, 0x38, 0xd6, 0xc4, 0xa5, 0x9e,
+	0x65, 0x53, 0xfb, 0x80, 0xe9, 0x20, 0xcc, 0xc6, 0x5c, 0x28, 0xf6, 0xbd, 0xeb, 0x52, 0xaf, 0xce,
+	0x25, 0xf8, 0x0a, 0x9c, 0x1d, 0xd3, 0x27, 0x56, 0xc0, 0x26, 0xae, 0x63, 0x8b, 0x51, 0x2c, 0x97,
+	0x8e, 0xac, 0x71, 0xa8, 0x97, 0x85, 0x0d, 0x6b, 0x63, 0xfa, 0x84, 0xcc, 0x84, 0x4d, 0x3a, 0x6a,
+	0x85, 0xb5, 0xaf, 0x42, 0x75, 0x1e, 0x7c, 0xbc, 0x0a, 0x95, 0xfe, 0xbd, 0xae, 0x69, 0x19, 0xed,
+	0x3d, 0xab, 0x6d, 0xb4, 0x4c, 0x74, 0x06, 0x57, 0xa0, 0x24, 0x58, 0x9d, 0x76, 0xf3, 0x1e, 0xd2,
+	0xf0, 0x12, 0x64, 0x8d, 0x66, 0x13, 0x65, 0x6a, 0xd7, 0xa1, 0x18, 0xa3, 0x88, 0x57, 0xa0, 0x3c,
+	0x68, 0xf7, 0xba, 0x66, 0xbd, 0xb1, 0xdf, 0x30, 0xf7, 0xd0, 0x19, 0x5c, 0x84, 0x5c, 0xa7, 0xd9,
+	0xef, 0x22, 0x4d, 0xb6, 0x8c, 0x2e, 0xca, 0xf0, 0x9e, 0x7b, 0xbb, 0x06, 0xca, 0xd6, 0xfe, 0x4c,
+	0x83, 0xf5, 0x45, 0x68, 0xe0, 0x32, 0x2c, 0xed, 0x99, 0xfb, 0xc6, 0xa0, 0xd9, 0x47, 0x67, 0xf0,
+	0x1a, 0xac, 0x10, 0xb3, 0x6b, 0x1a, 0x7d, 0x63, 0xb7, 0x69, 0x5a, 0xc4, 0x34, 0xf6, 0x90, 0x86,
+	0x31, 0x54, 0x79, 0xcb, 0xaa, 0x77, 0x5a, 0xad, 0x46, 0xbf, 0x6f, 0xee, 0xa1, 0x0c, 0x5e, 0x07,
+	0x24, 0x78, 0x83, 0xf6, 0x8c, 0x9b, 0xc5, 0x08, 0x96, 0x7b, 0x26, 0x69, 0x18, 0xcd, 0xc6, 0x47,
+	0x7c, 0x00, 0x94, 0xc3, 0x9f, 0x87, 0xd7, 0xeb, 0x9d, 0x76, 0xaf, 0xd1, 0xeb, 0x9b, 0xed, 0xbe,
+	0xd5, 0x6b, 0x1b, 0xdd, 0xde, 0x87, 0x9d, 0xbe, 0x18, 0x59, 0x1a, 0x97, 0xc7, 0x55, 0x00, 0x63,
+	0xd0, 0xef, 0xc8, 0x71, 0x50, 0xe1, 0x66, 0xae, 0xa8, 0xa1, 0xcc, 0xcd, 0x5c, 0x31, 0x83, 0xb2,
+	0x37, 0x73, 0xc5, 0x2c, 0xca, 0xd5, 0xbe, 0x9b, 0x81, 0xbc, 0xc0, 0x8a, 0xc7, 0xc8, 0x54, 0xe4,
+	0x13, 0xed, 0x24, 0x5e, 0x64, 0x9e, 0x11, 0x2f, 0x44, 0x98, 0x55, 0x91, 0x4b, 0x12, 0xf8, 0x35,
+	0x28, 0xf9, 0xc1, 0xc8, 0x92, 0x12, 0x19, 0x73, 0x8b, 0x7e, 0x30, 0x12, 0xc1, 0x99, 0xc7, 0x3b,
+	0x1e, 0xaa, 0xef, 0xd3, 0x90, 0x09, 0xb7, 0x2f, 0x91, 0x84, 0xc6, 0xe7, 0x81, 0xeb, 0x59, 0x62,
+	0x1d, 0x05, 0x21, 0x5b, 0xf2, 0x83, 0x51, 0x9b, 0x2f, 0xe5, 0x2d, 0xa8, 0xd8, 0xbe, 0x3b, 0x1d,
+	0x7b, 0x96, 0xcb, 0xbc, 0x51, 0x74, 0xa0, 0x2f, 0x6d, 0x69, 0x17, 0x2a, 0x64, 0x59, 0x32, 0x9b,
+	0x82, 0x87, 0x75, 0x58, 0xb2, 0x0f, 0x68, 0x10, 0x32, 0xe9, 0xea, 0x15, 0x12, 0x93, 0x62, 0x56,
+	0x66, 0x3b, 0x63, 0xea, 0x86, 0xc2, 0xad, 0x2b, 0x24, 0xa1, 0xb9, 0x11, 0x0f, 0x5c, 0x3a, 0x0a,
+	0x85, 0x3b, 0x56, 0x88, 0x24, 0x6a, 0x3f, 0x0d, 0x59, 0xe2, 0x3f, 0xe6, 0x43, 0xca, 0x09, 0x43,
+	0x5d, 0xdb, 0xca, 0x5e, 0xc0, 0x24, 0x26, 0x79, 0x4a, 0x50, 0x51, 0x51, 0x06, 0xcb, 0x38, 0x0e,
+	0x7e, 0x5f, 0x83, 0xb2, 0xf0, 0x66, 0xc2, 0xc2, 0xa9, 0x1b, 0xf1, 0xe8, 0xa9, 0xc2, 0x86, 0x36,
+	0x17, 0x3d, 0x05, 0xec, 0x44, 0xc9, 0xb8, 0x7d, 0x3c, 0x12, 0x58, 0xf4, 0xc1, 0x03, 0x66, 0x47,
+	0x4c, 0x26, 0x89, 0x1c, 0x59, 0xe6, 0x4c, 0x43, 0xf1, 0x38, 0xb0, 0x8e, 0x17, 0xb2, 0x20, 0xb2,
+	0x9c, 0xa1, 0x80, 0x3c, 0x47, 0x8a, 0x92, 0xd1, 0x18, 0xe2, 0x37, 0x20, 0x27, 0x62, 0x49, 0x4e,
+	0xcc, 0x02, 0x6a, 0x16, 0xe2, 0x3f, 0x26, 0x82, 0x7f, 0x33, 0x57, 0xcc, 0xa3, 0x42, 0xed, 0x6b,
+	0xb0, 0x2c, 0x16, 0x77, 0x97, 0x06, 0x9e, 0xe3, 0x8d, 0x44, 0x6a, 0xf4, 0x87, 0x72, 0xdb, 0x2b,
+	0x44, 0xb4, 0xb9, 0xcd, 0x63, 0x16, 0x86, 0x74, 0xc4, 0x54, 0xaa, 0x8a, 0xc9, 0xda, 0x9f, 0x66,
+	0xa1, 0xdc, 0x8b, 0x02, 0x46, 0xc7, 0x22, 0xeb, 0xe1, 0xaf, 0x01, 0x84, 0x11, 0x8d, 0xd8, 0x98,
+	0x79, 0x51, 0x6c, 0xdf, 0xe7, 0xd4, 0xcc, 0x29, 0xbd, 0xed, 0x5e, 0xac, 0x44, 0x52, 0xfa, 0x78,
+	0x07, 0xca, 0x8c, 0x8b, 0xad, 0x88, 0x67, 0x4f, 0x15, 0xa1, 0x57, 0xe3, 0x70, 0x93, 0xa4, 0x55,
+	0x02, 0x2c, 0x69, 0x6f, 0xfe, 0x20, 0x03, 0xa5, 0x64, 0x34, 0x6c, 0x40, 0xd1, 0xa6, 0x11, 0x1b,
+	0xf9, 0xc1, 0x91, 0x4a, 0x6a, 0x6f, 0x3f, 0x6b, 0xf6, 0xed, 0xba, 0x52, 0x26, 0x49, 0x37, 0xfc,
+	0x3a, 0xc8, 0x93, 0x82, 0xf4, 0x3a, 0x69, 0x6f, 0x49, 0x70, 0x84, 0xdf, 0xbd, 0x0f, 0x78, 0x12,
+	0x38, 0x63, 0x1a, 0x1c, 0x59, 0x87, 0xec, 0x28, 0x4e, 0x00, 0xd9, 0x05, 0x3b, 0x89, 0x94, 0xde,
+	0x2d, 0x76, 0xa4, 0xa2, 0xcf, 0xf5, 0xf9, 0xbe, 0xca, 0x5b, 0x4e, 0xee, 0x4f, 0xaa, 0xa7, 0x48,
+	0xa9, 0x61, 0x9c, 0x3c, 0xf3, 0xc2, 0xb1, 0x78, 0xb3, 0xf6, 0x0e, 0x14, 0xe3, 0xc5, 0xe3, 0x12,
+	0xe4, 0xcd, 0x20, 0xf0, 0x03, 0x74, 0x46, 0x04, 0xa1, 0x56, 0x53, 0xc6, 0xb1, 0xbd, 0x3d, 0x1e,
+	0xc7, 0xfe, 0x29, 0x93, 0x64, 0x30, 0xc2, 0x1e, 0x4e, 0x59, 0x18, 0xe1, 0x9f, 0x83, 0x35, 0x26,
+	0x5c, 0xc8, 0x79, 0xc4, 0x2c, 0x5b, 0x1c, 0x77, 0xb8, 0x03, 0x69, 0x02, 0xef, 0x95, 0x6d, 0x79,
+	0x3a, 0x8b, 0x8f, 0x41, 0x64, 0x35, 0xd1, 0x55, 0xac, 0x21, 0x36, 0x61, 0xcd, 0x19, 0x8f, 0xd9,
+	0xd0, 0xa1, 0x51, 0x7a, 0x00, 0xb9, 0x61, 0x1b, 0xf1, 0x69, 0x60, 0xee, 0x34, 0x45, 0x56, 0x93,
+	0x1e, 0xc9, 0x30, 0x6f, 0x43, 0x21, 0x12, 0x27, 0x3f, 0xe1, 0xbb, 0xe5, 0x9d, 0x4a, 0x1c, 0x50,
+	0x04, 0x93, 0x28, 0x21, 0x7e, 0x07, 0xe4, 0x39, 0x52, 0x84, 0x8e, 0x99, 0x43, 0xcc, 0x8e, 0x07,
+	0x44, 0xca, 0xf1, 0xdb, 0x50, 0x9d, 0x4b, 0x5c, 0x43, 0x01, 0x58, 0x96, 0x54, 0x52, 0xdc, 0xc6,
+	0x10, 0x5f, 0x82, 0x25, 0x5f, 0x26, 0x2d, 0xbd, 0x30, 0xb7, 0xe2, 0xf9, 0x8c, 0x46, 0x62, 0x2d,
+	0xfc, 0x26, 0x94, 0x03, 0x16, 0xb2, 0xe0, 0x11, 0x1b, 0xf2, 0x41, 0x97, 0xc4, 0xa0, 0x10, 0xb3,
+	0x1a, 0xc3, 0xda, 0xcf, 0xc2, 0x4a, 0x02, 0x71, 0x38, 0xf1, 0xbd, 0x90, 0xe1, 0x8b, 0x50, 0x08,
+	0xc4, 0xf7, 0xae, 0x60, 0xc5, 0x6a, 0x8e, 0x54, 0x24, 0x20, 0x4a, 0xa3, 0x36, 0x84, 0x15, 0xc9,
+	0xb9, 0xeb, 0x44, 0x07, 0x62, 0x27, 0xf1, 0xdb, 0x90, 0x67, 0xbc, 0x71, 0x6c, 0x53, 0x48, 0xb7,
+	0x2e, 0xe4, 0x44, 0x4a, 0x53, 0xb3, 0x64, 0x9e, 0x3b, 0xcb, 0x7f, 0x64, 0x60, 0x4d, 0xad, 0x72,
+	0x97, 0x46, 0xf6, 0xc1, 0x4b, 0xea, 0x0d, 0x5f, 0x86, 0x25, 0xce, 0x77, 0x92, 0x2f, 0x67, 0x81,
+	0x3f, 0xc4, 0x1a, 0xdc, 0x23, 0x68, 0x68, 0xa5, 0xb6, 0x5f, 0x9d, 0xac, 0x2a, 0x34, 0x4c, 0x65,
+	0xe8, 0x05, 0x8e, 0x53, 0x78, 0x8e, 0xe3, 0x2c, 0x9d, 0xc6, 0x71, 0x6a, 0x7b, 0xb0, 0x3e, 0x8f,
+	0xb8, 0x72, 0x8e, 0x9f, 0x82, 0x25, 0xb9, 0x29, 0x71, 0x8c, 0x5c, 0xb4, 0x6f, 0xb1, 0x4a, 0xed,
+	0xe3, 0x0c, 0xac, 0xab, 0xf0, 0xf5, 0xe9, 0xf8, 0x8e, 0x53, 0x38, 0xe7, 0x4f, 0xf5, 0x81, 0x9e,
+	0x6e, 0xff, 0x6a, 0x75, 0xd8, 0x38, 0x86, 0xe3, 0x0b, 0x7c, 0xac, 0x3f, 0xd4, 0x60, 0x79, 0x97,
+	0x8d, 0x1c, 0xef, 0x25, 0xdd, 0x85, 0x14, 0xb8, 0xb9, 0x53, 0x39, 0xf1, 0x04, 0x2a, 0xca, 0x5e,
+	0x85, 0xd6, 0x49, 0xb4, 0xb5, 0x45, 0x5f, 0xcb, 0x75, 0x58, 0x56, 0xbf, 0xcd, 0xa9, 0xeb, 0xd0,
+	0x30, 0xb1, 0xe7, 0xd8, 0x8f, 0x73, 0x83, 0x0b, 0x49, 0x39, 0x9a, 0x11, 0xb5, 0x7f, 0xd6, 0xa0,
+	0x52, 0xf7, 0xc7, 0x63, 0x27, 0x7a, 0x49, 0x31, 0x3e, 0x89, 0x50, 0x6e, 0x91, 0x3f, 0xbe, 0x0b,
+	0xd5, 0xd8, 0x4c, 0x05, 0xed, 0xb1, 0x4c, 0xa3, 0x9d, 0xc8, 0x34, 0xff, 0xa2, 0xc1, 0x0a, 0xf1,
+	0x5d, 0xf7, 0x3e, 0xb5, 0x0f, 0x5f, 0x6d, 0x70, 0xae, 0x00, 0x9a, 0x19, 0x7a, 0x5a, 0x78, 0xfe,
+	0x5b, 0x83, 0x6a, 0x37, 0x60, 0x13, 0x1a, 0xb0, 0x57, 0x1a, 0x1d, 0x7e, 0x4c, 0x1f, 0x46, 0xea,
+	0x80, 0x53, 0x22, 0xa2, 0x5d, 0x5b, 0x85, 0x95, 0xc4, 0x76, 0x09, 0x58, 0xed, 0xef, 0x34, 0xd8,
+	0x90, 0x2e, 0xa6, 0x24, 0xc3, 0x97, 0x14, 0x96, 0xd8, 0xde, 0x5c, 0xca, 0x5e, 0x1d, 0xce, 0x1e,
+	0xb7, 0x4d, 0x99, 0xfd, 0xcd, 0x0c, 0x9c, 0x8b, 0x9d, 0xe7, 0x25, 0x37, 0xfc, 0xc7, 0xf0, 0x87,
+	0x4d, 0xd0, 0x4f, 0x82, 0xa0, 0x10, 0xfa, 0x4e, 0x06, 0xf4, 0x7a, 0xc0, 0x68, 0xc4, 0x52, 0xe7,
+	0xa0, 0x57, 0xc7, 0x37, 0xf0, 0xbb, 0xb0, 0x3c, 0xa1, 0x41, 0xe4, 0xd8, 0xce, 0x84, 0xf2, 0x9f,
+	0xa2, 0xf9, 0xad, 0xec, 0xc9, 0x01, 0xe6, 0x54, 0x6a, 0xaf, 0xc1, 0xf9, 0x05, 0x88, 0x28, 0xbc,
+	0xfe, 0x47, 0x03, 0xdc, 0x8b, 0x68, 0x10, 0x7d, 0x0a, 0xf2, 0xd2, 0x42, 0x67, 0xda, 0x80, 0xb5,
+	0x39, 0xfb, 0xd3, 0xb8, 0xb0, 0xe8, 0x53, 0x91, 0x92, 0x3e, 0x11, 0x97, 0xb4, 0xfd, 0x0a, 0x97,
+	0x7f, 0xd0, 0x60, 0xb3, 0xee, 0xcb, 0xcb, 0xc7, 0x57, 0xf2, 0x0b, 0xab, 0xbd, 0x0e, 0xaf, 0x2d,
+	0x34, 0x50, 0x01, 0xf0, 0xf7, 0x1a, 0x9c, 0x25, 0x8c, 0x0e, 0x5f, 0x4d, 0xe3, 0x6f, 0xc3, 0xb9,
+	0x13, 0xc6, 0xa9, 0x33, 0xca, 0x35, 0x28, 0x8e, 0x59, 0x44, 0x87, 0x34, 0xa2, 0xca, 0xa4, 0xcd,
+	0x78, 0xdc, 0x99, 0x76, 0x4b, 0x69, 0x90, 0x44, 0xb7, 0xf6, 0x8f, 0x19, 0x58, 0x13, 0xe7, 0xec,
+	0xcf, 0x7e, 0xe4, 0x9d, 0xea, 0x16, 0xa6, 0x70, 0xfc, 0xf0, 0xc7, 0x15, 0x26, 0x01, 0xb3, 0xe2,
+	0xdb, 0x81, 0x25, 0xf1, 0x30, 0x07, 0x93, 0x80, 0xdd, 0x96, 0x9c, 0xda, 0x5f, 0x69, 0xb0, 0x3e,
+	0x0f, 0x71, 0xf2, 0x8b, 0xe6, 0xff, 0xfa, 0xb6, 0x65, 0x41, 0x48, 0xc9, 0x9e, 0xe6, 0x47, 0x52,
+	0xee, 0xd4, 0x3f, 0x92, 0xfe, 0x3a, 0x03, 0x7a, 0xda, 0x98, 0xcf, 0xee, 0x74, 0xe6, 0xef, 0x74,
+	0x7e, 0xd4, 0x5b, 0xbe, 0xda, 0xdf, 0x68, 0x70, 0x7e, 0x01, 0xa0, 0x3f, 0x9a, 0x8b, 0xa4, 0x6e,
+	0x76, 0x32, 0xcf, 0xbd, 0xd9, 0xf9, 0xc9, 0x3b, 0xc9, 0xdf, 0x6a, 0xb0, 0xde, 0x92, 0x77, 0xf5,
+	0xf2, 0xe6, 0xe3, 0xe5, 0x8d, 0xc1, 0xe2, 0x3a, 0x3e, 0x37, 0x7b, 0x8c, 0xe2, 0xb7, 0x39, 0xc7,
+	0x4c, 0x7b, 0x81, 0xdb, 0x9c, 0xff, 0xd2, 0x60, 0x55, 0x8d, 0x62, 0xd8, 0x87, 0xaf, 0x0e, 0x3a,
+	0xf8, 0x0d, 0xc8, 0x3a, 0xc3, 0xf8, 0xdc, 0x3b, 0xff, 0x40, 0xcf, 0x05, 0xb5, 0x0f, 0x00, 0xa7,
+	0xed, 0x7e, 0x01, 0xe8, 0xfe, 0x35, 0x03, 0x1b, 0x44, 0x46, 0xdf, 0xcf, 0xde, 0x17, 0x7e, 0xdc,
+	0xf7, 0x85, 0x67, 0x27, 0xae, 0x8f, 0xc5, 0x61, 0x6a, 0x1e, 0xea, 0x9f, 0x5c, 0xea, 0x3a, 0x96,
+	0x68, 0xb3, 0x27, 0x12, 0xed, 0x8b, 0xc7, 0xa3, 0x8f, 0x33, 0xb0, 0xa9, 0x0c, 0xf9, 0xec, 0xac,
+	0x73, 0x7a, 0x8f, 0x28, 0x9c, 0xf0, 0x88, 0xff, 0xd4, 0xe0, 0xb5, 0x85, 0x40, 0xfe, 0xbf, 0x9f,
+	0x68, 0x8e, 0x79, 0x4f, 0xee, 0xb9, 0xde, 0x93, 0x3f, 0xb5, 0xf7, 0x7c, 0x3b, 0x03, 0x55, 0xc2,
+	0x5c, 0x46, 0xc3, 0x57, 0xfc, 0x76, 0xef, 0x18, 0x86, 0xf9, 0x13, 0xf7, 0x9c, 0xab, 0xb0, 0x92,
+	0x00, 0xa1, 0x7e, 0x70, 0x89, 0x1f, 0xe8, 0x3c, 0x0f, 0x7e, 0xc8, 0xa8, 0x1b, 0xc5, 0x27, 0xc1,
+	0xda, 0xbf, 0x17, 0xa1, 0x42, 0x38, 0xc7, 0x19, 0x33, 0xfe, 0xee, 0x1d, 0xe2, 0xcf, 0xc3, 0xf2,
+	0x81, 0x50, 0xb1, 0x66, 0x1e, 0x52, 0x22, 0x65, 0xc9, 0x93, 0xaf, 0x8f, 0x3b, 0xb0, 0x11, 0x32,
+	0xdb, 0xf7, 0x86, 0xa1, 0x75, 0x9f, 0x1d, 0xf0, 0x1a, 0xad, 0x31, 0x0d, 0x23, 0x16, 0x08, 0x58,
+	0x2a, 0x64, 0x4d, 0x09, 0x77, 0x85, 0xac, 0x25, 0x44, 0xf8, 0x32, 0xac, 0xdf, 0x77, 0x3c, 0xd7,
+	0x1f, 0xf1, 0x82, 0x9e, 0x23, 0x16, 0x84, 0x96, 0xed, 0x4f, 0x3d, 0x89, 0x47, 0x9e, 0x60, 0x29,
+	0xeb, 0x4a, 0x51, 0x9d, 0x4b, 0xf0, 0x47, 0x70, 0x71, 0xe1, 0x2c, 0xd6, 0x03, 0xc7, 0x8d, 0x58,
+	0xc0, 0x86, 0xe9, 0x72, 0x1f, 0x05, 0xd4, 0x17, 0x17, 0x4c, 0xbd, 0xaf, 0xd4, 0x53, 0xf5, 0x3f,
+	0xbc, 0x32, 0xc2, 0x9e, 0x4c, 0xad, 0xa9, 0x28, 0x5a, 0xe0, 0xf8, 0x69, 0xa4, 0x68, 0x4f, 0xa6,
+	0x03, 0x4e, 0xf3, 0xd7, 0xf4, 0x87, 0x13, 0x19, 0x9c, 0x35, 0xc2, 0x9b, 0xf8, 0x4b, 0xb0, 0xaa,
+	0x8a, 0x91, 0x7c, 0xdf, 0xb5, 0x1c, 0xcf, 0x9a, 0x86, 0x4c, 0xbd, 0xf3, 0x56, 0x85, 0xa0, 0xeb,
+	0xfb, 0x6e, 0xc3, 0x1b, 0x84, 0x0c, 0x6f, 0xc3, 0x5a, 0x4a, 0xd5, 0xa6, 0x13, 0x6a, 0x3b, 0xd1,
+	0x91, 0x2a, 0xa5, 0x5a, 0x4d, 0x94, 0xeb, 0x4a, 0x80, 0xdf, 0x83, 0x73, 0xe9, 0x2d, 0x4f, 0x4f,
+	0x50, 0x12, 0x7d, 0xd2, 0x35, 0x52, 0xb3, 0x69, 0xde, 0x87, 0xf3, 0x27, 0xba, 0x25, 0x93, 0x81,
+	0xe8, 0x78, 0xee, 0x58, 0xc7, 0x64, 0xca, 0xcb, 0xb0, 0x2e, 0x4b, 0x18, 0x42, 0xfb, 0x80, 0x8d,
+	0xa9, 0x65, 0x1f, 0x50, 0x6f, 0xc4, 0x86, 0x7a, 0x59, 0x84, 0x11, 0x2c, 0x64, 0x3d, 0x21, 0xaa,
+	0x4b, 0x09, 0xfe, 0x32, 0xac, 0x8a, 0xc1, 0x44, 0x99, 0xa1, 0x15, 0x46, 0x34, 0x9a, 0x86, 0xfa,
+	0xb2, 0x70, 0x0c, 0x34, 0x13, 0xf4, 0x04, 0x1f, 0xbf, 0x03, 0x2b, 0x81, 0xef, 0x32, 0xcb, 0xf6,
+	0xbd, 0x07, 0xce, 0x90, 0x79, 0x36, 0xd3, 0x2b, 0xc2, 0x2f, 0xaa, 0x9c, 0x5d, 0x4f, 0xb8, 0xb2,
+	0x86, 0xc5, 0x65, 0xd6, 0x90, 0x8d, 0x02, 0x3a, 0x64, 0x43, 0xbd, 0x2a, 0x0e, 0xea, 0xcb, 0x9c,
+	0xb9, 0xa7, 0x78, 0xf8, 0x0d, 0x80, 0x49, 0xe0, 0x8f, 0x7d, 0xb1, 0x2a, 0x7d, 0x45, 0x68, 0xa4,
+	0x38, 0xf8, 0x2b, 0x80, 0x25, 0xc5, 0x57, 0x76, 0xdf, 0xf5, 0xed, 0x43, 0x16, 0x84, 0x3a, 0x12,
+	0xa6, 0xac, 0x26, 0x92, 0x5d, 0x25, 0xe0, 0xe5, 0x1b, 0xbc, 0x30, 0x2c, 0xf4, 0xa7, 0x81, 0xcd,
+	0xf4, 0x55, 0x59, 0xbe, 0xe1, 0xd2, 0x51, 0x4f, 0x30, 0xf0, 0x25, 0x58, 0x9b, 0x7a, 0x01, 0x0b,
+	0x7d, 0x97, 0x7f, 0x5b, 0x13, 0x79, 0x2d, 0x1a, 0xea, 0x58, 0x00, 0x8a, 0x67, 0x22, 0x75, 0x61,
+	0x1a, 0xe2, 0x26, 0xbc, 0xb5, 0xa0, 0x83, 0xc5, 0x8b, 0xd1, 0xe8, 0x88, 0x59, 0xca, 0x1d, 0xf5,
+	0x35, 0x01, 0xc0, 0x9b, 0x27, 0x07, 0x68, 0xd1, 0x27, 0xc6, 0x88, 0xf5, 0xa4, 0x1a, 0x47, 0xe4,
+	0x31, 0xff, 0x59, 0xc1, 0x02, 0x01, 0x32, 0xd3, 0xd7, 0xc5, 0x02, 0x97, 0x15, 0x93, 0x03, 0xcc,
+	0xf0, 0x55, 0x38, 0x9b, 0x28, 0xcd, 0x7d, 0x1f, 0xfa, 0x86, 0x74, 0x98, 0x58, 0x3b, 0xfd, 0x29,
+	0xe0, 0x1b, 0x50, 0xa6, 0x9e, 0xe7, 0x47, 0x54, 0xe6, 0x99, 0xb3, 0xe2, 0xe0, 0x17, 0x57, 0xbf,
+	0xcc, 0x45, 0x80, 0x6d, 0x63, 0xa6, 0x27, 0x6b, 0x29, 0xd3, 0x3d, 0x37, 0xbf, 0x0e, 0xe8, 0xb8,
+	0xc2, 0x82, 0x3a, 0xca, 0xb9, 0xca, 0xd0, 0x52, 0xba, 0x64, 0xf2, 0x87, 0x1a, 0x54, 0x8d, 0xd1,
+	0x28, 0x60, 0x23, 0x1a, 0xa9, 0x90, 0x73, 0x19, 0xd6, 0x65, 0x78, 0x39, 0xb2, 0x54, 0xe8, 0x97,
+	0xb1, 0x41, 0x93, 0xb1, 0x41, 0xc9, 0x64, 0xdc, 0x97, 0xb1, 0xe1, 0x2a, 0x9c, 0x9d, 0x7a, 0x0b,
+	0xfb, 0x64, 0x44, 0x9f, 0xf5, 0xa9, 0xb7, 0xa0, 0xd7, 0xcf, 0xc0, 0xf9, 0xc5, 0x11, 0x65, 0xec,
+	0xc8, 0x62, 0xda, 0x0a, 0x39, 0xbb, 0x20, 0x80, 0xb4, 0x1c, 0xef, 0x19, 0x5d, 0xe9, 0x13, 0x3d,
+	0xf7, 0xc9, 0x5d, 0xe9, 0x93, 0xda, 0x9f, 0x67, 0x61, 0x7d, 0x3e, 0xf4, 0x26, 0x49, 0x38, 0x4e,
+	0x0a, 0xda, 0xb3, 0x92, 0x82, 0x0e, 0x4b, 0x3c, 0xb0, 0x3b, 0xde, 0x48, 0x18, 0x57, 0x24, 0x31,
+	0x89, 0x7b, 0xf0, 0x45, 0x65, 0x3b, 0x7b, 0x12, 0xb1, 0xc0, 0xa3, 0xae, 0x7b, 0x64, 0x49, 0xc7,
+	0xf2, 0x22, 0x36, 0xb4, 0x66, 0xc5, 0xc5, 0x32, 0x15, 0xbf, 0x25, 0xb5, 0xcd, 0x44, 0x99, 0x24,
+	0xba, 0xfd, 0x58, 0x15, 0x7f, 0x15, 0xaa, 0x81, 0x72, 0x07, 0xe1, 0x84, 0xf1, 0xf9, 0x6d, 0x7d,
+	0x91, 0xaf, 0x90, 0x4a, 0x90, 0x26, 0x5f, 0x3c, 0x79, 0xe3, 0x2b, 0x00, 0xd4, 0x0d, 0x7d, 0x8b,
+	0xba, 0xae, 0xff, 0x58, 0x9c, 0x71, 0x3f, 0xa9, 0x52, 0xbb, 0xc4, 0xf5, 0x0c, 0xae, 0x86, 0x3f,
+	0x84, 0x0d, 0x6a, 0xdb, 0x6c, 0x22, 0x8c, 0x9d, 0x15, 0x7a, 0x87, 0x7a, 0xf1, 0x19, 0xfd, 0xd7,
+	0xe2, 0x2e, 0x33, 0x1e, 0xaf, 0x76, 0x2b, 0xa0, 0xa5, 0xda, 0x5f, 0x68, 0xb0, 0xb6, 0xe0, 0x1a,
+	0x2e, 0xb9, 0xe3, 0xd3, 0x52, 0x4f, 0x08, 0x5f, 0x81, 0xbc, 0xfc, 0x46, 0x65, 0xb5, 0xe3, 0xb9,
+	0x93, 0xb7, 0x78, 0xe2, 0x73, 0x25, 0x52, 0x8b, 0xa7, 0x55, 0x01, 0xa9, 0x2d, 0xde, 0x10, 0xe2,
+	0xc3, 0x51, 0x99, 0xf3, 0xe4, 0xb3, 0xc2, 0xc9, 0x47, 0x89, 0xdc, 0x73, 0x1f, 0x25, 0x2e, 0xfe,
+	0x6e, 0x16, 0x4a, 0xad, 0xa3, 0xde, 0x43, 0x77, 0xdf, 0xa5, 0x23, 0x51, 0xe8, 0xd5, 0xea, 0xf6,
+	0xef, 0xa1, 0x33, 0xbc, 0x92, 0xb5, 0xdd, 0xe9, 0x5b, 0xed, 0x41, 0xb3, 0x69, 0xed, 0x37, 0x8d,
+	0x1b, 0x48, 0xe3, 0x25, 0xa1, 0x5d, 0xd2, 0xb0, 0x6e, 0x99, 0xf7, 0x24, 0x27, 0xc3, 0x6b, 0x4c,
+	0x07, 0xed, 0xc6, 0xed, 0x81, 0x39, 0x63, 0xe6, 0xf0, 0x06, 0xac, 0xb6, 0x06, 0xcd, 0x7e, 0xa3,
+	0xdb, 0x4c, 0xb1, 0x8b, 0xbc, 0x0e, 0x76, 0xb7, 0xd9, 0xd9, 0x95, 0x24, 0xe2, 0xe3, 0x0f, 0xda,
+	0xbd, 0xc6, 0x8d, 0xb6, 0xb9, 0x27, 0x59, 0x5b, 0x9c, 0xf5, 0x91, 0x49, 0x3a, 0xfb, 0x8d, 0x78,
+	0xca, 0x0f, 0x30, 0x82, 0xf2, 0x6e, 0xa3, 0x6d, 0x10, 0x35, 0xca, 0x53, 0x0d, 0x57, 0xa1, 0x64,
+	0xb6, 0x07, 0x2d, 0x45, 0x67, 0xb0, 0x0e, 0x6b, 0xbc, 0xe4, 0xd4, 0x6a, 0xb4, 0xeb, 0xc4, 0x6c,
+	0xf1, 0xca, 0x54, 0x29, 0xc9, 0xe1, 0x35, 0xa8, 0xf6, 0x1b, 0x2d, 0xb3, 0xd7, 0x37, 0x5a, 0x5d,
+	0xc5, 0xe4, 0xab, 0x28, 0xf6, 0xcc, 0x58, 0x07, 0xe1, 0x4d, 0xd8, 0x68, 0x77, 0x2c, 0x55, 0x34,
+	0x6b, 0xdd, 0x31, 0x9a, 0x03, 0x53, 0xc9, 0xb6, 0xf0, 0x39, 0xc0, 0x9d, 0xb6, 0x35, 0xe8, 0xee,
+	0x19, 0x7d, 0xd3, 0x6a, 0x77, 0xee, 0x2a, 0xc1, 0x07, 0xb8, 0x0a, 0xc5, 0xd9, 0x0a, 0x9e, 0x72,
+	0x14, 0x2a, 0x5d, 0x83, 0xf4, 0x67, 0xc6, 0x3e, 0x7d, 0xca, 0xc1, 0x82, 0x1b, 0xa4, 0x33, 0xe8,
+	0xce, 0xd4, 0x56, 0xa1, 0xac, 0xc0, 0x52, 0xac, 0x1c, 0x67, 0xed, 0x36, 0xda, 0xf5, 0x64, 0x7d,
+	0x4f, 0x8b, 0x9b, 0x19, 0xa4, 0x5d, 0x3c, 0x84, 0x9c, 0xd8, 0x8e, 0x22, 0xe4, 0xda, 0x9d, 0x36,
+	0x2f, 0x22, 0x5e, 0x01, 0x68, 0xf4, 0x1a, 0xed, 0xbe, 0x79, 0x83, 0x18, 0x4d, 0x6e, 0xb6, 0x60,
+	0xc4, 0x00, 0x72, 0x6b, 0x97, 0x61, 0xa9, 0xd1, 0xdb, 0x6f, 0x76, 0x8c, 0xbe, 0x32, 0xb3, 0xd1,
+	0xbb, 0x3d, 0xe8, 0xf0, 0x5a, 0xde, 0xa7, 0x08, 0x97, 0xa1, 0xc0, 0xcb, 0x76, 0xbf, 0xd1, 0xe7,
+	0x76, 0x09, 0x99, 0x44, 0x15, 0x3d, 0xfd, 0xe0, 0xe2, 0xf7, 0xb2, 0x90, 0x13, 0x7f, 0x5a, 0xa8,
+	0x40, 0x49, 0xec, 0x36, 0xaf, 0x56, 0x46, 0x67, 0x70, 0x09, 0x72, 0x8d, 0x76, 0xff, 0x3a, 0xfa,
+	0x85, 0x0c, 0x06, 0xc8, 0x0f, 0x44, 0xfb, 0x17, 0x0b, 0xbc, 0xdd, 0x68, 0xf7, 0xdf, 0xbd, 0x86,
+	0xbe, 0x99, 0xe1, 0xc3, 0x0e, 0x24, 0xf1, 0x4b, 0xb1, 0x60, 0xe7, 0x2a, 0xfa, 0x56, 0x22, 0xd8,
+	0xb9, 0x8a, 0x7e, 0x39, 0x16, 0x5c, 0xd9, 0x41, 0xdf, 0x4e, 0x04, 0x57, 0x76, 0xd0, 0xaf, 0xc4,
+	0x82, 0x6b, 0x57, 0xd1, 0xaf, 0x26, 0x82, 0x6b, 0x57, 0xd1, 0xaf, 0x15, 0xb8, 0x2d, 0xc2, 0x92,
+	0x2b, 0x3b, 0xe8, 0xd7, 0x8b, 0x09, 0x75, 0xed, 0x2a, 0xfa, 0x8d, 0x22, 0xdf, 0xff, 0x64, 0x57,
+	0xd1, 0x6f, 0x22, 0xbe, 0x4c, 0xbe, 0x41, 0xe8, 0xb7, 0x44, 0x93, 0x8b, 0xd0, 0x6f, 0x23, 0x6e,
+	0x23, 0xe7, 0x0a, 0xf2, 0x3b, 0x42, 0x72, 0xcf, 0x34, 0x08, 0xfa, 0x9d, 0x82, 0xac, 0x91, 0xae,
+	0x37, 0x5a, 0x46, 0x13, 0x61, 0xd1, 0x83, 0xa3, 0xf2, 0x7b, 0x97, 0x79, 0x93, 0xbb, 0x27, 0xfa,
+	0xfd, 0x2e, 0x9f, 0xf0, 0x8e, 0x41, 0xea, 0x1f, 0x1a, 0x04, 0xfd, 0xc1, 0x65, 0x3e, 0xe1, 0x1d,
+	0x83, 0x28, 0xbc, 0xfe, 0xb0, 0xcb, 0x15, 0x85, 0xe8, 0xbb, 0x97, 0xf9, 0xa2, 0x15, 0xff, 0x8f,
+	0xba, 0xb8, 0x08, 0xd9, 0xdd, 0x46, 0x1f, 0x7d, 0x4f, 0xcc, 0xc6, 0x5d, 0x14, 0xfd, 0x31, 0xe2,
+	0xcc, 0x9e, 0xd9, 0x47, 0xdf, 0xe7, 0xcc, 0x7c, 0x7f, 0xd0, 0x6d, 0x9a, 0xe8, 0x73, 0x7c, 0x71,
+	0x37, 0xcc, 0x4e, 0xcb, 0xec, 0x93, 0x7b, 0xe8, 0x4f, 0x84, 0xfa, 0xcd, 0x5e, 0xa7, 0x8d, 0x7e,
+	0x80, 0x78, 0xfd, 0xb4, 0xf9, 0x8d, 0x2e, 0x31, 0x7b, 0xbd, 0x46, 0xa7, 0x8d, 0xde, 0xbc, 0xb8,
+	0x0f, 0xe8, 0x78, 0x38, 0xe0, 0x06, 0x0c, 0xda, 0xb7, 0xda, 0x9d, 0xbb, 0x6d, 0x74, 0x86, 0x13,
+	0x5d, 0x62, 0x76, 0x0d, 0x62, 0x22, 0x0d, 0x03, 0x14, 0x54, 0xe5, 0x75, 0x06, 0x2f, 0x43, 0x91,
+	0x74, 0x9a, 0xcd, 0x5d, 0xa3, 0x7e, 0x0b, 0x65, 0x77, 0xdf, 0x83, 0x15, 0xc7, 0xdf, 0x7e, 0xe4,
+	0x44, 0x2c, 0x0c, 0xe5, 0xdf, 0x62, 0x3e, 0xaa, 0x29, 0xca, 0xf1, 0x2f, 0xc9, 0xd6, 0xa5, 0x91,
+	0x7f, 0xe9, 0x51, 0x74, 0x49, 0x48, 0x2f, 0x89, 0x88, 0x71, 0xbf, 0x20, 0x88, 0x2b, 0xff, 0x3b,
+	0x00, 0x45, 0xca, 0x2d, 0xd8, 0x74, 0x33, 0x00, 0x00,
 }
@@ -3166,6 +3166,76 @@ func (m *UndoDemoteMasterResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_UndoDemoteMasterResponse proto.InternalMessageInfo
 
+type CanTransitionRequest struct {
+	TabletType           topodata.TabletType `protobuf:"varint,1,opt,name=tablet_type,json=tabletType,proto3,enum=topodata.TabletType" json:"tablet_type,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *CanTransitionRequest) Reset()         { *m = CanTransitionRequest{} }
+func (m *CanTransitionRequest) String() string { return proto.CompactTextString(m) }
+func (*CanTransitionRequest) ProtoMessage()    {}
+func (*CanTransitionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{78}
+}
+
+func (m *CanTransitionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CanTransitionRequest.Unmarshal(m, b)
+}
+func (m *CanTransitionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CanTransitionRequest.Marshal(b, m, deterministic)
+}
+func (m *CanTransitionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CanTransitionRequest.Merge(m, src)
+}
+func (m *CanTransitionRequest) XXX_Size() int {
+	return xxx_messageInfo_CanTransitionRequest.Size(m)
+}
+func (m *CanTransitionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CanTransitionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CanTransitionRequest proto.InternalMessageInfo
+
+func (m *CanTransitionRequest) GetTabletType() topodata.TabletType {
+	if m != nil {
+		return m.TabletType
+	}
+	return topodata.TabletType_UNKNOWN
+}
+
+type CanTransitionResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CanTransitionResponse) Reset()         { *m = CanTransitionResponse{} }
+func (m *CanTransitionResponse) String() string { return proto.CompactTextString(m) }
+func (*CanTransitionResponse) ProtoMessage()    {}
+func (*CanTransitionResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{79}
+}
+
+func (m *CanTransitionResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CanTransitionResponse.Unmarshal(m, b)
+}
+func (m *CanTransitionResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CanTransitionResponse.Marshal(b, m, deterministic)
+}
+func (m *CanTransitionResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CanTransitionResponse.Merge(m, src)
+}
+func (m *CanTransitionResponse) XXX_Size() int {
+	return xxx_messageInfo_CanTransitionResponse.Size(m)
+}
+func (m *CanTransitionResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CanTransitionResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CanTransitionResponse proto.InternalMessageInfo
+
 type ReplicaWasPromotedRequest struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -3176,7 +3246,7 @@ func (m *ReplicaWasPromotedRequest) Reset()         { *m = ReplicaWasPromotedReq
 func (m *ReplicaWasPromotedRequest) String() string { return proto.CompactTextString(m) }
 func (*ReplicaWasPromotedRequest) ProtoMessage()    {}
 func (*ReplicaWasPromotedRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{78}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{80}
 }
 
 func (m *ReplicaWasPromotedRequest) XXX_Unmarshal(b []byte) error {
@@ -3207,7 +3277,7 @@ func (m *ReplicaWasPromotedResponse) Reset()         { *m = ReplicaWasPromotedRe
 func (m *ReplicaWasPromotedResponse) String() string { return proto.CompactTextString(m) }
 func (*ReplicaWasPromotedResponse) ProtoMessage()    {}
 func (*ReplicaWasPromotedResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{79}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{81}
 }
 
 func (m *ReplicaWasPromotedResponse) XXX_Unmarshal(b []byte) error {
@@ -3242,7 +3312,7 @@ func (m *SetMasterRequest) Reset()         { *m = SetMasterRequest{} }
 func (m *SetMasterRequest) String() string { return proto.CompactTextString(m) }
 func (*SetMasterRequest) ProtoMessage()    {}
 func (*SetMasterRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{80}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{82}
 }
 
 func (m *SetMasterRequest) XXX_Unmarshal(b []byte) error {
@@ -3301,7 +3371,7 @@ func (m *SetMasterResponse) Reset()         { *m = SetMasterResponse{} }
 func (m *SetMasterResponse) String() string { return proto.CompactTextString(m) }
 func (*SetMasterResponse) ProtoMessage()    {}
 func (*SetMasterResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{81}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{83}
 }
 
 func (m *SetMasterResponse) XXX_Unmarshal(b []byte) error {
@@ -3334,7 +3404,7 @@ func (m *ReplicaWasRestartedRequest) Reset()         { *m = ReplicaWasRestartedR
 func (m *ReplicaWasRestartedRequest) String() string { return proto.CompactTextString(m) }
 func (*ReplicaWasRestartedRequest) ProtoMessage()    {}
 func (*ReplicaWasRestartedRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{82}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{84}
 }
 
 func (m *ReplicaWasRestartedRequest) XXX_Unmarshal(b []byte) error {
@@ -3372,7 +3442,7 @@ func (m *ReplicaWasRestartedResponse) Reset()         { *m = ReplicaWasRestarted
 func (m *ReplicaWasRestartedResponse) String() string { return proto.CompactTextString(m) }
 func (*ReplicaWasRestartedResponse) ProtoMessage()    {}
 func (*ReplicaWasRestartedResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{83}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{85}
 }
 
 func (m *ReplicaWasRestartedResponse) XXX_Unmarshal(b []byte) error {
@@ -3404,7 +3474,7 @@ func (m *StopReplicationAndGetStatusRequest) Reset()         { *m = StopReplicat
 func (m *StopReplicationAndGetStatusRequest) String() string { return proto.CompactTextString(m) }
 func (*StopReplicationAndGetStatusRequest) ProtoMessage()    {}
 func (*StopReplicationAndGetStatusRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{84}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{86}
 }
 
 func (m *StopReplicationAndGetStatusRequest) XXX_Unmarshal(b []byte) error {
@@ -3448,7 +3518,7 @@ func (m *StopReplicationAndGetStatusResponse) Reset()         { *m = StopReplica
 func (m *StopReplicationAndGetStatusResponse) String() string { return proto.CompactTextString(m) }
 func (*StopReplicationAndGetStatusResponse) ProtoMessage()    {}
 func (*StopReplicationAndGetStatusResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{85}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{87}
 }
 
 func (m *StopReplicationAndGetStatusResponse) XXX_Unmarshal(b []byte) error {
@@ -3494,7 +3564,7 @@ func (m *PromoteReplicaRequest) Reset()         { *m = PromoteReplicaRequest{} }
 func (m *PromoteReplicaRequest) String() string { return proto.CompactTextString(m) }
 func (*PromoteReplicaRequest) ProtoMessage()    {}
 func (*PromoteReplicaRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{86}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{88}
 }
 
 func (m *PromoteReplicaRequest) XXX_Unmarshal(b []byte) error {
@@ -3526,7 +3596,7 @@ func (m *PromoteReplicaResponse) Reset()         { *m = PromoteReplicaResponse{}
 func (m *PromoteReplicaResponse) String() string { return proto.CompactTextString(m) }
 func (*PromoteReplicaResponse) ProtoMessage()    {}
 func (*PromoteReplicaResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{87}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{89}
 }
 
 func (m *PromoteReplicaResponse) XXX_Unmarshal(b []byte) error {
@@ -3566,7 +3636,7 @@ func (m *BackupRequest) Reset()         { *m = BackupRequest{} }
 func (m *BackupRequest) String() string { return proto.CompactTextString(m) }
 func (*BackupRequest) ProtoMessage()    {}
 func (*BackupRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{88}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{90}
 }
 
 func (m *BackupRequest) XXX_Unmarshal(b []byte) error {
@@ -3612,7 +3682,7 @@ func (m *BackupResponse) Reset()         { *m = BackupResponse{} }
 func (m *BackupResponse) String() string { return proto.CompactTextString(m) }
 func (*BackupResponse) ProtoMessage()    {}
 func (*BackupResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{89}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{91}
 }
 
 func (m *BackupResponse) XXX_Unmarshal(b []byte) error {
@@ -3650,7 +3720,7 @@ func (m *RestoreFromBackupRequest) Reset()         { *m = RestoreFromBackupReque
 func (m *RestoreFromBackupRequest) String() string { return proto.CompactTextString(m) }
 func (*RestoreFromBackupRequest) ProtoMessage()    {}
 func (*RestoreFromBackupRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{90}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{92}
 }
 
 func (m *RestoreFromBackupRequest) XXX_Unmarshal(b []byte) error {
@@ -3682,7 +3752,7 @@ func (m *RestoreFromBackupResponse) Reset()         { *m = RestoreFromBackupResp
 func (m *RestoreFromBackupResponse) String() string { return proto.CompactTextString(m) }
 func (*RestoreFromBackupResponse) ProtoMessage()    {}
 func (*RestoreFromBackupResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{91}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{93}
 }
 
 func (m *RestoreFromBackupResponse) XXX_Unmarshal(b []byte) error {
@@ -3721,7 +3791,7 @@ func (m *SlaveStatusRequest) Reset()         { *m = SlaveStatusRequest{} }
 func (m *SlaveStatusRequest) String() string { return proto.CompactTextString(m) }
 func (*SlaveStatusRequest) ProtoMessage()    {}
 func (*SlaveStatusRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{92}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{94}
 }
 
 func (m *SlaveStatusRequest) XXX_Unmarshal(b []byte) error {
@@ -3754,7 +3824,7 @@ func (m *SlaveStatusResponse) Reset()         { *m = SlaveStatusResponse{} }
 func (m *SlaveStatusResponse) String() string { return proto.CompactTextString(m) }
 func (*SlaveStatusResponse) ProtoMessage()    {}
 func (*SlaveStatusResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{93}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{95}
 }
 
 func (m *SlaveStatusResponse) XXX_Unmarshal(b []byte) error {
@@ -3793,7 +3863,7 @@ func (m *StopSlaveRequest) Reset()         { *m = StopSlaveRequest{} }
 func (m *StopSlaveRequest) String() string { return proto.CompactTextString(m) }
 func (*StopSlaveRequest) ProtoMessage()    {}
 func (*StopSlaveRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{94}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{96}
 }
 
 func (m *StopSlaveRequest) XXX_Unmarshal(b []byte) error {
@@ -3825,7 +3895,7 @@ func (m *StopSlaveResponse) Reset()         { *m = StopSlaveResponse{} }
 func (m *StopSlaveResponse) String() string { return proto.CompactTextString(m) }
 func (*StopSlaveResponse) ProtoMessage()    {}
 func (*StopSlaveResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{95}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{97}
 }
 
 func (m *StopSlaveResponse) XXX_Unmarshal(b []byte) error {
@@ -3859,7 +3929,7 @@ func (m *StopSlaveMinimumRequest) Reset()         { *m = StopSlaveMinimumRequest
 func (m *StopSlaveMinimumRequest) String() string { return proto.CompactTextString(m) }
 func (*StopSlaveMinimumRequest) ProtoMessage()    {}
 func (*StopSlaveMinimumRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{96}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{98}
 }
 
 func (m *StopSlaveMinimumRequest) XXX_Unmarshal(b []byte) error {
@@ -3906,7 +3976,7 @@ func (m *StopSlaveMinimumResponse) Reset()         { *m = StopSlaveMinimumRespon
 func (m *StopSlaveMinimumResponse) String() string { return proto.CompactTextString(m) }
 func (*StopSlaveMinimumResponse) ProtoMessage()    {}
 func (*StopSlaveMinimumResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{97}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{99}
 }
 
 func (m *StopSlaveMinimumResponse) XXX_Unmarshal(b []byte) error {
@@ -3945,7 +4015,7 @@ func (m *StartSlaveRequest) Reset()         { *m = StartSlaveRequest{} }
 func (m *StartSlaveRequest) String() string { return proto.CompactTextString(m) }
 func (*StartSlaveRequest) ProtoMessage()    {}
 func (*StartSlaveRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{98}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{100}
 }
 
 func (m *StartSlaveRequest) XXX_Unmarshal(b []byte) error {
@@ -3977,7 +4047,7 @@ func (m *StartSlaveResponse) Reset()         { *m = StartSlaveResponse{} }
 func (m *StartSlaveResponse) String() string { return proto.CompactTextString(m) }
 func (*StartSlaveResponse) ProtoMessage()    {}
 func (*StartSlaveResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{99}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{101}
 }
 
 func (m *StartSlaveResponse) XXX_Unmarshal(b []byte) error {
@@ -4011,7 +4081,7 @@ func (m *StartSlaveUntilAfterRequest) Reset()         { *m = StartSlaveUntilAfte
 func (m *StartSlaveUntilAfterRequest) String() string { return proto.CompactTextString(m) }
 func (*StartSlaveUntilAfterRequest) ProtoMessage()    {}
 func (*StartSlaveUntilAfterRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{100}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{102}
 }
 
 func (m *StartSlaveUntilAfterRequest) XXX_Unmarshal(b []byte) error {
@@ -4057,7 +4127,7 @@ func (m *StartSlaveUntilAfterResponse) Reset()         { *m = StartSlaveUntilAft
 func (m *StartSlaveUntilAfterResponse) String() string { return proto.CompactTextString(m) }
 func (*StartSlaveUntilAfterResponse) ProtoMessage()    {}
 func (*StartSlaveUntilAfterResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{101}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{103}
 }
 
 func (m *StartSlaveUntilAfterResponse) XXX_Unmarshal(b []byte) error {
@@ -4089,7 +4159,7 @@ func (m *GetSlavesRequest) Reset()         { *m = GetSlavesRequest{} }
 func (m *GetSlavesRequest) String() string { return proto.CompactTextString(m) }
 func (*GetSlavesRequest) ProtoMessage()    {}
 func (*GetSlavesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{102}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{104}
 }
 
 func (m *GetSlavesRequest) XXX_Unmarshal(b []byte) error {
@@ -4122,7 +4192,7 @@ func (m *GetSlavesResponse) Reset()         { *m = GetSlavesResponse{} }
 func (m *GetSlavesResponse) String() string { return proto.CompactTextString(m) }
 func (*GetSlavesResponse) ProtoMessage()    {}
 func (*GetSlavesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{103}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{105}
 }
 
 func (m *GetSlavesResponse) XXX_Unmarshal(b []byte) error {
@@ -4164,7 +4234,7 @@ func (m *InitSlaveRequest) Reset()         { *m = InitSlaveRequest{} }
 func (m *InitSlaveRequest) String() string { return proto.CompactTextString(m) }
 func (*InitSlaveRequest) ProtoMessage()    {}
 func (*InitSlaveRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{104}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{106}
 }
 
 func (m *InitSlaveRequest) XXX_Unmarshal(b []byte) error {
@@ -4217,7 +4287,7 @@ func (m *InitSlaveResponse) Reset()         { *m = InitSlaveResponse{} }
 func (m *InitSlaveResponse) String() string { return proto.CompactTextString(m) }
 func (*InitSlaveResponse) ProtoMessage()    {}
 func (*InitSlaveResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{105}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{107}
 }
 
 func (m *InitSlaveResponse) XXX_Unmarshal(b []byte) error {
@@ -4249,7 +4319,7 @@ func (m *SlaveWasPromotedRequest) Reset()         { *m = SlaveWasPromotedRequest
 func (m *SlaveWasPromotedRequest) String() string { return proto.CompactTextString(m) }
 func (*SlaveWasPromotedRequest) ProtoMessage()    {}
 func (*SlaveWasPromotedRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{106}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{108}
 }
 
 func (m *SlaveWasPromotedRequest) XXX_Unmarshal(b []byte) error {
@@ -4281,7 +4351,7 @@ func (m *SlaveWasPromotedResponse) Reset()         { *m = SlaveWasPromotedRespon
 func (m *SlaveWasPromotedResponse) String() string { return proto.CompactTextString(m) }
 func (*SlaveWasPromotedResponse) ProtoMessage()    {}
 func (*SlaveWasPromotedResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{107}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{109}
 }
 
 func (m *SlaveWasPromotedResponse) XXX_Unmarshal(b []byte) error {
@@ -4315,7 +4385,7 @@ func (m *SlaveWasRestartedRequest) Reset()         { *m = SlaveWasRestartedReque
 func (m *SlaveWasRestartedRequest) String() string { return proto.CompactTextString(m) }
 func (*SlaveWasRestartedRequest) ProtoMessage()    {}
 func (*SlaveWasRestartedRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{108}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{110}
 }
 
 func (m *SlaveWasRestartedRequest) XXX_Unmarshal(b []byte) error {
@@ -4354,7 +4424,7 @@ func (m *SlaveWasRestartedResponse) Reset()         { *m = SlaveWasRestartedResp
 func (m *SlaveWasRestartedResponse) String() string { return proto.CompactTextString(m) }
 func (*SlaveWasRestartedResponse) ProtoMessage()    {}
 func (*SlaveWasRestartedResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ff9ac4f89e61ffa4, []int{109}
+	return fileDescriptor_ff9ac4f89e61ffa4, []int{111}
 }
 
 func (m *SlaveWasRestartedResponse) XXX_Unmarshal(b []byte) error {
@@ -4457,6 +4527,8 @@ func init() {
 	proto.RegisterType((*DemoteMasterResponse)(nil), "tabletmanagerdata.DemoteMasterResponse")
 	proto.RegisterType((*UndoDemoteMasterRequest)(nil), "tabletmanagerdata.UndoDemoteMasterRequest")
 	proto.RegisterType((*UndoDemoteMasterResponse)(nil), "tabletmanagerdata.UndoDemoteMasterResponse")
+	proto.RegisterType((*CanTransitionRequest)(nil), "tabletmanagerdata.CanTransitionRequest")
+	proto.RegisterType((*CanTransitionResponse)(nil), "tabletmanagerdata.CanTransitionResponse")
 	proto.RegisterType((*ReplicaWasPromotedRequest)(nil), "tabletmanagerdata.ReplicaWasPromotedRequest")
 	proto.RegisterType((*ReplicaWasPromotedResponse)(nil), "tabletmanagerdata.ReplicaWasPromotedResponse")
 	proto.RegisterType((*SetMasterRequest)(nil), "tabletmanagerdata.SetMasterRequest")
@@ -4494,148 +4566,148 @@ func init() {
 func init() { proto.RegisterFile("tabletmanagerdata.proto", fileDescriptor_ff9ac4f89e61ffa4) }
 
 var fileDescriptor_ff9ac4f89e61ffa4 = []byte{
-	// 2276 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x59, 0xdd, 0x72, 0xdb, 0xc6,
-	0x15, 0x1e, 0x50, 0x3f, 0x96, 0x0e, 0x7f, 0x44, 0x81, 0x94, 0x44, 0x51, 0xb1, 0x2c, 0xc3, 0x4e,
-	0xe2, 0x26, 0x53, 0x2a, 0x51, 0x12, 0x4f, 0x26, 0xfd, 0x99, 0xca, 0xb6, 0x64, 0x3b, 0x96, 0x63,
-	0x05, 0xb2, 0xe3, 0x4c, 0xa6, 0x53, 0x0c, 0x48, 0xac, 0x28, 0x8c, 0x40, 0x2c, 0xbc, 0xbb, 0xa0,
-	0xc4, 0x9b, 0x3e, 0x42, 0xfb, 0x02, 0x9d, 0xde, 0x74, 0xa6, 0xbd, 0xef, 0x43, 0xf4, 0x11, 0xd2,
-	0x47, 0xe9, 0x45, 0x2f, 0xda, 0xd9, 0xdd, 0x03, 0x12, 0x20, 0x20, 0x59, 0x76, 0xdd, 0x99, 0xdc,
-	0x68, 0xb0, 0xdf, 0xd9, 0xf3, 0xbb, 0x67, 0xcf, 0x39, 0x4b, 0xc1, 0x9a, 0x70, 0xbb, 0x01, 0x11,
-	0x03, 0x37, 0x74, 0xfb, 0x84, 0x79, 0xae, 0x70, 0x3b, 0x11, 0xa3, 0x82, 0x9a, 0xcb, 0x39, 0x42,
-	0xbb, 0xfc, 0x2a, 0x26, 0x6c, 0xa4, 0xe9, 0xed, 0x9a, 0xa0, 0x11, 0x9d, 0xec, 0x6f, 0xaf, 0x30,
-	0x12, 0x05, 0x7e, 0xcf, 0x15, 0x3e, 0x0d, 0x53, 0x70, 0x35, 0xa0, 0xfd, 0x58, 0xf8, 0x81, 0x5e,
-	0x5a, 0xff, 0x31, 0x60, 0xe9, 0xb9, 0x14, 0xfc, 0x80, 0x1c, 0xfb, 0xa1, 0x2f, 0x37, 0x9b, 0x26,
-	0xcc, 0x86, 0xee, 0x80, 0xb4, 0x8c, 0x2d, 0xe3, 0xce, 0xa2, 0xad, 0xbe, 0xcd, 0x55, 0x98, 0xe7,
-	0xbd, 0x13, 0x32, 0x70, 0x5b, 0x25, 0x85, 0xe2, 0xca, 0x6c, 0xc1, 0xb5, 0x1e, 0x0d, 0xe2, 0x41,
-	0xc8, 0x5b, 0x33, 0x5b, 0x33, 0x77, 0x16, 0xed, 0x64, 0x69, 0x76, 0xa0, 0x11, 0x31, 0x7f, 0xe0,
-	0xb2, 0x91, 0x73, 0x4a, 0x46, 0x4e, 0xb2, 0x6b, 0x56, 0xed, 0x5a, 0x46, 0xd2, 0x13, 0x32, 0xba,
-	0x8f, 0xfb, 0x4d, 0x98, 0x15, 0xa3, 0x88, 0xb4, 0xe6, 0xb4, 0x56, 0xf9, 0x6d, 0xde, 0x80, 0xb2,
-	0x34, 0xdd, 0x09, 0x48, 0xd8, 0x17, 0x27, 0xad, 0xf9, 0x2d, 0xe3, 0xce, 0xac, 0x0d, 0x12, 0x3a,
-	0x50, 0x88, 0xb9, 0x01, 0x8b, 0x8c, 0x9e, 0x39, 0x3d, 0x1a, 0x87, 0xa2, 0x75, 0x4d, 0x91, 0x17,
-	0x18, 0x3d, 0xbb, 0x2f, 0xd7, 0xe6, 0x6d, 0x98, 0x3f, 0xf6, 0x49, 0xe0, 0xf1, 0xd6, 0xc2, 0xd6,
-	0xcc, 0x9d, 0xf2, 0x4e, 0xa5, 0xa3, 0xe3, 0xb5, 0x2f, 0x41, 0x1b, 0x69, 0xd6, 0x5f, 0x0d, 0xa8,
-	0x1f, 0x29, 0x67, 0x52, 0x21, 0xf8, 0x10, 0x96, 0xa4, 0x96, 0xae, 0xcb, 0x89, 0x83, 0x7e, 0xeb,
-	0x68, 0xd4, 0x12, 0x58, 0xb3, 0x98, 0xcf, 0x40, 0x9f, 0x8b, 0xe3, 0x8d, 0x99, 0x79, 0xab, 0xa4,
-	0xd4, 0x59, 0x9d, 0xfc, 0x51, 0x4e, 0x85, 0xda, 0xae, 0x8b, 0x2c, 0xc0, 0x65, 0x40, 0x87, 0x84,
-	0x71, 0x9f, 0x86, 0xad, 0x19, 0xa5, 0x31, 0x59, 0x4a, 0x43, 0x4d, 0xad, 0xf5, 0xfe, 0x89, 0x1b,
-	0xf6, 0x89, 0x4d, 0x78, 0x1c, 0x08, 0xf3, 0x11, 0x54, 0xbb, 0xe4, 0x98, 0xb2, 0x8c, 0xa1, 0xe5,
-	0x9d, 0x5b, 0x05, 0xda, 0xa7, 0xdd, 0xb4, 0x2b, 0x9a, 0x13, 0x7d, 0xd9, 0x87, 0x8a, 0x7b, 0x2c,
-	0x08, 0x73, 0x52, 0x27, 0x7d, 0x45, 0x41, 0x65, 0xc5, 0xa8, 0x61, 0xeb, 0x5f, 0x06, 0xd4, 0x5e,
-	0x70, 0xc2, 0x0e, 0x09, 0x1b, 0xf8, 0x9c, 0x63, 0x4a, 0x9d, 0x50, 0x2e, 0x92, 0x94, 0x92, 0xdf,
-	0x12, 0x8b, 0x39, 0x61, 0x98, 0x50, 0xea, 0xdb, 0xfc, 0x18, 0x96, 0x23, 0x97, 0xf3, 0x33, 0xca,
-	0x3c, 0xa7, 0x77, 0x42, 0x7a, 0xa7, 0x3c, 0x1e, 0xa8, 0x38, 0xcc, 0xda, 0xf5, 0x84, 0x70, 0x1f,
-	0x71, 0xf3, 0x5b, 0x80, 0x88, 0xf9, 0x43, 0x3f, 0x20, 0x7d, 0xa2, 0x13, 0xab, 0xbc, 0xf3, 0x69,
-	0x81, 0xb5, 0x59, 0x5b, 0x3a, 0x87, 0x63, 0x9e, 0xbd, 0x50, 0xb0, 0x91, 0x9d, 0x12, 0xd2, 0xfe,
-	0x15, 0x2c, 0x4d, 0x91, 0xcd, 0x3a, 0xcc, 0x9c, 0x92, 0x11, 0x5a, 0x2e, 0x3f, 0xcd, 0x26, 0xcc,
-	0x0d, 0xdd, 0x20, 0x26, 0x68, 0xb9, 0x5e, 0x7c, 0x55, 0xfa, 0xd2, 0xb0, 0x7e, 0x34, 0xa0, 0xf2,
-	0xa0, 0xfb, 0x1a, 0xbf, 0x6b, 0x50, 0xf2, 0xba, 0xc8, 0x5b, 0xf2, 0xba, 0xe3, 0x38, 0xcc, 0xa4,
-	0xe2, 0xf0, 0xac, 0xc0, 0xb5, 0xed, 0x02, 0xd7, 0xd2, 0xca, 0xfe, 0x9f, 0x8e, 0xfd, 0xc5, 0x80,
-	0xf2, 0x44, 0x13, 0x37, 0x0f, 0xa0, 0x2e, 0xed, 0x74, 0xa2, 0x09, 0xd6, 0x32, 0x94, 0x95, 0x37,
-	0x5f, 0x7b, 0x00, 0xf6, 0x52, 0x9c, 0x59, 0x73, 0x73, 0x1f, 0x6a, 0x5e, 0x37, 0x23, 0x4b, 0xdf,
-	0xa0, 0x1b, 0xaf, 0xf1, 0xd8, 0xae, 0x7a, 0xa9, 0x15, 0xb7, 0x3e, 0x84, 0xf2, 0xa1, 0x1f, 0xf6,
-	0x6d, 0xf2, 0x2a, 0x26, 0x5c, 0xc8, 0xab, 0x14, 0xb9, 0xa3, 0x80, 0xba, 0x1e, 0x3a, 0x99, 0x2c,
-	0xad, 0x3b, 0x50, 0xd1, 0x1b, 0x79, 0x44, 0x43, 0x4e, 0x2e, 0xd9, 0xf9, 0x11, 0x54, 0x8e, 0x02,
-	0x42, 0xa2, 0x44, 0x66, 0x1b, 0x16, 0xbc, 0x98, 0xa9, 0xa2, 0xaa, 0xb6, 0xce, 0xd8, 0xe3, 0xb5,
-	0xb5, 0x04, 0x55, 0xdc, 0xab, 0xc5, 0x5a, 0xff, 0x34, 0xc0, 0xdc, 0x3b, 0x27, 0xbd, 0x58, 0x90,
-	0x47, 0x94, 0x9e, 0x26, 0x32, 0x8a, 0xea, 0xeb, 0x26, 0x40, 0xe4, 0x32, 0x77, 0x40, 0x04, 0x61,
-	0xda, 0xfd, 0x45, 0x3b, 0x85, 0x98, 0x87, 0xb0, 0x48, 0xce, 0x05, 0x73, 0x1d, 0x12, 0x0e, 0x55,
-	0xa5, 0x2d, 0xef, 0x7c, 0x56, 0x10, 0x9d, 0xbc, 0xb6, 0xce, 0x9e, 0x64, 0xdb, 0x0b, 0x87, 0x3a,
-	0x27, 0x16, 0x08, 0x2e, 0xdb, 0xbf, 0x80, 0x6a, 0x86, 0xf4, 0x46, 0xf9, 0x70, 0x0c, 0x8d, 0x8c,
-	0x2a, 0x8c, 0xe3, 0x0d, 0x28, 0x93, 0x73, 0x5f, 0x38, 0x5c, 0xb8, 0x22, 0xe6, 0x18, 0x20, 0x90,
-	0xd0, 0x91, 0x42, 0x54, 0x1b, 0x11, 0x1e, 0x8d, 0xc5, 0xb8, 0x8d, 0xa8, 0x15, 0xe2, 0x84, 0x25,
-	0xb7, 0x00, 0x57, 0xd6, 0x10, 0xea, 0x0f, 0x89, 0xd0, 0x75, 0x25, 0x09, 0xdf, 0x2a, 0xcc, 0x2b,
-	0xc7, 0x75, 0xc6, 0x2d, 0xda, 0xb8, 0x32, 0x6f, 0x41, 0xd5, 0x0f, 0x7b, 0x41, 0xec, 0x11, 0x67,
-	0xe8, 0x93, 0x33, 0xae, 0x54, 0x2c, 0xd8, 0x15, 0x04, 0xbf, 0x93, 0x98, 0xf9, 0x3e, 0xd4, 0xc8,
-	0xb9, 0xde, 0x84, 0x42, 0x74, 0xdb, 0xaa, 0x22, 0xaa, 0x0a, 0x34, 0xb7, 0x08, 0x2c, 0xa7, 0xf4,
-	0xa2, 0x77, 0x87, 0xb0, 0xac, 0x2b, 0x63, 0xaa, 0xd8, 0xbf, 0x49, 0xb5, 0xad, 0xf3, 0x29, 0xc4,
-	0x5a, 0x83, 0x95, 0x87, 0x44, 0xa4, 0x52, 0x18, 0x7d, 0xb4, 0x7e, 0x80, 0xd5, 0x69, 0x02, 0x1a,
-	0xf1, 0x1b, 0x28, 0x67, 0x2f, 0x9d, 0x54, 0xbf, 0x59, 0xa0, 0x3e, 0xcd, 0x9c, 0x66, 0xb1, 0x9a,
-	0x60, 0x1e, 0x11, 0x61, 0x13, 0xd7, 0x7b, 0x16, 0x06, 0xa3, 0x44, 0xe3, 0x0a, 0x34, 0x32, 0x28,
-	0xa6, 0xf0, 0x04, 0x7e, 0xc9, 0x7c, 0x41, 0x92, 0xdd, 0xab, 0xd0, 0xcc, 0xc2, 0xb8, 0xfd, 0x6b,
-	0x58, 0xd6, 0xcd, 0xe9, 0xf9, 0x28, 0x4a, 0x36, 0x9b, 0x5f, 0x40, 0x59, 0x9b, 0xe7, 0xa8, 0x06,
-	0x2f, 0x4d, 0xae, 0xed, 0x34, 0x3b, 0xe3, 0x79, 0x45, 0xc5, 0x5c, 0x28, 0x0e, 0x10, 0xe3, 0x6f,
-	0x69, 0x67, 0x5a, 0xd6, 0xc4, 0x20, 0x9b, 0x1c, 0x33, 0xc2, 0x4f, 0x64, 0x4a, 0xa5, 0x0d, 0xca,
-	0xc2, 0xb8, 0x7d, 0x0d, 0x56, 0xec, 0x38, 0x7c, 0x44, 0xdc, 0x40, 0x9c, 0xa8, 0xc6, 0x91, 0x30,
-	0xb4, 0x60, 0x75, 0x9a, 0x80, 0x2c, 0x9f, 0x43, 0xeb, 0x71, 0x3f, 0xa4, 0x8c, 0x68, 0xe2, 0x1e,
-	0x63, 0x94, 0x65, 0x4a, 0x8a, 0x10, 0x84, 0x85, 0x93, 0x42, 0xa1, 0x96, 0xd6, 0x06, 0xac, 0x17,
-	0x70, 0xa1, 0xc8, 0xaf, 0xa4, 0xd1, 0xb2, 0x9e, 0x64, 0x33, 0xf9, 0x16, 0x54, 0xcf, 0x5c, 0x5f,
-	0x38, 0x11, 0xe5, 0x93, 0x64, 0x5a, 0xb4, 0x2b, 0x12, 0x3c, 0x44, 0x4c, 0x7b, 0x96, 0xe6, 0x45,
-	0x99, 0x3b, 0xb0, 0x7a, 0xc8, 0xc8, 0x71, 0xe0, 0xf7, 0x4f, 0xa6, 0x2e, 0x88, 0x9c, 0xc9, 0x54,
-	0xe0, 0x92, 0x1b, 0x92, 0x2c, 0xad, 0x3e, 0xac, 0xe5, 0x78, 0x30, 0xaf, 0x0e, 0xa0, 0xa6, 0x77,
-	0x39, 0x4c, 0xcd, 0x15, 0x49, 0x3d, 0x7f, 0xff, 0xc2, 0xcc, 0x4e, 0x4f, 0x21, 0x76, 0xb5, 0x97,
-	0x5a, 0x71, 0xeb, 0xdf, 0x06, 0x98, 0xbb, 0x51, 0x14, 0x8c, 0xb2, 0x96, 0xd5, 0x61, 0x86, 0xbf,
-	0x0a, 0x92, 0x12, 0xc3, 0x5f, 0x05, 0xb2, 0xc4, 0x1c, 0x53, 0xd6, 0x23, 0x78, 0x59, 0xf5, 0x42,
-	0x8e, 0x01, 0x6e, 0x10, 0xd0, 0x33, 0x27, 0x35, 0xc3, 0xaa, 0xca, 0xb0, 0x60, 0xd7, 0x15, 0xc1,
-	0x9e, 0xe0, 0xf9, 0x01, 0x68, 0xf6, 0x5d, 0x0d, 0x40, 0x73, 0x6f, 0x39, 0x00, 0xfd, 0xcd, 0x80,
-	0x46, 0xc6, 0x7b, 0x8c, 0xf1, 0x4f, 0x6f, 0x54, 0x6b, 0xc0, 0xf2, 0x01, 0xed, 0x9d, 0xea, 0xaa,
-	0x97, 0x5c, 0x8d, 0x26, 0x98, 0x69, 0x70, 0x72, 0xf1, 0x5e, 0x84, 0x41, 0x6e, 0xf3, 0x2a, 0x34,
-	0xb3, 0x30, 0x6e, 0xff, 0xbb, 0x01, 0x2d, 0x6c, 0x11, 0xfb, 0x44, 0xf4, 0x4e, 0x76, 0xf9, 0x83,
-	0xee, 0x38, 0x0f, 0x9a, 0x30, 0xa7, 0x46, 0x71, 0x15, 0x80, 0x8a, 0xad, 0x17, 0xe6, 0x1a, 0x5c,
-	0xf3, 0xba, 0x8e, 0x6a, 0x8d, 0xd8, 0x1d, 0xbc, 0xee, 0x37, 0xb2, 0x39, 0xae, 0xc3, 0xc2, 0xc0,
-	0x3d, 0x77, 0x18, 0x3d, 0xe3, 0x38, 0x0c, 0x5e, 0x1b, 0xb8, 0xe7, 0x36, 0x3d, 0xe3, 0x6a, 0x50,
-	0xf7, 0xb9, 0x9a, 0xc0, 0xbb, 0x7e, 0x18, 0xd0, 0x3e, 0x57, 0xc7, 0xbf, 0x60, 0xd7, 0x10, 0xbe,
-	0xa7, 0x51, 0x79, 0xd7, 0x98, 0xba, 0x46, 0xe9, 0xc3, 0x5d, 0xb0, 0x2b, 0x2c, 0x75, 0xb7, 0xac,
-	0x87, 0xb0, 0x5e, 0x60, 0x33, 0x9e, 0xde, 0x47, 0x30, 0xaf, 0xaf, 0x06, 0x1e, 0x9b, 0x89, 0xcf,
-	0x89, 0x6f, 0xe5, 0x5f, 0xbc, 0x06, 0xb8, 0xc3, 0xfa, 0x83, 0x01, 0xd7, 0xb3, 0x92, 0x76, 0x83,
-	0x40, 0x0e, 0x60, 0xfc, 0xdd, 0x87, 0x20, 0xe7, 0xd9, 0x6c, 0x81, 0x67, 0x07, 0xb0, 0x79, 0x91,
-	0x3d, 0x6f, 0xe1, 0xde, 0x93, 0xe9, 0xb3, 0xdd, 0x8d, 0xa2, 0xcb, 0x1d, 0x4b, 0xdb, 0x5f, 0xca,
-	0xd8, 0x9f, 0x0f, 0xba, 0x12, 0xf6, 0x16, 0x56, 0xb5, 0xa1, 0x95, 0xaa, 0x0b, 0x7a, 0xe2, 0x48,
-	0xd2, 0xf4, 0x00, 0xd6, 0x0b, 0x68, 0xa8, 0x64, 0x5b, 0x4e, 0x1f, 0xe3, 0x89, 0xa5, 0xbc, 0xb3,
-	0xd6, 0x99, 0x7e, 0x3b, 0x23, 0x03, 0x6e, 0x93, 0x77, 0xe1, 0xa9, 0xcb, 0xe5, 0x35, 0xca, 0x28,
-	0x79, 0x0a, 0xcd, 0x2c, 0x8c, 0xf2, 0xbf, 0x98, 0x92, 0x7f, 0x3d, 0x27, 0x3f, 0xc3, 0x96, 0x68,
-	0x59, 0x83, 0x15, 0x8d, 0x27, 0xbd, 0x20, 0xd1, 0xf3, 0x39, 0xac, 0x4e, 0x13, 0x50, 0x53, 0x1b,
-	0x16, 0xa6, 0x9a, 0xc9, 0x78, 0x2d, 0xb9, 0x5e, 0xba, 0xbe, 0xd8, 0xa7, 0xd3, 0xf2, 0x2e, 0xe5,
-	0x5a, 0x87, 0xb5, 0x1c, 0x17, 0x5e, 0xf1, 0x16, 0xac, 0x1e, 0x09, 0x1a, 0xa5, 0xe2, 0x9a, 0x18,
-	0xb8, 0x0e, 0x6b, 0x39, 0x0a, 0x32, 0xfd, 0x0e, 0xae, 0x4f, 0x91, 0x9e, 0xfa, 0xa1, 0x3f, 0x88,
-	0x07, 0x57, 0x30, 0xc6, 0xbc, 0x09, 0xaa, 0x37, 0x3a, 0xc2, 0x1f, 0x90, 0x64, 0x88, 0x9c, 0xb1,
-	0xcb, 0x12, 0x7b, 0xae, 0x21, 0xeb, 0x97, 0xb0, 0x79, 0x91, 0xfc, 0x2b, 0xc4, 0x48, 0x19, 0xee,
-	0x32, 0x51, 0xe0, 0x53, 0x1b, 0x5a, 0x79, 0x12, 0x3a, 0xd5, 0x85, 0x9b, 0xd3, 0xb4, 0x17, 0xa1,
-	0xf0, 0x83, 0x5d, 0x59, 0x6a, 0xdf, 0x91, 0x63, 0xb7, 0xc1, 0xba, 0x4c, 0x07, 0x5a, 0xd2, 0x04,
-	0xf3, 0x21, 0x49, 0xf6, 0x8c, 0x13, 0xf3, 0x63, 0x68, 0x64, 0x50, 0x8c, 0x44, 0x13, 0xe6, 0x5c,
-	0xcf, 0x63, 0xc9, 0x98, 0xa0, 0x17, 0x32, 0x06, 0x36, 0xe1, 0xe4, 0x82, 0x18, 0xe4, 0x49, 0xa8,
-	0x79, 0x1b, 0xd6, 0xbe, 0x4b, 0xe1, 0xf2, 0x4a, 0x17, 0x96, 0x84, 0x45, 0x2c, 0x09, 0xd6, 0x3e,
-	0xb4, 0xf2, 0x0c, 0x6f, 0x55, 0x8c, 0xae, 0xa7, 0xe5, 0x4c, 0xb2, 0x35, 0x51, 0x5f, 0x83, 0x92,
-	0xef, 0xe1, 0x63, 0xa4, 0xe4, 0x7b, 0x99, 0x83, 0x28, 0x4d, 0x25, 0xc0, 0x16, 0x6c, 0x5e, 0x24,
-	0x0c, 0xfd, 0x6c, 0xc0, 0xf2, 0xe3, 0xd0, 0x17, 0xfa, 0x02, 0x26, 0x81, 0xf9, 0x04, 0xcc, 0x34,
-	0x78, 0x85, 0x4c, 0xfb, 0xd1, 0x80, 0xcd, 0x43, 0x1a, 0xc5, 0x81, 0x9a, 0x56, 0x23, 0x97, 0x91,
-	0x50, 0x7c, 0x4d, 0x63, 0x16, 0xba, 0x41, 0x62, 0xf7, 0x07, 0xb0, 0x24, 0xf3, 0xc1, 0xe9, 0x31,
-	0xe2, 0x0a, 0xe2, 0x39, 0x61, 0xf2, 0xa2, 0xaa, 0x4a, 0xf8, 0xbe, 0x46, 0xbf, 0xe1, 0xf2, 0xd5,
-	0xe5, 0xf6, 0xa4, 0xd0, 0x74, 0xe3, 0x00, 0x0d, 0xa9, 0xe6, 0xf1, 0x25, 0x54, 0x06, 0xca, 0x32,
-	0xc7, 0x0d, 0x7c, 0x57, 0x37, 0x90, 0xf2, 0xce, 0xca, 0xf4, 0x04, 0xbe, 0x2b, 0x89, 0x76, 0x59,
-	0x6f, 0x55, 0x0b, 0xf3, 0x53, 0x68, 0xa6, 0x4a, 0xd5, 0x64, 0x50, 0x9d, 0x55, 0x3a, 0x1a, 0x29,
-	0xda, 0x78, 0x5e, 0xbd, 0x09, 0x37, 0x2e, 0xf4, 0x0b, 0x43, 0xf8, 0x67, 0x43, 0x87, 0x0b, 0x03,
-	0x9d, 0xf8, 0xfb, 0x73, 0x98, 0xd7, 0xfb, 0xf1, 0xd0, 0x2f, 0x30, 0x10, 0x37, 0x5d, 0x68, 0x5b,
-	0xe9, 0x42, 0xdb, 0x8a, 0x22, 0x3a, 0x53, 0x10, 0x51, 0x59, 0xdf, 0x33, 0xf6, 0x4d, 0x46, 0xa0,
-	0x07, 0x64, 0x40, 0x05, 0xc9, 0x1e, 0xfe, 0x1f, 0x0d, 0x68, 0x66, 0x71, 0x3c, 0xff, 0xcf, 0xa0,
-	0xe1, 0x91, 0x88, 0x91, 0x9e, 0x52, 0x96, 0x4d, 0x85, 0x7b, 0xa5, 0x96, 0x61, 0x9b, 0x13, 0xf2,
-	0xd8, 0xc6, 0x7b, 0x50, 0xc5, 0xc3, 0xc2, 0x9e, 0x51, 0xba, 0x4a, 0xcf, 0xc0, 0x03, 0xd6, 0x2b,
-	0x79, 0x85, 0x5f, 0x84, 0x1e, 0x2d, 0x32, 0xb6, 0x0d, 0xad, 0x3c, 0x09, 0xfd, 0xdb, 0x18, 0x37,
-	0xc9, 0x97, 0x2e, 0x3f, 0x64, 0x54, 0x6e, 0xf1, 0x12, 0xc6, 0xf7, 0xa0, 0x5d, 0x44, 0x44, 0xd6,
-	0x7f, 0x18, 0x50, 0x3f, 0x22, 0xd9, 0x5b, 0xf1, 0xa6, 0x07, 0x5a, 0x70, 0x3a, 0xa5, 0xa2, 0x7c,
-	0xbf, 0x0b, 0x6b, 0xea, 0x99, 0x20, 0x03, 0xc4, 0x44, 0xc1, 0x1b, 0x61, 0x45, 0x91, 0xa7, 0xab,
-	0x65, 0xfe, 0xb9, 0x35, 0x5b, 0xf0, 0xdc, 0x6a, 0xc0, 0x72, 0xca, 0x0f, 0xf4, 0xee, 0x49, 0xda,
-	0x77, 0x9b, 0x28, 0xbd, 0xe3, 0xc8, 0xbc, 0xa1, 0x9b, 0xd6, 0x75, 0xd8, 0x28, 0x14, 0x86, 0xba,
-	0x7e, 0x2f, 0xeb, 0x7c, 0xa6, 0x81, 0xed, 0x86, 0xde, 0x43, 0x22, 0x32, 0xa3, 0x86, 0xf9, 0x3d,
-	0xac, 0x70, 0x41, 0xa3, 0xb4, 0xf3, 0xce, 0x80, 0x7a, 0xc9, 0xeb, 0xfa, 0x76, 0xc1, 0x04, 0x93,
-	0x6d, 0x8a, 0xd4, 0x23, 0x76, 0x83, 0xe7, 0x41, 0xf9, 0x78, 0xb9, 0x75, 0xa9, 0x01, 0xe3, 0x1f,
-	0x22, 0xaa, 0x27, 0xa3, 0x2e, 0xf3, 0x3d, 0xe7, 0x4a, 0xb3, 0x93, 0xca, 0xf7, 0x8a, 0xe6, 0xc0,
-	0x1f, 0x83, 0x7e, 0x3d, 0x1e, 0x8b, 0x74, 0x8a, 0x7f, 0xf0, 0x3a, 0xa3, 0xf3, 0xf3, 0x11, 0xe6,
-	0x61, 0xb6, 0x90, 0xc8, 0x49, 0x67, 0x9a, 0x70, 0x85, 0x8a, 0x7c, 0x04, 0xd5, 0x7b, 0x6e, 0xef,
-	0x34, 0x1e, 0x4f, 0xb2, 0x5b, 0x50, 0xee, 0xd1, 0xb0, 0x17, 0x33, 0x46, 0xc2, 0xde, 0x08, 0x6b,
-	0x6f, 0x1a, 0x92, 0x3b, 0xd4, 0x73, 0x54, 0xa7, 0x0b, 0xbe, 0x61, 0xd3, 0x90, 0x75, 0x17, 0x6a,
-	0x89, 0x50, 0x34, 0xe1, 0x36, 0xcc, 0x91, 0xe1, 0x24, 0x59, 0x6a, 0x9d, 0xe4, 0x1f, 0x32, 0x7b,
-	0x12, 0xb5, 0x35, 0x11, 0x3b, 0xad, 0xa0, 0x8c, 0xec, 0x33, 0x3a, 0xc8, 0xd8, 0x65, 0xed, 0xca,
-	0x6b, 0x9a, 0xa3, 0xbd, 0x91, 0xf8, 0x26, 0x98, 0x47, 0x81, 0x3b, 0x24, 0xd9, 0xf9, 0x75, 0x1f,
-	0x1a, 0x19, 0xf4, 0x6d, 0xc7, 0x63, 0x13, 0xea, 0xf2, 0xe4, 0x94, 0xac, 0x44, 0xb6, 0xbc, 0x57,
-	0x13, 0x0c, 0x73, 0xfd, 0x7b, 0x3d, 0x27, 0x2a, 0xf0, 0xdd, 0x8e, 0x81, 0x77, 0xe5, 0xb4, 0x36,
-	0x2d, 0xf9, 0x0a, 0x49, 0xa0, 0xcc, 0x74, 0x99, 0xc8, 0xd8, 0x2e, 0xa3, 0x95, 0x02, 0xd1, 0xf8,
-	0xdf, 0xc2, 0xc6, 0x04, 0x7d, 0xe7, 0xe3, 0xde, 0x26, 0xbc, 0x57, 0x2c, 0x1d, 0xb5, 0x9b, 0xfa,
-	0x97, 0x51, 0x49, 0x1d, 0x9f, 0xdf, 0xcf, 0xf4, 0xaf, 0x96, 0x88, 0x5d, 0x3a, 0xe4, 0xfd, 0xc9,
-	0x80, 0xba, 0x6c, 0x71, 0x69, 0x3f, 0x7f, 0x42, 0x0d, 0x18, 0x87, 0xac, 0x6c, 0xc0, 0xe5, 0x70,
-	0x2e, 0x81, 0x82, 0xe6, 0x24, 0x87, 0xf3, 0x1c, 0x09, 0xd9, 0x1e, 0x4f, 0x68, 0xff, 0x6b, 0xe9,
-	0xde, 0x80, 0xf5, 0x02, 0x51, 0x5a, 0xcf, 0xbd, 0x4f, 0x7e, 0xe8, 0x0c, 0x7d, 0x41, 0x38, 0xef,
-	0xf8, 0x74, 0x5b, 0x7f, 0x6d, 0xf7, 0xe9, 0xf6, 0x50, 0x6c, 0xab, 0x7f, 0xb5, 0x6e, 0xe7, 0x7e,
-	0x9b, 0xe9, 0xce, 0x2b, 0xc2, 0x67, 0xff, 0x0d, 0x00, 0x00, 0xff, 0xff, 0x96, 0x1e, 0xcb, 0x36,
-	0xf4, 0x1d, 0x00, 0x00,
+	// 2286 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x59, 0xdb, 0x72, 0xdb, 0xc6,
+	0x19, 0x1e, 0x50, 0x07, 0x4b, 0x3f, 0x0f, 0xa2, 0x40, 0x4a, 0x84, 0xa8, 0x58, 0x96, 0x61, 0x27,
+	0x71, 0x93, 0x29, 0x95, 0x28, 0x89, 0x27, 0x93, 0x1e, 0xa6, 0xb2, 0x2c, 0xd9, 0x8e, 0xe5, 0x58,
+	0x81, 0xec, 0x38, 0x93, 0xe9, 0x14, 0xb3, 0x24, 0x56, 0x14, 0x46, 0x20, 0x16, 0xde, 0x5d, 0x50,
+	0xe2, 0x4d, 0x1f, 0xa1, 0x7d, 0x81, 0x4e, 0x6f, 0x3a, 0xd3, 0xde, 0xf7, 0x21, 0xfa, 0x08, 0xe9,
+	0xa3, 0xf4, 0xa2, 0x17, 0xed, 0xec, 0x01, 0x24, 0x40, 0x40, 0xb2, 0xac, 0x7a, 0x3a, 0xb9, 0xd1,
+	0x60, 0xbf, 0x7f, 0xf7, 0x3f, 0xed, 0x7f, 0x5a, 0x0a, 0x5a, 0x1c, 0x75, 0x03, 0xcc, 0x07, 0x28,
+	0x44, 0x7d, 0x4c, 0x3d, 0xc4, 0x51, 0x27, 0xa2, 0x84, 0x13, 0x73, 0x39, 0x47, 0x68, 0x97, 0x5f,
+	0xc7, 0x98, 0x8e, 0x14, 0xbd, 0x5d, 0xe3, 0x24, 0x22, 0x93, 0xfd, 0xed, 0x15, 0x8a, 0xa3, 0xc0,
+	0xef, 0x21, 0xee, 0x93, 0x30, 0x05, 0x57, 0x03, 0xd2, 0x8f, 0xb9, 0x1f, 0xa8, 0xa5, 0xfd, 0x1f,
+	0x03, 0x96, 0x5e, 0x08, 0xc6, 0x0f, 0xf1, 0xb1, 0x1f, 0xfa, 0x62, 0xb3, 0x69, 0xc2, 0x6c, 0x88,
+	0x06, 0xd8, 0x32, 0x36, 0x8d, 0x7b, 0x8b, 0x8e, 0xfc, 0x36, 0x57, 0x61, 0x9e, 0xf5, 0x4e, 0xf0,
+	0x00, 0x59, 0x25, 0x89, 0xea, 0x95, 0x69, 0xc1, 0x8d, 0x1e, 0x09, 0xe2, 0x41, 0xc8, 0xac, 0x99,
+	0xcd, 0x99, 0x7b, 0x8b, 0x4e, 0xb2, 0x34, 0x3b, 0xd0, 0x88, 0xa8, 0x3f, 0x40, 0x74, 0xe4, 0x9e,
+	0xe2, 0x91, 0x9b, 0xec, 0x9a, 0x95, 0xbb, 0x96, 0x35, 0xe9, 0x29, 0x1e, 0xed, 0xea, 0xfd, 0x26,
+	0xcc, 0xf2, 0x51, 0x84, 0xad, 0x39, 0x25, 0x55, 0x7c, 0x9b, 0xb7, 0xa0, 0x2c, 0x54, 0x77, 0x03,
+	0x1c, 0xf6, 0xf9, 0x89, 0x35, 0xbf, 0x69, 0xdc, 0x9b, 0x75, 0x40, 0x40, 0x07, 0x12, 0x31, 0xd7,
+	0x61, 0x91, 0x92, 0x33, 0xb7, 0x47, 0xe2, 0x90, 0x5b, 0x37, 0x24, 0x79, 0x81, 0x92, 0xb3, 0x5d,
+	0xb1, 0x36, 0xef, 0xc2, 0xfc, 0xb1, 0x8f, 0x03, 0x8f, 0x59, 0x0b, 0x9b, 0x33, 0xf7, 0xca, 0xdb,
+	0x95, 0x8e, 0xf2, 0xd7, 0xbe, 0x00, 0x1d, 0x4d, 0xb3, 0xff, 0x6a, 0x40, 0xfd, 0x48, 0x1a, 0x93,
+	0x72, 0xc1, 0x87, 0xb0, 0x24, 0xa4, 0x74, 0x11, 0xc3, 0xae, 0xb6, 0x5b, 0x79, 0xa3, 0x96, 0xc0,
+	0xea, 0x88, 0xf9, 0x1c, 0xd4, 0xbd, 0xb8, 0xde, 0xf8, 0x30, 0xb3, 0x4a, 0x52, 0x9c, 0xdd, 0xc9,
+	0x5f, 0xe5, 0x94, 0xab, 0x9d, 0x3a, 0xcf, 0x02, 0x4c, 0x38, 0x74, 0x88, 0x29, 0xf3, 0x49, 0x68,
+	0xcd, 0x48, 0x89, 0xc9, 0x52, 0x28, 0x6a, 0x2a, 0xa9, 0xbb, 0x27, 0x28, 0xec, 0x63, 0x07, 0xb3,
+	0x38, 0xe0, 0xe6, 0x63, 0xa8, 0x76, 0xf1, 0x31, 0xa1, 0x19, 0x45, 0xcb, 0xdb, 0x77, 0x0a, 0xa4,
+	0x4f, 0x9b, 0xe9, 0x54, 0xd4, 0x49, 0x6d, 0xcb, 0x3e, 0x54, 0xd0, 0x31, 0xc7, 0xd4, 0x4d, 0xdd,
+	0xf4, 0x15, 0x19, 0x95, 0xe5, 0x41, 0x05, 0xdb, 0xff, 0x32, 0xa0, 0xf6, 0x92, 0x61, 0x7a, 0x88,
+	0xe9, 0xc0, 0x67, 0x4c, 0x87, 0xd4, 0x09, 0x61, 0x3c, 0x09, 0x29, 0xf1, 0x2d, 0xb0, 0x98, 0x61,
+	0xaa, 0x03, 0x4a, 0x7e, 0x9b, 0x1f, 0xc3, 0x72, 0x84, 0x18, 0x3b, 0x23, 0xd4, 0x73, 0x7b, 0x27,
+	0xb8, 0x77, 0xca, 0xe2, 0x81, 0xf4, 0xc3, 0xac, 0x53, 0x4f, 0x08, 0xbb, 0x1a, 0x37, 0xbf, 0x05,
+	0x88, 0xa8, 0x3f, 0xf4, 0x03, 0xdc, 0xc7, 0x2a, 0xb0, 0xca, 0xdb, 0x9f, 0x16, 0x68, 0x9b, 0xd5,
+	0xa5, 0x73, 0x38, 0x3e, 0xb3, 0x17, 0x72, 0x3a, 0x72, 0x52, 0x4c, 0xda, 0xbf, 0x82, 0xa5, 0x29,
+	0xb2, 0x59, 0x87, 0x99, 0x53, 0x3c, 0xd2, 0x9a, 0x8b, 0x4f, 0xb3, 0x09, 0x73, 0x43, 0x14, 0xc4,
+	0x58, 0x6b, 0xae, 0x16, 0x5f, 0x95, 0xbe, 0x34, 0xec, 0x1f, 0x0d, 0xa8, 0x3c, 0xec, 0xbe, 0xc1,
+	0xee, 0x1a, 0x94, 0xbc, 0xae, 0x3e, 0x5b, 0xf2, 0xba, 0x63, 0x3f, 0xcc, 0xa4, 0xfc, 0xf0, 0xbc,
+	0xc0, 0xb4, 0xad, 0x02, 0xd3, 0x1e, 0x76, 0xff, 0x3f, 0x86, 0xfd, 0xc5, 0x80, 0xf2, 0x44, 0x12,
+	0x33, 0x0f, 0xa0, 0x2e, 0xf4, 0x74, 0xa3, 0x09, 0x66, 0x19, 0x52, 0xcb, 0xdb, 0x6f, 0xbc, 0x00,
+	0x67, 0x29, 0xce, 0xac, 0x99, 0xb9, 0x0f, 0x35, 0xaf, 0x9b, 0xe1, 0xa5, 0x32, 0xe8, 0xd6, 0x1b,
+	0x2c, 0x76, 0xaa, 0x5e, 0x6a, 0xc5, 0xec, 0x0f, 0xa1, 0x7c, 0xe8, 0x87, 0x7d, 0x07, 0xbf, 0x8e,
+	0x31, 0xe3, 0x22, 0x95, 0x22, 0x34, 0x0a, 0x08, 0xf2, 0xb4, 0x91, 0xc9, 0xd2, 0xbe, 0x07, 0x15,
+	0xb5, 0x91, 0x45, 0x24, 0x64, 0xf8, 0x92, 0x9d, 0x1f, 0x41, 0xe5, 0x28, 0xc0, 0x38, 0x4a, 0x78,
+	0xb6, 0x61, 0xc1, 0x8b, 0xa9, 0x2c, 0xaa, 0x72, 0xeb, 0x8c, 0x33, 0x5e, 0xdb, 0x4b, 0x50, 0xd5,
+	0x7b, 0x15, 0x5b, 0xfb, 0x9f, 0x06, 0x98, 0x7b, 0xe7, 0xb8, 0x17, 0x73, 0xfc, 0x98, 0x90, 0xd3,
+	0x84, 0x47, 0x51, 0x7d, 0xdd, 0x00, 0x88, 0x10, 0x45, 0x03, 0xcc, 0x31, 0x55, 0xe6, 0x2f, 0x3a,
+	0x29, 0xc4, 0x3c, 0x84, 0x45, 0x7c, 0xce, 0x29, 0x72, 0x71, 0x38, 0x94, 0x95, 0xb6, 0xbc, 0xfd,
+	0x59, 0x81, 0x77, 0xf2, 0xd2, 0x3a, 0x7b, 0xe2, 0xd8, 0x5e, 0x38, 0x54, 0x31, 0xb1, 0x80, 0xf5,
+	0xb2, 0xfd, 0x0b, 0xa8, 0x66, 0x48, 0x6f, 0x15, 0x0f, 0xc7, 0xd0, 0xc8, 0x88, 0xd2, 0x7e, 0xbc,
+	0x05, 0x65, 0x7c, 0xee, 0x73, 0x97, 0x71, 0xc4, 0x63, 0xa6, 0x1d, 0x04, 0x02, 0x3a, 0x92, 0x88,
+	0x6c, 0x23, 0xdc, 0x23, 0x31, 0x1f, 0xb7, 0x11, 0xb9, 0xd2, 0x38, 0xa6, 0x49, 0x16, 0xe8, 0x95,
+	0x3d, 0x84, 0xfa, 0x23, 0xcc, 0x55, 0x5d, 0x49, 0xdc, 0xb7, 0x0a, 0xf3, 0xd2, 0x70, 0x15, 0x71,
+	0x8b, 0x8e, 0x5e, 0x99, 0x77, 0xa0, 0xea, 0x87, 0xbd, 0x20, 0xf6, 0xb0, 0x3b, 0xf4, 0xf1, 0x19,
+	0x93, 0x22, 0x16, 0x9c, 0x8a, 0x06, 0xbf, 0x13, 0x98, 0xf9, 0x3e, 0xd4, 0xf0, 0xb9, 0xda, 0xa4,
+	0x99, 0xa8, 0xb6, 0x55, 0xd5, 0xa8, 0x2c, 0xd0, 0xcc, 0xc6, 0xb0, 0x9c, 0x92, 0xab, 0xad, 0x3b,
+	0x84, 0x65, 0x55, 0x19, 0x53, 0xc5, 0xfe, 0x6d, 0xaa, 0x6d, 0x9d, 0x4d, 0x21, 0x76, 0x0b, 0x56,
+	0x1e, 0x61, 0x9e, 0x0a, 0x61, 0x6d, 0xa3, 0xfd, 0x03, 0xac, 0x4e, 0x13, 0xb4, 0x12, 0xbf, 0x81,
+	0x72, 0x36, 0xe9, 0x84, 0xf8, 0x8d, 0x02, 0xf1, 0xe9, 0xc3, 0xe9, 0x23, 0x76, 0x13, 0xcc, 0x23,
+	0xcc, 0x1d, 0x8c, 0xbc, 0xe7, 0x61, 0x30, 0x4a, 0x24, 0xae, 0x40, 0x23, 0x83, 0xea, 0x10, 0x9e,
+	0xc0, 0xaf, 0xa8, 0xcf, 0x71, 0xb2, 0x7b, 0x15, 0x9a, 0x59, 0x58, 0x6f, 0xff, 0x1a, 0x96, 0x55,
+	0x73, 0x7a, 0x31, 0x8a, 0x92, 0xcd, 0xe6, 0x17, 0x50, 0x56, 0xea, 0xb9, 0xb2, 0xc1, 0x0b, 0x95,
+	0x6b, 0xdb, 0xcd, 0xce, 0x78, 0x5e, 0x91, 0x3e, 0xe7, 0xf2, 0x04, 0xf0, 0xf1, 0xb7, 0xd0, 0x33,
+	0xcd, 0x6b, 0xa2, 0x90, 0x83, 0x8f, 0x29, 0x66, 0x27, 0x22, 0xa4, 0xd2, 0x0a, 0x65, 0x61, 0xbd,
+	0xbd, 0x05, 0x2b, 0x4e, 0x1c, 0x3e, 0xc6, 0x28, 0xe0, 0x27, 0xb2, 0x71, 0x24, 0x07, 0x2c, 0x58,
+	0x9d, 0x26, 0xe8, 0x23, 0x9f, 0x83, 0xf5, 0xa4, 0x1f, 0x12, 0x8a, 0x15, 0x71, 0x8f, 0x52, 0x42,
+	0x33, 0x25, 0x85, 0x73, 0x4c, 0xc3, 0x49, 0xa1, 0x90, 0x4b, 0x7b, 0x1d, 0xd6, 0x0a, 0x4e, 0x69,
+	0x96, 0x5f, 0x09, 0xa5, 0x45, 0x3d, 0xc9, 0x46, 0xf2, 0x1d, 0xa8, 0x9e, 0x21, 0x9f, 0xbb, 0x11,
+	0x61, 0x93, 0x60, 0x5a, 0x74, 0x2a, 0x02, 0x3c, 0xd4, 0x98, 0xb2, 0x2c, 0x7d, 0x56, 0xf3, 0xdc,
+	0x86, 0xd5, 0x43, 0x8a, 0x8f, 0x03, 0xbf, 0x7f, 0x32, 0x95, 0x20, 0x62, 0x26, 0x93, 0x8e, 0x4b,
+	0x32, 0x24, 0x59, 0xda, 0x7d, 0x68, 0xe5, 0xce, 0xe8, 0xb8, 0x3a, 0x80, 0x9a, 0xda, 0xe5, 0x52,
+	0x39, 0x57, 0x24, 0xf5, 0xfc, 0xfd, 0x0b, 0x23, 0x3b, 0x3d, 0x85, 0x38, 0xd5, 0x5e, 0x6a, 0xc5,
+	0xec, 0x7f, 0x1b, 0x60, 0xee, 0x44, 0x51, 0x30, 0xca, 0x6a, 0x56, 0x87, 0x19, 0xf6, 0x3a, 0x48,
+	0x4a, 0x0c, 0x7b, 0x1d, 0x88, 0x12, 0x73, 0x4c, 0x68, 0x0f, 0xeb, 0x64, 0x55, 0x0b, 0x31, 0x06,
+	0xa0, 0x20, 0x20, 0x67, 0x6e, 0x6a, 0x86, 0x95, 0x95, 0x61, 0xc1, 0xa9, 0x4b, 0x82, 0x33, 0xc1,
+	0xf3, 0x03, 0xd0, 0xec, 0xbb, 0x1a, 0x80, 0xe6, 0xae, 0x39, 0x00, 0xfd, 0xcd, 0x80, 0x46, 0xc6,
+	0x7a, 0xed, 0xe3, 0x9f, 0xde, 0xa8, 0xd6, 0x80, 0xe5, 0x03, 0xd2, 0x3b, 0x55, 0x55, 0x2f, 0x49,
+	0x8d, 0x26, 0x98, 0x69, 0x70, 0x92, 0x78, 0x2f, 0xc3, 0x20, 0xb7, 0x79, 0x15, 0x9a, 0x59, 0x58,
+	0x6f, 0xff, 0xbb, 0x01, 0x96, 0x6e, 0x11, 0xfb, 0x98, 0xf7, 0x4e, 0x76, 0xd8, 0xc3, 0xee, 0x38,
+	0x0e, 0x9a, 0x30, 0x27, 0x47, 0x71, 0xe9, 0x80, 0x8a, 0xa3, 0x16, 0x66, 0x0b, 0x6e, 0x78, 0x5d,
+	0x57, 0xb6, 0x46, 0xdd, 0x1d, 0xbc, 0xee, 0x37, 0xa2, 0x39, 0xae, 0xc1, 0xc2, 0x00, 0x9d, 0xbb,
+	0x94, 0x9c, 0x31, 0x3d, 0x0c, 0xde, 0x18, 0xa0, 0x73, 0x87, 0x9c, 0x31, 0x39, 0xa8, 0xfb, 0x4c,
+	0x4e, 0xe0, 0x5d, 0x3f, 0x0c, 0x48, 0x9f, 0xc9, 0xeb, 0x5f, 0x70, 0x6a, 0x1a, 0x7e, 0xa0, 0x50,
+	0x91, 0x6b, 0x54, 0xa6, 0x51, 0xfa, 0x72, 0x17, 0x9c, 0x0a, 0x4d, 0xe5, 0x96, 0xfd, 0x08, 0xd6,
+	0x0a, 0x74, 0xd6, 0xb7, 0xf7, 0x11, 0xcc, 0xab, 0xd4, 0xd0, 0xd7, 0x66, 0xea, 0xe7, 0xc4, 0xb7,
+	0xe2, 0xaf, 0x4e, 0x03, 0xbd, 0xc3, 0xfe, 0x83, 0x01, 0x37, 0xb3, 0x9c, 0x76, 0x82, 0x40, 0x0c,
+	0x60, 0xec, 0xdd, 0xbb, 0x20, 0x67, 0xd9, 0x6c, 0x81, 0x65, 0x07, 0xb0, 0x71, 0x91, 0x3e, 0xd7,
+	0x30, 0xef, 0xe9, 0xf4, 0xdd, 0xee, 0x44, 0xd1, 0xe5, 0x86, 0xa5, 0xf5, 0x2f, 0x65, 0xf4, 0xcf,
+	0x3b, 0x5d, 0x32, 0xbb, 0x86, 0x56, 0x6d, 0xb0, 0x52, 0x75, 0x41, 0x4d, 0x1c, 0x49, 0x98, 0x1e,
+	0xc0, 0x5a, 0x01, 0x4d, 0x0b, 0xd9, 0x12, 0xd3, 0xc7, 0x78, 0x62, 0x29, 0x6f, 0xb7, 0x3a, 0xd3,
+	0x6f, 0x67, 0x7d, 0x40, 0x6f, 0x13, 0xb9, 0xf0, 0x0c, 0x31, 0x91, 0x46, 0x19, 0x21, 0xcf, 0xa0,
+	0x99, 0x85, 0x35, 0xff, 0x2f, 0xa6, 0xf8, 0xdf, 0xcc, 0xf1, 0xcf, 0x1c, 0x4b, 0xa4, 0xb4, 0x60,
+	0x45, 0xe1, 0x49, 0x2f, 0x48, 0xe4, 0x7c, 0x0e, 0xab, 0xd3, 0x04, 0x2d, 0xa9, 0x0d, 0x0b, 0x53,
+	0xcd, 0x64, 0xbc, 0x16, 0xa7, 0x5e, 0x21, 0x9f, 0xef, 0x93, 0x69, 0x7e, 0x97, 0x9e, 0x5a, 0x83,
+	0x56, 0xee, 0x94, 0x4e, 0x71, 0x0b, 0x56, 0x8f, 0x38, 0x89, 0x52, 0x7e, 0x4d, 0x14, 0x5c, 0x83,
+	0x56, 0x8e, 0xa2, 0x0f, 0xfd, 0x0e, 0x6e, 0x4e, 0x91, 0x9e, 0xf9, 0xa1, 0x3f, 0x88, 0x07, 0x57,
+	0x50, 0xc6, 0xbc, 0x0d, 0xb2, 0x37, 0xba, 0xdc, 0x1f, 0xe0, 0x64, 0x88, 0x9c, 0x71, 0xca, 0x02,
+	0x7b, 0xa1, 0x20, 0xfb, 0x97, 0xb0, 0x71, 0x11, 0xff, 0x2b, 0xf8, 0x48, 0x2a, 0x8e, 0x28, 0x2f,
+	0xb0, 0xa9, 0x0d, 0x56, 0x9e, 0xa4, 0x8d, 0xea, 0xc2, 0xed, 0x69, 0xda, 0xcb, 0x90, 0xfb, 0xc1,
+	0x8e, 0x28, 0xb5, 0xef, 0xc8, 0xb0, 0xbb, 0x60, 0x5f, 0x26, 0x43, 0x6b, 0xd2, 0x04, 0xf3, 0x11,
+	0x4e, 0xf6, 0x8c, 0x03, 0xf3, 0x63, 0x68, 0x64, 0x50, 0xed, 0x89, 0x26, 0xcc, 0x21, 0xcf, 0xa3,
+	0xc9, 0x98, 0xa0, 0x16, 0xc2, 0x07, 0x0e, 0x66, 0xf8, 0x02, 0x1f, 0xe4, 0x49, 0x5a, 0xf2, 0x16,
+	0xb4, 0xbe, 0x4b, 0xe1, 0x22, 0xa5, 0x0b, 0x4b, 0xc2, 0xa2, 0x2e, 0x09, 0xf6, 0x3e, 0x58, 0xf9,
+	0x03, 0xd7, 0x2a, 0x46, 0x37, 0xd3, 0x7c, 0x26, 0xd1, 0x9a, 0x88, 0xaf, 0x41, 0xc9, 0xf7, 0xf4,
+	0x63, 0xa4, 0xe4, 0x7b, 0x99, 0x8b, 0x28, 0x4d, 0x05, 0xc0, 0x26, 0x6c, 0x5c, 0xc4, 0x4c, 0xdb,
+	0xd9, 0x80, 0xe5, 0x27, 0xa1, 0xcf, 0x55, 0x02, 0x26, 0x8e, 0xf9, 0x04, 0xcc, 0x34, 0x78, 0x85,
+	0x48, 0xfb, 0xd1, 0x80, 0x8d, 0x43, 0x12, 0xc5, 0x81, 0x9c, 0x56, 0x23, 0x44, 0x71, 0xc8, 0xbf,
+	0x26, 0x31, 0x0d, 0x51, 0x90, 0xe8, 0xfd, 0x01, 0x2c, 0x89, 0x78, 0x70, 0x7b, 0x14, 0x23, 0x8e,
+	0x3d, 0x37, 0x4c, 0x5e, 0x54, 0x55, 0x01, 0xef, 0x2a, 0xf4, 0x1b, 0x26, 0x5e, 0x5d, 0xa8, 0x27,
+	0x98, 0xa6, 0x1b, 0x07, 0x28, 0x48, 0x36, 0x8f, 0x2f, 0xa1, 0x32, 0x90, 0x9a, 0xb9, 0x28, 0xf0,
+	0x91, 0x6a, 0x20, 0xe5, 0xed, 0x95, 0xe9, 0x09, 0x7c, 0x47, 0x10, 0x9d, 0xb2, 0xda, 0x2a, 0x17,
+	0xe6, 0xa7, 0xd0, 0x4c, 0x95, 0xaa, 0xc9, 0xa0, 0x3a, 0x2b, 0x65, 0x34, 0x52, 0xb4, 0xf1, 0xbc,
+	0x7a, 0x1b, 0x6e, 0x5d, 0x68, 0x97, 0x76, 0xe1, 0x9f, 0x0d, 0xe5, 0x2e, 0xed, 0xe8, 0xc4, 0xde,
+	0x9f, 0xc3, 0xbc, 0xda, 0x6f, 0x19, 0x97, 0x29, 0xa8, 0x37, 0x5d, 0xa8, 0x5b, 0xe9, 0x42, 0xdd,
+	0x8a, 0x3c, 0x3a, 0x53, 0xe0, 0x51, 0x51, 0xdf, 0x33, 0xfa, 0x4d, 0x46, 0xa0, 0x87, 0x78, 0x40,
+	0x38, 0xce, 0x5e, 0xfe, 0x1f, 0x0d, 0x68, 0x66, 0x71, 0x7d, 0xff, 0x9f, 0x41, 0xc3, 0xc3, 0x11,
+	0xc5, 0x3d, 0x29, 0x2c, 0x1b, 0x0a, 0x0f, 0x4a, 0x96, 0xe1, 0x98, 0x13, 0xf2, 0x58, 0xc7, 0x07,
+	0x50, 0xd5, 0x97, 0xa5, 0x7b, 0x46, 0xe9, 0x2a, 0x3d, 0xa3, 0x32, 0x48, 0xad, 0x44, 0x0a, 0xbf,
+	0x0c, 0x3d, 0x52, 0xa4, 0x6c, 0x1b, 0xac, 0x3c, 0x49, 0xdb, 0xf7, 0x0c, 0x9a, 0xbb, 0x28, 0x7c,
+	0x41, 0x51, 0x98, 0xed, 0x0f, 0xd7, 0x7c, 0xc0, 0xb5, 0x60, 0x65, 0x8a, 0x9d, 0x96, 0xb3, 0x3e,
+	0x6e, 0xc6, 0xaf, 0x10, 0x3b, 0xa4, 0x44, 0xa8, 0xe2, 0x25, 0x0a, 0xbe, 0x07, 0xed, 0x22, 0xa2,
+	0x3e, 0xfa, 0x0f, 0xf1, 0x6b, 0x2d, 0xce, 0x66, 0xdf, 0xdb, 0x06, 0x4e, 0x41, 0x14, 0x94, 0x8a,
+	0xf2, 0xea, 0x3e, 0xb4, 0xe4, 0x73, 0x44, 0x5c, 0x04, 0xe5, 0x05, 0x6f, 0x91, 0x15, 0x49, 0x9e,
+	0xae, 0xca, 0xf9, 0x67, 0xdd, 0x6c, 0xc1, 0xb3, 0xae, 0x01, 0xcb, 0x29, 0x3b, 0xb4, 0x75, 0x4f,
+	0xd3, 0xb6, 0x3b, 0x58, 0xca, 0xc5, 0xde, 0xf5, 0xcc, 0xb4, 0x6f, 0xc2, 0x7a, 0x21, 0x33, 0x2d,
+	0xeb, 0xf7, 0xa2, 0x9f, 0x64, 0x1a, 0xe5, 0x4e, 0xe8, 0x89, 0x1f, 0x3d, 0xd2, 0x23, 0x8d, 0xf9,
+	0x3d, 0xac, 0x30, 0x4e, 0xa2, 0xb4, 0xf1, 0xee, 0x80, 0x78, 0x49, 0x10, 0xdc, 0x2d, 0x98, 0x94,
+	0xb2, 0xcd, 0x97, 0x78, 0xd8, 0x69, 0xb0, 0x3c, 0x28, 0x1e, 0x49, 0x77, 0x2e, 0x55, 0x60, 0xfc,
+	0x83, 0x47, 0xf5, 0x64, 0xd4, 0xa5, 0xbe, 0xe7, 0x5e, 0x69, 0x46, 0x93, 0x79, 0x55, 0x51, 0x27,
+	0x14, 0x62, 0xfe, 0x7a, 0x3c, 0x7e, 0xa9, 0x54, 0xfa, 0xe0, 0x4d, 0x4a, 0xe7, 0xe7, 0x30, 0x1d,
+	0x87, 0xd9, 0x82, 0x25, 0x26, 0xaa, 0x69, 0xc2, 0x15, 0x2a, 0xff, 0x11, 0x54, 0x1f, 0xa0, 0xde,
+	0x69, 0x3c, 0x9e, 0x98, 0x37, 0xa1, 0xdc, 0x23, 0x61, 0x2f, 0xa6, 0x14, 0x87, 0xbd, 0x91, 0xae,
+	0xf1, 0x69, 0x48, 0xec, 0x90, 0xcf, 0x5e, 0x15, 0x2e, 0xfa, 0xad, 0x9c, 0x86, 0xec, 0xfb, 0x50,
+	0x4b, 0x98, 0x6a, 0x15, 0xee, 0xc2, 0x1c, 0x1e, 0x4e, 0x82, 0xa5, 0xd6, 0x49, 0xfe, 0xf1, 0xb3,
+	0x27, 0x50, 0x47, 0x11, 0x75, 0x47, 0xe7, 0x84, 0xe2, 0x7d, 0x4a, 0x06, 0x19, 0xbd, 0xec, 0x1d,
+	0x58, 0x2b, 0xa0, 0xbd, 0x15, 0x7b, 0xf1, 0x5b, 0x53, 0x80, 0x86, 0x38, 0x3b, 0x27, 0xef, 0x43,
+	0x23, 0x83, 0x5e, 0x77, 0x0c, 0x37, 0xa1, 0x2e, 0x6e, 0x4e, 0xf2, 0x4a, 0x78, 0x8b, 0xbc, 0x9a,
+	0x60, 0x3a, 0xd6, 0xbf, 0x87, 0xd6, 0x18, 0x7c, 0xb7, 0xe3, 0xe6, 0x7d, 0xb0, 0xf2, 0x9c, 0xaf,
+	0x10, 0x04, 0x52, 0x4d, 0x44, 0x79, 0x46, 0x77, 0xe1, 0xad, 0x14, 0xa8, 0x95, 0xff, 0x2d, 0xac,
+	0x4f, 0xd0, 0x77, 0x3e, 0x56, 0x6e, 0xc0, 0x7b, 0xc5, 0xdc, 0xb5, 0x74, 0x53, 0xfd, 0x02, 0x2b,
+	0xa8, 0xe3, 0xfb, 0xfb, 0x19, 0x2c, 0xa7, 0xb0, 0x4b, 0x87, 0xc9, 0x3f, 0x19, 0x50, 0x17, 0xad,
+	0x34, 0x6d, 0xe7, 0x4f, 0xa8, 0xd1, 0xeb, 0x61, 0x2e, 0xeb, 0x70, 0xf1, 0x08, 0x10, 0x40, 0x41,
+	0x73, 0x12, 0x8f, 0x80, 0x1c, 0x49, 0x1f, 0x7b, 0x32, 0xa1, 0xfd, 0xaf, 0xa5, 0x7b, 0x1d, 0xd6,
+	0x0a, 0x58, 0x29, 0x39, 0x0f, 0x3e, 0xf9, 0xa1, 0x33, 0xf4, 0x39, 0x66, 0xac, 0xe3, 0x93, 0x2d,
+	0xf5, 0xb5, 0xd5, 0x27, 0x5b, 0x43, 0xbe, 0x25, 0xff, 0xa5, 0xbb, 0x95, 0xfb, 0x0d, 0xa8, 0x3b,
+	0x2f, 0x09, 0x9f, 0xfd, 0x77, 0x00, 0x21, 0x99, 0x97, 0xb8, 0x5c, 0x1e, 0x00, 0x00,
 }
@@ -29,77 +29,79 @@ const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 func init() { proto.RegisterFile("tabletmanagerservice.proto", fileDescriptor_9ee75fe63cfd9360) }
 
 var fileDescriptor_9ee75fe63cfd9360 = []byte{
-	// 1116 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x98, 0xdb, 0x6f, 0x1b, 0x45,
-	0x14, 0xc6, 0x89, 0x04, 0x95, 0x98, 0x72, 0x1d, 0x2a, 0x2a, 0x05, 0x89, 0x5b, 0x13, 0x28, 0x71,
-	0xb1, 0x7b, 0xa1, 0xbc, 0xbb, 0x97, 0xa4, 0x41, 0x8d, 0x30, 0x76, 0x43, 0x10, 0x48, 0x95, 0x26,
-	0xf6, 0x89, 0xbd, 0x64, 0xbd, 0xb3, 0xcc, 0x8c, 0x2d, 0xf2, 0x84, 0xd4, 0x57, 0x24, 0xfe, 0x66,
-	0xb4, 0xeb, 0x9d, 0xdd, 0x33, 0xbb, 0x67, 0x8f, 0x37, 0x6f, 0x96, 0xbf, 0xdf, 0x9c, 0x6f, 0x2e,
-	0x67, 0xce, 0xcc, 0x8e, 0xd8, 0x75, 0xea, 0x3c, 0x06, 0xb7, 0x54, 0x89, 0x9a, 0x83, 0xb1, 0x60,
-	0xd6, 0xd1, 0x14, 0xfa, 0xa9, 0xd1, 0x4e, 0xcb, 0x5b, 0x94, 0xb6, 0x7b, 0x3b, 0xf8, 0x77, 0xa6,
-	0x9c, 0xda, 0xe0, 0x0f, 0xdf, 0xf4, 0xc4, 0xfb, 0xaf, 0x72, 0xed, 0x64, 0xa3, 0xc9, 0x63, 0xf1,
-	0xf6, 0x28, 0x4a, 0xe6, 0xf2, 0xf3, 0x7e, 0xb3, 0x4d, 0x26, 0x8c, 0xe1, 0xaf, 0x15, 0x58, 0xb7,
-	0xfb, 0x45, 0xab, 0x6e, 0x53, 0x9d, 0x58, 0xf8, 0xfa, 0x2d, 0xf9, 0x52, 0xbc, 0x33, 0x89, 0x01,
-	0x52, 0x49, 0xb1, 0xb9, 0xe2, 0x83, 0x7d, 0xd9, 0x0e, 0x94, 0xd1, 0x5e, 0x8b, 0x9b, 0xcf, 0xff,
-	0x86, 0xe9, 0xca, 0xc1, 0x0b, 0xad, 0x2f, 0xe5, 0x3e, 0xd1, 0x04, 0xe9, 0x3e, 0xf2, 0x37, 0xdb,
-	0xb0, 0x32, 0xfe, 0x6f, 0xe2, 0xdd, 0x23, 0x70, 0x93, 0xe9, 0x02, 0x96, 0x4a, 0xde, 0x21, 0x9a,
-	0x95, 0xaa, 0x8f, 0xbd, 0xc7, 0x43, 0x65, 0xe4, 0xb9, 0xf8, 0xe0, 0x08, 0xdc, 0x08, 0xcc, 0x32,
-	0xb2, 0x36, 0xd2, 0x89, 0x95, 0x77, 0xe9, 0x96, 0x08, 0xf1, 0x1e, 0xdf, 0x75, 0x20, 0xf1, 0x14,
-	0x4d, 0xc0, 0x8d, 0x41, 0xcd, 0x7e, 0x4e, 0xe2, 0x2b, 0x72, 0x8a, 0x90, 0xce, 0x4d, 0x51, 0x80,
-	0x95, 0xf1, 0x95, 0x78, 0xaf, 0x10, 0xce, 0x4c, 0xe4, 0x40, 0x32, 0x2d, 0x73, 0xc0, 0x3b, 0x7c,
-	0xbb, 0x95, 0x2b, 0x2d, 0xfe, 0x10, 0xe2, 0xe9, 0x42, 0x25, 0x73, 0x78, 0x75, 0x95, 0x82, 0xa4,
-	0x66, 0xb8, 0x92, 0x7d, 0xf8, 0xfd, 0x2d, 0x14, 0xee, 0xff, 0x18, 0x2e, 0x0c, 0xd8, 0xc5, 0xc4,
-	0xa9, 0x96, 0xfe, 0x63, 0x80, 0xeb, 0x7f, 0xc8, 0xe1, 0xb5, 0x1e, 0xaf, 0x92, 0x17, 0xa0, 0x62,
-	0xb7, 0x78, 0xba, 0x80, 0xe9, 0x25, 0xb9, 0xd6, 0x21, 0xc2, 0xad, 0x75, 0x9d, 0x2c, 0x8d, 0x52,
-	0xf1, 0xf1, 0xf1, 0x3c, 0xd1, 0x06, 0x36, 0xf2, 0x73, 0x63, 0xb4, 0x91, 0x3d, 0x22, 0x42, 0x83,
-	0xf2, 0x76, 0xf7, 0xba, 0xc1, 0xe1, 0xec, 0xc5, 0x5a, 0xcd, 0x8a, 0x3d, 0x42, 0xcf, 0x5e, 0x05,
-	0xf0, 0xb3, 0x87, 0xb9, 0xd2, 0xe2, 0x4f, 0xf1, 0xe1, 0xc8, 0xc0, 0x45, 0x1c, 0xcd, 0x17, 0x7e,
-	0x27, 0x52, 0x93, 0x52, 0x63, 0xbc, 0xd1, 0x41, 0x17, 0x14, 0x6f, 0x96, 0x61, 0x9a, 0xc6, 0x57,
-	0x85, 0x0f, 0x95, 0x44, 0x48, 0xe7, 0x36, 0x4b, 0x80, 0xe1, 0x4c, 0x7e, 0xa9, 0xa7, 0x97, 0x79,
-	0x75, 0xb5, 0x64, 0x26, 0x57, 0x32, 0x97, 0xc9, 0x98, 0xc2, 0x6b, 0x71, 0x9a, 0xc4, 0x55, 0x78,
-	0xaa, 0x5b, 0x18, 0xe0, 0xd6, 0x22, 0xe4, 0x70, 0x82, 0x15, 0x85, 0xf2, 0x10, 0xdc, 0x74, 0x31,
-	0xb4, 0xcf, 0xce, 0x15, 0x99, 0x60, 0x0d, 0x8a, 0x4b, 0x30, 0x02, 0x2e, 0x1d, 0xff, 0x11, 0x9f,
-	0x86, 0xf2, 0x30, 0x8e, 0x47, 0x26, 0x5a, 0x5b, 0x79, 0x7f, 0x6b, 0x24, 0x8f, 0x7a, 0xef, 0x07,
-	0xd7, 0x68, 0xd1, 0x3e, 0xe4, 0x61, 0x9a, 0x76, 0x18, 0xf2, 0x30, 0x4d, 0xbb, 0x0f, 0x39, 0x87,
-	0xb1, 0xe3, 0x18, 0xd2, 0x38, 0x9a, 0x2a, 0x17, 0xe9, 0x24, 0x2b, 0x26, 0x2b, 0x4b, 0x3a, 0x36,
-	0x28, 0xce, 0x91, 0x80, 0x71, 0xe6, 0x9c, 0x28, 0xeb, 0xc0, 0x14, 0x66, 0x54, 0xe6, 0x60, 0x80,
-	0xcb, 0x9c, 0x90, 0xc3, 0x35, 0x70, 0xa3, 0x8c, 0xb4, 0x8d, 0xb2, 0x4e, 0x90, 0x35, 0x30, 0x44,
-	0xb8, 0x1a, 0x58, 0x27, 0x71, 0xb9, 0x38, 0x53, 0x91, 0x3b, 0xd4, 0x95, 0x13, 0xd5, 0xbe, 0xc6,
-	0x70, 0xe5, 0xa2, 0x81, 0x62, 0xaf, 0x89, 0xd3, 0x29, 0x9a, 0x5a, 0xd2, 0xab, 0xc6, 0x70, 0x5e,
-	0x0d, 0x14, 0x6f, 0x84, 0x9a, 0x78, 0x12, 0x25, 0xd1, 0x72, 0xb5, 0x24, 0x37, 0x02, 0x8d, 0x72,
-	0x1b, 0xa1, 0xad, 0x45, 0xd9, 0x81, 0xa5, 0xf8, 0x68, 0xe2, 0x94, 0x71, 0x78, 0xb4, 0xf4, 0x10,
-	0x42, 0xc8, 0x9b, 0xf6, 0x3a, 0xb1, 0xa5, 0xdd, 0xbf, 0x3b, 0x62, 0xb7, 0x2e, 0x9f, 0x26, 0x2e,
-	0x8a, 0x87, 0x17, 0x0e, 0x8c, 0xfc, 0xa1, 0x43, 0xb4, 0x0a, 0xf7, 0x7d, 0x78, 0x7c, 0xcd, 0x56,
-	0xf8, 0x60, 0x38, 0x02, 0x4f, 0x59, 0xf2, 0x60, 0x40, 0x3a, 0x77, 0x30, 0x04, 0x18, 0x9e, 0xdc,
-	0x5f, 0x51, 0x1f, 0xb2, 0xf2, 0x40, 0x4e, 0x6e, 0x1d, 0xe2, 0x26, 0xb7, 0xc9, 0xe2, 0x64, 0xc2,
-	0x6a, 0x95, 0xe1, 0x64, 0x32, 0xd1, 0x28, 0x97, 0x4c, 0x6d, 0x2d, 0xf0, 0x78, 0xc7, 0x60, 0x61,
-	0x6b, 0x32, 0xd5, 0x21, 0x6e, 0xbc, 0x4d, 0x16, 0x9f, 0xbb, 0xc7, 0x49, 0xe4, 0x36, 0x45, 0x83,
-	0x3c, 0x77, 0x2b, 0x99, 0x3b, 0x77, 0x31, 0x55, 0x06, 0x7f, 0xb3, 0x23, 0x6e, 0x8f, 0x74, 0xba,
-	0x8a, 0xf3, 0x5b, 0x5f, 0xaa, 0x0c, 0x24, 0xee, 0x27, 0xbd, 0x32, 0x89, 0x8a, 0x25, 0x35, 0x39,
-	0x2d, 0xac, 0xf7, 0x7d, 0x78, 0x9d, 0x26, 0x38, 0x41, 0xb3, 0xce, 0x15, 0xc3, 0x97, 0x6d, 0x9d,
-	0x2f, 0x74, 0x2e, 0x41, 0x03, 0x0c, 0x1f, 0x11, 0xcf, 0x60, 0xa9, 0x1d, 0x14, 0x73, 0x48, 0xb5,
-	0xc4, 0x00, 0x77, 0x44, 0x84, 0x1c, 0xce, 0x89, 0xd3, 0x64, 0xa6, 0x03, 0x9b, 0x03, 0xf2, 0x6e,
-	0x12, 0x42, 0x5c, 0x4e, 0x34, 0xd9, 0xd2, 0xce, 0x0a, 0x59, 0x0c, 0xf3, 0x4c, 0xd9, 0x91, 0xd1,
-	0x19, 0x34, 0x93, 0xcc, 0xd1, 0x89, 0x30, 0x6f, 0xf9, 0x7d, 0x47, 0x1a, 0x7f, 0x50, 0x4e, 0xc0,
-	0xe7, 0xe1, 0x1d, 0xfa, 0x13, 0x28, 0x1c, 0xd5, 0x1e, 0x0f, 0x95, 0x91, 0xd7, 0xe2, 0x93, 0xca,
-	0x79, 0x0c, 0x36, 0xab, 0x6a, 0x30, 0x93, 0x7c, 0x0f, 0x4b, 0xce, 0xbb, 0xf5, 0xbb, 0xe2, 0xa5,
-	0xef, 0x7f, 0x3b, 0xe2, 0xb3, 0xda, 0xd9, 0x31, 0x4c, 0x66, 0xd9, 0x27, 0xef, 0xe6, 0x2e, 0xf1,
-	0x78, 0xfb, 0x59, 0x83, 0x79, 0xdf, 0x91, 0x1f, 0xaf, 0xdb, 0x0c, 0xdf, 0x34, 0x8a, 0x89, 0xf7,
-	0x9b, 0xe1, 0x2e, 0xf9, 0x0d, 0x80, 0x11, 0xee, 0xa6, 0x51, 0x27, 0x4b, 0xa3, 0x5f, 0xc4, 0x8d,
-	0x27, 0x6a, 0x7a, 0xb9, 0x4a, 0x25, 0xf5, 0x54, 0xb1, 0x91, 0x7c, 0xe0, 0xaf, 0x18, 0xc2, 0x07,
-	0xbc, 0xbf, 0x23, 0x4d, 0x76, 0xf5, 0xb3, 0x4e, 0x1b, 0x38, 0x34, 0x7a, 0x59, 0x44, 0x6f, 0xa9,
-	0x75, 0x21, 0xc5, 0x5f, 0xfd, 0x1a, 0x30, 0xf2, 0x7c, 0x2d, 0x6e, 0x4e, 0x62, 0xb5, 0x86, 0x62,
-	0xbd, 0xc8, 0x07, 0x82, 0x4a, 0x67, 0x1f, 0x08, 0x30, 0x16, 0xa4, 0xbc, 0xd3, 0x69, 0x2e, 0xd2,
-	0x29, 0xef, 0x55, 0x36, 0xe5, 0x2b, 0x28, 0xbc, 0x91, 0x14, 0x7f, 0xfb, 0xcb, 0xd0, 0x01, 0xd7,
-	0xb6, 0x76, 0x0d, 0xea, 0x75, 0x62, 0xf1, 0x21, 0x92, 0xdf, 0x15, 0x36, 0x23, 0xd9, 0x6b, 0xbb,
-	0x4a, 0x04, 0x43, 0xd9, 0xdf, 0x42, 0x95, 0xc1, 0xaf, 0xc4, 0xad, 0xea, 0x7f, 0x74, 0xcf, 0xe9,
-	0xb3, 0x01, 0x9a, 0x37, 0x9c, 0x41, 0x67, 0xbe, 0xfe, 0xc8, 0x95, 0xe9, 0xb6, 0xf5, 0x91, 0x2b,
-	0x57, 0xb7, 0x3d, 0x72, 0x15, 0x10, 0x8e, 0x9c, 0x9d, 0x26, 0xed, 0x4b, 0x5f, 0xaa, 0x5c, 0x64,
-	0x04, 0x05, 0x4b, 0x9f, 0xfd, 0x85, 0x4b, 0xf7, 0x41, 0x5b, 0x4a, 0x12, 0x85, 0xbb, 0xd7, 0x89,
-	0xc5, 0x9f, 0x64, 0x5e, 0xad, 0x4a, 0x2b, 0x17, 0xa3, 0x51, 0x58, 0xef, 0x75, 0x83, 0xbd, 0xe3,
-	0x93, 0x47, 0xbf, 0x3f, 0x58, 0x47, 0x0e, 0xac, 0xed, 0x47, 0x7a, 0xb0, 0xf9, 0x35, 0x98, 0xeb,
-	0xc1, 0xda, 0x0d, 0xf2, 0x47, 0xda, 0x01, 0xf5, 0xa4, 0x7b, 0x7e, 0x23, 0xd7, 0x1e, 0xfd, 0x1f,
-	0x00, 0x00, 0xff, 0xff, 0x6e, 0x16, 0x3b, 0x7d, 0x0d, 0x16, 0x00, 0x00,
+	// 1137 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x98, 0xdb, 0x6f, 0x23, 0xb5,
+	0x17, 0xc7, 0x7f, 0x95, 0x7e, 0xac, 0x84, 0x61, 0xb9, 0x98, 0x15, 0x2b, 0x15, 0x89, 0xdb, 0xb6,
+	0x50, 0x9a, 0xa5, 0xd9, 0x0b, 0xcb, 0x7b, 0xb6, 0xbb, 0xed, 0x16, 0x6d, 0x45, 0x48, 0x5a, 0x8a,
+	0x40, 0x42, 0x72, 0x93, 0xd3, 0x64, 0xe8, 0xc4, 0x1e, 0x6c, 0x27, 0xa2, 0x4f, 0x48, 0xbc, 0x22,
+	0xf1, 0x77, 0xf2, 0x67, 0xa0, 0x99, 0x8c, 0x3d, 0xc7, 0x33, 0x67, 0x9c, 0xe9, 0x5b, 0x94, 0xef,
+	0xc7, 0xe7, 0x6b, 0x1f, 0x1f, 0x5f, 0xc6, 0x6c, 0xdb, 0x8a, 0xcb, 0x14, 0xec, 0x42, 0x48, 0x31,
+	0x03, 0x6d, 0x40, 0xaf, 0x92, 0x09, 0x1c, 0x64, 0x5a, 0x59, 0xc5, 0xef, 0x51, 0xda, 0xf6, 0xfd,
+	0xe0, 0xdf, 0xa9, 0xb0, 0x62, 0x8d, 0x3f, 0xf9, 0xb7, 0xc7, 0xee, 0x9e, 0x15, 0xda, 0xe9, 0x5a,
+	0xe3, 0x27, 0xec, 0xff, 0xc3, 0x44, 0xce, 0xf8, 0xc7, 0x07, 0xcd, 0x36, 0xb9, 0x30, 0x82, 0xdf,
+	0x97, 0x60, 0xec, 0xf6, 0x27, 0xad, 0xba, 0xc9, 0x94, 0x34, 0xf0, 0xf9, 0xff, 0xf8, 0x6b, 0xf6,
+	0xc6, 0x38, 0x05, 0xc8, 0x38, 0xc5, 0x16, 0x8a, 0x0b, 0xf6, 0x69, 0x3b, 0xe0, 0xa3, 0xfd, 0xca,
+	0xde, 0x7a, 0xf9, 0x07, 0x4c, 0x96, 0x16, 0x5e, 0x29, 0x75, 0xcd, 0x77, 0x89, 0x26, 0x48, 0x77,
+	0x91, 0xbf, 0xd8, 0x84, 0xf9, 0xf8, 0x3f, 0xb1, 0x37, 0x8f, 0xc1, 0x8e, 0x27, 0x73, 0x58, 0x08,
+	0xfe, 0x80, 0x68, 0xe6, 0x55, 0x17, 0x7b, 0x27, 0x0e, 0xf9, 0xc8, 0x33, 0xf6, 0xce, 0x31, 0xd8,
+	0x21, 0xe8, 0x45, 0x62, 0x4c, 0xa2, 0xa4, 0xe1, 0x7b, 0x74, 0x4b, 0x84, 0x38, 0x8f, 0xaf, 0x3a,
+	0x90, 0x38, 0x45, 0x63, 0xb0, 0x23, 0x10, 0xd3, 0xef, 0x65, 0x7a, 0x43, 0xa6, 0x08, 0xe9, 0xb1,
+	0x14, 0x05, 0x98, 0x8f, 0x2f, 0xd8, 0xdb, 0xa5, 0x70, 0xa1, 0x13, 0x0b, 0x3c, 0xd2, 0xb2, 0x00,
+	0x9c, 0xc3, 0x97, 0x1b, 0x39, 0x6f, 0xf1, 0x0b, 0x63, 0x87, 0x73, 0x21, 0x67, 0x70, 0x76, 0x93,
+	0x01, 0xa7, 0x32, 0x5c, 0xc9, 0x2e, 0xfc, 0xee, 0x06, 0x0a, 0xf7, 0x7f, 0x04, 0x57, 0x1a, 0xcc,
+	0x7c, 0x6c, 0x45, 0x4b, 0xff, 0x31, 0x10, 0xeb, 0x7f, 0xc8, 0xe1, 0xb9, 0x1e, 0x2d, 0xe5, 0x2b,
+	0x10, 0xa9, 0x9d, 0x1f, 0xce, 0x61, 0x72, 0x4d, 0xce, 0x75, 0x88, 0xc4, 0xe6, 0xba, 0x4e, 0x7a,
+	0xa3, 0x8c, 0xbd, 0x7f, 0x32, 0x93, 0x4a, 0xc3, 0x5a, 0x7e, 0xa9, 0xb5, 0xd2, 0xbc, 0x47, 0x44,
+	0x68, 0x50, 0xce, 0xee, 0x61, 0x37, 0x38, 0xcc, 0x5e, 0xaa, 0xc4, 0xb4, 0x5c, 0x23, 0x74, 0xf6,
+	0x2a, 0x20, 0x9e, 0x3d, 0xcc, 0x79, 0x8b, 0xdf, 0xd8, 0xbb, 0x43, 0x0d, 0x57, 0x69, 0x32, 0x9b,
+	0xbb, 0x95, 0x48, 0x25, 0xa5, 0xc6, 0x38, 0xa3, 0xfd, 0x2e, 0x28, 0x5e, 0x2c, 0x83, 0x2c, 0x4b,
+	0x6f, 0x4a, 0x1f, 0xaa, 0x88, 0x90, 0x1e, 0x5b, 0x2c, 0x01, 0x86, 0x2b, 0xf9, 0xb5, 0x9a, 0x5c,
+	0x17, 0xbb, 0xab, 0x21, 0x2b, 0xb9, 0x92, 0x63, 0x95, 0x8c, 0x29, 0x3c, 0x17, 0xe7, 0x32, 0xad,
+	0xc2, 0x53, 0xdd, 0xc2, 0x40, 0x6c, 0x2e, 0x42, 0x0e, 0x17, 0x58, 0xb9, 0x51, 0x1e, 0x81, 0x9d,
+	0xcc, 0x07, 0xe6, 0xc5, 0xa5, 0x20, 0x0b, 0xac, 0x41, 0xc5, 0x0a, 0x8c, 0x80, 0xbd, 0xe3, 0x9f,
+	0xec, 0xc3, 0x50, 0x1e, 0xa4, 0xe9, 0x50, 0x27, 0x2b, 0xc3, 0x1f, 0x6d, 0x8c, 0xe4, 0x50, 0xe7,
+	0xfd, 0xf8, 0x16, 0x2d, 0xda, 0x87, 0x3c, 0xc8, 0xb2, 0x0e, 0x43, 0x1e, 0x64, 0x59, 0xf7, 0x21,
+	0x17, 0x30, 0x76, 0x1c, 0x41, 0x96, 0x26, 0x13, 0x61, 0x13, 0x25, 0xc7, 0x56, 0xd8, 0xa5, 0x21,
+	0x1d, 0x1b, 0x54, 0xcc, 0x91, 0x80, 0x71, 0xe5, 0x9c, 0x0a, 0x63, 0x41, 0x97, 0x66, 0x54, 0xe5,
+	0x60, 0x20, 0x56, 0x39, 0x21, 0x87, 0xf7, 0xc0, 0xb5, 0x32, 0x54, 0x26, 0xc9, 0x3b, 0x41, 0xee,
+	0x81, 0x21, 0x12, 0xdb, 0x03, 0xeb, 0x24, 0xde, 0x2e, 0x2e, 0x44, 0x62, 0x8f, 0x54, 0xe5, 0x44,
+	0xb5, 0xaf, 0x31, 0xb1, 0xed, 0xa2, 0x81, 0x62, 0xaf, 0xb1, 0x55, 0x19, 0x4a, 0x2d, 0xe9, 0x55,
+	0x63, 0x62, 0x5e, 0x0d, 0x14, 0x2f, 0x84, 0x9a, 0x78, 0x9a, 0xc8, 0x64, 0xb1, 0x5c, 0x90, 0x0b,
+	0x81, 0x46, 0x63, 0x0b, 0xa1, 0xad, 0x85, 0xef, 0xc0, 0x82, 0xbd, 0x37, 0xb6, 0x42, 0x5b, 0x3c,
+	0x5a, 0x7a, 0x08, 0x21, 0xe4, 0x4c, 0x7b, 0x9d, 0x58, 0x6f, 0xf7, 0xf7, 0x16, 0xdb, 0xae, 0xcb,
+	0xe7, 0xd2, 0x26, 0xe9, 0xe0, 0xca, 0x82, 0xe6, 0xdf, 0x74, 0x88, 0x56, 0xe1, 0xae, 0x0f, 0xcf,
+	0x6e, 0xd9, 0x0a, 0x1f, 0x0c, 0xc7, 0xe0, 0x28, 0x43, 0x1e, 0x0c, 0x48, 0x8f, 0x1d, 0x0c, 0x01,
+	0x86, 0x93, 0xfb, 0x23, 0xea, 0x43, 0xbe, 0x3d, 0x90, 0xc9, 0xad, 0x43, 0xb1, 0xe4, 0x36, 0x59,
+	0x5c, 0x4c, 0x58, 0xad, 0x2a, 0x9c, 0x2c, 0x26, 0x1a, 0x8d, 0x15, 0x53, 0x5b, 0x0b, 0x3c, 0xde,
+	0x11, 0x18, 0xd8, 0x58, 0x4c, 0x75, 0x28, 0x36, 0xde, 0x26, 0x8b, 0xcf, 0xdd, 0x13, 0x99, 0xd8,
+	0xf5, 0xa6, 0x41, 0x9e, 0xbb, 0x95, 0x1c, 0x3b, 0x77, 0x31, 0xe5, 0x83, 0xff, 0xb5, 0xc5, 0xee,
+	0x0f, 0x55, 0xb6, 0x4c, 0x85, 0x85, 0x11, 0x64, 0x42, 0x83, 0xb4, 0xdf, 0xa9, 0xa5, 0x96, 0x22,
+	0xe5, 0x54, 0x72, 0x5a, 0x58, 0xe7, 0xfb, 0xe4, 0x36, 0x4d, 0x70, 0x81, 0xe6, 0x9d, 0x2b, 0x87,
+	0xcf, 0xdb, 0x3a, 0x5f, 0xea, 0xb1, 0x02, 0x0d, 0x30, 0x7c, 0x44, 0xbc, 0x80, 0x85, 0xb2, 0x50,
+	0xe6, 0x90, 0x6a, 0x89, 0x81, 0xd8, 0x11, 0x11, 0x72, 0xb8, 0x26, 0xce, 0xe5, 0x54, 0x05, 0x36,
+	0xfb, 0xe4, 0xdd, 0x64, 0xaa, 0x28, 0xab, 0x5e, 0x27, 0xd6, 0xdb, 0x4d, 0xd9, 0xdd, 0x43, 0x21,
+	0xcf, 0xb4, 0x90, 0xe5, 0x31, 0x41, 0x75, 0x35, 0x20, 0x9c, 0xd1, 0xde, 0x66, 0xd0, 0xbb, 0x18,
+	0xc6, 0xcb, 0x64, 0x5e, 0x08, 0x33, 0xd4, 0x2a, 0xef, 0xca, 0x94, 0x47, 0x0e, 0x68, 0x84, 0x39,
+	0xbf, 0xaf, 0x3b, 0xd2, 0xf8, 0xb3, 0x75, 0x0c, 0xae, 0xda, 0x1f, 0xd0, 0x1f, 0x5a, 0x61, 0xee,
+	0x76, 0xe2, 0x90, 0x8f, 0xbc, 0x62, 0x1f, 0x54, 0xce, 0x23, 0x30, 0x56, 0xe8, 0x7c, 0x3c, 0xf1,
+	0x1e, 0x7a, 0xce, 0xb9, 0x1d, 0x74, 0xc5, 0xbd, 0xef, 0x3f, 0x5b, 0xec, 0xa3, 0xda, 0x09, 0x35,
+	0x90, 0xd3, 0xfc, 0xc3, 0x7a, 0x7d, 0x63, 0x79, 0xb6, 0xf9, 0x44, 0xc3, 0xbc, 0xeb, 0xc8, 0xb7,
+	0xb7, 0x6d, 0x86, 0xef, 0x33, 0x65, 0xe2, 0xdd, 0x92, 0xdb, 0x23, 0xbf, 0x34, 0x30, 0x12, 0xbb,
+	0xcf, 0xd4, 0x49, 0x6f, 0xf4, 0x03, 0xbb, 0xf3, 0x5c, 0x4c, 0xae, 0x97, 0x19, 0xa7, 0x1e, 0x44,
+	0xd6, 0x92, 0x0b, 0xfc, 0x59, 0x84, 0x70, 0x01, 0x1f, 0x6d, 0x71, 0x9d, 0x5f, 0x30, 0x8d, 0x55,
+	0x1a, 0x8e, 0xb4, 0x5a, 0x94, 0xd1, 0x5b, 0x76, 0xd4, 0x90, 0x8a, 0x5f, 0x30, 0x1b, 0x30, 0xf2,
+	0xcc, 0x9f, 0x21, 0x52, 0xb1, 0x82, 0x72, 0xbe, 0xc8, 0x67, 0x88, 0x4a, 0x8f, 0x3e, 0x43, 0x60,
+	0x2c, 0x28, 0x79, 0xab, 0xb2, 0x42, 0xa4, 0x4b, 0xde, 0xa9, 0xd1, 0x92, 0xaf, 0xa0, 0xf0, 0xde,
+	0x53, 0xfe, 0xed, 0xae, 0x5c, 0xfb, 0xb1, 0xb6, 0xb5, 0xcb, 0x56, 0xaf, 0x13, 0x8b, 0x8f, 0xaa,
+	0xe2, 0x46, 0xb2, 0x1e, 0xc9, 0x4e, 0xdb, 0x85, 0x25, 0x18, 0xca, 0xee, 0x06, 0xca, 0x07, 0xbf,
+	0x61, 0xf7, 0xaa, 0xff, 0xd1, 0x6d, 0xea, 0x20, 0x1a, 0xa0, 0x79, 0x8f, 0xea, 0x77, 0xe6, 0xeb,
+	0x4f, 0x69, 0xb9, 0x6e, 0x5a, 0x9f, 0xd2, 0x0a, 0x75, 0xd3, 0x53, 0x5a, 0x09, 0xe1, 0xc8, 0xf9,
+	0x99, 0xd5, 0x3e, 0xf5, 0x5e, 0x8d, 0x45, 0x46, 0x50, 0x30, 0xf5, 0xf9, 0x5f, 0x78, 0xeb, 0xde,
+	0x6f, 0x2b, 0x49, 0x62, 0xe3, 0xee, 0x75, 0x62, 0xf1, 0x87, 0x9f, 0x53, 0xab, 0xad, 0x35, 0x16,
+	0xa3, 0xb1, 0xb1, 0x3e, 0xec, 0x06, 0x3b, 0xc7, 0xe7, 0x4f, 0x7f, 0x7e, 0xbc, 0x4a, 0x2c, 0x18,
+	0x73, 0x90, 0xa8, 0xfe, 0xfa, 0x57, 0x7f, 0xa6, 0xfa, 0x2b, 0xdb, 0x2f, 0x9e, 0x82, 0xfb, 0xd4,
+	0xc3, 0xf1, 0xe5, 0x9d, 0x42, 0x7b, 0xfa, 0xdf, 0x00, 0x53, 0xb0, 0x82, 0x81, 0x73, 0x16, 0x00,
+	0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -175,6 +177,9 @@ type TabletManagerClient interface {
 	DemoteMaster(ctx context.Context, in *tabletmanagerdata.DemoteMasterRequest, opts ...grpc.CallOption) (*tabletmanagerdata.DemoteMasterResponse, error)
 	// UndoDemoteMaster reverts all changes made by DemoteMaster
 	UndoDemoteMaster(ctx context.Context, in *tabletmanagerdata.UndoDemoteMasterRequest, opts ...grpc.CallOption) (*tabletmanagerdata.UndoDemoteMasterResponse, error)
+	// CanTransition checks if the tablet could change to the
+	// requested type right now, without changing anything.
+	CanTransition(ctx context.Context, in *tabletmanagerdata.CanTransitionRequest, opts ...grpc.CallOption) (*tabletmanagerdata.CanTransitionResponse, error)
 	// ReplicaWasPromoted tells the remote tablet it is now the master
 	ReplicaWasPromoted(ctx context.Context, in *tabletmanagerdata.ReplicaWasPromotedRequest, opts ...grpc.CallOption) (*tabletmanagerdata.ReplicaWasPromotedResponse, error)
 	// SetMaster tells the replica to reparent
@@ -541,6 +546,15 @@ func (c *tabletManagerClient) UndoDemoteMaster(ctx context.Context, in *tabletma
 	return out, nil
 }
 
+func (c *tabletManagerClient) CanTransition(ctx context.Context, in *tabletmanagerdata.CanTransitionRequest, opts ...grpc.CallOption) (*tabletmanagerdata.CanTransitionResponse, error) {
+	out := new(tabletmanagerdata.CanTransitionResponse)
+	err := c.cc.Invoke(ctx, "/tabletmanagerservice.TabletManager/CanTransition", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *tabletManagerClient) ReplicaWasPromoted(ctx context.Context, in *tabletmanagerdata.ReplicaWasPromotedRequest, opts ...grpc.CallOption) (*tabletmanagerdata.ReplicaWasPromotedResponse, error) {
 	out := new(tabletmanagerdata.ReplicaWasPromotedResponse)
 	err := c.cc.Invoke(ctx, "/tabletmanagerservice.TabletManager/ReplicaWasPromoted", in, out, opts...)
@@ -794,6 +808,9 @@ type TabletManagerServer interface {
 	DemoteMaster(context.Context, *tabletmanagerdata.DemoteMasterRequest) (*tabletmanagerdata.DemoteMasterResponse, error)
 	// UndoDemoteMaster reverts all changes made by DemoteMaster
 	UndoDemoteMaster(context.Context, *tabletmanagerdata.UndoDemoteMasterRequest) (*tabletmanagerdata.UndoDemoteMasterResponse, error)
+	// CanTransition checks if the tablet could change to the
+	// requested type right now, without changing anything.
+	CanTransition(context.Context, *tabletmanagerdata.CanTransitionRequest) (*tabletmanagerdata.CanTransitionResponse, error)
 	// ReplicaWasPromoted tells the remote tablet it is now the master
 	ReplicaWasPromoted(context.Context, *tabletmanagerdata.ReplicaWasPromotedRequest) (*tabletmanagerdata.ReplicaWasPromotedResponse, error)
 	// SetMaster tells the replica to reparent
@@ -940,6 +957,9 @@ func (*UnimplementedTabletManagerServer) DemoteMaster(ctx context.Context, req *
 func (*UnimplementedTabletManagerServer) UndoDemoteMaster(ctx context.Context, req *tabletmanagerdata.UndoDemoteMasterRequest) (*tabletmanagerdata.UndoDemoteMasterResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UndoDemoteMaster not implemented")
 }
+func (*UnimplementedTabletManagerServer) CanTransition(ctx context.Context, req *tabletmanagerdata.CanTransitionRequest) (*tabletmanagerdata.CanTransitionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CanTransition not implemented")
+}
 func (*UnimplementedTabletManagerServer) ReplicaWasPromoted(ctx context.Context, req *tabletmanagerdata.ReplicaWasPromotedRequest) (*tabletmanagerdata.ReplicaWasPromotedResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ReplicaWasPromoted not implemented")
 }
@@ -1641,6 +1661,24 @@ func _TabletManager_UndoDemoteMaster_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TabletManager_CanTransition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(tabletmanagerdata.CanTransitionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TabletManagerServer).CanTransition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tabletmanagerservice.TabletManager/CanTransition",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TabletManagerServer).CanTransition(ctx, req.(*tabletmanagerdata.CanTransitionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _TabletManager_ReplicaWasPromoted_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(tabletmanagerdata.ReplicaWasPromotedRequest)
 	if err := dec(in); err != nil {
@@ -2083,6 +2121,10 @@ var _TabletManager_serviceDesc = grpc.ServiceDesc{
 			MethodName: "UndoDemoteMaster",
 			Handler:    _TabletManager_UndoDemoteMaster_Handler,
 		},
+		{
+			MethodName: "CanTransition",
+			Handler:    _TabletManager_CanTransition_Handler,
+		},
 		{
 			MethodName: "ReplicaWasPromoted",
 			Handler:    _TabletManager_ReplicaWasPromoted_Handler,
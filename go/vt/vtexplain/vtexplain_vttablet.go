@@ -289,6 +289,14 @@ func initTabletEnvironment(ddls []*sqlparser.DDL, opts *Options) error {
 				{sqltypes.NewVarBinary("STRICT_TRANS_TABLES")},
 			},
 		},
+		"select @@global.read_only": {
+			Fields: []*querypb.Field{{
+				Type: sqltypes.Int64,
+			}},
+			Rows: [][]sqltypes.Value{
+				{sqltypes.NewInt64(0)},
+			},
+		},
 		"select @@autocommit": {
 			Fields: []*querypb.Field{{
 				Type: sqltypes.Uint64,
@@ -264,7 +264,12 @@ func causedByFailover(err error) bool {
 	log.V(2).Infof("Checking error (type: %T) if it is caused by a failover. err: %v", err, err)
 
 	// TODO(sougou): Remove the INTERNAL check after rollout.
-	if code := vterrors.Code(err); code != vtrpcpb.Code_FAILED_PRECONDITION && code != vtrpcpb.Code_INTERNAL {
+	// vttablet returns UNAVAILABLE while it transitions to serving,
+	// e.g. while a new master is being promoted, if it's started with
+	// -retryable_request_errors.
+	switch vterrors.Code(err) {
+	case vtrpcpb.Code_FAILED_PRECONDITION, vtrpcpb.Code_UNAVAILABLE, vtrpcpb.Code_INTERNAL:
+	default:
 		return false
 	}
 	switch {
@@ -357,6 +357,36 @@ func TestPassthrough(t *testing.T) {
 	}
 }
 
+// TestCausedByFailover tests which errors returned by vttablet
+// trigger buffering.
+func TestCausedByFailover(t *testing.T) {
+	testcases := []struct {
+		err  error
+		want bool
+	}{{
+		err:  failoverErr,
+		want: true,
+	}, {
+		err:  nonFailoverErr,
+		want: false,
+	}, {
+		// vttablet returns UNAVAILABLE while it's transitioning to serving.
+		err:  vterrors.New(vtrpcpb.Code_UNAVAILABLE, "operation not allowed in state NOT_SERVING (tablet type: MASTER, state: Not Serving, want: Serving)"),
+		want: true,
+	}, {
+		err:  vterrors.New(vtrpcpb.Code_UNAVAILABLE, "operation not allowed: replication is unhealthy: lag 1h0m0s exceeds the unhealthy threshold (tablet type: REPLICA, state: Serving, want: Serving)"),
+		want: false,
+	}, {
+		err:  vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, "operation not allowed in state NOT_SERVING"),
+		want: false,
+	}}
+	for _, tcase := range testcases {
+		if got := causedByFailover(tcase.err); got != tcase.want {
+			t.Errorf("causedByFailover(%v): %v, want %v", tcase.err, got, tcase.want)
+		}
+	}
+}
+
 // TestLastReparentTooRecent_BufferingSkipped tests that buffering is skipped if
 // we see the reparent (end) *before* any request failures due to it.
 // We must not start buffering because we already observed the trigger for
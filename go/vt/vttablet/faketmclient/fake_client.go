@@ -275,6 +275,11 @@ func (client *FakeTabletManagerClient) UndoDemoteMaster(ctx context.Context, tab
 	return nil
 }
 
+// CanTransition is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) CanTransition(ctx context.Context, tablet *topodatapb.Tablet, tabletType topodatapb.TabletType) error {
+	return nil
+}
+
 // SetMaster is part of the tmclient.TabletManagerClient interface.
 func (client *FakeTabletManagerClient) SetMaster(ctx context.Context, tablet *topodatapb.Tablet, parent *topodatapb.TabletAlias, timeCreatedNS int64, waitPosition string, forceStartReplication bool) error {
 	return nil
@@ -694,6 +694,19 @@ func (client *Client) UndoDemoteMaster(ctx context.Context, tablet *topodatapb.T
 	return err
 }
 
+// CanTransition is part of the tmclient.TabletManagerClient interface.
+func (client *Client) CanTransition(ctx context.Context, tablet *topodatapb.Tablet, tabletType topodatapb.TabletType) error {
+	cc, c, err := client.dial(tablet)
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+	_, err = c.CanTransition(ctx, &tabletmanagerdatapb.CanTransitionRequest{
+		TabletType: tabletType,
+	})
+	return err
+}
+
 // ReplicaWasPromoted is part of the tmclient.TabletManagerClient interface.
 func (client *Client) ReplicaWasPromoted(ctx context.Context, tablet *topodatapb.Tablet) error {
 	cc, c, err := client.dial(tablet)
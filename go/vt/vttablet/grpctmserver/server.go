@@ -388,6 +388,13 @@ func (s *server) UndoDemoteMaster(ctx context.Context, request *tabletmanagerdat
 	return response, err
 }
 
+func (s *server) CanTransition(ctx context.Context, request *tabletmanagerdatapb.CanTransitionRequest) (response *tabletmanagerdatapb.CanTransitionResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "CanTransition", request, response, false /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	response = &tabletmanagerdatapb.CanTransitionResponse{}
+	return response, s.tm.CanTransition(ctx, request.TabletType)
+}
+
 func (s *server) ReplicaWasPromoted(ctx context.Context, request *tabletmanagerdatapb.ReplicaWasPromotedRequest) (response *tabletmanagerdatapb.ReplicaWasPromotedResponse, err error) {
 	defer s.tm.HandleRPCPanic(ctx, "ReplicaWasPromoted", request, response, true /*verbose*/, &err)
 	ctx = callinfo.GRPCCallInfo(ctx)
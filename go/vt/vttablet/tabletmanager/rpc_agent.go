@@ -135,6 +135,8 @@ type RPCTM interface {
 
 	UndoDemoteMaster(ctx context.Context) error
 
+	CanTransition(ctx context.Context, tabletType topodatapb.TabletType) error
+
 	// Deprecated
 	SlaveWasPromoted(ctx context.Context) error
 
@@ -63,7 +63,7 @@ func (tm *TabletManager) LockTables(ctx context.Context) error {
 	if err != nil {
 		// as fall back, we can lock each individual table as well.
 		// this requires slightly less privileges but achieves the same effect
-		err = tm.lockTablesUsingLockTables(conn)
+		err = tm.lockTablesUsingLockTables(ctx, conn)
 		if err != nil {
 			return err
 		}
@@ -90,14 +90,14 @@ func (tm *TabletManager) LockTables(ctx context.Context) error {
 	return nil
 }
 
-func (tm *TabletManager) lockTablesUsingLockTables(conn *dbconnpool.DBConnection) error {
+func (tm *TabletManager) lockTablesUsingLockTables(ctx context.Context, conn *dbconnpool.DBConnection) error {
 	log.Warningf("failed to lock tables with FTWRL - falling back to LOCK TABLES")
 
 	// Ensure schema engine is Open. If vttablet came up in a non_serving role,
 	// the schema engine may not have been initialized. Open() is idempotent, so this
 	// is always safe
 	se := tm.QueryServiceControl.SchemaEngine()
-	if err := se.Open(); err != nil {
+	if err := se.Open(ctx); err != nil {
 		return err
 	}
 
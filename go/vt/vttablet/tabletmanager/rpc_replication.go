@@ -30,6 +30,7 @@ import (
 	"vitess.io/vitess/go/vt/mysqlctl"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver"
 
 	replicationdatapb "vitess.io/vitess/go/vt/proto/replicationdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
@@ -380,12 +381,12 @@ func (tm *TabletManager) demoteMaster(ctx context.Context, revertPartialFailure
 		// considered successful. If we are already not serving, this will be
 		// idempotent.
 		log.Infof("DemoteMaster disabling query service")
-		if err := tm.QueryServiceControl.SetServingType(tablet.Type, logutil.ProtoToTime(tablet.MasterTermStartTime), false, "demotion in progress"); err != nil {
+		if err := tm.QueryServiceControl.SetServingType(ctx, tablet.Type, logutil.ProtoToTime(tablet.MasterTermStartTime), false, "demotion in progress"); err != nil {
 			return nil, vterrors.Wrap(err, "SetServingType(serving=false) failed")
 		}
 		defer func() {
 			if finalErr != nil && revertPartialFailure && wasServing {
-				if err := tm.QueryServiceControl.SetServingType(tablet.Type, logutil.ProtoToTime(tablet.MasterTermStartTime), true, ""); err != nil {
+				if err := tm.QueryServiceControl.SetServingType(ctx, tablet.Type, logutil.ProtoToTime(tablet.MasterTermStartTime), true, ""); err != nil {
 					log.Warningf("SetServingType(serving=true) failed during revert: %v", err)
 				}
 			}
@@ -459,13 +460,21 @@ func (tm *TabletManager) UndoDemoteMaster(ctx context.Context) error {
 	// Update serving graph
 	tablet := tm.Tablet()
 	log.Infof("UndoDemoteMaster re-enabling query service")
-	if err := tm.QueryServiceControl.SetServingType(tablet.Type, logutil.ProtoToTime(tablet.MasterTermStartTime), true, ""); err != nil {
+	if err := tm.QueryServiceControl.SetServingType(ctx, tablet.Type, logutil.ProtoToTime(tablet.MasterTermStartTime), true, ""); err != nil {
 		return vterrors.Wrap(err, "SetServingType(serving=true) failed")
 	}
 
 	return nil
 }
 
+// CanTransition checks if the query service could switch to tabletType
+// right now, without changing anything. It's used by the reparent
+// tooling before it starts a reparent.
+func (tm *TabletManager) CanTransition(ctx context.Context, tabletType topodatapb.TabletType) error {
+	serving := tm.tmState.CanServe(tabletType) == ""
+	return tm.QueryServiceControl.CanTransition(tabletType, serving)
+}
+
 // ReplicaWasPromoted promotes a replica to master, no questions asked.
 func (tm *TabletManager) ReplicaWasPromoted(ctx context.Context) error {
 	return tm.ChangeType(ctx, topodatapb.TabletType_MASTER)
@@ -733,13 +742,33 @@ func (tm *TabletManager) PromoteReplica(ctx context.Context) (string, error) {
 		return "", err
 	}
 
-	if err := tm.changeTypeLocked(ctx, topodatapb.TabletType_MASTER, DBActionSetReadWrite); err != nil {
+	// The reparent doesn't wait for the query service: the new master
+	// takes writes once it's serving, and the result is logged.
+	h, err := tm.tmState.ChangeTabletTypeAsync(ctx, topodatapb.TabletType_MASTER, DBActionSetReadWrite)
+	if err != nil {
 		return "", err
 	}
+	if h != nil {
+		go tm.logServingTransition(h)
+	}
+	if err := tm.fixSemiSyncAndReplication(topodatapb.TabletType_MASTER); err != nil {
+		return "", vterrors.Wrap(err, "fixSemiSyncAndReplication failed, may not ack correctly")
+	}
 
 	return mysql.EncodePosition(pos), nil
 }
 
+// logServingTransition logs the result of an asynchronous transition
+// of the query service once it completes.
+func (tm *TabletManager) logServingTransition(h *tabletserver.TransitionHandle) {
+	<-h.Done()
+	if err := h.Err(); err != nil {
+		log.Errorf("Cannot start query service (transition %d): %v", h.Token(), err)
+		return
+	}
+	log.Infof("Query service started (transition %d)", h.Token())
+}
+
 func isMasterEligible(tabletType topodatapb.TabletType) bool {
 	switch tabletType {
 	case topodatapb.TabletType_MASTER, topodatapb.TabletType_REPLICA:
@@ -55,7 +55,12 @@ func (tm *TabletManager) ReloadSchema(ctx context.Context, waitPosition string)
 	}
 
 	log.Infof("ReloadSchema requested via RPC")
-	return tm.QueryServiceControl.ReloadSchema(ctx)
+	changed, err := tm.QueryServiceControl.ReloadSchema(ctx)
+	if err != nil {
+		return err
+	}
+	log.Infof("ReloadSchema: changed tables: %v", changed)
+	return nil
 }
 
 // PreflightSchema will try out the schema changes in "changes".
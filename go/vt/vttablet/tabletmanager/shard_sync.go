@@ -35,6 +35,10 @@ var (
 	shardSyncRetryDelay = flag.Duration("shard_sync_retry_delay", 30*time.Second, "delay between retries of updates to keep the tablet and its shard record in sync")
 )
 
+// topoChecksPerTimeout is how many times a master confirms that the
+// topo is reachable within the query service's topo isolation timeout.
+const topoChecksPerTimeout = 4
+
 // shardSyncLoop is a loop that tries to keep the tablet state and the
 // shard record in sync.
 //
@@ -56,6 +60,11 @@ func (tm *TabletManager) shardSyncLoop(ctx context.Context, notifyChan <-chan st
 	// to always select on it -- a nil channel is never ready.
 	var retryChan <-chan time.Time
 
+	// topoCheckChan is how we wake up to confirm that the topo is still
+	// reachable while we are master. It's nil unless the query service
+	// stops serving when it can't reach the topo.
+	var topoCheckChan <-chan time.Time
+
 	// shardWatch is how we get notified when the shard record is updated.
 	// We only watch the shard record while we are master.
 	shardWatch := &shardWatcher{}
@@ -71,6 +80,8 @@ func (tm *TabletManager) shardSyncLoop(ctx context.Context, notifyChan <-chan st
 		case <-retryChan:
 			// It's time to retry a previous failed sync attempt.
 			log.Info("Retry sync")
+		case <-topoCheckChan:
+			// It's time to confirm that the topo is reachable.
 		case event := <-shardWatch.watchChan:
 			// Something may have changed in the shard record.
 			// We don't use the watch event except to know that we should
@@ -89,6 +100,7 @@ func (tm *TabletManager) shardSyncLoop(ctx context.Context, notifyChan <-chan st
 		// Disconnect any pending retry timer since we're already retrying for
 		// another reason.
 		retryChan = nil
+		topoCheckChan = nil
 
 		// Get the latest internal tablet value, representing what we think we are.
 		tablet := tm.Tablet()
@@ -112,6 +124,11 @@ func (tm *TabletManager) shardSyncLoop(ctx context.Context, notifyChan <-chan st
 				retryChan = time.After(*shardSyncRetryDelay)
 				continue
 			}
+			// We just read the shard record, so the topo is reachable.
+			tm.QueryServiceControl.SetTopoLastSeenHealthy(time.Now())
+			if timeout := tm.QueryServiceControl.TopoIsolationTimeout(); timeout != 0 {
+				topoCheckChan = time.After(timeout / topoChecksPerTimeout)
+			}
 			if !topoproto.TabletAliasEqual(masterAlias, tablet.Alias) {
 				// Another master has taken over while we still think we're master.
 				if err := tm.abortMasterTerm(ctx, masterAlias); err != nil {
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"vitess.io/vitess/go/sync2"
+	"vitess.io/vitess/go/vt/dbconfigs"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl/fakemysqldaemon"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vttablet/tabletservermock"
+)
+
+func TestShardSyncTopoLastSeen(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+	tablet := newTestTablet(t, 1, "ks", "0")
+	_, err := ts.GetOrCreateShard(ctx, "ks", "0")
+	require.NoError(t, err)
+	_, err = ts.UpdateShardFields(ctx, "ks", "0", func(si *topo.ShardInfo) error {
+		si.MasterAlias = tablet.Alias
+		si.MasterTermStartTime = logutil.TimeToProto(time.Now())
+		return nil
+	})
+	require.NoError(t, err)
+
+	qsc := tabletservermock.NewController()
+	qsc.TopoIsolationTimeoutValue = 40 * time.Millisecond
+	tm := &TabletManager{
+		BatchCtx:            ctx,
+		TopoServer:          ts,
+		MysqlDaemon:         &fakemysqldaemon.FakeMysqlDaemon{MysqlPort: sync2.NewAtomicInt32(1)},
+		DBConfigs:           &dbconfigs.DBConfigs{},
+		QueryServiceControl: qsc,
+	}
+	require.NoError(t, tm.Start(tablet, 0))
+	defer tm.Stop()
+	require.Equal(t, topodatapb.TabletType_MASTER, tm.Tablet().Type)
+
+	// The master keeps confirming that the topo is reachable.
+	waitForNewer := func(than time.Time) time.Time {
+		t.Helper()
+		for i := 0; i < 100; i++ {
+			if lastSeen := qsc.TopoLastSeenHealthy(); lastSeen.After(than) {
+				return lastSeen
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatal("topo last seen was not updated")
+		return time.Time{}
+	}
+	lastSeen := waitForNewer(time.Time{})
+	assert.True(t, waitForNewer(lastSeen).After(lastSeen))
+}
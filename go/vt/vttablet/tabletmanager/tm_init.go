@@ -268,6 +268,13 @@ func (tm *TabletManager) Start(tablet *topodatapb.Tablet, healthCheckInterval ti
 	if err != nil {
 		return vterrors.Wrap(err, "failed to InitDBConfig")
 	}
+	// Resume serving in the state the previous process left off,
+	// until the tablet manager state is applied.
+	if tabletType, terTime, serving, reason, ok := tm.QueryServiceControl.RestoredServingType(); ok {
+		if err := tm.QueryServiceControl.SetServingType(ctx, tabletType, terTime, serving, reason); err != nil {
+			log.Warningf("Could not restore the serving type %v: %v", tabletType, err)
+		}
+	}
 	tm.QueryServiceControl.RegisterQueryRuleSource(blacklistQueryRules)
 
 	if tm.UpdateStream != nil {
@@ -34,6 +34,7 @@ import (
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/topotools"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/rules"
 )
 
@@ -88,7 +89,7 @@ func (ts *tmState) Open() {
 	}
 
 	ts.isOpen = true
-	ts.updateLocked(ts.ctx)
+	ts.updateLocked(ts.ctx, false)
 	ts.publishStateLocked(ts.ctx)
 }
 
@@ -147,10 +148,23 @@ func (ts *tmState) RefreshFromTopoInfo(ctx context.Context, shardInfo *topo.Shar
 		}
 	}
 
-	ts.updateLocked(ctx)
+	ts.updateLocked(ctx, false)
 }
 
 func (ts *tmState) ChangeTabletType(ctx context.Context, tabletType topodatapb.TabletType, action DBAction) error {
+	_, err := ts.changeTabletType(ctx, tabletType, action, false)
+	return err
+}
+
+// ChangeTabletTypeAsync is like ChangeTabletType, but it doesn't wait
+// for the query service to start serving as tabletType: the returned
+// handle resolves once it does. The handle is nil if the query service
+// is not started.
+func (ts *tmState) ChangeTabletTypeAsync(ctx context.Context, tabletType topodatapb.TabletType, action DBAction) (*tabletserver.TransitionHandle, error) {
+	return ts.changeTabletType(ctx, tabletType, action, true)
+}
+
+func (ts *tmState) changeTabletType(ctx context.Context, tabletType topodatapb.TabletType, action DBAction, async bool) (*tabletserver.TransitionHandle, error) {
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
 	log.Infof("Changing Tablet Type: %v", tabletType)
@@ -161,13 +175,13 @@ func (ts *tmState) ChangeTabletType(ctx context.Context, tabletType topodatapb.T
 		// Update the tablet record first.
 		_, err := topotools.ChangeType(ctx, ts.tm.TopoServer, ts.tm.tabletAlias, tabletType, masterTermStartTime)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if action == DBActionSetReadWrite {
 			// We call SetReadOnly only after the topo has been updated to avoid
 			// situations where two tablets are master at the DB level but not at the vitess level
 			if err := ts.tm.MysqlDaemon.SetReadOnly(false); err != nil {
-				return err
+				return nil, err
 			}
 		}
 
@@ -182,10 +196,10 @@ func (ts *tmState) ChangeTabletType(ctx context.Context, tabletType topodatapb.T
 	statsTabletType.Set(s)
 	statsTabletTypeCount.Add(s, 1)
 
-	ts.updateLocked(ctx)
+	h := ts.updateLocked(ctx, async)
 	ts.publishStateLocked(ctx)
 	ts.tm.notifyShardSync()
-	return nil
+	return h, nil
 }
 
 func (ts *tmState) SetMysqlPort(mport int32) {
@@ -204,13 +218,16 @@ func (ts *tmState) UpdateTablet(update func(tablet *topodatapb.Tablet)) {
 	ts.publishForDisplay()
 }
 
-func (ts *tmState) updateLocked(ctx context.Context) {
+// updateLocked applies the tablet state to the services. If async is
+// set, it doesn't wait for the query service to start serving, and it
+// returns the handle of that transition, if it started one.
+func (ts *tmState) updateLocked(ctx context.Context, async bool) *tabletserver.TransitionHandle {
 	span, ctx := trace.NewSpan(ctx, "tmState.update")
 	defer span.Finish()
 	ts.publishForDisplay()
 
 	if !ts.isOpen {
-		return
+		return nil
 	}
 
 	terTime := logutil.ProtoToTime(ts.tablet.MasterTermStartTime)
@@ -220,7 +237,7 @@ func (ts *tmState) updateLocked(ctx context.Context) {
 	reason := ts.canServe(ts.tablet.Type)
 	if reason != "" {
 		log.Infof("Disabling query service: %v", reason)
-		if err := ts.tm.QueryServiceControl.SetServingType(ts.tablet.Type, terTime, false, reason); err != nil {
+		if err := ts.tm.QueryServiceControl.SetServingType(ctx, ts.tablet.Type, terTime, false, reason); err != nil {
 			log.Errorf("SetServingType(serving=false) failed: %v", err)
 		}
 	}
@@ -248,11 +265,24 @@ func (ts *tmState) updateLocked(ctx context.Context) {
 	}
 
 	// Open TabletServer last so that it advertises serving after all other services are up.
-	if reason == "" {
-		if err := ts.tm.QueryServiceControl.SetServingType(ts.tablet.Type, terTime, true, ""); err != nil {
-			log.Errorf("Cannot start query service: %v", err)
-		}
+	if reason != "" {
+		return nil
+	}
+	if async {
+		return ts.tm.QueryServiceControl.SetServingTypeAsync(ctx, ts.tablet.Type, terTime, true, "", false)
 	}
+	if err := ts.tm.QueryServiceControl.SetServingType(ctx, ts.tablet.Type, terTime, true, ""); err != nil {
+		log.Errorf("Cannot start query service: %v", err)
+	}
+	return nil
+}
+
+// CanServe returns the reason why the query service would not serve
+// as tabletType, or an empty string if it would.
+func (ts *tmState) CanServe(tabletType topodatapb.TabletType) string {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.canServe(tabletType)
 }
 
 func (ts *tmState) canServe(tabletType topodatapb.TabletType) string {
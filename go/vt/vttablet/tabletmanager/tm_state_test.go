@@ -157,7 +157,7 @@ func TestStateNonServing(t *testing.T) {
 
 	tm.tmState.mu.Lock()
 	tm.tmState.tablet.Type = topodatapb.TabletType_SPARE
-	tm.tmState.updateLocked(ctx)
+	tm.tmState.updateLocked(ctx, false)
 	tm.tmState.mu.Unlock()
 
 	qsc := tm.QueryServiceControl.(*tabletservermock.Controller)
@@ -191,6 +191,31 @@ func TestStateChangeTabletType(t *testing.T) {
 	assert.Nil(t, ti.MasterTermStartTime)
 }
 
+func TestStateChangeTabletTypeAsync(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+	tm := newTestTM(t, ts, 2, "ks", "0")
+	defer tm.Stop()
+
+	h, err := tm.tmState.ChangeTabletTypeAsync(ctx, topodatapb.TabletType_MASTER, DBActionSetReadWrite)
+	require.NoError(t, err)
+	require.NotNil(t, h)
+	<-h.Done()
+	require.NoError(t, h.Err())
+	qsc := tm.QueryServiceControl.(*tabletservermock.Controller)
+	status, ok := qsc.TransitionStatus(h.Token())
+	require.True(t, ok)
+	assert.True(t, status.Done)
+	assert.Equal(t, topodatapb.TabletType_MASTER, qsc.CurrentTarget().TabletType)
+	assert.True(t, qsc.IsServing())
+
+	// No transition is started if the query service doesn't serve.
+	h, err = tm.tmState.ChangeTabletTypeAsync(ctx, topodatapb.TabletType_SPARE, DBActionNone)
+	require.NoError(t, err)
+	assert.Nil(t, h)
+	assert.False(t, qsc.IsServing())
+}
+
 func TestPublishStateNew(t *testing.T) {
 	defer func(saved time.Duration) { *publishRetryInterval = saved }(*publishRetryInterval)
 	*publishRetryInterval = 1 * time.Millisecond
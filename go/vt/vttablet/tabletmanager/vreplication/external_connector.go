@@ -93,7 +93,7 @@ func (ec *externalConnector) Get(name string) (*mysqlConnector, error) {
 	c.se.InitDBConfig(c.env.Config().DB.AllPrivsWithDB())
 
 	// Open
-	if err := c.se.Open(); err != nil {
+	if err := c.se.Open(tabletenv.LocalContext()); err != nil {
 		return nil, vterrors.Wrapf(err, "external mysqlConnector: %v", name)
 	}
 	c.vstreamer.Open()
@@ -23,6 +23,7 @@ import (
 	"golang.org/x/net/context"
 
 	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vttablet/tabletmanager/vreplication"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
@@ -34,6 +35,26 @@ type VStreamer interface {
 	Stream(ctx context.Context, startPos string, tablePKs []*binlogdatapb.TableLastPK, filter *binlogdatapb.Filter, send func([]*binlogdatapb.VEvent) error) error
 }
 
+// blpFunc is a legaacy feature.
+// TODO(sougou): remove after legacy resharding worflows are removed.
+var blpFunc = vreplication.StatusSummary
+
+// WatcherStatus is the status of the replication streams of
+// the tablet: the one of the BinlogWatcher, and the filtered
+// replication.
+type WatcherStatus struct {
+	// State is running, stopped while the stream restarts,
+	// or n/a if the BinlogWatcher is closed or disabled.
+	State string `json:"state"`
+	// SecondsBehind is how far behind mysql the last event
+	// read by the BinlogWatcher is. It's 0 unless it's running.
+	SecondsBehind int64 `json:"secondsBehind"`
+	// FilteredReplicationSecondsBehind and BinlogPlayersCount
+	// are reported whether the BinlogWatcher is open or not.
+	FilteredReplicationSecondsBehind int64 `json:"filteredReplicationSecondsBehind"`
+	BinlogPlayersCount               int32 `json:"binlogPlayersCount"`
+}
+
 // BinlogWatcher is a tabletserver service that watches the
 // replication stream.  It will trigger schema reloads if a DDL
 // is encountered.
@@ -44,6 +65,12 @@ type BinlogWatcher struct {
 
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
+
+	// mu protects the fields reported by Status.
+	mu            sync.Mutex
+	open          bool
+	running       bool
+	secondsBehind int64
 }
 
 // NewBinlogWatcher creates a new BinlogWatcher.
@@ -64,6 +91,9 @@ func (blw *BinlogWatcher) Open() {
 
 	ctx, cancel := context.WithCancel(tabletenv.LocalContext())
 	blw.cancel = cancel
+	blw.mu.Lock()
+	blw.open = true
+	blw.mu.Unlock()
 	blw.wg.Add(1)
 	go blw.process(ctx)
 }
@@ -76,9 +106,58 @@ func (blw *BinlogWatcher) Close() {
 	blw.cancel()
 	blw.cancel = nil
 	blw.wg.Wait()
+	blw.mu.Lock()
+	blw.open = false
+	blw.mu.Unlock()
 	log.Info("Binlog Watcher: closed")
 }
 
+// Status returns the status of the BinlogWatcher. The state
+// of a closed BinlogWatcher is n/a: what it last reported
+// would be stale.
+func (blw *BinlogWatcher) Status() WatcherStatus {
+	status := WatcherStatus{State: "n/a"}
+	status.FilteredReplicationSecondsBehind, status.BinlogPlayersCount = blpFunc()
+	blw.mu.Lock()
+	defer blw.mu.Unlock()
+	switch {
+	case !blw.open:
+	case blw.running:
+		status.State = "running"
+		status.SecondsBehind = blw.secondsBehind
+	default:
+		status.State = "stopped"
+	}
+	return status
+}
+
+// setRunning records if the stream is running, and
+// resets how far behind it is.
+func (blw *BinlogWatcher) setRunning(running bool) {
+	blw.mu.Lock()
+	defer blw.mu.Unlock()
+	blw.running = running
+	blw.secondsBehind = 0
+}
+
+// recordEvents updates how far behind the stream is from
+// the last event that has a timestamp.
+func (blw *BinlogWatcher) recordEvents(events []*binlogdatapb.VEvent) {
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Timestamp == 0 {
+			continue
+		}
+		secondsBehind := events[i].CurrentTime/1e9 - events[i].Timestamp
+		if secondsBehind < 0 {
+			secondsBehind = 0
+		}
+		blw.mu.Lock()
+		blw.secondsBehind = secondsBehind
+		blw.mu.Unlock()
+		return
+	}
+}
+
 func (blw *BinlogWatcher) process(ctx context.Context) {
 	defer blw.env.LogError()
 	defer blw.wg.Done()
@@ -91,9 +170,12 @@ func (blw *BinlogWatcher) process(ctx context.Context) {
 
 	for {
 		// VStreamer will reload the schema when it encounters a DDL.
+		blw.setRunning(true)
 		err := blw.vs.Stream(ctx, "current", nil, filter, func(events []*binlogdatapb.VEvent) error {
+			blw.recordEvents(events)
 			return nil
 		})
+		blw.setRunning(false)
 		log.Infof("ReplicatinWatcher VStream ended: %v, retrying in 5 seconds", err)
 		select {
 		case <-ctx.Done():
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+// fakeWatcherVStreamer sends the events it's given,
+// and then blocks until the stream is canceled.
+type fakeWatcherVStreamer struct {
+	events []*binlogdatapb.VEvent
+	sent   chan struct{}
+}
+
+func (vs *fakeWatcherVStreamer) Stream(ctx context.Context, startPos string, tablePKs []*binlogdatapb.TableLastPK, filter *binlogdatapb.Filter, send func([]*binlogdatapb.VEvent) error) error {
+	if err := send(vs.events); err != nil {
+		return err
+	}
+	close(vs.sent)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestBinlogWatcherStatus(t *testing.T) {
+	blpFunc = testBlpFunc
+	config := tabletenv.NewDefaultConfig()
+	config.WatchReplication = true
+	now := time.Now()
+	vs := &fakeWatcherVStreamer{
+		events: []*binlogdatapb.VEvent{{
+			Type:        binlogdatapb.VEventType_GTID,
+			Timestamp:   now.Unix() - 5,
+			CurrentTime: now.UnixNano(),
+		}, {
+			Type:        binlogdatapb.VEventType_COMMIT,
+			Timestamp:   now.Unix() - 2,
+			CurrentTime: now.UnixNano(),
+		}, {
+			Type: binlogdatapb.VEventType_OTHER,
+		}},
+		sent: make(chan struct{}),
+	}
+	blw := NewBinlogWatcher(tabletenv.NewEnv(config, "BinlogWatcherTest"), vs, config)
+	assert.Equal(t, WatcherStatus{State: "n/a", FilteredReplicationSecondsBehind: 1, BinlogPlayersCount: 2}, blw.Status())
+
+	blw.Open()
+	<-vs.sent
+	assert.Equal(t, WatcherStatus{State: "running", SecondsBehind: 2, FilteredReplicationSecondsBehind: 1, BinlogPlayersCount: 2}, blw.Status())
+
+	// What the watcher last saw isn't reported once it's closed.
+	blw.Close()
+	assert.Equal(t, WatcherStatus{State: "n/a", FilteredReplicationSecondsBehind: 1, BinlogPlayersCount: 2}, blw.Status())
+}
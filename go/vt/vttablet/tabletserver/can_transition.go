@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// CanTransition checks if sm could transition to tabletType and state
+// right now, without changing anything. It fails if a transition is
+// in progress, or if mysql can't be used the way the transition needs
+// it. The serving components are not touched, and nothing is broadcast.
+func (sm *stateManager) CanTransition(tabletType topodatapb.TabletType, state servingState) error {
+	if !sm.transitioning.TryAcquire() {
+		return vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "cannot transition to %v %v: a state transition is in progress", tabletType, state)
+	}
+	defer sm.transitioning.Release()
+	return sm.canTransition(tabletType, state)
+}
+
+// canTransition is CanTransition for callers that hold transitioning.
+func (sm *stateManager) canTransition(tabletType topodatapb.TabletType, state servingState) error {
+	if state == StateNotConnected || tabletType == topodatapb.TabletType_RESTORE || (tabletType == topodatapb.TabletType_BACKUP && !sm.keepVStreamerOnBackup) {
+		// Nothing needs to be reached.
+		return nil
+	}
+
+	sm.mu.Lock()
+	isMaster := sm.target.TabletType == topodatapb.TabletType_MASTER
+	topoIsolated := sm.topoIsolated
+	sm.mu.Unlock()
+	if tabletType == topodatapb.TabletType_MASTER && state == StateServing && topoIsolated {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot transition to %v %v: the master is isolated from the topo", tabletType, state)
+	}
+
+	ctx, cancel := sm.transitionContext()
+	defer cancel()
+	if _, err := sm.se.EnsureConnectionAndDB(ctx, false); err != nil {
+		// A new master creates the database if it's missing.
+		if sqlErr, ok := err.(*mysql.SQLError); !ok || sqlErr.Num != mysql.ERBadDb || tabletType != topodatapb.TabletType_MASTER {
+			return vterrors.Wrapf(err, "cannot transition to %v %v: cannot connect to mysql", tabletType, state)
+		}
+	}
+	if !state.serving() {
+		return nil
+	}
+	// Writes are only probed on a master. Others are
+	// read-only until they're promoted.
+	if err := sm.qe.IsMySQLReachable(ctx, tabletType == topodatapb.TabletType_MASTER && isMaster); err != nil {
+		return vterrors.Wrapf(err, "cannot transition to %v %v: mysql cannot serve", tabletType, state)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestStateManagerCanTransition(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	order.Set(0)
+	assert.NoError(t, sm.CanTransition(topodatapb.TabletType_MASTER, StateServing))
+	// Nothing was opened or closed, and the state didn't change.
+	assert.EqualValues(t, 0, order.Get())
+	assert.Equal(t, topodatapb.TabletType_REPLICA, sm.Target().TabletType)
+	assert.Equal(t, StateServing, sm.State())
+	assert.Equal(t, testStateNonMaster, sm.te.(*testTxEngine).state)
+
+	sm.se.(*testSchemaEngine).failMySQL = true
+	err = sm.CanTransition(topodatapb.TabletType_MASTER, StateServing)
+	assert.EqualError(t, err, "cannot transition to MASTER Serving: cannot connect to mysql: intentional error")
+
+	sm.qe.(*testQueryEngine).failMySQL = true
+	err = sm.CanTransition(topodatapb.TabletType_MASTER, StateServing)
+	assert.EqualError(t, err, "cannot transition to MASTER Serving: mysql cannot serve: intentional error")
+
+	// Not serving doesn't need the query engine.
+	sm.qe.(*testQueryEngine).failMySQL = true
+	assert.NoError(t, sm.CanTransition(topodatapb.TabletType_MASTER, StateNotServing))
+	assert.NoError(t, sm.CanTransition(topodatapb.TabletType_RESTORE, StateNotServing))
+	sm.qe.(*testQueryEngine).failMySQL = false
+
+	sm.transitioning.Acquire()
+	err = sm.CanTransition(topodatapb.TabletType_MASTER, StateServing)
+	sm.transitioning.Release()
+	assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err))
+	assert.Contains(t, err.Error(), "a state transition is in progress")
+}
+
+func TestStateManagerCanTransitionMaster(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	// A master that fails writes can't stay one.
+	qe := sm.qe.(*testQueryEngine)
+	qe.failWrites.Set(true)
+	err = sm.CanTransition(topodatapb.TabletType_MASTER, StateServing)
+	assert.EqualError(t, err, "cannot transition to MASTER Serving: mysql cannot serve: mysql is not accepting writes: disk full")
+	// Replicas don't need writes.
+	assert.NoError(t, sm.CanTransition(topodatapb.TabletType_REPLICA, StateServing))
+	qe.failWrites.Set(false)
+
+	sm.mu.Lock()
+	sm.topoIsolated = true
+	sm.mu.Unlock()
+	err = sm.CanTransition(topodatapb.TabletType_MASTER, StateServing)
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	assert.NoError(t, sm.CanTransition(topodatapb.TabletType_MASTER, StateNotServing))
+	sm.mu.Lock()
+	sm.topoIsolated = false
+	sm.mu.Unlock()
+}
+
+func TestStateManagerCanTransitionMissingDB(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	se := &missingDBSchemaEngine{testSchemaEngine: sm.se.(*testSchemaEngine)}
+	sm.se = se
+
+	// The master creates the database, others need it.
+	assert.NoError(t, sm.CanTransition(topodatapb.TabletType_MASTER, StateServing))
+	err := sm.CanTransition(topodatapb.TabletType_REPLICA, StateServing)
+	assert.Contains(t, err.Error(), "cannot connect to mysql")
+	assert.Equal(t, []bool{false, false}, se.createDBCalls)
+}
+
+// missingDBSchemaEngine fails to connect because the database is missing.
+type missingDBSchemaEngine struct {
+	*testSchemaEngine
+}
+
+func (se *missingDBSchemaEngine) EnsureConnectionAndDB(ctx context.Context, createDB bool) (bool, error) {
+	se.createDBCalls = append(se.createDBCalls, createDB)
+	return false, mysql.NewSQLError(mysql.ERBadDb, mysql.SSUnknownSQLState, "unknown database")
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"encoding/json"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+// configSnapshot is the config the stateManager was initialized
+// with, once flags, config file and defaults were resolved. It's
+// reported at /debug/config/effective along with the reloads of the
+// live fields and the values the stateManager derived from it.
+type configSnapshot struct {
+	Time time.Time `json:"time"`
+	// Config is the config as a config file would set it: the
+	// passwords are redacted, and it can be parsed back as is.
+	Config *tabletenv.TabletConfig `json:"config"`
+	// FlagOnly has the fields of the config that can only be
+	// set by flags, which Config leaves out.
+	FlagOnly flagOnlyConfig `json:"flagOnly"`
+}
+
+// flagOnlyConfig lists the fields of tabletenv.TabletConfig that
+// aren't serialized.
+type flagOnlyConfig struct {
+	StrictTableACL              bool                             `json:"strictTableACL"`
+	EnableTableACLDryRun        bool                             `json:"enableTableACLDryRun"`
+	TableACLExemptACL           string                           `json:"tableACLExemptACL"`
+	TwoPCEnable                 bool                             `json:"twoPCEnable"`
+	TwoPCCoordinatorAddress     string                           `json:"twoPCCoordinatorAddress"`
+	TwoPCAbandonAge             tabletenv.Seconds                `json:"twoPCAbandonAge"`
+	EnableTxThrottler           bool                             `json:"enableTxThrottler"`
+	TxThrottlerConfig           string                           `json:"txThrottlerConfig"`
+	TxThrottlerHealthCheckCells []string                         `json:"txThrottlerHealthCheckCells"`
+	TxThrottlerDryRun           bool                             `json:"txThrottlerDryRun"`
+	TransactionLimit            tabletenv.TransactionLimitConfig `json:"transactionLimit"`
+	EnforceStrictTransTables    bool                             `json:"enforceStrictTransTables"`
+}
+
+// stateManagerSettings are the values the stateManager uses,
+// whether they were configured or came from the defaults.
+type stateManagerSettings struct {
+	TransitionGracePeriod             time.Duration `json:"transitionGracePeriod"`
+	ShutdownGracePeriod               time.Duration `json:"shutdownGracePeriod"`
+	QueryKillGracePeriod              time.Duration `json:"queryKillGracePeriod"`
+	TransitionRetryInterval           time.Duration `json:"transitionRetryInterval"`
+	Timebomb                          time.Duration `json:"timebomb"`
+	SnapshotMaxAge                    time.Duration `json:"snapshotMaxAge"`
+	PlanWarmupTimeout                 time.Duration `json:"planWarmupTimeout"`
+	LivenessThreshold                 time.Duration `json:"livenessThreshold"`
+	StuckThreshold                    time.Duration `json:"stuckThreshold"`
+	FlapThreshold                     int           `json:"flapThreshold"`
+	FlapWindow                        time.Duration `json:"flapWindow"`
+	FlapIgnoreOperator                bool          `json:"flapIgnoreOperator"`
+	LagHistorySize                    int           `json:"lagHistorySize"`
+	TopoIsolationTimeout              time.Duration `json:"topoIsolationTimeout"`
+	CheckMySQLMinErrorTables          int           `json:"checkMySQLMinErrorTables"`
+	ServeWithoutReplication           bool          `json:"serveWithoutReplication"`
+	DrainStreamsOnLameduck            bool          `json:"drainStreamsOnLameduck"`
+	CrashOnPanic                      bool          `json:"crashOnPanic"`
+	UnhealthySubcomponentsStopServing bool          `json:"unhealthySubcomponentsStopServing"`
+	ServerIdentityChangeFatal         bool          `json:"serverIdentityChangeFatal"`
+	MasterWritableWait                time.Duration `json:"masterWritableWait"`
+	TransitionTimeout                 time.Duration `json:"transitionTimeout"`
+	SkipReadOnlyCheck                 bool          `json:"skipReadOnlyCheck"`
+	RetryableRequestErrors            bool          `json:"retryableRequestErrors"`
+	ServingBroadcastInterval          time.Duration `json:"servingBroadcastInterval"`
+	NotServingBroadcastInterval       time.Duration `json:"notServingBroadcastInterval"`
+	HealthIdentityMismatchFatal       bool          `json:"healthIdentityMismatchFatal"`
+	KeepVStreamerOnBackup             bool          `json:"keepVStreamerOnBackup"`
+	StructuredLogs                    bool          `json:"structuredLogs"`
+	SnapshotExportMaxPause            time.Duration `json:"snapshotExportMaxPause"`
+	SnapshotExportTTL                 time.Duration `json:"snapshotExportTTL"`
+}
+
+// effectiveConfig is reported at /debug/config/effective.
+type effectiveConfig struct {
+	configSnapshot
+	Settings stateManagerSettings `json:"settings"`
+	Live     liveConfigValues     `json:"live"`
+	Reloads  []*configReload      `json:"reloads"`
+}
+
+// newConfigSnapshot returns the snapshot of config.
+func newConfigSnapshot(config *tabletenv.TabletConfig) *configSnapshot {
+	snapshot := &configSnapshot{
+		Time: time.Now(),
+		FlagOnly: flagOnlyConfig{
+			StrictTableACL:              config.StrictTableACL,
+			EnableTableACLDryRun:        config.EnableTableACLDryRun,
+			TableACLExemptACL:           config.TableACLExemptACL,
+			TwoPCEnable:                 config.TwoPCEnable,
+			TwoPCCoordinatorAddress:     config.TwoPCCoordinatorAddress,
+			TwoPCAbandonAge:             config.TwoPCAbandonAge,
+			EnableTxThrottler:           config.EnableTxThrottler,
+			TxThrottlerConfig:           config.TxThrottlerConfig,
+			TxThrottlerHealthCheckCells: append([]string{}, config.TxThrottlerHealthCheckCells...),
+			TxThrottlerDryRun:           config.TxThrottlerDryRun,
+			TransactionLimit:            config.TransactionLimitConfig,
+			EnforceStrictTransTables:    config.EnforceStrictTransTables,
+		},
+	}
+	// The marshaling redacts the passwords, and drops what
+	// can't be parsed back.
+	snapshot.Config = &tabletenv.TabletConfig{}
+	data, err := json.Marshal(config)
+	if err == nil {
+		err = json.Unmarshal(data, snapshot.Config)
+	}
+	if err != nil {
+		log.Errorf("Could not snapshot the config: %v", err)
+	}
+	return snapshot
+}
+
+// ConfigSnapshot returns the config sm was initialized
+// with, or nil if it wasn't yet.
+func (sm *stateManager) ConfigSnapshot() *configSnapshot {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.configSnapshot
+}
+
+// settings returns the values sm uses. The grace periods
+// are the current ones, which can be reloaded.
+func (sm *stateManager) settings() stateManagerSettings {
+	return stateManagerSettings{
+		TransitionGracePeriod:             sm.live.TransitionGracePeriod(),
+		ShutdownGracePeriod:               sm.live.ShutdownGracePeriod(),
+		QueryKillGracePeriod:              sm.queryKillGracePeriod,
+		TransitionRetryInterval:           transitionRetryInterval,
+		Timebomb:                          sm.timebombDuration,
+		SnapshotMaxAge:                    sm.snapshotMaxAge,
+		PlanWarmupTimeout:                 sm.planWarmupTimeout,
+		LivenessThreshold:                 sm.livenessThreshold,
+		StuckThreshold:                    sm.stuckThreshold,
+		FlapThreshold:                     sm.flaps.threshold,
+		FlapWindow:                        sm.flaps.window,
+		FlapIgnoreOperator:                sm.flaps.ignoreOperator,
+		LagHistorySize:                    sm.lagHistorySize(),
+		TopoIsolationTimeout:              sm.topoIsolationTimeout,
+		CheckMySQLMinErrorTables:          sm.checkMySQLMinErrorTables,
+		ServeWithoutReplication:           sm.serveWithoutReplication,
+		DrainStreamsOnLameduck:            sm.drainStreamsOnLameduck,
+		CrashOnPanic:                      sm.crashOnPanic,
+		UnhealthySubcomponentsStopServing: sm.unhealthySubcomponentsStopServing,
+		ServerIdentityChangeFatal:         sm.serverIdentityChangeFatal,
+		MasterWritableWait:                sm.masterWritableWait,
+		TransitionTimeout:                 sm.transitionTimeout,
+		SkipReadOnlyCheck:                 sm.skipReadOnlyCheck,
+		RetryableRequestErrors:            sm.retryableRequestErrors,
+		ServingBroadcastInterval:          sm.servingInterval,
+		NotServingBroadcastInterval:       sm.notServingInterval,
+		HealthIdentityMismatchFatal:       sm.hs.mismatchFatal,
+		KeepVStreamerOnBackup:             sm.keepVStreamerOnBackup,
+		StructuredLogs:                    sm.structuredLogs,
+		SnapshotExportMaxPause:            sm.exportMaxPause,
+		SnapshotExportTTL:                 sm.exportTTL,
+	}
+}
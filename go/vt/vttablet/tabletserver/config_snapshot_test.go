@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+	"vitess.io/vitess/go/yaml2"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestEffectiveConfig(t *testing.T) {
+	config := tabletenv.NewDefaultConfig()
+	config.OltpReadPool.Size = 7
+	config.TwoPCAbandonAge = 5
+	db := setupFakeDB(t)
+	defer db.Close()
+	tsv := NewTabletServer("TabletServerTest", config, memorytopo.NewServer(""), topodatapb.TabletAlias{})
+	defer tsv.StopService()
+
+	get := func() (int, []byte) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", tsv.exporter.URLPrefix()+"/debug/config/effective", nil)
+		http.DefaultServeMux.ServeHTTP(rr, req)
+		return rr.Code, rr.Body.Bytes()
+	}
+	code, _ := get()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+
+	dbcfgs := newDBConfigs(db)
+	dbcfgs.App.User = "vt_app"
+	dbcfgs.App.Password = "secret"
+	dbcfgs.Dba.Password = "secret"
+	target := querypb.Target{TabletType: topodatapb.TabletType_MASTER}
+	require.NoError(t, tsv.StartService(target, dbcfgs, nil /* mysqld */))
+	reloaded := tabletenv.NewDefaultConfig()
+	reloaded.GracePeriods.TransactionShutdownSeconds.Set(3 * time.Second)
+	require.NoError(t, tsv.ReloadConfig("test", reloaded))
+
+	code, body := get()
+	require.Equal(t, http.StatusOK, code, string(body))
+	assert.NotContains(t, string(body), "secret")
+	var got effectiveConfig
+	require.NoError(t, json.Unmarshal(body, &got))
+	assert.Equal(t, "vt_app", got.Config.DB.App.User)
+	assert.Equal(t, "****", got.Config.DB.App.Password)
+	assert.Equal(t, "****", got.Config.DB.Dba.Password)
+	assert.Equal(t, 7, got.Config.OltpReadPool.Size)
+	assert.Equal(t, tabletenv.Seconds(5), got.FlagOnly.TwoPCAbandonAge)
+	assert.Equal(t, 3*time.Second, got.Settings.ShutdownGracePeriod)
+	assert.Equal(t, transitionRetryInterval, got.Settings.TransitionRetryInterval)
+	assert.Equal(t, tsv.sm.timebombDuration, got.Settings.Timebomb)
+	assert.Equal(t, 3*time.Second, got.Live.ShutdownGracePeriod)
+	require.Len(t, got.Reloads, 1)
+	assert.Equal(t, "test", got.Reloads[0].Source)
+	assert.False(t, got.Reloads[0].Time.IsZero())
+
+	// The config parses back to the config that was reported.
+	snapshot := tsv.sm.ConfigSnapshot()
+	assert.True(t, snapshot.Time.Equal(got.Time))
+	assert.Equal(t, snapshot.Config, got.Config)
+	assert.Equal(t, snapshot.FlagOnly, got.FlagOnly)
+	var raw struct {
+		Config json.RawMessage `json:"config"`
+	}
+	require.NoError(t, json.Unmarshal(body, &raw))
+	parsed := &tabletenv.TabletConfig{}
+	require.NoError(t, yaml2.Unmarshal(raw.Config, parsed))
+	assert.Equal(t, snapshot.Config, parsed)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+)
+
+// connErrorWindow is how long the connection errors of the queries
+// of a table count towards the spread of the errors.
+var connErrorWindow = time.Minute
+
+// isConnFailure returns true if err looks like the connection to
+// mysql failed. Unlike mysql.IsConnErr, the killed queries don't
+// count: they don't say anything about mysql.
+func isConnFailure(err error) bool {
+	sqlErr, ok := err.(*mysql.SQLError)
+	return ok && mysql.IsConnErr(sqlErr) && sqlErr.Number() != mysql.ERQueryInterrupted
+}
+
+// AddConnError records that a query of planName on tableName
+// failed with a connection error.
+func (qe *QueryEngine) AddConnError(planName, tableName string) {
+	qe.queryConnErrorCounts.Add([]string{tableName, planName}, 1)
+
+	qe.connErrorsMu.Lock()
+	defer qe.connErrorsMu.Unlock()
+	qe.connErrors[tableName] = time.Now()
+}
+
+// ConnErrorTables returns the number of tables whose queries
+// failed with connection errors within the last connErrorWindow.
+// The older errors are forgotten.
+func (qe *QueryEngine) ConnErrorTables() int {
+	qe.connErrorsMu.Lock()
+	defer qe.connErrorsMu.Unlock()
+
+	since := time.Now().Add(-connErrorWindow)
+	for tableName, last := range qe.connErrors {
+		if last.Before(since) {
+			delete(qe.connErrors, tableName)
+		}
+	}
+	return len(qe.connErrors)
+}
+
+// suppressCheckMySQL returns true if the error of a failed mysql
+// check doesn't warrant shutting down the query service. The checks
+// that can't connect to mysql, or can't write to it, are always acted
+// upon. The others only are if the queries of enough tables failed
+// with connection errors recently: a flood of errors from a single
+// bad table must not cycle the tablet through closes and opens.
+func (sm *stateManager) suppressCheckMySQL(err error) bool {
+	if sm.checkMySQLMinErrorTables == 0 {
+		return false
+	}
+	if _, ok := err.(*mysqlWriteError); ok {
+		return false
+	}
+	if sqlErr, ok := err.(*mysql.SQLError); !ok || mysql.IsConnErr(sqlErr) {
+		return false
+	}
+	tables := sm.qe.ConnErrorTables()
+	if tables >= sm.checkMySQLMinErrorTables {
+		return false
+	}
+	sm.checkMySQLSuppressions.Add(1)
+	sm.checkMySQLSuppressedLog.Warningf("MySQL check failed, but the queries of only %d tables failed with connection errors in the last %v: keeping the query service up: %v", tables, connErrorWindow, err)
+	return true
+}
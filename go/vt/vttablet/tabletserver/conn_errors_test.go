@@ -0,0 +1,123 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestQueryEngineConnErrors(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+
+	qe := tsv.qe
+	connErrors := qe.queryConnErrorCounts.Counts()["test_table.Select"]
+	db.AddRejectedQuery("select * from test_table limit 10001", mysql.NewSQLError(mysql.CRServerLost, mysql.SSUnknownSQLState, "lost connection"))
+	db.AddQuery("select a from test_table where 1 != 1", sqltypes.MakeTestResult(sqltypes.MakeTestFields("a", "int64")))
+	db.AddRejectedQuery("select a from test_table limit 10001", mysql.NewSQLError(mysql.ERQueryInterrupted, mysql.SSUnknownSQLState, "killed"))
+
+	_, err := newTestQueryExecutor(ctx, tsv, "select * from test_table", 0).Execute()
+	require.Error(t, err)
+	_, err = newTestQueryExecutor(ctx, tsv, "select * from test_table", 0).Execute()
+	require.Error(t, err)
+	// The killed queries aren't connection failures.
+	_, err = newTestQueryExecutor(ctx, tsv, "select a from test_table", 0).Execute()
+	require.Error(t, err)
+
+	assert.Equal(t, 1, qe.ConnErrorTables())
+	assert.Equal(t, connErrors+2, qe.queryConnErrorCounts.Counts()["test_table.Select"])
+
+	defer func(saved time.Duration) { connErrorWindow = saved }(connErrorWindow)
+	connErrorWindow = 0
+	assert.Zero(t, qe.ConnErrorTables())
+}
+
+func TestStateManagerCheckMySQLSuppressed(t *testing.T) {
+	defer func(saved time.Duration) { transitionRetryInterval = saved }(transitionRetryInterval)
+	transitionRetryInterval = 10 * time.Millisecond
+
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	qe := sm.qe.(*testQueryEngine)
+	assert.Equal(t, 2, sm.checkMySQLMinErrorTables)
+
+	checked := make(chan struct{}, 10)
+	checkErr := errors.New("unexpected")
+	qe.reachable = func(bool) error {
+		checked <- struct{}{}
+		return checkErr
+	}
+	checkMySQL := func() {
+		// Wait for the previous check to release the throttler.
+		for !sm.checkMySQLThrottler.TryAcquire() {
+			time.Sleep(10 * time.Millisecond)
+		}
+		sm.checkMySQLThrottler.Release()
+		sm.CheckMySQL()
+		<-checked
+	}
+
+	// A check that can connect to mysql is ignored while the
+	// connection errors are confined to a single table.
+	suppressions := sm.checkMySQLSuppressions.Get()
+	checkErr = mysql.NewSQLError(mysql.ERConCount, mysql.SSUnknownSQLState, "too many connections")
+	qe.connErrorTables = 1
+	checkMySQL()
+	assert.Eventually(t, func() bool {
+		return sm.checkMySQLSuppressions.Get() == suppressions+1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, StateServing, sm.State())
+
+	// The checks that can't connect to mysql are always acted upon.
+	closes := order.Get()
+	checkErr = mysql.NewSQLError(mysql.CRConnHostError, mysql.SSUnknownSQLState, "connection refused")
+	checkMySQL()
+	assert.Eventually(t, func() bool { return order.Get() > closes }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, suppressions+1, sm.checkMySQLSuppressions.Get())
+
+	// So are the others once the errors are spread across tables.
+	assert.Eventually(t, func() bool { return sm.State() == StateServing && !sm.isTransitioning() }, 5*time.Second, 10*time.Millisecond)
+	closes = order.Get()
+	checkErr = mysql.NewSQLError(mysql.ERConCount, mysql.SSUnknownSQLState, "too many connections")
+	qe.connErrorTables = 2
+	checkMySQL()
+	assert.Eventually(t, func() bool { return order.Get() > closes }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, suppressions+1, sm.checkMySQLSuppressions.Get())
+
+	// Wait for the retry to finish.
+	assert.Eventually(t, func() bool {
+		sm.mu.Lock()
+		defer sm.mu.Unlock()
+		return !sm.retrying
+	}, 5*time.Second, 10*time.Millisecond)
+}
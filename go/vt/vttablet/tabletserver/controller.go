@@ -50,9 +50,26 @@ type Controller interface {
 	// InitDBConfig sets up the db config vars.
 	InitDBConfig(querypb.Target, *dbconfigs.DBConfigs, mysqlctl.MysqlDaemon) error
 
+	// RestoredServingType returns the serving type saved by the
+	// previous process, if InitDBConfig loaded a state snapshot.
+	RestoredServingType() (tabletType topodatapb.TabletType, terTimestamp time.Time, serving bool, reason string, ok bool)
+
 	// SetServingType transitions the query service to the required serving type.
 	// Returns true if the state of QueryService or the tablet type changed.
-	SetServingType(tabletType topodatapb.TabletType, terTimestamp time.Time, serving bool, reason string) error
+	SetServingType(ctx context.Context, tabletType topodatapb.TabletType, terTimestamp time.Time, serving bool, reason string) error
+
+	// SetServingTypeAsync is like SetServingType, but it returns
+	// as soon as the transition is queued. The handle resolves with
+	// the result of the transition.
+	SetServingTypeAsync(ctx context.Context, tabletType topodatapb.TabletType, terTimestamp time.Time, serving bool, reason string, waitRetries bool) *TransitionHandle
+
+	// TransitionStatus returns the status of the asynchronous
+	// transition identified by token, if it's still known.
+	TransitionStatus(token int64) (AsyncTransitionStatus, bool)
+
+	// CanTransition checks if the query service could transition to
+	// the serving type right now, without changing anything.
+	CanTransition(tabletType topodatapb.TabletType, serving bool) error
 
 	// EnterLameduck causes tabletserver to enter the lameduck state.
 	EnterLameduck()
@@ -66,8 +83,9 @@ type Controller interface {
 	// ClearQueryPlanCache clears internal query plan cache
 	ClearQueryPlanCache()
 
-	// ReloadSchema makes the quey service reload its schema cache
-	ReloadSchema(ctx context.Context) error
+	// ReloadSchema makes the quey service reload its schema cache.
+	// It returns the names of the tables that changed.
+	ReloadSchema(ctx context.Context) ([]string, error)
 
 	// RegisterQueryRuleSource adds a query rule source
 	RegisterQueryRuleSource(ruleSource string)
@@ -89,6 +107,13 @@ type Controller interface {
 
 	// TopoServer returns the topo server.
 	TopoServer() *topo.Server
+
+	// SetTopoLastSeenHealthy records the last time the topo was reachable.
+	SetTopoLastSeenHealthy(lastSeen time.Time)
+
+	// TopoIsolationTimeout returns how long a master can go without
+	// reaching the topo before it stops serving. It's 0 if disabled.
+	TopoIsolationTimeout() time.Duration
 }
 
 // Ensure TabletServer satisfies Controller interface.
@@ -0,0 +1,103 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"encoding/json"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+)
+
+// The decision points of the stateManager that are logged.
+const (
+	decisionTransitionStart = "transition_start"
+	decisionTransitionEnd   = "transition_end"
+	decisionRetryScheduled  = "retry_scheduled"
+	decisionEnterLameduck   = "lameduck_enter"
+	decisionExitLameduck    = "lameduck_exit"
+	decisionCheckMySQL      = "check_mysql"
+	decisionReplUnhealthy   = "repl_unhealthy"
+	decisionReplHealthy     = "repl_healthy"
+)
+
+// decision is a decision point of the stateManager. If structuredLogs
+// is set, it's logged as a JSON object with fixed field names, so that
+// the log pipelines can query it. All fields are always present.
+type decision struct {
+	Event      string `json:"event"`
+	FromState  string `json:"from_state"`
+	ToState    string `json:"to_state"`
+	TabletType string `json:"tablet_type"`
+	Reason     string `json:"reason"`
+	Error      string `json:"error"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// writeDecision writes a structured decision line with logf.
+// It's a var for tests.
+var writeDecision = func(logf func(string, ...interface{}), line []byte) {
+	logf("%s", line)
+}
+
+// logDecision logs d with logf. Unless structuredLogs is set, the
+// human-readable message described by format and args is logged
+// instead, if there is one.
+func (sm *stateManager) logDecision(logf func(string, ...interface{}), d *decision, format string, args ...interface{}) {
+	if !sm.structuredLogs {
+		if format != "" {
+			logf(format, args...)
+		}
+		return
+	}
+	b, err := json.Marshal(d)
+	if err != nil {
+		log.Errorf("Could not marshal state manager decision: %v", err)
+		return
+	}
+	writeDecision(logf, b)
+}
+
+// decisionLocked returns a decision about the current state of sm,
+// with err as its error, if any.
+func (sm *stateManager) decisionLocked(event string, err error) *decision {
+	d := &decision{
+		Event:      event,
+		FromState:  sm.state.String(),
+		ToState:    sm.state.String(),
+		TabletType: sm.target.TabletType.String(),
+	}
+	if err != nil {
+		d.Error = err.Error()
+	}
+	return d
+}
+
+func (sm *stateManager) decision(event string, err error) *decision {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.decisionLocked(event, err)
+}
+
+// durationMs returns the milliseconds elapsed since start,
+// or 0 if start is not set.
+func durationMs(start time.Time) int64 {
+	if start.IsZero() {
+		return 0
+	}
+	return time.Since(start).Milliseconds()
+}
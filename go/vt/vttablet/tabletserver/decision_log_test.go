@@ -0,0 +1,125 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/timer/fakeclock"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// captureDecisions records the structured decisions
+// logged until the returned func is called.
+func captureDecisions(t *testing.T) (get func() []map[string]interface{}, restore func()) {
+	t.Helper()
+	var mu sync.Mutex
+	var lines [][]byte
+	saved := writeDecision
+	writeDecision = func(_ func(string, ...interface{}), line []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		lines = append(lines, line)
+	}
+	get = func() []map[string]interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		var decisions []map[string]interface{}
+		for _, line := range lines {
+			d := make(map[string]interface{})
+			require.NoError(t, json.Unmarshal(line, &d))
+			decisions = append(decisions, d)
+		}
+		return decisions
+	}
+	return get, func() { writeDecision = saved }
+}
+
+func TestStateManagerStructuredLogs(t *testing.T) {
+	get, restore := captureDecisions(t)
+	defer restore()
+	// The retries of the failed transitions never fire.
+	sm := newTestStateManagerWithClock(t, fakeclock.New(testNow))
+	defer sm.StopService()
+	sm.structuredLogs = true
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	sm.EnterLameduck()
+	sm.ExitLameduck()
+	sm.se.(*testSchemaEngine).failMySQL = true
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "promoted")
+	require.Error(t, err)
+
+	decisions := get()
+	var events []string
+	for _, d := range decisions {
+		// The field names are fixed.
+		for _, field := range []string{"event", "from_state", "to_state", "tablet_type", "reason", "error", "duration_ms"} {
+			assert.Contains(t, d, field)
+		}
+		events = append(events, d["event"].(string))
+	}
+	assert.Equal(t, []string{
+		decisionTransitionStart,
+		decisionTransitionEnd,
+		decisionReplHealthy,
+		decisionExitLameduck,
+		decisionEnterLameduck,
+		decisionExitLameduck,
+		decisionTransitionStart,
+		// The failed transition doesn't end.
+		decisionRetryScheduled,
+		decisionExitLameduck,
+	}, events)
+
+	// The transition end is logged along with the health history record.
+	end := decisions[1]
+	assert.Equal(t, StateNotConnected.String(), end["from_state"])
+	assert.Equal(t, StateServing.String(), end["to_state"])
+	assert.Equal(t, "REPLICA", end["tablet_type"])
+
+	start := decisions[6]
+	assert.Equal(t, "Serving", start["from_state"])
+	assert.Equal(t, "Serving", start["to_state"])
+	assert.Equal(t, "MASTER", start["tablet_type"])
+	assert.Equal(t, "promoted", start["reason"])
+
+	retry := decisions[7]
+	assert.Equal(t, "transition failed", retry["reason"])
+	assert.Equal(t, "MASTER", retry["tablet_type"])
+	assert.NotEmpty(t, retry["error"])
+}
+
+func TestStateManagerHumanReadableLogs(t *testing.T) {
+	get, restore := captureDecisions(t)
+	defer restore()
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	sm.EnterLameduck()
+	assert.Empty(t, get())
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fairshare divides the request concurrency of vttablet
+// across the effective callers. See the Limiter struct for details.
+package fairshare
+
+import (
+	"sync"
+
+	"golang.org/x/net/context"
+
+	"vitess.io/vitess/go/cache"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const unknown string = "unknown"
+
+// Limiter admits up to a fixed number of concurrent requests, and
+// divides them across the effective callers:
+//   - A caller can use the slots that nobody else needs, even if this
+//     takes it above its share.
+//   - Once all slots are in use, a caller that holds less than its share
+//     waits for the next free slot, and gets it ahead of anybody else.
+//     A caller that holds its share or more is rejected right away.
+//   - Waiting requests are woken up in FIFO order, and are unblocked
+//     if their context is done.
+type Limiter struct {
+	// Immutable fields.
+	enabled bool
+	limit   int
+	share   int
+
+	mu      sync.Mutex
+	inUse   int
+	usage   map[string]int
+	waiters []*waiter
+	// callers holds the stats of the recently seen callers. It bounds
+	// the number of exported callers, while usage only holds the
+	// callers that have requests in flight.
+	callers *cache.LRUCache
+}
+
+// waiter is a request waiting for a slot. admitted is
+// closed once the slot was assigned to it.
+type waiter struct {
+	caller   string
+	admitted chan struct{}
+}
+
+// callerStats are the exported stats of a caller.
+type callerStats struct {
+	rejections int64
+}
+
+// Size implements cache.Value.
+func (cs *callerStats) Size() int {
+	return 1
+}
+
+// DoneFunc is returned by Admit and must be called once the request is done.
+type DoneFunc func()
+
+// New returns a Limiter. If fair-share admission is not enabled,
+// it admits all requests without tracking them.
+func New(env tabletenv.Env) *Limiter {
+	config := env.Config().FairShare
+	if !config.Enable {
+		return &Limiter{}
+	}
+
+	l := &Limiter{
+		enabled: true,
+		limit:   config.Concurrency,
+		share:   int(float64(config.Concurrency) * config.MaxShare),
+		usage:   make(map[string]int),
+		callers: cache.NewLRUCache(int64(config.CallerCacheSize)),
+	}
+	env.Exporter().NewGaugesFuncWithMultiLabels("FairShareUsage", "Requests in flight per effective caller", []string{"caller"}, l.callerUsage)
+	env.Exporter().NewGaugesFuncWithMultiLabels("FairShareLimit", "Requests a caller can hold while others are waiting", []string{"caller"}, l.callerLimits)
+	env.Exporter().NewCountersFuncWithMultiLabels("FairShareRejections", "Requests rejected because the caller was over its share", []string{"caller"}, l.callerRejections)
+	return l
+}
+
+// Admit blocks until the request of the effective caller can be executed.
+// It fails if the caller is over its share, or if ctx is done while it waits.
+// If it succeeds, the returned DoneFunc must be called when the request is done.
+func (l *Limiter) Admit(ctx context.Context, effective *vtrpcpb.CallerID) (DoneFunc, error) {
+	if !l.enabled {
+		return func() {}, nil
+	}
+	caller := unknown
+	if effective != nil {
+		caller = callerid.GetPrincipal(effective)
+	}
+
+	l.mu.Lock()
+	cs := l.callerStatsLocked(caller)
+	switch {
+	case l.inUse < l.limit:
+		l.admitLocked(caller)
+		l.mu.Unlock()
+		return l.doneFunc(caller), nil
+	case l.usage[caller] >= l.share:
+		cs.rejections++
+		usage := l.usage[caller]
+		l.mu.Unlock()
+		log.V(2).Infof("FairShare: rejecting request of caller %s: over its share", caller)
+		return nil, vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "caller %s holds %d concurrent requests, which is at or above its share of %d", caller, usage, l.share)
+	}
+	w := &waiter{caller: caller, admitted: make(chan struct{})}
+	l.waiters = append(l.waiters, w)
+	l.mu.Unlock()
+
+	select {
+	case <-w.admitted:
+		return l.doneFunc(caller), nil
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	select {
+	case <-w.admitted:
+		// The slot was assigned in the meantime. Pass it on.
+		l.releaseLocked(caller)
+	default:
+		l.removeWaiterLocked(w)
+	}
+	return nil, vterrors.Errorf(vtrpcpb.Code_DEADLINE_EXCEEDED, "waiting for fair share admission of caller %s: %v", caller, ctx.Err())
+}
+
+func (l *Limiter) doneFunc(caller string) DoneFunc {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.releaseLocked(caller)
+		})
+	}
+}
+
+func (l *Limiter) admitLocked(caller string) {
+	l.inUse++
+	l.usage[caller]++
+}
+
+// releaseLocked frees the slot of the caller, and assigns it to the
+// first waiter under its share. If there's none, the first waiter
+// gets it.
+func (l *Limiter) releaseLocked(caller string) {
+	l.inUse--
+	if l.usage[caller] <= 1 {
+		delete(l.usage, caller)
+	} else {
+		l.usage[caller]--
+	}
+	if len(l.waiters) == 0 {
+		return
+	}
+	next := l.waiters[0]
+	for _, w := range l.waiters {
+		if l.usage[w.caller] < l.share {
+			next = w
+			break
+		}
+	}
+	l.removeWaiterLocked(next)
+	l.admitLocked(next.caller)
+	close(next.admitted)
+}
+
+func (l *Limiter) removeWaiterLocked(w *waiter) {
+	for i, cur := range l.waiters {
+		if cur == w {
+			l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+func (l *Limiter) callerStatsLocked(caller string) *callerStats {
+	if v, ok := l.callers.Get(caller); ok {
+		return v.(*callerStats)
+	}
+	cs := &callerStats{}
+	l.callers.Set(caller, cs)
+	return cs
+}
+
+func (l *Limiter) callerUsage() map[string]int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	m := make(map[string]int64)
+	for _, caller := range l.callers.Keys() {
+		m[caller] = int64(l.usage[caller])
+	}
+	return m
+}
+
+func (l *Limiter) callerLimits() map[string]int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	m := make(map[string]int64)
+	for _, caller := range l.callers.Keys() {
+		m[caller] = int64(l.share)
+	}
+	return m
+}
+
+func (l *Limiter) callerRejections() map[string]int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	m := make(map[string]int64)
+	for _, item := range l.callers.Items() {
+		m[item.Key] = item.Value.(*callerStats).rejections
+	}
+	return m
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fairshare
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func newTestLimiter(concurrency int, maxShare float64, callerCacheSize int) *Limiter {
+	config := tabletenv.NewDefaultConfig()
+	config.FairShare.Enable = true
+	config.FairShare.Concurrency = concurrency
+	config.FairShare.MaxShare = maxShare
+	config.FairShare.CallerCacheSize = callerCacheSize
+	return New(tabletenv.NewEnv(config, "FairShareTest"))
+}
+
+// admitAsync calls Admit in a goroutine. The result is sent
+// to the returned channel once the request is admitted or fails.
+func admitAsync(ctx context.Context, l *Limiter, caller *vtrpcpb.CallerID) chan error {
+	ch := make(chan error, 1)
+	go func() {
+		_, err := l.Admit(ctx, caller)
+		ch <- err
+	}()
+	return ch
+}
+
+func waitForWaiters(t *testing.T, l *Limiter, want int) {
+	t.Helper()
+	for i := 0; i < 1000; i++ {
+		l.mu.Lock()
+		got := len(l.waiters)
+		l.mu.Unlock()
+		if got == want {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("waiters did not reach %d", want)
+}
+
+func TestFairShareDisabledAdmitsAll(t *testing.T) {
+	l := New(tabletenv.NewEnv(tabletenv.NewDefaultConfig(), "FairShareTest"))
+	noisy := callerid.NewEffectiveCallerID("noisy", "", "")
+	for i := 0; i < 200; i++ {
+		done, err := l.Admit(context.Background(), noisy)
+		require.NoError(t, err)
+		defer done()
+	}
+}
+
+func TestFairShareNoisyCaller(t *testing.T) {
+	l := newTestLimiter(4, 0.5, 10)
+	noisy := callerid.NewEffectiveCallerID("noisy", "", "")
+	quiet := callerid.NewEffectiveCallerID("quiet", "", "")
+
+	// Without contention, the noisy caller uses the whole capacity.
+	var noisyDone []DoneFunc
+	for i := 0; i < 4; i++ {
+		done, err := l.Admit(context.Background(), noisy)
+		require.NoError(t, err)
+		noisyDone = append(noisyDone, done)
+	}
+	_, err := l.Admit(context.Background(), noisy)
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	assert.EqualError(t, err, "caller noisy holds 4 concurrent requests, which is at or above its share of 2")
+
+	// The quiet caller is under its share, and waits
+	// for the next slot instead of being rejected.
+	quiet1 := admitAsync(context.Background(), l, quiet)
+	waitForWaiters(t, l, 1)
+	noisyDone[0]()
+	require.NoError(t, <-quiet1)
+
+	// The freed slot went to the quiet caller, and the noisy
+	// one keeps hitting its share ceiling.
+	_, err = l.Admit(context.Background(), noisy)
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+
+	// The quiet caller gets a second slot ahead of the noisy caller.
+	quiet2 := admitAsync(context.Background(), l, quiet)
+	waitForWaiters(t, l, 1)
+	noisyDone[1]()
+	require.NoError(t, <-quiet2)
+
+	// Calling done more than once has no effect.
+	noisyDone[1]()
+	l.mu.Lock()
+	assert.Equal(t, 4, l.inUse)
+	assert.Equal(t, map[string]int{"noisy": 2, "quiet": 2}, l.usage)
+	l.mu.Unlock()
+
+	assert.Equal(t, map[string]int64{"noisy": 2, "quiet": 2}, l.callerUsage())
+	assert.Equal(t, map[string]int64{"noisy": 2, "quiet": 2}, l.callerLimits())
+	assert.Equal(t, map[string]int64{"noisy": 2, "quiet": 0}, l.callerRejections())
+
+	// Unused capacity is available to whoever needs it.
+	noisyDone[2]()
+	noisyDone[3]()
+	for i := 0; i < 2; i++ {
+		_, err := l.Admit(context.Background(), quiet)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, map[string]int64{"noisy": 0, "quiet": 4}, l.callerUsage())
+}
+
+func TestFairShareWaitContextDone(t *testing.T) {
+	l := newTestLimiter(2, 0.5, 10)
+	noisy := callerid.NewEffectiveCallerID("noisy", "", "")
+	quiet := callerid.NewEffectiveCallerID("quiet", "", "")
+
+	var noisyDone []DoneFunc
+	for i := 0; i < 2; i++ {
+		done, err := l.Admit(context.Background(), noisy)
+		require.NoError(t, err)
+		noisyDone = append(noisyDone, done)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	quiet1 := admitAsync(ctx, l, quiet)
+	quiet2 := admitAsync(context.Background(), l, quiet)
+	waitForWaiters(t, l, 2)
+	cancel()
+	err := <-quiet1
+	assert.Equal(t, vtrpcpb.Code_DEADLINE_EXCEEDED, vterrors.Code(err))
+	assert.Contains(t, err.Error(), "waiting for fair share admission of caller quiet")
+	waitForWaiters(t, l, 1)
+
+	// The canceled waiter doesn't get the next slot.
+	noisyDone[0]()
+	require.NoError(t, <-quiet2)
+	assert.Equal(t, map[string]int64{"noisy": 1, "quiet": 1}, l.callerUsage())
+}
+
+func TestFairShareCallerCache(t *testing.T) {
+	l := newTestLimiter(10, 0.5, 3)
+	for i := 0; i < 5; i++ {
+		caller := callerid.NewEffectiveCallerID(fmt.Sprintf("caller%d", i), "", "")
+		_, err := l.Admit(context.Background(), caller)
+		require.NoError(t, err)
+	}
+	_, err := l.Admit(context.Background(), nil)
+	require.NoError(t, err)
+
+	// Only the most recent callers are exported.
+	assert.Equal(t, map[string]int64{"caller3": 1, "caller4": 1, "unknown": 1}, l.callerUsage())
+	l.mu.Lock()
+	assert.Equal(t, 6, l.inUse)
+	l.mu.Unlock()
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"fmt"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/hook"
+	"vitess.io/vitess/go/vt/log"
+)
+
+// The events of the flap detector, as passed to the flap hooks.
+const (
+	flapTripped = "tripped"
+	flapCleared = "cleared"
+)
+
+// runFlapHook is for tests.
+var runFlapHook = func(h *hook.Hook) *hook.HookResult {
+	return h.Execute()
+}
+
+// flapHook is notified when the tablet starts flapping between
+// serving and not serving, and when it stops. The hooks are called
+// under the mutex of the stateManager: they must not block.
+type flapHook interface {
+	flapChanged(event string, changes int, window time.Duration, state string)
+}
+
+// logFlapHook logs the flap events.
+type logFlapHook struct{}
+
+func (logFlapHook) flapChanged(event string, changes int, window time.Duration, state string) {
+	if event == flapTripped {
+		log.Errorf("TabletServer is flapping: the serving state changed %d times in the last %v, now %v", changes, window, state)
+		return
+	}
+	log.Infof("TabletServer stopped flapping: the serving state changed %d times in the last %v, now %v", changes, window, state)
+}
+
+// statsFlapHook counts the flap events by event.
+type statsFlapHook struct {
+	events *stats.CountersWithSingleLabel
+}
+
+func (sh statsFlapHook) flapChanged(event string, changes int, window time.Duration, state string) {
+	sh.events.Add(event, 1)
+}
+
+// execFlapHook runs a vthook for the flap events. The event, the number
+// of serving changes, the window and the state are passed in the
+// FLAP_EVENT, FLAP_CHANGES, FLAP_WINDOW and TABLET_STATE variables.
+type execFlapHook struct {
+	name string
+}
+
+func (eh execFlapHook) flapChanged(event string, changes int, window time.Duration, state string) {
+	h := hook.NewHookWithEnv(eh.name, nil, map[string]string{
+		"FLAP_EVENT":   event,
+		"FLAP_CHANGES": fmt.Sprint(changes),
+		"FLAP_WINDOW":  window.String(),
+		"TABLET_STATE": state,
+	})
+	go func() {
+		if hr := runFlapHook(h); hr.ExitStatus != hook.HOOK_SUCCESS {
+			log.Errorf("Serving flap hook %v failed: %v", h.Name, hr.String())
+		}
+	}()
+}
+
+// flapDetector counts the changes between serving and not serving
+// over a sliding window. The tablet is flapping while there are more
+// than threshold changes in the window: the hooks are notified when
+// the threshold trips, and again when it clears. If ignoreOperator is
+// set, the changes requested by an operator are not counted. A zero
+// threshold disables the detection. flapDetector is not thread safe.
+type flapDetector struct {
+	threshold      int
+	window         time.Duration
+	ignoreOperator bool
+	hooks          []flapHook
+
+	// changes are the times of the changes in the window, oldest
+	// first. Only the last threshold+1 are kept: older ones can't
+	// make a difference.
+	changes  []time.Time
+	flapping bool
+}
+
+// record counts a serving change that happened at now, and
+// notifies the hooks if the threshold trips.
+func (fd *flapDetector) record(now time.Time, byOperator bool, state string) {
+	if fd.threshold == 0 || (byOperator && fd.ignoreOperator) {
+		return
+	}
+	fd.changes = append(fd.changes, now)
+	if len(fd.changes) > fd.threshold+1 {
+		fd.changes = fd.changes[len(fd.changes)-fd.threshold-1:]
+	}
+	fd.check(now, state)
+}
+
+// check forgets the changes that left the window, and notifies
+// the hooks if the threshold trips or clears.
+func (fd *flapDetector) check(now time.Time, state string) {
+	if fd.threshold == 0 {
+		return
+	}
+	i := 0
+	for i < len(fd.changes) && now.Sub(fd.changes[i]) > fd.window {
+		i++
+	}
+	fd.changes = fd.changes[i:]
+
+	flapping := len(fd.changes) > fd.threshold
+	if flapping == fd.flapping {
+		return
+	}
+	fd.flapping = flapping
+	event := flapCleared
+	if flapping {
+		event = flapTripped
+	}
+	for _, h := range fd.hooks {
+		h.flapChanged(event, len(fd.changes), fd.window, state)
+	}
+}
+
+// Flapping returns true if the threshold is tripped.
+func (fd *flapDetector) Flapping() bool {
+	return fd.flapping
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/hook"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+type recordedFlap struct {
+	event   string
+	changes int
+}
+
+type testFlapHook struct {
+	events []recordedFlap
+}
+
+func (th *testFlapHook) flapChanged(event string, changes int, window time.Duration, state string) {
+	th.events = append(th.events, recordedFlap{event: event, changes: changes})
+}
+
+func TestFlapDetector(t *testing.T) {
+	th := &testFlapHook{}
+	fd := &flapDetector{threshold: 3, window: time.Minute, hooks: []flapHook{th}}
+
+	// 3 changes don't trip the threshold, the 4th does.
+	for i := 0; i < 3; i++ {
+		fd.record(testNow.Add(time.Duration(i)*time.Second), false, "")
+	}
+	assert.False(t, fd.Flapping())
+	assert.Empty(t, th.events)
+	fd.record(testNow.Add(3*time.Second), false, "")
+	assert.True(t, fd.Flapping())
+	assert.Equal(t, []recordedFlap{{flapTripped, 4}}, th.events)
+
+	// More changes don't notify again, and the
+	// kept changes are bounded by the threshold.
+	for i := 4; i < 10; i++ {
+		fd.record(testNow.Add(time.Duration(i)*time.Second), false, "")
+	}
+	assert.Len(t, th.events, 1)
+	assert.Len(t, fd.changes, 4)
+
+	// The threshold clears once the changes leave the window.
+	fd.check(testNow.Add(time.Minute+6*time.Second), "")
+	assert.True(t, fd.Flapping())
+	fd.check(testNow.Add(time.Minute+7*time.Second), "")
+	assert.False(t, fd.Flapping())
+	assert.Equal(t, []recordedFlap{{flapTripped, 4}, {flapCleared, 3}}, th.events)
+}
+
+func TestFlapDetectorSlowChanges(t *testing.T) {
+	th := &testFlapHook{}
+	fd := &flapDetector{threshold: 2, window: time.Minute, hooks: []flapHook{th}}
+
+	// Changes that are more than a window apart never trip it.
+	for i := 0; i < 10; i++ {
+		fd.record(testNow.Add(time.Duration(i)*31*time.Second), false, "")
+	}
+	assert.False(t, fd.Flapping())
+	assert.Empty(t, th.events)
+}
+
+func TestFlapDetectorOperator(t *testing.T) {
+	th := &testFlapHook{}
+	fd := &flapDetector{threshold: 1, window: time.Minute, hooks: []flapHook{th}}
+	fd.record(testNow, true, "")
+	fd.record(testNow, true, "")
+	assert.True(t, fd.Flapping())
+
+	th = &testFlapHook{}
+	fd = &flapDetector{threshold: 1, window: time.Minute, ignoreOperator: true, hooks: []flapHook{th}}
+	fd.record(testNow, true, "")
+	fd.record(testNow, true, "")
+	fd.record(testNow, false, "")
+	assert.False(t, fd.Flapping())
+	fd.record(testNow, false, "")
+	assert.True(t, fd.Flapping())
+	assert.Equal(t, []recordedFlap{{flapTripped, 2}}, th.events)
+
+	// A zero threshold disables the detection.
+	fd = &flapDetector{window: time.Minute, hooks: []flapHook{th}}
+	for i := 0; i < 10; i++ {
+		fd.record(testNow, false, "")
+	}
+	assert.False(t, fd.Flapping())
+	assert.Empty(t, fd.changes)
+}
+
+func TestExecFlapHook(t *testing.T) {
+	defer func(saved func(*hook.Hook) *hook.HookResult) { runFlapHook = saved }(runFlapHook)
+	hooks := make(chan *hook.Hook, 1)
+	runFlapHook = func(h *hook.Hook) *hook.HookResult {
+		hooks <- h
+		return &hook.HookResult{ExitStatus: hook.HOOK_SUCCESS}
+	}
+
+	execFlapHook{name: "serving_flap"}.flapChanged(flapTripped, 4, time.Minute, "REPLICA: Serving")
+	h := <-hooks
+	assert.Equal(t, "serving_flap", h.Name)
+	assert.Equal(t, map[string]string{
+		"FLAP_EVENT":   "tripped",
+		"FLAP_CHANGES": "4",
+		"FLAP_WINDOW":  "1m0s",
+		"TABLET_STATE": "REPLICA: Serving",
+	}, h.ExtraEnv)
+}
+
+func TestStateManagerFlaps(t *testing.T) {
+	for _, ignoreOperator := range []bool{false, true} {
+		t.Run(fmt.Sprintf("ignoreOperator=%v", ignoreOperator), func(t *testing.T) {
+			sm := newTestStateManager(t)
+			defer sm.StopService()
+			th := &testFlapHook{}
+			sm.mu.Lock()
+			sm.flaps.threshold = 2
+			sm.flaps.window = time.Hour
+			sm.flaps.ignoreOperator = ignoreOperator
+			sm.flaps.hooks = []flapHook{th}
+			sm.mu.Unlock()
+
+			for _, state := range []servingState{StateServing, StateNotServing, StateServing} {
+				err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, state, "")
+				require.NoError(t, err)
+			}
+			sm.mu.Lock()
+			flapping := sm.flaps.Flapping()
+			sm.mu.Unlock()
+			assert.Equal(t, !ignoreOperator, flapping)
+
+			// The changes caused by mysql failures are always counted.
+			sm.handleMySQLError(context.Background(), fmt.Errorf("mysql down"))
+			sm.mu.Lock()
+			defer sm.mu.Unlock()
+			if ignoreOperator {
+				assert.False(t, sm.flaps.Flapping())
+				assert.Len(t, sm.flaps.changes, 1)
+				return
+			}
+			assert.True(t, sm.flaps.Flapping())
+			assert.Equal(t, []recordedFlap{{flapTripped, 3}}, th.events)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"net/http"
+	"regexp"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// The limits of the health annotations. They're sent with every
+// health broadcast, to every subscriber: they must stay small.
+const (
+	maxHealthAnnotationKeyLen = 63
+	maxHealthAnnotationsBytes = 4096
+)
+
+// healthAnnotationKeyRE is the format of the annotation keys: lower
+// case alphanumeric words separated by '_', '-' or '.'.
+var healthAnnotationKeyRE = regexp.MustCompile(`^[a-z0-9]+([_.-][a-z0-9]+)*$`)
+
+// SetHealthAnnotation sets an annotation that's reported in the
+// health stream until it's deleted. It's broadcast right away. The
+// annotations are kept across the state transitions, but are cleared
+// by StopService.
+func (sm *stateManager) SetHealthAnnotation(key, value string) error {
+	if len(key) > maxHealthAnnotationKeyLen || !healthAnnotationKeyRE.MatchString(key) {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid health annotation key %q: it must be at most %d lower case alphanumeric characters, with words separated by '_', '-' or '.'", key, maxHealthAnnotationKeyLen)
+	}
+	if key == snapshotExportAnnotation {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid health annotation key %q: it's reserved for the snapshot exports", key)
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	size := len(key) + len(value)
+	for k, v := range sm.annotations {
+		if k != key {
+			size += len(k) + len(v)
+		}
+	}
+	if size > maxHealthAnnotationsBytes {
+		return vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "cannot set health annotation %s: the annotations would take %d bytes, more than the limit of %d", key, size, maxHealthAnnotationsBytes)
+	}
+	if sm.annotations == nil {
+		sm.annotations = make(map[string]string)
+	}
+	sm.annotations[key] = value
+	log.Infof("Health annotation set: %s=%s", key, value)
+	sm.updateAnnotationsLocked()
+	return nil
+}
+
+// DeleteHealthAnnotation deletes an annotation set by
+// SetHealthAnnotation, if it's set.
+func (sm *stateManager) DeleteHealthAnnotation(key string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if _, ok := sm.annotations[key]; !ok {
+		return
+	}
+	delete(sm.annotations, key)
+	log.Infof("Health annotation deleted: %s", key)
+	sm.updateAnnotationsLocked()
+}
+
+// HealthAnnotations returns a copy of the annotations.
+func (sm *stateManager) HealthAnnotations() map[string]string {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return copyAnnotations(sm.annotations)
+}
+
+// updateAnnotationsLocked hands the annotations to hs, and broadcasts
+// them. The broadcast is skipped until the serving type is first set.
+func (sm *stateManager) updateAnnotationsLocked() {
+	sm.hs.SetAnnotations(sm.reportedAnnotationsLocked())
+	if sm.initialized {
+		sm.broadcastLocked()
+	}
+}
+
+// reportedAnnotationsLocked returns the annotations reported in the
+// health stream: those set by SetHealthAnnotation, and the reason of
+// the snapshot export in progress.
+func (sm *stateManager) reportedAnnotationsLocked() map[string]string {
+	annotations := copyAnnotations(sm.annotations)
+	if sm.export != nil {
+		if annotations == nil {
+			annotations = make(map[string]string, 1)
+		}
+		annotations[snapshotExportAnnotation] = sm.export.Reason
+	}
+	return annotations
+}
+
+// clearHealthAnnotations deletes all the annotations. They're no
+// longer reported from the next broadcast on.
+func (sm *stateManager) clearHealthAnnotations() {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.annotations = nil
+	sm.hs.SetAnnotations(sm.reportedAnnotationsLocked())
+}
+
+func copyAnnotations(annotations map[string]string) map[string]string {
+	if len(annotations) == 0 {
+		return nil
+	}
+	c := make(map[string]string, len(annotations))
+	for k, v := range annotations {
+		c[k] = v
+	}
+	return c
+}
+
+// SetHealthAnnotation sets an annotation reported in the health
+// stream. See stateManager.SetHealthAnnotation.
+func (tsv *TabletServer) SetHealthAnnotation(key, value string) error {
+	return tsv.sm.SetHealthAnnotation(key, value)
+}
+
+// DeleteHealthAnnotation deletes an annotation
+// set by SetHealthAnnotation.
+func (tsv *TabletServer) DeleteHealthAnnotation(key string) {
+	tsv.sm.DeleteHealthAnnotation(key)
+}
+
+// registerHealthAnnotationsHandler registers an admin action that sets
+// the value form value as the annotation of the key form value, or
+// deletes the annotation if delete is true.
+func (tsv *TabletServer) registerHealthAnnotationsHandler() {
+	tsv.exporter.HandleFunc("/debug/health/annotations", func(w http.ResponseWriter, r *http.Request) {
+		healthAnnotationsHandler(w, r, tsv.sm)
+	})
+}
+
+func healthAnnotationsHandler(w http.ResponseWriter, r *http.Request, sm *stateManager) {
+	adminActionHandler(w, r, func() error {
+		key := r.FormValue("key")
+		if r.FormValue("delete") == "true" {
+			sm.DeleteHealthAnnotation(key)
+			return nil
+		}
+		return sm.SetHealthAnnotation(key, r.FormValue("value"))
+	})
+}
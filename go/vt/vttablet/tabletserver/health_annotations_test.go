@@ -0,0 +1,148 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// nextAnnotations reads ch until a message whose
+// annotations satisfy match arrives.
+func nextAnnotations(ch <-chan *querypb.StreamHealthResponse, match func(map[string]string) bool) *querypb.StreamHealthResponse {
+	for {
+		shr := <-ch
+		if match(shr.RealtimeStats.Annotations) {
+			return shr
+		}
+	}
+}
+
+func TestStateManagerHealthAnnotations(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	ch, cancel := testStream(sm.hs)
+	defer cancel()
+	<-ch
+
+	// The annotations are broadcast right away.
+	require.NoError(t, sm.SetHealthAnnotation("cache_warm", "true"))
+	require.NoError(t, sm.SetHealthAnnotation("canary", "v2"))
+	want := map[string]string{"cache_warm": "true", "canary": "v2"}
+	shr := nextAnnotations(ch, func(a map[string]string) bool { return len(a) == 2 })
+	assert.Equal(t, want, shr.RealtimeStats.Annotations)
+	assert.Equal(t, want, sm.HealthAnnotations())
+
+	// They survive the transitions.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_RDONLY, testNow, StateServing, "")
+	require.NoError(t, err)
+	shr = nextAnnotations(ch, func(map[string]string) bool { return true })
+	for shr.Target.TabletType != topodatapb.TabletType_RDONLY {
+		shr = <-ch
+	}
+	assert.Equal(t, want, shr.RealtimeStats.Annotations)
+
+	sm.DeleteHealthAnnotation("canary")
+	sm.DeleteHealthAnnotation("unknown")
+	shr = nextAnnotations(ch, func(a map[string]string) bool { return len(a) == 1 })
+	assert.Equal(t, map[string]string{"cache_warm": "true"}, shr.RealtimeStats.Annotations)
+
+	// They're cleared by StopService.
+	sm.StopService()
+	assert.Empty(t, sm.HealthAnnotations())
+	sm.hs.mu.Lock()
+	assert.Nil(t, sm.hs.state.RealtimeStats.Annotations)
+	sm.hs.mu.Unlock()
+}
+
+func TestStateManagerHealthAnnotationsLimits(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+
+	for _, key := range []string{"", "Canary", "cache warm", "_canary", "canary-", strings.Repeat("a", maxHealthAnnotationKeyLen+1)} {
+		err := sm.SetHealthAnnotation(key, "v")
+		assert.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err), key)
+	}
+	for _, key := range []string{"a", "cache_warm", "app.v2-canary", strings.Repeat("a", maxHealthAnnotationKeyLen)} {
+		assert.NoError(t, sm.SetHealthAnnotation(key, "v"), key)
+	}
+
+	// The size of a replaced value doesn't count.
+	sm.clearHealthAnnotations()
+	big := strings.Repeat("v", maxHealthAnnotationsBytes-len("big"))
+	require.NoError(t, sm.SetHealthAnnotation("big", big))
+	require.NoError(t, sm.SetHealthAnnotation("big", big))
+	err := sm.SetHealthAnnotation("a", "")
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	assert.EqualError(t, err, "cannot set health annotation a: the annotations would take 4097 bytes, more than the limit of 4096")
+	sm.DeleteHealthAnnotation("big")
+	assert.NoError(t, sm.SetHealthAnnotation("a", ""))
+}
+
+func TestHealthAnnotationsHandler(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+
+	testcases := []struct {
+		query string
+		code  int
+		body  string
+		want  map[string]string
+	}{{
+		query: "key=canary&value=v2",
+		code:  http.StatusOK,
+		body:  "ok",
+		want:  map[string]string{"canary": "v2"},
+	}, {
+		query: "key=Canary&value=v3",
+		code:  http.StatusServiceUnavailable,
+		body:  `invalid health annotation key "Canary"`,
+		want:  map[string]string{"canary": "v2"},
+	}, {
+		query: "key=canary&delete=true",
+		code:  http.StatusOK,
+		body:  "ok",
+		want:  map[string]string{},
+	}}
+	for _, tcase := range testcases {
+		request, _ := http.NewRequest("POST", "/debug/health/annotations?"+tcase.query, nil)
+		response := httptest.NewRecorder()
+		healthAnnotationsHandler(response, request, sm)
+		assert.Equal(t, tcase.code, response.Code, tcase.query)
+		assert.Contains(t, response.Body.String(), tcase.body, tcase.query)
+		annotations := sm.HealthAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		assert.Equal(t, tcase.want, annotations, tcase.query)
+	}
+}
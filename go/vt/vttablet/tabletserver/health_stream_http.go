@@ -0,0 +1,128 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/json2"
+	"vitess.io/vitess/go/vt/log"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// healthStreamWriteTimeout bounds the write of a message to a
+// browser. A browser that doesn't read its messages ends its stream
+// instead of holding up the subscription.
+var healthStreamWriteTimeout = 10 * time.Second
+
+var healthStreamUpgrader = websocket.Upgrader{}
+
+// registerHealthStreamHandler registers /debug/health/stream, which
+// streams the health of the tablet to browsers. See streamHealthHTTP.
+func (tsv *TabletServer) registerHealthStreamHandler() {
+	tsv.exporter.HandleFunc("/debug/health/stream", func(w http.ResponseWriter, r *http.Request) {
+		if err := acl.CheckAccessHTTP(r, acl.MONITORING); err != nil {
+			acl.SendError(w, err)
+			return
+		}
+		streamHealthHTTP(w, r, tsv.hs)
+	})
+}
+
+// streamHealthHTTP sends each StreamHealthResponse of hs as JSON, the
+// current one first. The request is upgraded to a websocket if it asks
+// for it, and answered with server-sent events otherwise. If the
+// serving_changes_only parameter is true, only the changes of the
+// serving state are sent, along with heartbeats.
+func streamHealthHTTP(w http.ResponseWriter, r *http.Request, hs *healthStreamer) {
+	opts := streamOptions{ServingChangesOnly: r.FormValue("serving_changes_only") == "true"}
+	if websocket.IsWebSocketUpgrade(r) {
+		streamHealthWebSocket(w, r, hs, opts)
+		return
+	}
+	streamHealthEvents(w, r, hs, opts)
+}
+
+func streamHealthWebSocket(w http.ResponseWriter, r *http.Request, hs *healthStreamer, opts streamOptions) {
+	conn, err := healthStreamUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already replied.
+		log.Warningf("Health stream websocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// The context of a hijacked request isn't canceled when the
+	// browser goes away: the reads tell, and stop once conn is closed.
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	err = hs.StreamWithOptions(ctx, opts, func(shr *querypb.StreamHealthResponse) error {
+		data, err := json2.MarshalPB(shr)
+		if err != nil {
+			return err
+		}
+		conn.SetWriteDeadline(time.Now().Add(healthStreamWriteTimeout))
+		return conn.WriteMessage(websocket.TextMessage, data)
+	})
+	if err != nil {
+		msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, err.Error())
+		conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(healthStreamWriteTimeout))
+	}
+}
+
+func streamHealthEvents(w http.ResponseWriter, r *http.Request, hs *healthStreamer, opts streamOptions) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	// The context of the request is canceled when the browser goes away.
+	err := hs.StreamWithOptions(r.Context(), opts, func(shr *querypb.StreamHealthResponse) error {
+		data, err := json2.MarshalPB(shr)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(w, "event: error\ndata: %v\n\n", err)
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func newHTTPHealthStreamer(t *testing.T) (*healthStreamer, *httptest.Server) {
+	env := tabletenv.NewEnv(tabletenv.NewDefaultConfig(), "HealthStreamHTTPTest")
+	blpFunc = testBlpFunc
+	hs := newHealthStreamer(env, topodatapb.TabletAlias{Cell: "cell", Uid: 1})
+	hs.InitDBConfig(querypb.Target{})
+	hs.Open()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		streamHealthHTTP(w, r, hs)
+	}))
+	return hs, server
+}
+
+func healthClients(hs *healthStreamer) int {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	return len(hs.clients)
+}
+
+func TestStreamHealthWebSocket(t *testing.T) {
+	hs, server := newHTTPHealthStreamer(t)
+	defer server.Close()
+	defer hs.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+
+	// The current state comes first.
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	shr := &querypb.StreamHealthResponse{}
+	require.NoError(t, jsonpb.UnmarshalString(string(data), shr))
+	assert.Equal(t, "tabletserver uninitialized", shr.RealtimeStats.HealthError)
+	assert.Equal(t, 1, healthClients(hs))
+
+	hs.ChangeState(healthState{tabletType: topodatapb.TabletType_REPLICA, serving: true})
+	_, data, err = conn.ReadMessage()
+	require.NoError(t, err)
+	shr = &querypb.StreamHealthResponse{}
+	require.NoError(t, jsonpb.UnmarshalString(string(data), shr))
+	assert.True(t, shr.Serving)
+	assert.Equal(t, topodatapb.TabletType_REPLICA, shr.Target.TabletType)
+
+	// An abrupt close, without a close message, ends the subscription
+	// even if the tablet has nothing to send.
+	conn.UnderlyingConn().Close()
+	assert.Eventually(t, func() bool {
+		return healthClients(hs) == 0
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestStreamHealthWebSocketShutdown(t *testing.T) {
+	hs, server := newHTTPHealthStreamer(t)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, _, err = conn.ReadMessage()
+	require.NoError(t, err)
+
+	// The browser is told why the stream ended.
+	hs.Close()
+	_, _, err = conn.ReadMessage()
+	require.Error(t, err)
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "%v", err)
+	assert.Contains(t, err.Error(), "tabletserver is shutdown")
+}
+
+func TestStreamHealthEvents(t *testing.T) {
+	hs, server := newHTTPHealthStreamer(t)
+	defer server.Close()
+	defer hs.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(line, "data: "), line)
+	shr := &querypb.StreamHealthResponse{}
+	require.NoError(t, jsonpb.UnmarshalString(strings.TrimPrefix(line, "data: "), shr))
+	assert.Equal(t, "tabletserver uninitialized", shr.RealtimeStats.HealthError)
+	assert.Equal(t, 1, healthClients(hs))
+
+	resp.Body.Close()
+	assert.Eventually(t, func() bool {
+		return healthClients(hs) == 0
+	}, 5*time.Second, time.Millisecond)
+}
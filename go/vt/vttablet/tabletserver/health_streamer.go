@@ -17,51 +17,94 @@ limitations under the License.
 package tabletserver
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/golang/protobuf/proto"
 	"golang.org/x/net/context"
 	"vitess.io/vitess/go/history"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vterrors"
-	"vitess.io/vitess/go/vt/vttablet/tabletmanager/vreplication"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/repltracker"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 )
 
 var (
-	// blpFunc is a legaacy feature.
-	// TODO(sougou): remove after legacy resharding worflows are removed.
-	blpFunc = vreplication.StatusSummary
-
 	errUnintialized = "tabletserver uninitialized"
+	// errInitializing is the health error broadcast until
+	// the serving type is first set.
+	errInitializing = errors.New("initializing")
+)
+
+// poolUsage contains the utilization of the connection pools
+// sampled at the time of a broadcast.
+type poolUsage struct {
+	queryInUse, queryCapacity int64
+	txInUse, txCapacity       int64
+}
+
+// The estimated sizes of the health streamer buffers.
+const (
+	healthRecordBytes   = 512
+	healthResponseBytes = 256
 )
 
 // healthStreamer streams health information to callers.
 type healthStreamer struct {
-	stats              *tabletenv.Stats
-	degradedThreshold  time.Duration
-	unhealthyThreshold time.Duration
+	stats *tabletenv.Stats
+	// live provides the lag thresholds of the status page.
+	live *liveConfig
 
 	mu      sync.Mutex
 	ctx     context.Context
 	cancel  context.CancelFunc
-	clients map[chan *querypb.StreamHealthResponse]struct{}
+	clients map[chan *querypb.StreamHealthResponse]*healthSubscriber
 	state   *querypb.StreamHealthResponse
 
 	history *history.History
+	// historyMem and clientsMem account the memory used by the
+	// history and by the queues of the subscribers. The history
+	// is shrunk if the state buffers exceed their cap.
+	historyMem *memoryAccount
+	clientsMem *memoryAccount
+	// transitionOps are the operations of the last transition.
+	// They're attached to the next history record.
+	transitionOps []transitionOp
+
+	// alias and initTarget are the identity hs was created and
+	// initialized with, and managerTarget the target of the state
+	// manager at its last broadcast. Every response is checked
+	// against them. See verifyIdentityLocked.
+	alias              topodatapb.TabletAlias
+	initTarget         querypb.Target
+	managerTarget      *querypb.Target
+	identityMismatches *stats.Counter
+	mismatchFatal      bool
+
+	// maxClients caps the number of subscribers, if set. A
+	// subscriber is evicted if none of the last idleBroadcasts
+	// broadcasts could be queued for it, if set.
+	maxClients      int
+	idleBroadcasts  int
+	clientsRejected *stats.Counter
+	clientsEvicted  *stats.Counter
 }
 
 func newHealthStreamer(env tabletenv.Env, alias topodatapb.TabletAlias) *healthStreamer {
-	return &healthStreamer{
-		stats:              env.Stats(),
-		degradedThreshold:  env.Config().Healthcheck.DegradedThresholdSeconds.Get(),
-		unhealthyThreshold: env.Config().Healthcheck.UnhealthyThresholdSeconds.Get(),
-		clients:            make(map[chan *querypb.StreamHealthResponse]struct{}),
+	hs := &healthStreamer{
+		stats:   env.Stats(),
+		live:    newLiveConfig(env.Config()),
+		clients: make(map[chan *querypb.StreamHealthResponse]*healthSubscriber),
 
 		state: &querypb.StreamHealthResponse{
 			Target:      &querypb.Target{},
@@ -72,7 +115,25 @@ func newHealthStreamer(env tabletenv.Env, alias topodatapb.TabletAlias) *healthS
 		},
 
 		history: history.New(5),
+
+		alias:              alias,
+		identityMismatches: env.Exporter().NewCounter("HealthIdentityMismatches", "Count of health responses whose tablet alias, keyspace, shard or cell differed from the ones the tablet was initialized with"),
+		mismatchFatal:      env.Config().HealthIdentityMismatchFatal,
+
+		maxClients:      env.Config().Healthcheck.MaxStreamSubscribers,
+		idleBroadcasts:  env.Config().Healthcheck.StreamIdleBroadcasts,
+		clientsRejected: env.Exporter().NewCounter("HealthStreamSubscribersRejected", "Count of health streams rejected because the tablet had too many subscribers"),
+		clientsEvicted:  env.Exporter().NewCounter("HealthStreamSubscribersEvicted", "Count of health streams closed because their subscriber stopped receiving the broadcasts"),
 	}
+	env.Exporter().NewGaugeFunc("HealthStreamSubscribers", "Number of active health stream subscribers", hs.clientCount)
+	return hs
+}
+
+// clientCount returns the number of subscribers.
+func (hs *healthStreamer) clientCount() int64 {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	return int64(len(hs.clients))
 }
 
 func (hs *healthStreamer) InitDBConfig(target querypb.Target) {
@@ -80,6 +141,82 @@ func (hs *healthStreamer) InitDBConfig(target querypb.Target) {
 	// a separate variable.
 	inner := target
 	hs.state.Target = &inner
+	hs.initTarget = target
+}
+
+// setManagerTarget records the target of the state manager,
+// which the following responses are checked against.
+func (hs *healthStreamer) setManagerTarget(target querypb.Target) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.managerTarget = &target
+}
+
+// verifyIdentityLocked checks that shr advertises the tablet alias of
+// hs, and the keyspace, shard and cell it was initialized with, which
+// must also be the ones of the state manager. A mismatch would make
+// the vtgates merge the tablet with another one: it's counted and
+// logged, and shr is sent as not serving, with the mismatch as its
+// health error. If mismatchFatal is set, it panics instead.
+func (hs *healthStreamer) verifyIdentityLocked(shr *querypb.StreamHealthResponse) {
+	if shr.TabletAlias == nil {
+		alias := hs.alias
+		shr.TabletAlias = &alias
+	}
+	err := hs.identityMismatch(shr)
+	if err == nil {
+		return
+	}
+	hs.identityMismatches.Add(1)
+	log.Errorf("Health stream identity mismatch: %v", err)
+	if hs.mismatchFatal {
+		panic(err)
+	}
+	shr.Serving = false
+	shr.AcceptedTabletTypes = nil
+	shr.RealtimeStats.HealthError = err.Error()
+}
+
+func (hs *healthStreamer) identityMismatch(shr *querypb.StreamHealthResponse) error {
+	if !proto.Equal(shr.TabletAlias, &hs.alias) {
+		return fmt.Errorf("health stream advertises tablet %v, but the tablet is %v", topoproto.TabletAliasString(shr.TabletAlias), topoproto.TabletAliasString(&hs.alias))
+	}
+	if err := targetMismatch(shr.GetTarget(), &hs.initTarget, "InitDBConfig"); err != nil {
+		return err
+	}
+	if hs.managerTarget != nil {
+		return targetMismatch(shr.GetTarget(), hs.managerTarget, "the state manager")
+	}
+	return nil
+}
+
+// targetMismatch returns an error if target and want
+// differ in their keyspace, shard or cell.
+func targetMismatch(target, want *querypb.Target, source string) error {
+	if target.GetKeyspace() == want.Keyspace && target.GetShard() == want.Shard && target.GetCell() == want.Cell {
+		return nil
+	}
+	return fmt.Errorf("health stream advertises %s/%s in cell %q, but %s has %s/%s in cell %q", target.GetKeyspace(), target.GetShard(), target.GetCell(), source, want.Keyspace, want.Shard, want.Cell)
+}
+
+// registerMemory adds the buffers of hs to ma.
+func (hs *healthStreamer) registerMemory(ma *memoryAccounting) {
+	historyMem := ma.register("healthHistory", historyPriority, func(maxBytes int64) int64 {
+		if length := int(maxBytes / healthRecordBytes); length < hs.history.Cap() {
+			if length < 1 {
+				length = 1
+			}
+			hs.history.Resize(length)
+		}
+		return int64(hs.history.Cap()) * healthRecordBytes
+	})
+	clientsMem := ma.register("healthSubscribers", 0, nil)
+
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.historyMem, hs.clientsMem = historyMem, clientsMem
+	hs.historyMem.Set(int64(hs.history.Cap()) * healthRecordBytes)
+	hs.clientsMem.Set(int64(len(hs.clients)) * healthResponseBytes)
 }
 
 func (hs *healthStreamer) Open() {
@@ -102,44 +239,147 @@ func (hs *healthStreamer) Close() {
 	}
 }
 
+// defaultStreamHeartbeat is the heartbeat interval of the filtered
+// streams that don't set one.
+const defaultStreamHeartbeat = 1 * time.Minute
+
+// streamOptions changes which messages a health stream delivers.
+// The zero value delivers every message.
+type streamOptions struct {
+	// ServingChangesOnly suppresses the messages where Serving, the
+	// tablet type and the health error are the same as in the last
+	// message delivered to the subscriber.
+	ServingChangesOnly bool
+	// Heartbeat is the maximum interval between two messages of a
+	// filtered stream. If nothing was delivered for that long, the
+	// current state is sent anyway, so that the subscriber can tell
+	// a quiet tablet from a dead stream. It defaults to
+	// defaultStreamHeartbeat.
+	Heartbeat time.Duration
+}
+
+// healthSubscriber is the state kept by hs for each stream.
+type healthSubscriber struct {
+	ch   chan *querypb.StreamHealthResponse
+	opts streamOptions
+	// missed is the number of consecutive broadcasts that couldn't
+	// be queued because the subscriber didn't consume the previous
+	// message. evicted is closed if hs evicts the subscriber.
+	missed  int
+	evicted chan struct{}
+	// last is the last message queued for a filtered stream.
+	// Filtering at queue time rather than at delivery time ensures
+	// that suppressed messages never take the place of a change in
+	// the queue.
+	last *querypb.StreamHealthResponse
+}
+
 func (hs *healthStreamer) Stream(ctx context.Context, callback func(*querypb.StreamHealthResponse) error) error {
-	ch, hsCtx := hs.register()
-	if hsCtx == nil {
-		return vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "tabletserver is shutdown")
+	return hs.StreamWithOptions(ctx, streamOptions{}, callback)
+}
+
+// StreamWithOptions is like Stream, but filters the messages as
+// requested by opts.
+func (hs *healthStreamer) StreamWithOptions(ctx context.Context, opts streamOptions, callback func(*querypb.StreamHealthResponse) error) error {
+	sub, hsCtx, err := hs.register(opts)
+	if err != nil {
+		return err
+	}
+	defer hs.unregister(sub.ch)
+
+	var heartbeat *time.Timer
+	var heartbeats <-chan time.Time
+	if opts.ServingChangesOnly {
+		if opts.Heartbeat <= 0 {
+			opts.Heartbeat = defaultStreamHeartbeat
+		}
+		heartbeat = time.NewTimer(opts.Heartbeat)
+		defer heartbeat.Stop()
+		heartbeats = heartbeat.C
 	}
-	defer hs.unregister(ch)
 
 	for {
+		var shr *querypb.StreamHealthResponse
 		select {
 		case <-ctx.Done():
 			return nil
 		case <-hsCtx.Done():
 			return vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "tabletserver is shutdown")
-		case shr := <-ch:
-			if err := callback(shr); err != nil {
-				if err == io.EOF {
-					return nil
+		case <-sub.evicted:
+			return vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "health stream closed: none of the last %d health broadcasts could be delivered", hs.idleBroadcasts)
+		case shr = <-sub.ch:
+		case <-heartbeats:
+			shr = hs.heartbeat(sub.ch)
+		}
+		if heartbeat != nil {
+			if !heartbeat.Stop() {
+				select {
+				case <-heartbeat.C:
+				default:
 				}
-				return err
 			}
+			heartbeat.Reset(opts.Heartbeat)
+		}
+		if err := callback(shr); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
 		}
 	}
 }
 
-func (hs *healthStreamer) register() (chan *querypb.StreamHealthResponse, context.Context) {
+// servingChanged returns true if shr differs from last in any of the
+// fields watched by the streams that filter on serving changes.
+func servingChanged(last, shr *querypb.StreamHealthResponse) bool {
+	return shr.Serving != last.Serving ||
+		shr.GetTarget().GetTabletType() != last.GetTarget().GetTabletType() ||
+		shr.GetRealtimeStats().GetHealthError() != last.GetRealtimeStats().GetHealthError()
+}
+
+// heartbeat returns a copy of the current state for the filtered
+// stream of ch, and records it as the last message of the stream.
+func (hs *healthStreamer) heartbeat(ch chan *querypb.StreamHealthResponse) *querypb.StreamHealthResponse {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	shr := proto.Clone(hs.state).(*querypb.StreamHealthResponse)
+	hs.verifyIdentityLocked(shr)
+	if sub, ok := hs.clients[ch]; ok {
+		sub.last = shr
+	}
+	return shr
+}
+
+// register adds a subscriber with opts. It fails if hs is closed,
+// or if it already has maxClients subscribers.
+func (hs *healthStreamer) register(opts streamOptions) (*healthSubscriber, context.Context, error) {
 	hs.mu.Lock()
 	defer hs.mu.Unlock()
 
 	if hs.cancel == nil {
-		return nil, nil
+		return nil, nil, vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "tabletserver is shutdown")
+	}
+	if hs.maxClients > 0 && len(hs.clients) >= hs.maxClients {
+		hs.clientsRejected.Add(1)
+		return nil, nil, vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "too many health stream subscribers: the limit is %d", hs.maxClients)
 	}
 
-	ch := make(chan *querypb.StreamHealthResponse, 1)
-	hs.clients[ch] = struct{}{}
+	sub := &healthSubscriber{
+		ch:      make(chan *querypb.StreamHealthResponse, 1),
+		opts:    opts,
+		evicted: make(chan struct{}),
+	}
+	hs.clients[sub.ch] = sub
+	hs.clientsMem.Set(int64(len(hs.clients)) * healthResponseBytes)
 
 	// Send the current state immediately.
-	ch <- proto.Clone(hs.state).(*querypb.StreamHealthResponse)
-	return ch, hs.ctx
+	shr := proto.Clone(hs.state).(*querypb.StreamHealthResponse)
+	hs.verifyIdentityLocked(shr)
+	sub.ch <- shr
+	if opts.ServingChangesOnly {
+		sub.last = shr
+	}
+	return sub, hs.ctx, nil
 }
 
 func (hs *healthStreamer) unregister(ch chan *querypb.StreamHealthResponse) {
@@ -147,44 +387,205 @@ func (hs *healthStreamer) unregister(ch chan *querypb.StreamHealthResponse) {
 	defer hs.mu.Unlock()
 
 	delete(hs.clients, ch)
+	hs.clientsMem.Set(int64(len(hs.clients)) * healthResponseBytes)
+}
+
+// healthState is the snapshot of the health of the state
+// manager that ChangeState broadcasts.
+type healthState struct {
+	tabletType   topodatapb.TabletType
+	terTimestamp time.Time
+	lag          time.Duration
+	// err is the health error, if any.
+	err     error
+	serving bool
+	pu      poolUsage
+	// notConnected tells why the tablet is not connected to mysql,
+	// and reason why it was last asked to change state, if set.
+	notConnected string
+	reason       string
+	alsoAllow    []topodatapb.TabletType
+	// transitionStatus describes a partial service, if any.
+	transitionStatus string
+	// roleConfidence is only set for a serving master.
+	roleConfidence *roleConfidence
 }
 
-func (hs *healthStreamer) ChangeState(tabletType topodatapb.TabletType, terTimestamp time.Time, lag time.Duration, err error, serving bool) {
+// ChangeState broadcasts st, and records it in the history.
+func (hs *healthStreamer) ChangeState(st healthState) {
 	hs.mu.Lock()
 	defer hs.mu.Unlock()
 
-	hs.state.Target.TabletType = tabletType
-	if tabletType == topodatapb.TabletType_MASTER {
-		hs.state.TabletExternallyReparentedTimestamp = terTimestamp.Unix()
+	hs.state.Target.TabletType = st.tabletType
+	if st.tabletType == topodatapb.TabletType_MASTER {
+		hs.state.TabletExternallyReparentedTimestamp = st.terTimestamp.Unix()
 	} else {
 		hs.state.TabletExternallyReparentedTimestamp = 0
 	}
-	if err != nil {
-		hs.state.RealtimeStats.HealthError = err.Error()
-	} else {
+	switch {
+	case st.err != nil:
+		hs.state.RealtimeStats.HealthError = st.err.Error()
+	case !st.serving && st.reason != "":
+		hs.state.RealtimeStats.HealthError = fmt.Sprintf("not serving: %s", st.reason)
+	default:
 		hs.state.RealtimeStats.HealthError = ""
 	}
-	hs.state.RealtimeStats.SecondsBehindMaster = uint32(lag.Seconds())
-	hs.state.Serving = serving
+	hs.state.RealtimeStats.SecondsBehindMaster = uint32(st.lag.Seconds())
+	hs.state.Serving = st.serving
+	hs.state.AlsoAllow = st.alsoAllow
+	hs.state.AcceptedTabletTypes = acceptedTabletTypes(st.tabletType, st.alsoAllow, st.serving)
 
-	hs.state.RealtimeStats.SecondsBehindMasterFilteredReplication, hs.state.RealtimeStats.BinlogPlayersCount = blpFunc()
 	hs.state.RealtimeStats.Qps = hs.stats.QPSRates.TotalRate()
+	hs.state.RealtimeStats.QueryPoolInUse = st.pu.queryInUse
+	hs.state.RealtimeStats.QueryPoolCapacity = st.pu.queryCapacity
+	hs.state.RealtimeStats.TransactionPoolInUse = st.pu.txInUse
+	hs.state.RealtimeStats.TransactionPoolCapacity = st.pu.txCapacity
+	hs.state.RealtimeStats.TransitionStatus = st.transitionStatus
+	hs.state.RealtimeStats.RoleConfidence, hs.state.RealtimeStats.RoleDegraded = 0, false
+	if rc := st.roleConfidence; rc != nil {
+		hs.state.RealtimeStats.RoleConfidence = uint32(rc.Confidence)
+		hs.state.RealtimeStats.RoleDegraded = rc.Degraded
+	}
 
 	shr := proto.Clone(hs.state).(*querypb.StreamHealthResponse)
 
-	for ch := range hs.clients {
+	hs.broadcastLocked(shr)
+	hs.history.Add(&historyRecord{
+		Time:         time.Now(),
+		serving:      shr.Serving,
+		tabletType:   shr.Target.TabletType,
+		lag:          st.lag,
+		err:          st.err,
+		notConnected: st.notConnected,
+		reason:       st.reason,
+		transition:   hs.transitionOps,
+	})
+	hs.transitionOps = nil
+}
+
+// SetLagSource updates the source of the replication lag
+// reported by the next broadcast.
+func (hs *healthStreamer) SetLagSource(source repltracker.LagSource) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.state.RealtimeStats.LagSource = source.String()
+}
+
+// SetUnresolvedPrepares updates the unresolved 2pc transactions
+// reported by the next broadcast.
+func (hs *healthStreamer) SetUnresolvedPrepares(count int64, maxAge time.Duration) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.state.RealtimeStats.UnresolvedPrepares = count
+	hs.state.RealtimeStats.UnresolvedPreparesMaxAgeSeconds = uint32(maxAge.Seconds())
+}
+
+// SetWatcherStatus updates the status of the replication
+// streams reported by the next broadcast.
+func (hs *healthStreamer) SetWatcherStatus(status WatcherStatus) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.state.RealtimeStats.WatcherState = status.State
+	hs.state.RealtimeStats.WatcherSecondsBehind = status.SecondsBehind
+	hs.state.RealtimeStats.SecondsBehindMasterFilteredReplication = status.FilteredReplicationSecondsBehind
+	hs.state.RealtimeStats.BinlogPlayersCount = status.BinlogPlayersCount
+}
+
+// SetPromotion updates the promotion verdict reported
+// by the next broadcast.
+func (hs *healthStreamer) SetPromotion(promotable bool, blockers []string) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.state.RealtimeStats.Promotable = promotable
+	hs.state.RealtimeStats.PromotionBlockers = blockers
+}
+
+// SetAnnotations updates the annotations
+// reported by the next broadcast.
+func (hs *healthStreamer) SetAnnotations(annotations map[string]string) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.state.RealtimeStats.Annotations = annotations
+}
+
+// setTransitionOps saves the operations of a transition
+// to be recorded in the next history record.
+func (hs *healthStreamer) setTransitionOps(ops []transitionOp) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.transitionOps = ops
+}
+
+// recordEvent adds an entry to the history for an event
+// that is not accompanied by a state change.
+func (hs *healthStreamer) recordEvent(tabletType topodatapb.TabletType, err error) {
+	hs.history.Add(&historyRecord{
+		Time:       time.Now(),
+		tabletType: tabletType,
+		err:        err,
+	})
+}
+
+// recordInitialized adds an entry to the history for the end
+// of the initializing phase, elapsed after the process start.
+func (hs *healthStreamer) recordInitialized(tabletType topodatapb.TabletType, elapsed time.Duration) {
+	hs.history.Add(&historyRecord{
+		Time:       time.Now(),
+		tabletType: tabletType,
+		event:      fmt.Sprintf("initialized %v after the process start", elapsed.Round(time.Millisecond)),
+	})
+}
+
+// schemaChanged is registered as a schema engine notifier. It
+// immediately sends the names of the changed tables to the subscribers.
+// On registration, all known tables are reported as changed.
+func (hs *healthStreamer) schemaChanged(_ map[string]*schema.Table, created, altered, dropped []string) {
+	var tables []string
+	tables = append(tables, created...)
+	tables = append(tables, altered...)
+	tables = append(tables, dropped...)
+	if len(tables) == 0 {
+		return
+	}
+	sort.Strings(tables)
+
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	shr := proto.Clone(hs.state).(*querypb.StreamHealthResponse)
+	shr.RealtimeStats.TableSchemaChanged = tables
+	hs.broadcastLocked(shr)
+}
+
+func (hs *healthStreamer) broadcastLocked(shr *querypb.StreamHealthResponse) {
+	hs.verifyIdentityLocked(shr)
+	for ch, sub := range hs.clients {
+		if sub.opts.ServingChangesOnly && !servingChanged(sub.last, shr) {
+			continue
+		}
 		select {
 		case ch <- shr:
+			sub.missed = 0
+			if sub.opts.ServingChangesOnly {
+				sub.last = shr
+			}
 		default:
+			sub.missed++
+			if hs.idleBroadcasts > 0 && sub.missed >= hs.idleBroadcasts {
+				hs.evictLocked(sub)
+			}
 		}
 	}
-	hs.history.Add(&historyRecord{
-		Time:       time.Now(),
-		serving:    shr.Serving,
-		tabletType: shr.Target.TabletType,
-		lag:        lag,
-		err:        err,
-	})
+}
+
+// evictLocked removes sub, whose stream ends with an error. The other
+// subscribers are not affected.
+func (hs *healthStreamer) evictLocked(sub *healthSubscriber) {
+	log.Warningf("Evicting a health stream subscriber: none of the last %d health broadcasts could be delivered", sub.missed)
+	delete(hs.clients, sub.ch)
+	hs.clientsMem.Set(int64(len(hs.clients)) * healthResponseBytes)
+	close(sub.evicted)
+	hs.clientsEvicted.Add(1)
 }
 
 func (hs *healthStreamer) ApppendDetails(details []*kv) []*kv {
@@ -196,15 +597,19 @@ func (hs *healthStreamer) ApppendDetails(details []*kv) []*kv {
 	sbm := time.Duration(hs.state.RealtimeStats.SecondsBehindMaster) * time.Second
 	class := healthyClass
 	switch {
-	case sbm > hs.unhealthyThreshold:
+	case sbm > hs.live.UnhealthyThreshold():
 		class = unhealthyClass
-	case sbm > hs.degradedThreshold:
+	case sbm > hs.live.DegradedThreshold():
 		class = unhappyClass
 	}
+	value := fmt.Sprintf("%ds", hs.state.RealtimeStats.SecondsBehindMaster)
+	if source := hs.state.RealtimeStats.LagSource; source != "" {
+		value = fmt.Sprintf("%s (%s)", value, source)
+	}
 	details = append(details, &kv{
 		Key:   "Replication Lag",
 		Class: class,
-		Value: fmt.Sprintf("%ds", hs.state.RealtimeStats.SecondsBehindMaster),
+		Value: value,
 	})
 	if hs.state.RealtimeStats.HealthError != "" {
 		details = append(details, &kv{
@@ -23,8 +23,11 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 )
 
@@ -50,7 +53,6 @@ func TestHealthStreamerBroadcast(t *testing.T) {
 		Cell: "cell",
 		Uid:  1,
 	}
-	blpFunc = testBlpFunc
 	hs := newHealthStreamer(env, alias)
 	hs.Open()
 	defer hs.Close()
@@ -70,7 +72,9 @@ func TestHealthStreamerBroadcast(t *testing.T) {
 	}
 	assert.Equal(t, want, shr)
 
-	hs.ChangeState(topodatapb.TabletType_REPLICA, time.Time{}, 0, nil, false)
+	hs.SetWatcherStatus(WatcherStatus{FilteredReplicationSecondsBehind: 1, BinlogPlayersCount: 2})
+
+	hs.ChangeState(healthState{tabletType: topodatapb.TabletType_REPLICA})
 	shr = <-ch
 	want = &querypb.StreamHealthResponse{
 		Target: &querypb.Target{
@@ -84,9 +88,9 @@ func TestHealthStreamerBroadcast(t *testing.T) {
 	}
 	assert.Equal(t, want, shr)
 
-	// Test master and timestamp.
+	// Test master, timestamp and role confidence.
 	now := time.Now()
-	hs.ChangeState(topodatapb.TabletType_MASTER, now, 0, nil, true)
+	hs.ChangeState(healthState{tabletType: topodatapb.TabletType_MASTER, terTimestamp: now, serving: true, roleConfidence: &roleConfidence{Confidence: 70, Degraded: true}})
 	shr = <-ch
 	want = &querypb.StreamHealthResponse{
 		Target: &querypb.Target{
@@ -95,15 +99,18 @@ func TestHealthStreamerBroadcast(t *testing.T) {
 		TabletAlias:                         &alias,
 		Serving:                             true,
 		TabletExternallyReparentedTimestamp: now.Unix(),
+		AcceptedTabletTypes:                 []topodatapb.TabletType{topodatapb.TabletType_MASTER},
 		RealtimeStats: &querypb.RealtimeStats{
 			SecondsBehindMasterFilteredReplication: 1,
 			BinlogPlayersCount:                     2,
+			RoleConfidence:                         70,
+			RoleDegraded:                           true,
 		},
 	}
 	assert.Equal(t, want, shr)
 
 	// Test non-serving, and 0 timestamp for non-master.
-	hs.ChangeState(topodatapb.TabletType_REPLICA, now, 1*time.Second, nil, false)
+	hs.ChangeState(healthState{tabletType: topodatapb.TabletType_REPLICA, terTimestamp: now, lag: 1 * time.Second})
 	shr = <-ch
 	want = &querypb.StreamHealthResponse{
 		Target: &querypb.Target{
@@ -119,7 +126,7 @@ func TestHealthStreamerBroadcast(t *testing.T) {
 	assert.Equal(t, want, shr)
 
 	// Test Health error.
-	hs.ChangeState(topodatapb.TabletType_REPLICA, now, 0, errors.New("repl err"), false)
+	hs.ChangeState(healthState{tabletType: topodatapb.TabletType_REPLICA, terTimestamp: now, err: errors.New("repl err")})
 	shr = <-ch
 	want = &querypb.StreamHealthResponse{
 		Target: &querypb.Target{
@@ -133,6 +140,346 @@ func TestHealthStreamerBroadcast(t *testing.T) {
 		},
 	}
 	assert.Equal(t, want, shr)
+
+	// Test pool usage.
+	pu := poolUsage{
+		queryInUse:    1,
+		queryCapacity: 2,
+		txInUse:       3,
+		txCapacity:    4,
+	}
+	hs.ChangeState(healthState{tabletType: topodatapb.TabletType_REPLICA, terTimestamp: now, serving: true, pu: pu})
+	shr = <-ch
+	want = &querypb.StreamHealthResponse{
+		Target: &querypb.Target{
+			TabletType: topodatapb.TabletType_REPLICA,
+		},
+		Serving:             true,
+		TabletAlias:         &alias,
+		AcceptedTabletTypes: []topodatapb.TabletType{topodatapb.TabletType_REPLICA},
+		RealtimeStats: &querypb.RealtimeStats{
+			SecondsBehindMasterFilteredReplication: 1,
+			BinlogPlayersCount:                     2,
+			QueryPoolInUse:                         1,
+			QueryPoolCapacity:                      2,
+			TransactionPoolInUse:                   3,
+			TransactionPoolCapacity:                4,
+		},
+	}
+	assert.Equal(t, want, shr)
+
+	// Test the reason for not serving.
+	hs.ChangeState(healthState{tabletType: topodatapb.TabletType_REPLICA, terTimestamp: now, reason: "planned reparent"})
+	shr = <-ch
+	assert.Equal(t, "not serving: planned reparent", shr.RealtimeStats.HealthError)
+	assert.Equal(t, "not serving (reason: planned reparent)", hs.history.Latest().(*historyRecord).Status())
+
+	// The reason is not reported while serving.
+	hs.ChangeState(healthState{tabletType: topodatapb.TabletType_REPLICA, terTimestamp: now, serving: true, reason: "planned reparent"})
+	shr = <-ch
+	assert.Empty(t, shr.RealtimeStats.HealthError)
+}
+
+func TestHealthStreamerSchemaChanged(t *testing.T) {
+	config := tabletenv.NewDefaultConfig()
+	env := tabletenv.NewEnv(config, "ReplTrackerTest")
+	alias := topodatapb.TabletAlias{
+		Cell: "cell",
+		Uid:  1,
+	}
+	blpFunc = testBlpFunc
+	hs := newHealthStreamer(env, alias)
+	hs.Open()
+	defer hs.Close()
+	target := querypb.Target{}
+	hs.InitDBConfig(target)
+
+	ch, cancel := testStream(hs)
+	defer cancel()
+	<-ch
+
+	hs.schemaChanged(nil, []string{"t3"}, []string{"t1"}, []string{"t2"})
+	shr := <-ch
+	assert.Equal(t, []string{"t1", "t2", "t3"}, shr.RealtimeStats.TableSchemaChanged)
+
+	// No changes, no message.
+	hs.schemaChanged(nil, nil, nil, nil)
+
+	// The changes are not carried by subsequent messages.
+	hs.ChangeState(healthState{tabletType: topodatapb.TabletType_REPLICA, serving: true})
+	shr = <-ch
+	assert.Nil(t, shr.RealtimeStats.TableSchemaChanged)
+	assert.True(t, shr.Serving)
+}
+
+func TestHealthStreamerServingChangesOnly(t *testing.T) {
+	config := tabletenv.NewDefaultConfig()
+	env := tabletenv.NewEnv(config, "ReplTrackerTest")
+	alias := topodatapb.TabletAlias{
+		Cell: "cell",
+		Uid:  1,
+	}
+	blpFunc = testBlpFunc
+	hs := newHealthStreamer(env, alias)
+	hs.Open()
+	defer hs.Close()
+	hs.InitDBConfig(querypb.Target{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan *querypb.StreamHealthResponse)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = hs.StreamWithOptions(ctx, streamOptions{ServingChangesOnly: true, Heartbeat: time.Hour}, func(shr *querypb.StreamHealthResponse) error {
+			ch <- shr
+			return nil
+		})
+	}()
+	// The current state is always delivered first.
+	<-ch
+
+	hs.ChangeState(healthState{tabletType: topodatapb.TabletType_REPLICA, serving: true})
+	shr := <-ch
+	assert.True(t, shr.Serving)
+
+	// Lag and pool usage changes are suppressed.
+	hs.ChangeState(healthState{tabletType: topodatapb.TabletType_REPLICA, lag: 2 * time.Second, serving: true})
+	hs.ChangeState(healthState{tabletType: topodatapb.TabletType_REPLICA, serving: true, pu: poolUsage{txInUse: 1}})
+	hs.schemaChanged(nil, []string{"t1"}, nil, nil)
+
+	hs.ChangeState(healthState{tabletType: topodatapb.TabletType_REPLICA, err: errors.New("repl err"), serving: true})
+	shr = <-ch
+	assert.Equal(t, "repl err", shr.RealtimeStats.HealthError)
+
+	hs.ChangeState(healthState{tabletType: topodatapb.TabletType_RDONLY, err: errors.New("repl err"), serving: true})
+	shr = <-ch
+	assert.Equal(t, topodatapb.TabletType_RDONLY, shr.Target.TabletType)
+
+	hs.ChangeState(healthState{tabletType: topodatapb.TabletType_RDONLY, err: errors.New("repl err")})
+	shr = <-ch
+	assert.False(t, shr.Serving)
+
+	select {
+	case shr := <-ch:
+		t.Errorf("unexpected message: %v", shr)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The subscription is dropped with the stream.
+	cancel()
+	<-done
+	hs.mu.Lock()
+	assert.Empty(t, hs.clients)
+	hs.mu.Unlock()
+}
+
+func TestHealthStreamerServingChangesHeartbeat(t *testing.T) {
+	config := tabletenv.NewDefaultConfig()
+	env := tabletenv.NewEnv(config, "ReplTrackerTest")
+	alias := topodatapb.TabletAlias{
+		Cell: "cell",
+		Uid:  1,
+	}
+	blpFunc = testBlpFunc
+	hs := newHealthStreamer(env, alias)
+	hs.Open()
+	defer hs.Close()
+	hs.InitDBConfig(querypb.Target{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := make(chan *querypb.StreamHealthResponse)
+	go func() {
+		_ = hs.StreamWithOptions(ctx, streamOptions{ServingChangesOnly: true, Heartbeat: 10 * time.Millisecond}, func(shr *querypb.StreamHealthResponse) error {
+			ch <- shr
+			return nil
+		})
+	}()
+	<-ch
+
+	// Suppressed changes are still reported by the heartbeat.
+	hs.ChangeState(healthState{tabletType: topodatapb.TabletType_UNKNOWN, err: errors.New(errUnintialized), pu: poolUsage{txInUse: 1}})
+	shr := <-ch
+	assert.Equal(t, int64(1), shr.RealtimeStats.TransactionPoolInUse)
+}
+
+func TestHealthStreamerIdentityMismatch(t *testing.T) {
+	config := tabletenv.NewDefaultConfig()
+	env := tabletenv.NewEnv(config, "ReplTrackerTest")
+	alias := topodatapb.TabletAlias{
+		Cell: "cell",
+		Uid:  1,
+	}
+	blpFunc = testBlpFunc
+	hs := newHealthStreamer(env, alias)
+	hs.Open()
+	defer hs.Close()
+	hs.InitDBConfig(querypb.Target{Keyspace: "ks", Shard: "0", Cell: "cell"})
+	mismatches := hs.identityMismatches.Get()
+
+	ch, cancel := testStream(hs)
+	defer cancel()
+	<-ch
+
+	hs.ChangeState(healthState{tabletType: topodatapb.TabletType_REPLICA, serving: true})
+	shr := <-ch
+	assert.True(t, shr.Serving)
+	assert.Empty(t, shr.RealtimeStats.HealthError)
+
+	// The alias is sent even if the state lost it.
+	hs.mu.Lock()
+	hs.state.TabletAlias = nil
+	hs.mu.Unlock()
+	hs.ChangeState(healthState{tabletType: topodatapb.TabletType_REPLICA, serving: true})
+	shr = <-ch
+	assert.Equal(t, &alias, shr.TabletAlias)
+	assert.True(t, shr.Serving)
+
+	// The target changes mid-stream.
+	hs.mu.Lock()
+	hs.state.Target.Shard = "80-"
+	hs.mu.Unlock()
+	hs.ChangeState(healthState{tabletType: topodatapb.TabletType_REPLICA, serving: true})
+	shr = <-ch
+	assert.False(t, shr.Serving)
+	assert.Nil(t, shr.AcceptedTabletTypes)
+	assert.Equal(t, `health stream advertises ks/80- in cell "cell", but InitDBConfig has ks/0 in cell "cell"`, shr.RealtimeStats.HealthError)
+	assert.Equal(t, mismatches+1, hs.identityMismatches.Get())
+
+	hs.mu.Lock()
+	hs.mismatchFatal = true
+	hs.mu.Unlock()
+	assert.Panics(t, func() {
+		hs.ChangeState(healthState{tabletType: topodatapb.TabletType_REPLICA, serving: true})
+	})
+}
+
+func TestStateManagerHealthIdentityMismatch(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	mismatches := sm.hs.identityMismatches.Get()
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	assert.NoError(t, err)
+
+	ch, cancel := testStream(sm.hs)
+	defer cancel()
+	<-ch
+
+	// The target of the state manager changes mid-stream. The
+	// broadcasts of the transition may still be on their way.
+	sm.mu.Lock()
+	sm.target.Keyspace = "other"
+	sm.mu.Unlock()
+	sm.Broadcast()
+	var shr *querypb.StreamHealthResponse
+	for shr = <-ch; shr.Serving; shr = <-ch {
+	}
+	assert.Equal(t, `health stream advertises / in cell "", but the state manager has other/ in cell ""`, shr.RealtimeStats.HealthError)
+	// The periodic and transition broadcasts may count more.
+	assert.Greater(t, sm.hs.identityMismatches.Get(), mismatches)
+}
+
+func TestHealthStreamerMaxSubscribers(t *testing.T) {
+	config := tabletenv.NewDefaultConfig()
+	config.Healthcheck.MaxStreamSubscribers = 2
+	env := tabletenv.NewEnv(config, "ReplTrackerTest")
+	alias := topodatapb.TabletAlias{
+		Cell: "cell",
+		Uid:  1,
+	}
+	blpFunc = testBlpFunc
+	hs := newHealthStreamer(env, alias)
+	hs.Open()
+	defer hs.Close()
+	hs.InitDBConfig(querypb.Target{})
+	rejected := hs.clientsRejected.Get()
+
+	ch1, cancel1 := testStream(hs)
+	<-ch1
+	ch2, cancel2 := testStream(hs)
+	defer cancel2()
+	<-ch2
+	assert.Equal(t, int64(2), hs.clientCount())
+
+	err := hs.Stream(context.Background(), func(shr *querypb.StreamHealthResponse) error {
+		t.Errorf("unexpected message: %v", shr)
+		return nil
+	})
+	require.Error(t, err)
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	assert.Equal(t, "too many health stream subscribers: the limit is 2", err.Error())
+	assert.Equal(t, rejected+1, hs.clientsRejected.Get())
+
+	// A closed stream makes room for another one.
+	cancel1()
+	for hs.clientCount() == 2 {
+		time.Sleep(time.Millisecond)
+	}
+	ch3, cancel3 := testStream(hs)
+	defer cancel3()
+	<-ch3
+	assert.Equal(t, rejected+1, hs.clientsRejected.Get())
+}
+
+func TestHealthStreamerIdleEviction(t *testing.T) {
+	config := tabletenv.NewDefaultConfig()
+	config.Healthcheck.StreamIdleBroadcasts = 3
+	env := tabletenv.NewEnv(config, "ReplTrackerTest")
+	alias := topodatapb.TabletAlias{
+		Cell: "cell",
+		Uid:  1,
+	}
+	blpFunc = testBlpFunc
+	hs := newHealthStreamer(env, alias)
+	hs.Open()
+	defer hs.Close()
+	hs.InitDBConfig(querypb.Target{})
+	evicted := hs.clientsEvicted.Get()
+
+	healthy, cancel := testStream(hs)
+	defer cancel()
+	<-healthy
+
+	// The stuck subscriber blocks on its first message.
+	stuck := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- hs.Stream(context.Background(), func(shr *querypb.StreamHealthResponse) error {
+			select {
+			case stuck <- struct{}{}:
+				<-release
+			default:
+			}
+			return nil
+		})
+	}()
+	<-stuck
+
+	// The first broadcast is queued for the stuck subscriber,
+	// the following ones can't be.
+	for i := 0; i < 4; i++ {
+		hs.ChangeState(healthState{tabletType: topodatapb.TabletType_REPLICA, lag: time.Duration(i) * time.Second, serving: true})
+		shr := <-healthy
+		assert.Equal(t, uint32(i), shr.RealtimeStats.SecondsBehindMaster)
+		if i < 3 {
+			assert.Equal(t, int64(2), hs.clientCount())
+		}
+	}
+	assert.Equal(t, int64(1), hs.clientCount())
+	assert.Equal(t, evicted+1, hs.clientsEvicted.Get())
+
+	close(release)
+	err := <-done
+	require.Error(t, err)
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	assert.Equal(t, "health stream closed: none of the last 3 health broadcasts could be delivered", err.Error())
+
+	// The healthy subscriber is not disturbed.
+	hs.ChangeState(healthState{tabletType: topodatapb.TabletType_REPLICA})
+	shr := <-healthy
+	assert.False(t, shr.Serving)
+	assert.Equal(t, evicted+1, hs.clientsEvicted.Get())
 }
 
 func testStream(hs *healthStreamer) (<-chan *querypb.StreamHealthResponse, context.CancelFunc) {
@@ -0,0 +1,84 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interceptor
+
+import (
+	"sync"
+
+	"golang.org/x/net/context"
+
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// CallerConcurrency is an Interceptor that caps the number of
+// concurrent requests of every effective caller. Unlike the fair
+// share limiter, it never queues requests: a caller at its cap is
+// rejected right away, whatever the load of the tablet.
+type CallerConcurrency struct {
+	max int
+
+	mu       sync.Mutex
+	inFlight map[string]int
+}
+
+// NewCallerConcurrency returns a CallerConcurrency that allows
+// max concurrent requests per effective caller.
+func NewCallerConcurrency(max int) *CallerConcurrency {
+	return &CallerConcurrency{
+		max:      max,
+		inFlight: make(map[string]int),
+	}
+}
+
+// Name implements Interceptor.
+func (cc *CallerConcurrency) Name() string {
+	return "caller_concurrency"
+}
+
+// Intercept implements Interceptor.
+func (cc *CallerConcurrency) Intercept(ctx context.Context, req *Request) (DoneFunc, error) {
+	caller := "unknown"
+	if req.EffectiveCallerID != nil {
+		caller = callerid.GetPrincipal(req.EffectiveCallerID)
+	}
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.inFlight[caller] >= cc.max {
+		return nil, vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "caller %s is at its cap of %d concurrent requests", caller, cc.max)
+	}
+	cc.inFlight[caller]++
+	return func() {
+		cc.mu.Lock()
+		defer cc.mu.Unlock()
+		if cc.inFlight[caller] <= 1 {
+			delete(cc.inFlight, caller)
+			return
+		}
+		cc.inFlight[caller]--
+	}, nil
+}
+
+// InFlight returns the number of requests of caller in flight.
+func (cc *CallerConcurrency) InFlight(caller string) int {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.inFlight[caller]
+}
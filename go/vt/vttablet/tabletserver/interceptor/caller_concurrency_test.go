@@ -0,0 +1,66 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interceptor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestCallerConcurrency(t *testing.T) {
+	cc := NewCallerConcurrency(2)
+	ctx := context.Background()
+	app := &Request{EffectiveCallerID: callerid.NewEffectiveCallerID("app", "", "")}
+	other := &Request{EffectiveCallerID: callerid.NewEffectiveCallerID("other", "", "")}
+
+	done1, err := cc.Intercept(ctx, app)
+	require.NoError(t, err)
+	done2, err := cc.Intercept(ctx, app)
+	require.NoError(t, err)
+	assert.Equal(t, 2, cc.InFlight("app"))
+
+	_, err = cc.Intercept(ctx, app)
+	assert.EqualError(t, err, "caller app is at its cap of 2 concurrent requests")
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+
+	// The other callers have their own cap.
+	done3, err := cc.Intercept(ctx, other)
+	require.NoError(t, err)
+	done3()
+
+	done1()
+	done1, err = cc.Intercept(ctx, app)
+	require.NoError(t, err)
+	done1()
+	done2()
+	assert.Equal(t, 0, cc.InFlight("app"))
+	assert.Empty(t, cc.inFlight)
+
+	// Requests without a caller share the unknown caller.
+	done, err := cc.Intercept(ctx, &Request{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, cc.InFlight("unknown"))
+	done()
+}
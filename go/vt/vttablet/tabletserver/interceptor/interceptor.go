@@ -0,0 +1,163 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package interceptor lets code outside of the query paths of
+// vttablet act on every request it admits, e.g. to account for the
+// requests of every caller. See the Interceptor interface.
+package interceptor
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/tb"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// Request describes a request that vttablet admitted.
+type Request struct {
+	// Name is the name of the request, e.g. Execute.
+	Name              string
+	Target            *querypb.Target
+	ImmediateCallerID *querypb.VTGateCallerID
+	EffectiveCallerID *vtrpcpb.CallerID
+	// State is the state of the tablet when it admitted the request.
+	State State
+}
+
+// State is a snapshot of the state of the tablet.
+type State struct {
+	TabletType     topodatapb.TabletType
+	Serving        bool
+	Lameduck       bool
+	ReplicationLag time.Duration
+}
+
+// DoneFunc is called once an intercepted request is done.
+type DoneFunc func()
+
+// Interceptor is called for every request that vttablet admits,
+// before it's executed. It's on the path of every request, so it
+// must be cheap, and it's called concurrently. No lock of vttablet
+// is held while it's called.
+type Interceptor interface {
+	// Name identifies the interceptor in the logs and the stats.
+	Name() string
+	// Intercept may veto req by returning an error, which is returned
+	// to the client as is. Otherwise, the returned DoneFunc, if not
+	// nil, is called once the request is done.
+	Intercept(ctx context.Context, req *Request) (DoneFunc, error)
+}
+
+// Chain calls the interceptors registered with it in order. A panic
+// in an interceptor is logged and counted, and doesn't fail the
+// request.
+type Chain struct {
+	mu           sync.Mutex
+	interceptors []Interceptor
+
+	vetoes *stats.CountersWithSingleLabel
+	panics *stats.CountersWithSingleLabel
+}
+
+// NewChain returns an empty Chain.
+func NewChain(env tabletenv.Env) *Chain {
+	return &Chain{
+		vetoes: env.Exporter().NewCountersWithSingleLabel("InterceptorVetoes", "Count of requests vetoed by the request interceptors, by interceptor", "interceptor"),
+		panics: env.Exporter().NewCountersWithSingleLabel("InterceptorPanics", "Count of panics recovered in the request interceptors, by interceptor", "interceptor"),
+	}
+}
+
+// Register adds interceptor at the end of the chain.
+func (c *Chain) Register(interceptor Interceptor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Intercept may be iterating over the current slice.
+	interceptors := make([]Interceptor, 0, len(c.interceptors)+1)
+	c.interceptors = append(append(interceptors, c.interceptors...), interceptor)
+}
+
+// Len returns the number of registered interceptors. The callers
+// of Intercept can skip building the request if it's 0.
+func (c *Chain) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.interceptors)
+}
+
+// Intercept calls the interceptors in order, until one of them
+// vetoes req. The interceptors that let req through before a veto
+// are done right away. If there's no veto, the returned DoneFunc
+// must be called once the request is done. It calls the DoneFuncs
+// of the interceptors in reverse order.
+func (c *Chain) Intercept(ctx context.Context, req *Request) (DoneFunc, error) {
+	c.mu.Lock()
+	interceptors := c.interceptors
+	c.mu.Unlock()
+
+	dones := make([]DoneFunc, 0, len(interceptors))
+	allDone := func() {
+		for i := len(dones) - 1; i >= 0; i-- {
+			c.callDone(interceptors[i], dones[i])
+		}
+	}
+	for _, interceptor := range interceptors {
+		done, err := c.call(ctx, interceptor, req)
+		if err != nil {
+			c.vetoes.Add(interceptor.Name(), 1)
+			allDone()
+			return nil, err
+		}
+		dones = append(dones, done)
+	}
+	return allDone, nil
+}
+
+// call calls interceptor. If it panics, req goes through.
+func (c *Chain) call(ctx context.Context, interceptor Interceptor, req *Request) (done DoneFunc, err error) {
+	defer func() {
+		if x := recover(); x != nil {
+			c.recovered(interceptor, x)
+			done, err = nil, nil
+		}
+	}()
+	return interceptor.Intercept(ctx, req)
+}
+
+func (c *Chain) callDone(interceptor Interceptor, done DoneFunc) {
+	if done == nil {
+		return
+	}
+	defer func() {
+		if x := recover(); x != nil {
+			c.recovered(interceptor, x)
+		}
+	}()
+	done()
+}
+
+func (c *Chain) recovered(interceptor Interceptor, x interface{}) {
+	c.panics.Add(interceptor.Name(), 1)
+	log.Errorf("Uncaught panic in request interceptor %s:\n%v\n%s", interceptor.Name(), x, tb.Stack(4))
+}
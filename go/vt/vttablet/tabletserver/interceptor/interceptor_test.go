@@ -0,0 +1,98 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interceptor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+// testInterceptor appends its events to a shared log.
+type testInterceptor struct {
+	name  string
+	log   *[]string
+	err   error
+	panic string
+}
+
+func (ti *testInterceptor) Name() string {
+	return ti.name
+}
+
+func (ti *testInterceptor) Intercept(ctx context.Context, req *Request) (DoneFunc, error) {
+	*ti.log = append(*ti.log, ti.name)
+	if ti.panic != "" {
+		panic(ti.panic)
+	}
+	if ti.err != nil {
+		return nil, ti.err
+	}
+	return func() {
+		*ti.log = append(*ti.log, ti.name+" done")
+	}, nil
+}
+
+func newTestChain(t *testing.T, name string) *Chain {
+	return NewChain(tabletenv.NewEnv(tabletenv.NewDefaultConfig(), name))
+}
+
+func TestChain(t *testing.T) {
+	c := newTestChain(t, "TestChain")
+	assert.Equal(t, 0, c.Len())
+	done, err := c.Intercept(context.Background(), &Request{})
+	require.NoError(t, err)
+	done()
+
+	var log []string
+	c.Register(&testInterceptor{name: "a", log: &log})
+	c.Register(&testInterceptor{name: "b", log: &log})
+	assert.Equal(t, 2, c.Len())
+	done, err = c.Intercept(context.Background(), &Request{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, log)
+	done()
+	assert.Equal(t, []string{"a", "b", "b done", "a done"}, log)
+
+	// A veto stops the chain, and the interceptors before it are done.
+	log = nil
+	c.Register(&testInterceptor{name: "veto", log: &log, err: errors.New("vetoed")})
+	c.Register(&testInterceptor{name: "d", log: &log})
+	_, err = c.Intercept(context.Background(), &Request{})
+	assert.EqualError(t, err, "vetoed")
+	assert.Equal(t, []string{"a", "b", "veto", "b done", "a done"}, log)
+	assert.Equal(t, map[string]int64{"veto": 1}, c.vetoes.Counts())
+}
+
+func TestChainPanic(t *testing.T) {
+	c := newTestChain(t, "TestChainPanic")
+	var log []string
+	c.Register(&testInterceptor{name: "panic", log: &log, panic: "boom"})
+	c.Register(&testInterceptor{name: "b", log: &log})
+
+	// The request goes through.
+	done, err := c.Intercept(context.Background(), &Request{})
+	require.NoError(t, err)
+	done()
+	assert.Equal(t, []string{"panic", "b", "b done"}, log)
+	assert.Equal(t, map[string]int64{"panic": 1}, c.panics.Counts())
+}
@@ -0,0 +1,472 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+// liveConfigReloads is the number of reloads kept in the changelog.
+const liveConfigReloads = 20
+
+// liveConfig holds the config fields that can be changed while
+// vttablet runs, which avoids the serving blip of a restart. Its
+// consumers read the fields through its accessors at every use
+// rather than caching them.
+type liveConfig struct {
+	mu      sync.Mutex
+	values  liveConfigValues
+	reloads []*configReload
+
+	// tableExists, if set, is used to reject the table_max_rows
+	// overrides of unknown tables.
+	tableExists func(table string) bool
+}
+
+// liveConfigValues are the fields of a liveConfig. They're named
+// after the flags that set them at startup.
+type liveConfigValues struct {
+	DegradedThreshold     time.Duration `json:"degraded_threshold"`
+	UnhealthyThreshold    time.Duration `json:"unhealthy_threshold"`
+	TransitionGracePeriod time.Duration `json:"serving_state_grace_period"`
+	ShutdownGracePeriod   time.Duration `json:"transaction_shutdown_grace_period"`
+	// ThrottleAppThresholds is replaced as a whole at every
+	// reload, never modified, so copies of the values can share it.
+	ThrottleAppThresholds map[string]time.Duration `json:"throttle_app_thresholds,omitempty"`
+	// KeyspaceAliases and ShardAliases are replaced as a whole too.
+	KeyspaceAliases map[string]string `json:"keyspace_aliases,omitempty"`
+	ShardAliases    map[string]string `json:"shard_aliases,omitempty"`
+	// TableMaxRows and TransactionCapsByCaller are replaced as a
+	// whole too.
+	TableMaxRows            map[string]int64 `json:"table_max_rows,omitempty"`
+	TransactionCapsByCaller map[string]int64 `json:"transaction_caps_by_caller,omitempty"`
+}
+
+// configReload is an entry of the changelog of a liveConfig.
+// Rejected reloads are logged too, with their error.
+type configReload struct {
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"`
+	Changes []string  `json:"changes,omitempty"`
+	Error   string    `json:"error,omitempty"`
+}
+
+func newLiveConfig(config *tabletenv.TabletConfig) *liveConfig {
+	return &liveConfig{values: liveConfigValuesFrom(config)}
+}
+
+// liveConfigValuesFrom returns the live fields of config.
+func liveConfigValuesFrom(config *tabletenv.TabletConfig) liveConfigValues {
+	values := liveConfigValues{
+		DegradedThreshold:     config.Healthcheck.DegradedThresholdSeconds.Get(),
+		UnhealthyThreshold:    config.Healthcheck.UnhealthyThresholdSeconds.Get(),
+		TransitionGracePeriod: config.GracePeriods.TransitionSeconds.Get(),
+		ShutdownGracePeriod:   config.GracePeriods.TransactionShutdownSeconds.Get(),
+		KeyspaceAliases:       tabletenv.CloneAliases(config.KeyspaceAliases),
+		ShardAliases:          tabletenv.CloneAliases(config.ShardAliases),
+	}
+	if len(config.ThrottleAppThresholds) != 0 {
+		values.ThrottleAppThresholds = make(map[string]time.Duration, len(config.ThrottleAppThresholds))
+		for appName, threshold := range config.ThrottleAppThresholds {
+			values.ThrottleAppThresholds[appName] = threshold.Get()
+		}
+	}
+	values.TableMaxRows = liveCounts(config.TableMaxRows)
+	values.TransactionCapsByCaller = liveCounts(config.TransactionCapsByCaller)
+	return values
+}
+
+// liveCounts returns a copy of counts, or nil if it's empty.
+func liveCounts(counts map[string]int) map[string]int64 {
+	if len(counts) == 0 {
+		return nil
+	}
+	live := make(map[string]int64, len(counts))
+	for name, count := range counts {
+		live[name] = int64(count)
+	}
+	return live
+}
+
+// DegradedThreshold is the replication lag above which
+// a replica is reported as degraded.
+func (lc *liveConfig) DegradedThreshold() time.Duration {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.values.DegradedThreshold
+}
+
+// UnhealthyThreshold is the replication lag above which
+// a replica stops serving.
+func (lc *liveConfig) UnhealthyThreshold() time.Duration {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.values.UnhealthyThreshold
+}
+
+// TransitionGracePeriod is how long the old tablet type keeps
+// being served after a transition.
+func (lc *liveConfig) TransitionGracePeriod() time.Duration {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.values.TransitionGracePeriod
+}
+
+// ShutdownGracePeriod is how long the tx engine waits for the
+// open transactions to complete when it shuts down.
+func (lc *liveConfig) ShutdownGracePeriod() time.Duration {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.values.ShutdownGracePeriod
+}
+
+// ThrottleAppThreshold returns the lag throttler threshold
+// of appName if it overrides the default one.
+func (lc *liveConfig) ThrottleAppThreshold(appName string) (time.Duration, bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	threshold, ok := lc.values.ThrottleAppThresholds[appName]
+	return threshold, ok
+}
+
+// KeyspaceAlias returns the keyspace that keyspace is an alias of.
+func (lc *liveConfig) KeyspaceAlias(keyspace string) (string, bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	newName, ok := lc.values.KeyspaceAliases[keyspace]
+	return newName, ok
+}
+
+// ShardAlias returns the shard that shard is an alias of.
+func (lc *liveConfig) ShardAlias(shard string) (string, bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	newName, ok := lc.values.ShardAliases[shard]
+	return newName, ok
+}
+
+// TableMaxRows returns the max number of rows the queries
+// of table can return if it overrides the global one.
+func (lc *liveConfig) TableMaxRows(table string) (int64, bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	rows, ok := lc.values.TableMaxRows[table]
+	return rows, ok
+}
+
+// TransactionCap returns the max number of concurrent
+// transactions of caller if it's capped.
+func (lc *liveConfig) TransactionCap(caller string) (int64, bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	max, ok := lc.values.TransactionCapsByCaller[caller]
+	return max, ok
+}
+
+// Values returns the current fields of lc.
+func (lc *liveConfig) Values() liveConfigValues {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.values
+}
+
+// Reloads returns the changelog of lc, oldest first.
+func (lc *liveConfig) Reloads() []*configReload {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return append([]*configReload(nil), lc.reloads...)
+}
+
+// Reload replaces the fields of lc with values. All the fields are
+// checked before any is applied: if one is invalid, the reload is
+// rejected and lc is left unchanged. source tells what triggered the
+// reload in the changelog.
+func (lc *liveConfig) Reload(source string, values liveConfigValues) error {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	reload := &configReload{
+		Time:   time.Now(),
+		Source: source,
+	}
+	defer func() {
+		lc.reloads = append(lc.reloads, reload)
+		if len(lc.reloads) > liveConfigReloads {
+			lc.reloads = lc.reloads[len(lc.reloads)-liveConfigReloads:]
+		}
+	}()
+	err := values.verify()
+	if err == nil {
+		err = lc.verifyTablesLocked(values.TableMaxRows)
+	}
+	if err != nil {
+		reload.Error = err.Error()
+		log.Warningf("Config reload from %s rejected: %v", source, err)
+		return err
+	}
+	reload.Changes = lc.values.diff(values)
+	lc.values = values
+	log.Infof("Config reloaded from %s: %v", source, reload.Changes)
+	return nil
+}
+
+// verifyTablesLocked checks that the tables of the new or changed
+// table_max_rows overrides exist. The overrides of the tables dropped
+// since are kept, so that they don't block the reloads of the other
+// fields.
+func (lc *liveConfig) verifyTablesLocked(tableMaxRows map[string]int64) error {
+	if lc.tableExists == nil {
+		return nil
+	}
+	for table, rows := range tableMaxRows {
+		if prev, ok := lc.values.TableMaxRows[table]; ok && prev == rows {
+			continue
+		}
+		if !lc.tableExists(table) {
+			return fmt.Errorf("table_max_rows: table %s not found in the schema", table)
+		}
+	}
+	return nil
+}
+
+func (values liveConfigValues) verify() error {
+	if values.DegradedThreshold <= 0 {
+		return fmt.Errorf("degraded_threshold must be > 0 (specified value: %v)", values.DegradedThreshold)
+	}
+	if values.UnhealthyThreshold <= values.DegradedThreshold {
+		return fmt.Errorf("unhealthy_threshold must be > degraded_threshold (specified values: %v, %v)", values.UnhealthyThreshold, values.DegradedThreshold)
+	}
+	if values.TransitionGracePeriod < 0 {
+		return fmt.Errorf("serving_state_grace_period must be >= 0 (specified value: %v)", values.TransitionGracePeriod)
+	}
+	if values.ShutdownGracePeriod < 0 {
+		return fmt.Errorf("transaction_shutdown_grace_period must be >= 0 (specified value: %v)", values.ShutdownGracePeriod)
+	}
+	for appName, threshold := range values.ThrottleAppThresholds {
+		if threshold <= 0 {
+			return fmt.Errorf("throttle_app_thresholds must be > 0 (specified value for %s: %v)", appName, threshold)
+		}
+	}
+	for table, rows := range values.TableMaxRows {
+		if rows <= 0 {
+			return fmt.Errorf("table_max_rows must be > 0 (specified value for %s: %v)", table, rows)
+		}
+	}
+	for caller, max := range values.TransactionCapsByCaller {
+		if max <= 0 {
+			return fmt.Errorf("transaction_caps_by_caller must be > 0 (specified value for %s: %v)", caller, max)
+		}
+	}
+	if err := tabletenv.VerifyAliases(values.KeyspaceAliases); err != nil {
+		return fmt.Errorf("keyspace_aliases: %v", err)
+	}
+	if err := tabletenv.VerifyAliases(values.ShardAliases); err != nil {
+		return fmt.Errorf("shard_aliases: %v", err)
+	}
+	return nil
+}
+
+// diff describes the fields that differ between values and next.
+func (values liveConfigValues) diff(next liveConfigValues) []string {
+	var changes []string
+	add := func(name string, from, to time.Duration) {
+		if from != to {
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", name, from, to))
+		}
+	}
+	add("degraded_threshold", values.DegradedThreshold, next.DegradedThreshold)
+	add("unhealthy_threshold", values.UnhealthyThreshold, next.UnhealthyThreshold)
+	add("serving_state_grace_period", values.TransitionGracePeriod, next.TransitionGracePeriod)
+	add("transaction_shutdown_grace_period", values.ShutdownGracePeriod, next.ShutdownGracePeriod)
+	if from, to := formatAppThresholds(values.ThrottleAppThresholds), formatAppThresholds(next.ThrottleAppThresholds); from != to {
+		changes = append(changes, fmt.Sprintf("throttle_app_thresholds: %s -> %s", from, to))
+	}
+	if from, to := formatAliases(values.KeyspaceAliases), formatAliases(next.KeyspaceAliases); from != to {
+		changes = append(changes, fmt.Sprintf("keyspace_aliases: %s -> %s", from, to))
+	}
+	if from, to := formatAliases(values.ShardAliases), formatAliases(next.ShardAliases); from != to {
+		changes = append(changes, fmt.Sprintf("shard_aliases: %s -> %s", from, to))
+	}
+	if from, to := formatCounts(values.TableMaxRows), formatCounts(next.TableMaxRows); from != to {
+		changes = append(changes, fmt.Sprintf("table_max_rows: %s -> %s", from, to))
+	}
+	if from, to := formatCounts(values.TransactionCapsByCaller), formatCounts(next.TransactionCapsByCaller); from != to {
+		changes = append(changes, fmt.Sprintf("transaction_caps_by_caller: %s -> %s", from, to))
+	}
+	return changes
+}
+
+// formatAppThresholds formats thresholds as app:duration pairs
+// sorted by app, the format update parses.
+func formatAppThresholds(thresholds map[string]time.Duration) string {
+	pairs := make([]string, 0, len(thresholds))
+	for appName, threshold := range thresholds {
+		pairs = append(pairs, fmt.Sprintf("%s:%v", appName, threshold))
+	}
+	sort.Strings(pairs)
+	return "[" + strings.Join(pairs, ",") + "]"
+}
+
+// parseAppThresholds parses comma separated app:duration pairs.
+// An empty value clears the overrides.
+func parseAppThresholds(value string) (map[string]time.Duration, error) {
+	if value == "" {
+		return nil, nil
+	}
+	thresholds := make(map[string]time.Duration)
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.Split(pair, ":")
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid throttle_app_thresholds: %q is not an app:duration pair", pair)
+		}
+		threshold, err := time.ParseDuration(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid throttle_app_thresholds: %v", err)
+		}
+		thresholds[parts[0]] = threshold
+	}
+	return thresholds, nil
+}
+
+// formatAliases formats aliases as old:new pairs sorted by old
+// name, the format update parses.
+func formatAliases(aliases map[string]string) string {
+	pairs := make([]string, 0, len(aliases))
+	for oldName, newName := range aliases {
+		pairs = append(pairs, fmt.Sprintf("%s:%s", oldName, newName))
+	}
+	sort.Strings(pairs)
+	return "[" + strings.Join(pairs, ",") + "]"
+}
+
+// parseAliases parses the comma separated old:new pairs of the
+// key field. An empty value clears the aliases.
+func parseAliases(key, value string) (map[string]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	aliases := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.Split(pair, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid %s: %q is not an old:new pair", key, pair)
+		}
+		aliases[parts[0]] = parts[1]
+	}
+	return aliases, nil
+}
+
+// formatCounts formats counts as name:count pairs sorted by
+// name, the format update parses.
+func formatCounts(counts map[string]int64) string {
+	pairs := make([]string, 0, len(counts))
+	for name, count := range counts {
+		pairs = append(pairs, fmt.Sprintf("%s:%d", name, count))
+	}
+	sort.Strings(pairs)
+	return "[" + strings.Join(pairs, ",") + "]"
+}
+
+// parseCounts parses the comma separated name:count pairs of the
+// key field. An empty value clears the counts.
+func parseCounts(key, value string) (map[string]int64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	counts := make(map[string]int64)
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.Split(pair, ":")
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid %s: %q is not a name:count pair", key, pair)
+		}
+		count, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", key, err)
+		}
+		counts[parts[0]] = count
+	}
+	return counts, nil
+}
+
+// update returns values with the fields set in form replaced. The
+// form keys are the flag names, and its values durations like "30s",
+// or app:duration pairs like "vreplication:500ms,online-ddl:5s" for
+// throttle_app_thresholds, old:new pairs for keyspace_aliases and
+// shard_aliases, or name:count pairs for table_max_rows and
+// transaction_caps_by_caller. Unknown keys are rejected, so that a typo
+// doesn't go unnoticed.
+func (values liveConfigValues) update(form url.Values) (liveConfigValues, error) {
+	fields := map[string]*time.Duration{
+		"degraded_threshold":                &values.DegradedThreshold,
+		"unhealthy_threshold":               &values.UnhealthyThreshold,
+		"serving_state_grace_period":        &values.TransitionGracePeriod,
+		"transaction_shutdown_grace_period": &values.ShutdownGracePeriod,
+	}
+	aliasFields := map[string]*map[string]string{
+		"keyspace_aliases": &values.KeyspaceAliases,
+		"shard_aliases":    &values.ShardAliases,
+	}
+	countFields := map[string]*map[string]int64{
+		"table_max_rows":             &values.TableMaxRows,
+		"transaction_caps_by_caller": &values.TransactionCapsByCaller,
+	}
+	for key := range form {
+		if key == "throttle_app_thresholds" {
+			thresholds, err := parseAppThresholds(form.Get(key))
+			if err != nil {
+				return values, err
+			}
+			values.ThrottleAppThresholds = thresholds
+			continue
+		}
+		if counts, ok := countFields[key]; ok {
+			parsed, err := parseCounts(key, form.Get(key))
+			if err != nil {
+				return values, err
+			}
+			*counts = parsed
+			continue
+		}
+		if aliases, ok := aliasFields[key]; ok {
+			parsed, err := parseAliases(key, form.Get(key))
+			if err != nil {
+				return values, err
+			}
+			*aliases = parsed
+			continue
+		}
+		field, ok := fields[key]
+		if !ok {
+			return values, fmt.Errorf("%s cannot be reloaded", key)
+		}
+		d, err := time.ParseDuration(form.Get(key))
+		if err != nil {
+			return values, fmt.Errorf("invalid %s: %v", key, err)
+		}
+		*field = d
+	}
+	return values, nil
+}
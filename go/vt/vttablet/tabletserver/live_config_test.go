@@ -0,0 +1,310 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+func TestLiveConfigReload(t *testing.T) {
+	lc := newLiveConfig(tabletenv.NewDefaultConfig())
+	old := lc.Values()
+	assert.Equal(t, 30*time.Second, old.DegradedThreshold)
+	assert.Equal(t, 2*time.Hour, old.UnhealthyThreshold)
+
+	values := old
+	values.UnhealthyThreshold = time.Minute
+	values.ShutdownGracePeriod = 5 * time.Second
+	require.NoError(t, lc.Reload("test", values))
+	assert.Equal(t, time.Minute, lc.UnhealthyThreshold())
+	assert.Equal(t, 5*time.Second, lc.ShutdownGracePeriod())
+	reloads := lc.Reloads()
+	require.Len(t, reloads, 1)
+	assert.Equal(t, "test", reloads[0].Source)
+	assert.Equal(t, []string{
+		"unhealthy_threshold: 2h0m0s -> 1m0s",
+		"transaction_shutdown_grace_period: 0s -> 5s",
+	}, reloads[0].Changes)
+
+	// An invalid field rejects the whole reload.
+	invalid := values
+	invalid.TransitionGracePeriod = 10 * time.Second
+	invalid.DegradedThreshold = 2 * time.Minute
+	err := lc.Reload("test", invalid)
+	assert.EqualError(t, err, "unhealthy_threshold must be > degraded_threshold (specified values: 1m0s, 2m0s)")
+	assert.Equal(t, values, lc.Values())
+	reloads = lc.Reloads()
+	require.Len(t, reloads, 2)
+	assert.Empty(t, reloads[1].Changes)
+	assert.Equal(t, err.Error(), reloads[1].Error)
+
+	// The changelog is capped.
+	for i := 0; i < liveConfigReloads; i++ {
+		require.NoError(t, lc.Reload(fmt.Sprintf("test%d", i), values))
+	}
+	reloads = lc.Reloads()
+	assert.Len(t, reloads, liveConfigReloads)
+	assert.Equal(t, "test0", reloads[0].Source)
+}
+
+func TestLiveConfigVerify(t *testing.T) {
+	valid := liveConfigValues{
+		DegradedThreshold:  time.Second,
+		UnhealthyThreshold: time.Minute,
+	}
+	testcases := []struct {
+		update func(*liveConfigValues)
+		err    string
+	}{{
+		update: func(*liveConfigValues) {},
+	}, {
+		update: func(v *liveConfigValues) { v.DegradedThreshold = 0 },
+		err:    "degraded_threshold must be > 0 (specified value: 0s)",
+	}, {
+		update: func(v *liveConfigValues) { v.UnhealthyThreshold = time.Second },
+		err:    "unhealthy_threshold must be > degraded_threshold (specified values: 1s, 1s)",
+	}, {
+		update: func(v *liveConfigValues) { v.TransitionGracePeriod = -time.Second },
+		err:    "serving_state_grace_period must be >= 0 (specified value: -1s)",
+	}, {
+		update: func(v *liveConfigValues) { v.ShutdownGracePeriod = -time.Second },
+		err:    "transaction_shutdown_grace_period must be >= 0 (specified value: -1s)",
+	}, {
+		update: func(v *liveConfigValues) { v.ThrottleAppThresholds = map[string]time.Duration{"vreplication": 0} },
+		err:    "throttle_app_thresholds must be > 0 (specified value for vreplication: 0s)",
+	}, {
+		update: func(v *liveConfigValues) { v.KeyspaceAliases = map[string]string{"ks": "ks"} },
+		err:    "keyspace_aliases: ks is an alias of itself",
+	}, {
+		update: func(v *liveConfigValues) { v.ShardAliases = map[string]string{"": "0"} },
+		err:    `shard_aliases: empty name in "":"0"`,
+	}, {
+		update: func(v *liveConfigValues) { v.TableMaxRows = map[string]int64{"events": 0} },
+		err:    "table_max_rows must be > 0 (specified value for events: 0)",
+	}, {
+		update: func(v *liveConfigValues) { v.TransactionCapsByCaller = map[string]int64{"batch": 0} },
+		err:    "transaction_caps_by_caller must be > 0 (specified value for batch: 0)",
+	}}
+	for _, tcase := range testcases {
+		values := valid
+		tcase.update(&values)
+		err := values.verify()
+		if tcase.err == "" {
+			assert.NoError(t, err)
+			continue
+		}
+		assert.EqualError(t, err, tcase.err)
+	}
+}
+
+func TestLiveConfigUpdate(t *testing.T) {
+	values := liveConfigValues{
+		DegradedThreshold:  time.Second,
+		UnhealthyThreshold: time.Minute,
+	}
+	got, err := values.update(url.Values{
+		"unhealthy_threshold":        []string{"1h"},
+		"serving_state_grace_period": []string{"3s"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, liveConfigValues{
+		DegradedThreshold:     time.Second,
+		UnhealthyThreshold:    time.Hour,
+		TransitionGracePeriod: 3 * time.Second,
+	}, got)
+
+	got, err = got.update(url.Values{"throttle_app_thresholds": []string{"vreplication:500ms,online-ddl:5s"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{"vreplication": 500 * time.Millisecond, "online-ddl": 5 * time.Second}, got.ThrottleAppThresholds)
+	assert.Equal(t, []string{
+		"unhealthy_threshold: 1m0s -> 1h0m0s",
+		"serving_state_grace_period: 0s -> 3s",
+		"throttle_app_thresholds: [] -> [online-ddl:5s,vreplication:500ms]",
+	}, values.diff(got))
+	got, err = got.update(url.Values{"throttle_app_thresholds": []string{""}})
+	require.NoError(t, err)
+	assert.Nil(t, got.ThrottleAppThresholds)
+	_, err = values.update(url.Values{"throttle_app_thresholds": []string{"vreplication"}})
+	assert.EqualError(t, err, `invalid throttle_app_thresholds: "vreplication" is not an app:duration pair`)
+
+	got, err = values.update(url.Values{
+		"keyspace_aliases": []string{"oldks:ks"},
+		"shard_aliases":    []string{"0:-,1:80-"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"oldks": "ks"}, got.KeyspaceAliases)
+	assert.Equal(t, map[string]string{"0": "-", "1": "80-"}, got.ShardAliases)
+	assert.Equal(t, []string{
+		"keyspace_aliases: [] -> [oldks:ks]",
+		"shard_aliases: [] -> [0:-,1:80-]",
+	}, values.diff(got))
+	_, err = values.update(url.Values{"shard_aliases": []string{"0"}})
+	assert.EqualError(t, err, `invalid shard_aliases: "0" is not an old:new pair`)
+
+	got, err = values.update(url.Values{"table_max_rows": []string{"events:50000,logs:100"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"events": 50000, "logs": 100}, got.TableMaxRows)
+	assert.Equal(t, []string{"table_max_rows: [] -> [events:50000,logs:100]"}, values.diff(got))
+	_, err = values.update(url.Values{"table_max_rows": []string{"events:many"}})
+	assert.Contains(t, err.Error(), "invalid table_max_rows")
+
+	got, err = values.update(url.Values{"transaction_caps_by_caller": []string{"batch:10"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"batch": 10}, got.TransactionCapsByCaller)
+	assert.Equal(t, []string{"transaction_caps_by_caller: [] -> [batch:10]"}, values.diff(got))
+	_, err = values.update(url.Values{"transaction_caps_by_caller": []string{"batch"}})
+	assert.EqualError(t, err, `invalid transaction_caps_by_caller: "batch" is not a name:count pair`)
+
+	_, err = values.update(url.Values{"query_timeout": []string{"1s"}})
+	assert.EqualError(t, err, "query_timeout cannot be reloaded")
+	_, err = values.update(url.Values{"degraded_threshold": []string{"10"}})
+	assert.Contains(t, err.Error(), "invalid degraded_threshold")
+}
+
+func TestLiveConfigTableMaxRows(t *testing.T) {
+	lc := newLiveConfig(tabletenv.NewDefaultConfig())
+	tables := map[string]bool{"events": true, "logs": true}
+	lc.tableExists = func(table string) bool { return tables[table] }
+
+	values := lc.Values()
+	values.TableMaxRows = map[string]int64{"events": 50000, "missing": 10}
+	err := lc.Reload("test", values)
+	assert.EqualError(t, err, "table_max_rows: table missing not found in the schema")
+	_, ok := lc.TableMaxRows("events")
+	assert.False(t, ok)
+
+	values.TableMaxRows = map[string]int64{"events": 50000}
+	require.NoError(t, lc.Reload("test", values))
+	rows, ok := lc.TableMaxRows("events")
+	assert.True(t, ok)
+	assert.EqualValues(t, 50000, rows)
+
+	// The override of a dropped table doesn't block other reloads.
+	delete(tables, "events")
+	values.TableMaxRows = map[string]int64{"events": 50000, "logs": 100}
+	require.NoError(t, lc.Reload("test", values))
+	values.TableMaxRows = map[string]int64{"events": 100}
+	err = lc.Reload("test", values)
+	assert.EqualError(t, err, "table_max_rows: table events not found in the schema")
+}
+
+func TestStateManagerLiveUnhealthyThreshold(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	rt := sm.rt.(*testReplTracker)
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	rt.lag = 10 * time.Minute
+	sm.Broadcast()
+	assert.True(t, sm.replHealthy)
+
+	values := sm.live.Values()
+	values.UnhealthyThreshold = 5 * time.Minute
+	require.NoError(t, sm.live.Reload("test", values))
+	sm.Broadcast()
+	assert.False(t, sm.replHealthy)
+}
+
+func TestConfigHandlers(t *testing.T) {
+	db, tsv := setupTabletServerTest(t)
+	defer tsv.StopService()
+	defer db.Close()
+
+	post := func(path, form string) (int, string) {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", tsv.exporter.URLPrefix()+path, strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		http.DefaultServeMux.ServeHTTP(rr, req)
+		return rr.Code, rr.Body.String()
+	}
+	reload := func(form string) (int, string) {
+		t.Helper()
+		return post("/debug/config/reload", form)
+	}
+
+	code, body := reload("unhealthy_threshold=10m&serving_state_grace_period=2s")
+	assert.Equal(t, http.StatusOK, code, body)
+	assert.Equal(t, 10*time.Minute, tsv.sm.live.UnhealthyThreshold())
+	assert.Equal(t, 2*time.Second, tsv.te.live.TransitionGracePeriod())
+
+	// The lag throttler reads the app thresholds at every check.
+	code, body = reload("throttle_app_thresholds=vreplication:500ms")
+	assert.Equal(t, http.StatusOK, code, body)
+	threshold, ok := tsv.live.ThrottleAppThreshold("vreplication")
+	assert.True(t, ok)
+	assert.Equal(t, 500*time.Millisecond, threshold)
+	assert.Equal(t, http.StatusNotFound, tsv.CheckThrottler(context.Background(), "vreplication").StatusCode)
+
+	code, body = reload("unhealthy_threshold=1s")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Contains(t, body, "unhealthy_threshold must be > degraded_threshold")
+	assert.Equal(t, 10*time.Minute, tsv.hs.live.UnhealthyThreshold())
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", tsv.exporter.URLPrefix()+"/debug/config", nil)
+	http.DefaultServeMux.ServeHTTP(rr, req)
+	var got struct {
+		Values  liveConfigValues `json:"values"`
+		Reloads []*configReload  `json:"reloads"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+	assert.Equal(t, 10*time.Minute, got.Values.UnhealthyThreshold)
+	require.Len(t, got.Reloads, 3)
+	assert.Equal(t, "/debug/config/reload", got.Reloads[0].Source)
+	assert.NotEmpty(t, got.Reloads[2].Error)
+
+	cfg := tabletenv.NewDefaultConfig()
+	cfg.GracePeriods.TransactionShutdownSeconds.Set(3 * time.Second)
+	require.NoError(t, tsv.ReloadConfig("test", cfg))
+	assert.Equal(t, 3*time.Second, tsv.te.live.ShutdownGracePeriod())
+	assert.Equal(t, 2*time.Hour, tsv.sm.live.UnhealthyThreshold())
+
+	// The tablet config file is re-read on request.
+	code, body = post("/debug/config/reload_file", "")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Contains(t, body, "-tablet_config is not set")
+	f, err := ioutil.TempFile("", "tablet_config")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("healthcheck:\n  intervalSeconds: 20\n  degradedThresholdSeconds: 30\n  unhealthyThresholdSeconds: 7200\ngracePeriods:\n  transactionShutdownSeconds: 5\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	tsv.SetConfigFile(f.Name())
+	code, body = post("/debug/config/reload_file", "")
+	assert.Equal(t, http.StatusOK, code, body)
+	assert.Equal(t, 5*time.Second, tsv.te.live.ShutdownGracePeriod())
+	reloads := tsv.live.Reloads()
+	assert.Equal(t, f.Name(), reloads[len(reloads)-1].Source)
+}
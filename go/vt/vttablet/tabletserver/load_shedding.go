@@ -0,0 +1,83 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// refreshShedLocked recomputes the fraction of the requests that
+// StartRequest sheds. A healthy replica whose lag exceeds the
+// degraded threshold sheds a fraction that grows linearly with the
+// lag, from 0 at the degraded threshold to shedMaxFraction at the
+// unhealthy threshold, where it stops serving altogether. The
+// rejected requests are retryable, so vtgate sends them to the
+// healthier replicas instead. Masters and unhealthy tablets shed
+// nothing: the former can't be replaced, and the latter reject
+// every request anyway.
+func (sm *stateManager) refreshShedLocked(lag time.Duration) {
+	if sm.shedMaxFraction == 0 {
+		return
+	}
+	fraction := 0.0
+	degraded, unhealthy := sm.live.DegradedThreshold(), sm.live.UnhealthyThreshold()
+	if sm.target.TabletType != topodatapb.TabletType_MASTER && sm.replHealthy && lag > degraded && unhealthy > degraded {
+		fraction = sm.shedMaxFraction * float64(lag-degraded) / float64(unhealthy-degraded)
+	}
+	if (fraction == 0) != (sm.shedFraction == 0) {
+		if fraction == 0 {
+			log.Infof("Stopped shedding requests: replication lag %v", lag)
+		} else {
+			log.Warningf("Shedding %d%% of the requests: replication lag %v exceeds %v", shedPercent(fraction), lag, degraded)
+		}
+	}
+	sm.shedFraction = fraction
+}
+
+// shedLocked returns true if the current request must be shed.
+// It counts the shed requests. A new master doesn't wait for the
+// next broadcast to stop shedding.
+func (sm *stateManager) shedLocked() bool {
+	if sm.shedFraction == 0 || sm.target.TabletType == topodatapb.TabletType_MASTER {
+		return false
+	}
+	if sm.shedRand() >= sm.shedFraction {
+		return false
+	}
+	sm.requestsShed.Add(1)
+	return true
+}
+
+func (sm *stateManager) shedGauge() int64 {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return int64(shedPercent(sm.shedFraction))
+}
+
+// shedPercent returns fraction in percent, rounded up so that a
+// tablet that sheds requests never reports 0%.
+func shedPercent(fraction float64) int {
+	percent := int(fraction * 100)
+	if float64(percent) < fraction*100 {
+		percent++
+	}
+	return percent
+}
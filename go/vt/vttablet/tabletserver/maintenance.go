@@ -0,0 +1,82 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// maintenanceReason is the reason reported while the
+// tablet serves reads only for maintenance.
+const maintenanceReason = "maintenance"
+
+// serveMaintenance serves reads only, without changing the tablet
+// type. It's used during storage maintenance windows. The components
+// that write are closed, and the tx engine only accepts read-only
+// transactions, even on a master.
+func (sm *stateManager) serveMaintenance(ctx context.Context, wantTabletType topodatapb.TabletType) error {
+	sm.closeServing(true)
+	if wantTabletType != topodatapb.TabletType_MASTER {
+		sm.timeCall("se.MakeNonMaster", sm.se.MakeNonMaster)
+	}
+
+	if err := sm.connect(ctx, wantTabletType); err != nil {
+		return err
+	}
+	sm.se.RegisterNotifier(hsNotifierName, sm.hs.schemaChanged)
+
+	if err := sm.timeOp("te.AcceptReadOnly", sm.acceptReadOnly); err != nil {
+		return err
+	}
+	if wantTabletType == topodatapb.TabletType_MASTER {
+		sm.timeCall("watcher.Close", sm.watcher.Close)
+		sm.timeCall("rt.MakeMaster", sm.rt.MakeMaster)
+	} else {
+		sm.timeCall("rt.MakeNonMaster", sm.rt.MakeNonMaster)
+		sm.timeCall("watcher.Open", sm.watcher.Open)
+	}
+	sm.setState(wantTabletType, StateServingReadOnly)
+	return nil
+}
+
+// SetMaintenance switches a serving tablet to or from the read-only
+// maintenance state. The tablet type doesn't change.
+func (sm *stateManager) SetMaintenance(enable bool) error {
+	sm.mu.Lock()
+	tabletType, terTimestamp, wantState := sm.wantTabletType, sm.terTimestamp, sm.wantState
+	sm.mu.Unlock()
+	if !wantState.serving() {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot change maintenance: tablet is not serving")
+	}
+	if enable {
+		return sm.SetServingType(context.Background(), tabletType, terTimestamp, StateServingReadOnly, maintenanceReason)
+	}
+	return sm.SetServingType(context.Background(), tabletType, terTimestamp, StateServing, "")
+}
+
+// inMaintenance returns true if the tablet is requested
+// to serve reads only for maintenance.
+func (sm *stateManager) inMaintenance() bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.wantState == StateServingReadOnly
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestStateManagerMaintenance(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+
+	err := sm.SetMaintenance(true)
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	require.NoError(t, sm.SetMaintenance(true))
+
+	// The master keeps its type, but only accepts reads.
+	assert.Equal(t, topodatapb.TabletType_MASTER, sm.Target().TabletType)
+	assert.Equal(t, StateServingReadOnly, sm.State())
+	assert.Equal(t, "SERVING", sm.IsServingString())
+	assert.Equal(t, maintenanceReason, sm.reason)
+	assert.Equal(t, testStateOpen, sm.qe.(*testQueryEngine).state)
+	assert.Equal(t, testStateNonMaster, sm.te.(*testTxEngine).state)
+	assert.Equal(t, testStateMaster, sm.rt.(*testReplTracker).state)
+	assert.Equal(t, testStateClosed, sm.messager.(*testSubcomponent).state)
+	assert.Equal(t, testStateClosed, sm.tracker.(*testSubcomponent).state)
+	assert.Equal(t, "read-only", sm.Status().Subcomponents[7].Status)
+
+	target := &querypb.Target{TabletType: topodatapb.TabletType_MASTER}
+	require.NoError(t, sm.StartRequest(ctx, target, false))
+	sm.EndRequest()
+
+	err = sm.VerifyWritable()
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	assert.EqualError(t, err, "writes not allowed in state SERVING_READ_ONLY")
+
+	sm.Broadcast()
+	sm.hs.mu.Lock()
+	assert.True(t, sm.hs.state.Serving)
+	assert.Equal(t, "serving reads only for maintenance", sm.hs.state.RealtimeStats.TransitionStatus)
+	sm.hs.mu.Unlock()
+
+	require.NoError(t, sm.SetMaintenance(false))
+	assert.Equal(t, StateServing, sm.State())
+	assert.Equal(t, testStateMaster, sm.te.(*testTxEngine).state)
+	assert.Equal(t, testStateOpen, sm.messager.(*testSubcomponent).state)
+	assert.Equal(t, testStateOpen, sm.tracker.(*testSubcomponent).state)
+	assert.NoError(t, sm.VerifyWritable())
+}
+
+func TestStateManagerMaintenanceNonMaster(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServingReadOnly, maintenanceReason)
+	require.NoError(t, err)
+	assert.Equal(t, StateServingReadOnly, sm.State())
+	assert.True(t, sm.se.(*testSchemaEngine).nonMaster)
+	assert.Equal(t, testStateNonMaster, sm.te.(*testTxEngine).state)
+	assert.Equal(t, testStateNonMaster, sm.rt.(*testReplTracker).state)
+	assert.Equal(t, testStateOpen, sm.watcher.(*testBinlogWatcher).state)
+	assert.True(t, sm.IsServing())
+}
+
+func TestTabletServerMaintenance(t *testing.T) {
+	db, tsv := setupTabletServerTest(t)
+	defer tsv.StopService()
+	defer db.Close()
+	target := querypb.Target{TabletType: topodatapb.TabletType_MASTER}
+
+	maintenance := func(enable string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest("POST", tsv.exporter.URLPrefix()+"/debug/maintenance?enable="+enable, nil)
+		response := httptest.NewRecorder()
+		http.DefaultServeMux.ServeHTTP(response, request)
+		return response
+	}
+	response := maintenance("true")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, StateServingReadOnly, tsv.sm.State())
+
+	_, _, err := tsv.Begin(ctx, &target, nil)
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+
+	// Read-only transactions are still allowed, but not their writes.
+	db.AddQuery("set transaction isolation level REPEATABLE READ", &sqltypes.Result{})
+	db.AddQuery("start transaction with consistent snapshot, read only", &sqltypes.Result{})
+	options := &querypb.ExecuteOptions{TransactionIsolation: querypb.ExecuteOptions_CONSISTENT_SNAPSHOT_READ_ONLY}
+	txid, _, err := tsv.Begin(ctx, &target, options)
+	require.NoError(t, err)
+	_, err = tsv.Execute(ctx, &target, "update test_table set name_string = 'a' where pk = 1", nil, txid, 0, nil)
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	_, err = tsv.Rollback(ctx, &target, txid)
+	require.NoError(t, err)
+
+	// Tablet type refreshes don't end the maintenance.
+	err = tsv.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, true, "")
+	require.NoError(t, err)
+	assert.Equal(t, StateServingReadOnly, tsv.sm.State())
+
+	response = maintenance("false")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, StateServing, tsv.sm.State())
+	txid, _, err = tsv.Begin(ctx, &target, nil)
+	require.NoError(t, err)
+	_, err = tsv.Rollback(ctx, &target, txid)
+	require.NoError(t, err)
+}
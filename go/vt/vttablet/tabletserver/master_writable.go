@@ -0,0 +1,70 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// writablePollInterval is how often waitForWritable checks
+// if mysql is still read-only. It's a var for tests.
+var writablePollInterval = 100 * time.Millisecond
+
+// errMySQLReadOnly is the health error of a master
+// whose mysql is still read-only.
+var errMySQLReadOnly = vterrors.New(vtrpcpb.Code_FAILED_PRECONDITION, "mysql still read-only")
+
+// waitForWritable waits for mysql to stop being read-only, which it
+// may still be if the tablet became master before the reparent
+// completed. Until it does, errMySQLReadOnly is reported as a health
+// error. If mysql is still read-only after masterWritableWait, it's
+// returned, which fails the transition into the retry loop. The wait
+// ends early if ctx is done.
+func (sm *stateManager) waitForWritable(ctx context.Context) error {
+	deadline := sm.clock.Now().Add(sm.masterWritableWait)
+	for {
+		readOnly, err := sm.se.IsReadOnly()
+		if err != nil {
+			return err
+		}
+		sm.mu.Lock()
+		if !readOnly {
+			sm.writableErr = nil
+			sm.mu.Unlock()
+			return nil
+		}
+		if sm.writableErr == nil {
+			log.Warningf("mysql is still read-only, waiting up to %v for it to become writable", sm.masterWritableWait)
+		}
+		sm.writableErr = errMySQLReadOnly
+		sm.mu.Unlock()
+		if !sm.clock.Now().Before(deadline) {
+			return errMySQLReadOnly
+		}
+		select {
+		case <-sm.clock.After(writablePollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/timer/fakeclock"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestStateManagerWaitForWritable(t *testing.T) {
+	defer func(saved time.Duration) { writablePollInterval = saved }(writablePollInterval)
+	writablePollInterval = time.Millisecond
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	se := sm.se.(*testSchemaEngine)
+
+	// Replicas don't check.
+	se.readOnlyChecks = 1
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, se.readOnlyChecks)
+
+	// The master waits for mysql to become writable.
+	se.readOnlyChecks = 3
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.Equal(t, 0, se.readOnlyChecks)
+	assert.True(t, sm.IsServing())
+	assert.Equal(t, topodatapb.TabletType_MASTER, sm.Target().TabletType)
+	assert.Nil(t, sm.writableErr)
+}
+
+func TestStateManagerWaitForWritableTimeout(t *testing.T) {
+	// The retries of the failed transitions never fire.
+	sm := newTestStateManagerWithClock(t, fakeclock.New(testNow))
+	defer sm.StopService()
+	sm.masterWritableWait = 0
+	se := sm.se.(*testSchemaEngine)
+	rt := sm.rt.(*testReplTracker)
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	// The tablet is left as it was, and reports why.
+	se.readOnlyChecks = 1
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.Error(t, err)
+	assert.Equal(t, "mysql still read-only", err.Error())
+	assert.Equal(t, topodatapb.TabletType_REPLICA, sm.Target().TabletType)
+	// The steps that write are not run.
+	assert.NotEqual(t, testStateMaster, rt.State())
+
+	sm.hs.Open()
+	ch, cancel := testStream(sm.hs)
+	defer cancel()
+	<-ch
+	sm.Broadcast()
+	shr := <-ch
+	assert.Equal(t, "mysql still read-only", shr.RealtimeStats.HealthError)
+
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.True(t, sm.IsServing())
+	sm.Broadcast()
+	shr = <-ch
+	assert.Equal(t, topodatapb.TabletType_MASTER, shr.Target.TabletType)
+	assert.Empty(t, shr.RealtimeStats.HealthError)
+}
+
+func TestStateManagerSkipReadOnlyCheck(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	sm.skipReadOnlyCheck = true
+	se := sm.se.(*testSchemaEngine)
+
+	se.readOnlyChecks = 1
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.True(t, sm.IsServing())
+	assert.Equal(t, 1, se.readOnlyChecks)
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"sort"
+	"sync"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+// The shrink priorities of the elastic buffers. Buffers with
+// a lower priority are shrunk first.
+const (
+	lagHistoryPriority = 0
+	historyPriority    = 1
+)
+
+// memoryAccounting tracks the estimated memory used by the buffers
+// of the state machinery. Each buffer reports its estimate when it's
+// resized, not when an element is added. If the total exceeds capBytes,
+// the elastic buffers are shrunk proportionally, starting with the
+// lowest priority.
+type memoryAccounting struct {
+	capBytes int64
+	shrinks  *stats.Counter
+
+	mu        sync.Mutex
+	accounts  []*memoryAccount
+	total     int64
+	shrinking bool
+}
+
+// memoryAccount is the memory used by one buffer.
+type memoryAccount struct {
+	ma       *memoryAccounting
+	name     string
+	priority int
+	// shrink shrinks the buffer to at most maxBytes and returns
+	// its new estimate. It's nil for fixed buffers.
+	shrink func(maxBytes int64) int64
+
+	// bytes is protected by ma.mu.
+	bytes int64
+}
+
+func newMemoryAccounting(env tabletenv.Env) *memoryAccounting {
+	ma := &memoryAccounting{
+		capBytes: env.Config().StateBuffersCapBytes,
+		shrinks:  env.Exporter().NewCounter("StateBuffersShrinks", "Count of times the state buffers were shrunk because they exceeded their memory cap"),
+	}
+	env.Exporter().NewGaugeFunc("StateBuffersBytes", "Estimated memory used by the health stream subscribers, the health history, the replication lag history and the request tracker", ma.Total)
+	return ma
+}
+
+// register adds a buffer. If shrink is nil, the buffer is fixed
+// and its priority is ignored.
+func (ma *memoryAccounting) register(name string, priority int, shrink func(maxBytes int64) int64) *memoryAccount {
+	ma.mu.Lock()
+	defer ma.mu.Unlock()
+	a := &memoryAccount{
+		ma:       ma,
+		name:     name,
+		priority: priority,
+		shrink:   shrink,
+	}
+	ma.accounts = append(ma.accounts, a)
+	return a
+}
+
+// Total returns the estimated memory used by all the buffers.
+func (ma *memoryAccounting) Total() int64 {
+	ma.mu.Lock()
+	defer ma.mu.Unlock()
+	return ma.total
+}
+
+// Set records the new estimate of the buffer, and shrinks the
+// elastic buffers if the cap is exceeded. It must not be called
+// with the lock of an elastic buffer held. Set is a no-op on a
+// nil account, which is the case before registration.
+func (a *memoryAccount) Set(bytes int64) {
+	if a == nil {
+		return
+	}
+	ma := a.ma
+	ma.mu.Lock()
+	ma.total += bytes - a.bytes
+	a.bytes = bytes
+	if ma.capBytes == 0 || ma.total <= ma.capBytes || ma.shrinking {
+		ma.mu.Unlock()
+		return
+	}
+	ma.shrinking = true
+	ma.mu.Unlock()
+
+	ma.enforce()
+}
+
+// enforce shrinks the elastic buffers until the total fits the cap.
+// Within a priority, every buffer loses the same fraction of its size.
+func (ma *memoryAccounting) enforce() {
+	ma.mu.Lock()
+	before := ma.total
+	ma.mu.Unlock()
+	defer func() {
+		ma.mu.Lock()
+		defer ma.mu.Unlock()
+		ma.shrinking = false
+		if ma.total < before {
+			ma.shrinks.Add(1)
+			log.Warningf("State buffers exceeded their memory cap of %d bytes, shrunk from %d to %d bytes", ma.capBytes, before, ma.total)
+		}
+	}()
+
+	for _, tier := range ma.tiers() {
+		ma.mu.Lock()
+		excess := ma.total - ma.capBytes
+		var tierBytes int64
+		for _, a := range tier {
+			tierBytes += a.bytes
+		}
+		ma.mu.Unlock()
+		if excess <= 0 {
+			return
+		}
+		if tierBytes == 0 {
+			continue
+		}
+		keep := 1 - float64(excess)/float64(tierBytes)
+		if keep < 0 {
+			keep = 0
+		}
+		for _, a := range tier {
+			ma.mu.Lock()
+			maxBytes := int64(float64(a.bytes) * keep)
+			ma.mu.Unlock()
+
+			// The buffer is shrunk without holding mu, so that
+			// it can use its own lock.
+			bytes := a.shrink(maxBytes)
+
+			ma.mu.Lock()
+			ma.total += bytes - a.bytes
+			a.bytes = bytes
+			ma.mu.Unlock()
+		}
+	}
+}
+
+// tiers returns the elastic accounts grouped by priority,
+// lowest first.
+func (ma *memoryAccounting) tiers() [][]*memoryAccount {
+	ma.mu.Lock()
+	var elastic []*memoryAccount
+	for _, a := range ma.accounts {
+		if a.shrink != nil {
+			elastic = append(elastic, a)
+		}
+	}
+	ma.mu.Unlock()
+
+	sort.SliceStable(elastic, func(i, j int) bool {
+		return elastic[i].priority < elastic[j].priority
+	})
+	var tiers [][]*memoryAccount
+	for i, a := range elastic {
+		if i == 0 || a.priority != elastic[i-1].priority {
+			tiers = append(tiers, nil)
+		}
+		tiers[len(tiers)-1] = append(tiers[len(tiers)-1], a)
+	}
+	return tiers
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+func newTestMemoryAccounting(capBytes int64) *memoryAccounting {
+	config := tabletenv.NewDefaultConfig()
+	config.StateBuffersCapBytes = capBytes
+	return newMemoryAccounting(tabletenv.NewEnv(config, "MemoryAccountingTest"))
+}
+
+// elasticBuffer is a buffer that can shrink down to min bytes.
+type elasticBuffer struct {
+	min     int64
+	shrunk  int
+	maxSeen int64
+}
+
+func (eb *elasticBuffer) shrink(maxBytes int64) int64 {
+	eb.shrunk++
+	eb.maxSeen = maxBytes
+	if maxBytes < eb.min {
+		return eb.min
+	}
+	return maxBytes
+}
+
+func TestMemoryAccounting(t *testing.T) {
+	ma := newTestMemoryAccounting(0)
+	fixed := ma.register("fixed", 0, nil)
+	eb := &elasticBuffer{}
+	elastic := ma.register("elastic", historyPriority, eb.shrink)
+
+	fixed.Set(100)
+	elastic.Set(1000)
+	assert.Equal(t, int64(1100), ma.Total())
+	fixed.Set(50)
+	assert.Equal(t, int64(1050), ma.Total())
+
+	// There's no cap.
+	elastic.Set(1 << 30)
+	assert.Zero(t, eb.shrunk)
+	assert.Zero(t, ma.shrinks.Get())
+
+	// A nil account is not registered yet.
+	var unregistered *memoryAccount
+	unregistered.Set(100)
+}
+
+func TestMemoryAccountingShrink(t *testing.T) {
+	ma := newTestMemoryAccounting(1000)
+	fixed := ma.register("fixed", 0, nil)
+	history1 := &elasticBuffer{}
+	history2 := &elasticBuffer{}
+	later := &elasticBuffer{}
+	ma.register("history1", historyPriority, history1.shrink).Set(400)
+	ma.register("history2", historyPriority, history2.shrink).Set(400)
+	laterAccount := ma.register("later", historyPriority+1, later.shrink)
+	laterAccount.Set(100)
+	assert.Zero(t, history1.shrunk)
+
+	// The histories lose the same fraction of their size,
+	// and the other buffers are left alone.
+	fixed.Set(300)
+	assert.Equal(t, int64(1000), ma.Total())
+	assert.Equal(t, int64(300), history1.maxSeen)
+	assert.Equal(t, int64(300), history2.maxSeen)
+	assert.Zero(t, later.shrunk)
+	assert.Equal(t, int64(1), ma.shrinks.Get())
+
+	// If the histories can't shrink enough, the next
+	// priority is shrunk too.
+	history1.min = 200
+	history2.min = 200
+	fixed.Set(550)
+	assert.Equal(t, int64(1000), ma.Total())
+	assert.Equal(t, 1, later.shrunk)
+	assert.Equal(t, int64(50), later.maxSeen)
+
+	// The fixed buffers are never shrunk, even if
+	// the cap can't be met.
+	fixed.Set(2000)
+	assert.Equal(t, int64(2000), fixed.bytes)
+	assert.Equal(t, int64(2400), ma.Total())
+	assert.Equal(t, int64(3), ma.shrinks.Get())
+}
+
+func TestMemoryAccountingStateManager(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	historyBytes := int64(sm.hs.history.Cap()) * healthRecordBytes
+	lagHistoryBytes := int64(sm.lagHistory.Cap()) * replLagSampleBytes
+	assert.Equal(t, historyBytes+lagHistoryBytes, sm.memory.Total())
+
+	// The replication lag history is shrunk first.
+	sm.memory.capBytes = historyBytes + replLagSampleBytes + healthResponseBytes
+	sm.hs.Open()
+	defer sm.hs.Close()
+	sub1, _, err := sm.hs.register(streamOptions{})
+	require.NoError(t, err)
+	defer sm.hs.unregister(sub1.ch)
+	assert.Equal(t, 1, sm.lagHistory.Cap())
+	assert.Equal(t, 5, sm.hs.history.Cap())
+
+	// The second subscriber exceeds the cap,
+	// and the history is shrunk to fit.
+	sub2, _, err := sm.hs.register(streamOptions{})
+	require.NoError(t, err)
+	defer sm.hs.unregister(sub2.ch)
+	assert.Equal(t, 4, sm.hs.history.Cap())
+	assert.Equal(t, 4*healthRecordBytes+replLagSampleBytes+2*healthResponseBytes, int(sm.memory.Total()))
+
+	// The request tracker is never shrunk. The histories
+	// keep at least one record.
+	st := sm.StartStream()
+	defer st.Done()
+	assert.Equal(t, 1, sm.hs.history.Cap())
+	assert.Equal(t, 1, sm.lagHistory.Cap())
+	assert.Equal(t, healthRecordBytes+replLagSampleBytes+2*healthResponseBytes+streamsMemChunk*streamTokenBytes, int(sm.memory.Total()))
+
+	// Further streams of the same chunk don't update the account.
+	for i := 1; i < streamsMemChunk; i++ {
+		defer sm.StartStream().Done()
+	}
+	assert.Equal(t, 1, sm.streamsMemChunks)
+	defer sm.StartStream().Done()
+	assert.Equal(t, 2, sm.streamsMemChunks)
+}
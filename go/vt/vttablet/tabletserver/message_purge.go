@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// PurgeAckedMessages deletes the messages of a message table that were
+// acked before olderThan, without waiting for the periodic purge. It
+// uses the same bounded deletes as the messager, and returns the number
+// of messages deleted, which is partial if it fails midway. It fails if
+// the messager is closed because the tablet is not a serving master.
+// Like the messager's own requests, it has no target: ctx must be based
+// on tabletenv.LocalContext.
+func (tsv *TabletServer) PurgeAckedMessages(ctx context.Context, name string, olderThan time.Time) (int64, error) {
+	if err := tsv.sm.checkMessagerOpen(); err != nil {
+		return 0, err
+	}
+	return tsv.messager.PurgeMessages(ctx, name, olderThan)
+}
+
+// checkMessagerOpen returns an error that explains why the messager
+// is closed, if it is. The messager is open only while serving master.
+func (sm *stateManager) checkMessagerOpen() error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.target.TabletType == topodatapb.TabletType_MASTER && sm.state == StateServing {
+		return nil
+	}
+	return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "messager is closed because the tablet is not a serving master: %s", sm.stateStringLocked(sm.target.TabletType, sm.state))
+}
+
+// registerPurgeMessagesHandler registers an admin action that purges
+// the acked messages of a message table on demand.
+func (tsv *TabletServer) registerPurgeMessagesHandler() {
+	tsv.exporter.HandleFunc("/debug/messages/purge", func(w http.ResponseWriter, r *http.Request) {
+		purgeMessagesHandler(w, r, tsv.PurgeAckedMessages)
+	})
+}
+
+// purgeMessagesHandler purges the messages of the table form value that
+// were acked more than older_than ago, and reports how many were purged.
+func purgeMessagesHandler(w http.ResponseWriter, r *http.Request, purge func(ctx context.Context, name string, olderThan time.Time) (int64, error)) {
+	if err := acl.CheckAccessHTTP(r, acl.ADMIN); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	name := r.FormValue("table")
+	if name == "" {
+		http.Error(w, "not ok: table must be specified", http.StatusBadRequest)
+		return
+	}
+	olderThan, err := time.ParseDuration(r.FormValue("older_than"))
+	if err != nil || olderThan < 0 {
+		http.Error(w, fmt.Sprintf("not ok: older_than must be a duration >= 0: %q", r.FormValue("older_than")), http.StatusBadRequest)
+		return
+	}
+	count, err := purge(tabletenv.LocalContext(), name, time.Now().Add(-olderThan))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("not ok: purged %d messages: %v", count, err), http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintf(w, "ok: purged %d messages\n", count)
+}
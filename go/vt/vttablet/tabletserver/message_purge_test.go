@@ -0,0 +1,103 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestPurgeAckedMessages(t *testing.T) {
+	_, tsv, db := newTestTxExecutor(t)
+	defer db.Close()
+	defer tsv.StopService()
+
+	db.AddQueryPattern("delete from msg where time_acked < .* limit 500", &sqltypes.Result{RowsAffected: 3})
+	ctx := tabletenv.LocalContext()
+	count, err := tsv.PurgeAckedMessages(ctx, "msg", time.Now())
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+
+	_, err = tsv.PurgeAckedMessages(ctx, "nonmsg", time.Now())
+	assert.EqualError(t, err, "message table nonmsg not found in schema")
+
+	err = tsv.SetServingType(ctx, topodatapb.TabletType_REPLICA, time.Time{}, true, "")
+	require.NoError(t, err)
+	_, err = tsv.PurgeAckedMessages(ctx, "msg", time.Now())
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	assert.EqualError(t, err, "messager is closed because the tablet is not a serving master: REPLICA: Serving")
+}
+
+func TestPurgeMessagesHandler(t *testing.T) {
+	var gotName string
+	var gotOlderThan time.Time
+	purge := func(ctx context.Context, name string, olderThan time.Time) (int64, error) {
+		gotName, gotOlderThan = name, olderThan
+		if name == "fail" {
+			return 500, errors.New("deadline exceeded")
+		}
+		return 1003, nil
+	}
+	testcases := []struct {
+		query string
+		code  int
+		body  string
+	}{{
+		query: "table=msg&older_than=1h",
+		code:  http.StatusOK,
+		body:  "ok: purged 1003 messages\n",
+	}, {
+		query: "older_than=1h",
+		code:  http.StatusBadRequest,
+		body:  "not ok: table must be specified\n",
+	}, {
+		query: "table=msg&older_than=-1h",
+		code:  http.StatusBadRequest,
+		body:  "not ok: older_than must be a duration >= 0: \"-1h\"\n",
+	}, {
+		query: "table=fail&older_than=0s",
+		code:  http.StatusServiceUnavailable,
+		body:  "not ok: purged 500 messages: deadline exceeded\n",
+	}}
+	for _, tcase := range testcases {
+		request, _ := http.NewRequest("POST", "/debug/messages/purge?"+tcase.query, nil)
+		response := httptest.NewRecorder()
+		purgeMessagesHandler(response, request, purge)
+		assert.Equal(t, tcase.code, response.Code, tcase.query)
+		assert.Equal(t, tcase.body, response.Body.String(), tcase.query)
+	}
+
+	gotName = ""
+	request, _ := http.NewRequest("POST", "/debug/messages/purge?table=msg&older_than=1h", nil)
+	purgeMessagesHandler(httptest.NewRecorder(), request, purge)
+	assert.Equal(t, "msg", gotName)
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), gotOlderThan, time.Minute)
+}
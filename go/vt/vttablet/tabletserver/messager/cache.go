@@ -32,7 +32,10 @@ type MessageRow struct {
 	TimeNext  int64
 	Epoch     int64
 	TimeAcked int64
-	Row       []sqltypes.Value
+	// TimeScheduled is 0 unless the table has a time_scheduled
+	// column, and the message was scheduled.
+	TimeScheduled int64
+	Row           []sqltypes.Value
 
 	// defunct is set if the row was asked to be removed
 	// from cache.
@@ -181,6 +184,24 @@ func (mc *cache) Discard(ids []string) {
 	}
 }
 
+// Remove removes the specified id from the send queue. Unlike
+// Discard, it leaves the message alone if it's being sent.
+func (mc *cache) Remove(id string) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if mr := mc.inQueue[id]; mr != nil {
+		mr.defunct = true
+		delete(mc.inQueue, id)
+	}
+}
+
+// Len returns the number of messages waiting to be sent.
+func (mc *cache) Len() int {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	return len(mc.inQueue)
+}
+
 // Size returns the max size of cache.
 func (mc *cache) Size() int {
 	mc.mu.Lock()
@@ -214,3 +214,21 @@ func TestMessagerCacheEmpty(t *testing.T) {
 		t.Errorf("Pop(non-empty): nil, want %v", row)
 	}
 }
+
+func TestMessagerCacheRemove(t *testing.T) {
+	mc := newCache(10)
+	mc.Add(&MessageRow{Row: []sqltypes.Value{sqltypes.NewVarBinary("row01")}})
+	mc.Add(&MessageRow{Row: []sqltypes.Value{sqltypes.NewVarBinary("row02")}})
+	inFlight := mc.Pop()
+	mc.Remove(inFlight.Row[0].ToString())
+	mc.Remove("row02")
+	if row := mc.Pop(); row != nil {
+		t.Errorf("Pop(removed): %v, want nil", row)
+	}
+	// The message being sent can't be added back
+	// until it's discarded.
+	mc.Add(&MessageRow{Row: []sqltypes.Value{inFlight.Row[0]}})
+	if got := mc.Len(); got != 0 {
+		t.Errorf("Len: %d, want 0", got)
+	}
+}
@@ -18,6 +18,7 @@ package messager
 
 import (
 	"sync"
+	"time"
 
 	"golang.org/x/net/context"
 
@@ -158,12 +159,42 @@ func (me *Engine) GeneratePurgeQuery(name string, timeCutoff int64) (string, map
 	return query, bv, nil
 }
 
+// PurgeMessages deletes the messages of the requested table that were
+// acked before olderThan, without waiting for the periodic purge. It
+// deletes them in batches, and returns the number of messages deleted.
+func (me *Engine) PurgeMessages(ctx context.Context, name string, olderThan time.Time) (int64, error) {
+	me.mu.Lock()
+	if !me.isOpen {
+		me.mu.Unlock()
+		return 0, vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "messager engine is closed, probably because this is not a master any more")
+	}
+	if me.managers[name] == nil {
+		me.mu.Unlock()
+		return 0, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "message table %s not found in schema", name)
+	}
+	me.mu.Unlock()
+	return purgeBatches(ctx, me.tsv, name, olderThan.UnixNano())
+}
+
 func (me *Engine) schemaChanged(tables map[string]*schema.Table, created, altered, dropped []string) {
 	me.mu.Lock()
 	defer me.mu.Unlock()
+	// updated contains the altered tables whose manager was
+	// updated in place rather than restarted.
+	updated := make(map[string]bool)
+	for _, name := range altered {
+		mm := me.managers[name]
+		if t := tables[name]; mm == nil || t == nil || t.Type != schema.Message || !mm.CanUpdate(t) {
+			continue
+		}
+		log.Infof("Updating messager for table: %v", name)
+		mm.Update(tables[name].MessageInfo)
+		updated[name] = true
+	}
+
 	for _, name := range append(dropped, altered...) {
 		mm := me.managers[name]
-		if mm == nil {
+		if mm == nil || updated[name] {
 			continue
 		}
 		log.Infof("Stopping messager for dropped/updated table: %v", name)
@@ -173,7 +204,7 @@ func (me *Engine) schemaChanged(tables map[string]*schema.Table, created, altere
 
 	for _, name := range append(created, altered...) {
 		t := tables[name]
-		if t.Type != schema.Message {
+		if t.Type != schema.Message || updated[name] {
 			continue
 		}
 		if me.managers[name] != nil {
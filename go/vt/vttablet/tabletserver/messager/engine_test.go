@@ -19,8 +19,12 @@ package messager
 import (
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
+	"golang.org/x/time/rate"
 
 	"vitess.io/vitess/go/mysql/fakesqldb"
 	"vitess.io/vitess/go/sqltypes"
@@ -92,6 +96,21 @@ func TestEngineSchemaChanged(t *testing.T) {
 	if !reflect.DeepEqual(got, want) {
 		t.Errorf("got: %+v, want %+v", got, want)
 	}
+	// A send rate change updates the manager in place.
+	mm := engine.managers["t1"]
+	rateChanged := newMMTable()
+	rateChanged.MessageInfo.MaxSendRate = 10
+	tables["t1"] = rateChanged
+	engine.schemaChanged(tables, nil, []string{"t1"}, nil)
+	assert.True(t, mm == engine.managers["t1"])
+	assert.Equal(t, rate.Limit(10), mm.limiter.Limit())
+	// Other changes restart it.
+	batchChanged := newMMTable()
+	batchChanged.MessageInfo.BatchSize = 2
+	tables["t1"] = batchChanged
+	engine.schemaChanged(tables, nil, []string{"t1"}, nil)
+	assert.False(t, mm == engine.managers["t1"])
+	assert.Equal(t, 2, engine.managers["t1"].batchSize)
 }
 
 func extractManagerNames(in map[string]*messageManager) map[string]bool {
@@ -138,6 +157,32 @@ func TestSubscribe(t *testing.T) {
 	}
 }
 
+func TestEnginePurgeMessages(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	engine := newTestEngine(db)
+	defer engine.Close()
+	engine.schemaChanged(map[string]*schema.Table{
+		"t1": meTable,
+	}, []string{"t1"}, nil, nil)
+
+	// The purge continues while batches are full.
+	tsv := engine.tsv.(*fakeTabletServer)
+	tsv.mu.Lock()
+	tsv.purgeRows = []int64{500, 500, 3}
+	tsv.mu.Unlock()
+	count, err := engine.PurgeMessages(context.Background(), "t1", time.Now())
+	require.NoError(t, err)
+	assert.EqualValues(t, 1003, count)
+
+	_, err = engine.PurgeMessages(context.Background(), "t2", time.Now())
+	assert.EqualError(t, err, "message table t2 not found in schema")
+
+	engine.Close()
+	_, err = engine.PurgeMessages(context.Background(), "t1", time.Now())
+	assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err))
+}
+
 func TestEngineGenerate(t *testing.T) {
 	db := fakesqldb.New(t)
 	defer db.Close()
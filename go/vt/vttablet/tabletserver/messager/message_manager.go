@@ -26,7 +26,9 @@ import (
 
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
 
+	"github.com/golang/protobuf/proto"
 	"golang.org/x/net/context"
+	"golang.org/x/time/rate"
 
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/sqltypes"
@@ -143,7 +145,7 @@ type receiverWithStatus struct {
 // If so, the system reverts to the steady state mode.
 //
 // Rate limiting
-// There are two ways for the system to rate-limit:
+// There are three ways for the system to rate-limit:
 // 1. Client ingestion rate. If clients ingest messages slowly,
 // that makes the senders wait on them to send more messages.
 // 2. Postpone rate limiting: A client is considered to be non-busy only
@@ -152,6 +154,10 @@ type receiverWithStatus struct {
 // limit the send rate to how fast messages can be postponed.
 // The postpone functions also needs to obtain a semaphore that limits
 // the number of tx pool connections they can occupy.
+// 3. Max send rate: The send loop doesn't pull more rows out of the cache
+// than the max send rate of the table allows. It waits for the limiter
+// otherwise, and the rows stay in the cache as backlog. The rate can be
+// changed while the manager is open.
 //
 // Client load balancing
 // The messages are sent to the clients in a round-robin fashion.
@@ -175,6 +181,16 @@ type messageManager struct {
 	pollerTicks  *timer.Timer
 	purgeTicks   *timer.Timer
 	postponeSema *sync2.Semaphore
+	// info is the MessageInfo the manager was created with, except
+	// for the max send rate, which can be updated in place.
+	info *schema.MessageInfo
+	// limiter enforces the max send rate. It's rate.Inf if there's
+	// no limit.
+	limiter *rate.Limiter
+	// hasTimeScheduled is set if the table has the optional
+	// time_scheduled column. Messages are then not sent before
+	// their scheduled time.
+	hasTimeScheduled bool
 
 	mu     sync.Mutex
 	isOpen bool
@@ -185,6 +201,9 @@ type messageManager struct {
 	receivers       []*receiverWithStatus
 	curReceiver     int
 	messagesPending bool
+	// rateWaitPending is set while the send loop waits for
+	// the limiter to allow more rows.
+	rateWaitPending bool
 
 	// streamMu keeps the cache and database consistent with each other.
 	// Specifically:
@@ -227,30 +246,45 @@ func newMessageManager(tsv TabletService, vs VStreamer, table *schema.Table, pos
 		fieldResult: &sqltypes.Result{
 			Fields: table.MessageInfo.Fields,
 		},
-		ackWaitTime:     table.MessageInfo.AckWaitDuration,
-		purgeAfter:      table.MessageInfo.PurgeAfterDuration,
-		minBackoff:      table.MessageInfo.MinBackoff,
-		maxBackoff:      table.MessageInfo.MaxBackoff,
-		batchSize:       table.MessageInfo.BatchSize,
-		cache:           newCache(table.MessageInfo.CacheSize),
-		pollerTicks:     timer.NewTimer(table.MessageInfo.PollInterval),
-		purgeTicks:      timer.NewTimer(table.MessageInfo.PollInterval),
-		postponeSema:    postponeSema,
-		messagesPending: true,
+		ackWaitTime:      table.MessageInfo.AckWaitDuration,
+		purgeAfter:       table.MessageInfo.PurgeAfterDuration,
+		minBackoff:       table.MessageInfo.MinBackoff,
+		maxBackoff:       table.MessageInfo.MaxBackoff,
+		batchSize:        table.MessageInfo.BatchSize,
+		cache:            newCache(table.MessageInfo.CacheSize),
+		pollerTicks:      timer.NewTimer(table.MessageInfo.PollInterval),
+		purgeTicks:       timer.NewTimer(table.MessageInfo.PollInterval),
+		postponeSema:     postponeSema,
+		messagesPending:  true,
+		info:             table.MessageInfo,
+		limiter:          rate.NewLimiter(maxSendRate(tsv, table.MessageInfo), table.MessageInfo.BatchSize),
+		hasTimeScheduled: hasTimeScheduled(table),
 	}
 	mm.cond.L = &mm.mu
 
+	hiddenList := "priority, time_next, epoch, time_acked"
+	if mm.hasTimeScheduled {
+		hiddenList += ", time_scheduled"
+	}
 	columnList := buildSelectColumnList(table)
-	vsQuery := fmt.Sprintf("select priority, time_next, epoch, time_acked, %s from %v", columnList, mm.name)
+	vsQuery := fmt.Sprintf("select %s, %s from %v", hiddenList, columnList, mm.name)
 	mm.vsFilter = &binlogdatapb.Filter{
 		Rules: []*binlogdatapb.Rule{{
 			Match:  table.Name.String(),
 			Filter: vsQuery,
 		}},
 	}
-	mm.readByPriorityAndTimeNext = sqlparser.BuildParsedQuery(
-		"select priority, time_next, epoch, time_acked, %s from %v where time_next < %a order by priority, time_next desc limit %a",
-		columnList, mm.name, ":time_next", ":max")
+	if mm.hasTimeScheduled {
+		// The messages scheduled in the future are left in the
+		// table. So, they don't take up space in the cache.
+		mm.readByPriorityAndTimeNext = sqlparser.BuildParsedQuery(
+			"select %s, %s from %v where time_next < %a and (time_scheduled is null or time_scheduled < %a) order by priority, time_next desc limit %a",
+			hiddenList, columnList, mm.name, ":time_next", ":time_next", ":max")
+	} else {
+		mm.readByPriorityAndTimeNext = sqlparser.BuildParsedQuery(
+			"select %s, %s from %v where time_next < %a order by priority, time_next desc limit %a",
+			hiddenList, columnList, mm.name, ":time_next", ":max")
+	}
 	mm.ackQuery = sqlparser.BuildParsedQuery(
 		"update %v set time_acked = %a, time_next = null where id in %a and time_acked is null",
 		mm.name, ":time_acked", "::ids")
@@ -308,6 +342,25 @@ func buildPostponeQuery(name sqlparser.TableIdent, minBackoff, maxBackoff time.D
 	return sqlparser.BuildParsedQuery(buf.String(), args...)
 }
 
+// hasTimeScheduled returns true if the message table has the
+// optional time_scheduled column.
+func hasTimeScheduled(table *schema.Table) bool {
+	return table.FindColumn(sqlparser.NewColIdent("time_scheduled")) != -1
+}
+
+// maxSendRate returns the max send rate of a message table. The
+// table's vt_max_send_rate wins over the tablet wide default.
+func maxSendRate(tsv TabletService, info *schema.MessageInfo) rate.Limit {
+	r := info.MaxSendRate
+	if r == 0 {
+		r = tsv.Config().MessageMaxSendRate
+	}
+	if r == 0 {
+		return rate.Inf
+	}
+	return rate.Limit(r)
+}
+
 // buildSelectColumnList is a convenience function that
 // builds a 'select' list for the user-defined columns.
 func buildSelectColumnList(t *schema.Table) string {
@@ -357,6 +410,7 @@ func (mm *messageManager) Close() {
 	mm.receivers = nil
 	MessageStats.Set([]string{mm.name.String(), "ClientCount"}, 0)
 	mm.cache.Clear()
+	MessageStats.Set([]string{mm.name.String(), "Backlog"}, 0)
 	// This broadcast will cause runSend to exit.
 	mm.cond.Broadcast()
 	mm.mu.Unlock()
@@ -366,6 +420,40 @@ func (mm *messageManager) Close() {
 	mm.wg.Wait()
 }
 
+// CanUpdate returns true if table differs from the table of mm
+// only by what can be changed in place by Update.
+func (mm *messageManager) CanUpdate(table *schema.Table) bool {
+	old, info := mm.info, table.MessageInfo
+	if mm.hasTimeScheduled != hasTimeScheduled(table) ||
+		old.AckWaitDuration != info.AckWaitDuration ||
+		old.PurgeAfterDuration != info.PurgeAfterDuration ||
+		old.BatchSize != info.BatchSize ||
+		old.CacheSize != info.CacheSize ||
+		old.PollInterval != info.PollInterval ||
+		old.MinBackoff != info.MinBackoff ||
+		old.MaxBackoff != info.MaxBackoff ||
+		len(old.Fields) != len(info.Fields) {
+		return false
+	}
+	for i, field := range old.Fields {
+		if !proto.Equal(field, info.Fields[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Update applies the max send rate of info without a reopen.
+// The send loop is woken up in case it's waiting for the
+// previous rate.
+func (mm *messageManager) Update(info *schema.MessageInfo) {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	mm.info = info
+	mm.limiter.SetLimit(maxSendRate(mm.tsv, info))
+	mm.cond.Broadcast()
+}
+
 // Subscribe registers the send function as a receiver of messages
 // and returns a 'done' channel that will be closed when the subscription
 // ends. There are many reasons for a subscription to end: a grpc context
@@ -426,6 +514,7 @@ func (mm *messageManager) unsubscribe(receiver *messageReceiver) {
 	if len(mm.receivers) == 0 {
 		mm.stopVStream()
 		mm.cache.Clear()
+		MessageStats.Set([]string{mm.name.String(), "Backlog"}, 0)
 	}
 }
 
@@ -470,6 +559,7 @@ func (mm *messageManager) Add(mr *MessageRow) bool {
 		mm.messagesPending = true
 		return false
 	}
+	MessageStats.Set([]string{mm.name.String(), "Backlog"}, int64(mm.cache.Len()))
 	return true
 }
 
@@ -514,9 +604,15 @@ func (mm *messageManager) runSend() {
 				continue
 			}
 
-			// Fetch rows from cache.
+			// Fetch rows from cache, as many as the send rate allows.
 			lateCount := int64(0)
+			limited := false
+			now := time.Now()
 			for i := 0; i < mm.batchSize; i++ {
+				if !mm.limiter.AllowN(now, 1) {
+					limited = true
+					break
+				}
 				mr := mm.cache.Pop()
 				if mr == nil {
 					break
@@ -527,11 +623,15 @@ func (mm *messageManager) runSend() {
 				rows = append(rows, mr.Row)
 			}
 			MessageStats.Add([]string{mm.name.String(), "Delayed"}, lateCount)
+			MessageStats.Set([]string{mm.name.String(), "Backlog"}, int64(mm.cache.Len()))
 
 			// If we have rows to send, break out of this loop.
 			if rows != nil {
 				break
 			}
+			if limited {
+				mm.waitForSendRate()
+			}
 		}
 		MessageStats.Add([]string{mm.name.String(), "Sent"}, int64(len(rows)))
 		// If we're here, there is a current receiver, and messages
@@ -546,6 +646,26 @@ func (mm *messageManager) runSend() {
 	}
 }
 
+// waitForSendRate waits until the limiter allows one more row
+// to be sent, or until something else wakes up the send loop.
+// It must be called with mu held.
+func (mm *messageManager) waitForSendRate() {
+	if !mm.rateWaitPending {
+		mm.rateWaitPending = true
+		MessageStats.Add([]string{mm.name.String(), "Deferred"}, 1)
+		// A cond can't wait with a timeout. So, a timer
+		// broadcasts once a row can be sent.
+		wait := time.Duration(float64(time.Second) / float64(mm.limiter.Limit()))
+		time.AfterFunc(wait, func() {
+			mm.mu.Lock()
+			defer mm.mu.Unlock()
+			mm.rateWaitPending = false
+			mm.cond.Broadcast()
+		})
+	}
+	mm.cond.Wait()
+}
+
 func (mm *messageManager) send(receiver *receiverWithStatus, qr *sqltypes.Result) {
 	defer func() {
 		mm.tsv.LogError()
@@ -721,10 +841,16 @@ func (mm *messageManager) processRowEvent(fields []*querypb.Field, rowEvent *bin
 			continue
 		}
 		row := sqltypes.MakeRowTrusted(fields, rc.After)
-		mr, err := BuildMessageRow(row)
+		mr, err := mm.buildMessageRow(row)
 		if err != nil {
 			return err
 		}
+		if mr.TimeScheduled > now {
+			// The message was scheduled or rescheduled in the
+			// future. The poller will load it once it's due.
+			mm.cache.Remove(mr.Row[0].ToString())
+			continue
+		}
 		if mr.TimeAcked != 0 || mr.TimeNext > now {
 			continue
 		}
@@ -776,7 +902,7 @@ func (mm *messageManager) runPoller() {
 		defer mm.cond.Broadcast()
 	}
 	for _, row := range qr.Rows {
-		mr, err := BuildMessageRow(row)
+		mr, err := mm.buildMessageRow(row)
 		if err != nil {
 			mm.tsv.Stats().InternalErrors.Add("Messages", 1)
 			log.Errorf("Error reading message row: %v", err)
@@ -801,17 +927,27 @@ func purge(tsv TabletService, name string, purgeAfter, purgeInterval time.Durati
 		tsv.LogError()
 		cancel()
 	}()
+	if _, err := purgeBatches(ctx, tsv, name, time.Now().Add(-purgeAfter).UnixNano()); err != nil {
+		log.Errorf("Unable to delete messages: %v", err)
+	}
+}
+
+// purgeBatches deletes the messages acked before timeCutoff, 500 at a
+// time, until there are none left. It returns the number of messages
+// deleted, which is partial if it fails or ctx expires midway.
+func purgeBatches(ctx context.Context, tsv TabletService, name string, timeCutoff int64) (int64, error) {
+	var total int64
 	for {
-		count, err := tsv.PurgeMessages(ctx, nil, name, time.Now().Add(-purgeAfter).UnixNano())
+		count, err := tsv.PurgeMessages(ctx, nil, name, timeCutoff)
 		if err != nil {
 			MessageStats.Add([]string{name, "PurgeFailed"}, 1)
-			log.Errorf("Unable to delete messages: %v", err)
-		} else {
-			MessageStats.Add([]string{name, "Purged"}, count)
+			return total, err
 		}
+		MessageStats.Add([]string{name, "Purged"}, count)
+		total += count
 		// If deleted 500 or more, we should continue.
 		if count < 500 {
-			return
+			return total, nil
 		}
 	}
 }
@@ -903,6 +1039,27 @@ func BuildMessageRow(row []sqltypes.Value) (*MessageRow, error) {
 	return mr, nil
 }
 
+// buildMessageRow builds a MessageRow for a row read by the poller
+// or the vstream, which have time_scheduled after the time_acked
+// column if the table has it.
+func (mm *messageManager) buildMessageRow(row []sqltypes.Value) (*MessageRow, error) {
+	if !mm.hasTimeScheduled {
+		return BuildMessageRow(row)
+	}
+	mr, err := BuildMessageRow(append(row[:4:4], row[5:]...))
+	if err != nil {
+		return nil, err
+	}
+	if !row[4].IsNull() {
+		v, err := evalengine.ToInt64(row[4])
+		if err != nil {
+			return nil, err
+		}
+		mr.TimeScheduled = v
+	}
+	return mr, nil
+}
+
 func (mm *messageManager) receiverCount() int {
 	mm.mu.Lock()
 	defer mm.mu.Unlock()
@@ -31,6 +31,7 @@ import (
 	"vitess.io/vitess/go/test/utils"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
 
 	"vitess.io/vitess/go/sqltypes"
@@ -437,6 +438,51 @@ func TestMessageManagerBatchSend(t *testing.T) {
 	}
 }
 
+func TestMessageManagerMaxSendRate(t *testing.T) {
+	ti := newMMTable()
+	ti.MessageInfo.MaxSendRate = 0.1
+	mm := newMessageManager(newFakeTabletServer(), newFakeVStreamer(), ti, sync2.NewSemaphore(1, 0))
+	mm.Open()
+	defer mm.Close()
+
+	r1 := newTestReceiver(3)
+	mm.Subscribe(context.Background(), r1.rcv)
+	<-r1.ch
+
+	deferred := MessageStats.Counts()["foo.Deferred"]
+	for i := 1; i <= 3; i++ {
+		mm.Add(&MessageRow{Row: []sqltypes.Value{sqltypes.NewVarBinary(fmt.Sprint(i)), sqltypes.NULL}})
+	}
+	got := <-r1.ch
+	assert.Equal(t, "1", got.Rows[0][0].ToString())
+
+	// The other rows wait for the limiter.
+	for MessageStats.Counts()["foo.Deferred"] == deferred {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, deferred+1, MessageStats.Counts()["foo.Deferred"])
+	assert.EqualValues(t, 2, MessageStats.Counts()["foo.Backlog"])
+	select {
+	case got := <-r1.ch:
+		t.Fatalf("Received %v before the send rate allowed it", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// A new rate applies without a reopen.
+	info := *ti.MessageInfo
+	info.MaxSendRate = 0
+	require.True(t, mm.CanUpdate(&schema.Table{MessageInfo: &info}))
+	mm.Update(&info)
+	<-r1.ch
+	<-r1.ch
+	r1.WaitForCount(4)
+	assert.EqualValues(t, 0, MessageStats.Counts()["foo.Backlog"])
+
+	batchChanged := info
+	batchChanged.BatchSize = 2
+	assert.False(t, mm.CanUpdate(&schema.Table{MessageInfo: &batchChanged}))
+}
+
 func TestMessageManagerStreamerSimple(t *testing.T) {
 	fvs := newFakeVStreamer()
 	fvs.setStreamerResponse([][]*binlogdatapb.VEvent{{{
@@ -733,6 +779,76 @@ func TestMessageManagerPurge(t *testing.T) {
 	}
 }
 
+func TestMessageManagerTimeScheduled(t *testing.T) {
+	ti := newMMTable()
+	ti.Fields = []*querypb.Field{
+		{Name: "id", Type: sqltypes.Int64},
+		{Name: "priority", Type: sqltypes.Int64},
+		{Name: "time_next", Type: sqltypes.Int64},
+		{Name: "epoch", Type: sqltypes.Int64},
+		{Name: "time_acked", Type: sqltypes.Int64},
+		{Name: "time_scheduled", Type: sqltypes.Int64},
+		{Name: "message", Type: sqltypes.VarBinary},
+	}
+	mm := newMessageManager(newFakeTabletServer(), newFakeVStreamer(), ti, sync2.NewSemaphore(1, 0))
+	assert.Equal(t, "select priority, time_next, epoch, time_acked, time_scheduled, id, message from foo", mm.vsFilter.Rules[0].Filter)
+	assert.Equal(t,
+		"select priority, time_next, epoch, time_acked, time_scheduled, id, message from foo where time_next < :time_next and (time_scheduled is null or time_scheduled < :time_next) order by priority, time_next desc limit :max",
+		mm.readByPriorityAndTimeNext.Query)
+
+	now := time.Now().UnixNano()
+	mr, err := mm.buildMessageRow([]sqltypes.Value{
+		sqltypes.NewInt64(1),
+		sqltypes.NewInt64(now),
+		sqltypes.NewInt64(2),
+		sqltypes.NULL,
+		sqltypes.NewInt64(now + 1e9),
+		sqltypes.NewInt64(1),
+		sqltypes.NewVarBinary("1"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &MessageRow{
+		Priority:      1,
+		TimeNext:      now,
+		Epoch:         2,
+		TimeScheduled: now + 1e9,
+		Row:           []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarBinary("1")},
+	}, mr)
+
+	// A pending message that's rescheduled in the future
+	// leaves the cache.
+	mm.cache.Add(&MessageRow{Row: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarBinary("1")}})
+	fields := []*querypb.Field{
+		{Type: sqltypes.Int64},
+		{Type: sqltypes.Int64},
+		{Type: sqltypes.Int64},
+		{Type: sqltypes.Int64},
+		{Type: sqltypes.Int64},
+		{Type: sqltypes.Int64},
+		{Type: sqltypes.VarBinary},
+	}
+	err = mm.processRowEvent(fields, &binlogdatapb.RowEvent{
+		TableName: "foo",
+		RowChanges: []*binlogdatapb.RowChange{{
+			After: sqltypes.RowToProto3([]sqltypes.Value{
+				sqltypes.NewInt64(1),
+				sqltypes.NewInt64(now),
+				sqltypes.NewInt64(0),
+				sqltypes.NULL,
+				sqltypes.NewInt64(now + 3600e9),
+				sqltypes.NewInt64(1),
+				sqltypes.NewVarBinary("1"),
+			}),
+		}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, mm.cache.Len())
+	assert.Nil(t, mm.cache.Pop())
+
+	// Adding the column needs a restart of the manager.
+	assert.False(t, mm.CanUpdate(newMMTable()))
+}
+
 func TestMMGenerate(t *testing.T) {
 	mm := newMessageManager(newFakeTabletServer(), newFakeVStreamer(), newMMTable(), sync2.NewSemaphore(1, 0))
 	mm.Open()
@@ -831,6 +947,8 @@ type fakeTabletServer struct {
 
 	mu sync.Mutex
 	ch chan string
+	// purgeRows are returned by the next PurgeMessages calls.
+	purgeRows []int64
 }
 
 func newFakeTabletServer() *fakeTabletServer {
@@ -863,11 +981,14 @@ func (fts *fakeTabletServer) PurgeMessages(ctx context.Context, target *querypb.
 	fts.purgeCount.Add(1)
 	fts.mu.Lock()
 	ch := fts.ch
+	if len(fts.purgeRows) != 0 {
+		count, fts.purgeRows = fts.purgeRows[0], fts.purgeRows[1:]
+	}
 	fts.mu.Unlock()
 	if ch != nil {
 		ch <- "purge"
 	}
-	return 0, nil
+	return count, nil
 }
 
 type fakeVStreamer struct {
@@ -0,0 +1,57 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+)
+
+// probeMySQL is invoked by mysqlProbeTicks. While sm is serving, it
+// checks that mysql is reachable, so that a tablet without traffic
+// notices a dead mysql before the first query fails. If it's not,
+// CheckMySQL takes the usual action. The check runs in the background,
+// and the ticks that come while it's running are skipped.
+func (sm *stateManager) probeMySQL() {
+	if sm.State() != StateServing {
+		return
+	}
+	if !sm.mysqlProbing.CompareAndSwap(false, true) {
+		sm.mysqlProbesSkipped.Add(1)
+		return
+	}
+	go func() {
+		defer sm.recoverPanic()
+		defer sm.mysqlProbing.Set(false)
+
+		start := time.Now()
+		err := sm.qe.IsMySQLReachable(context.TODO(), false)
+		if err == nil {
+			sm.mysqlProbeTimings.Record("Success", start)
+			return
+		}
+		sm.mysqlProbeTimings.Record("Failure", start)
+		sm.mysqlProbeFailures.Add(1)
+		log.Warningf("MySQL probe failed: %v", err)
+		// The service may have been stopped while the probe ran.
+		if sm.State() == StateServing {
+			sm.CheckMySQL()
+		}
+	}()
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sync2"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestStateManagerMySQLProbe(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	var probes sync2.AtomicInt32
+	probed := make(chan bool)
+	release := make(chan error)
+	sm.qe.(*testQueryEngine).reachable = func(checkWrites bool) error {
+		if probes.Add(1) == 1 {
+			probed <- checkWrites
+			return <-release
+		}
+		return errors.New("intentional error")
+	}
+
+	// The probe only runs while serving.
+	sm.probeMySQL()
+	assert.Zero(t, probes.Get())
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	failures, skipped := sm.mysqlProbeFailures.Get(), sm.mysqlProbesSkipped.Get()
+	timedFailures := sm.mysqlProbeTimings.Counts()["StateManagerTest.Failure"]
+	sm.probeMySQL()
+	assert.False(t, <-probed)
+
+	// A slow probe doesn't pile up.
+	sm.probeMySQL()
+	assert.Equal(t, skipped+1, sm.mysqlProbesSkipped.Get())
+	assert.Equal(t, int32(1), probes.Get())
+
+	// A failure shuts down the query service.
+	release <- errors.New("intentional error")
+	for sm.State() != StateNotConnected {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, failures+1, sm.mysqlProbeFailures.Get())
+	assert.Equal(t, timedFailures+1, sm.mysqlProbeTimings.Counts()["StateManagerTest.Failure"])
+	for sm.mysqlProbing.Get() {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStateManagerMySQLProbeStop(t *testing.T) {
+	sm := newTestStateManager(t)
+	sm.mysqlProbeTicks.SetInterval(10 * time.Millisecond)
+	sm.mysqlProbeTicks.Start(sm.probeMySQL)
+	successes := sm.mysqlProbeTimings.Counts()["StateManagerTest.Success"]
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	for sm.mysqlProbeTimings.Counts()["StateManagerTest.Success"] == successes {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	sm.StopService()
+	assert.False(t, sm.mysqlProbeTicks.Running())
+}
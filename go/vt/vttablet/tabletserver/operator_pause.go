@@ -0,0 +1,130 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"fmt"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// pausedReason is the reason reported while an operator
+// paused serving.
+func pausedReason(operator string) string {
+	return fmt.Sprintf("paused by operator %s", operator)
+}
+
+// PauseServing stops serving without changing the tablet type, until
+// ResumeServing is called. Unlike lameduck, the pause isn't undone by
+// the next request to serve: those are overridden by applyPause.
+func (sm *stateManager) PauseServing(operator string) error {
+	if operator == "" {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cannot pause serving: the operator must be named")
+	}
+	sm.mu.Lock()
+	if sm.pausedBy != "" {
+		pausedBy := sm.pausedBy
+		sm.mu.Unlock()
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "serving is already paused by operator %s", pausedBy)
+	}
+	tabletType, terTimestamp, wantState := sm.wantTabletType, sm.terTimestamp, sm.wantState
+	if !wantState.serving() {
+		sm.mu.Unlock()
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot pause serving: tablet is not serving")
+	}
+	sm.pausedBy = operator
+	sm.mu.Unlock()
+
+	log.Infof("Serving paused by operator %s", operator)
+	// The request is overridden by applyPause.
+	return sm.SetServingType(context.Background(), tabletType, terTimestamp, wantState, "")
+}
+
+// ResumeServing ends the pause started by PauseServing. The tablet
+// goes to the state that was last requested. If that's a serving
+// state, mysql must be reachable and the replication healthy first.
+// Otherwise, the tablet remains paused.
+func (sm *stateManager) ResumeServing(operator string) error {
+	sm.mu.Lock()
+	if sm.pausedBy == "" {
+		sm.mu.Unlock()
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot resume serving: serving is not paused")
+	}
+	tabletType, terTimestamp, state := sm.wantTabletType, sm.terTimestamp, sm.pausedWantState
+	sm.mu.Unlock()
+
+	if state.serving() {
+		if err := sm.checkResume(tabletType, state); err != nil {
+			return err
+		}
+	}
+
+	sm.mu.Lock()
+	sm.pausedBy = ""
+	sm.mu.Unlock()
+	log.Infof("Serving resumed by operator %s", operator)
+	return sm.SetServingType(context.Background(), tabletType, terTimestamp, state, "")
+}
+
+// checkResume runs the checks that must pass for a paused tablet
+// to serve again.
+func (sm *stateManager) checkResume(tabletType topodatapb.TabletType, state servingState) error {
+	checkWrites := tabletType == topodatapb.TabletType_MASTER && state == StateServing
+	if err := sm.qe.IsMySQLReachable(context.TODO(), checkWrites); err != nil {
+		return vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "cannot resume serving: mysql is not reachable: %v", err)
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if tabletType == topodatapb.TabletType_MASTER {
+		return nil
+	}
+	sm.refreshReplHealthLocked()
+	if !sm.replHealthy {
+		return vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "cannot resume serving: replication is unhealthy: %s", sm.replUnhealthyCauseLocked())
+	}
+	return nil
+}
+
+// applyPause overrides a request to serve while an operator paused
+// serving. The requested state is recorded for ResumeServing.
+func (sm *stateManager) applyPause(state servingState, reason string) (servingState, string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.pausedBy == "" {
+		return state, reason
+	}
+	sm.pausedWantState = state
+	if state.serving() {
+		return StateNotServing, pausedReason(sm.pausedBy)
+	}
+	return state, reason
+}
+
+// PausedBy returns the operator who paused serving,
+// or "" if serving isn't paused.
+func (sm *stateManager) PausedBy() string {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.pausedBy
+}
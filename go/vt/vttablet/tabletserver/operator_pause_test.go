@@ -0,0 +1,145 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestStateManagerPauseServing(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+
+	err := sm.PauseServing("alice")
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	err = sm.ResumeServing("alice")
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	err = sm.PauseServing("")
+	assert.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err))
+	require.NoError(t, sm.PauseServing("alice"))
+	err = sm.PauseServing("bob")
+	assert.EqualError(t, err, "serving is already paused by operator alice")
+
+	// The tablet keeps its type, and the pause is reported.
+	assert.Equal(t, topodatapb.TabletType_REPLICA, sm.Target().TabletType)
+	assert.Equal(t, StateNotServing, sm.State())
+	assert.Equal(t, "alice", sm.PausedBy())
+	assert.Equal(t, "alice", sm.Status().PausedBy)
+	sm.Broadcast()
+	sm.hs.mu.Lock()
+	assert.False(t, sm.hs.state.Serving)
+	assert.Equal(t, "not serving: paused by operator alice", sm.hs.state.RealtimeStats.HealthError)
+	sm.hs.mu.Unlock()
+
+	// Requests to serve are overridden, even if the type changes.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_RDONLY, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.Equal(t, topodatapb.TabletType_RDONLY, sm.Target().TabletType)
+	assert.Equal(t, StateNotServing, sm.State())
+
+	require.NoError(t, sm.ResumeServing("bob"))
+	assert.Equal(t, topodatapb.TabletType_RDONLY, sm.Target().TabletType)
+	assert.Equal(t, StateServing, sm.State())
+	assert.Equal(t, "", sm.PausedBy())
+	assert.Equal(t, "", sm.reason)
+}
+
+func TestStateManagerResumeServingChecks(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	require.NoError(t, sm.PauseServing("alice"))
+
+	// The tablet remains paused until mysql and the replication
+	// are healthy again.
+	sm.qe.(*testQueryEngine).reachable = func(bool) error { return errors.New("mysql down") }
+	err = sm.ResumeServing("alice")
+	assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err))
+	assert.EqualError(t, err, "cannot resume serving: mysql is not reachable: mysql down")
+	assert.Equal(t, "alice", sm.PausedBy())
+	assert.Equal(t, StateNotServing, sm.State())
+
+	sm.qe.(*testQueryEngine).reachable = nil
+	sm.rt.(*testReplTracker).err = errors.New("replication stopped")
+	err = sm.ResumeServing("alice")
+	assert.EqualError(t, err, "cannot resume serving: replication is unhealthy: replication stopped")
+	assert.Equal(t, "alice", sm.PausedBy())
+
+	sm.rt.(*testReplTracker).err = nil
+	require.NoError(t, sm.ResumeServing("alice"))
+	assert.Equal(t, StateServing, sm.State())
+
+	// A request to stop serving while paused is honored on resume.
+	require.NoError(t, sm.PauseServing("alice"))
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateNotServing, "drain")
+	require.NoError(t, err)
+	assert.Equal(t, "drain", sm.reason)
+	sm.rt.(*testReplTracker).err = errors.New("replication stopped")
+	require.NoError(t, sm.ResumeServing("alice"))
+	assert.Equal(t, StateNotServing, sm.State())
+}
+
+func TestTabletServerPauseServing(t *testing.T) {
+	db, tsv := setupTabletServerTest(t)
+	defer tsv.StopService()
+	defer db.Close()
+	target := querypb.Target{TabletType: topodatapb.TabletType_MASTER}
+
+	action := func(name, operator string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest("POST", tsv.exporter.URLPrefix()+"/debug/serving/"+name+"?operator="+operator, nil)
+		response := httptest.NewRecorder()
+		http.DefaultServeMux.ServeHTTP(response, request)
+		return response
+	}
+	response := action("pause", "alice")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, StateNotServing, tsv.sm.State())
+	assert.Equal(t, topodatapb.TabletType_MASTER, tsv.sm.Target().TabletType)
+
+	_, _, err := tsv.Begin(ctx, &target, nil)
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+
+	// Tablet type refreshes don't end the pause.
+	err = tsv.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, true, "")
+	require.NoError(t, err)
+	assert.Equal(t, StateNotServing, tsv.sm.State())
+
+	response = action("resume", "alice")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, StateServing, tsv.sm.State())
+	txid, _, err := tsv.Begin(ctx, &target, nil)
+	require.NoError(t, err)
+	_, err = tsv.Rollback(ctx, &target, txid)
+	require.NoError(t, err)
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+// planWarmupVersion must be incremented every time the
+// layout of planWarmup changes incompatibly.
+const planWarmupVersion = 1
+
+// planWarmup is the content of the plan warmup file: the queries
+// of the most executed plans, most executed first.
+type planWarmup struct {
+	Version int       `json:"version"`
+	Time    time.Time `json:"time"`
+	Queries []string  `json:"queries"`
+}
+
+// topPlanQueries returns the keys of the n most executed
+// cached plans, most executed first.
+func (qe *QueryEngine) topPlanQueries(n int) []string {
+	type entry struct {
+		query string
+		count int64
+	}
+	var entries []entry
+	for _, item := range qe.plans.Items() {
+		plan, _ := item.Value.(*TabletPlan)
+		if plan == nil {
+			continue
+		}
+		count, _, _, _, _ := plan.Stats()
+		entries = append(entries, entry{query: item.Key, count: count})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].count > entries[j].count })
+	if len(entries) > n {
+		entries = entries[:n]
+	}
+	queries := make([]string, 0, len(entries))
+	for _, e := range entries {
+		queries = append(queries, e.query)
+	}
+	return queries
+}
+
+// savePlanWarmup saves the queries of the most executed plans to
+// the warmup file. An empty cache doesn't overwrite the file, so
+// that a restarted tablet still finds the queries of its previous
+// process. The file is replaced atomically.
+func (qe *QueryEngine) savePlanWarmup() {
+	queries := qe.topPlanQueries(qe.planWarmup.Count)
+	if len(queries) == 0 {
+		return
+	}
+	b, err := json.Marshal(&planWarmup{
+		Version: planWarmupVersion,
+		Time:    time.Now(),
+		Queries: queries,
+	})
+	if err != nil {
+		log.Warningf("Query Engine: cannot encode the plan warmup: %v", err)
+		return
+	}
+	tmp := qe.planWarmup.File + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		log.Warningf("Query Engine: cannot save the plan warmup: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, qe.planWarmup.File); err != nil {
+		log.Warningf("Query Engine: cannot save the plan warmup: %v", err)
+	}
+}
+
+// WarmPlans builds the plans of the queries saved in the warmup
+// file, most executed first, until ctx expires. It returns the
+// number of plans built. A missing file is not an error, and the
+// queries that fail to plan are skipped.
+func (qe *QueryEngine) WarmPlans(ctx context.Context) (int, error) {
+	if !qe.planWarmup.Enabled() {
+		return 0, nil
+	}
+	b, err := ioutil.ReadFile(qe.planWarmup.File)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	warmup := &planWarmup{}
+	if err := json.Unmarshal(b, warmup); err != nil {
+		return 0, fmt.Errorf("invalid plan warmup file %s: %v", qe.planWarmup.File, err)
+	}
+	if warmup.Version != planWarmupVersion {
+		return 0, fmt.Errorf("plan warmup file %s has version %d, want %d", qe.planWarmup.File, warmup.Version, planWarmupVersion)
+	}
+	queries := warmup.Queries
+	if len(queries) > qe.planWarmup.Count {
+		queries = queries[:qe.planWarmup.Count]
+	}
+	built, failed := 0, 0
+	for _, query := range queries {
+		if err := ctx.Err(); err != nil {
+			return built, err
+		}
+		logStats := tabletenv.NewLogStats(ctx, "WarmPlans")
+		if _, err := qe.GetPlan(ctx, logStats, query, false, false); err != nil {
+			failed++
+			continue
+		}
+		built++
+	}
+	if failed != 0 {
+		log.Infof("Query Engine: %d of %d warmup queries could not be planned", failed, len(queries))
+	}
+	return built, nil
+}
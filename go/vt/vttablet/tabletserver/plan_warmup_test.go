@@ -0,0 +1,144 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/fakesqldb"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema/schematest"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+func TestQueryEnginePlanWarmup(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	for query, result := range schematest.Queries() {
+		db.AddQuery(query, result)
+	}
+	dir, err := ioutil.TempDir("", "plan_warmup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := path.Join(dir, "plans.json")
+
+	qe := newTestQueryEngine(10, 10*time.Second, true, newDBConfigs(db))
+	qe.planWarmup = tabletenv.PlanWarmupConfig{Count: 2, File: file}
+	qe.se.Open(context.Background())
+	qe.Open(context.Background())
+	defer qe.Close()
+
+	ctx := context.Background()
+	counts := map[string]int64{
+		"select * from test_table_01": 5,
+		"select * from test_table_02": 1,
+		"select * from test_table_03": 10,
+	}
+	for query, count := range counts {
+		plan, err := qe.GetPlan(ctx, tabletenv.NewLogStats(ctx, "Test"), query, false, false)
+		require.NoError(t, err)
+		plan.AddStats(count, 0, 0, 0, 0)
+	}
+
+	qe.savePlanWarmup()
+	b, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+	warmup := &planWarmup{}
+	require.NoError(t, json.Unmarshal(b, warmup))
+	want := []string{"select * from test_table_03", "select * from test_table_01"}
+	assert.Equal(t, want, warmup.Queries)
+
+	// An empty cache doesn't overwrite the file.
+	qe.ClearQueryPlanCache()
+	qe.savePlanWarmup()
+	b2, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, b, b2)
+
+	built, err := qe.WarmPlans(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, built)
+	assert.NotNil(t, qe.peekQuery("select * from test_table_03"))
+	assert.NotNil(t, qe.peekQuery("select * from test_table_01"))
+	assert.Nil(t, qe.peekQuery("select * from test_table_02"))
+
+	// The warmup stops at the deadline.
+	qe.ClearQueryPlanCache()
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	built, err = qe.WarmPlans(cancelled)
+	assert.Equal(t, context.Canceled, err)
+	assert.Zero(t, built)
+
+	// Queries that can't be planned are skipped.
+	require.NoError(t, ioutil.WriteFile(file, []byte(`{"version":1,"queries":["select * from unknown syntax (","select * from test_table_02"]}`), 0644))
+	built, err = qe.WarmPlans(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, built)
+
+	require.NoError(t, ioutil.WriteFile(file, []byte(`{"version":2}`), 0644))
+	_, err = qe.WarmPlans(ctx)
+	assert.Contains(t, err.Error(), "has version 2, want 1")
+
+	require.NoError(t, os.Remove(file))
+	built, err = qe.WarmPlans(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, built)
+}
+
+func TestStateManagerPlanWarmup(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	qe := sm.qe.(*testQueryEngine)
+	sm.planWarmupTimeout = time.Second
+
+	// The warmup runs before the master serves, and its
+	// failures don't fail the transition.
+	qe.warmPlans = func(ctx context.Context) (int, error) {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.False(t, sm.IsServing())
+		return 3, errors.New("intentional error")
+	}
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, qe.warmups)
+	assert.Equal(t, StateServing, sm.state)
+	assert.Equal(t, "open", sm.Status().subcomponent("qe"))
+
+	// Only a serving master is warmed up.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateNotServing, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, qe.warmups)
+
+	sm.planWarmupTimeout = 0
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, qe.warmups)
+}
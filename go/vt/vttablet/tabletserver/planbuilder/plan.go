@@ -114,6 +114,16 @@ func (pt PlanType) IsSelect() bool {
 	return pt == PlanSelect || pt == PlanSelectLock || pt == PlanSelectImpossible
 }
 
+// IsWrite returns true if PlanType is about a query that changes
+// rows or the schema.
+func (pt PlanType) IsWrite() bool {
+	switch pt {
+	case PlanInsert, PlanInsertMessage, PlanUpdate, PlanUpdateLimit, PlanDelete, PlanDeleteLimit, PlanDDL:
+		return true
+	}
+	return false
+}
+
 // MarshalJSON returns a json string for PlanType.
 func (pt PlanType) MarshalJSON() ([]byte, error) {
 	return json.Marshal(pt.String())
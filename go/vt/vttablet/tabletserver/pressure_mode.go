@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"fmt"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+)
+
+// pressureMode is how the query engine handles the transactions
+// that wait for a hot row.
+type pressureMode int
+
+const (
+	// pressureQueue queues the transactions. It's the default.
+	pressureQueue = pressureMode(iota)
+	// pressureFailFast rejects the transactions right away, so that
+	// they don't pile up while the tablet is under pressure.
+	pressureFailFast
+)
+
+// pressureExitPercent is the txpool usage, in percent, below which
+// the pool doesn't count as exhausted anymore.
+const pressureExitPercent = 80
+
+func (mode pressureMode) String() string {
+	if mode == pressureFailFast {
+		return "fail fast"
+	}
+	return "queue"
+}
+
+// refreshPressureLocked pushes the pressure mode to qe if it changed.
+// The tablet is under pressure if the replication lag exceeds the
+// degraded threshold, or if the txpool is exhausted. The conditions
+// to leave the fail fast mode are stricter than the ones to enter it:
+// the lag must be back under half the threshold, and the txpool usage
+// under pressureExitPercent. This prevents the mode from flipping at
+// every broadcast while the tablet hovers around the thresholds.
+func (sm *stateManager) refreshPressureLocked(lag time.Duration) {
+	if !sm.failFastUnderPressure {
+		return
+	}
+	pu := sm.poolUsage()
+	degradedThreshold := sm.live.DegradedThreshold()
+	var cause string
+	if sm.pressure == pressureQueue {
+		switch {
+		case lag > degradedThreshold:
+			cause = fmt.Sprintf("replication lag %v exceeds %v", lag, degradedThreshold)
+		case pu.txCapacity > 0 && pu.txInUse >= pu.txCapacity:
+			cause = fmt.Sprintf("txpool exhausted: %d of %d in use", pu.txInUse, pu.txCapacity)
+		default:
+			return
+		}
+		sm.setPressureLocked(pressureFailFast, cause)
+		return
+	}
+	if lag > degradedThreshold/2 {
+		return
+	}
+	if pu.txCapacity > 0 && pu.txInUse*100 >= pu.txCapacity*pressureExitPercent {
+		return
+	}
+	cause = fmt.Sprintf("replication lag %v, txpool %d of %d in use", lag, pu.txInUse, pu.txCapacity)
+	sm.setPressureLocked(pressureQueue, cause)
+}
+
+func (sm *stateManager) setPressureLocked(mode pressureMode, cause string) {
+	if mode == pressureFailFast {
+		log.Warningf("Hot row protection switching to %v: %s", mode, cause)
+	} else {
+		log.Infof("Hot row protection switching to %v: %s", mode, cause)
+	}
+	sm.pressure, sm.pressureCause = mode, cause
+	sm.qe.SetPressureMode(mode)
+}
+
+func (sm *stateManager) pressureGauge() int64 {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.pressure == pressureFailFast {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestStateManagerPressureLagEpisode(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	sm.failFastUnderPressure = true
+	qe := sm.qe.(*testQueryEngine)
+	rt := sm.rt.(*testReplTracker)
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.Empty(t, qe.pressures)
+
+	// The lag builds up, but stays under the degraded threshold.
+	rt.lag = 20 * time.Second
+	sm.Broadcast()
+	assert.Empty(t, qe.pressures)
+
+	rt.lag = 40 * time.Second
+	sm.Broadcast()
+	assert.Equal(t, []pressureMode{pressureFailFast}, qe.pressures)
+	status := sm.Status()
+	assert.Equal(t, "fail fast", status.PressureMode)
+	assert.Equal(t, "replication lag 40s exceeds 30s", status.PressureCause)
+	assert.Contains(t, detailKeys(sm), "Hot Row Protection")
+
+	// Modes are only pushed on change.
+	rt.lag = 50 * time.Second
+	sm.Broadcast()
+	assert.Len(t, qe.pressures, 1)
+
+	// Dropping under the threshold isn't enough to leave
+	// the fail fast mode.
+	rt.lag = 20 * time.Second
+	sm.Broadcast()
+	assert.Len(t, qe.pressures, 1)
+
+	rt.lag = 10 * time.Second
+	sm.Broadcast()
+	assert.Equal(t, []pressureMode{pressureFailFast, pressureQueue}, qe.pressures)
+	status = sm.Status()
+	assert.Equal(t, "queue", status.PressureMode)
+	assert.Equal(t, "replication lag 10s, txpool 0 of 0 in use", status.PressureCause)
+	assert.NotContains(t, detailKeys(sm), "Hot Row Protection")
+
+	// And going back up to the threshold doesn't enter it again.
+	rt.lag = 30 * time.Second
+	sm.Broadcast()
+	assert.Len(t, qe.pressures, 2)
+}
+
+func TestStateManagerPressureTxPool(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	sm.failFastUnderPressure = true
+	qe := sm.qe.(*testQueryEngine)
+	te := sm.te.(*testTxEngine)
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	te.inUse, te.capacity = 10, 10
+	sm.Broadcast()
+	assert.Equal(t, []pressureMode{pressureFailFast}, qe.pressures)
+	assert.Equal(t, int64(1), sm.pressureGauge())
+	assert.Equal(t, "txpool exhausted: 10 of 10 in use", sm.Status().PressureCause)
+
+	te.inUse = 8
+	sm.Broadcast()
+	assert.Len(t, qe.pressures, 1)
+
+	te.inUse = 7
+	sm.Broadcast()
+	assert.Equal(t, []pressureMode{pressureFailFast, pressureQueue}, qe.pressures)
+	assert.Equal(t, int64(0), sm.pressureGauge())
+}
+
+func TestStateManagerPressureDisabled(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	qe := sm.qe.(*testQueryEngine)
+	te := sm.te.(*testTxEngine)
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	te.inUse, te.capacity = 10, 10
+	sm.Broadcast()
+	assert.Empty(t, qe.pressures)
+	assert.Equal(t, "queue", sm.Status().PressureMode)
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// promotionVerdict tells external failover tools if the tablet would
+// currently be a viable target for a reparent. It's refreshed at every
+// broadcast rather than on demand, so that it's cheap to poll. The part
+// of the checks that needs mysql runs at the health check interval.
+// Callers that need a fresh verdict can compare its age with it.
+type promotionVerdict struct {
+	Promotable bool `json:"promotable"`
+	// Blockers lists the checks that failed, if any.
+	Blockers []string `json:"blockers,omitempty"`
+	// CheckedAt is the time of the last transition dry run.
+	CheckedAt time.Time `json:"checkedAt"`
+	// AgeSeconds is the time elapsed since CheckedAt. It's
+	// only set by PromotionVerdict.
+	AgeSeconds float64 `json:"ageSeconds"`
+}
+
+// checkPromotion runs the transition dry run of a promotion. It's
+// called by promotionTicks if -enable_promotion_check is set.
+func (sm *stateManager) checkPromotion() {
+	err := sm.promotionDryRun()
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.promotionCheckErr, sm.promotionCheckedAt = err, sm.clock.Now()
+}
+
+// promotionDryRun checks that mysql could serve the tablet as a
+// master. Unlike canTransition, it doesn't hold transitioning, so
+// that it never delays a transition, and it never writes: the writes
+// are not probed. Its mysql check is bounded by the check interval.
+func (sm *stateManager) promotionDryRun() error {
+	sm.mu.Lock()
+	state, topoIsolated := sm.state, sm.topoIsolated
+	sm.mu.Unlock()
+	switch {
+	case state == StateNotConnected:
+		return errors.New("not connected to mysql")
+	case topoIsolated:
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot transition to MASTER Serving: the master is isolated from the topo")
+	}
+
+	ctx, cancel := context.WithTimeout(tabletenv.LocalContext(), sm.promotionTicks.Interval())
+	defer cancel()
+	if err := sm.qe.IsMySQLReachable(ctx, false); err != nil {
+		// A new master creates the database if it's missing.
+		if sqlErr, ok := err.(*mysql.SQLError); !ok || sqlErr.Num != mysql.ERBadDb {
+			return vterrors.Wrap(err, "cannot transition to MASTER Serving: mysql cannot serve")
+		}
+	}
+	return nil
+}
+
+// refreshPromotionLocked combines the result of the last transition
+// dry run with the state of sm into a new verdict. It's called from
+// broadcastLocked so that the verdict is published with the
+// replication health it was computed from. There's no verdict
+// until the first dry run.
+func (sm *stateManager) refreshPromotionLocked(lag time.Duration, replErr error) {
+	if sm.promotionCheckedAt.IsZero() {
+		return
+	}
+	var blockers []string
+	switch sm.wantTabletType {
+	case topodatapb.TabletType_MASTER, topodatapb.TabletType_REPLICA:
+	default:
+		blockers = append(blockers, fmt.Sprintf("tablet type: %v tablets are not promoted", sm.wantTabletType))
+	}
+	if sm.lameduck {
+		blockers = append(blockers, "lameduck: the tablet is shutting down")
+	}
+	if sm.state == StateServingReadOnly {
+		blockers = append(blockers, "maintenance: the tablet serves reads only")
+	}
+	switch {
+	case replErr != nil:
+		blockers = append(blockers, fmt.Sprintf("replication: %v", replErr))
+	case lag > sm.live.DegradedThreshold():
+		blockers = append(blockers, fmt.Sprintf("replication: lag %v exceeds %v", lag, sm.live.DegradedThreshold()))
+	}
+	if sm.promotionCheckErr != nil {
+		blockers = append(blockers, fmt.Sprintf("transition: %v", sm.promotionCheckErr))
+	}
+	sm.promotion = &promotionVerdict{
+		Promotable: len(blockers) == 0,
+		Blockers:   blockers,
+		CheckedAt:  sm.promotionCheckedAt,
+	}
+	sm.hs.SetPromotion(sm.promotion.Promotable, blockers)
+}
+
+// PromotionVerdict returns the last promotion verdict with its age.
+// It returns nil if no check ran yet.
+func (sm *stateManager) PromotionVerdict() *promotionVerdict {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.promotion == nil {
+		return nil
+	}
+	pv := *sm.promotion
+	pv.AgeSeconds = sm.clock.Now().Sub(pv.CheckedAt).Seconds()
+	return &pv
+}
+
+func (sm *stateManager) promotableGauge() int64 {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.promotion != nil && sm.promotion.Promotable {
+		return 1
+	}
+	return 0
+}
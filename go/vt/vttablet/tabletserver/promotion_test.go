@@ -0,0 +1,186 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/timer/fakeclock"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// promotionTick runs the promotion check, then broadcasts.
+func promotionTick(sm *stateManager) {
+	sm.checkPromotion()
+	sm.Broadcast()
+}
+
+func TestStateManagerPromotionVerdict(t *testing.T) {
+	fc := fakeclock.New(testNow)
+	sm := newTestStateManagerWithClock(t, fc)
+	defer sm.StopService()
+	rt := sm.rt.(*testReplTracker)
+
+	assert.Nil(t, sm.PromotionVerdict())
+	promotionTick(sm)
+	assert.Equal(t, []string{
+		"tablet type: UNKNOWN tablets are not promoted",
+		"transition: not connected to mysql",
+	}, sm.PromotionVerdict().Blockers)
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	promotionTick(sm)
+	pv := sm.PromotionVerdict()
+	require.NotNil(t, pv)
+	assert.True(t, pv.Promotable)
+	assert.Empty(t, pv.Blockers)
+	assert.Equal(t, testNow, pv.CheckedAt)
+	assert.Equal(t, float64(0), pv.AgeSeconds)
+	assert.Equal(t, int64(1), sm.promotableGauge())
+	assert.True(t, sm.hs.state.RealtimeStats.Promotable)
+
+	// Broadcasts refresh the verdict without a new dry run:
+	// it ages.
+	rt.lag = 40 * time.Second
+	fc.Advance(5 * time.Second)
+	sm.Broadcast()
+	pv = sm.PromotionVerdict()
+	assert.False(t, pv.Promotable)
+	assert.Equal(t, []string{"replication: lag 40s exceeds 30s"}, pv.Blockers)
+	assert.Equal(t, float64(5), pv.AgeSeconds)
+	assert.Equal(t, int64(0), sm.promotableGauge())
+	assert.False(t, sm.hs.state.RealtimeStats.Promotable)
+	assert.Equal(t, pv.Blockers, sm.hs.state.RealtimeStats.PromotionBlockers)
+
+	rt.lag = 0
+	rt.err = errors.New("replication stopped")
+	promotionTick(sm)
+	pv = sm.PromotionVerdict()
+	assert.Equal(t, []string{"replication: replication stopped"}, pv.Blockers)
+	assert.Equal(t, float64(0), pv.AgeSeconds)
+
+	// mysql fails the transition dry run.
+	rt.err = nil
+	sm.qe.(*testQueryEngine).failMySQL = true
+	promotionTick(sm)
+	assert.Equal(t, []string{"transition: intentional error\ncannot transition to MASTER Serving: mysql cannot serve"}, sm.PromotionVerdict().Blockers)
+
+	// Several checks can fail at once.
+	sm.EnterLameduck()
+	sm.wantTabletType = topodatapb.TabletType_RDONLY
+	promotionTick(sm)
+	assert.Equal(t, []string{
+		"tablet type: RDONLY tablets are not promoted",
+		"lameduck: the tablet is shutting down",
+	}, sm.PromotionVerdict().Blockers)
+	sm.ExitLameduck()
+	sm.wantTabletType = topodatapb.TabletType_REPLICA
+
+	promotionTick(sm)
+	assert.True(t, sm.PromotionVerdict().Promotable)
+	assert.Equal(t, int64(1), sm.promotableGauge())
+
+	// The dry run doesn't wait for a transition.
+	sm.qe.(*testQueryEngine).failMySQL = true
+	sm.transitioning.Acquire()
+	fc.Advance(3 * time.Second)
+	sm.checkPromotion()
+	sm.transitioning.Release()
+	sm.Broadcast()
+	pv = sm.PromotionVerdict()
+	assert.False(t, pv.Promotable)
+	assert.Equal(t, float64(0), pv.AgeSeconds)
+}
+
+func TestStateManagerPromotionCheckDoesNotWrite(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	// The check is opt-in.
+	assert.False(t, sm.promotionTicks.Running())
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	var probedWrites bool
+	sm.qe.(*testQueryEngine).reachable = func(checkWrites bool) error {
+		probedWrites = probedWrites || checkWrites
+		return nil
+	}
+	promotionTick(sm)
+	assert.True(t, sm.PromotionVerdict().Promotable)
+	assert.False(t, probedWrites)
+}
+
+func TestStateManagerPromotionMaintenance(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	err = sm.SetMaintenance(true)
+	require.NoError(t, err)
+	promotionTick(sm)
+	assert.Equal(t, []string{"maintenance: the tablet serves reads only"}, sm.PromotionVerdict().Blockers)
+	assert.Equal(t, sm.PromotionVerdict().Blockers, sm.Status().Promotion.Blockers)
+}
+
+func TestPromotableHandler(t *testing.T) {
+	db, tsv := setupTabletServerTest(t)
+	defer tsv.StopService()
+	defer db.Close()
+
+	get := func() (int, *promotionVerdict) {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", tsv.exporter.URLPrefix()+"/debug/promotable", nil)
+		http.DefaultServeMux.ServeHTTP(rr, req)
+		pv := &promotionVerdict{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), pv))
+		return rr.Code, pv
+	}
+
+	tsv.sm.mu.Lock()
+	tsv.sm.promotion = nil
+	tsv.sm.mu.Unlock()
+	code, pv := get()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, []string{"no promotion check ran yet: it's enabled by -enable_promotion_check"}, pv.Blockers)
+
+	tsv.sm.mu.Lock()
+	tsv.sm.promotion = &promotionVerdict{Promotable: true, CheckedAt: time.Now()}
+	tsv.sm.mu.Unlock()
+	code, pv = get()
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, pv.Promotable)
+
+	tsv.sm.mu.Lock()
+	tsv.sm.promotion = &promotionVerdict{Blockers: []string{"lameduck: the tablet is shutting down"}, CheckedAt: time.Now()}
+	tsv.sm.mu.Unlock()
+	code, pv = get()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, []string{"lameduck: the tablet is shutting down"}, pv.Blockers)
+}
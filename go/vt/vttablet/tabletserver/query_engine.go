@@ -28,9 +28,11 @@ import (
 
 	"vitess.io/vitess/go/acl"
 	"vitess.io/vitess/go/cache"
+	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/streamlog"
 	"vitess.io/vitess/go/sync2"
+	"vitess.io/vitess/go/timer"
 	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/dbconnpool"
 	"vitess.io/vitess/go/vt/log"
@@ -48,6 +50,43 @@ import (
 	querypb "vitess.io/vitess/go/vt/proto/query"
 )
 
+const (
+	sqlCreateWriteProbeTable = `create table if not exists _vt.write_probe (
+  id int unsigned not null primary key,
+  writes bigint unsigned not null
+) engine=InnoDB`
+	// sqlWriteProbe changes the row every time, so that it
+	// always needs to be persisted.
+	sqlWriteProbe = "insert into _vt.write_probe (id, writes) values (1, 1) on duplicate key update writes=writes+1"
+)
+
+// mysqlCheckTimeout bounds IsMySQLReachable. A full disk usually
+// blocks the writes instead of failing them: a write probe that
+// times out is a write error. It's a var for tests.
+var mysqlCheckTimeout = 10 * time.Second
+
+// mysqlWriteError is returned by IsMySQLReachable if MySQL
+// can be read from, but fails writes.
+type mysqlWriteError struct {
+	err error
+}
+
+func (e *mysqlWriteError) Error() string {
+	return fmt.Sprintf("mysql is not accepting writes: %v", e.err)
+}
+
+// isMySQLWriteErr returns true if the error means that MySQL
+// cannot persist writes, typically because its disk is full.
+func isMySQLWriteErr(err error) bool {
+	if sqlErr, ok := err.(*mysql.SQLError); ok {
+		switch sqlErr.Number() {
+		case mysql.ERDiskFull, mysql.ERRecordFileFull:
+			return true
+		}
+	}
+	return false
+}
+
 //_______________________________________________
 
 // TabletPlan wraps the planbuilder's exec plan to enforce additional rules
@@ -135,6 +174,9 @@ type QueryEngine struct {
 	// For implementation details, please see BeginExecute() in tabletserver.go.
 	txSerializer *txserializer.TxSerializer
 	streamQList  *QueryList
+	// queryList tracks the non-streaming queries that
+	// are running against mysql.
+	queryList *QueryList
 
 	// Vars
 	maxResultSize    sync2.AtomicInt64
@@ -150,11 +192,36 @@ type QueryEngine struct {
 
 	strictTransTables bool
 
-	consolidatorMode            string
+	// consolidatorMode is set by the state manager to the mode
+	// of the tablet type.
+	consolidatorMode            sync2.AtomicString
 	enableQueryPlanFieldCaching bool
 
+	// live provides the max result size overrides of the tables.
+	// It's shared with the TabletServer, so that they're kept
+	// across reopens.
+	live *liveConfig
+
+	// planWarmup configures the saving of the most executed
+	// plans, which are built again by WarmPlans. planWarmupSaver
+	// saves them periodically while qe is open.
+	planWarmup      tabletenv.PlanWarmupConfig
+	planWarmupSaver *timer.Timer
+
 	// stats
 	queryCounts, queryTimes, queryRowCounts, queryErrorCounts *stats.CountersWithMultiLabels
+	// consolidations counts the queries that went through the
+	// consolidator, by tablet type and whether they were
+	// consolidated.
+	consolidations *stats.CountersWithMultiLabels
+	// queryConnErrorCounts counts the queries that failed with
+	// connection errors, by table and plan.
+	queryConnErrorCounts *stats.CountersWithMultiLabels
+
+	// connErrorsMu protects connErrors, which records when the
+	// queries of each table last failed with a connection error.
+	connErrorsMu sync.Mutex
+	connErrors   map[string]time.Time
 
 	// Loggers
 	accessCheckerLogger *logutil.ThrottledLogger
@@ -171,15 +238,22 @@ func NewQueryEngine(env tabletenv.Env, se *schema.Engine) *QueryEngine {
 		tables:           make(map[string]*schema.Table),
 		plans:            cache.NewLRUCache(int64(config.QueryCacheSize)),
 		queryRuleSources: rules.NewMap(),
+		live:             newLiveConfig(config),
+		connErrors:       make(map[string]time.Time),
 	}
 
 	qe.conns = connpool.NewPool(env, "ConnPool", config.OltpReadPool)
 	qe.streamConns = connpool.NewPool(env, "StreamConnPool", config.OlapReadPool)
-	qe.consolidatorMode = config.Consolidator
+	qe.consolidatorMode.Set(config.Consolidator)
 	qe.enableQueryPlanFieldCaching = config.CacheResultFields
+	qe.planWarmup = config.PlanWarmup
+	if qe.planWarmup.Enabled() {
+		qe.planWarmupSaver = timer.NewTimer(qe.planWarmup.SaveIntervalSeconds.Get())
+	}
 	qe.consolidator = sync2.NewConsolidator()
 	qe.txSerializer = txserializer.New(env)
 	qe.streamQList = NewQueryList()
+	qe.queryList = NewQueryList()
 
 	qe.strictTableACL = config.StrictTableACL
 	qe.enableTableACLDryRun = config.EnableTableACLDryRun
@@ -223,8 +297,12 @@ func NewQueryEngine(env tabletenv.Env, se *schema.Engine) *QueryEngine {
 	qe.queryTimes = env.Exporter().NewCountersWithMultiLabels("QueryTimesNs", "query times in ns", []string{"Table", "Plan"})
 	qe.queryRowCounts = env.Exporter().NewCountersWithMultiLabels("QueryRowCounts", "query row counts", []string{"Table", "Plan"})
 	qe.queryErrorCounts = env.Exporter().NewCountersWithMultiLabels("QueryErrorCounts", "query error counts", []string{"Table", "Plan"})
+	env.Exporter().NewRates("QueryErrorRates", qe.queryErrorCounts, 15*60/5, 5*time.Second)
+	qe.queryConnErrorCounts = env.Exporter().NewCountersWithMultiLabels("QueryConnErrorCounts", "Count of the queries that failed with connection errors", []string{"Table", "Plan"})
+	qe.consolidations = env.Exporter().NewCountersWithMultiLabels("ConsolidatorQueries", "Count of the queries that went through the consolidator, by tablet type and whether they were consolidated", []string{"TabletType", "Result"})
 
 	env.Exporter().HandleFunc("/debug/hotrows", qe.txSerializer.ServeHTTP)
+	env.Exporter().HandleFunc("/debug/hotrows/keys", qe.txSerializer.ServeKeysHTTP)
 	env.Exporter().HandleFunc("/debug/tablet_plans", qe.handleHTTPQueryPlans)
 	env.Exporter().HandleFunc("/debug/query_stats", qe.handleHTTPQueryStats)
 	env.Exporter().HandleFunc("/debug/query_rules", qe.handleHTTPQueryRules)
@@ -235,7 +313,9 @@ func NewQueryEngine(env tabletenv.Env, se *schema.Engine) *QueryEngine {
 }
 
 // Open must be called before sending requests to QueryEngine.
-func (qe *QueryEngine) Open() error {
+// The connection it checks the sql mode with is abandoned if ctx
+// is done.
+func (qe *QueryEngine) Open(ctx context.Context) error {
 	if qe.isOpen {
 		return nil
 	}
@@ -243,7 +323,7 @@ func (qe *QueryEngine) Open() error {
 
 	qe.conns.Open(qe.env.Config().DB.AppWithDB(), qe.env.Config().DB.DbaWithDB(), qe.env.Config().DB.AppDebugWithDB())
 
-	conn, err := qe.conns.Get(tabletenv.LocalContext())
+	conn, err := qe.conns.Get(ctx)
 	if err != nil {
 		qe.conns.Close()
 		return err
@@ -260,6 +340,9 @@ func (qe *QueryEngine) Open() error {
 
 	qe.streamConns.Open(qe.env.Config().DB.AppWithDB(), qe.env.Config().DB.DbaWithDB(), qe.env.Config().DB.AppDebugWithDB())
 	qe.se.RegisterNotifier("qe", qe.schemaChanged)
+	if qe.planWarmupSaver != nil {
+		qe.planWarmupSaver.Start(qe.savePlanWarmup)
+	}
 	qe.isOpen = true
 	return nil
 }
@@ -271,6 +354,18 @@ func (qe *QueryEngine) StopServing() {
 	qe.streamQList.TerminateAll()
 }
 
+// KillActiveQueries kills the queries that have been running for
+// longer than olderThan. It's used when the tablet type changes,
+// and the killed queries fail with an error that states reason.
+func (qe *QueryEngine) KillActiveQueries(olderThan time.Duration, reason string) {
+	count := qe.queryList.TerminateOlderThan(olderThan, reason)
+	count += qe.streamQList.TerminateOlderThan(olderThan, reason)
+	if count != 0 {
+		qe.env.Stats().KillReasons.Add("Transition", int64(count))
+		log.Infof("Query Engine: killed %d queries running for longer than %v: %s", count, olderThan, reason)
+	}
+}
+
 // Close must be called to shut down QueryEngine.
 // You must ensure that no more queries will be sent
 // before calling Close.
@@ -279,6 +374,10 @@ func (qe *QueryEngine) Close() {
 		return
 	}
 	// Close in reverse order of Open.
+	if qe.planWarmupSaver != nil {
+		qe.planWarmupSaver.Stop()
+		qe.savePlanWarmup()
+	}
 	qe.se.UnregisterNotifier("qe")
 	qe.plans.Clear()
 	qe.tables = make(map[string]*schema.Table)
@@ -377,16 +476,93 @@ func (qe *QueryEngine) ClearQueryPlanCache() {
 }
 
 // IsMySQLReachable returns an error if it cannot connect to MySQL.
+// If checkWrites is set, it also verifies that MySQL accepts writes,
+// and returns a *mysqlWriteError if it's the only check that failed.
+// The writes are probed once CreateSidecarTables has created the
+// table of the probe. The check is bounded by mysqlCheckTimeout.
 // This can be called before opening the QueryEngine.
-func (qe *QueryEngine) IsMySQLReachable() error {
-	conn, err := dbconnpool.NewDBConnection(context.TODO(), qe.env.Config().DB.AppWithDB())
+func (qe *QueryEngine) IsMySQLReachable(ctx context.Context, checkWrites bool) error {
+	ctx, cancel := context.WithTimeout(ctx, mysqlCheckTimeout)
+	defer cancel()
+	conn, err := dbconnpool.NewDBConnection(ctx, qe.env.Config().DB.AppWithDB())
 	if err != nil {
 		return err
 	}
 	conn.Close()
+	if !checkWrites {
+		return nil
+	}
+
+	conn, err = dbconnpool.NewDBConnection(ctx, qe.env.Config().DB.DbaWithDB())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	// Closing conn interrupts a probe that blocks.
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	_, err = conn.ExecuteFetch(sqlWriteProbe, 1, false)
+	close(done)
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return &mysqlWriteError{err: fmt.Errorf("write probe did not complete within %v: %v", mysqlCheckTimeout, err)}
+	}
+	if sqlErr, ok := err.(*mysql.SQLError); ok && sqlErr.Number() == mysql.ERNoSuchTable {
+		// The tablet was never a master yet.
+		return nil
+	}
+	if mysql.IsConnErr(err) {
+		return err
+	}
+	return &mysqlWriteError{err: err}
+}
+
+// CreateSidecarTables creates the table of the write probe of
+// IsMySQLReachable. It's called when the tablet becomes a master:
+// the probe only writes.
+func (qe *QueryEngine) CreateSidecarTables(ctx context.Context) error {
+	conn, err := dbconnpool.NewDBConnection(ctx, qe.env.Config().DB.DbaWithDB())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	for _, query := range []string{"create database if not exists _vt", sqlCreateWriteProbeTable} {
+		if _, err := conn.ExecuteFetch(query, 0, false); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// PoolUsage returns the number of connections of the query
+// pool that are in use, and its capacity.
+func (qe *QueryEngine) PoolUsage() (inUse, capacity int64) {
+	return qe.conns.InUse(), qe.conns.Capacity()
+}
+
+// SetPressureMode switches the hot row protection between queueing
+// the transactions and failing them fast.
+func (qe *QueryEngine) SetPressureMode(mode pressureMode) {
+	qe.txSerializer.SetFailFast(mode == pressureFailFast)
+}
+
+// SetConsolidatorMode switches the consolidator mode. It takes
+// effect for the queries that start afterwards.
+func (qe *QueryEngine) SetConsolidatorMode(mode string) {
+	if old := qe.consolidatorMode.Get(); old != mode {
+		log.Infof("Consolidator mode changed from %s to %s", old, mode)
+	}
+	qe.consolidatorMode.Set(mode)
+}
+
 func (qe *QueryEngine) schemaChanged(tables map[string]*schema.Table, created, altered, dropped []string) {
 	qe.mu.Lock()
 	defer qe.mu.Unlock()
@@ -17,6 +17,7 @@ limitations under the License.
 package tabletserver
 
 import (
+	"errors"
 	"expvar"
 	"net/http"
 	"net/http/httptest"
@@ -25,9 +26,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
 	"vitess.io/vitess/go/streamlog"
 
+	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/mysql/fakesqldb"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/dbconfigs"
@@ -38,6 +42,7 @@ import (
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
 func TestStrictMode(t *testing.T) {
@@ -54,8 +59,8 @@ func TestStrictMode(t *testing.T) {
 	se := schema.NewEngine(env)
 	qe := NewQueryEngine(env, se)
 	qe.se.InitDBConfig(newDBConfigs(db).DbaWithDB())
-	qe.se.Open()
-	if err := qe.Open(); err != nil {
+	qe.se.Open(context.Background())
+	if err := qe.Open(context.Background()); err != nil {
 		t.Error(err)
 	}
 	qe.Close()
@@ -69,7 +74,7 @@ func TestStrictMode(t *testing.T) {
 		},
 	)
 	qe = NewQueryEngine(env, se)
-	err := qe.Open()
+	err := qe.Open(context.Background())
 	wantErr := "require sql_mode to be STRICT_TRANS_TABLES or STRICT_ALL_TABLES: got ''"
 	if err == nil || err.Error() != wantErr {
 		t.Errorf("Open: %v, want %s", err, wantErr)
@@ -79,7 +84,7 @@ func TestStrictMode(t *testing.T) {
 	// Test that we succeed if the enforcement flag is off.
 	config.EnforceStrictTransTables = false
 	qe = NewQueryEngine(env, se)
-	if err := qe.Open(); err != nil {
+	if err := qe.Open(context.Background()); err != nil {
 		t.Fatal(err)
 	}
 	qe.Close()
@@ -92,8 +97,8 @@ func TestGetPlanPanicDuetoEmptyQuery(t *testing.T) {
 		db.AddQuery(query, result)
 	}
 	qe := newTestQueryEngine(10, 10*time.Second, true, newDBConfigs(db))
-	qe.se.Open()
-	qe.Open()
+	qe.se.Open(context.Background())
+	qe.Open(context.Background())
 	defer qe.Close()
 
 	ctx := context.Background()
@@ -105,6 +110,47 @@ func TestGetPlanPanicDuetoEmptyQuery(t *testing.T) {
 	}
 }
 
+func TestQueryEngineHotRowStatsSurviveReopen(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	for query, result := range schematest.Queries() {
+		db.AddQuery(query, result)
+	}
+	config := tabletenv.NewDefaultConfig()
+	config.DB = newDBConfigs(db)
+	config.HotRowProtection.MaxConcurrency = 1
+	env := tabletenv.NewEnv(config, "TabletServerTest")
+	se := schema.NewEngine(env)
+	se.InitDBConfig(newDBConfigs(db).DbaWithDB())
+	qe := NewQueryEngine(env, se)
+	qe.se.Open(context.Background())
+	require.NoError(t, qe.Open(context.Background()))
+	defer qe.Close()
+
+	done1, _, err := qe.txSerializer.Wait(context.Background(), "t1 where1", "t1")
+	require.NoError(t, err)
+	ch := make(chan struct{})
+	go func() {
+		defer close(ch)
+		done2, waited, err := qe.txSerializer.Wait(context.Background(), "t1 where1", "t1")
+		assert.NoError(t, err)
+		assert.True(t, waited)
+		done2()
+	}()
+	for qe.txSerializer.Pending("t1 where1") != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	done1()
+	<-ch
+	hotKeys := qe.txSerializer.HotKeys()
+	require.Len(t, hotKeys, 1)
+
+	// A transition closes and opens the query engine again.
+	qe.Close()
+	require.NoError(t, qe.Open(context.Background()))
+	assert.Equal(t, hotKeys, qe.txSerializer.HotKeys())
+}
+
 func TestGetMessageStreamPlan(t *testing.T) {
 	db := fakesqldb.New(t)
 	defer db.Close()
@@ -112,8 +158,8 @@ func TestGetMessageStreamPlan(t *testing.T) {
 		db.AddQuery(query, result)
 	}
 	qe := newTestQueryEngine(10, 10*time.Second, true, newDBConfigs(db))
-	qe.se.Open()
-	qe.Open()
+	qe.se.Open(context.Background())
+	qe.Open(context.Background())
 	defer qe.Close()
 
 	plan, err := qe.GetMessageStreamPlan("msg")
@@ -149,8 +195,8 @@ func TestQueryPlanCache(t *testing.T) {
 	db.AddQuery("select * from test_table_02 where 1 != 1", &sqltypes.Result{})
 
 	qe := newTestQueryEngine(10, 10*time.Second, true, newDBConfigs(db))
-	qe.se.Open()
-	qe.Open()
+	qe.se.Open(context.Background())
+	qe.Open(context.Background())
 	defer qe.Close()
 
 	ctx := context.Background()
@@ -191,8 +237,8 @@ func TestNoQueryPlanCache(t *testing.T) {
 	db.AddQuery("select * from test_table_02 where 1 != 1", &sqltypes.Result{})
 
 	qe := newTestQueryEngine(10, 10*time.Second, true, newDBConfigs(db))
-	qe.se.Open()
-	qe.Open()
+	qe.se.Open(context.Background())
+	qe.Open(context.Background())
 	defer qe.Close()
 
 	ctx := context.Background()
@@ -223,8 +269,8 @@ func TestNoQueryPlanCacheDirective(t *testing.T) {
 	db.AddQuery("select /*vt+ SKIP_QUERY_PLAN_CACHE=1 */ * from test_table_02 where 1 != 1", &sqltypes.Result{})
 
 	qe := newTestQueryEngine(10, 10*time.Second, true, newDBConfigs(db))
-	qe.se.Open()
-	qe.Open()
+	qe.se.Open(context.Background())
+	qe.Open(context.Background())
 	defer qe.Close()
 
 	ctx := context.Background()
@@ -252,8 +298,8 @@ func TestStatsURL(t *testing.T) {
 	query := "select * from test_table_01"
 	db.AddQuery("select * from test_table_01 where 1 != 1", &sqltypes.Result{})
 	qe := newTestQueryEngine(10, 1*time.Second, true, newDBConfigs(db))
-	qe.se.Open()
-	qe.Open()
+	qe.se.Open(context.Background())
+	qe.Open(context.Background())
 	defer qe.Close()
 	// warm up cache
 	ctx := context.Background()
@@ -287,13 +333,95 @@ func newTestQueryEngine(queryCacheSize int, idleTimeout time.Duration, strict bo
 	return qe
 }
 
+func TestQueryEngineKillActiveQueries(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	qe := newTestQueryEngine(10, 1*time.Second, true, newDBConfigs(db))
+
+	oldConn := &testConn{id: 1}
+	oldQD := NewQueryDetail(context.Background(), oldConn)
+	oldQD.start = time.Now().Add(-time.Minute)
+	qe.queryList.Add(oldQD)
+	oldStreamConn := &testConn{id: 2}
+	oldStreamQD := NewQueryDetail(context.Background(), oldStreamConn)
+	oldStreamQD.start = time.Now().Add(-time.Minute)
+	qe.streamQList.Add(oldStreamQD)
+	newConn := &testConn{id: 3}
+	qe.queryList.Add(NewQueryDetail(context.Background(), newConn))
+
+	kills := qe.env.Stats().KillReasons.Counts()["Transition"]
+	qe.KillActiveQueries(time.Second, "tablet transitioning to REPLICA")
+	assert.True(t, oldConn.IsKilled())
+	assert.True(t, oldStreamConn.IsKilled())
+	assert.False(t, newConn.IsKilled())
+	assert.Contains(t, oldQD.killedError(errors.New("killed")).Error(), "query killed: tablet transitioning to REPLICA")
+	assert.Contains(t, oldStreamQD.killedError(errors.New("killed")).Error(), "query killed: tablet transitioning to REPLICA")
+	assert.Equal(t, kills+2, qe.env.Stats().KillReasons.Counts()["Transition"])
+}
+
+func TestQueryEngineIsMySQLReachable(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	qe := newTestQueryEngine(10, 1*time.Second, true, newDBConfigs(db))
+
+	db.AddRejectedQuery(sqlWriteProbe, mysql.NewSQLError(mysql.ERDiskFull, mysql.SSUnknownSQLState, "disk full"))
+	require.NoError(t, qe.IsMySQLReachable(ctx, false))
+	err := qe.IsMySQLReachable(ctx, true)
+	require.IsType(t, &mysqlWriteError{}, err)
+	assert.Contains(t, err.Error(), "mysql is not accepting writes: disk full")
+	assert.True(t, isMySQLWriteErr(err.(*mysqlWriteError).err))
+
+	db.DeleteRejectedQuery(sqlWriteProbe)
+	db.AddQuery(sqlWriteProbe, &sqltypes.Result{RowsAffected: 1})
+	require.NoError(t, qe.IsMySQLReachable(ctx, true))
+
+	db.EnableConnFail()
+	err = qe.IsMySQLReachable(ctx, true)
+	require.Error(t, err)
+	assert.IsType(t, &mysql.SQLError{}, err)
+	db.DisableConnFail()
+
+	// The writes are not probed until the table is created.
+	db.AddRejectedQuery(sqlWriteProbe, mysql.NewSQLError(mysql.ERNoSuchTable, mysql.SSUnknownSQLState, "no such table"))
+	require.NoError(t, qe.IsMySQLReachable(ctx, true))
+	db.DeleteRejectedQuery(sqlWriteProbe)
+}
+
+func TestQueryEngineWriteProbeTimeout(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	qe := newTestQueryEngine(10, 1*time.Second, true, newDBConfigs(db))
+	defer func(saved time.Duration) { mysqlCheckTimeout = saved }(mysqlCheckTimeout)
+	mysqlCheckTimeout = 10 * time.Millisecond
+
+	// A write that blocks is a write error.
+	release := make(chan struct{})
+	defer close(release)
+	db.AddQuery(sqlWriteProbe, &sqltypes.Result{RowsAffected: 1})
+	db.SetBeforeFunc(sqlWriteProbe, func() { <-release })
+	err := qe.IsMySQLReachable(ctx, true)
+	require.IsType(t, &mysqlWriteError{}, err)
+	assert.Contains(t, err.Error(), "write probe did not complete within 10ms")
+}
+
+func TestQueryEngineCreateSidecarTables(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	qe := newTestQueryEngine(10, 1*time.Second, true, newDBConfigs(db))
+
+	db.AddQuery("create database if not exists _vt", &sqltypes.Result{})
+	db.AddQuery(sqlCreateWriteProbeTable, &sqltypes.Result{})
+	require.NoError(t, qe.CreateSidecarTables(ctx))
+	assert.Equal(t, 1, db.GetQueryCalledNum(sqlCreateWriteProbeTable))
+}
+
 func runConsolidatedQuery(t *testing.T, sql string) *QueryEngine {
 	db := fakesqldb.New(t)
 	defer db.Close()
 
 	qe := newTestQueryEngine(10, 1*time.Second, true, newDBConfigs(db))
-	qe.se.Open()
-	qe.Open()
+	qe.se.Open(context.Background())
+	qe.Open(context.Background())
 	defer qe.Close()
 
 	r1, ok := qe.consolidator.Create(sql)
@@ -345,3 +473,34 @@ func TestConsolidationsUIRedaction(t *testing.T) {
 		t.Fatalf("Response missing redacted consolidated query: %v %v", redactedSQL, redactedResponse.Body.String())
 	}
 }
+
+func TestConsolidatorModeByTabletType(t *testing.T) {
+	config := tabletenv.NewDefaultConfig()
+	config.ConsolidatorByTabletType = map[string]string{"master": tabletenv.Disable, "replica": tabletenv.Enable}
+	db, tsv := setupTabletServerTestCustom(t, config)
+	defer tsv.StopService()
+	defer db.Close()
+	db.AddQueryPattern("select \\* from test_table.*", &sqltypes.Result{})
+
+	execute := func(tabletType topodatapb.TabletType) {
+		t.Helper()
+		target := querypb.Target{TabletType: tabletType}
+		_, err := tsv.Execute(context.Background(), &target, "select * from test_table", nil, 0, 0, nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, tabletenv.Disable, tsv.qe.consolidatorMode.Get())
+	execute(topodatapb.TabletType_MASTER)
+	assert.Equal(t, int64(0), tsv.qe.consolidations.Counts()["MASTER.Original"])
+
+	// The mode changes with the tablet type, without a qe reopen.
+	err := tsv.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, time.Time{}, true, "")
+	require.NoError(t, err)
+	assert.Equal(t, tabletenv.Enable, tsv.qe.consolidatorMode.Get())
+	execute(topodatapb.TabletType_REPLICA)
+	assert.Equal(t, int64(1), tsv.qe.consolidations.Counts()["REPLICA.Original"])
+
+	// The types that aren't listed use the global mode.
+	err = tsv.SetServingType(context.Background(), topodatapb.TabletType_RDONLY, time.Time{}, true, "")
+	require.NoError(t, err)
+	assert.Equal(t, config.Consolidator, tsv.qe.consolidatorMode.Get())
+}
@@ -84,6 +84,9 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 		if reply == nil {
 			qre.tsv.qe.AddStats(planName, tableName, 1, duration, mysqlTime, 0, 1)
 			qre.plan.AddStats(1, duration, mysqlTime, 0, 1)
+			if isConnFailure(err) {
+				qre.tsv.qe.AddConnError(planName, tableName)
+			}
 			return
 		}
 		qre.tsv.qe.AddStats(planName, tableName, 1, duration, mysqlTime, int64(reply.RowsAffected), 0)
@@ -96,6 +99,11 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 	if err := qre.checkPermissions(); err != nil {
 		return nil, err
 	}
+	release, err := qre.admitQuiesced()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
 	switch qre.plan.PlanID {
 	case planbuilder.PlanNextval:
@@ -117,6 +125,9 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 			return nil, err
 		}
 		defer conn.Unlock()
+		if conn.IsInTransaction() && conn.TxProperties().ReadOnly && qre.plan.PlanID.IsWrite() {
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "%s not allowed in a read-only transaction", qre.plan.PlanID.String())
+		}
 		return qre.txConnExec(conn)
 	}
 
@@ -155,6 +166,9 @@ func (qre *QueryExecutor) execAutocommit(f func(conn *StatefulConnection) (*sqlt
 	}
 	qre.options.TransactionIsolation = querypb.ExecuteOptions_AUTOCOMMIT
 
+	if err := qre.tsv.sm.VerifyWritable(); err != nil {
+		return nil, err
+	}
 	conn, _, err := qre.tsv.te.txPool.Begin(qre.ctx, qre.options, false, 0, nil)
 
 	if err != nil {
@@ -166,6 +180,9 @@ func (qre *QueryExecutor) execAutocommit(f func(conn *StatefulConnection) (*sqlt
 }
 
 func (qre *QueryExecutor) execAsTransaction(f func(conn *StatefulConnection) (*sqltypes.Result, error)) (*sqltypes.Result, error) {
+	if err := qre.tsv.sm.VerifyWritable(); err != nil {
+		return nil, err
+	}
 	conn, beginSQL, err := qre.tsv.te.txPool.Begin(qre.ctx, qre.options, false, 0, nil)
 	if err != nil {
 		return nil, err
@@ -238,6 +255,11 @@ func (qre *QueryExecutor) Stream(callback func(*sqltypes.Result) error) error {
 	if err := qre.checkPermissions(); err != nil {
 		return err
 	}
+	release, err := qre.admitQuiesced()
+	if err != nil {
+		return err
+	}
+	defer release()
 
 	// if we have a transaction id, let's use the txPool for this query
 	var conn *connpool.DBConn
@@ -258,10 +280,11 @@ func (qre *QueryExecutor) Stream(callback func(*sqltypes.Result) error) error {
 	}
 
 	qd := NewQueryDetail(qre.logStats.Ctx, conn)
+	qd.kills = qre.tsv.qe.env.Stats().KillReasons
 	qre.tsv.qe.streamQList.Add(qd)
 	defer qre.tsv.qe.streamQList.Remove(qd)
 
-	return qre.streamFetch(conn, qre.plan.FullQuery, qre.bindVars, callback)
+	return qd.killedError(qre.streamFetch(conn, qre.plan.FullQuery, qre.bindVars, qre.limitStreamRows(callback)))
 }
 
 // MessageStream streams messages from a message table.
@@ -295,6 +318,15 @@ func (qre *QueryExecutor) MessageStream(callback func(*sqltypes.Result) error) e
 
 // checkPermissions returns an error if the query does not pass all checks
 // (query blacklisting, table ACL).
+// admitQuiesced holds or rejects the query if one of its tables is
+// quiesced. Like checkPermissions, it lets the internal queries through.
+func (qre *QueryExecutor) admitQuiesced() (func(), error) {
+	if tabletenv.IsLocalContext(qre.ctx) {
+		return func() {}, nil
+	}
+	return qre.tsv.sm.quiescer.admit(qre.ctx, qre.plan)
+}
+
 func (qre *QueryExecutor) checkPermissions() error {
 	// Skip permissions check if the context is local.
 	if tabletenv.IsLocalContext(qre.ctx) {
@@ -502,7 +534,7 @@ func (qre *QueryExecutor) execSelect() (*sqltypes.Result, error) {
 }
 
 func (qre *QueryExecutor) execDMLLimit(conn *StatefulConnection) (*sqltypes.Result, error) {
-	maxrows := qre.tsv.qe.maxResultSize.Get()
+	maxrows := qre.maxResultSize()
 	qre.bindVars["#maxLimit"] = sqltypes.Int64BindVariable(maxrows + 1)
 	result, err := qre.txFetch(conn, true)
 	if err != nil {
@@ -518,8 +550,7 @@ func (qre *QueryExecutor) execDMLLimit(conn *StatefulConnection) (*sqltypes.Resu
 
 func (qre *QueryExecutor) verifyRowCount(count, maxrows int64) error {
 	if count > maxrows {
-		callerID := callerid.ImmediateCallerIDFromContext(qre.ctx)
-		return mysql.NewSQLError(mysql.ERVitessMaxRowsExceeded, mysql.SSUnknownSQLState, "caller id: %s: row count exceeded %d", callerID.Username, maxrows)
+		return qre.maxRowsExceeded(maxrows)
 	}
 	warnThreshold := qre.tsv.qe.warnResultSize.Get()
 	if warnThreshold > 0 && count > warnThreshold {
@@ -530,6 +561,30 @@ func (qre *QueryExecutor) verifyRowCount(count, maxrows int64) error {
 	return nil
 }
 
+func (qre *QueryExecutor) maxRowsExceeded(maxrows int64) error {
+	callerID := callerid.ImmediateCallerIDFromContext(qre.ctx)
+	return mysql.NewSQLError(mysql.ERVitessMaxRowsExceeded, mysql.SSUnknownSQLState, "caller id: %s: row count exceeded %d", callerid.GetUsername(callerID), maxrows)
+}
+
+// limitStreamRows wraps callback to fail the stream before the rows
+// sent exceed the max rows of the table of the query, if the table
+// overrides the max result size. Streaming queries aren't limited
+// otherwise.
+func (qre *QueryExecutor) limitStreamRows(callback func(*sqltypes.Result) error) func(*sqltypes.Result) error {
+	maxrows, ok := qre.tsv.qe.live.TableMaxRows(qre.plan.TableName().String())
+	if !ok {
+		return callback
+	}
+	var count int64
+	return func(result *sqltypes.Result) error {
+		count += int64(len(result.Rows))
+		if count > maxrows {
+			return qre.maxRowsExceeded(maxrows)
+		}
+		return callback(result)
+	}
+}
+
 func (qre *QueryExecutor) execOther() (*sqltypes.Result, error) {
 	conn, err := qre.getConn()
 	if err != nil {
@@ -577,9 +632,10 @@ func (qre *QueryExecutor) qFetch(logStats *tabletenv.LogStats, parsedQuery *sqlp
 		return nil, err
 	}
 	// Check tablet type.
-	if qre.tsv.qe.consolidatorMode == tabletenv.Enable || (qre.tsv.qe.consolidatorMode == tabletenv.NotOnMaster && qre.tabletType != topodatapb.TabletType_MASTER) {
+	if mode := qre.tsv.qe.consolidatorMode.Get(); mode == tabletenv.Enable || (mode == tabletenv.NotOnMaster && qre.tabletType != topodatapb.TabletType_MASTER) {
 		q, original := qre.tsv.qe.consolidator.Create(string(sqlWithoutComments))
 		if original {
+			qre.tsv.qe.consolidations.Add([]string{qre.tabletType.String(), "Original"}, 1)
 			defer q.Broadcast()
 			conn, err := qre.getConn()
 
@@ -590,6 +646,7 @@ func (qre *QueryExecutor) qFetch(logStats *tabletenv.LogStats, parsedQuery *sqlp
 				q.Result, q.Err = qre.execSQL(conn, sql, false)
 			}
 		} else {
+			qre.tsv.qe.consolidations.Add([]string{qre.tabletType.String(), "Consolidated"}, 1)
 			logStats.QuerySources |= tabletenv.QuerySourceConsolidator
 			startTime := time.Now()
 			q.Wait()
@@ -662,8 +719,18 @@ func (qre *QueryExecutor) generateFinalSQL(parsedQuery *sqlparser.ParsedQuery, b
 	return fullSQL, withoutComments, nil
 }
 
+// maxResultSize returns the max number of rows the query can
+// return: the override of its table if there's one, or the global
+// max result size.
+func (qre *QueryExecutor) maxResultSize() int64 {
+	if maxRows, ok := qre.tsv.qe.live.TableMaxRows(qre.plan.TableName().String()); ok {
+		return maxRows
+	}
+	return qre.tsv.qe.maxResultSize.Get()
+}
+
 func (qre *QueryExecutor) getSelectLimit() int64 {
-	maxRows := qre.tsv.qe.maxResultSize.Get()
+	maxRows := qre.maxResultSize()
 	sqlLimit := qre.options.GetSqlSelectLimit()
 	if sqlLimit > 0 && sqlLimit < maxRows {
 		return sqlLimit
@@ -681,7 +748,27 @@ func (qre *QueryExecutor) execSQL(conn executor, sql string, wantfields bool) (*
 	defer span.Finish()
 
 	defer qre.logStats.AddRewrittenSQL(sql, time.Now())
-	return conn.Exec(ctx, sql, int(qre.tsv.qe.maxResultSize.Get()), wantfields)
+
+	// Track the query so that it can be killed if the tablet type changes.
+	var dbConn *connpool.DBConn
+	switch conn := conn.(type) {
+	case *connpool.DBConn:
+		dbConn = conn
+	case *StatefulConnection:
+		dbConn = conn.UnderlyingDBConn()
+	}
+	if dbConn == nil {
+		return conn.Exec(ctx, sql, int(qre.maxResultSize()), wantfields)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	qd := NewQueryDetail(ctx, dbConn)
+	qd.cancel = cancel
+	qd.kills = qre.tsv.qe.env.Stats().KillReasons
+	qre.tsv.qe.queryList.Add(qd)
+	defer qre.tsv.qe.queryList.Remove(qd)
+	qr, err := conn.Exec(ctx, sql, int(qre.maxResultSize()), wantfields)
+	return qr, qd.killedError(err)
 }
 
 func (qre *QueryExecutor) execStreamSQL(conn *connpool.DBConn, sql string, callback func(*sqltypes.Result) error) error {
@@ -468,6 +468,59 @@ func TestQueryExecutorLimitFailure(t *testing.T) {
 	}
 }
 
+func TestQueryExecutorTableMaxRows(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	rows := &sqltypes.Result{
+		Fields: getTestTableFields(),
+		Rows: [][]sqltypes.Value{
+			{sqltypes.NewInt32(1), sqltypes.NewInt32(2), sqltypes.NewInt32(3)},
+			{sqltypes.NewInt32(4), sqltypes.NewInt32(5), sqltypes.NewInt32(6)},
+			{sqltypes.NewInt32(7), sqltypes.NewInt32(8), sqltypes.NewInt32(9)},
+		},
+	}
+	db.AddQuery("select * from test_table limit 3", rows)
+	db.AddQuery("select * from test_table limit 10001", rows)
+	db.AddQuery("select * from test_table", rows)
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+
+	values := tsv.live.Values()
+	values.TableMaxRows = map[string]int64{"unknown_table": 2}
+	err := tsv.live.Reload("test", values)
+	require.EqualError(t, err, "table_max_rows: table unknown_table not found in the schema")
+	values.TableMaxRows = map[string]int64{"test_table": 2}
+	require.NoError(t, tsv.live.Reload("test", values))
+
+	qre := newTestQueryExecutor(ctx, tsv, "select * from test_table", 0)
+	_, err = qre.Execute()
+	assert.Contains(t, err.Error(), "Row count exceeded 2")
+	assert.Contains(t, qre.logStats.RewrittenSQL(), "select * from test_table limit 3")
+
+	target := tsv.sm.Target()
+	var streamed int
+	stream := func(qr *sqltypes.Result) error {
+		streamed += len(qr.Rows)
+		return nil
+	}
+	err = tsv.StreamExecute(ctx, &target, "select * from test_table", nil, 0, nil, stream)
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	assert.Contains(t, err.Error(), "row count exceeded 2")
+	assert.Zero(t, streamed)
+
+	// Without the override, the global limits apply again.
+	values.TableMaxRows = nil
+	require.NoError(t, tsv.live.Reload("test", values))
+	qre = newTestQueryExecutor(ctx, tsv, "select * from test_table", 0)
+	got, err := qre.Execute()
+	require.NoError(t, err)
+	assert.Len(t, got.Rows, 3)
+	err = tsv.StreamExecute(ctx, &target, "select * from test_table", nil, 0, nil, stream)
+	require.NoError(t, err)
+	assert.Equal(t, 3, streamed)
+}
+
 func TestQueryExecutorPlanPassSelectWithLockOutsideATransaction(t *testing.T) {
 	db := setUpQueryExecutorTest(t)
 	defer db.Close()
@@ -492,6 +545,26 @@ func TestQueryExecutorPlanPassSelectWithLockOutsideATransaction(t *testing.T) {
 	}
 }
 
+func TestQueryExecutorReadOnlyTransaction(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	db.AddQuery("set transaction isolation level REPEATABLE READ", &sqltypes.Result{})
+	db.AddQuery("start transaction with consistent snapshot, read only", &sqltypes.Result{})
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	txid := newTransaction(tsv, &querypb.ExecuteOptions{TransactionIsolation: querypb.ExecuteOptions_CONSISTENT_SNAPSHOT_READ_ONLY})
+	defer tsv.Rollback(ctx, &querypb.Target{TabletType: topodatapb.TabletType_MASTER}, txid)
+	db.ResetQueryLog()
+
+	qre := newTestQueryExecutor(ctx, tsv, "update test_table set name_string = 'a' where pk = 1", txid)
+	assert.Equal(t, planbuilder.PlanUpdateLimit, qre.plan.PlanID)
+	_, err := qre.Execute()
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	assert.EqualError(t, err, "UpdateLimit not allowed in a read-only transaction")
+	assert.Empty(t, db.QueryLog())
+}
+
 func TestQueryExecutorPlanNextval(t *testing.T) {
 	db := setUpQueryExecutorTest(t)
 	defer db.Close()
@@ -1165,6 +1238,8 @@ func getTestTableFields() []*querypb.Field {
 
 func getQueryExecutorSupportedQueries(testTableHasMultipleUniqueKeys bool) map[string]*sqltypes.Result {
 	return map[string]*sqltypes.Result{
+		// queries for the mysql write check
+		sqlCreateWriteProbeTable: {},
 		// queries for twopc
 		fmt.Sprintf(sqlCreateSidecarDB, "_vt"):          {},
 		fmt.Sprintf(sqlDropLegacy1, "_vt"):              {},
@@ -1194,6 +1269,15 @@ func getQueryExecutorSupportedQueries(testTableHasMultipleUniqueKeys bool) map[s
 			},
 			RowsAffected: 1,
 		},
+		"select @@global.read_only": {
+			Fields: []*querypb.Field{{
+				Type: sqltypes.Int64,
+			}},
+			Rows: [][]sqltypes.Value{
+				{sqltypes.NewInt64(0)},
+			},
+			RowsAffected: 1,
+		},
 		"select @@autocommit": {
 			Fields: []*querypb.Field{{
 				Type: sqltypes.Uint64,
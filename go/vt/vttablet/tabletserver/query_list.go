@@ -24,7 +24,11 @@ import (
 	"time"
 
 	"golang.org/x/net/context"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/sync2"
 	"vitess.io/vitess/go/vt/callinfo"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
 )
 
 // QueryDetail is a simple wrapper for Query, Context and a killable conn.
@@ -33,6 +37,16 @@ type QueryDetail struct {
 	conn   killable
 	connID int64
 	start  time.Time
+
+	// cancel, if set, is used to abort the query instead of
+	// killing the connection directly. This lets the caller
+	// know that the query must not be retried.
+	cancel context.CancelFunc
+	// killReason is set if the query was killed by TerminateOlderThan.
+	killReason sync2.AtomicString
+	// kills, if set, counts the queries killed because
+	// they timed out. See killedError.
+	kills *stats.CountersWithSingleLabel
 }
 
 type killable interface {
@@ -92,6 +106,52 @@ func (ql *QueryList) TerminateAll() {
 	}
 }
 
+// TerminateOlderThan kills the queries that have been running for longer
+// than olderThan. The reason is reported to the clients of the killed
+// queries. It returns the number of queries that were killed.
+func (ql *QueryList) TerminateOlderThan(olderThan time.Duration, reason string) int {
+	ql.mu.Lock()
+	defer ql.mu.Unlock()
+	count := 0
+	for _, qd := range ql.queryDetails {
+		elapsed := time.Since(qd.start)
+		if elapsed < olderThan {
+			continue
+		}
+		if !qd.killReason.CompareAndSwap("", reason) {
+			// Already killed.
+			continue
+		}
+		count++
+		if qd.cancel != nil {
+			qd.cancel()
+			continue
+		}
+		qd.conn.Kill(reason, elapsed)
+	}
+	return count
+}
+
+// killedError returns the error to report for a query that failed
+// with err. If the query was killed by TerminateOlderThan, or because
+// its deadline was exceeded, the error states the reason, so that the
+// client doesn't mistake the kill for a network error.
+func (qd *QueryDetail) killedError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if reason := qd.killReason.Get(); reason != "" {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "query killed: %s, after running for %v: %v", reason, time.Since(qd.start), err)
+	}
+	if qd.ctx.Err() != context.DeadlineExceeded {
+		return err
+	}
+	if qd.kills != nil {
+		qd.kills.Add("QueryTimeout", 1)
+	}
+	return vterrors.Errorf(vtrpcpb.Code_DEADLINE_EXCEEDED, "query killed: deadline exceeded, after running for %v: %v", time.Since(qd.start), err)
+}
+
 // QueryDetailzRow is used for rendering QueryDetail in a template
 type QueryDetailzRow struct {
 	Query             string
@@ -17,10 +17,15 @@ limitations under the License.
 package tabletserver
 
 import (
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"golang.org/x/net/context"
+	"vitess.io/vitess/go/stats"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
 )
 
 type testConn struct {
@@ -66,3 +71,54 @@ func TestQueryList(t *testing.T) {
 		t.Errorf("failed to remove from QueryList")
 	}
 }
+
+func TestQueryListTerminateOlderThan(t *testing.T) {
+	ql := NewQueryList()
+	oldConn := &testConn{id: 1}
+	oldQD := NewQueryDetail(context.Background(), oldConn)
+	oldQD.start = time.Now().Add(-time.Minute)
+	ql.Add(oldQD)
+	canceled := false
+	cancelQD := NewQueryDetail(context.Background(), &testConn{id: 2})
+	cancelQD.start = time.Now().Add(-time.Minute)
+	cancelQD.cancel = func() { canceled = true }
+	ql.Add(cancelQD)
+	newConn := &testConn{id: 3}
+	newQD := NewQueryDetail(context.Background(), newConn)
+	ql.Add(newQD)
+
+	assert.Equal(t, 2, ql.TerminateOlderThan(time.Second, "test reason"))
+	assert.True(t, oldConn.IsKilled())
+	assert.True(t, canceled)
+	assert.False(t, newConn.IsKilled())
+
+	// Queries are not killed twice.
+	assert.Equal(t, 0, ql.TerminateOlderThan(time.Second, "test reason"))
+
+	assert.NoError(t, oldQD.killedError(nil))
+	err := oldQD.killedError(errors.New("connection lost"))
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	assert.Contains(t, err.Error(), "query killed: test reason, after running for")
+	assert.Contains(t, err.Error(), "connection lost")
+	assert.EqualError(t, newQD.killedError(errors.New("other error")), "other error")
+}
+
+func TestQueryDetailDeadlineExceeded(t *testing.T) {
+	kills := stats.NewCountersWithSingleLabel("", "", "reason")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	qd := NewQueryDetail(ctx, &testConn{id: 1})
+	qd.kills = kills
+
+	err := qd.killedError(errors.New("connection lost"))
+	assert.Equal(t, vtrpcpb.Code_DEADLINE_EXCEEDED, vterrors.Code(err))
+	assert.Contains(t, err.Error(), "query killed: deadline exceeded, after running for")
+	assert.Equal(t, int64(1), kills.Counts()["QueryTimeout"])
+
+	// The kill reason of a transition takes precedence.
+	qd.killReason.Set("tablet transitioning to REPLICA")
+	err = qd.killedError(errors.New("connection lost"))
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	assert.Contains(t, err.Error(), "query killed: tablet transitioning to REPLICA")
+}
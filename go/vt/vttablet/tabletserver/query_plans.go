@@ -0,0 +1,187 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"vitess.io/vitess/go/acl"
+)
+
+// maxQueryPlansLimit caps the number of plans returned by a
+// single request to /debug/query_plans.json. It's also the
+// default page size.
+const maxQueryPlansLimit = 1000
+
+// planStats is the entry of a plan in /debug/query_plans.json.
+// The stats are cumulative since the plan was cached, or since
+// they were last reset.
+type planStats struct {
+	// Key is the plan cache key: the query as sent by vtgate.
+	Key string `json:"key"`
+	// Query is the query as rebuilt from its parsed form.
+	Query      string        `json:"query"`
+	Table      string        `json:"table"`
+	Plan       string        `json:"plan"`
+	QueryCount int64         `json:"queryCount"`
+	Time       time.Duration `json:"timeNs"`
+	MysqlTime  time.Duration `json:"mysqlTimeNs"`
+	RowCount   int64         `json:"rowCount"`
+	ErrorCount int64         `json:"errorCount"`
+	StatsReset bool          `json:"statsReset,omitempty"`
+}
+
+// queryPlansPage is the response of /debug/query_plans.json.
+type queryPlansPage struct {
+	// Total is the number of cached plans. The plans are sorted
+	// by key, and the page starts at Offset.
+	Total  int          `json:"total"`
+	Offset int          `json:"offset"`
+	Plans  []*planStats `json:"plans"`
+}
+
+// StatsAndReset returns the current stats of TabletPlan, and resets
+// them. No stats update is lost between the read and the reset.
+func (ep *TabletPlan) StatsAndReset() (queryCount int64, duration, mysqlTime time.Duration, rowCount, errorCount int64) {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	queryCount, duration, mysqlTime, rowCount, errorCount = ep.QueryCount, ep.Time, ep.MysqlTime, ep.RowCount, ep.ErrorCount
+	ep.QueryCount, ep.Time, ep.MysqlTime, ep.RowCount, ep.ErrorCount = 0, 0, 0, 0, 0
+	return
+}
+
+// planStatsPage returns the stats of at most limit cached plans
+// sorted by key, starting at offset. If reset is set, the stats
+// of the returned plans are reset after they're read.
+func (qe *QueryEngine) planStatsPage(offset, limit int, reset bool) *queryPlansPage {
+	items := qe.plans.Items()
+	sort.Slice(items, func(i, j int) bool { return items[i].Key < items[j].Key })
+	page := &queryPlansPage{
+		Total:  len(items),
+		Offset: offset,
+		Plans:  []*planStats{},
+	}
+	if offset >= len(items) {
+		return page
+	}
+	items = items[offset:]
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	for _, item := range items {
+		plan, _ := item.Value.(*TabletPlan)
+		if plan == nil {
+			continue
+		}
+		ps := &planStats{
+			Key:        unicoded(item.Key),
+			Query:      unicoded(item.Key),
+			Table:      plan.TableName().String(),
+			Plan:       plan.PlanID.String(),
+			StatsReset: reset,
+		}
+		if plan.FullQuery != nil {
+			ps.Query = plan.FullQuery.Query
+		}
+		if reset {
+			ps.QueryCount, ps.Time, ps.MysqlTime, ps.RowCount, ps.ErrorCount = plan.StatsAndReset()
+		} else {
+			ps.QueryCount, ps.Time, ps.MysqlTime, ps.RowCount, ps.ErrorCount = plan.Stats()
+		}
+		page.Plans = append(page.Plans, ps)
+	}
+	return page
+}
+
+// queryPlansHandler serves the stats of the cached plans as JSON,
+// for tools that look for regressions. The page is selected with
+// the offset and limit parameters. With reset=true, the stats of
+// the returned plans are reset, which requires a POST and the admin
+// role.
+// It fails with the state of sm while qe isn't open.
+func queryPlansHandler(qe *QueryEngine, sm *stateManager, w http.ResponseWriter, r *http.Request) {
+	if err := acl.CheckAccessHTTP(r, acl.DEBUGGING); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+	offset, limit, reset, err := parseQueryPlansParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if reset {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, fmt.Sprintf("method %s not allowed with reset: use POST", r.Method), http.StatusMethodNotAllowed)
+			return
+		}
+		if err := acl.CheckAccessHTTP(r, acl.ADMIN); err != nil {
+			acl.SendError(w, err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	status := sm.Status()
+	if qeStatus := status.subcomponent("qe"); qeStatus != "open" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(struct {
+			Error      string `json:"error"`
+			State      string `json:"state"`
+			WantState  string `json:"wantState"`
+			TabletType string `json:"tabletType"`
+		}{
+			Error:      fmt.Sprintf("query engine is %s", qeStatus),
+			State:      status.State,
+			WantState:  status.WantState,
+			TabletType: status.TabletType,
+		})
+		return
+	}
+	json.NewEncoder(w).Encode(qe.planStatsPage(offset, limit, reset))
+}
+
+func parseQueryPlansParams(r *http.Request) (offset, limit int, reset bool, err error) {
+	if err := r.ParseForm(); err != nil {
+		return 0, 0, false, err
+	}
+	limit = maxQueryPlansLimit
+	if v := r.FormValue("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			return 0, 0, false, fmt.Errorf("invalid offset: %q", v)
+		}
+	}
+	if v := r.FormValue("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			return 0, 0, false, fmt.Errorf("invalid limit: %q", v)
+		}
+		if limit > maxQueryPlansLimit {
+			limit = maxQueryPlansLimit
+		}
+	}
+	if v := r.FormValue("reset"); v != "" {
+		if reset, err = strconv.ParseBool(v); err != nil {
+			return 0, 0, false, fmt.Errorf("invalid reset: %q", v)
+		}
+	}
+	return offset, limit, reset, nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
+)
+
+func TestQueryPlansHandler(t *testing.T) {
+	db, tsv := setupTabletServerTest(t)
+	defer db.Close()
+
+	request := func(method, query string) (int, []byte) {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(method, tsv.exporter.URLPrefix()+"/debug/query_plans.json?"+query, nil)
+		http.DefaultServeMux.ServeHTTP(rr, req)
+		return rr.Code, rr.Body.Bytes()
+	}
+	get := func(query string) (int, []byte) {
+		t.Helper()
+		return request("GET", query)
+	}
+	getPage := func(method, query string) *queryPlansPage {
+		t.Helper()
+		code, body := request(method, query)
+		require.Equal(t, http.StatusOK, code, string(body))
+		page := &queryPlansPage{}
+		require.NoError(t, json.Unmarshal(body, page))
+		return page
+	}
+
+	plan1 := &TabletPlan{
+		Plan: &planbuilder.Plan{
+			Table:     &schema.Table{Name: sqlparser.NewTableIdent("t1")},
+			PlanID:    planbuilder.PlanSelect,
+			FullQuery: sqlparser.BuildParsedQuery("select a from t1 where id = :id"),
+		},
+	}
+	plan1.AddStats(10, 2*time.Second, time.Second, 20, 1)
+	tsv.qe.plans.Set("select a from t1 where id=:id", plan1)
+	plan2 := &TabletPlan{
+		Plan: &planbuilder.Plan{
+			Table:  &schema.Table{Name: sqlparser.NewTableIdent("t2")},
+			PlanID: planbuilder.PlanInsert,
+		},
+	}
+	plan2.AddStats(1, time.Millisecond, time.Millisecond, 1, 0)
+	tsv.qe.plans.Set("insert into t2 values (1)", plan2)
+	plan3 := &TabletPlan{
+		Plan: &planbuilder.Plan{PlanID: planbuilder.PlanOtherRead},
+	}
+	tsv.qe.plans.Set("show tables", plan3)
+
+	// The plans are sorted by key.
+	page := getPage("GET", "limit=2")
+	assert.Equal(t, 3, page.Total)
+	require.Len(t, page.Plans, 2)
+	assert.Equal(t, &planStats{
+		Key:        "insert into t2 values (1)",
+		Query:      "insert into t2 values (1)",
+		Table:      "t2",
+		Plan:       "Insert",
+		QueryCount: 1,
+		Time:       time.Millisecond,
+		MysqlTime:  time.Millisecond,
+		RowCount:   1,
+	}, page.Plans[0])
+	assert.Equal(t, "select a from t1 where id = :id", page.Plans[1].Query)
+	assert.Equal(t, "t1", page.Plans[1].Table)
+	assert.Equal(t, int64(10), page.Plans[1].QueryCount)
+	assert.Equal(t, int64(1), page.Plans[1].ErrorCount)
+
+	page = getPage("GET", "offset=2")
+	require.Len(t, page.Plans, 1)
+	assert.Equal(t, "show tables", page.Plans[0].Key)
+	assert.Empty(t, getPage("GET", "offset=5").Plans)
+
+	// A GET doesn't reset the stats.
+	code, body := get("offset=1&limit=1&reset=true")
+	assert.Equal(t, http.StatusMethodNotAllowed, code, string(body))
+	count, _, _, _, _ := plan1.Stats()
+	assert.Equal(t, int64(10), count)
+
+	// Only the returned plans are reset.
+	page = getPage("POST", "offset=1&limit=1&reset=true")
+	require.Len(t, page.Plans, 1)
+	assert.True(t, page.Plans[0].StatsReset)
+	assert.Equal(t, int64(10), page.Plans[0].QueryCount)
+	count, _, _, _, _ = plan1.Stats()
+	assert.Zero(t, count)
+	count, _, _, _, _ = plan2.Stats()
+	assert.Equal(t, int64(1), count)
+
+	code, body = get("limit=0")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, string(body), "invalid limit")
+
+	tsv.StopService()
+	code, body = get("")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	var got map[string]string
+	require.NoError(t, json.Unmarshal(body, &got))
+	assert.Equal(t, "query engine is closed", got["error"])
+	assert.Equal(t, "Not connected to mysql", got["state"])
+}
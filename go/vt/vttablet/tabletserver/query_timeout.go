@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"time"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// loadQueryTimeout returns the query timeout of the tablet types
+// currently served. It's read at every request, so it changes as
+// soon as the tablet type does. The request deadline is the earlier
+// of the client deadline and this timeout.
+func (tsv *TabletServer) loadQueryTimeout() time.Duration {
+	if len(tsv.queryTimeoutOverrides) == 0 {
+		return tsv.QueryTimeout.Get()
+	}
+	return effectiveQueryTimeout(tsv.QueryTimeout.Get(), tsv.queryTimeoutOverrides, tsv.sm.servedTabletTypes())
+}
+
+// effectiveQueryTimeout returns the stricter of the timeouts of
+// tabletTypes. The types without an override use base. A timeout
+// of 0 means no timeout, and only applies if all are 0.
+func effectiveQueryTimeout(base time.Duration, overrides map[topodatapb.TabletType]time.Duration, tabletTypes []topodatapb.TabletType) time.Duration {
+	var timeout time.Duration
+	for _, tabletType := range tabletTypes {
+		t, ok := overrides[tabletType]
+		if !ok {
+			t = base
+		}
+		if t != 0 && (timeout == 0 || t < timeout) {
+			timeout = t
+		}
+	}
+	return timeout
+}
+
+// servedTabletTypes returns the tablet type of sm, followed by
+// the types it also serves during a transition grace period.
+func (sm *stateManager) servedTabletTypes() []topodatapb.TabletType {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return append([]topodatapb.TabletType{sm.target.TabletType}, sm.alsoAllowLocked()...)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+func TestEffectiveQueryTimeout(t *testing.T) {
+	overrides := map[topodatapb.TabletType]time.Duration{
+		topodatapb.TabletType_RDONLY:  time.Hour,
+		topodatapb.TabletType_REPLICA: 0,
+	}
+	testcases := []struct {
+		tabletTypes []topodatapb.TabletType
+		want        time.Duration
+	}{{
+		tabletTypes: []topodatapb.TabletType{topodatapb.TabletType_MASTER},
+		want:        30 * time.Second,
+	}, {
+		tabletTypes: []topodatapb.TabletType{topodatapb.TabletType_RDONLY},
+		want:        time.Hour,
+	}, {
+		tabletTypes: []topodatapb.TabletType{topodatapb.TabletType_REPLICA},
+		want:        0,
+	}, {
+		// The stricter timeout wins during a grace period.
+		tabletTypes: []topodatapb.TabletType{topodatapb.TabletType_MASTER, topodatapb.TabletType_RDONLY},
+		want:        30 * time.Second,
+	}, {
+		tabletTypes: []topodatapb.TabletType{topodatapb.TabletType_MASTER, topodatapb.TabletType_REPLICA},
+		want:        30 * time.Second,
+	}}
+	for _, tcase := range testcases {
+		got := effectiveQueryTimeout(30*time.Second, overrides, tcase.tabletTypes)
+		assert.Equal(t, tcase.want, got, "%v", tcase.tabletTypes)
+	}
+}
+
+func TestQueryTimeoutByTabletType(t *testing.T) {
+	config := tabletenv.NewDefaultConfig()
+	config.Oltp.QueryTimeoutSeconds = 10
+	config.Oltp.QueryTimeoutByTabletType = map[string]tabletenv.Seconds{"rdonly": 100}
+	db, tsv := setupTabletServerTestCustom(t, config)
+	defer tsv.StopService()
+	defer db.Close()
+
+	assert.Equal(t, 10*time.Second, tsv.loadQueryTimeout())
+
+	// The timeout changes with the tablet type, without a qe reopen.
+	err := tsv.SetServingType(context.Background(), topodatapb.TabletType_RDONLY, time.Time{}, true, "")
+	require.NoError(t, err)
+	assert.Equal(t, 100*time.Second, tsv.loadQueryTimeout())
+
+	// A client deadline that's earlier wins.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	ctx2, cancel2 := withTimeout(ctx, tsv.loadQueryTimeout(), nil)
+	defer cancel2()
+	deadline, ok := ctx2.Deadline()
+	require.True(t, ok)
+	assert.True(t, time.Until(deadline) <= time.Second)
+
+	// While the rdonly type is also allowed, the master timeout applies.
+	values := tsv.live.Values()
+	values.TransitionGracePeriod = time.Minute
+	require.NoError(t, tsv.live.Reload("test", values))
+	err = tsv.SetServingType(context.Background(), topodatapb.TabletType_MASTER, time.Now(), true, "")
+	require.NoError(t, err)
+	assert.Equal(t, []topodatapb.TabletType{topodatapb.TabletType_MASTER, topodatapb.TabletType_RDONLY}, tsv.sm.servedTabletTypes())
+	assert.Equal(t, 10*time.Second, tsv.loadQueryTimeout())
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// serveReadOnly is called if a serving master can read from mysql,
+// but can't write to it, typically because the disk is full. Instead
+// of shutting down the query service, it closes the components that
+// need to write, and keeps the query engine open for reads. The
+// master then also accepts REPLICA requests.
+func (sm *stateManager) serveReadOnly(ctx context.Context, err error) {
+	if !sm.transitioning.TryAcquire() {
+		// If we're already transitioning, don't interfere.
+		return
+	}
+	defer sm.transitioning.Release()
+	sm.transitionCtx = ctx
+	defer func() { sm.transitionCtx = nil }()
+
+	sm.mu.Lock()
+	if sm.state != StateServing || sm.target.TabletType != topodatapb.TabletType_MASTER || sm.wantState != StateServing || sm.readOnlyErr != nil {
+		sm.mu.Unlock()
+		return
+	}
+	sm.mu.Unlock()
+
+	log.Errorf("MySQL is not accepting writes, serving reads only: %v", err)
+	sm.readOnlyServings.Add(1)
+	sm.closeServing(false)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.readOnlyErr = err
+	sm.broadcastLocked()
+}
+
+// checkReadOnly is called by readOnlyTicks. While a master serves
+// reads only, it checks if mysql accepts writes again, and reopens
+// the components that were closed if it does. If mysql can't be
+// reached at all, CheckMySQL shuts down the query service.
+func (sm *stateManager) checkReadOnly() {
+	if sm.readOnlyError() == nil {
+		return
+	}
+	err := sm.qe.IsMySQLReachable(context.TODO(), true)
+	if err != nil {
+		if _, ok := err.(*mysqlWriteError); !ok {
+			sm.CheckMySQL()
+		}
+		return
+	}
+
+	if !sm.transitioning.TryAcquire() {
+		// The transition takes care of the components.
+		return
+	}
+	defer sm.transitioning.Release()
+	if sm.readOnlyError() == nil {
+		return
+	}
+
+	ctx, cancel := sm.transitionContext()
+	defer cancel()
+	if err := sm.openServing(ctx); err != nil {
+		log.Errorf("Could not resume serving writes, will keep serving reads only: %v", err)
+		sm.closeServing(false)
+		return
+	}
+	log.Info("MySQL is accepting writes again, resuming full service")
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.readOnlyErr = nil
+	sm.broadcastLocked()
+}
+
+// VerifyWritable returns an error if the tablet is serving reads
+// only for maintenance, or a retryable error if the master is serving
+// reads only because mysql fails writes.
+func (sm *stateManager) VerifyWritable() error {
+	if sm.State() == StateServingReadOnly {
+		return vterrors.New(vtrpcpb.Code_FAILED_PRECONDITION, "writes not allowed in state SERVING_READ_ONLY")
+	}
+	if err := sm.readOnlyError(); err != nil {
+		return vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "serving reads only: %v", err)
+	}
+	return nil
+}
+
+func (sm *stateManager) readOnlyError() error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.readOnlyErr
+}
+
+// alsoAllowLocked returns the tablet types that are served in
+// addition to the target type. A master serving reads only
+// also serves REPLICA requests.
+func (sm *stateManager) alsoAllowLocked() []topodatapb.TabletType {
+	if sm.readOnlyErr == nil {
+		return sm.alsoAllow
+	}
+	alsoAllow := append([]topodatapb.TabletType{}, sm.alsoAllow...)
+	return append(alsoAllow, topodatapb.TabletType_REPLICA)
+}
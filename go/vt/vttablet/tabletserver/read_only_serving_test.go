@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestStateManagerServeReadOnly(t *testing.T) {
+	defer func(saved time.Duration) { readOnlyCheckInterval = saved }(readOnlyCheckInterval)
+	readOnlyCheckInterval = 10 * time.Millisecond
+
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	qe := sm.qe.(*testQueryEngine)
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	replica := &querypb.Target{TabletType: topodatapb.TabletType_REPLICA}
+	err = sm.StartRequest(ctx, replica, false)
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+
+	qe.failWrites.Set(true)
+	sm.CheckMySQL()
+	for sm.readOnlyError() == nil {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The query engine stays open for reads.
+	assert.Equal(t, topodatapb.TabletType_MASTER, sm.Target().TabletType)
+	assert.Equal(t, StateServing, sm.State())
+	assert.True(t, sm.IsServing())
+	assert.Equal(t, testStateOpen, qe.state)
+	assert.Equal(t, testStateClosed, sm.te.(*testTxEngine).state)
+	assert.Equal(t, testStateClosed, sm.messager.(*testSubcomponent).state)
+	assert.Equal(t, testStateClosed, sm.tracker.(*testSubcomponent).state)
+	assert.EqualValues(t, 1, sm.readOnlyServings.Get())
+	assert.Contains(t, detailKeys(sm), "Read Only Serving")
+
+	err = sm.VerifyWritable()
+	assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err))
+	assert.EqualError(t, err, "serving reads only: mysql is not accepting writes: disk full")
+
+	// REPLICA requests are accepted, and the health
+	// stream advertises them.
+	require.NoError(t, sm.StartRequest(ctx, replica, false))
+	sm.EndRequest()
+	require.NoError(t, sm.VerifyTarget(ctx, replica))
+	sm.hs.mu.Lock()
+	assert.Equal(t, []topodatapb.TabletType{topodatapb.TabletType_REPLICA}, sm.hs.state.AlsoAllow)
+	assert.True(t, sm.hs.state.Serving)
+	sm.hs.mu.Unlock()
+
+	// Further write failures don't restart the components.
+	order.Set(0)
+	sm.serveReadOnly(context.Background(), &mysqlWriteError{})
+	assert.EqualValues(t, 0, order.Get())
+
+	// Full service resumes once the writes succeed again.
+	qe.failWrites.Set(false)
+	for sm.readOnlyError() != nil {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, testStateMaster, sm.te.(*testTxEngine).state)
+	assert.Equal(t, testStateOpen, sm.messager.(*testSubcomponent).state)
+	assert.Equal(t, testStateOpen, sm.tracker.(*testSubcomponent).state)
+	require.NoError(t, sm.VerifyWritable())
+	err = sm.StartRequest(ctx, replica, false)
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+}
+
+func TestStateManagerServeReadOnlyNonMaster(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	// Non-masters don't check writes.
+	sm.qe.(*testQueryEngine).failWrites.Set(true)
+	sm.CheckMySQL()
+	sm.serveReadOnly(context.Background(), &mysqlWriteError{})
+	assert.NoError(t, sm.VerifyWritable())
+	assert.Equal(t, testStateNonMaster, sm.te.(*testTxEngine).state)
+}
+
+func TestStateManagerServeReadOnlyTransition(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	sm.serveReadOnly(context.Background(), &mysqlWriteError{})
+	require.Error(t, sm.VerifyWritable())
+
+	// A transition clears read-only serving.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.NoError(t, sm.VerifyWritable())
+	assert.Equal(t, testStateNonMaster, sm.te.(*testTxEngine).state)
+	err = sm.StartRequest(context.Background(), &querypb.Target{TabletType: topodatapb.TabletType_MASTER}, false)
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+}
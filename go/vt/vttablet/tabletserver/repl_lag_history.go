@@ -0,0 +1,200 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/repltracker"
+)
+
+// replLagSampleBytes is the estimated size of a replLagSample.
+const replLagSampleBytes = 256
+
+// replLagSample is a replication lag sample, taken by
+// refreshReplHealthLocked, along with the health decision
+// and the thresholds it was made with.
+type replLagSample struct {
+	Time       time.Time `json:"time"`
+	TabletType string    `json:"tablet_type"`
+	State      string    `json:"state"`
+	LagSeconds float64   `json:"lag_seconds"`
+	Source     string    `json:"source,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Healthy    bool      `json:"healthy"`
+
+	DegradedThresholdSeconds  float64 `json:"degraded_threshold_seconds"`
+	UnhealthyThresholdSeconds float64 `json:"unhealthy_threshold_seconds"`
+}
+
+// registerLagHistoryMemory adds the replication lag history to the
+// memory accounting. It's shrunk before the health history.
+func (sm *stateManager) registerLagHistoryMemory() {
+	if sm.lagHistory == nil {
+		return
+	}
+	mem := sm.memory.register("replLagHistory", lagHistoryPriority, func(maxBytes int64) int64 {
+		if length := int(maxBytes / replLagSampleBytes); length < sm.lagHistory.Cap() {
+			if length < 1 {
+				length = 1
+			}
+			sm.lagHistory.Resize(length)
+		}
+		return int64(sm.lagHistory.Cap()) * replLagSampleBytes
+	})
+	mem.Set(int64(sm.lagHistory.Cap()) * replLagSampleBytes)
+}
+
+// recordLagSampleLocked adds a sample to the replication lag history,
+// if it's enabled. The samples are recorded whatever the state, so
+// that the recovery of a tablet that stopped serving can be seen.
+func (sm *stateManager) recordLagSampleLocked(lag time.Duration, source repltracker.LagSource, err error) {
+	if sm.lagHistory == nil {
+		return
+	}
+	sample := &replLagSample{
+		Time:                      sm.clock.Now(),
+		TabletType:                sm.target.TabletType.String(),
+		State:                     sm.state.String(),
+		LagSeconds:                lag.Seconds(),
+		Source:                    source.String(),
+		Healthy:                   sm.replHealthy,
+		DegradedThresholdSeconds:  sm.live.DegradedThreshold().Seconds(),
+		UnhealthyThresholdSeconds: sm.live.UnhealthyThreshold().Seconds(),
+	}
+	if err != nil {
+		sample.Error = err.Error()
+	}
+	sm.lagHistory.Add(sample)
+}
+
+// lagHistorySize returns the number of samples the replication lag
+// history can keep, which the memory cap may have reduced.
+func (sm *stateManager) lagHistorySize() int {
+	if sm.lagHistory == nil {
+		return 0
+	}
+	return sm.lagHistory.Cap()
+}
+
+// LagHistory returns the replication lag samples, oldest first.
+func (sm *stateManager) LagHistory() []*replLagSample {
+	if sm.lagHistory == nil {
+		return nil
+	}
+	records := sm.lagHistory.Records()
+	samples := make([]*replLagSample, 0, len(records))
+	for i := len(records) - 1; i >= 0; i-- {
+		samples = append(samples, records[i].(*replLagSample))
+	}
+	return samples
+}
+
+// registerReplLagHistoryHandler registers /debug/repl_lag_history,
+// which shows the replication lag samples as a sparkline and a table,
+// or as JSON if the format parameter is json.
+func (tsv *TabletServer) registerReplLagHistoryHandler() {
+	tsv.exporter.HandleFunc("/debug/repl_lag_history", func(w http.ResponseWriter, r *http.Request) {
+		replLagHistoryHandler(w, r, tsv.sm.LagHistory())
+	})
+}
+
+var replLagHistoryTmpl = template.Must(template.New("replLagHistory").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Replication lag history</title></head>
+<body>
+<h1>Replication lag history</h1>
+{{if .Samples}}
+<svg width="{{.Width}}" height="{{.Height}}" style="border: 1px solid #ccc">
+  <line x1="0" y1="{{.UnhealthyY}}" x2="{{.Width}}" y2="{{.UnhealthyY}}" stroke="red" stroke-dasharray="4"/>
+  <line x1="0" y1="{{.DegradedY}}" x2="{{.Width}}" y2="{{.DegradedY}}" stroke="orange" stroke-dasharray="4"/>
+  <polyline points="{{.Points}}" fill="none" stroke="steelblue"/>
+</svg>
+<p>From {{.From}} to {{.To}}. Max lag: {{.MaxLag}}s. The dashed lines are the last degraded (orange) and unhealthy (red) thresholds.</p>
+<table border="1" cellpadding="2">
+<tr><th>Time</th><th>Tablet type</th><th>State</th><th>Lag (s)</th><th>Source</th><th>Healthy</th><th>Degraded threshold (s)</th><th>Unhealthy threshold (s)</th><th>Error</th></tr>
+{{range .Samples}}<tr><td>{{.Time.Format "2006-01-02 15:04:05"}}</td><td>{{.TabletType}}</td><td>{{.State}}</td><td>{{.LagSeconds}}</td><td>{{.Source}}</td><td>{{.Healthy}}</td><td>{{.DegradedThresholdSeconds}}</td><td>{{.UnhealthyThresholdSeconds}}</td><td>{{.Error}}</td></tr>
+{{end}}</table>
+{{else}}
+<p>No samples.</p>
+{{end}}
+</body>
+</html>
+`))
+
+// replLagSparkline is the data of replLagHistoryTmpl. The
+// sparkline is scaled to the max of the lags and thresholds.
+type replLagSparkline struct {
+	Samples               []*replLagSample
+	Width, Height         int
+	Points                string
+	DegradedY, UnhealthyY float64
+	From, To              string
+	MaxLag                float64
+}
+
+func replLagHistoryHandler(w http.ResponseWriter, r *http.Request, samples []*replLagSample) {
+	if err := acl.CheckAccessHTTP(r, acl.MONITORING); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+	if r.FormValue("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(samples)
+		return
+	}
+	data := replLagSparkline{Samples: samples, Width: 600, Height: 100}
+	if len(samples) != 0 {
+		last := samples[len(samples)-1]
+		scale := last.UnhealthyThresholdSeconds
+		for _, s := range samples {
+			if s.LagSeconds > data.MaxLag {
+				data.MaxLag = s.LagSeconds
+			}
+		}
+		if data.MaxLag > scale {
+			scale = data.MaxLag
+		}
+		y := func(lag float64) float64 {
+			if scale == 0 {
+				return float64(data.Height)
+			}
+			return float64(data.Height) * (1 - lag/scale)
+		}
+		points := make([]string, 0, len(samples))
+		for i, s := range samples {
+			x := 0.0
+			if len(samples) > 1 {
+				x = float64(data.Width) * float64(i) / float64(len(samples)-1)
+			}
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x, y(s.LagSeconds)))
+		}
+		data.Points = strings.Join(points, " ")
+		data.DegradedY, data.UnhealthyY = y(last.DegradedThresholdSeconds), y(last.UnhealthyThresholdSeconds)
+		data.From, data.To = samples[0].Time.Format(time.RFC3339), last.Time.Format(time.RFC3339)
+	}
+	if err := replLagHistoryTmpl.Execute(w, data); err != nil {
+		log.Errorf("repl_lag_history: couldn't execute template: %v", err)
+	}
+}
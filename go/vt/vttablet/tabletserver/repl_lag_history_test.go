@@ -0,0 +1,115 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/history"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/repltracker"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestReplLagHistory(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	sm.lagHistory = history.New(3)
+	rt := sm.rt.(*testReplTracker)
+	rt.source = repltracker.LagSourceHeartbeat
+
+	sm.mu.Lock()
+	sm.target.TabletType = topodatapb.TabletType_REPLICA
+	sm.mu.Unlock()
+	// The samples are recorded even if the tablet doesn't serve.
+	rt.lag = time.Second
+	sm.Broadcast()
+	rt.lag = 3 * time.Hour
+	sm.Broadcast()
+	rt.err = errors.New("replication stopped")
+	sm.Broadcast()
+	rt.lag, rt.err = 2*time.Second, nil
+	sm.Broadcast()
+
+	// The oldest sample was dropped.
+	samples := sm.LagHistory()
+	require.Len(t, samples, 3)
+	assert.Equal(t, 3*time.Hour.Seconds(), samples[0].LagSeconds)
+	assert.False(t, samples[0].Healthy)
+	assert.Equal(t, "replication stopped", samples[1].Error)
+	assert.False(t, samples[1].Healthy)
+	assert.Equal(t, 2.0, samples[2].LagSeconds)
+	assert.True(t, samples[2].Healthy)
+	assert.Equal(t, "heartbeat", samples[2].Source)
+	assert.Equal(t, "REPLICA", samples[2].TabletType)
+	assert.Equal(t, StateNotConnected.String(), samples[2].State)
+	assert.Equal(t, sm.live.DegradedThreshold().Seconds(), samples[2].DegradedThresholdSeconds)
+	assert.Equal(t, sm.live.UnhealthyThreshold().Seconds(), samples[2].UnhealthyThresholdSeconds)
+
+	// A disabled history records nothing.
+	sm.lagHistory = nil
+	sm.Broadcast()
+	assert.Empty(t, sm.LagHistory())
+}
+
+func TestReplLagHistoryHandler(t *testing.T) {
+	samples := []*replLagSample{{
+		Time:                      testNow,
+		TabletType:                "REPLICA",
+		State:                     "Serving",
+		LagSeconds:                1,
+		Healthy:                   true,
+		DegradedThresholdSeconds:  30,
+		UnhealthyThresholdSeconds: 7200,
+	}, {
+		Time:                      testNow.Add(time.Minute),
+		TabletType:                "REPLICA",
+		State:                     "Not Serving",
+		LagSeconds:                9000,
+		DegradedThresholdSeconds:  30,
+		UnhealthyThresholdSeconds: 7200,
+	}}
+
+	w := httptest.NewRecorder()
+	replLagHistoryHandler(w, httptest.NewRequest(http.MethodGet, "/debug/repl_lag_history?format=json", nil), samples)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var got []*replLagSample
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	require.Len(t, got, 2)
+	assert.Equal(t, 9000.0, got[1].LagSeconds)
+	assert.Equal(t, 7200.0, got[1].UnhealthyThresholdSeconds)
+
+	// The sparkline is scaled to the max lag.
+	w = httptest.NewRecorder()
+	replLagHistoryHandler(w, httptest.NewRequest(http.MethodGet, "/debug/repl_lag_history", nil), samples)
+	body := w.Body.String()
+	assert.Contains(t, body, `points="0.0,100.0 600.0,0.0"`)
+	assert.Contains(t, body, "Max lag: 9000s")
+	assert.Contains(t, body, "<td>Not Serving</td>")
+
+	w = httptest.NewRecorder()
+	replLagHistoryHandler(w, httptest.NewRequest(http.MethodGet, "/debug/repl_lag_history", nil), nil)
+	assert.Contains(t, w.Body.String(), "No samples.")
+}
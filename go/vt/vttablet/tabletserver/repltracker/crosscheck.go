@@ -0,0 +1,118 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repltracker
+
+import (
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+// suspiciousLag is the lag below which the reported value
+// is verified against the state of replication.
+const suspiciousLag = 1 * time.Second
+
+var errLagUnreliable = vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "replication stopped (lag unreliable)")
+
+// crossChecker verifies that a low replication lag is backed by
+// running replication. A broken replication stream can otherwise
+// report zero lag and keep a replica serving stale data.
+// The check is rate limited to once per interval. In between,
+// the result of the last check is returned.
+type crossChecker struct {
+	enabled bool
+	// expectWrites is set if the master writes heartbeats. If so,
+	// the retrieved GTID set must advance between two checks.
+	// Otherwise, an idle master legitimately produces no events.
+	expectWrites bool
+	interval     time.Duration
+	now          func() time.Time
+
+	mysqld mysqlctl.MysqlDaemon
+
+	mu        sync.Mutex
+	lastCheck time.Time
+	lastPos   mysql.Position
+	lastErr   error
+}
+
+func newCrossChecker(env tabletenv.Env) *crossChecker {
+	config := env.Config()
+	if !config.ReplicationTracker.CrossCheck || config.ReplicationTracker.Mode == tabletenv.Disable {
+		return &crossChecker{}
+	}
+	return &crossChecker{
+		enabled:      true,
+		expectWrites: config.ReplicationTracker.Mode == tabletenv.Heartbeat,
+		interval:     config.ReplicationTracker.CrossCheckIntervalSeconds.Get(),
+		now:          time.Now,
+	}
+}
+
+func (cc *crossChecker) InitDBConfig(mysqld mysqlctl.MysqlDaemon) {
+	cc.mysqld = mysqld
+}
+
+// Check returns an error if the lag cannot be trusted.
+func (cc *crossChecker) Check(lag time.Duration) error {
+	if !cc.enabled || lag >= suspiciousLag {
+		return nil
+	}
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	now := cc.now()
+	if !cc.lastCheck.IsZero() && now.Sub(cc.lastCheck) < cc.interval {
+		return cc.lastErr
+	}
+	cc.lastCheck = now
+	cc.lastErr = cc.verifyLocked()
+	if cc.lastErr != nil {
+		crossCheckFailures.Add(1)
+	}
+	return cc.lastErr
+}
+
+func (cc *crossChecker) verifyLocked() error {
+	status, err := cc.mysqld.ReplicationStatus()
+	if err != nil {
+		return err
+	}
+	if !status.ReplicationRunning() {
+		cc.lastPos = mysql.Position{}
+		return errLagUnreliable
+	}
+
+	// Some flavors don't expose the retrieved GTID set.
+	// Fall back to the executed one.
+	pos := status.RelayLogPosition
+	if pos.IsZero() {
+		pos = status.Position
+	}
+	prev := cc.lastPos
+	cc.lastPos = pos
+	if cc.expectWrites && !prev.IsZero() && pos.Equal(prev) {
+		return errLagUnreliable
+	}
+	return nil
+}
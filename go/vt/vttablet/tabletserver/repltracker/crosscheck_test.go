@@ -0,0 +1,127 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repltracker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/mysqlctl/fakemysqldaemon"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+func newTestCrossChecker(mode string) (*crossChecker, *fakemysqldaemon.FakeMysqlDaemon, *time.Time) {
+	config := tabletenv.NewDefaultConfig()
+	config.ReplicationTracker.Mode = mode
+	config.ReplicationTracker.CrossCheck = true
+	config.ReplicationTracker.CrossCheckIntervalSeconds = 10
+	cc := newCrossChecker(tabletenv.NewEnv(config, "CrossCheckTest"))
+
+	now := time.Now()
+	cc.now = func() time.Time { return now }
+
+	mysqld := fakemysqldaemon.NewFakeMysqlDaemon(nil)
+	mysqld.Replicating = true
+	mysqld.IOThreadRunning = true
+	mysqld.CurrentMasterPosition = testPosition(1)
+	cc.InitDBConfig(mysqld)
+	return cc, mysqld, &now
+}
+
+func testPosition(seq uint64) mysql.Position {
+	return mysql.Position{
+		GTIDSet: mysql.MariadbGTIDSet{0: mysql.MariadbGTID{Domain: 0, Server: 1, Sequence: seq}},
+	}
+}
+
+func TestCrossCheckDisabled(t *testing.T) {
+	config := tabletenv.NewDefaultConfig()
+	config.ReplicationTracker.Mode = tabletenv.Polling
+	cc := newCrossChecker(tabletenv.NewEnv(config, "CrossCheckTest"))
+	assert.False(t, cc.enabled)
+	assert.NoError(t, cc.Check(0))
+
+	config.ReplicationTracker.Mode = tabletenv.Disable
+	config.ReplicationTracker.CrossCheck = true
+	cc = newCrossChecker(tabletenv.NewEnv(config, "CrossCheckTest"))
+	assert.False(t, cc.enabled)
+}
+
+func TestCrossCheckStoppedThreads(t *testing.T) {
+	cc, mysqld, now := newTestCrossChecker(tabletenv.Polling)
+
+	mysqld.IOThreadRunning = false
+	assert.Equal(t, errLagUnreliable, cc.Check(0))
+
+	// High lag is not suspicious.
+	*now = now.Add(time.Minute)
+	assert.NoError(t, cc.Check(2*time.Second))
+
+	mysqld.IOThreadRunning = true
+	mysqld.Replicating = false
+	*now = now.Add(time.Minute)
+	assert.Equal(t, errLagUnreliable, cc.Check(0))
+
+	mysqld.Replicating = true
+	*now = now.Add(time.Minute)
+	assert.NoError(t, cc.Check(0))
+}
+
+func TestCrossCheckIdleReplication(t *testing.T) {
+	// Without heartbeats, an idle master produces no events.
+	// The retrieved GTID set not advancing must not be flagged.
+	cc, _, now := newTestCrossChecker(tabletenv.Polling)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, cc.Check(0))
+		*now = now.Add(time.Minute)
+	}
+}
+
+func TestCrossCheckStalledPosition(t *testing.T) {
+	// With heartbeats, the retrieved GTID set must advance.
+	cc, mysqld, now := newTestCrossChecker(tabletenv.Heartbeat)
+	assert.NoError(t, cc.Check(0))
+
+	*now = now.Add(time.Minute)
+	mysqld.CurrentMasterPosition = testPosition(2)
+	assert.NoError(t, cc.Check(0))
+
+	*now = now.Add(time.Minute)
+	assert.Equal(t, errLagUnreliable, cc.Check(0))
+
+	*now = now.Add(time.Minute)
+	mysqld.CurrentMasterPosition = testPosition(3)
+	assert.NoError(t, cc.Check(0))
+}
+
+func TestCrossCheckInterval(t *testing.T) {
+	cc, mysqld, now := newTestCrossChecker(tabletenv.Polling)
+
+	mysqld.ReplicationStatusError = errors.New("err")
+	assert.EqualError(t, cc.Check(0), "err")
+
+	// The last result is returned until the interval elapses.
+	mysqld.ReplicationStatusError = nil
+	*now = now.Add(5 * time.Second)
+	assert.EqualError(t, cc.Check(0), "err")
+
+	*now = now.Add(5 * time.Second)
+	assert.NoError(t, cc.Check(0))
+}
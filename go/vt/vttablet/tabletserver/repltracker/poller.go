@@ -20,6 +20,7 @@ import (
 	"sync"
 	"time"
 
+	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/vt/mysqlctl"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/vterrors"
@@ -42,6 +43,9 @@ func (p *poller) Status() (time.Duration, error) {
 	defer p.mu.Unlock()
 
 	status, err := p.mysqld.ReplicationStatus()
+	if err == mysql.ErrNotReplica {
+		return 0, ErrNotConfigured
+	}
 	if err != nil {
 		return 0, err
 	}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/vt/mysqlctl/fakemysqldaemon"
 )
 
@@ -34,6 +35,10 @@ func TestPoller(t *testing.T) {
 	_, err := poller.Status()
 	assert.Equal(t, "err", err.Error())
 
+	mysqld.ReplicationStatusError = mysql.ErrNotReplica
+	_, err = poller.Status()
+	assert.Equal(t, ErrNotConfigured, err)
+
 	mysqld.ReplicationStatusError = nil
 	mysqld.Replicating = false
 	_, err = poller.Status()
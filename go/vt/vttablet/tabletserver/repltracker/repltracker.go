@@ -17,20 +17,62 @@ limitations under the License.
 package repltracker
 
 import (
+	"fmt"
 	"sync"
 	"time"
 
+	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/mysqlctl"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 )
 
+// ErrNotConfigured is returned by Status if mysql is not set up
+// to replicate from anywhere. This is the case for a freshly
+// provisioned replica that has not been restored yet.
+var ErrNotConfigured = vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "replication is not configured")
+
+// LagSource tells where the lag reported by Status comes from.
+type LagSource int
+
+const (
+	// LagSourceNone is reported when no lag is measured: on a
+	// master, or if the tracker is disabled or not configured.
+	LagSourceNone = LagSource(iota)
+	// LagSourceHeartbeat is the lag of the last heartbeat read.
+	LagSourceHeartbeat
+	// LagSourceReplicaStatus is the lag reported by mysql in
+	// the replica status.
+	LagSourceReplicaStatus
+	// LagSourceFallback is the lag of the replica status, used
+	// because the heartbeat failed.
+	LagSourceFallback
+)
+
+func (source LagSource) String() string {
+	switch source {
+	case LagSourceHeartbeat:
+		return "heartbeat"
+	case LagSourceReplicaStatus:
+		return "replica status"
+	case LagSourceFallback:
+		return "replica status (heartbeat fallback)"
+	}
+	return ""
+}
+
 var (
+	// HeartbeatFallbacks keeps a count of the times the replica status was used because the heartbeat failed.
+	fallbacks = stats.NewCounter("HeartbeatFallbacks", "Count of times the replication lag was read from the replica status because the heartbeat failed")
 	// HeartbeatWrites keeps a count of the number of heartbeats written over time.
 	writes = stats.NewCounter("HeartbeatWrites", "Count of heartbeats written over time")
+	// HeartbeatWritesSkipped keeps a count of the heartbeat writes skipped because the writer was paused or closed.
+	skippedWrites = stats.NewCounter("HeartbeatWritesSkipped", "Count of heartbeat writes skipped because the writer was paused or closed")
 	// HeartbeatWriteErrors keeps a count of errors encountered while writing heartbeats.
 	writeErrors = stats.NewCounter("HeartbeatWriteErrors", "Count of errors encountered while writing heartbeats")
 	// HeartbeatReads keeps a count of the number of heartbeats read over time.
@@ -46,18 +88,26 @@ var (
 	heartbeatLagNsHistogram = stats.NewGenericHistogram("HeartbeatLagNsHistogram",
 		"Histogram of lag values in nanoseconds", []int64{0, 1e6, 1e7, 1e8, 1e9, 1e10, 1e11, 1e12},
 		[]string{"0", "1ms", "10ms", "100ms", "1s", "10s", "100s", "1000s", ">1000s"}, "Count", "Total")
+	// crossCheckFailures keeps a count of the times a low lag could not be backed by running replication.
+	crossCheckFailures = stats.NewCounter("ReplicationLagCrossCheckFailures", "Count of times a low replication lag could not be backed by running replication")
 )
 
 // ReplTracker tracks replication lag.
 type ReplTracker struct {
-	mode string
+	mode   string
+	mysqld mysqlctl.MysqlDaemon
 
 	mu       sync.Mutex
 	isMaster bool
+	// configured and configuredAt cache the last check of
+	// isConfiguredCached in disable mode.
+	configured   bool
+	configuredAt time.Time
 
 	hw     *heartbeatWriter
 	hr     *heartbeatReader
 	poller *poller
+	cc     *crossChecker
 }
 
 // NewReplTracker creates a new ReplTracker.
@@ -67,14 +117,17 @@ func NewReplTracker(env tabletenv.Env, alias topodatapb.TabletAlias) *ReplTracke
 		hw:     newHeartbeatWriter(env, alias),
 		hr:     newHeartbeatReader(env),
 		poller: &poller{},
+		cc:     newCrossChecker(env),
 	}
 }
 
 // InitDBConfig initializes the target name.
 func (rt *ReplTracker) InitDBConfig(target querypb.Target, mysqld mysqlctl.MysqlDaemon) {
+	rt.mysqld = mysqld
 	rt.hw.InitDBConfig(target)
 	rt.hr.InitDBConfig(target)
 	rt.poller.InitDBConfig(mysqld)
+	rt.cc.InitDBConfig(mysqld)
 }
 
 // MakeMaster must be called if the tablet type becomes MASTER.
@@ -107,6 +160,15 @@ func (rt *ReplTracker) MakeNonMaster() {
 	}
 }
 
+// PauseWrites stops the heartbeat writes until the next MakeMaster.
+// It's called at the start of a demotion, before mysql is made
+// read-only, since MakeNonMaster only comes at its end.
+func (rt *ReplTracker) PauseWrites() {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.hw.pauseWrites()
+}
+
 // Close closes ReplTracker.
 func (rt *ReplTracker) Close() {
 	rt.hw.Close()
@@ -114,19 +176,114 @@ func (rt *ReplTracker) Close() {
 	log.Info("Replication Tracker: closed")
 }
 
-// Status reports the replication status.
-func (rt *ReplTracker) Status() (time.Duration, error) {
+// Status reports the replication status, and where the lag comes
+// from. In heartbeat mode, the replica status is used as a fallback
+// if the heartbeat fails. If both fail, the error mentions both.
+func (rt *ReplTracker) Status() (time.Duration, LagSource, error) {
 	rt.mu.Lock()
 	defer rt.mu.Unlock()
 
 	switch {
-	case rt.isMaster || rt.mode == tabletenv.Disable:
-		return 0, nil
-	case rt.mode == tabletenv.Heartbeat:
-		return rt.hr.Status()
+	case rt.isMaster:
+		return 0, LagSourceNone, nil
+	case rt.mode == tabletenv.Polling:
+		// The poller detects unconfigured replication by itself.
+		lag, err := rt.poller.Status()
+		if err == ErrNotConfigured {
+			return 0, LagSourceNone, err
+		}
+		return lag, LagSourceReplicaStatus, err
+	}
+	if rt.mode == tabletenv.Disable {
+		if !rt.isConfiguredCached() {
+			return 0, LagSourceNone, ErrNotConfigured
+		}
+		return 0, LagSourceNone, nil
+	}
+	// rt.mode == tabletenv.Heartbeat
+	// Replication is only checked if the heartbeat has no data:
+	// the replica status fallback reports if it's not configured.
+	lag, err := rt.hr.Status()
+	if err == nil || rt.mysqld == nil {
+		return lag, LagSourceHeartbeat, err
+	}
+	lag, fallbackErr := rt.poller.Status()
+	if fallbackErr == ErrNotConfigured {
+		return 0, LagSourceNone, ErrNotConfigured
+	}
+	fallbacks.Add(1)
+	if fallbackErr != nil {
+		return 0, LagSourceFallback, fmt.Errorf("heartbeat failed: %v, and the replica status fallback failed: %v", err, fallbackErr)
+	}
+	return lag, LagSourceFallback, nil
+}
+
+// configuredCheckInterval is how long a tracker in disable mode
+// reuses its last check that replication is configured. Status is
+// called under the lock of the state manager: it must not query
+// mysql every time. It's a var for tests.
+var configuredCheckInterval = 10 * time.Second
+
+// isConfiguredCached returns false only if mysql positively reports
+// that it has no replication status. Other errors are ignored. The
+// result is reused for configuredCheckInterval. rt.mu must be held.
+func (rt *ReplTracker) isConfiguredCached() bool {
+	if rt.mysqld == nil {
+		return true
+	}
+	if !rt.configuredAt.IsZero() && time.Since(rt.configuredAt) < configuredCheckInterval {
+		return rt.configured
+	}
+	_, err := rt.mysqld.ReplicationStatus()
+	rt.configured, rt.configuredAt = err != mysql.ErrNotReplica, time.Now()
+	return rt.configured
+}
+
+// RoleStatus is the state of replication that tells whether
+// a master is likely to really be the master of its shard.
+type RoleStatus struct {
+	// Replicating is set if mysql is configured to replicate
+	// from another server, which a true master shouldn't be.
+	Replicating bool
+	// SemiSync is set if semi-sync is enabled on the master side.
+	// SemiSyncClients is then the number of connected semi-sync
+	// replicas.
+	SemiSync        bool
+	SemiSyncClients uint32
+}
+
+// RoleStatus reports the state of replication as seen from a master.
+func (rt *ReplTracker) RoleStatus() (RoleStatus, error) {
+	var rs RoleStatus
+	if rt.mysqld == nil {
+		return rs, nil
+	}
+	_, err := rt.mysqld.ReplicationStatus()
+	switch err {
+	case nil:
+		rs.Replicating = true
+	case mysql.ErrNotReplica:
+	default:
+		return rs, err
+	}
+	if rs.SemiSync, _ = rt.mysqld.SemiSyncEnabled(); rs.SemiSync {
+		if rs.SemiSyncClients, err = rt.mysqld.SemiSyncClients(); err != nil {
+			return rs, err
+		}
+	}
+	return rs, nil
+}
+
+// CrossCheck verifies a lag reported by Status against the state
+// of replication. It returns an error if the lag cannot be trusted.
+func (rt *ReplTracker) CrossCheck(lag time.Duration) error {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if rt.isMaster {
+		return nil
 	}
-	// rt.mode == tabletenv.Poller
-	return rt.poller.Status()
+	return rt.cc.Check(lag)
 }
 
 // EnableHeartbeat enables or disables writes of heartbeat. This functionality
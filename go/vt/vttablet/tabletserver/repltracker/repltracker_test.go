@@ -22,6 +22,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/mysql/fakesqldb"
 	"vitess.io/vitess/go/vt/dbconfigs"
 	"vitess.io/vitess/go/vt/mysqlctl/fakemysqldaemon"
@@ -59,9 +61,10 @@ func TestReplTracker(t *testing.T) {
 	assert.False(t, rt.hr.isOpen)
 	assert.True(t, rt.isMaster)
 
-	lag, err := rt.Status()
+	lag, source, err := rt.Status()
 	assert.NoError(t, err)
 	assert.Equal(t, time.Duration(0), lag)
+	assert.Equal(t, LagSourceNone, source)
 
 	rt.MakeNonMaster()
 	assert.False(t, rt.hw.isOpen)
@@ -69,9 +72,10 @@ func TestReplTracker(t *testing.T) {
 	assert.False(t, rt.isMaster)
 
 	rt.hr.lastKnownLag = 1 * time.Second
-	lag, err = rt.Status()
+	lag, source, err = rt.Status()
 	assert.NoError(t, err)
 	assert.Equal(t, 1*time.Second, lag)
+	assert.Equal(t, LagSourceHeartbeat, source)
 
 	rt.Close()
 	assert.False(t, rt.hw.isOpen)
@@ -90,7 +94,124 @@ func TestReplTracker(t *testing.T) {
 	assert.False(t, rt.hr.isOpen)
 	assert.False(t, rt.isMaster)
 
+	mysqld.Replicating = true
+	mysqld.SecondsBehindMaster = 3
+	lag, source, err = rt.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, 3*time.Second, lag)
+	assert.Equal(t, LagSourceReplicaStatus, source)
+
 	mysqld.ReplicationStatusError = errors.New("err")
-	_, err = rt.Status()
+	_, source, err = rt.Status()
 	assert.Equal(t, "err", err.Error())
+	assert.Equal(t, LagSourceReplicaStatus, source)
+}
+
+func TestReplTrackerNotConfigured(t *testing.T) {
+	defer func(saved time.Duration) { configuredCheckInterval = saved }(configuredCheckInterval)
+	configuredCheckInterval = 0
+	for _, mode := range []string{tabletenv.Disable, tabletenv.Polling, tabletenv.Heartbeat} {
+		t.Run(mode, func(t *testing.T) {
+			config := tabletenv.NewDefaultConfig()
+			config.ReplicationTracker.Mode = mode
+			env := tabletenv.NewEnv(config, "ReplTrackerTest")
+			mysqld := fakemysqldaemon.NewFakeMysqlDaemon(nil)
+			mysqld.Replicating = true
+			rt := NewReplTracker(env, topodatapb.TabletAlias{})
+			rt.InitDBConfig(querypb.Target{}, mysqld)
+
+			_, _, err := rt.Status()
+			assert.NotEqual(t, ErrNotConfigured, err)
+
+			mysqld.ReplicationStatusError = mysql.ErrNotReplica
+			if mode == tabletenv.Heartbeat {
+				// Replication isn't checked while the heartbeat has data.
+				_, _, err = rt.Status()
+				assert.NoError(t, err)
+				rt.hr.lastKnownError = errors.New("no heartbeat")
+			}
+			_, source, err := rt.Status()
+			assert.Equal(t, ErrNotConfigured, err)
+			assert.Equal(t, LagSourceNone, source)
+
+			// A master is not expected to replicate.
+			rt.isMaster = true
+			_, _, err = rt.Status()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestReplTrackerHeartbeatFallback(t *testing.T) {
+	config := tabletenv.NewDefaultConfig()
+	config.ReplicationTracker.Mode = tabletenv.Heartbeat
+	env := tabletenv.NewEnv(config, "ReplTrackerTest")
+	mysqld := fakemysqldaemon.NewFakeMysqlDaemon(nil)
+	mysqld.Replicating = true
+	mysqld.SecondsBehindMaster = 5
+	rt := NewReplTracker(env, topodatapb.TabletAlias{})
+	rt.InitDBConfig(querypb.Target{}, mysqld)
+
+	rt.hr.lastKnownLag = 1 * time.Second
+	lag, source, err := rt.Status()
+	require.NoError(t, err)
+	assert.Equal(t, 1*time.Second, lag)
+	assert.Equal(t, LagSourceHeartbeat, source)
+
+	// The replica status is used while the heartbeat fails.
+	rt.hr.lastKnownError = errors.New("heartbeat err")
+	lag, source, err = rt.Status()
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, lag)
+	assert.Equal(t, LagSourceFallback, source)
+	assert.Equal(t, "replica status (heartbeat fallback)", source.String())
+
+	// The error mentions both sources if both fail.
+	mysqld.ReplicationStatusError = errors.New("status err")
+	_, source, err = rt.Status()
+	assert.EqualError(t, err, "heartbeat failed: heartbeat err, and the replica status fallback failed: status err")
+	assert.Equal(t, LagSourceFallback, source)
+}
+
+func TestReplTrackerRoleStatus(t *testing.T) {
+	env := tabletenv.NewEnv(tabletenv.NewDefaultConfig(), "ReplTrackerTest")
+	mysqld := fakemysqldaemon.NewFakeMysqlDaemon(nil)
+	rt := NewReplTracker(env, topodatapb.TabletAlias{})
+	rt.InitDBConfig(querypb.Target{}, mysqld)
+
+	rs, err := rt.RoleStatus()
+	require.NoError(t, err)
+	assert.Equal(t, RoleStatus{Replicating: true}, rs)
+
+	mysqld.ReplicationStatusError = mysql.ErrNotReplica
+	mysqld.SemiSyncMasterEnabled = true
+	mysqld.SemiSyncMasterClients = 2
+	rs, err = rt.RoleStatus()
+	require.NoError(t, err)
+	assert.Equal(t, RoleStatus{SemiSync: true, SemiSyncClients: 2}, rs)
+
+	mysqld.ReplicationStatusError = errors.New("err")
+	_, err = rt.RoleStatus()
+	assert.EqualError(t, err, "err")
+}
+
+func TestReplTrackerNotConfiguredCached(t *testing.T) {
+	config := tabletenv.NewDefaultConfig()
+	config.ReplicationTracker.Mode = tabletenv.Disable
+	env := tabletenv.NewEnv(config, "ReplTrackerTest")
+	mysqld := fakemysqldaemon.NewFakeMysqlDaemon(nil)
+	mysqld.Replicating = true
+	rt := NewReplTracker(env, topodatapb.TabletAlias{})
+	rt.InitDBConfig(querypb.Target{}, mysqld)
+
+	_, _, err := rt.Status()
+	require.NoError(t, err)
+	// The last check is reused.
+	mysqld.ReplicationStatusError = mysql.ErrNotReplica
+	_, _, err = rt.Status()
+	require.NoError(t, err)
+
+	rt.configuredAt = time.Now().Add(-configuredCheckInterval)
+	_, _, err = rt.Status()
+	assert.Equal(t, ErrNotConfigured, err)
 }
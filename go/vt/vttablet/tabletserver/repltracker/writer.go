@@ -26,6 +26,7 @@ import (
 	"golang.org/x/net/context"
 
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/sync2"
 	"vitess.io/vitess/go/timer"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/logutil"
@@ -68,6 +69,10 @@ type heartbeatWriter struct {
 	isOpen bool
 	pool   *connpool.Pool
 	ticks  *timer.Timer
+
+	// writesPaused is set by Close and pauseWrites, and cleared by
+	// Open. The writes attempted meanwhile are skipped and counted.
+	writesPaused sync2.AtomicBool
 }
 
 // newHeartbeatWriter creates a new heartbeatWriter.
@@ -103,15 +108,37 @@ func (w *heartbeatWriter) Open() {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 	if w.isOpen {
+		if w.writesPaused.Get() {
+			log.Info("Hearbeat Writer: resuming writes")
+			w.writesPaused.Set(false)
+			w.enableWrites(true)
+		}
 		return
 	}
 	log.Info("Hearbeat Writer: opening")
 
 	w.pool.Open(w.env.Config().DB.AppWithDB(), w.env.Config().DB.DbaWithDB(), w.env.Config().DB.AppDebugWithDB())
+	w.writesPaused.Set(false)
 	w.enableWrites(true)
 	w.isOpen = true
 }
 
+// pauseWrites stops the writes without closing the writer. When it
+// returns, no write is in progress. The writes resume at the next Open.
+func (w *heartbeatWriter) pauseWrites() {
+	if !w.enabled {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.isOpen || w.writesPaused.Get() {
+		return
+	}
+	w.writesPaused.Set(true)
+	w.enableWrites(false)
+	log.Info("Hearbeat Writer: writes paused")
+}
+
 // Close closes the heartbeatWriter's db connection and stops the periodic ticker.
 func (w *heartbeatWriter) Close() {
 	if !w.enabled {
@@ -123,6 +150,7 @@ func (w *heartbeatWriter) Close() {
 		return
 	}
 
+	w.writesPaused.Set(true)
 	w.enableWrites(false)
 	w.pool.Close()
 	w.isOpen = false
@@ -148,6 +176,10 @@ func (w *heartbeatWriter) bindHeartbeatVars(query string) (string, error) {
 
 // writeHeartbeat updates the heartbeat row for this tablet with the current time in nanoseconds.
 func (w *heartbeatWriter) writeHeartbeat() {
+	if w.writesPaused.Get() {
+		skippedWrites.Add(1)
+		return
+	}
 	if err := w.write(); err != nil {
 		w.recordError(err)
 		return
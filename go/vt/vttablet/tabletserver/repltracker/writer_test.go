@@ -92,6 +92,63 @@ func TestWriteHeartbeatError(t *testing.T) {
 	assert.Equal(t, int64(1), writeErrors.Get())
 }
 
+func TestWriterPauseWrites(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+
+	tw := newTestWriter(db, mockNowFunc)
+	tw.isOpen = true
+	tw.enableWrites(true)
+	defer tw.Close()
+	upsert := fmt.Sprintf("INSERT INTO %s.heartbeat (ts, tabletUid, keyspaceShard) VALUES (%d, %d, '%s') ON DUPLICATE KEY UPDATE ts=VALUES(ts), tabletUid=VALUES(tabletUid)",
+		"_vt", now.UnixNano(), tw.tabletAlias.Uid, tw.keyspaceShard)
+	db.AddQuery(upsert, &sqltypes.Result{})
+
+	writes.Reset()
+	skippedWrites.Reset()
+
+	// Block a write midway.
+	started := make(chan struct{})
+	release := make(chan struct{})
+	db.SetBeforeFunc(upsert, func() {
+		started <- struct{}{}
+		<-release
+	})
+	tw.ticks.Trigger()
+	<-started
+
+	// pauseWrites waits for the write in progress.
+	paused := make(chan struct{})
+	go func() {
+		tw.pauseWrites()
+		close(paused)
+	}()
+	select {
+	case <-paused:
+		t.Fatal("pauseWrites returned while a write was in progress")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	<-paused
+	assert.Equal(t, int64(1), writes.Get())
+
+	tw.writeHeartbeat()
+	assert.Equal(t, int64(1), writes.Get())
+	assert.Equal(t, int64(1), skippedWrites.Get())
+	assert.Equal(t, 1, db.GetQueryCalledNum(upsert))
+
+	// Open resumes the writes.
+	db.SetBeforeFunc(upsert, func() {
+		started <- struct{}{}
+	})
+	tw.Open()
+	tw.ticks.Trigger()
+	<-started
+	tw.pauseWrites()
+	assert.Equal(t, int64(2), writes.Get())
+	assert.Equal(t, int64(1), skippedWrites.Get())
+}
+
 func newTestWriter(db *fakesqldb.DB, nowFunc func() time.Time) *heartbeatWriter {
 	config := tabletenv.NewDefaultConfig()
 	config.ReplicationTracker.Mode = tabletenv.Heartbeat
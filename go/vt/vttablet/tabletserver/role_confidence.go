@@ -0,0 +1,199 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/repltracker"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// The weights of the role confidence factors. They add up to 100.
+const (
+	termWeight        = 10
+	topoWeight        = 30
+	replicationWeight = 30
+	semiSyncWeight    = 30
+)
+
+// roleConfidence estimates how likely a master is to really be
+// the master of its shard. After a messy failover, two tablets
+// can both believe that they're the master. The confidence lets
+// operators and tools tell which one is likely wrong.
+type roleConfidence struct {
+	// Confidence is the sum of the weights of the factors
+	// that are ok, in percent.
+	Confidence int           `json:"confidence"`
+	Degraded   bool          `json:"degraded"`
+	Factors    []*roleFactor `json:"factors"`
+}
+
+// roleFactor is one of the signals that contribute to the
+// role confidence.
+type roleFactor struct {
+	Name   string `json:"name"`
+	Weight int    `json:"weight"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+}
+
+// newRoleConfidence combines the factors. If threshold is set,
+// a confidence below it is reported as degraded.
+func newRoleConfidence(factors []*roleFactor, threshold int) *roleConfidence {
+	rc := &roleConfidence{Factors: factors}
+	for _, f := range factors {
+		if f.OK {
+			rc.Confidence += f.Weight
+		}
+	}
+	rc.Degraded = threshold != 0 && rc.Confidence < threshold
+	return rc
+}
+
+// String returns the confidence followed by the factors that
+// lowered it.
+func (rc *roleConfidence) String() string {
+	var failed []string
+	for _, f := range rc.Factors {
+		if !f.OK {
+			failed = append(failed, fmt.Sprintf("%s: %s", f.Name, f.Detail))
+		}
+	}
+	if len(failed) == 0 {
+		return fmt.Sprintf("%d%%", rc.Confidence)
+	}
+	return fmt.Sprintf("%d%% (%s)", rc.Confidence, strings.Join(failed, ", "))
+}
+
+// roleSample is the last replication state sampled by
+// sampleRoleStatus.
+type roleSample struct {
+	status repltracker.RoleStatus
+	err    error
+}
+
+// sampleRoleStatus samples the replication state of a serving master
+// for its role confidence. It's called by roleStatusTicks if the role
+// confidence is enabled. The replication state is read from mysql
+// outside of sm.mu: the broadcasts only use the last sample.
+func (sm *stateManager) sampleRoleStatus() {
+	sm.mu.Lock()
+	if sm.target.TabletType != topodatapb.TabletType_MASTER || !sm.state.serving() {
+		sm.roleSample = nil
+		sm.mu.Unlock()
+		return
+	}
+	sm.mu.Unlock()
+	rs, err := sm.rt.RoleStatus()
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.roleSample = &roleSample{status: rs, err: err}
+}
+
+// refreshRoleConfidenceLocked recomputes the role confidence of
+// a serving master from the last sample of its replication state.
+// There's no confidence until the first sample, or if the role
+// confidence is disabled. It's cleared for other tablets.
+func (sm *stateManager) refreshRoleConfidenceLocked() {
+	if sm.target.TabletType != topodatapb.TabletType_MASTER || !sm.state.serving() {
+		sm.roleConfidence, sm.roleSample = nil, nil
+		return
+	}
+	if sm.roleConfidenceThreshold == 0 || sm.roleSample == nil {
+		sm.roleConfidence = nil
+		return
+	}
+	wasDegraded := sm.roleConfidence != nil && sm.roleConfidence.Degraded
+	sm.roleConfidence = newRoleConfidence(sm.roleFactorsLocked(), sm.roleConfidenceThreshold)
+	if sm.roleConfidence.Degraded != wasDegraded {
+		if sm.roleConfidence.Degraded {
+			log.Warningf("Master role confidence is low: %v", sm.roleConfidence)
+		} else {
+			log.Infof("Master role confidence is back to %v", sm.roleConfidence)
+		}
+	}
+}
+
+func (sm *stateManager) roleFactorsLocked() []*roleFactor {
+	now := sm.clock.Now()
+	term := &roleFactor{Name: "term", Weight: termWeight}
+	switch {
+	case sm.terTimestamp.IsZero():
+		term.Detail = "term start is unknown"
+	case sm.terTimestamp.After(now):
+		term.Detail = "term starts in the future"
+	default:
+		term.OK = true
+		term.Detail = fmt.Sprintf("term started %v ago", now.Sub(sm.terTimestamp).Round(time.Second))
+	}
+
+	topo := &roleFactor{Name: "topo", Weight: topoWeight}
+	switch {
+	case sm.wantTabletType != topodatapb.TabletType_MASTER || !sm.wantState.serving():
+		topo.Detail = fmt.Sprintf("topo wants %v", sm.stateStringLocked(sm.wantTabletType, sm.wantState))
+	case sm.topoIsolated:
+		topo.Detail = "topo isolated"
+	case sm.topoIsolationTimeout != 0 && now.Sub(sm.topoLastSeen) > sm.topoIsolationTimeout:
+		topo.Detail = fmt.Sprintf("topo last seen %v ago", now.Sub(sm.topoLastSeen).Round(time.Second))
+	default:
+		topo.OK = true
+		topo.Detail = "topo agrees"
+	}
+
+	replication := &roleFactor{Name: "replication", Weight: replicationWeight}
+	semiSync := &roleFactor{Name: "semiSync", Weight: semiSyncWeight}
+	rs, err := sm.roleSample.status, sm.roleSample.err
+	switch {
+	case err != nil:
+		replication.Detail = fmt.Sprintf("cannot check replication: %v", err)
+		semiSync.Detail = replication.Detail
+		return []*roleFactor{term, topo, replication, semiSync}
+	case rs.Replicating:
+		replication.Detail = "replication is configured"
+	default:
+		replication.OK = true
+		replication.Detail = "not replicating"
+	}
+	switch {
+	case !rs.SemiSync:
+		semiSync.OK = true
+		semiSync.Detail = "semi-sync is disabled"
+	case rs.SemiSyncClients == 0:
+		semiSync.Detail = "no semi-sync replicas connected"
+	default:
+		semiSync.OK = true
+		semiSync.Detail = fmt.Sprintf("%d semi-sync replicas connected", rs.SemiSyncClients)
+	}
+	return []*roleFactor{term, topo, replication, semiSync}
+}
+
+// roleConfidenceGauge returns the role confidence of a serving
+// master, or -1 for other tablets.
+func (sm *stateManager) roleConfidenceGauge() int64 {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.roleConfidence == nil {
+		return -1
+	}
+	return int64(sm.roleConfidence.Confidence)
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/timer/fakeclock"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/repltracker"
+)
+
+func TestRoleConfidence(t *testing.T) {
+	testcases := []struct {
+		name         string
+		noTerm       bool
+		terTimestamp time.Time
+		topoIsolated bool
+		topoLastSeen time.Duration
+		roleStatus   repltracker.RoleStatus
+		roleErr      error
+		threshold    int
+		confidence   int
+		degraded     bool
+		failed       []string
+	}{{
+		name:       "all agree",
+		roleStatus: repltracker.RoleStatus{SemiSync: true, SemiSyncClients: 2},
+		confidence: 100,
+	}, {
+		name:       "unknown term",
+		noTerm:     true,
+		confidence: 90,
+		failed:     []string{"term"},
+	}, {
+		name:         "term in the future",
+		terTimestamp: testNow.Add(2 * time.Hour),
+		confidence:   90,
+		failed:       []string{"term"},
+	}, {
+		name:         "topo isolated",
+		topoIsolated: true,
+		confidence:   70,
+		failed:       []string{"topo"},
+	}, {
+		name:         "topo not seen",
+		topoLastSeen: 2 * time.Minute,
+		confidence:   70,
+		failed:       []string{"topo"},
+	}, {
+		name:       "replicating",
+		roleStatus: repltracker.RoleStatus{Replicating: true},
+		threshold:  80,
+		confidence: 70,
+		degraded:   true,
+		failed:     []string{"replication"},
+	}, {
+		name:       "no semi-sync replicas",
+		roleStatus: repltracker.RoleStatus{SemiSync: true},
+		threshold:  70,
+		confidence: 70,
+		failed:     []string{"semiSync"},
+	}, {
+		name:         "replicating and topo isolated",
+		roleStatus:   repltracker.RoleStatus{Replicating: true},
+		topoIsolated: true,
+		threshold:    50,
+		confidence:   40,
+		degraded:     true,
+		failed:       []string{"topo", "replication"},
+	}, {
+		name:       "replication check error",
+		roleErr:    errors.New("mysql down"),
+		confidence: 40,
+		failed:     []string{"replication", "semiSync"},
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			fc := fakeclock.New(testNow.Add(time.Hour))
+			sm := newTestStateManagerWithClock(t, fc)
+			defer sm.StopService()
+			terTimestamp := testNow
+			switch {
+			case tcase.noTerm:
+				terTimestamp = time.Time{}
+			case !tcase.terTimestamp.IsZero():
+				terTimestamp = tcase.terTimestamp
+			}
+			err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, terTimestamp, StateServing, "")
+			require.NoError(t, err)
+
+			rt := sm.rt.(*testReplTracker)
+			rt.roleStatus, rt.roleErr = tcase.roleStatus, tcase.roleErr
+			// The factors are checked without degrading
+			// the confidence if there's no threshold.
+			threshold := tcase.threshold
+			if threshold == 0 {
+				threshold = 1
+			}
+			sm.sampleRoleStatus()
+			sm.mu.Lock()
+			sm.roleConfidenceThreshold = threshold
+			sm.topoIsolationTimeout = time.Minute
+			sm.topoLastSeen = fc.Now().Add(-tcase.topoLastSeen)
+			sm.topoIsolated = tcase.topoIsolated
+			sm.mu.Unlock()
+			sm.Broadcast()
+
+			rc := sm.Status().RoleConfidence
+			require.NotNil(t, rc)
+			assert.Equal(t, tcase.confidence, rc.Confidence)
+			assert.Equal(t, tcase.degraded, rc.Degraded)
+			var failed []string
+			for _, f := range rc.Factors {
+				if !f.OK {
+					failed = append(failed, f.Name)
+				}
+			}
+			assert.Equal(t, tcase.failed, failed)
+			assert.Equal(t, int64(tcase.confidence), sm.roleConfidenceGauge())
+
+			shr := sm.hs.state
+			assert.Equal(t, uint32(tcase.confidence), shr.RealtimeStats.RoleConfidence)
+			assert.Equal(t, tcase.degraded, shr.RealtimeStats.RoleDegraded)
+
+			// A low confidence doesn't change serving.
+			assert.Equal(t, StateServing, sm.State())
+		})
+	}
+}
+
+func TestRoleConfidenceNonMaster(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	sm.roleConfidenceThreshold = 50
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	sm.sampleRoleStatus()
+	sm.Broadcast()
+	assert.Equal(t, int64(100), sm.roleConfidenceGauge())
+	assert.Contains(t, detailKeys(sm), "Role Confidence")
+
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	sm.Broadcast()
+	assert.Nil(t, sm.Status().RoleConfidence)
+	assert.Equal(t, int64(-1), sm.roleConfidenceGauge())
+	assert.Zero(t, sm.hs.state.RealtimeStats.RoleConfidence)
+	assert.NotContains(t, detailKeys(sm), "Role Confidence")
+}
+
+func TestRoleConfidenceString(t *testing.T) {
+	rc := newRoleConfidence([]*roleFactor{
+		{Name: "term", Weight: termWeight, OK: true, Detail: "term started 1h0m0s ago"},
+		{Name: "topo", Weight: topoWeight, Detail: "topo isolated"},
+		{Name: "replication", Weight: replicationWeight, Detail: "replication is configured"},
+		{Name: "semiSync", Weight: semiSyncWeight, OK: true, Detail: "semi-sync is disabled"},
+	}, 0)
+	assert.False(t, rc.Degraded)
+	assert.Equal(t, "40% (topo: topo isolated, replication: replication is configured)", rc.String())
+}
+
+func TestRoleConfidenceSampling(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	rt := sm.rt.(*testReplTracker)
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	// It's disabled by default.
+	assert.False(t, sm.roleStatusTicks.Running())
+	sm.sampleRoleStatus()
+	sm.Broadcast()
+	assert.Nil(t, sm.Status().RoleConfidence)
+
+	// The broadcasts only use the last sample.
+	sm.mu.Lock()
+	sm.roleConfidenceThreshold = 50
+	sm.roleSample = nil
+	sm.mu.Unlock()
+	sm.Broadcast()
+	assert.Nil(t, sm.Status().RoleConfidence)
+	sm.sampleRoleStatus()
+	rt.roleStatus = repltracker.RoleStatus{Replicating: true}
+	sm.Broadcast()
+	assert.Equal(t, 100, sm.Status().RoleConfidence.Confidence)
+	sm.sampleRoleStatus()
+	sm.Broadcast()
+	assert.Equal(t, 70, sm.Status().RoleConfidence.Confidence)
+
+	// The sample of a former master is dropped.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	sm.sampleRoleStatus()
+	sm.mu.Lock()
+	assert.Nil(t, sm.roleSample)
+	sm.mu.Unlock()
+}
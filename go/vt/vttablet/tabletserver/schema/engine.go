@@ -21,33 +21,40 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"vitess.io/vitess/go/vt/dbconnpool"
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
 
+	"github.com/golang/protobuf/proto"
 	"golang.org/x/net/context"
 
 	"vitess.io/vitess/go/acl"
 	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/timer"
 	"vitess.io/vitess/go/vt/concurrency"
 	"vitess.io/vitess/go/vt/dbconfigs"
 	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/connpool"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
-	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
 const maxTableCount = 10000
 
-type notifier func(full map[string]*Table, created, altered, dropped []string)
+// Notifier is the function invoked on schema changes. See RegisterNotifier.
+type Notifier func(full map[string]*Table, created, altered, dropped []string)
 
 // Engine stores the schema info and performs operations that
 // keep itself up-to-date.
@@ -64,7 +71,7 @@ type Engine struct {
 	//the position at which the schema was last loaded. it is only used in conjunction with ReloadAt
 	reloadAtPos mysql.Position
 	notifierMu  sync.Mutex
-	notifiers   map[string]notifier
+	notifiers   map[string]Notifier
 
 	// SkipMetaCheck skips the metadata about the database and table information
 	SkipMetaCheck bool
@@ -74,8 +81,32 @@ type Engine struct {
 	conns *connpool.Pool
 	ticks *timer.Timer
 
+	reloadTimings   *servenv.TimingsWrapper
+	tablesRefreshed *stats.Counter
+
 	// dbCreationFailed is for preventing log spam.
 	dbCreationFailed bool
+
+	// serverIdentity is the identity of the mysql server that
+	// EnsureConnectionAndDB last connected to. It's protected
+	// by mu.
+	serverIdentity ServerIdentity
+}
+
+// ServerIdentity identifies a mysql server. It's the zero value
+// if the server couldn't be identified.
+type ServerIdentity struct {
+	UUID string `json:"uuid,omitempty"`
+	ID   uint32 `json:"id,omitempty"`
+}
+
+// IsZero returns true if the server couldn't be identified.
+func (si ServerIdentity) IsZero() bool {
+	return si == ServerIdentity{}
+}
+
+func (si ServerIdentity) String() string {
+	return fmt.Sprintf("server_uuid %s, server_id %d", si.UUID, si.ID)
 }
 
 // NewEngine creates a new Engine.
@@ -89,8 +120,10 @@ func NewEngine(env tabletenv.Env) *Engine {
 			Size:               3,
 			IdleTimeoutSeconds: env.Config().OltpReadPool.IdleTimeoutSeconds,
 		}),
-		ticks:      timer.NewTimer(reloadTime),
-		reloadTime: reloadTime,
+		ticks:           timer.NewTimer(reloadTime),
+		reloadTime:      reloadTime,
+		reloadTimings:   env.Exporter().NewTimings("SchemaReloads", "Schema reloads, by type: full or incremental", "type"),
+		tablesRefreshed: env.Exporter().NewCounter("SchemaTablesRefreshed", "Number of tables whose schema was fetched by schema reloads"),
 	}
 	_ = env.Exporter().NewGaugeDurationFunc("SchemaReloadTime", "vttablet keeps table schemas in its own memory and periodically refreshes it from MySQL. This config controls the reload time.", se.ticks.Interval)
 
@@ -98,7 +131,7 @@ func NewEngine(env tabletenv.Env) *Engine {
 	env.Exporter().HandleFunc("/schemaz", func(w http.ResponseWriter, r *http.Request) {
 		// Ensure schema engine is Open. If vttablet came up in a non_serving role,
 		// the schema engine may not have been initialized.
-		err := se.Open()
+		err := se.Open(r.Context())
 		if err != nil {
 			w.Write([]byte(err.Error()))
 			return
@@ -116,51 +149,167 @@ func (se *Engine) InitDBConfig(cp dbconfigs.Connector) {
 }
 
 // EnsureConnectionAndDB ensures that we can connect to mysql.
-// If tablet type is master and there is no db, then the database is created.
-// This function can be called before opening the Engine.
-func (se *Engine) EnsureConnectionAndDB(tabletType topodatapb.TabletType) error {
-	ctx := tabletenv.LocalContext()
+// If createDB is set and there is no db, then the database is created,
+// with the configured character set and collation.
+// It returns true if the database was created by this call.
+// This function can be called before opening the Engine. The
+// connection attempts are abandoned if ctx is done.
+func (se *Engine) EnsureConnectionAndDB(ctx context.Context, createDB bool) (bool, error) {
 	conn, err := dbconnpool.NewDBConnection(ctx, se.env.Config().DB.AppWithDB())
 	if err == nil {
+		if createDB {
+			se.checkDBCharset(conn)
+		}
+		se.recordServerIdentity(conn)
 		conn.Close()
 		se.dbCreationFailed = false
-		return nil
+		return false, nil
 	}
-	if tabletType != topodatapb.TabletType_MASTER {
-		return err
+	if !createDB {
+		return false, err
 	}
 	if merr, isSQLErr := err.(*mysql.SQLError); !isSQLErr || merr.Num != mysql.ERBadDb {
-		return err
+		return false, err
 	}
 
 	// We are master and db is not found. Let's create it.
 	// We use allprivs instead of DBA because we want db create to fail if we're read-only.
 	conn, err = dbconnpool.NewDBConnection(ctx, se.env.Config().DB.AllPrivsConnector())
 	if err != nil {
-		return vterrors.Wrap(err, "allprivs connection failed")
+		return false, vterrors.Wrap(err, "allprivs connection failed")
 	}
 	defer conn.Close()
 
 	dbname := se.env.Config().DB.DBName
-	_, err = conn.ExecuteFetch(fmt.Sprintf("create database if not exists `%s`", dbname), 1, false)
+	charsetClause, err := se.dbCharsetClause(conn)
+	if err != nil {
+		return false, err
+	}
+	_, err = conn.ExecuteFetch(fmt.Sprintf("create database if not exists `%s`%s", dbname, charsetClause), 1, false)
 	if err != nil {
 		if !se.dbCreationFailed {
 			// This is the first failure.
 			log.Errorf("db creation failed for %v: %v, will keep retrying", dbname, err)
 			se.dbCreationFailed = true
 		}
-		return err
+		return false, err
 	}
 
-	se.dbCreationFailed = false
 	log.Infof("db %v created", dbname)
 	se.dbCreationFailed = false
-	return nil
+	// The database may have been created by someone else in the meantime.
+	se.checkDBCharset(conn)
+	se.recordServerIdentity(conn)
+	return true, nil
+}
+
+// recordServerIdentity fetches the identity of the mysql server conn
+// is connected to. If it can't be fetched, the identity is cleared:
+// it's a best effort, servers that don't have a server_uuid can't be
+// identified.
+func (se *Engine) recordServerIdentity(conn *dbconnpool.DBConnection) {
+	var identity ServerIdentity
+	qr, err := conn.ExecuteFetch("select @@global.server_uuid, @@global.server_id", 1, false)
+	if err == nil && len(qr.Rows) == 1 {
+		var id uint64
+		id, err = evalengine.ToUint64(qr.Rows[0][1])
+		identity = ServerIdentity{UUID: qr.Rows[0][0].ToString(), ID: uint32(id)}
+	}
+	if err != nil {
+		log.Warningf("Could not fetch the identity of the mysql server: %v", err)
+		identity = ServerIdentity{}
+	}
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	se.serverIdentity = identity
+}
+
+// ServerIdentity returns the identity of the mysql server that
+// EnsureConnectionAndDB last connected to.
+func (se *Engine) ServerIdentity() ServerIdentity {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	return se.serverIdentity
+}
+
+// IsReadOnly returns true if mysql is read-only. super_read_only
+// implies read_only, so read_only is the only one checked.
+func (se *Engine) IsReadOnly() (bool, error) {
+	conn, err := dbconnpool.NewDBConnection(tabletenv.LocalContext(), se.env.Config().DB.AppWithDB())
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	qr, err := conn.ExecuteFetch("select @@global.read_only", 1, false)
+	if err != nil {
+		return false, vterrors.Wrap(err, "could not check if mysql is read-only")
+	}
+	if len(qr.Rows) != 1 {
+		return false, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected result for read_only: %v", qr.Rows)
+	}
+	readOnly, err := evalengine.ToInt64(qr.Rows[0][0])
+	if err != nil {
+		return false, err
+	}
+	return readOnly != 0, nil
+}
+
+// dbCharsetClause returns the character set and collation clause
+// of the create database statement. The configured character set
+// and collation are validated against the ones MySQL supports.
+func (se *Engine) dbCharsetClause(conn *dbconnpool.DBConnection) (string, error) {
+	charset, collation := se.env.Config().DBCreateCharset, se.env.Config().DBCreateCollation
+	if charset == "" && collation == "" {
+		return "", nil
+	}
+	var conditions []string
+	clause := ""
+	if charset != "" {
+		conditions = append(conditions, fmt.Sprintf("character_set_name = %s", encodeString(charset)))
+		clause += " character set " + charset
+	}
+	if collation != "" {
+		conditions = append(conditions, fmt.Sprintf("collation_name = %s", encodeString(collation)))
+		clause += " collate " + collation
+	}
+	qr, err := conn.ExecuteFetch("select collation_name from information_schema.collations where "+strings.Join(conditions, " and ")+" limit 1", 1, false)
+	if err != nil {
+		return "", vterrors.Wrap(err, "could not validate the database character set")
+	}
+	if len(qr.Rows) == 0 {
+		return "", vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "database character set '%s' and collation '%s' are not supported by MySQL", charset, collation)
+	}
+	return clause, nil
+}
+
+// checkDBCharset logs a warning if the database exists with a
+// character set or collation other than the configured ones.
+// The database is not altered.
+func (se *Engine) checkDBCharset(conn *dbconnpool.DBConnection) {
+	charset, collation := se.env.Config().DBCreateCharset, se.env.Config().DBCreateCollation
+	if charset == "" && collation == "" {
+		return
+	}
+	dbname := se.env.Config().DB.DBName
+	qr, err := conn.ExecuteFetch(fmt.Sprintf("select default_character_set_name, default_collation_name from information_schema.schemata where schema_name = %s", encodeString(dbname)), 1, false)
+	if err != nil || len(qr.Rows) == 0 {
+		log.Warningf("Could not check the character set of db %v: %v", dbname, err)
+		return
+	}
+	dbCharset, dbCollation := qr.Rows[0][0].ToString(), qr.Rows[0][1].ToString()
+	if (charset != "" && !strings.EqualFold(charset, dbCharset)) || (collation != "" && !strings.EqualFold(collation, dbCollation)) {
+		log.Warningf("db %v has character set '%s' and collation '%s' instead of the configured '%s' and '%s'", dbname, dbCharset, dbCollation, charset, collation)
+	}
 }
 
 // Open initializes the Engine. Calling Open on an already
 // open engine is a no-op.
-func (se *Engine) Open() error {
+// If the schema was loaded before the engine was last closed,
+// Open reuses it and refreshes it in the background, so that
+// state transitions aren't delayed by the reload of large schemas.
+// Otherwise, the initial load is abandoned if ctx is done. The
+// background reloads don't depend on ctx.
+func (se *Engine) Open(ctx context.Context) error {
 	se.mu.Lock()
 	defer se.mu.Unlock()
 	if se.isOpen {
@@ -168,8 +317,6 @@ func (se *Engine) Open() error {
 	}
 	log.Info("Schema Engine: opening")
 
-	ctx := tabletenv.LocalContext()
-
 	// The function we're in is supposed to be idempotent, but this conns.Open()
 	// call is not itself idempotent. Therefore, if we return for any reason
 	// without marking ourselves as open, we need to call conns.Close() so the
@@ -181,13 +328,16 @@ func (se *Engine) Open() error {
 		}
 	}()
 
-	se.tables = map[string]*Table{
-		"dual": NewTable("dual"),
-	}
-	se.notifiers = make(map[string]notifier)
+	se.notifiers = make(map[string]Notifier)
 
-	if err := se.reload(ctx); err != nil {
-		return err
+	cached := se.lastChange != 0
+	if !cached {
+		se.tables = map[string]*Table{
+			"dual": NewTable("dual"),
+		}
+		if _, err := se.reload(ctx); err != nil {
+			return err
+		}
 	}
 	if !se.SkipMetaCheck {
 		if err := se.historian.Open(); err != nil {
@@ -195,13 +345,21 @@ func (se *Engine) Open() error {
 		}
 	}
 
+	reloadCtx := tabletenv.LocalContext()
 	se.ticks.Start(func() {
-		if err := se.Reload(ctx); err != nil {
+		if err := se.Reload(reloadCtx); err != nil {
 			log.Errorf("periodic schema reload failed: %v", err)
 		}
 	})
 
 	se.isOpen = true
+	if cached {
+		go func() {
+			if err := se.Reload(reloadCtx); err != nil {
+				log.Errorf("schema refresh after open failed: %v", err)
+			}
+		}()
+	}
 	return nil
 }
 
@@ -213,7 +371,8 @@ func (se *Engine) IsOpen() bool {
 }
 
 // Close shuts down Engine and is idempotent.
-// It can be re-opened after Close.
+// It can be re-opened after Close. The schema is kept
+// for the next Open, but the sequence caches are cleared.
 func (se *Engine) Close() {
 	se.mu.Lock()
 	defer se.mu.Unlock()
@@ -225,9 +384,8 @@ func (se *Engine) Close() {
 	se.historian.Close()
 	se.conns.Close()
 
-	se.tables = make(map[string]*Table)
-	se.lastChange = 0
-	se.notifiers = make(map[string]notifier)
+	se.clearSequenceCaches()
+	se.notifiers = make(map[string]Notifier)
 	se.isOpen = false
 	log.Info("Schema Engine: closed")
 }
@@ -238,6 +396,11 @@ func (se *Engine) MakeNonMaster() {
 	// This function is tested through endtoend test.
 	se.mu.Lock()
 	defer se.mu.Unlock()
+	se.clearSequenceCaches()
+}
+
+// clearSequenceCaches must be called while holding a lock on se.mu.
+func (se *Engine) clearSequenceCaches() {
 	for _, t := range se.tables {
 		if t.SequenceInfo != nil {
 			t.SequenceInfo.Lock()
@@ -265,25 +428,39 @@ func (se *Engine) Reload(ctx context.Context) error {
 // It maintains the position at which the schema was reloaded and if the same position is provided
 // (say by multiple vstreams) it returns the cached schema. In case of a newer or empty pos it always reloads the schema
 func (se *Engine) ReloadAt(ctx context.Context, pos mysql.Position) error {
+	_, err := se.reloadAt(ctx, pos)
+	return err
+}
+
+// ReloadTables reloads the schema info from the db like Reload,
+// and returns the sorted names of the tables that were created,
+// altered or dropped.
+func (se *Engine) ReloadTables(ctx context.Context) ([]string, error) {
+	return se.reloadAt(ctx, mysql.Position{})
+}
+
+func (se *Engine) reloadAt(ctx context.Context, pos mysql.Position) ([]string, error) {
 	se.mu.Lock()
 	defer se.mu.Unlock()
 	if !se.isOpen {
 		log.Warning("Schema reload called for an engine that is not yet open")
-		return nil
+		return nil, nil
 	}
 	if !pos.IsZero() && se.reloadAtPos.AtLeast(pos) {
 		log.V(2).Infof("ReloadAt: found cached schema at %s", mysql.EncodePosition(pos))
-		return nil
+		return nil, nil
 	}
-	if err := se.reload(ctx); err != nil {
-		return err
+	changed, err := se.reload(ctx)
+	if err != nil {
+		return nil, err
 	}
 	se.reloadAtPos = pos
-	return nil
+	return changed, nil
 }
 
 // reload reloads the schema. It can also be used to initialize it.
-func (se *Engine) reload(ctx context.Context) error {
+// It returns the sorted names of the tables that changed.
+func (se *Engine) reload(ctx context.Context) ([]string, error) {
 	start := time.Now()
 	defer func() {
 		log.Infof("Time taken to load the schema: %v", time.Since(start))
@@ -292,22 +469,31 @@ func (se *Engine) reload(ctx context.Context) error {
 
 	conn, err := se.conns.Get(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer conn.Recycle()
 
 	// curTime will be saved into lastChange after schema is loaded.
 	curTime, err := se.mysqlTime(ctx, conn)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	// Tables created since the last load are the only ones whose
+	// schema is fetched again. If the MySQL clock went backwards,
+	// their create time can't be trusted, and all of them are fetched.
+	full := curTime < se.lastChange
+	reloadType := "incremental"
+	if se.lastChange == 0 || full {
+		reloadType = "full"
+	}
+	defer se.reloadTimings.Record(reloadType, start)
 	// if this flag is set, then we don't need table meta information
 	if se.SkipMetaCheck {
-		return nil
+		return nil, nil
 	}
 	tableData, err := conn.Exec(ctx, mysql.BaseShowTables, maxTableCount, false)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	rec := concurrency.AllErrorRecorder{}
@@ -317,13 +503,20 @@ func (se *Engine) reload(ctx context.Context) error {
 	changedTables := make(map[string]*Table)
 	// created and altered contain the names of created and altered tables for broadcast.
 	var created, altered []string
+	// views are loaded after the tables, because their columns come from them.
+	var views [][]sqltypes.Value
 	for _, row := range tableData.Rows {
 		tableName := row[0].ToString()
 		curTables[tableName] = true
+		if row[1].ToString() == viewTableType {
+			views = append(views, row)
+			continue
+		}
 		createTime, _ := evalengine.ToInt64(row[2])
 		// TODO(sougou); find a better way detect changed tables. This method
 		// seems unreliable. The endtoend test flags all tables as changed.
-		if _, ok := se.tables[tableName]; ok && createTime < se.lastChange {
+		// Tables without a create time are always fetched.
+		if _, ok := se.tables[tableName]; ok && !full && !row[2].IsNull() && createTime < se.lastChange {
 			continue
 		}
 		log.V(2).Infof("Reading schema for table: %s", tableName)
@@ -340,7 +533,7 @@ func (se *Engine) reload(ctx context.Context) error {
 		}
 	}
 	if rec.HasErrors() {
-		return rec.Error()
+		return nil, rec.Error()
 	}
 
 	// Compute and handle dropped tables.
@@ -353,10 +546,25 @@ func (se *Engine) reload(ctx context.Context) error {
 		delete(se.tables, tableName)
 	}
 
+	changedViews, err := se.loadViews(ctx, conn, views, len(changedTables) != 0 || len(dropped) != 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, view := range changedViews {
+		viewName := view.Name.String()
+		changedTables[viewName] = view
+		if _, ok := se.tables[viewName]; ok {
+			altered = append(altered, viewName)
+		} else {
+			created = append(created, viewName)
+		}
+	}
+
 	// Populate PKColumns for changed tables.
-	if err := se.populatePrimaryKeys(ctx, conn, changedTables); err != nil {
-		return err
+	if err := se.populatePrimaryKeys(ctx, conn, changedTables, len(changedTables) > len(se.tables)/2); err != nil {
+		return nil, err
 	}
+	se.tablesRefreshed.Add(int64(len(changedTables)))
 
 	// Update se.tables and se.lastChange
 	for k, t := range changedTables {
@@ -364,7 +572,78 @@ func (se *Engine) reload(ctx context.Context) error {
 	}
 	se.lastChange = curTime
 	se.broadcast(created, altered, dropped)
-	return nil
+
+	changed := make([]string, 0, len(created)+len(altered)+len(dropped))
+	changed = append(changed, created...)
+	changed = append(changed, altered...)
+	changed = append(changed, dropped...)
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// loadViews returns the views that were created or altered.
+// Views have no create time: a view is reloaded if it's new or its
+// definition changed. Its columns come from the tables and views it
+// selects from, so all the views are reloaded if any of those changed.
+// A view that can't be read because it references a dropped table
+// or column is kept without columns instead of failing the reload.
+func (se *Engine) loadViews(ctx context.Context, conn *connpool.DBConn, views [][]sqltypes.Value, tablesChanged bool) ([]*Table, error) {
+	if len(views) == 0 {
+		return nil, nil
+	}
+	viewData, err := conn.Exec(ctx, mysql.BaseShowViews, maxTableCount, false)
+	if err != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNKNOWN, "could not get view definitions: %v", err)
+	}
+	definitions := make(map[string]string, len(viewData.Rows))
+	for _, row := range viewData.Rows {
+		definitions[row[0].ToString()] = row[1].ToString()
+	}
+
+	reloadAll := tablesChanged
+	for _, row := range views {
+		viewName := row[0].ToString()
+		if old, ok := se.tables[viewName]; !ok || old.Type != View || old.ViewDefinition != definitions[viewName] {
+			reloadAll = true
+			break
+		}
+	}
+	if !reloadAll {
+		return nil, nil
+	}
+
+	var changedViews []*Table
+	for _, row := range views {
+		viewName := row[0].ToString()
+		log.V(2).Infof("Reading schema for view: %s", viewName)
+		view, err := LoadTable(conn, viewName, row[1].ToString(), row[3].ToString())
+		if err != nil {
+			if sqlErr, ok := err.(*mysql.SQLError); !ok || sqlErr.Number() != mysql.ERViewInvalid {
+				return nil, err
+			}
+			log.Warningf("View %s is invalid, its columns are unknown: %v", viewName, err)
+			view = NewTable(viewName)
+			view.Type = View
+		}
+		view.ViewDefinition = definitions[viewName]
+		if old, ok := se.tables[viewName]; ok && old.Type == View && old.ViewDefinition == view.ViewDefinition && fieldsEqual(old.Fields, view.Fields) {
+			continue
+		}
+		changedViews = append(changedViews, view)
+	}
+	return changedViews, nil
+}
+
+func fieldsEqual(fields1, fields2 []*querypb.Field) bool {
+	if len(fields1) != len(fields2) {
+		return false
+	}
+	for i, field := range fields1 {
+		if !proto.Equal(field, fields2[i]) {
+			return false
+		}
+	}
+	return true
 }
 
 func (se *Engine) mysqlTime(ctx context.Context, conn *connpool.DBConn) (int64, error) {
@@ -384,8 +663,21 @@ func (se *Engine) mysqlTime(ctx context.Context, conn *connpool.DBConn) (int64,
 }
 
 // populatePrimaryKeys populates the PKColumns for the specified tables.
-func (se *Engine) populatePrimaryKeys(ctx context.Context, conn *connpool.DBConn, tables map[string]*Table) error {
-	pkData, err := conn.Exec(ctx, mysql.BaseShowPrimary, maxTableCount, false)
+// Unless all is set, only the primary keys of those tables are fetched.
+func (se *Engine) populatePrimaryKeys(ctx context.Context, conn *connpool.DBConn, tables map[string]*Table, all bool) error {
+	if len(tables) == 0 {
+		return nil
+	}
+	query := mysql.BaseShowPrimary
+	if !all {
+		names := make([]string, 0, len(tables))
+		for tableName := range tables {
+			names = append(names, encodeString(tableName))
+		}
+		sort.Strings(names)
+		query = fmt.Sprintf(mysql.BaseShowPrimaryForTables, strings.Join(names, ", "))
+	}
+	pkData, err := conn.Exec(ctx, query, maxTableCount, false)
 	if err != nil {
 		return vterrors.Errorf(vtrpcpb.Code_UNKNOWN, "could not get table primary key info: %v", err)
 	}
@@ -433,7 +725,7 @@ func (se *Engine) GetTableForPos(tableName sqlparser.TableIdent, gtid string) (*
 // It also causes an immediate notification to the caller. The notified
 // function must not change the map or its contents. The only exception
 // is the sequence table where the values can be changed using the lock.
-func (se *Engine) RegisterNotifier(name string, f notifier) {
+func (se *Engine) RegisterNotifier(name string, f Notifier) {
 	if !se.isOpen {
 		return
 	}
@@ -513,7 +805,7 @@ func (se *Engine) handleDebugSchema(response http.ResponseWriter, request *http.
 func (se *Engine) handleHTTPSchema(response http.ResponseWriter, request *http.Request) {
 	// Ensure schema engine is Open. If vttablet came up in a non_serving role,
 	// the schema engine may not have been initialized.
-	err := se.Open()
+	err := se.Open(request.Context())
 	if err != nil {
 		response.Write([]byte(err.Error()))
 		return
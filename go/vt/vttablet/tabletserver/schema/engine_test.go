@@ -54,7 +54,7 @@ func TestOpenAndReload(t *testing.T) {
 		"1427325876",
 	))
 	se := newEngine(10, 10*time.Second, 10*time.Second, true, db)
-	se.Open()
+	se.Open(context.Background())
 	defer se.Close()
 
 	want := initialSchema()
@@ -115,6 +115,23 @@ func TestOpenAndReload(t *testing.T) {
 			mysql.ShowPrimaryRow("seq", "id"),
 		},
 	})
+	// Only the primary keys of the changed tables are fetched.
+	db.AddQuery(fmt.Sprintf(mysql.BaseShowPrimaryForTables, "'test_table_03', 'test_table_04'"), &sqltypes.Result{
+		Fields: mysql.ShowPrimaryFields,
+		Rows: [][]sqltypes.Value{
+			mysql.ShowPrimaryRow("test_table_03", "pk1"),
+			mysql.ShowPrimaryRow("test_table_03", "pk2"),
+			mysql.ShowPrimaryRow("test_table_04", "pk"),
+		},
+	})
+	// test_table_03 was created at the time of the last load, so it's fetched again.
+	db.AddQuery(fmt.Sprintf(mysql.BaseShowPrimaryForTables, "'test_table_03'"), &sqltypes.Result{
+		Fields: mysql.ShowPrimaryFields,
+		Rows: [][]sqltypes.Value{
+			mysql.ShowPrimaryRow("test_table_03", "pk1"),
+			mysql.ShowPrimaryRow("test_table_03", "pk2"),
+		},
+	})
 
 	firstTime := true
 	notifier := func(full map[string]*Table, created, altered, dropped []string) {
@@ -132,8 +149,9 @@ func TestOpenAndReload(t *testing.T) {
 		}
 	}
 	se.RegisterNotifier("test", notifier)
-	err := se.Reload(context.Background())
+	changed, err := se.ReloadTables(context.Background())
 	require.NoError(t, err)
+	assert.Equal(t, []string{"msg", "test_table_03", "test_table_04"}, changed)
 
 	want["test_table_03"] = &Table{
 		Name: sqlparser.NewTableIdent("test_table_03"),
@@ -205,6 +223,209 @@ func TestOpenAndReload(t *testing.T) {
 	assert.Equal(t, want, se.GetSchema())
 }
 
+func TestReloadViews(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	for query, result := range schematest.Queries() {
+		db.AddQuery(query, result)
+	}
+	// pre-advance to above the default 1427325875.
+	db.AddQuery("select unix_timestamp()", sqltypes.MakeTestResult(sqltypes.MakeTestFields(
+		"t",
+		"int64"),
+		"1427325876",
+	))
+	db.AddQuery(mysql.BaseShowTables, &sqltypes.Result{
+		Fields: mysql.BaseShowTablesFields,
+		Rows: [][]sqltypes.Value{
+			mysql.BaseShowTablesRow("test_table_01", false, ""),
+			mysql.BaseShowTablesRow("v1", true, "VIEW"),
+			mysql.BaseShowTablesRow("v2", true, "VIEW"),
+			mysql.BaseShowTablesRow("v3", true, "VIEW"),
+		},
+	})
+	db.AddQuery(mysql.BaseShowPrimary, &sqltypes.Result{
+		Fields: mysql.ShowPrimaryFields,
+		Rows: [][]sqltypes.Value{
+			mysql.ShowPrimaryRow("test_table_01", "pk"),
+		},
+	})
+	// v2 selects from v1, and v3 selects from a dropped table.
+	db.AddQuery(mysql.BaseShowViews, &sqltypes.Result{
+		Fields: mysql.ShowViewsFields,
+		Rows: [][]sqltypes.Value{
+			mysql.ShowViewsRow("v1", "select pk from test_table_01"),
+			mysql.ShowViewsRow("v2", "select pk from v1"),
+			mysql.ShowViewsRow("v3", "select pk from dropped"),
+		},
+	})
+	// Views have no primary key.
+	db.AddQueryPattern(".*table_name IN.*", &sqltypes.Result{Fields: mysql.ShowPrimaryFields})
+	pkFields := []*querypb.Field{{
+		Name: "pk",
+		Type: sqltypes.Int32,
+	}}
+	db.AddQuery("select * from v1 where 1 != 1", &sqltypes.Result{Fields: pkFields})
+	db.AddQuery("select * from v2 where 1 != 1", &sqltypes.Result{Fields: pkFields})
+	db.AddRejectedQuery("select * from v3 where 1 != 1", mysql.NewSQLError(mysql.ERViewInvalid, mysql.SSUnknownSQLState, "View 'fakesqldb.v3' references invalid table(s) or column(s) or function(s) or definer/invoker of view lack rights to use them"))
+
+	se := newEngine(10, 10*time.Second, 10*time.Second, true, db)
+	require.NoError(t, se.Open(context.Background()))
+	defer se.Close()
+
+	newView := func(name, definition string, fields []*querypb.Field) *Table {
+		return &Table{
+			Name:           sqlparser.NewTableIdent(name),
+			Fields:         fields,
+			Type:           View,
+			ViewDefinition: definition,
+		}
+	}
+	want := map[string]*Table{
+		"dual": {
+			Name: sqlparser.NewTableIdent("dual"),
+		},
+		"test_table_01": {
+			Name:      sqlparser.NewTableIdent("test_table_01"),
+			Fields:    pkFields,
+			PKColumns: []int{0},
+		},
+		"v1": newView("v1", "select pk from test_table_01", pkFields),
+		"v2": newView("v2", "select pk from v1", pkFields),
+		"v3": newView("v3", "select pk from dropped", nil),
+	}
+	assert.Equal(t, want, se.GetSchema())
+
+	// Nothing changed.
+	changed, err := se.ReloadTables(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+
+	// Alter v1. The columns of v2 change with it.
+	db.AddQuery(mysql.BaseShowViews, &sqltypes.Result{
+		Fields: mysql.ShowViewsFields,
+		Rows: [][]sqltypes.Value{
+			mysql.ShowViewsRow("v1", "select pk, pk as id from test_table_01"),
+			mysql.ShowViewsRow("v2", "select * from v1"),
+			mysql.ShowViewsRow("v3", "select pk from dropped"),
+		},
+	})
+	v1Fields := []*querypb.Field{{
+		Name: "pk",
+		Type: sqltypes.Int32,
+	}, {
+		Name: "id",
+		Type: sqltypes.Int32,
+	}}
+	db.AddQuery("select * from v1 where 1 != 1", &sqltypes.Result{Fields: v1Fields})
+	db.AddQuery("select * from v2 where 1 != 1", &sqltypes.Result{Fields: v1Fields})
+
+	var notified []string
+	se.RegisterNotifier("test", func(full map[string]*Table, created, altered, dropped []string) {
+		notified = append(notified, fmt.Sprintf("created=%v altered=%v dropped=%v", created, altered, dropped))
+	})
+	changed, err = se.ReloadTables(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1", "v2"}, changed)
+	want["v1"] = newView("v1", "select pk, pk as id from test_table_01", v1Fields)
+	want["v2"] = newView("v2", "select * from v1", v1Fields)
+	assert.Equal(t, want, se.GetSchema())
+
+	// Drop v2, and create the table v3 selects from.
+	db.AddQuery(mysql.BaseShowTables, &sqltypes.Result{
+		Fields: mysql.BaseShowTablesFields,
+		Rows: [][]sqltypes.Value{
+			mysql.BaseShowTablesRow("test_table_01", false, ""),
+			mysql.BaseShowTablesRow("v1", true, "VIEW"),
+			mysql.BaseShowTablesRow("v3", true, "VIEW"),
+		},
+	})
+	db.AddQuery(mysql.BaseShowViews, &sqltypes.Result{
+		Fields: mysql.ShowViewsFields,
+		Rows: [][]sqltypes.Value{
+			mysql.ShowViewsRow("v1", "select pk, pk as id from test_table_01"),
+			mysql.ShowViewsRow("v3", "select pk from dropped"),
+		},
+	})
+	db.DeleteRejectedQuery("select * from v3 where 1 != 1")
+	db.AddQuery("select * from v3 where 1 != 1", &sqltypes.Result{Fields: pkFields})
+	changed, err = se.ReloadTables(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v2", "v3"}, changed)
+	delete(want, "v2")
+	want["v3"] = newView("v3", "select pk from dropped", pkFields)
+	assert.Equal(t, want, se.GetSchema())
+
+	// The first notification is sent on registration.
+	assert.Equal(t, []string{
+		"created=[] altered=[v1 v2] dropped=[]",
+		"created=[] altered=[v3] dropped=[v2]",
+	}, notified[1:])
+}
+
+func TestOpenReusesSchema(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	for query, result := range schematest.Queries() {
+		db.AddQuery(query, result)
+	}
+	se := newEngine(10, 10*time.Second, 10*time.Second, true, db)
+	refreshed := se.tablesRefreshed.Get()
+	require.NoError(t, se.Open(context.Background()))
+	assert.Equal(t, initialSchema(), se.GetSchema())
+	// All the tables are fetched by the first load.
+	assert.EqualValues(t, 5, se.tablesRefreshed.Get()-refreshed)
+
+	seq := se.GetTable(sqlparser.NewTableIdent("seq"))
+	seq.SequenceInfo.NextVal = 10
+	seq.SequenceInfo.LastVal = 20
+	se.Close()
+	assert.Equal(t, &SequenceInfo{}, seq.SequenceInfo)
+
+	// Open doesn't wait for MySQL to list the tables.
+	reloads := func() int64 {
+		var count int64
+		for _, c := range se.reloadTimings.Counts() {
+			count += c
+		}
+		return count
+	}
+	initialReloads := reloads()
+	db.AddRejectedQuery(mysql.BaseShowTables, fmt.Errorf("injected error"))
+	require.NoError(t, se.Open(context.Background()))
+	defer se.Close()
+	assert.Equal(t, initialSchema(), se.GetSchema())
+	// Wait for the refresh to fail.
+	for reloads() == initialReloads {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, initialSchema(), se.GetSchema())
+
+	// The next reload is incremental: only the tables created since
+	// the last load are fetched.
+	db.DeleteRejectedQuery(mysql.BaseShowTables)
+	db.AddQuery("select unix_timestamp()", sqltypes.MakeTestResult(sqltypes.MakeTestFields(
+		"t",
+		"int64"),
+		"1427325876",
+	))
+	db.AddQuery(fmt.Sprintf(mysql.BaseShowPrimaryForTables, "'msg', 'seq', 'test_table_01', 'test_table_02', 'test_table_03'"), schematest.Queries()[mysql.BaseShowPrimary])
+	require.NoError(t, se.Reload(context.Background()))
+	refreshed = se.tablesRefreshed.Get()
+	require.NoError(t, se.Reload(context.Background()))
+	assert.Equal(t, refreshed, se.tablesRefreshed.Get())
+
+	// If the clock of MySQL goes backwards, all the tables are fetched.
+	db.AddQuery("select unix_timestamp()", sqltypes.MakeTestResult(sqltypes.MakeTestFields(
+		"t",
+		"int64"),
+		"1427325870",
+	))
+	require.NoError(t, se.Reload(context.Background()))
+	assert.EqualValues(t, 5, se.tablesRefreshed.Get()-refreshed)
+	assert.Equal(t, initialSchema(), se.GetSchema())
+}
+
 func TestOpenFailedDueToMissMySQLTime(t *testing.T) {
 	db := fakesqldb.New(t)
 	defer db.Close()
@@ -219,7 +440,7 @@ func TestOpenFailedDueToMissMySQLTime(t *testing.T) {
 		},
 	})
 	se := newEngine(10, 1*time.Second, 1*time.Second, false, db)
-	err := se.Open()
+	err := se.Open(context.Background())
 	want := "could not get MySQL time"
 	if err == nil || !strings.Contains(err.Error(), want) {
 		t.Errorf("se.Open: %v, want %s", err, want)
@@ -239,7 +460,7 @@ func TestOpenFailedDueToIncorrectMysqlRowNum(t *testing.T) {
 		},
 	})
 	se := newEngine(10, 1*time.Second, 1*time.Second, false, db)
-	err := se.Open()
+	err := se.Open(context.Background())
 	want := "unexpected result for MySQL time"
 	if err == nil || !strings.Contains(err.Error(), want) {
 		t.Errorf("se.Open: %v, want %s", err, want)
@@ -259,7 +480,7 @@ func TestOpenFailedDueToInvalidTimeFormat(t *testing.T) {
 		},
 	})
 	se := newEngine(10, 1*time.Second, 1*time.Second, false, db)
-	err := se.Open()
+	err := se.Open(context.Background())
 	want := "could not parse time"
 	if err == nil || !strings.Contains(err.Error(), want) {
 		t.Errorf("se.Open: %v, want %s", err, want)
@@ -274,7 +495,7 @@ func TestOpenFailedDueToExecErr(t *testing.T) {
 	}
 	db.AddRejectedQuery(mysql.BaseShowTables, fmt.Errorf("injected error"))
 	se := newEngine(10, 1*time.Second, 1*time.Second, false, db)
-	err := se.Open()
+	err := se.Open(context.Background())
 	want := "injected error"
 	if err == nil || !strings.Contains(err.Error(), want) {
 		t.Errorf("se.Open: %v, want %s", err, want)
@@ -305,13 +526,127 @@ func TestOpenFailedDueToTableErr(t *testing.T) {
 		},
 	})
 	se := newEngine(10, 1*time.Second, 1*time.Second, false, db)
-	err := se.Open()
+	err := se.Open(context.Background())
 	want := "Row count exceeded"
 	if err == nil || !strings.Contains(err.Error(), want) {
 		t.Errorf("se.Open: %v, want %s", err, want)
 	}
 }
 
+func TestEnsureConnectionAndDB(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	params, _ := db.ConnParams().MysqlParams()
+	config := tabletenv.NewDefaultConfig()
+	config.DB = dbconfigs.NewTestDBConfigs(*params, *params, "vttest")
+	config.DBCreateCharset = "utf8mb4"
+	config.DBCreateCollation = "utf8mb4_general_ci"
+	se := NewEngine(tabletenv.NewEnv(config, "SchemaTest"))
+
+	const collationQuery = "select collation_name from information_schema.collations where character_set_name = 'utf8mb4' and collation_name = 'utf8mb4_general_ci' limit 1"
+	const schemataQuery = "select default_character_set_name, default_collation_name from information_schema.schemata where schema_name = 'vttest'"
+	var created []string
+	db.AddQueryPatternWithCallback("create database.*", &sqltypes.Result{}, func(query string) {
+		created = append(created, query)
+	})
+	db.AddQuery(collationQuery, sqltypes.MakeTestResult(sqltypes.MakeTestFields("collation_name", "varchar"), "utf8mb4_general_ci"))
+	db.AddQuery(schemataQuery, sqltypes.MakeTestResult(sqltypes.MakeTestFields("default_character_set_name|default_collation_name", "varchar|varchar"), "utf8mb4|utf8mb4_general_ci"))
+	db.AddRejectedQuery("use `vttest`", mysql.NewSQLError(mysql.ERBadDb, mysql.SSUnknownSQLState, "Unknown database 'vttest'"))
+
+	// A non-master doesn't create the database.
+	ok, err := se.EnsureConnectionAndDB(context.Background(), false)
+	assert.False(t, ok)
+	assert.Contains(t, fmt.Sprint(err), "Unknown database")
+	assert.Empty(t, created)
+
+	// A master creates it, with the configured character set and collation.
+	ok, err = se.EnsureConnectionAndDB(context.Background(), true)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"create database if not exists `vttest` character set utf8mb4 collate utf8mb4_general_ci"}, created)
+
+	// An existing database isn't created again, even if its collation
+	// isn't the configured one.
+	db.DeleteRejectedQuery("use `vttest`")
+	db.AddQuery("use `vttest`", &sqltypes.Result{})
+	db.AddQuery(schemataQuery, sqltypes.MakeTestResult(sqltypes.MakeTestFields("default_character_set_name|default_collation_name", "varchar|varchar"), "latin1|latin1_swedish_ci"))
+	ok, err = se.EnsureConnectionAndDB(context.Background(), true)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 1, db.GetQueryCalledNum(schemataQuery))
+	ok, err = se.EnsureConnectionAndDB(context.Background(), false)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 1, db.GetQueryCalledNum(schemataQuery))
+	assert.Len(t, created, 1)
+
+	// A character set MySQL doesn't support fails the creation.
+	db.AddRejectedQuery("use `vttest`", mysql.NewSQLError(mysql.ERBadDb, mysql.SSUnknownSQLState, "Unknown database 'vttest'"))
+	db.AddQuery(collationQuery, &sqltypes.Result{})
+	_, err = se.EnsureConnectionAndDB(context.Background(), true)
+	assert.EqualError(t, err, "database character set 'utf8mb4' and collation 'utf8mb4_general_ci' are not supported by MySQL")
+	assert.Len(t, created, 1)
+
+	// The connection attempts are abandoned once the context is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = se.EnsureConnectionAndDB(ctx, true)
+	assert.Contains(t, fmt.Sprint(err), "context canceled")
+	assert.Len(t, created, 1)
+}
+
+func TestEnsureConnectionAndDBServerIdentity(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	params, _ := db.ConnParams().MysqlParams()
+	config := tabletenv.NewDefaultConfig()
+	config.DB = dbconfigs.NewTestDBConfigs(*params, *params, "")
+	se := NewEngine(tabletenv.NewEnv(config, "SchemaTest"))
+
+	const identityQuery = "select @@global.server_uuid, @@global.server_id"
+	identityFields := sqltypes.MakeTestFields("@@global.server_uuid|@@global.server_id", "varchar|uint32")
+	db.AddQuery(identityQuery, sqltypes.MakeTestResult(identityFields, "3e11fa47-71ca-11e1-9e33-c80aa9429562|1"))
+	_, err := se.EnsureConnectionAndDB(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, ServerIdentity{UUID: "3e11fa47-71ca-11e1-9e33-c80aa9429562", ID: 1}, se.ServerIdentity())
+
+	db.AddQuery(identityQuery, sqltypes.MakeTestResult(identityFields, "8a94f357-aab4-11df-86ab-c80aa9429562|2"))
+	_, err = se.EnsureConnectionAndDB(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, ServerIdentity{UUID: "8a94f357-aab4-11df-86ab-c80aa9429562", ID: 2}, se.ServerIdentity())
+
+	// A server that can't be identified doesn't fail the connection.
+	db.AddRejectedQuery(identityQuery, mysql.NewSQLError(mysql.ERUnknownSystemVariable, mysql.SSUnknownSQLState, "Unknown system variable 'server_uuid'"))
+	_, err = se.EnsureConnectionAndDB(context.Background(), false)
+	require.NoError(t, err)
+	assert.True(t, se.ServerIdentity().IsZero())
+}
+
+func TestIsReadOnly(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	params, _ := db.ConnParams().MysqlParams()
+	config := tabletenv.NewDefaultConfig()
+	config.DB = dbconfigs.NewTestDBConfigs(*params, *params, "")
+	se := NewEngine(tabletenv.NewEnv(config, "SchemaTest"))
+
+	const readOnlyQuery = "select @@global.read_only"
+	readOnlyFields := sqltypes.MakeTestFields("@@global.read_only", "int64")
+	db.AddQuery(readOnlyQuery, sqltypes.MakeTestResult(readOnlyFields, "1"))
+	readOnly, err := se.IsReadOnly()
+	require.NoError(t, err)
+	assert.True(t, readOnly)
+
+	db.AddQuery(readOnlyQuery, sqltypes.MakeTestResult(readOnlyFields, "0"))
+	readOnly, err = se.IsReadOnly()
+	require.NoError(t, err)
+	assert.False(t, readOnly)
+
+	db.AddRejectedQuery(readOnlyQuery, mysql.NewSQLError(mysql.ERUnknownError, mysql.SSUnknownSQLState, "boom"))
+	_, err = se.IsReadOnly()
+	assert.Contains(t, err.Error(), "could not check if mysql is read-only")
+}
+
 func TestExportVars(t *testing.T) {
 	db := fakesqldb.New(t)
 	defer db.Close()
@@ -319,7 +654,7 @@ func TestExportVars(t *testing.T) {
 		db.AddQuery(query, result)
 	}
 	se := newEngine(10, 1*time.Second, 1*time.Second, true, db)
-	se.Open()
+	se.Open(context.Background())
 	defer se.Close()
 	expvar.Do(func(kv expvar.KeyValue) {
 		_ = kv.Value.String()
@@ -333,7 +668,7 @@ func TestStatsURL(t *testing.T) {
 		db.AddQuery(query, result)
 	}
 	se := newEngine(10, 1*time.Second, 1*time.Second, true, db)
-	se.Open()
+	se.Open(context.Background())
 	defer se.Close()
 
 	request, _ := http.NewRequest("GET", "/debug/schema", nil)
@@ -22,11 +22,15 @@ import (
 	"strings"
 	"time"
 
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/connpool"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 )
 
+// viewTableType is the table_type of views in information_schema.tables.
+const viewTableType = "VIEW"
+
 // LoadTable creates a Table from the schema info in the database.
 func LoadTable(conn *connpool.DBConn, tableName string, tableType string, comment string) (*Table, error) {
 	ta := NewTable(tableName)
@@ -35,6 +39,8 @@ func LoadTable(conn *connpool.DBConn, tableName string, tableType string, commen
 		return nil, err
 	}
 	switch {
+	case tableType == viewTableType:
+		ta.Type = View
 	case strings.Contains(comment, "vitess_sequence"):
 		ta.Type = Sequence
 		ta.SequenceInfo = &SequenceInfo{}
@@ -62,6 +68,9 @@ func loadMessageInfo(ta *Table, comment string) error {
 		"time_next":  {},
 		"epoch":      {},
 		"time_acked": {},
+		// time_scheduled is optional. If present, messages
+		// are not sent before it.
+		"time_scheduled": {},
 	}
 
 	requiredCols := []string{
@@ -110,6 +119,10 @@ func loadMessageInfo(ta *Table, comment string) error {
 
 	ta.MessageInfo.MaxBackoff, _ = getDuration(keyvals, "vt_max_backoff")
 
+	if ta.MessageInfo.MaxSendRate, err = getRate(keyvals, "vt_max_send_rate"); err != nil {
+		return err
+	}
+
 	for _, col := range requiredCols {
 		num := ta.FindColumn(sqlparser.NewColIdent(col))
 		if num == -1 {
@@ -117,6 +130,10 @@ func loadMessageInfo(ta *Table, comment string) error {
 		}
 	}
 
+	if num := ta.FindColumn(sqlparser.NewColIdent("time_scheduled")); num != -1 && !sqltypes.IsIntegral(ta.Fields[num].Type) {
+		return fmt.Errorf("time_scheduled must be an integer in message table: %s", ta.Name.String())
+	}
+
 	// Load user-defined columns. Any "unrecognized" column is user-defined.
 	for _, field := range ta.Fields {
 		if _, ok := hiddenCols[strings.ToLower(field.Name)]; ok {
@@ -139,6 +156,23 @@ func getDuration(in map[string]string, key string) (time.Duration, error) {
 	return time.Duration(v * 1e9), nil
 }
 
+// getRate returns the optional rate specified by key, or 0 if
+// it's not specified.
+func getRate(in map[string]string, key string) (float64, error) {
+	sv := in[key]
+	if sv == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(sv, 64)
+	if err != nil {
+		return 0, err
+	}
+	if v < 0 {
+		return 0, fmt.Errorf("attribute %s must be >= 0 for message table", key)
+	}
+	return v, nil
+}
+
 func getNum(in map[string]string, key string) (int, error) {
 	sv := in[key]
 	if sv == "" {
@@ -140,6 +140,33 @@ func TestLoadTableMessage(t *testing.T) {
 	want.MessageInfo.MaxBackoff = 100 * time.Second
 	assert.Equal(t, want, table)
 
+	// Test loading the max send rate
+	table, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30,vt_min_backoff=10,vt_max_backoff=100,vt_max_send_rate=2.5", db)
+	require.NoError(t, err)
+	want.MessageInfo.MaxSendRate = 2.5
+	assert.Equal(t, want, table)
+
+	_, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30,vt_max_send_rate=-1", db)
+	assert.EqualError(t, err, "attribute vt_max_send_rate must be >= 0 for message table")
+
+	// The optional time_scheduled column is hidden.
+	fields := append(getMessageTableQueries()["select * from test_table where 1 != 1"].Fields, &querypb.Field{
+		Name: "time_scheduled",
+		Type: sqltypes.Int64,
+	})
+	db.AddQuery("select * from test_table where 1 != 1", &sqltypes.Result{Fields: fields})
+	table, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30,vt_min_backoff=10,vt_max_backoff=100,vt_max_send_rate=2.5", db)
+	require.NoError(t, err)
+	want.Fields = fields
+	assert.Equal(t, want, table)
+
+	fields[len(fields)-1].Type = sqltypes.VarChar
+	_, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30", db)
+	assert.EqualError(t, err, "time_scheduled must be an integer in message table: test_table")
+	for query, result := range getMessageTableQueries() {
+		db.AddQuery(query, result)
+	}
+
 	// Missing property
 	_, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30", db)
 	wanterr := "not specified for message table"
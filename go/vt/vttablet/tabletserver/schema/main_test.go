@@ -17,6 +17,7 @@ limitations under the License.
 package schema
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -37,7 +38,7 @@ func getTestSchemaEngine(t *testing.T) (*Engine, *fakesqldb.DB, func()) {
 
 	db.AddQuery(mysql.BaseShowPrimary, &sqltypes.Result{})
 	se := newEngine(10, 10*time.Second, 10*time.Second, true, db)
-	require.NoError(t, se.Open())
+	require.NoError(t, se.Open(context.Background()))
 	cancel := func() {
 		defer db.Close()
 		defer se.Close()
@@ -30,6 +30,7 @@ const (
 	NoType = iota
 	Sequence
 	Message
+	View
 )
 
 // TypeNames allows to fetch a the type name for a table.
@@ -38,6 +39,7 @@ var TypeNames = []string{
 	"none",
 	"sequence",
 	"message",
+	"view",
 }
 
 // Table contains info about a table.
@@ -52,6 +54,10 @@ type Table struct {
 
 	// MessageInfo contains info for message tables.
 	MessageInfo *MessageInfo
+
+	// ViewDefinition is the select statement of a view.
+	// It's empty for tables.
+	ViewDefinition string
 }
 
 // SequenceInfo contains info specific to sequence tabels.
@@ -100,6 +106,10 @@ type MessageInfo struct {
 	// MaxBackoff specifies the longest duration message manager
 	// should wait before rescheduling a message
 	MaxBackoff time.Duration
+
+	// MaxSendRate specifies the max number of rows per second
+	// to send. If 0, the tablet wide default applies.
+	MaxSendRate float64
 }
 
 // NewTable creates a new Table.
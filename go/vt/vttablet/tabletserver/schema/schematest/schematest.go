@@ -45,6 +45,24 @@ func Queries() map[string]*sqltypes.Result {
 				{sqltypes.NewVarBinary("STRICT_TRANS_TABLES")},
 			},
 		},
+		"select @@global.server_uuid, @@global.server_id": {
+			Fields: []*querypb.Field{{
+				Type: sqltypes.VarChar,
+			}, {
+				Type: sqltypes.Uint32,
+			}},
+			Rows: [][]sqltypes.Value{
+				{sqltypes.NewVarBinary("3e11fa47-71ca-11e1-9e33-c80aa9429562"), sqltypes.NewUint32(1)},
+			},
+		},
+		"select @@global.read_only": {
+			Fields: []*querypb.Field{{
+				Type: sqltypes.Int64,
+			}},
+			Rows: [][]sqltypes.Value{
+				{sqltypes.NewInt64(0)},
+			},
+		},
 		"select @@autocommit": {
 			Fields: []*querypb.Field{{
 				Type: sqltypes.Uint64,
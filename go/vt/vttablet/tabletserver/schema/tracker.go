@@ -62,6 +62,7 @@ type VStreamer interface {
 }
 
 // Tracker watches the replication and saves the latest schema into _vt.schema_version when a DDL is encountered.
+// The saved schema includes the views and their definitions, so CREATE, ALTER and DROP VIEW are tracked like table DDLs.
 type Tracker struct {
 	enabled bool
 
@@ -261,8 +262,9 @@ func (tr *Tracker) saveCurrentSchemaToDb(ctx context.Context, gtid, ddl string,
 
 func newMinimalTable(st *Table) *binlogdatapb.MinimalTable {
 	table := &binlogdatapb.MinimalTable{
-		Name:   st.Name.String(),
-		Fields: st.Fields,
+		Name:           st.Name.String(),
+		Fields:         st.Fields,
+		ViewDefinition: st.ViewDefinition,
 	}
 	var pkc []int64
 	for _, pk := range st.PKColumns {
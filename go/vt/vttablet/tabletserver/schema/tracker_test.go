@@ -24,6 +24,7 @@ import (
 	"golang.org/x/net/context"
 	"vitess.io/vitess/go/sqltypes"
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 )
 
@@ -130,6 +131,22 @@ func TestTrackerShouldNotInsertInitialSchema(t *testing.T) {
 	require.False(t, initialSchemaInserted)
 }
 
+func TestTrackerSavesViews(t *testing.T) {
+	view := NewTable("v1")
+	view.Type = View
+	view.Fields = []*querypb.Field{{
+		Name: "id",
+		Type: sqltypes.Int64,
+	}}
+	view.ViewDefinition = "select id from t1"
+	want := &binlogdatapb.MinimalTable{
+		Name:           "v1",
+		Fields:         view.Fields,
+		ViewDefinition: "select id from t1",
+	}
+	require.Equal(t, want, newMinimalTable(view))
+}
+
 var _ VStreamer = (*fakeVstreamer)(nil)
 
 type fakeVstreamer struct {
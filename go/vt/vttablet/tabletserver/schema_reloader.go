@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+
+	"vitess.io/vitess/go/vt/log"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// schemaReload is a schema reload shared by all the
+// ReloadSchema calls that coalesced into it.
+type schemaReload struct {
+	// callers is protected by reloadMu.
+	callers int
+	done    chan struct{}
+	changed []string
+	err     error
+}
+
+// ReloadSchema reloads the schema and returns the names of the
+// tables that changed. It's only allowed while connected to mysql,
+// and it doesn't run concurrently with a state transition.
+// Calls made while a reload is running don't queue up: they
+// coalesce into a single reload that starts once it's done.
+func (sm *stateManager) ReloadSchema(ctx context.Context) ([]string, error) {
+	sm.reloadMu.Lock()
+	if r := sm.pendingReload; r != nil {
+		r.callers++
+		sm.reloadMu.Unlock()
+		select {
+		case <-r.done:
+			return r.changed, r.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	r := &schemaReload{callers: 1, done: make(chan struct{})}
+	sm.pendingReload = r
+	sm.reloadMu.Unlock()
+
+	defer close(r.done)
+	// Calls that arrive after this point must wait for a new reload.
+	acquired := sm.transitioning.AcquireContext(ctx)
+	sm.reloadMu.Lock()
+	sm.pendingReload = nil
+	callers := r.callers
+	sm.reloadMu.Unlock()
+	if !acquired {
+		r.err = ctx.Err()
+		return nil, r.err
+	}
+	defer sm.transitioning.Release()
+
+	r.changed, r.err = sm.reloadSchema(ctx)
+	log.Infof("Schema reloaded for %d callers: changed tables: %v, err: %v", callers, r.changed, r.err)
+	return r.changed, r.err
+}
+
+// reloadSchema must be called while holding the transitioning semaphore.
+func (sm *stateManager) reloadSchema(ctx context.Context) ([]string, error) {
+	if state := sm.State(); state == StateNotConnected {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot reload schema: %v", state)
+	}
+	changed, err := sm.se.ReloadTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The schema engine notifies the health streamer of the changed
+	// tables. Follow up with the full state for the subscribers.
+	sm.Broadcast()
+	return changed, nil
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+func TestReloadSchema(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	se := sm.se.(*testSchemaEngine)
+	se.changed = []string{"t1", "t2"}
+
+	_, err := sm.ReloadSchema(context.Background())
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	assert.EqualError(t, err, "cannot reload schema: Not connected to mysql")
+	assert.EqualValues(t, 0, se.reloads.Get())
+
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateNotServing, "")
+	require.NoError(t, err)
+	changed, err := sm.ReloadSchema(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t1", "t2"}, changed)
+
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	// Wait for the transition to be broadcast.
+	for {
+		sm.hs.mu.Lock()
+		serving := sm.hs.state.Serving
+		sm.hs.mu.Unlock()
+		if serving {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	ch := make(chan *querypb.StreamHealthResponse, 5)
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = sm.hs.Stream(ctx, func(shr *querypb.StreamHealthResponse) error {
+			ch <- shr
+			return nil
+		})
+	}()
+	defer wg.Wait()
+	defer cancel()
+	// The current state is sent on registration.
+	<-ch
+
+	changed, err = sm.ReloadSchema(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t1", "t2"}, changed)
+	assert.EqualValues(t, 2, se.reloads.Get())
+
+	shr := <-ch
+	assert.Equal(t, []string{"t1", "t2"}, shr.RealtimeStats.TableSchemaChanged)
+}
+
+func TestReloadSchemaCoalesce(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	se := sm.se.(*testSchemaEngine)
+	se.changed = []string{"t1"}
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	se.reloadStarted = make(chan struct{})
+	se.reloadProceed = make(chan struct{})
+	reload := func() chan error {
+		done := make(chan error, 1)
+		go func() {
+			changed, err := sm.ReloadSchema(context.Background())
+			assert.Equal(t, []string{"t1"}, changed)
+			done <- err
+		}()
+		return done
+	}
+	pendingCallers := func() int {
+		sm.reloadMu.Lock()
+		defer sm.reloadMu.Unlock()
+		if sm.pendingReload == nil {
+			return 0
+		}
+		return sm.pendingReload.callers
+	}
+
+	// The first call starts a reload right away.
+	done1 := reload()
+	<-se.reloadStarted
+
+	// Transitions wait for the reload to finish.
+	assert.False(t, sm.transitioning.TryAcquire())
+
+	// The calls made in the meantime coalesce into the next reload.
+	var pending []chan error
+	for i := 0; i < 3; i++ {
+		pending = append(pending, reload())
+		for pendingCallers() != i+1 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	se.reloadProceed <- struct{}{}
+	require.NoError(t, <-done1)
+
+	<-se.reloadStarted
+	assert.Zero(t, pendingCallers())
+	se.reloadProceed <- struct{}{}
+	for _, done := range pending {
+		require.NoError(t, <-done)
+	}
+	assert.EqualValues(t, 2, se.reloads.Get())
+}
+
+func TestReloadSchemaContextDone(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	// The reload waits for the transition in progress.
+	sm.transitioning.Acquire()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = sm.ReloadSchema(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	sm.transitioning.Release()
+	assert.EqualValues(t, 0, sm.se.(*testSchemaEngine).reloads.Get())
+
+	_, err = sm.ReloadSchema(context.Background())
+	require.NoError(t, err)
+}
+
+func TestTabletServerReloadSchema(t *testing.T) {
+	db, tsv := setupTabletServerTest(t)
+	defer db.Close()
+
+	_, err := tsv.ReloadSchema(ctx)
+	require.NoError(t, err)
+
+	tsv.StopService()
+	_, err = tsv.ReloadSchema(ctx)
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// SelfTestStep is the outcome of a step of a self test.
+type SelfTestStep struct {
+	Name string `json:"name"`
+	// Latency is the time the step took, in seconds.
+	Latency float64 `json:"latency"`
+	// Error is set if the step failed. Skipped is set if it
+	// didn't run because a step it depends on failed.
+	Error   string `json:"error,omitempty"`
+	Skipped bool   `json:"skipped,omitempty"`
+}
+
+// SelfTestReport is the outcome of a self test.
+type SelfTestReport struct {
+	Steps []SelfTestStep `json:"steps"`
+	// Failed is the name of the step that failed, if any.
+	Failed string `json:"failed,omitempty"`
+}
+
+// SelfTest opens the subcomponents the way a transition to a serving
+// master does, and closes them. The health of the tablet isn't
+// broadcast, and no query is served. The heartbeat writer isn't
+// started: the tablet may be a replica. SelfTest must be called
+// after InitDBConfig, before the tablet is ever served. It fails
+// with the error of the first step that failed.
+func (tsv *TabletServer) SelfTest() (*SelfTestReport, error) {
+	return tsv.sm.selfTest()
+}
+
+func (sm *stateManager) selfTest() (*SelfTestReport, error) {
+	sm.transitioning.Acquire()
+	defer sm.transitioning.Release()
+
+	sm.mu.Lock()
+	if sm.state != StateNotConnected {
+		sm.mu.Unlock()
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "self test failed: the tablet is already %v", sm.state)
+	}
+	sm.broadcastsDisabled = true
+	sm.mu.Unlock()
+	defer func() {
+		sm.mu.Lock()
+		sm.broadcastsDisabled = false
+		sm.mu.Unlock()
+	}()
+
+	// The database isn't created: its absence is a failure.
+	steps := sm.connectSteps(topodatapb.TabletType_REPLICA)
+	steps = append(steps, sm.servingSteps()...)
+	report := &SelfTestReport{Steps: make([]SelfTestStep, len(steps))}
+	var mu sync.Mutex
+	for i := range steps {
+		i, run := i, steps[i].run
+		report.Steps[i] = SelfTestStep{Name: steps[i].name, Skipped: true}
+		steps[i].run = func(ctx context.Context) error {
+			start := time.Now()
+			err := run(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			report.Steps[i].Latency = time.Since(start).Seconds()
+			report.Steps[i].Skipped = false
+			if err != nil {
+				report.Steps[i].Error = err.Error()
+				if report.Failed == "" {
+					report.Failed = steps[i].name
+				}
+			}
+			return err
+		}
+	}
+	ctx, cancel := sm.transitionContext()
+	defer cancel()
+	err := sm.runSteps(ctx, steps)
+	sm.closeAll(NotConnectedByOperator)
+	if err != nil {
+		log.Errorf("Self test failed: %s: %v", report.Failed, err)
+		return report, vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "self test failed: %s: %v", report.Failed, err)
+	}
+	log.Infof("Self test passed")
+	return report, nil
+}
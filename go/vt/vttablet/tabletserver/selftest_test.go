@@ -0,0 +1,93 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestSelfTest(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+
+	report, err := sm.selfTest()
+	require.NoError(t, err)
+	assert.Empty(t, report.Failed)
+	names := make(map[string]bool)
+	for _, step := range report.Steps {
+		assert.False(t, step.Skipped, step.Name)
+		assert.Empty(t, step.Error, step.Name)
+		names[step.Name] = true
+	}
+	for _, name := range []string{"se.Open", "qe.Open", "txEngine.Open", "messager.Open"} {
+		assert.True(t, names[name], name)
+	}
+
+	// The database isn't created, and everything is closed.
+	assert.Equal(t, []bool{false}, sm.se.(*testSchemaEngine).createDBCalls)
+	assert.Equal(t, testStateClosed, sm.te.(*testTxEngine).state)
+	assert.Equal(t, testStateClosed, sm.se.(*testSchemaEngine).state)
+	assert.Equal(t, StateNotConnected, sm.State())
+	assert.False(t, sm.broadcastsDisabled)
+
+	// The tablet can then serve.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	_, err = sm.selfTest()
+	assert.EqualError(t, err, "self test failed: the tablet is already Serving")
+}
+
+func TestSelfTestFailure(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	sm.se.(*testSchemaEngine).failMySQL = true
+
+	// Nothing is broadcast while the self test runs.
+	sm.hs.Open()
+	sub, _, err := sm.hs.register(streamOptions{})
+	require.NoError(t, err)
+	defer sm.hs.unregister(sub.ch)
+	<-sub.ch
+
+	report, err := sm.selfTest()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "self test failed: se.EnsureConnectionAndDB: intentional error")
+	assert.Equal(t, "se.EnsureConnectionAndDB", report.Failed)
+	skipped := make(map[string]bool)
+	for _, step := range report.Steps {
+		if step.Name == "se.EnsureConnectionAndDB" {
+			assert.Equal(t, "intentional error", step.Error)
+		}
+		skipped[step.Name] = step.Skipped
+	}
+	// The steps that depend on the connection don't run.
+	for _, name := range []string{"se.Open", "qe.Open", "txEngine.Open", "messager.Open"} {
+		assert.True(t, skipped[name], name)
+	}
+	select {
+	case shr := <-sub.ch:
+		t.Errorf("unexpected broadcast: %v", shr)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// serverIdentityError is returned by checkServerIdentity if the
+// tablet refuses to serve from another mysql server.
+type serverIdentityError struct {
+	error
+}
+
+// checkServerIdentity compares the identity of the mysql server the
+// schema engine just connected to with the one the tablet connected to
+// before. A change means that the tablet was silently pointed to
+// another mysqld, likely with another database: it's counted, and
+// reported as a health error until the process restarts. If
+// serverIdentityChangeFatal is set, a serverIdentityError is returned,
+// which fails the transition and disconnects the tablet, until the
+// original server is back. Otherwise, the new server becomes the
+// expected one.
+// The servers that can't be identified are not checked.
+func (sm *stateManager) checkServerIdentity() error {
+	identity := sm.se.ServerIdentity()
+	if identity.IsZero() {
+		return nil
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.serverIdentity.IsZero() {
+		sm.serverIdentity = identity
+	}
+	last := sm.lastServerIdentity
+	sm.lastServerIdentity = identity
+	if identity == sm.serverIdentity {
+		return nil
+	}
+	err := vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "mysql server changed without a restart: %v, was %v", identity, sm.serverIdentity)
+	if identity != last {
+		sm.serverIdentityChanges.Add(1)
+		log.Errorf("The mysql server changed without a restart: %v, was %v. The tablet may be serving another database.", identity, sm.serverIdentity)
+	}
+	sm.serverIdentityErr = err
+	if sm.serverIdentityChangeFatal {
+		return &serverIdentityError{err}
+	}
+	sm.serverIdentity = identity
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/timer/fakeclock"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+var (
+	testServerIdentity1 = schema.ServerIdentity{UUID: "3e11fa47-71ca-11e1-9e33-c80aa9429562", ID: 1}
+	testServerIdentity2 = schema.ServerIdentity{UUID: "8a94f357-aab4-11df-86ab-c80aa9429562", ID: 2}
+)
+
+func TestStateManagerServerIdentityChange(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	se := sm.se.(*testSchemaEngine)
+	changes := sm.serverIdentityChanges.Get()
+
+	se.identity = testServerIdentity1
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	status := sm.Status()
+	assert.Equal(t, testServerIdentity1, status.ServerIdentity)
+	assert.Empty(t, status.ServerIdentityError)
+
+	// The tablet keeps serving from the new server, but
+	// reports the change until the process restarts.
+	se.identity = testServerIdentity2
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.Equal(t, StateServing, sm.State())
+	assert.Equal(t, changes+1, sm.serverIdentityChanges.Get())
+	want := "mysql server changed without a restart: server_uuid 8a94f357-aab4-11df-86ab-c80aa9429562, server_id 2, was server_uuid 3e11fa47-71ca-11e1-9e33-c80aa9429562, server_id 1"
+	status = sm.Status()
+	assert.Equal(t, testServerIdentity2, status.ServerIdentity)
+	assert.Equal(t, want, status.ServerIdentityError)
+
+	sm.hs.Open()
+	ch, cancel := testStream(sm.hs)
+	defer cancel()
+	<-ch
+	sm.Broadcast()
+	shr := <-ch
+	assert.True(t, shr.Serving)
+	assert.Equal(t, want, shr.RealtimeStats.HealthError)
+
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.Equal(t, changes+1, sm.serverIdentityChanges.Get())
+	assert.Equal(t, want, sm.Status().ServerIdentityError)
+}
+
+func TestStateManagerServerIdentityChangeFatal(t *testing.T) {
+	// The retries of the failed transitions never fire.
+	sm := newTestStateManagerWithClock(t, fakeclock.New(testNow))
+	defer sm.StopService()
+	sm.serverIdentityChangeFatal = true
+	se := sm.se.(*testSchemaEngine)
+	changes := sm.serverIdentityChanges.Get()
+
+	// The servers that can't be identified aren't checked.
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	se.identity = testServerIdentity1
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_RDONLY, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	// The tablet refuses to serve from another server.
+	se.identity = testServerIdentity2
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "mysql server changed without a restart")
+	assert.False(t, sm.IsServing())
+	assert.Equal(t, StateNotConnected, sm.State())
+	assert.Equal(t, changes+1, sm.serverIdentityChanges.Get())
+	assert.Equal(t, testServerIdentity1, sm.Status().ServerIdentity)
+
+	// The same change is only counted once.
+	require.Error(t, sm.checkServerIdentity())
+	assert.Equal(t, changes+1, sm.serverIdentityChanges.Get())
+
+	// Until the original server is back.
+	se.identity = testServerIdentity1
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.True(t, sm.IsServing())
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"fmt"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+)
+
+const (
+	shutdownPhaseStart    = "start"
+	shutdownPhaseLameduck = "lameduck"
+	shutdownPhaseDone     = "done"
+)
+
+// shutdownPhase is a step of StopService. The phases are logged
+// and reported in the status as they start, so that operators can
+// tell what a tablet that takes long to shut down is waiting for.
+// Besides start, lameduck and done, the phases are named after the
+// transition operations, like requests.Wait or qe.Close.
+type shutdownPhase struct {
+	Name   string    `json:"name"`
+	Detail string    `json:"detail,omitempty"`
+	Start  time.Time `json:"start"`
+}
+
+// startShutdownPhases starts recording the phases of StopService.
+// The phases of a previous StopService are forgotten.
+func (sm *stateManager) startShutdownPhases() {
+	sm.mu.Lock()
+	sm.shutdownPhases = nil
+	lameduck := sm.lameduck
+	sm.mu.Unlock()
+
+	sm.stopping.Set(true)
+	sm.enterShutdownPhase(shutdownPhaseStart, "")
+	if lameduck {
+		sm.enterShutdownPhase(shutdownPhaseLameduck, "the tablet was in lameduck")
+	}
+}
+
+// endShutdownPhases records the end of StopService.
+func (sm *stateManager) endShutdownPhases() {
+	sm.enterShutdownPhase(shutdownPhaseDone, "")
+	sm.stopping.Set(false)
+}
+
+// clearShutdownPhases forgets the phases of the last StopService,
+// once the tablet is asked to change its state again.
+func (sm *stateManager) clearShutdownPhases() {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.shutdownPhases = nil
+}
+
+// enterShutdownPhase records the start of a phase if StopService
+// is running. Otherwise, it does nothing.
+func (sm *stateManager) enterShutdownPhase(name, detail string) {
+	if !sm.stopping.Get() {
+		return
+	}
+	if name == "requests.Wait" && detail == "" {
+		detail = fmt.Sprintf("%d requests in flight", sm.requestsInFlight.Get())
+	}
+	phase := shutdownPhase{Name: name, Detail: detail, Start: time.Now()}
+
+	sm.mu.Lock()
+	var elapsed time.Duration
+	if len(sm.shutdownPhases) != 0 {
+		elapsed = phase.Start.Sub(sm.shutdownPhases[0].Start)
+	}
+	sm.shutdownPhases = append(sm.shutdownPhases, phase)
+	sm.mu.Unlock()
+
+	log.Infof("StopService phase=%s detail=%q elapsed=%v", phase.Name, phase.Detail, elapsed)
+}
+
+// shutdownPhasesLocked returns a copy of the phases of the
+// last StopService.
+func (sm *stateManager) shutdownPhasesLocked() []shutdownPhase {
+	if len(sm.shutdownPhases) == 0 {
+		return nil
+	}
+	return append([]shutdownPhase(nil), sm.shutdownPhases...)
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestStateManagerShutdownPhases(t *testing.T) {
+	sm := newTestStateManager(t)
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	// The transitions outside StopService aren't recorded.
+	assert.Empty(t, sm.Status().ShutdownPhases)
+
+	target := &querypb.Target{TabletType: topodatapb.TabletType_REPLICA}
+	require.NoError(t, sm.StartRequest(context.Background(), target, false))
+	sm.EnterLameduck()
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		sm.StopService()
+	}()
+
+	// StopService waits for the request.
+	var status *stateStatus
+	assert.Eventually(t, func() bool {
+		status = sm.Status()
+		n := len(status.ShutdownPhases)
+		return n != 0 && status.ShutdownPhases[n-1].Name == "requests.Wait"
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "1 requests in flight", status.ShutdownPhases[len(status.ShutdownPhases)-1].Detail)
+	assert.Equal(t, shutdownPhaseStart, status.ShutdownPhases[0].Name)
+	assert.Equal(t, shutdownPhaseLameduck, status.ShutdownPhases[1].Name)
+	var shutdown *kv
+	for _, detail := range status.appendDetails(nil) {
+		if detail.Key == "Shutdown" {
+			shutdown = detail
+		}
+	}
+	require.NotNil(t, shutdown)
+	assert.Contains(t, shutdown.Value, "requests.Wait: 1 requests in flight (")
+
+	sm.EndRequest()
+	<-stopped
+	var names []string
+	for _, phase := range sm.Status().ShutdownPhases {
+		names = append(names, phase.Name)
+	}
+	assert.Contains(t, names, "qe.Close")
+	assert.Contains(t, names, "hs.Close")
+	assert.Equal(t, shutdownPhaseDone, names[len(names)-1])
+	assert.False(t, sm.stopping.Get())
+	assert.Zero(t, sm.requestsInFlight.Get())
+
+	// The phases are forgotten once the state changes again.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	defer sm.StopService()
+	assert.Empty(t, sm.Status().ShutdownPhases)
+}
@@ -0,0 +1,294 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// snapshotExportAnnotation is the health annotation that reports the
+// reason of the snapshot export in progress. It can't be set by
+// SetHealthAnnotation.
+const snapshotExportAnnotation = "snapshot_export"
+
+// SnapshotExport is a consistent snapshot of the database taken by
+// StartSnapshotExport. Conn reads the tables as of Position until the
+// export is released. The export is released by Release, or when its
+// caller stops calling KeepAlive, or when the tablet type changes or
+// the tablet server stops.
+type SnapshotExport struct {
+	Reason string
+	// Position is the GTID position of the snapshot.
+	Position string
+	// Tables are the tables of the schema, sorted. The views are not
+	// included.
+	Tables []string
+	// Conn is in a consistent snapshot transaction. It must not be
+	// used after the export is released: it's closed.
+	Conn *mysql.Conn
+
+	sm *stateManager
+	// done is closed when the export is released.
+	done chan struct{}
+
+	// The fields below are protected by sm.mu.
+	// pausedUntil is when the requests are admitted again at the
+	// latest. It's zero once the snapshot is taken.
+	pausedUntil time.Time
+	// expires is moved forward by KeepAlive.
+	expires time.Time
+}
+
+// KeepAlive postpones the expiry of the export by -snapshot_export_ttl.
+// It returns an error if the export is already released.
+func (e *SnapshotExport) KeepAlive() error {
+	sm := e.sm
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.export != e {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "snapshot export %s is released", e.Reason)
+	}
+	e.expires = sm.clock.Now().Add(sm.exportTTL)
+	return nil
+}
+
+// Release ends the export: it closes Conn, and the health stream
+// stops reporting it. It's a no-op if the export is already released.
+func (e *SnapshotExport) Release() {
+	e.sm.releaseExport(e, "released")
+}
+
+// enterSnapshotMode starts rejecting the new requests for up to
+// exportMaxPause, and reports the export in the health stream. Only
+// one export can be in progress, and only on a serving replica: the
+// master keeps taking writes.
+func (sm *stateManager) enterSnapshotMode(reason string) (*SnapshotExport, error) {
+	if reason == "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cannot start snapshot export: the reason must be set")
+	}
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	switch {
+	case sm.export != nil:
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot start snapshot export: snapshot export %s is in progress", sm.export.Reason)
+	case !sm.isServingLocked():
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot start snapshot export: tablet is not serving")
+	case sm.target.TabletType == topodatapb.TabletType_MASTER:
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot start snapshot export: it must be taken from a replica")
+	}
+	now := sm.clock.Now()
+	e := &SnapshotExport{
+		Reason:      reason,
+		sm:          sm,
+		done:        make(chan struct{}),
+		pausedUntil: now.Add(sm.exportMaxPause),
+		expires:     now.Add(sm.exportTTL),
+	}
+	sm.export = e
+	log.Infof("Snapshot export %s started: rejecting the new requests for up to %v", reason, sm.exportMaxPause)
+	sm.updateAnnotationsLocked()
+	return e, nil
+}
+
+// endSnapshotPause records the snapshot taken for e, admits the
+// requests again, and starts the expiry of e.
+func (sm *stateManager) endSnapshotPause(e *SnapshotExport, conn *mysql.Conn, pos string, tables []string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.export != e {
+		// The tablet type changed, or the tablet server stopped.
+		return vterrors.Errorf(vtrpcpb.Code_ABORTED, "snapshot export %s was released while the snapshot was taken", e.Reason)
+	}
+	e.Conn, e.Position, e.Tables = conn, pos, tables
+	e.pausedUntil = time.Time{}
+	log.Infof("Snapshot export %s: snapshot taken at %s, admitting the requests again", e.Reason, pos)
+	go sm.expireExport(e)
+	return nil
+}
+
+// exportPausedLocked returns true if the new requests are rejected
+// because an export takes its snapshot. The pause is bounded by
+// exportMaxPause even if the snapshot takes longer.
+func (sm *stateManager) exportPausedLocked() bool {
+	return sm.export != nil && sm.clock.Now().Before(sm.export.pausedUntil)
+}
+
+// expireExport releases e if KeepAlive isn't
+// called before it expires.
+func (sm *stateManager) expireExport(e *SnapshotExport) {
+	for {
+		sm.mu.Lock()
+		if sm.export != e {
+			sm.mu.Unlock()
+			return
+		}
+		wait := e.expires.Sub(sm.clock.Now())
+		if wait <= 0 {
+			sm.mu.Unlock()
+			sm.releaseExport(e, fmt.Sprintf("no keepalive for %v", sm.exportTTL))
+			return
+		}
+		sm.mu.Unlock()
+
+		tmr := sm.clock.NewTimer(wait)
+		select {
+		case <-tmr.C():
+		case <-e.done:
+			tmr.Stop()
+			return
+		}
+	}
+}
+
+// releaseExportLocked ends e if it's in progress, and returns true if
+// it was. The annotations are updated, but not broadcast: the caller
+// does it.
+func (sm *stateManager) releaseExportLocked(e *SnapshotExport, why string) bool {
+	if sm.export != e {
+		return false
+	}
+	sm.export = nil
+	close(e.done)
+	if e.Conn != nil {
+		e.Conn.Close()
+	}
+	log.Infof("Snapshot export %s ended: %s", e.Reason, why)
+	sm.hs.SetAnnotations(sm.reportedAnnotationsLocked())
+	return true
+}
+
+// releaseExport ends e if it's in progress, and broadcasts
+// that it's no longer in progress.
+func (sm *stateManager) releaseExport(e *SnapshotExport, why string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.releaseExportLocked(e, why) && sm.initialized {
+		sm.broadcastLocked()
+	}
+}
+
+// StartSnapshotExport takes a consistent snapshot of the database for
+// a logical export. The new requests are rejected with a retryable
+// error while the snapshot is taken, for up to
+// -snapshot_export_max_pause. The serving state doesn't change, so the
+// tablet doesn't flap in the health stream, which reports the export
+// with its reason. The returned export must be kept alive by KeepAlive
+// at least every -snapshot_export_ttl, and released by Release once
+// the export is done.
+func (tsv *TabletServer) StartSnapshotExport(ctx context.Context, reason string) (*SnapshotExport, error) {
+	e, err := tsv.sm.enterSnapshotMode(reason)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, tsv.sm.exportMaxPause)
+	defer cancel()
+	tables := exportTables(tsv.se.GetSchema())
+	conn, pos, err := tsv.takeSnapshot(ctx, tables)
+	if err == nil {
+		if err = tsv.sm.endSnapshotPause(e, conn, pos, tables); err != nil {
+			conn.Close()
+		}
+	}
+	if err != nil {
+		tsv.sm.releaseExport(e, "the snapshot failed")
+		return nil, vterrors.Wrapf(err, "cannot start snapshot export %s", reason)
+	}
+	return e, nil
+}
+
+// takeSnapshot locks tables, starts a consistent snapshot transaction
+// on the returned connection, and returns the GTID position at which
+// the tables were locked. It's the position of the snapshot: the
+// tables can't change until it's started.
+func (tsv *TabletServer) takeSnapshot(ctx context.Context, tables []string) (*mysql.Conn, string, error) {
+	cp := tsv.config.DB.DbaWithDB()
+	lockConn, err := cp.Connect(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	defer lockConn.Close()
+	// Closing lockConn interrupts the lock if ctx is done first.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			lockConn.Close()
+		case <-done:
+		}
+	}()
+
+	if len(tables) > 0 {
+		locks := make([]string, 0, len(tables))
+		for _, table := range tables {
+			locks = append(locks, sqlparser.String(sqlparser.NewTableIdent(table))+" read")
+		}
+		if _, err := lockConn.ExecuteFetch("lock tables "+strings.Join(locks, ", "), 1, false); err != nil {
+			return nil, "", err
+		}
+		defer func() {
+			if _, err := lockConn.ExecuteFetch("unlock tables", 0, false); err != nil {
+				log.Warningf("Unlock tables failed: %v", err)
+			}
+		}()
+	}
+	pos, err := lockConn.MasterPosition()
+	if err != nil {
+		return nil, "", err
+	}
+
+	conn, err := cp.Connect(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, query := range []string{
+		"set transaction isolation level repeatable read",
+		"start transaction with consistent snapshot, read only",
+	} {
+		if _, err := conn.ExecuteFetch(query, 1, false); err != nil {
+			conn.Close()
+			return nil, "", err
+		}
+	}
+	return conn, mysql.EncodePosition(pos), nil
+}
+
+// exportTables returns the names of the tables of a schema, sorted.
+func exportTables(tables map[string]*schema.Table) []string {
+	names := make([]string, 0, len(tables))
+	for name, table := range tables {
+		if name == "dual" || table.Type == schema.View {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
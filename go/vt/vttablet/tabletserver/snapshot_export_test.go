@@ -0,0 +1,216 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestStateManagerSnapshotMode(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	target := &querypb.Target{TabletType: topodatapb.TabletType_REPLICA}
+
+	_, err := sm.enterSnapshotMode("")
+	assert.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err))
+	_, err = sm.enterSnapshotMode("nightly")
+	assert.EqualError(t, err, "cannot start snapshot export: tablet is not serving")
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	_, err = sm.enterSnapshotMode("nightly")
+	assert.EqualError(t, err, "cannot start snapshot export: it must be taken from a replica")
+
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	ch, cancel := testStream(sm.hs)
+	defer cancel()
+	<-ch
+
+	e, err := sm.enterSnapshotMode("nightly")
+	require.NoError(t, err)
+	_, err = sm.enterSnapshotMode("hourly")
+	assert.EqualError(t, err, "cannot start snapshot export: snapshot export nightly is in progress")
+
+	// The tablet keeps serving, and reports the export.
+	shr := nextAnnotations(ch, func(a map[string]string) bool { return a[snapshotExportAnnotation] != "" })
+	assert.Equal(t, "nightly", shr.RealtimeStats.Annotations[snapshotExportAnnotation])
+	assert.True(t, shr.Serving)
+	err = sm.SetHealthAnnotation(snapshotExportAnnotation, "x")
+	assert.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err))
+
+	// Only the new requests from the clients are rejected.
+	err = sm.StartRequest(context.Background(), target, false)
+	assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err))
+	assert.Contains(t, err.Error(), "operation not allowed: snapshot export nightly is taking its snapshot")
+	require.NoError(t, sm.StartRequest(context.Background(), target, true))
+	sm.EndRequest()
+	require.NoError(t, sm.StartRequest(tabletenv.LocalContext(), nil, false))
+	sm.EndRequest()
+
+	require.NoError(t, sm.endSnapshotPause(e, nil, "MySQL56/pos", []string{"t1"}))
+	require.NoError(t, sm.StartRequest(context.Background(), target, false))
+	sm.EndRequest()
+	assert.Equal(t, "MySQL56/pos", e.Position)
+	assert.Equal(t, []string{"t1"}, e.Tables)
+
+	before := e.expires
+	time.Sleep(time.Millisecond)
+	require.NoError(t, e.KeepAlive())
+	assert.True(t, e.expires.After(before))
+
+	e.Release()
+	e.Release()
+	assert.Nil(t, sm.export)
+	assert.EqualError(t, e.KeepAlive(), "snapshot export nightly is released")
+	nextAnnotations(ch, func(a map[string]string) bool { return a[snapshotExportAnnotation] == "" })
+}
+
+func TestStateManagerSnapshotPauseIsBounded(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	sm.exportMaxPause = 10 * time.Millisecond
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	target := &querypb.Target{TabletType: topodatapb.TabletType_REPLICA}
+
+	e, err := sm.enterSnapshotMode("nightly")
+	require.NoError(t, err)
+	assert.Error(t, sm.StartRequest(context.Background(), target, false))
+	time.Sleep(sm.exportMaxPause)
+	require.NoError(t, sm.StartRequest(context.Background(), target, false))
+	sm.EndRequest()
+	e.Release()
+}
+
+func TestStateManagerSnapshotExpiry(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	sm.exportTTL = 10 * time.Millisecond
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	// An abandoned export is released.
+	e, err := sm.enterSnapshotMode("nightly")
+	require.NoError(t, err)
+	require.NoError(t, sm.endSnapshotPause(e, nil, "", nil))
+	for {
+		sm.mu.Lock()
+		released := sm.export == nil
+		sm.mu.Unlock()
+		if released {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	assert.Error(t, e.KeepAlive())
+	assert.Empty(t, sm.HealthAnnotations())
+
+	// So is the one of a tablet that changes type.
+	sm.exportTTL = time.Hour
+	e, err = sm.enterSnapshotMode("nightly")
+	require.NoError(t, err)
+	require.NoError(t, sm.endSnapshotPause(e, nil, "", nil))
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.Error(t, e.KeepAlive())
+
+	// And of a tablet server that stops.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_RDONLY, testNow, StateServing, "")
+	require.NoError(t, err)
+	e, err = sm.enterSnapshotMode("nightly")
+	require.NoError(t, err)
+	sm.StopService()
+	assert.Error(t, e.KeepAlive())
+	assert.EqualError(t, sm.endSnapshotPause(e, nil, "", nil), "snapshot export nightly was released while the snapshot was taken")
+}
+
+func TestExportTables(t *testing.T) {
+	tables := map[string]*schema.Table{
+		"dual": schema.NewTable("dual"),
+		"t2":   schema.NewTable("t2"),
+		"t1":   schema.NewTable("t1"),
+		"v1":   {Type: schema.View},
+	}
+	assert.Equal(t, []string{"t1", "t2"}, exportTables(tables))
+}
+
+func TestTabletServerStartSnapshotExport(t *testing.T) {
+	db, tsv := setupTabletServerTest(t)
+	defer tsv.StopService()
+	defer db.Close()
+	err := tsv.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, time.Time{}, true, "")
+	require.NoError(t, err)
+
+	tables := exportTables(tsv.se.GetSchema())
+	require.NotEmpty(t, tables)
+	locks := make([]string, 0, len(tables))
+	for _, table := range tables {
+		locks = append(locks, sqlparser.String(sqlparser.NewTableIdent(table))+" read")
+	}
+	lockQuery := "lock tables " + strings.Join(locks, ", ")
+	db.AddQuery(lockQuery, &sqltypes.Result{})
+	db.AddQuery("unlock tables", &sqltypes.Result{})
+	db.AddQuery("SELECT @@global.gtid_executed", sqltypes.MakeTestResult(sqltypes.MakeTestFields("gtid", "varchar"), "16b1039f-22b6-11ed-b765-0242ac110002:1-20"))
+	db.AddQuery("set transaction isolation level repeatable read", &sqltypes.Result{})
+	db.AddQuery("start transaction with consistent snapshot, read only", &sqltypes.Result{})
+
+	e, err := tsv.StartSnapshotExport(ctx, "nightly")
+	require.NoError(t, err)
+	assert.Equal(t, "MySQL56/16b1039f-22b6-11ed-b765-0242ac110002:1-20", e.Position)
+	assert.Equal(t, tables, e.Tables)
+	require.NotNil(t, e.Conn)
+	assert.Equal(t, 1, db.GetQueryCalledNum(lockQuery))
+	assert.Equal(t, 1, db.GetQueryCalledNum("unlock tables"))
+	tsv.sm.hs.mu.Lock()
+	assert.Equal(t, map[string]string{snapshotExportAnnotation: "nightly"}, tsv.sm.hs.state.RealtimeStats.Annotations)
+	tsv.sm.hs.mu.Unlock()
+
+	// The requests are admitted again once the snapshot is taken.
+	target := querypb.Target{TabletType: topodatapb.TabletType_REPLICA}
+	db.AddQuery("select * from test_table limit 1000", &sqltypes.Result{})
+	_, err = tsv.Execute(ctx, &target, "select * from test_table limit 1000", nil, 0, 0, nil)
+	require.NoError(t, err)
+
+	e.Release()
+	assert.True(t, e.Conn.IsClosed())
+
+	// A failed snapshot ends the snapshot mode.
+	db.AddRejectedQuery(lockQuery, fmt.Errorf("lock wait timeout"))
+	_, err = tsv.StartSnapshotExport(ctx, "hourly")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot start snapshot export hourly")
+	assert.Nil(t, tsv.sm.export)
+	_, err = tsv.Execute(ctx, &target, "select * from test_table limit 1000", nil, 0, 0, nil)
+	require.NoError(t, err)
+}
@@ -19,17 +19,29 @@ package tabletserver
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"sync"
 	"time"
 
+	"vitess.io/vitess/go/history"
+	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/sync2"
+	"vitess.io/vitess/go/tb"
 	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/trace"
+	"vitess.io/vitess/go/vt/hook"
 	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/interceptor"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/repltracker"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
 )
 
 type servingState int64
@@ -43,21 +55,148 @@ const (
 	StateNotServing
 	// StateServing is where queries are allowed.
 	StateServing
+	// StateServingReadOnly is where reads are allowed, but new
+	// transactions and writes are rejected. It's used during
+	// maintenance windows, and is reported as serving.
+	StateServingReadOnly
 )
 
 func (state servingState) String() string {
 	switch state {
 	case StateServing:
 		return "Serving"
+	case StateServingReadOnly:
+		return "Serving Read Only"
 	case StateNotServing:
 		return "Not Serving"
 	}
 	return "Not connected to mysql"
 }
 
+// serving returns true if queries are allowed in the state.
+func (state servingState) serving() bool {
+	return state == StateServing || state == StateServingReadOnly
+}
+
+// notConnectedState qualifies StateNotConnected with the
+// reason why tabletserver is not connected.
+type notConnectedState int64
+
+const (
+	// NotConnectedNeverServed is the state of a tabletserver that
+	// was never asked to connect.
+	NotConnectedNeverServed = notConnectedState(iota)
+	// NotConnectedByOperator is the state of a tabletserver that
+	// was requested to disconnect.
+	NotConnectedByOperator
+	// NotConnectedByMySQLFailure is the state of a tabletserver that
+	// disconnected because it lost its connection to mysql.
+	NotConnectedByMySQLFailure
+	// NotConnectedShuttingDown is the state of a tabletserver that
+	// is shutting down.
+	NotConnectedShuttingDown
+	// NotConnectedByPanic is the state of a tabletserver that
+	// disconnected because of a panic during a state transition.
+	NotConnectedByPanic
+)
+
+func (ncs notConnectedState) String() string {
+	switch ncs {
+	case NotConnectedByOperator:
+		return "ClosedByOperator"
+	case NotConnectedByMySQLFailure:
+		return "ClosedByMySQLFailure"
+	case NotConnectedShuttingDown:
+		return "ShuttingDown"
+	case NotConnectedByPanic:
+		return "ClosedByPanic"
+	}
+	return "NeverServed"
+}
+
+// hsNotifierName is the name under which the health streamer
+// registers for schema change notifications.
+const hsNotifierName = "healthStreamer"
+
+// defaultServingOrder is the order in which the master-only
+// subcomponents are opened if none is configured.
+var defaultServingOrder = []string{"tracker", "txEngine", "messager", "throttler"}
+
+// servingComponent is a master-only subcomponent. The serving
+// components are opened in servingOrder when a master starts
+// serving, and closed in reverse when it stops.
+type servingComponent struct {
+	name string
+	// prepare, if set, creates what the component depends on.
+	// It must succeed before open is called.
+	prepare func() error
+	open    func(ctx context.Context) error
+	close   func()
+	// after lists the transition steps that open what the
+	// component depends on. See transitionStep.
+	after []string
+	// readOnly is set if the component is switched to read-only
+	// instead of being closed when a master is demoted.
+	readOnly bool
+}
+
+// transitionOpLogThreshold is for tests. Transition operations
+// that take longer than this are logged.
+var transitionOpLogThreshold = 1 * time.Second
+
+// transitionOp is the time taken by a subcomponent operation
+// during a state transition.
+type transitionOp struct {
+	Name string
+	Wall time.Duration
+}
+
 // transitionRetryInterval is for tests.
 var transitionRetryInterval = 1 * time.Second
 
+// drainBroadcastInterval is for tests.
+var drainBroadcastInterval = 1 * time.Second
+
+// slowDrainThreshold is for tests. A drain that takes longer than
+// this logs the oldest transactions and reserved connections that
+// are holding it up.
+var slowDrainThreshold = 10 * time.Second
+
+// processStart is when the process started. It's approximated
+// by the initialization of this package.
+var processStart = time.Now()
+
+// timeToFirstServingCutoffs are the buckets, in milliseconds,
+// of the time it takes a tablet to serve after the process starts.
+var timeToFirstServingCutoffs = []int64{1000, 5000, 10000, 30000, 60000, 300000, 600000, 1800000}
+
+// slowDrainReportCount is the number of transactions, and of
+// reserved connections, logged for a slow drain.
+const slowDrainReportCount = 5
+
+// timebombCrash is for tests.
+var timebombCrash = func() {
+	log.Fatal("Shutdown took too long. Crashing")
+}
+
+// transitionWatchdogInterval is for tests.
+var transitionWatchdogInterval = 10 * time.Second
+
+// topoIsolationCheckInterval is for tests.
+var topoIsolationCheckInterval = 1 * time.Second
+
+// readOnlyCheckInterval is for tests.
+var readOnlyCheckInterval = 5 * time.Second
+
+// topoIsolationReason is the reason reported while a master
+// is not serving because it could not reach the topo.
+const topoIsolationReason = "topo isolation"
+
+// runStuckTransitionHook is for tests.
+var runStuckTransitionHook = func(h *hook.Hook) *hook.HookResult {
+	return h.Execute()
+}
+
 // stateManager manages state transition for all the TabletServer
 // subcomponents.
 type stateManager struct {
@@ -87,10 +226,71 @@ type stateManager struct {
 	replHealthy    bool
 	lameduck       bool
 	alsoAllow      []topodatapb.TabletType
+	alsoAllowUntil time.Time
 	reason         string
 	transitionErr  error
+	// transitionStart is set while execTransition is in progress.
+	transitionStart time.Time
+	replLag         time.Duration
+	replLagSource   repltracker.LagSource
+	replErr         error
+	// transitionAudit records the events that drive the
+	// transitions, if a transition audit log is configured.
+	transitionAudit *transitionAudit
+	// wantGeneration is incremented every time a request is accepted
+	// as the new goal. pendingRequests counts the requests that wait
+	// for the running transition before they can replace the goal.
+	// The retries don't attempt a goal that's about to be replaced:
+	// they're skipped and counted in staleRetries.
+	wantGeneration  int64
+	pendingRequests int
+	staleRetries    *stats.Counter
+	// dbCreatedFor is the intent of the transition that created
+	// the database. Its retries don't try to create it again.
+	dbCreatedFor transitionIntent
+	// notConnected qualifies StateNotConnected. It's set along with
+	// state. wantNotConnected is the one requested by the caller.
+	notConnected     notConnectedState
+	wantNotConnected notConnectedState
+	// stuckSince is the time the watchdog first saw the state
+	// differ from the desired one. stuckReported is set once
+	// the watchdog has fired for it.
+	stuckSince    time.Time
+	stuckReported bool
+	// topoLastSeen is the last time the topo was known to be
+	// reachable. topoIsolated is set once the master stopped
+	// serving because it was not. resumeServing is set if a
+	// request to serve was overridden by the isolation.
+	topoLastSeen  time.Time
+	topoIsolated  bool
+	resumeServing bool
+	// pausedBy is the operator who paused serving, if any.
+	// pausedWantState is the state last requested since then.
+	pausedBy        string
+	pausedWantState servingState
+	// streams are the running streaming requests. Their memory
+	// is accounted in streamsMem by chunks of tokens.
+	streams          map[*streamToken]struct{}
+	streamsMem       *memoryAccount
+	streamsMemChunks int
+
+	// pendingReload is the schema reload that ReloadSchema
+	// calls join. It's protected by reloadMu.
+	reloadMu      sync.Mutex
+	pendingReload *schemaReload
 
 	requests sync.WaitGroup
+	// requestsInFlight counts the requests of the waitgroup,
+	// which can't tell how many there are.
+	requestsInFlight sync2.AtomicInt64
+
+	// stopping is set while StopService runs. Its phases are
+	// recorded in shutdownPhases, which is protected by mu.
+	stopping       sync2.AtomicBool
+	shutdownPhases []shutdownPhase
+
+	// configSnapshot is the config of Init. It's protected by mu.
+	configSnapshot *configSnapshot
 
 	// Open must be done in forward order.
 	// Close must be done in reverse order.
@@ -98,53 +298,337 @@ type stateManager struct {
 	hs          *healthStreamer
 	se          schemaEngine
 	rt          replTracker
-	vstreamer   subComponent
+	vstreamer   vstreamEngine
 	tracker     subComponent
-	watcher     subComponent
+	watcher     binlogWatcher
 	qe          queryEngine
 	txThrottler txThrottler
 	te          txEngine
 	messager    subComponent
 	throttler   lagThrottler
 
-	// hcticks starts on initialiazation and runs forever.
-	hcticks *timer.Timer
+	// servingOrder lists the master-only subcomponents
+	// in the order in which they're opened.
+	servingOrder []servingComponent
+	// serialOpens makes runSteps open the subcomponents one at
+	// a time instead of concurrently.
+	serialOpens bool
+
+	// clock measures the retry interval, the grace period,
+	// the timebomb and the health check interval. It's
+	// replaced by a fake clock in tests. Init defaults it
+	// to the real clock.
+	clock timer.Clock
+
+	// newSpan creates the tracing spans of the transitions. It's
+	// replaced by a recorder in tests. Init defaults it to
+	// trace.NewSpan.
+	newSpan func(ctx context.Context, label string) (trace.Span, context.Context)
+
+	// transitionCtx carries the span of the running transition.
+	// It's protected by transitioning: it's only set and read by
+	// the goroutine that holds it. timeOp creates a child span
+	// for each operation if it's set.
+	transitionCtx context.Context
+
+	// hcticks starts on initialiazation and runs forever. Its interval
+	// is servingInterval while serving, and notServingInterval
+	// otherwise. See updateBroadcastInterval.
+	hcticks            *timer.Timer
+	servingInterval    time.Duration
+	notServingInterval time.Duration
 
 	// checkMySQLThrottler ensures that CheckMysql
 	// doesn't get spammed.
 	checkMySQLThrottler *sync2.Semaphore
-
-	timebombDuration      time.Duration
-	unhealthyThreshold    time.Duration
-	transitionGracePeriod time.Duration
+	// checkMySQLMinErrorTables is the number of tables whose queries
+	// must have failed with connection errors for a check that can
+	// connect to mysql to shut down the query service. The checks
+	// suppressed because of it are counted by checkMySQLSuppressions.
+	checkMySQLMinErrorTables int
+	checkMySQLSuppressions   *stats.Counter
+	checkMySQLSuppressedLog  *logutil.ThrottledLogger
+
+	// replHealthRefreshes counts the refreshes requested through
+	// RefreshReplHealth.
+	replHealthRefreshes *stats.Counter
+
+	// watchdog periodically checks that the state converges
+	// to the desired one. It runs from Init until StopService.
+	watchdog         *timer.Timer
+	stuckTransitions *stats.Counter
+	stuckThreshold   time.Duration
+	stuckHook        string
+
+	// flaps detects a tablet flapping between serving and not
+	// serving. It's protected by mu. operatorTransition is set
+	// while a transition requested through SetServingType runs.
+	// Like transitionCtx, it's protected by transitioning.
+	flaps              *flapDetector
+	operatorTransition bool
+
+	// crashOnPanic disables the recovery of panics in the
+	// goroutines owned by the state manager.
+	crashOnPanic     bool
+	transitionPanics *stats.Counter
+	// componentPanics counts the panics recovered in the opens and
+	// closes of the subcomponents, by operation. closePanicked is
+	// set if a close panicked since the start of the last closeAll,
+	// and dirtyShutdown if one did during it. Both are protected
+	// by mu.
+	componentPanics *stats.CountersWithSingleLabel
+	closePanicked   bool
+	dirtyShutdown   bool
+
+	// streamsDrained counts the streams signaled by DrainStreams.
+	// If drainStreamsOnLameduck is set, EnterLameduck drains them.
+	streamsDrained         *stats.Counter
+	drainStreamsOnLameduck bool
+
+	// keepVStreamerOnBackup makes a BACKUP tablet not serving instead
+	// of not connected, with se and vstreamer left open. See unserveBackup.
+	keepVStreamerOnBackup bool
+
+	// structuredLogs makes the decision points log JSON
+	// objects instead of messages. See logDecision.
+	structuredLogs bool
+
+	// quiescer holds the tables quiesced by QuiesceTable.
+	// They're all resumed when the tablet type changes.
+	quiescer *tableQuiescer
+
+	// annotations are set by SetHealthAnnotation, and reported
+	// in the health stream. StopService clears them.
+	annotations map[string]string
+
+	// export is the snapshot export in progress, or nil. It's
+	// released when the tablet type changes. See StartSnapshotExport.
+	export         *SnapshotExport
+	exportMaxPause time.Duration
+	exportTTL      time.Duration
+
+	// restored is the state snapshot loaded by RestoreState, or nil.
+	// It's consumed by the next SetServingType.
+	restored *stateSnapshot
+
+	// subcomponents is the status of the subcomponents. It's
+	// updated by the transition operations, and protected by mu.
+	subcomponents map[string]string
+	// subcomponentErrs are the errors reported by the unhealthy
+	// subcomponents at the last broadcast. They make the tablet
+	// stop serving if unhealthySubcomponentsStopServing is set.
+	subcomponentErrs                  map[string]error
+	unhealthySubcomponentsStopServing bool
+
+	// broadcastsDisabled is set while a self test runs: the
+	// health of the tablet isn't broadcast. It's protected by mu.
+	broadcastsDisabled bool
+
+	// transitionOps lists the operations of the ongoing
+	// transition. The timings of the operations are also
+	// recorded in opWallTimings.
+	transitionOps []transitionOp
+	opWallTimings *servenv.TimingsWrapper
+
+	// transitionTimings records the latency of the SetServingType
+	// calls that transitioned, by tablet type before and after.
+	// timeToFirstServing records, once, the time from processStart
+	// to the first time the tablet served.
+	transitionTimings  *servenv.MultiTimingsWrapper
+	timeToFirstServing *stats.Histogram
+	processStart       time.Time
+	servedOnce         bool
+	// serverIdentity is the identity of the mysql server the tablet
+	// is expected to be connected to, and lastServerIdentity the one
+	// it last connected to. serverIdentityErr is set once it changed.
+	// See checkServerIdentity.
+	serverIdentity            schema.ServerIdentity
+	lastServerIdentity        schema.ServerIdentity
+	serverIdentityErr         error
+	serverIdentityChangeFatal bool
+	serverIdentityChanges     *stats.Counter
+	// masterWritableWait is how long serveMaster waits for mysql to
+	// stop being read-only, unless skipReadOnlyCheck is set. writableErr
+	// is set while mysql is read-only. See waitForWritable.
+	masterWritableWait time.Duration
+	skipReadOnlyCheck  bool
+	writableErr        error
+	// retryableRequestErrors makes StartRequest reject the requests
+	// that can be retried on this tablet with UNAVAILABLE.
+	retryableRequestErrors bool
+	// transitionTimeout bounds the opens of a transition, if set. A
+	// transition that times out disconnects, and timeoutErr is reported
+	// as a health error until a transition succeeds. See
+	// transitionContext.
+	transitionTimeout time.Duration
+	timeoutErr        error
+
+	// initialized is set once the tablet manager first sets the
+	// serving type. Until then, the target isn't known: the tablet
+	// reports that it's initializing, and rejects all requests.
+	initialized bool
+
+	// asyncTransitions are the handles of the SetServingTypeAsync
+	// calls by token: the pending ones, and the last resolved ones,
+	// listed in asyncResolved. asyncCurrent is the handle of the
+	// current intent, if it was requested asynchronously.
+	nextTransitionToken int64
+	asyncTransitions    map[int64]*TransitionHandle
+	asyncResolved       []int64
+	asyncCurrent        *TransitionHandle
+
+	// topoTicks periodically checks how long ago the topo was
+	// last seen if topoIsolationTimeout is set.
+	topoTicks            *timer.Timer
+	topoIsolations       *stats.Counter
+	topoIsolationTimeout time.Duration
+
+	// mysqlProbeTicks periodically checks that mysql is reachable
+	// if the probe interval is set. mysqlProbing is set while a
+	// probe runs, so that a slow mysql doesn't pile them up.
+	mysqlProbeTicks    *timer.Timer
+	mysqlProbing       sync2.AtomicBool
+	mysqlProbeTimings  *servenv.TimingsWrapper
+	mysqlProbeFailures *stats.Counter
+	mysqlProbesSkipped *stats.Counter
+
+	// readOnlyErr is set while a master serves reads only because
+	// mysql fails writes. It's protected by mu. readOnlyTicks
+	// periodically checks if the writes succeed again.
+	readOnlyErr      error
+	readOnlyTicks    *timer.Timer
+	readOnlyServings *stats.Counter
+
+	// replLagRejections counts the requests rejected by
+	// CheckReplicationLag, by keyspace.
+	replLagRejections *stats.CountersWithSingleLabel
+
+	// targetAliasHits counts the requests that targeted an alias
+	// of the keyspace or shard, by alias. It shows when the clients
+	// are done migrating to the new names.
+	targetAliasHits *stats.CountersWithMultiLabels
+
+	// roleConfidence is computed at every broadcast while the
+	// tablet is a serving master, from the last roleSample that
+	// roleStatusTicks took. They're nil otherwise. They're only
+	// computed if roleConfidenceThreshold is set.
+	roleConfidence          *roleConfidence
+	roleConfidenceThreshold int
+	roleSample              *roleSample
+	roleStatusTicks         *timer.Timer
+
+	// watcherStatus is polled at every broadcast. The watcher
+	// reports its state as n/a while it's closed.
+	watcherStatus WatcherStatus
+
+	// pressure is the hot row protection mode last pushed to qe,
+	// and pressureCause the condition that caused it. They're
+	// refreshed at every broadcast if failFastUnderPressure is set.
+	pressure              pressureMode
+	pressureCause         string
+	failFastUnderPressure bool
+
+	// shedFraction is the fraction of the requests StartRequest
+	// sheds while the replication lag is degraded. It's refreshed
+	// with the replication health if shedMaxFraction is set.
+	shedFraction    float64
+	shedMaxFraction float64
+	shedRand        func() float64
+	requestsShed    *stats.Counter
+
+	// terRegression is what to do with a transition to master
+	// whose terTimestamp is older than the current one: one of
+	// tabletenv.Reject, Warn or Ignore.
+	terRegression  string
+	terRegressions *stats.CountersWithSingleLabel
+
+	// promotion is the promotion verdict, refreshed at every
+	// broadcast. It combines the state of sm with the result of
+	// the last transition dry run, which promotionTicks runs at
+	// the health check interval if -enable_promotion_check is set.
+	promotion          *promotionVerdict
+	promotionCheckErr  error
+	promotionCheckedAt time.Time
+	promotionTicks     *timer.Timer
+
+	// memory tracks the estimated memory used by the health
+	// streamer buffers and the running streams.
+	memory *memoryAccounting
+
+	// lagHistory keeps the last replication lag samples. It's nil
+	// if disabled. See recordLagSampleLocked.
+	lagHistory *history.History
+
+	// live holds the config fields that can be reloaded at runtime:
+	// the lag thresholds and the transition grace period are read
+	// from it at every use.
+	live *liveConfig
+
+	timebombDuration     time.Duration
+	queryKillGracePeriod time.Duration
+	snapshotMaxAge       time.Duration
+	livenessThreshold    time.Duration
+	// planWarmupTimeout bounds the plan warmup of a new
+	// master. It's 0 if the warmup is disabled.
+	planWarmupTimeout time.Duration
+
+	serveWithoutReplication bool
+	// throttleOnReplicas makes serveNonMaster open the lag throttler.
+	// It's then left open when a master is demoted, and closed with
+	// the other serving components.
+	throttleOnReplicas bool
+	// consolidatorMode returns the consolidator mode of a tablet
+	// type. setState switches the mode of qe along with the type.
+	consolidatorMode func(topodatapb.TabletType) string
 }
 
 type (
 	schemaEngine interface {
-		EnsureConnectionAndDB(topodatapb.TabletType) error
-		Open() error
+		EnsureConnectionAndDB(ctx context.Context, createDB bool) (bool, error)
+		Open(ctx context.Context) error
 		MakeNonMaster()
+		ReloadTables(context.Context) ([]string, error)
+		RegisterNotifier(name string, f schema.Notifier)
+		UnregisterNotifier(name string)
 		Close()
+		ServerIdentity() schema.ServerIdentity
+		IsReadOnly() (bool, error)
 	}
 
 	replTracker interface {
 		MakeMaster()
 		MakeNonMaster()
+		PauseWrites()
 		Close()
-		Status() (time.Duration, error)
+		Status() (time.Duration, repltracker.LagSource, error)
+		CrossCheck(lag time.Duration) error
+		RoleStatus() (repltracker.RoleStatus, error)
 	}
 
 	queryEngine interface {
-		Open() error
-		IsMySQLReachable() error
+		Open(ctx context.Context) error
+		IsMySQLReachable(ctx context.Context, checkWrites bool) error
+		CreateSidecarTables(ctx context.Context) error
 		StopServing()
+		KillActiveQueries(olderThan time.Duration, reason string)
 		Close()
+		PoolUsage() (inUse, capacity int64)
+		SetPressureMode(mode pressureMode)
+		SetConsolidatorMode(mode string)
+		WarmPlans(ctx context.Context) (int, error)
+		ConnErrorTables() int
 	}
 
 	txEngine interface {
-		AcceptReadWrite() error
+		CreateSidecarTables() error
+		AcceptReadWrite(ctx context.Context) error
 		AcceptReadOnly() error
 		Close()
+		PoolUsage() (inUse, capacity int64)
+		Draining() (remaining int64, ok bool)
+		OpenTransactions() []OpenTransaction
+		ReservedConnections() []ReservedConnection
+		UnresolvedPrepares() (count int64, maxAge time.Duration, err error)
 	}
 
 	subComponent interface {
@@ -152,6 +636,18 @@ type (
 		Close()
 	}
 
+	binlogWatcher interface {
+		Open()
+		Close()
+		Status() WatcherStatus
+	}
+
+	vstreamEngine interface {
+		Open()
+		Close()
+		ActiveStreams() int
+	}
+
 	txThrottler interface {
 		Open() error
 		Close()
@@ -160,18 +656,243 @@ type (
 	lagThrottler interface {
 		Open() error
 		Close()
+		CheckByApp(ctx context.Context, appName string) *throttle.CheckResult
 	}
 )
 
 // Init performs the second phase of initialization.
-func (sm *stateManager) Init(env tabletenv.Env, target querypb.Target) {
+// It fails if the configured serving order is invalid.
+func (sm *stateManager) Init(env tabletenv.Env, target querypb.Target) error {
+	sm.mu.Lock()
+	sm.configSnapshot = newConfigSnapshot(env.Config())
+	sm.mu.Unlock()
+	sm.throttleOnReplicas = env.Config().ThrottleOnReplicas
+	sm.consolidatorMode = env.Config().ConsolidatorMode
+	sm.serialOpens = env.Config().SerialTransitionOpens
+	servingOrder, err := sm.buildServingOrder(env.Config().ServingOrder)
+	if err != nil {
+		return err
+	}
+	sm.servingOrder = servingOrder
 	sm.target = target
+	sm.subcomponents = make(map[string]string, len(subcomponentNames))
+	for _, name := range subcomponentNames {
+		sm.subcomponents[name] = "closed"
+	}
+	sm.subcomponentErrs = make(map[string]error)
+	sm.unhealthySubcomponentsStopServing = env.Config().Healthcheck.UnhealthySubcomponentsStopServing
+	if sm.clock == nil {
+		sm.clock = timer.RealClock
+	}
+	if sm.newSpan == nil {
+		sm.newSpan = trace.NewSpan
+	}
 	sm.transitioning = sync2.NewSemaphore(1, 0)
 	sm.checkMySQLThrottler = sync2.NewSemaphore(1, 0)
+	sm.checkMySQLMinErrorTables = env.Config().Healthcheck.CheckMySQLMinErrorTables
+	sm.checkMySQLSuppressions = env.Exporter().NewCounter("CheckMySQLSuppressions", "Count of failed mysql checks that did not shut down the query service because the connection errors were confined to too few tables")
+	sm.checkMySQLSuppressedLog = logutil.NewThrottledLogger("CheckMySQLSuppressed", time.Minute)
 	sm.timebombDuration = env.Config().OltpReadPool.TimeoutSeconds.Get() * 10
-	sm.hcticks = timer.NewTimer(env.Config().Healthcheck.IntervalSeconds.Get())
-	sm.unhealthyThreshold = env.Config().Healthcheck.UnhealthyThresholdSeconds.Get()
-	sm.transitionGracePeriod = env.Config().GracePeriods.TransitionSeconds.Get()
+	sm.servingInterval = env.Config().Healthcheck.IntervalSeconds.Get()
+	sm.notServingInterval = env.Config().Healthcheck.NotServingIntervalSeconds.Get()
+	if sm.notServingInterval == 0 {
+		sm.notServingInterval = sm.servingInterval
+	}
+	// The tablet starts not serving.
+	sm.hcticks = timer.NewTimerWithClock(sm.notServingInterval, sm.clock)
+	_ = env.Exporter().NewGaugeDurationFunc("HealthBroadcastInterval", "Current interval between the periodic health broadcasts, which depends on whether the tablet is serving", sm.hcticks.Interval)
+	if sm.live == nil {
+		sm.live = newLiveConfig(env.Config())
+	}
+	sm.queryKillGracePeriod = env.Config().GracePeriods.QueryKillSeconds.Get()
+	sm.snapshotMaxAge = env.Config().StateSnapshot.MaxAgeSeconds.Get()
+	if env.Config().PlanWarmup.Enabled() {
+		sm.planWarmupTimeout = env.Config().PlanWarmup.TimeoutSeconds.Get()
+	}
+	sm.livenessThreshold = env.Config().Healthcheck.LivenessThresholdSeconds.Get()
+	sm.serveWithoutReplication = env.Config().ReplicationTracker.ServeWithoutReplication
+	sm.replHealthRefreshes = env.Exporter().NewCounter("ReplHealthManualRefreshes", "Count of replication health refreshes requested by an operator")
+	sm.staleRetries = env.Exporter().NewCounter("StaleTransitionRetries", "Count of transition retries skipped because a newer request was about to replace their goal")
+
+	sm.stuckThreshold = env.Config().Healthcheck.StuckTransitionThresholdSeconds.Get()
+	sm.stuckHook = env.Config().Healthcheck.StuckTransitionHook
+	sm.stuckTransitions = env.Exporter().NewCounter("StuckTransitions", "Count of state transitions that did not complete within the stuck transition threshold")
+	sm.flaps = &flapDetector{
+		threshold:      env.Config().Healthcheck.FlapThreshold,
+		window:         env.Config().Healthcheck.FlapWindowSeconds.Get(),
+		ignoreOperator: env.Config().Healthcheck.FlapIgnoreOperator,
+		hooks: []flapHook{
+			logFlapHook{},
+			statsFlapHook{events: env.Exporter().NewCountersWithSingleLabel("ServingFlaps", "Count of times the tablet started and stopped flapping between serving and not serving, by event", "event")},
+		},
+	}
+	if name := env.Config().Healthcheck.FlapHook; name != "" {
+		sm.flaps.hooks = append(sm.flaps.hooks, execFlapHook{name: name})
+	}
+	env.Exporter().NewGaugeFunc("ServingFlapping", "Set to 1 while the tablet is flapping between serving and not serving", func() int64 {
+		sm.mu.Lock()
+		defer sm.mu.Unlock()
+		if sm.flaps.Flapping() {
+			return 1
+		}
+		return 0
+	})
+	sm.crashOnPanic = env.Config().CrashOnTransitionPanic
+	sm.serverIdentityChangeFatal = env.Config().MySQLServerIdentityChangeFatal
+	sm.masterWritableWait = env.Config().GracePeriods.MasterWritableWaitSeconds.Get()
+	sm.skipReadOnlyCheck = env.Config().SkipMasterReadOnlyCheck
+	sm.retryableRequestErrors = env.Config().RetryableRequestErrors
+	sm.transitionTimeout = env.Config().GracePeriods.TransitionTimeoutSeconds.Get()
+	sm.serverIdentityChanges = env.Exporter().NewCounter("MySQLServerIdentityChanges", "Count of times the server_uuid or server_id of the mysql server changed without a restart")
+	sm.transitionPanics = env.Exporter().NewCounter("TransitionPanics", "Count of panics recovered during state transitions")
+	sm.componentPanics = env.Exporter().NewCountersWithSingleLabel("SubcomponentPanics", "Count of panics recovered in the opens and closes of the subcomponents, by operation", "operation")
+	sm.drainStreamsOnLameduck = env.Config().DrainStreamsOnLameduck
+	sm.keepVStreamerOnBackup = env.Config().KeepVStreamerOnBackup
+	sm.structuredLogs = env.Config().StructuredStateLogs
+	sm.quiescer = newTableQuiescer(env)
+	sm.exportMaxPause = env.Config().SnapshotExport.MaxPauseSeconds.Get()
+	sm.exportTTL = env.Config().SnapshotExport.TTLSeconds.Get()
+	sm.streamsDrained = env.Exporter().NewCounter("StreamsDrained", "Count of streaming requests asked to end early because the tablet is draining")
+	env.Exporter().NewGaugeFunc("StreamsRunning", "Number of running streaming requests", func() int64 {
+		running, _ := sm.streamCounts()
+		return running
+	})
+	env.Exporter().NewGaugeFunc("StreamsDraining", "Number of streaming requests that were asked to drain and are still running", func() int64 {
+		_, draining := sm.streamCounts()
+		return draining
+	})
+	sm.opWallTimings = env.Exporter().NewTimings("TransitionOpWallTimings", "Wall time of the subcomponent operations during state transitions", "operation")
+	sm.transitionTimings = env.Exporter().NewMultiTimings("TransitionTimings", "Time taken by SetServingType to transition, including the wait for the requests and the subcomponent opens, by tablet type before and after", []string{"from_type", "to_type"})
+	sm.timeToFirstServing = env.Exporter().NewHistogram("TimeToFirstServingMs", "Time in milliseconds from the process start to the first time the tablet served", timeToFirstServingCutoffs)
+	sm.processStart = processStart
+	sm.watchdog = timer.NewTimer(transitionWatchdogInterval)
+	if sm.stuckThreshold != 0 {
+		sm.watchdog.Start(sm.checkTransition)
+	}
+	sm.topoIsolationTimeout = env.Config().Healthcheck.TopoIsolationTimeoutSeconds.Get()
+	sm.topoIsolations = env.Exporter().NewCounter("TopoIsolations", "Count of times a master stopped serving because it could not reach the topo")
+	env.Exporter().NewGaugeDurationFunc("TopoIsolationRemaining", "Time left before a master that cannot reach the topo stops serving", sm.topoIsolationRemaining)
+	sm.topoTicks = timer.NewTimer(topoIsolationCheckInterval)
+	if sm.topoIsolationTimeout != 0 {
+		sm.topoTicks.Start(sm.checkTopoIsolation)
+	}
+	sm.mysqlProbeTimings = env.Exporter().NewTimings("MySQLProbeTimings", "Time taken by the background checks that mysql is reachable", "result")
+	sm.mysqlProbeFailures = env.Exporter().NewCounter("MySQLProbeFailures", "Count of background checks that could not reach mysql")
+	sm.mysqlProbesSkipped = env.Exporter().NewCounter("MySQLProbesSkipped", "Count of background mysql checks skipped because the previous one was still running")
+	sm.mysqlProbeTicks = timer.NewTimer(env.Config().Healthcheck.MySQLProbeIntervalSeconds.Get())
+	if sm.mysqlProbeTicks.Interval() != 0 {
+		sm.mysqlProbeTicks.Start(sm.probeMySQL)
+	}
+	sm.readOnlyServings = env.Exporter().NewCounter("ReadOnlyServings", "Count of times a master switched to serving reads only because mysql failed writes")
+	env.Exporter().NewGaugeFunc("ServingReadOnly", "Set to 1 while a master serves reads only because mysql fails writes", func() int64 {
+		if sm.readOnlyError() != nil {
+			return 1
+		}
+		return 0
+	})
+	sm.readOnlyTicks = timer.NewTimer(readOnlyCheckInterval)
+	sm.targetAliasHits = env.Exporter().NewCountersWithMultiLabels("TargetAliasHits", "Count of requests that targeted an alias of the keyspace or shard of the tablet, by alias", []string{"type", "alias"})
+	sm.replLagRejections = env.Exporter().NewCountersWithSingleLabel("ReplicationLagRejections", "Count of requests rejected because the replication lag exceeded the max they allowed, by keyspace", "keyspace")
+	sm.readOnlyTicks.Start(sm.checkReadOnly)
+	sm.promotionTicks = timer.NewTimer(env.Config().Healthcheck.IntervalSeconds.Get())
+	if env.Config().Healthcheck.PromotionCheck {
+		sm.promotionTicks.Start(sm.checkPromotion)
+	}
+	sm.roleConfidenceThreshold = env.Config().Healthcheck.RoleConfidenceDegradedThreshold
+	sm.roleStatusTicks = timer.NewTimer(env.Config().Healthcheck.IntervalSeconds.Get())
+	if sm.roleConfidenceThreshold != 0 {
+		sm.roleStatusTicks.Start(sm.sampleRoleStatus)
+	}
+	sm.failFastUnderPressure = env.Config().HotRowProtection.FailFastUnderPressure
+	sm.terRegression = env.Config().TerTimestampRegression
+	sm.terRegressions = env.Exporter().NewCountersWithSingleLabel("TerTimestampRegressions", "Count of transitions to master with a timestamp older than the current one, by action taken", "action")
+	sm.shedMaxFraction = env.Config().Healthcheck.DegradedShedMaxFraction
+	sm.shedRand = rand.Float64
+	sm.requestsShed = env.Exporter().NewCounter("RequestsShed", "Count of requests shed because the replication lag was degraded")
+	env.Exporter().NewGaugeFunc("ShedPercent", "Percentage of the requests shed because the replication lag is degraded", sm.shedGauge)
+	env.Exporter().NewGaugeFunc("UnhealthySubcomponents", "Number of subcomponents that reported themselves unhealthy at the last broadcast", func() int64 {
+		sm.mu.Lock()
+		defer sm.mu.Unlock()
+		return int64(len(sm.subcomponentErrs))
+	})
+	env.Exporter().NewGaugeFunc("HotRowFailFast", "Set to 1 while hot row protection fails transactions fast because the tablet is under pressure", sm.pressureGauge)
+	sm.memory = newMemoryAccounting(env)
+	sm.hs.registerMemory(sm.memory)
+	if size := env.Config().Healthcheck.LagHistorySize; size > 0 {
+		sm.lagHistory = history.New(size)
+		sm.registerLagHistoryMemory()
+	}
+	sm.streamsMem = sm.memory.register("requestTracker", 0, nil)
+	if path := env.Config().TransitionAuditLog; path != "" {
+		if sm.transitionAudit, err = openTransitionAudit(path); err != nil {
+			return err
+		}
+	}
+	env.Exporter().NewGaugeFunc("Promotable", "Set to 1 if the last promotion check found the tablet to be a viable reparent target", sm.promotableGauge)
+	env.Exporter().NewGaugeFunc("RoleConfidence", "Estimated likelihood, in percent, that a master really is the master of its shard. -1 for other tablets", sm.roleConfidenceGauge)
+	return nil
+}
+
+// buildServingOrder returns the serving components in the order
+// of names, or in the default order if names is empty. Every
+// component must be listed exactly once.
+func (sm *stateManager) buildServingOrder(names []string) ([]servingComponent, error) {
+	configured := len(names) != 0
+	if !configured {
+		names = defaultServingOrder
+	}
+	components := map[string]servingComponent{
+		"tracker": {
+			open:  func(context.Context) error { sm.tracker.Open(); return nil },
+			close: func() { sm.tracker.Close() },
+			after: []string{"se.Open", "vstreamer.Open"},
+		},
+		"txEngine": {
+			// The 2pc sidecar tables must exist before
+			// the tx engine accepts writes.
+			prepare:  func() error { return sm.te.CreateSidecarTables() },
+			open:     sm.te.AcceptReadWrite,
+			close:    func() { sm.te.Close() },
+			after:    []string{"qe.Open"},
+			readOnly: true,
+		},
+		"messager": {
+			open:  func(context.Context) error { sm.messager.Open(); return nil },
+			close: func() { sm.messager.Close() },
+			after: []string{"se.Open", "txEngine.Open"},
+		},
+		"throttler": {
+			open:     func(context.Context) error { return sm.throttler.Open() },
+			close:    func() { sm.throttler.Close() },
+			after:    []string{"rt.MakeMaster"},
+			readOnly: sm.throttleOnReplicas,
+		},
+	}
+	order := make([]servingComponent, 0, len(names))
+	listed := make(map[string]bool, len(names))
+	for _, name := range names {
+		c, ok := components[name]
+		if !ok {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unknown serving component %q", name)
+		}
+		if listed[name] {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "serving component %q is listed more than once", name)
+		}
+		listed[name] = true
+		c.name = name
+		if configured && len(order) != 0 {
+			// A configured order is kept even if the
+			// components don't depend on each other.
+			c.after = append(c.after, order[len(order)-1].name+".Open")
+		}
+		order = append(order, c)
+	}
+	for _, name := range defaultServingOrder {
+		if !listed[name] {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "serving component %q is missing from the serving order", name)
+		}
+	}
+	return order, nil
 }
 
 // SetServingType changes the state to the specified settings.
@@ -180,74 +901,220 @@ func (sm *stateManager) Init(env tabletenv.Env, target querypb.Target) {
 // launches retryTransition to ensure that the request will eventually
 // be honored.
 // If sm is already in the requested state, it returns stateChanged as
-// false.
-func (sm *stateManager) SetServingType(tabletType topodatapb.TabletType, terTimestamp time.Time, state servingState, reason string) error {
-	defer sm.ExitLameduck()
+// false. The transition is traced as a child of the span of ctx.
+func (sm *stateManager) SetServingType(ctx context.Context, tabletType topodatapb.TabletType, terTimestamp time.Time, state servingState, reason string) error {
+	return sm.requestServingType(ctx, tabletType, terTimestamp, state, reason, nil)
+}
+
+// requestServingType implements SetServingType. h is the handle
+// of the request if it was made by SetServingTypeAsync.
+func (sm *stateManager) requestServingType(ctx context.Context, tabletType topodatapb.TabletType, terTimestamp time.Time, state servingState, reason string, h *TransitionHandle) error {
+	sm.clearShutdownPhases()
+	terRegression, err := sm.setServingType(ctx, tabletType, terTimestamp, state, reason, NotConnectedByOperator, h)
+	sm.applyRestored(tabletType, err)
+	if err == nil {
+		sm.mu.Lock()
+		initialized := sm.endInitializingLocked()
+		sm.mu.Unlock()
+		if initialized {
+			sm.hcticks.Trigger()
+		}
+	}
+	sm.audit(&TransitionAuditEntry{
+		Event:          auditSetServingType,
+		WantTabletType: tabletType.String(),
+		WantState:      state.String(),
+		TerTimestamp:   terTimestamp,
+		Reason:         reason,
+		TerRegression:  terRegression,
+	}, err)
+	return err
+}
+
+// setServingType returns what was done about a terTimestamp older
+// than the current one, if it was, as well as the transition error.
+// h is the handle of the request, if it's asynchronous.
+func (sm *stateManager) setServingType(ctx context.Context, tabletType topodatapb.TabletType, terTimestamp time.Time, state servingState, reason string, ncs notConnectedState, h *TransitionHandle) (terRegression string, err error) {
+	defer sm.exitLameduck()
+	start := time.Now()
+	fromType := sm.Target().TabletType
 
 	sm.hs.Open()
 	sm.hcticks.Start(sm.Broadcast)
 
-	if tabletType == topodatapb.TabletType_RESTORE || tabletType == topodatapb.TabletType_BACKUP {
+	switch {
+	case tabletType == topodatapb.TabletType_BACKUP && sm.keepVStreamerOnBackup:
+		state, reason = StateNotServing, backupReason
+	case tabletType == topodatapb.TabletType_RESTORE || tabletType == topodatapb.TabletType_BACKUP:
 		state = StateNotConnected
 	}
-
-	log.Infof("Starting transition to %v %v, timestamp: %v", tabletType, state, terTimestamp)
-	if sm.mustTransition(tabletType, terTimestamp, state, reason) {
-		return sm.execTransition(tabletType, state)
+	state, reason = sm.applyTopoIsolation(tabletType, state, reason)
+	state, reason = sm.applyPause(state, reason)
+
+	d := sm.decision(decisionTransitionStart, nil)
+	d.ToState, d.TabletType, d.Reason = state.String(), tabletType.String(), reason
+	sm.logDecision(log.Infof, d, "Starting transition to %v %v, timestamp: %v", tabletType, state, terTimestamp)
+	must, terRegression, err := sm.mustTransition(tabletType, terTimestamp, state, reason, ncs, h)
+	if err != nil || !must {
+		return terRegression, err
 	}
-	return nil
+	// The transition holds the semaphore acquired by mustTransition.
+	sm.operatorTransition = ncs == NotConnectedByOperator
+	err = sm.execTransition(ctx, tabletType, state)
+	sm.transitionTimings.Record([]string{fromType.String(), tabletType.String()}, start)
+	return terRegression, err
 }
 
 // mustTransition returns true if the requested state does not match the current
 // state. If so, it acquires the semaphore and returns true. If a transition is
 // already in progress, it waits. If the desired state is already reached, it
-// returns false without acquiring the semaphore.
-func (sm *stateManager) mustTransition(tabletType topodatapb.TabletType, terTimestamp time.Time, state servingState, reason string) bool {
+// returns false without acquiring the semaphore. It also returns false, with
+// an error, if the request is rejected by checkTerTimestampLocked. An
+// accepted request supersedes the asynchronous one it replaces, if any.
+// While the request waits, it's counted in pendingRequests.
+func (sm *stateManager) mustTransition(tabletType topodatapb.TabletType, terTimestamp time.Time, state servingState, reason string, ncs notConnectedState, h *TransitionHandle) (bool, string, error) {
+	sm.mu.Lock()
+	sm.pendingRequests++
+	sm.mu.Unlock()
 	sm.transitioning.Acquire()
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
+	sm.pendingRequests--
 
+	terRegression, err := sm.checkTerTimestampLocked(tabletType, terTimestamp)
+	if err != nil {
+		sm.transitioning.Release()
+		return false, terRegression, err
+	}
+	sm.acceptAsyncLocked(h)
+	sm.wantGeneration++
 	sm.wantTabletType = tabletType
 	sm.wantState = state
+	sm.wantNotConnected = ncs
 	sm.terTimestamp = terTimestamp
 	sm.reason = reason
 	if sm.target.TabletType == tabletType && sm.state == state {
+		// A shutdown supersedes the reason why we're not connected.
+		if state == StateNotConnected && ncs == NotConnectedShuttingDown {
+			sm.notConnected = ncs
+		}
 		sm.transitioning.Release()
-		return false
+		return false, terRegression, nil
 	}
-	return true
+	return true, terRegression, nil
 }
 
-func (sm *stateManager) execTransition(tabletType topodatapb.TabletType, state servingState) error {
+func (sm *stateManager) execTransition(ctx context.Context, tabletType topodatapb.TabletType, state servingState) (err error) {
 	defer sm.transitioning.Release()
+	defer func() { sm.operatorTransition = false }()
+	defer sm.recoverTransition(&err)
 
-	var err error
+	sm.mu.Lock()
+	sm.transitionStart = time.Now()
+	sm.transitionOps = nil
+	// The transition reopens or closes the components
+	// that were closed for read-only serving.
+	sm.readOnlyErr = nil
+	if tabletType != topodatapb.TabletType_MASTER {
+		sm.writableErr = nil
+	}
+	reason := sm.reason
+	sm.mu.Unlock()
+
+	span, ctx := sm.newSpan(ctx, "stateManager.transition")
+	span.Annotate("tablet_type", tabletType.String())
+	span.Annotate("target_state", state.String())
+	span.Annotate("reason", reason)
+	defer sm.traceTransition(ctx, span, &err)()
+
+	// The opens don't depend on the context of the caller,
+	// which may be canceled before the transition completes.
+	openCtx, cancel := sm.transitionContext()
+	defer cancel()
 	switch state {
 	case StateServing:
 		if tabletType == topodatapb.TabletType_MASTER {
-			err = sm.serveMaster()
+			err = sm.serveMaster(openCtx)
 		} else {
-			err = sm.serveNonMaster(tabletType)
+			err = sm.serveNonMaster(openCtx, tabletType)
 		}
+	case StateServingReadOnly:
+		err = sm.serveMaintenance(openCtx, tabletType)
 	case StateNotServing:
-		if tabletType == topodatapb.TabletType_MASTER {
-			err = sm.unserveMaster()
-		} else {
-			err = sm.unserveNonMaster(tabletType)
+		switch tabletType {
+		case topodatapb.TabletType_MASTER:
+			err = sm.unserveMaster(openCtx)
+		case topodatapb.TabletType_BACKUP:
+			err = sm.unserveBackup(openCtx)
+		default:
+			err = sm.unserveNonMaster(openCtx, tabletType)
 		}
 	case StateNotConnected:
-		sm.closeAll()
+		sm.mu.Lock()
+		ncs := sm.wantNotConnected
+		sm.mu.Unlock()
+		sm.closeAll(ncs)
+	}
+	if _, ok := err.(*serverIdentityError); ok {
+		// The tablet must not keep serving from the
+		// connections it opened before.
+		sm.closeAll(NotConnectedByMySQLFailure)
+	}
+	timeoutErr, timedOut := err.(*transitionTimeoutError)
+	if timedOut {
+		// The components that did open are closed along with
+		// the others, for the retry to start from scratch.
+		log.Errorf("Transition to %v %v timed out: %v", tabletType, state, err)
+		sm.closeAll(NotConnectedByMySQLFailure)
 	}
 	sm.mu.Lock()
 	sm.transitionErr = err
+	sm.timeoutErr = nil
+	if timedOut {
+		sm.timeoutErr = timeoutErr
+	}
+	sm.transitionStart = time.Time{}
+	ops := sm.transitionOps
 	sm.mu.Unlock()
+	logSlowOps(ops)
+	sm.updateBroadcastInterval()
 	if err != nil {
-		sm.retryTransition(fmt.Sprintf("Error transitioning to the desired state: %v, %v, will keep retrying: %v", tabletType, state, err))
+		sm.retryTransition("transition failed", err, fmt.Sprintf("Error transitioning to the desired state: %v, %v, will keep retrying: %v", tabletType, state, err))
 	}
 	return err
 }
 
-func (sm *stateManager) retryTransition(message string) {
+// transitionContext returns the context of the opens of a transition.
+// It expires after transitionTimeout, if set, which cancels the
+// connection attempts and the queries of the opens in progress.
+func (sm *stateManager) transitionContext() (context.Context, context.CancelFunc) {
+	ctx := tabletenv.LocalContext()
+	if sm.transitionTimeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, sm.transitionTimeout)
+}
+
+// transitionIntent is the state requested by a SetServingType
+// call. The retries of a failed transition share its intent.
+type transitionIntent struct {
+	tabletType   topodatapb.TabletType
+	state        servingState
+	terTimestamp time.Time
+}
+
+func (sm *stateManager) intentLocked() transitionIntent {
+	return transitionIntent{
+		tabletType:   sm.wantTabletType,
+		state:        sm.wantState,
+		terTimestamp: sm.terTimestamp,
+	}
+}
+
+// retryTransition starts retrying the transition to the
+// desired state, unless it's already being retried. reason
+// and err are why, and message is the human-readable log.
+func (sm *stateManager) retryTransition(reason string, err error, message string) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 	if sm.retrying {
@@ -255,10 +1122,13 @@ func (sm *stateManager) retryTransition(message string) {
 	}
 	sm.retrying = true
 
-	log.Error(message)
+	d := sm.decisionLocked(decisionRetryScheduled, err)
+	d.ToState, d.TabletType, d.Reason = sm.wantState.String(), sm.wantTabletType.String(), reason
+	sm.logDecision(log.Errorf, d, "%s", message)
 	go func() {
+		defer sm.recoverPanic()
 		for {
-			time.Sleep(transitionRetryInterval)
+			<-sm.clock.After(transitionRetryInterval)
 			if sm.recheckState() {
 				return
 			}
@@ -270,231 +1140,1035 @@ func (sm *stateManager) recheckState() bool {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
+	if !sm.retrying {
+		// The retries were abandoned after a panic.
+		return true
+	}
 	if sm.wantState == sm.state && sm.wantTabletType == sm.target.TabletType {
 		sm.retrying = false
+		if h := sm.asyncCurrent; h != nil {
+			sm.resolveAsyncLocked(h, nil)
+		}
 		return true
 	}
+	if sm.pendingRequests != 0 {
+		// A newer request will replace the goal
+		// as soon as it gets the semaphore.
+		sm.staleRetries.Add(1)
+		log.Infof("Skipping the retry of the transition to %v: a newer request is pending", sm.stateStringLocked(sm.wantTabletType, sm.wantState))
+		return false
+	}
 	if !sm.transitioning.TryAcquire() {
 		return false
 	}
-	go sm.execTransition(sm.wantTabletType, sm.wantState)
+	go sm.execRetry(sm.wantGeneration, sm.wantTabletType, sm.wantState)
 	return false
 }
 
-// CheckMySQL verifies that we can connect to mysql.
-// If it fails, then we shutdown the service and initiate
-// the retry loop.
-func (sm *stateManager) CheckMySQL() {
-	if !sm.checkMySQLThrottler.TryAcquire() {
+// execRetry retries the transition to the goal of generation gen. It
+// must be called with the semaphore acquired. If a newer request came
+// in since the retry was scheduled, the stale attempt is dropped: the
+// newer request transitions to its own goal, and the retries follow
+// it if it fails.
+func (sm *stateManager) execRetry(gen int64, tabletType topodatapb.TabletType, state servingState) {
+	sm.mu.Lock()
+	stale := gen != sm.wantGeneration || sm.pendingRequests != 0
+	if stale {
+		sm.staleRetries.Add(1)
+		log.Infof("Dropping a stale retry of the transition to %v", sm.stateStringLocked(tabletType, state))
+	}
+	sm.mu.Unlock()
+	if stale {
+		sm.transitioning.Release()
 		return
 	}
-	go func() {
-		defer func() {
-			time.Sleep(1 * time.Second)
-			sm.checkMySQLThrottler.Release()
-		}()
+	// Retries have no caller to inherit a span from.
+	sm.execTransition(context.Background(), tabletType, state)
+}
 
-		err := sm.qe.IsMySQLReachable()
-		if err == nil {
-			return
-		}
+// checkTransition is invoked periodically by the watchdog. It reports
+// a state that has not converged to the desired one for longer than
+// stuckThreshold. It reports only once until the state converges.
+func (sm *stateManager) checkTransition() {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
 
-		if !sm.transitioning.TryAcquire() {
-			// If we're already transitioning, don't interfere.
-			return
+	if sm.wantState == sm.state && sm.wantTabletType == sm.target.TabletType && !sm.retrying {
+		if sm.stuckReported {
+			log.Infof("TabletServer transition to %v has completed", sm.stateStringLocked(sm.wantTabletType, sm.wantState))
+		}
+		sm.stuckSince = time.Time{}
+		sm.stuckReported = false
+		return
+	}
+	now := time.Now()
+	if sm.stuckSince.IsZero() {
+		sm.stuckSince = now
+		return
+	}
+	if sm.stuckReported || now.Sub(sm.stuckSince) < sm.stuckThreshold {
+		return
+	}
+	sm.stuckReported = true
+	sm.stuckTransitions.Add(1)
+	current := sm.stateStringLocked(sm.target.TabletType, sm.state)
+	want := sm.stateStringLocked(sm.wantTabletType, sm.wantState)
+	log.Errorf("TabletServer has been unable to transition from %v to %v for %v, last error: %v", current, want, now.Sub(sm.stuckSince), sm.transitionErr)
+	if sm.stuckHook == "" {
+		return
+	}
+	h := hook.NewHookWithEnv(sm.stuckHook, nil, map[string]string{
+		"TABLET_STATE":      current,
+		"WANT_TABLET_STATE": want,
+	})
+	go func() {
+		defer sm.recoverPanic()
+		if hr := runStuckTransitionHook(h); hr.ExitStatus != hook.HOOK_SUCCESS {
+			log.Errorf("Stuck transition hook %v failed: %v", h.Name, hr.String())
 		}
-		defer sm.transitioning.Release()
-
-		sm.closeAll()
-		sm.retryTransition(fmt.Sprintf("Cannot connect to MySQL, shutting down query service: %v", err))
 	}()
 }
 
-// StopService shuts down sm. If the shutdown doesn't complete
-// within timeBombDuration, it crashes the process.
-func (sm *stateManager) StopService() {
-	defer close(sm.setTimeBomb())
-
-	log.Info("Stopping TabletServer")
-	sm.SetServingType(sm.Target().TabletType, time.Time{}, StateNotConnected, "service stopped")
-	sm.hcticks.Stop()
-	sm.hs.Close()
-}
-
-// StartRequest validates the current state and target and registers
-// the request (a waitgroup) as started. Every StartRequest must be
-// ended with an EndRequest.
-func (sm *stateManager) StartRequest(ctx context.Context, target *querypb.Target, allowOnShutdown bool) (err error) {
+// SetTopoLastSeenHealthy records the last time the tablet manager
+// could reach the topo. If the master had stopped serving because
+// it could not, it resumes.
+func (sm *stateManager) SetTopoLastSeenHealthy(lastSeen time.Time) {
 	sm.mu.Lock()
-	defer sm.mu.Unlock()
-
-	if sm.state != StateServing || !sm.replHealthy {
-		// This specific error string needs to be returned for vtgate buffering to work.
-		return vterrors.New(vtrpcpb.Code_FAILED_PRECONDITION, "operation not allowed in state NOT_SERVING")
+	if lastSeen.After(sm.topoLastSeen) {
+		sm.topoLastSeen = lastSeen
 	}
-
-	shuttingDown := sm.wantState != StateServing
-	if shuttingDown && !allowOnShutdown {
-		// This specific error string needs to be returned for vtgate buffering to work.
-		return vterrors.New(vtrpcpb.Code_FAILED_PRECONDITION, "operation not allowed in state SHUTTING_DOWN")
+	if !sm.topoIsolated || time.Since(sm.topoLastSeen) > sm.topoIsolationTimeout {
+		sm.mu.Unlock()
+		return
 	}
+	log.Info("TabletServer can reach the topo again")
+	resume, terTimestamp := sm.endTopoIsolationLocked()
+	sm.mu.Unlock()
 
-	if target != nil {
-		switch {
-		case target.Keyspace != sm.target.Keyspace:
-			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid keyspace %v does not match expected %v", target.Keyspace, sm.target.Keyspace)
-		case target.Shard != sm.target.Shard:
-			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid shard %v does not match expected %v", target.Shard, sm.target.Shard)
-		case target.TabletType != sm.target.TabletType:
-			for _, otherType := range sm.alsoAllow {
-				if target.TabletType == otherType {
-					goto ok
-				}
-			}
-			return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "invalid tablet type: %v, want: %v or %v", target.TabletType, sm.target.TabletType, sm.alsoAllow)
-		}
-	} else {
-		if !tabletenv.IsLocalContext(ctx) {
-			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "No target")
+	if resume {
+		if err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, terTimestamp, StateServing, ""); err != nil {
+			log.Errorf("Could not resume serving after topo isolation: %v", err)
 		}
 	}
+}
 
-ok:
-	sm.requests.Add(1)
+// AcknowledgeTopoIsolation lets an operator resume serving after
+// the master stopped serving because it could not reach the topo.
+// The timeout starts over.
+func (sm *stateManager) AcknowledgeTopoIsolation() error {
+	sm.mu.Lock()
+	if !sm.topoIsolated {
+		sm.mu.Unlock()
+		return vterrors.New(vtrpcpb.Code_FAILED_PRECONDITION, "tablet is not isolated from the topo")
+	}
+	log.Info("Topo isolation acknowledged by operator")
+	sm.topoLastSeen = time.Now()
+	resume, terTimestamp := sm.endTopoIsolationLocked()
+	sm.mu.Unlock()
+
+	if resume {
+		return sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, terTimestamp, StateServing, "")
+	}
 	return nil
 }
 
-// EndRequest unregisters the current request (a waitgroup) as done.
-func (sm *stateManager) EndRequest() {
-	sm.requests.Done()
+// endTopoIsolationLocked clears the isolation. It returns true if
+// sm must resume serving as master.
+func (sm *stateManager) endTopoIsolationLocked() (resume bool, terTimestamp time.Time) {
+	resume = sm.resumeServing && sm.wantTabletType == topodatapb.TabletType_MASTER
+	sm.topoIsolated = false
+	sm.resumeServing = false
+	return resume, sm.terTimestamp
 }
 
-// VerifyTarget allows requests to be executed even in non-serving state.
-// Such requests will get terminated without wait on shutdown.
-func (sm *stateManager) VerifyTarget(ctx context.Context, target *querypb.Target) error {
+// applyTopoIsolation overrides a request to serve as master
+// while the master is isolated from the topo. Any other request
+// ends the isolation.
+func (sm *stateManager) applyTopoIsolation(tabletType topodatapb.TabletType, state servingState, reason string) (servingState, string) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
-	if target != nil {
-		switch {
-		case target.Keyspace != sm.target.Keyspace:
-			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid keyspace %v does not match expected %v", target.Keyspace, sm.target.Keyspace)
-		case target.Shard != sm.target.Shard:
-			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid shard %v does not match expected %v", target.Shard, sm.target.Shard)
-		case target.TabletType != sm.target.TabletType:
-			for _, otherType := range sm.alsoAllow {
-				if target.TabletType == otherType {
-					return nil
-				}
-			}
-			return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "invalid tablet type: %v, want: %v or %v", target.TabletType, sm.target.TabletType, sm.alsoAllow)
-		}
-	} else {
-		if !tabletenv.IsLocalContext(ctx) {
-			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "No target")
-		}
-	}
-	return nil
-}
-
-func (sm *stateManager) serveMaster() error {
-	sm.watcher.Close()
 
-	if err := sm.connect(topodatapb.TabletType_MASTER); err != nil {
-		return err
+	if !sm.topoIsolated {
+		return state, reason
 	}
-
-	sm.rt.MakeMaster()
-	sm.tracker.Open()
-	if err := sm.te.AcceptReadWrite(); err != nil {
-		return err
+	if tabletType != topodatapb.TabletType_MASTER {
+		sm.topoIsolated = false
+		sm.resumeServing = false
+		return state, reason
 	}
-	sm.messager.Open()
-	sm.throttler.Open()
-	sm.setState(topodatapb.TabletType_MASTER, StateServing)
-	return nil
+	sm.resumeServing = state == StateServing
+	if state == StateServing {
+		return StateNotServing, topoIsolationReason
+	}
+	return state, reason
 }
 
-func (sm *stateManager) unserveMaster() error {
-	sm.unserveCommon()
-
-	sm.watcher.Close()
-
-	if err := sm.connect(topodatapb.TabletType_MASTER); err != nil {
-		return err
+// checkTopoIsolation is invoked periodically if topoIsolationTimeout
+// is set. It stops serving if the master has not been able to
+// reach the topo for longer than the timeout.
+func (sm *stateManager) checkTopoIsolation() {
+	sm.mu.Lock()
+	if sm.topoIsolated || sm.target.TabletType != topodatapb.TabletType_MASTER || sm.wantTabletType != topodatapb.TabletType_MASTER || sm.wantState != StateServing {
+		sm.mu.Unlock()
+		return
+	}
+	elapsed := time.Since(sm.topoLastSeen)
+	if elapsed <= sm.topoIsolationTimeout {
+		sm.mu.Unlock()
+		return
 	}
+	sm.topoIsolated = true
+	terTimestamp := sm.terTimestamp
+	sm.mu.Unlock()
 
-	sm.rt.MakeMaster()
-	sm.setState(topodatapb.TabletType_MASTER, StateNotServing)
-	return nil
+	sm.topoIsolations.Add(1)
+	log.Errorf("TabletServer has not been able to reach the topo for %v, it will stop serving until it can or the isolation is acknowledged", elapsed.Round(time.Second))
+	// The request is overridden by applyTopoIsolation.
+	if err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, terTimestamp, StateServing, ""); err != nil {
+		log.Errorf("Could not stop serving after topo isolation: %v", err)
+	}
 }
 
-func (sm *stateManager) serveNonMaster(wantTabletType topodatapb.TabletType) error {
-	sm.throttler.Close()
-	sm.messager.Close()
-	sm.tracker.Close()
-	sm.se.MakeNonMaster()
+// topoIsolationRemaining returns the time left before the master
+// stops serving because it cannot reach the topo. It returns 0 if
+// the check does not apply.
+func (sm *stateManager) topoIsolationRemaining() time.Duration {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.topoIsolationRemainingLocked()
+}
 
-	if err := sm.connect(wantTabletType); err != nil {
-		return err
+func (sm *stateManager) topoIsolationRemainingLocked() time.Duration {
+	if sm.topoIsolationTimeout == 0 || sm.topoIsolated || sm.target.TabletType != topodatapb.TabletType_MASTER {
+		return 0
 	}
-
-	if err := sm.te.AcceptReadOnly(); err != nil {
-		return err
+	if remaining := sm.topoIsolationTimeout - time.Since(sm.topoLastSeen); remaining > 0 {
+		return remaining
 	}
-	sm.rt.MakeNonMaster()
-	sm.watcher.Open()
-	sm.setState(wantTabletType, StateServing)
-	return nil
+	return 0
 }
 
-func (sm *stateManager) unserveNonMaster(wantTabletType topodatapb.TabletType) error {
-	sm.unserveCommon()
-
-	sm.se.MakeNonMaster()
-
-	if err := sm.connect(wantTabletType); err != nil {
-		return err
+// recoverTransition must be deferred by execTransition. It recovers
+// a panic during the transition and returns it as an error.
+func (sm *stateManager) recoverTransition(err *error) {
+	if sm.crashOnPanic {
+		return
+	}
+	if x := recover(); x != nil {
+		*err = sm.handlePanic(x, true)
 	}
-
-	sm.rt.MakeNonMaster()
-	sm.watcher.Open()
-	sm.setState(wantTabletType, StateNotServing)
-	return nil
 }
 
-func (sm *stateManager) connect(tabletType topodatapb.TabletType) error {
-	if err := sm.se.EnsureConnectionAndDB(tabletType); err != nil {
-		return err
-	}
-	if err := sm.se.Open(); err != nil {
-		return err
+// recoverPanic must be deferred by the other goroutines
+// owned by the state manager.
+func (sm *stateManager) recoverPanic() {
+	if sm.crashOnPanic {
+		return
 	}
-	sm.vstreamer.Open()
-	if err := sm.qe.Open(); err != nil {
-		return err
+	if x := recover(); x != nil {
+		sm.handlePanic(x, false)
 	}
-	return sm.txThrottler.Open()
 }
 
-func (sm *stateManager) unserveCommon() {
-	sm.throttler.Close()
-	sm.messager.Close()
-	sm.te.Close()
-	sm.qe.StopServing()
-	sm.tracker.Close()
-	sm.requests.Wait()
+// recoverComponentPanic must be deferred by the opens and closes of
+// the subcomponents that recover their own panics. It returns the
+// panic of op as an error.
+func (sm *stateManager) recoverComponentPanic(op string, err *error) {
+	if x := recover(); x != nil {
+		*err = fmt.Errorf("%s panicked: %v", op, x)
+		log.Errorf("%v\n%s", *err, tb.Stack(4))
+		sm.componentPanics.Add(op, 1)
+	}
 }
 
-func (sm *stateManager) closeAll() {
-	defer close(sm.setTimeBomb())
+// closeCall is timeCall for the closes of the subcomponents. Unless
+// crashOnPanic is set, a panic is recovered, for the remaining
+// subcomponents to be closed too.
+func (sm *stateManager) closeCall(name string, f func()) {
+	if sm.crashOnPanic {
+		sm.timeCall(name, f)
+		return
+	}
+	_ = sm.timeOp(name, func() (err error) {
+		defer func() {
+			if err != nil {
+				sm.mu.Lock()
+				sm.closePanicked = true
+				sm.mu.Unlock()
+			}
+		}()
+		defer sm.recoverComponentPanic(name, &err)
+		f()
+		return nil
+	})
+}
+
+// handlePanic records a recovered panic, abandons the pending
+// transition and disconnects from mysql. The state manager remains
+// NotConnected until a new state is requested. inTransition must be
+// set if the caller holds the transitioning semaphore.
+func (sm *stateManager) handlePanic(x interface{}, inTransition bool) error {
+	err := vterrors.Errorf(vtrpcpb.Code_INTERNAL, "transition panicked: %v", x)
+	log.Errorf("%v\n%s", err, tb.Stack(5))
+	sm.transitionPanics.Add(1)
+	sm.hs.recordEvent(sm.Target().TabletType, err)
+
+	if !inTransition {
+		sm.transitioning.Acquire()
+		defer sm.transitioning.Release()
+	}
+	sm.mu.Lock()
+	sm.transitionErr = err
+	sm.transitionStart = time.Time{}
+	sm.retrying = false
+	// The retries that an asynchronous request
+	// may be waiting for are abandoned.
+	if h := sm.asyncCurrent; h != nil && h.waitRetries {
+		sm.resolveAsyncLocked(h, err)
+	}
+	sm.mu.Unlock()
+
+	defer func() {
+		// The components may be in a state where they panic
+		// again on Close. Make sure we end up NotConnected.
+		if x := recover(); x != nil {
+			log.Errorf("Panic while closing after a panic: %v\n%s", x, tb.Stack(4))
+			sm.mu.Lock()
+			sm.notConnected = NotConnectedByPanic
+			sm.mu.Unlock()
+			sm.setState(topodatapb.TabletType_UNKNOWN, StateNotConnected)
+		}
+	}()
+	sm.closeAll(NotConnectedByPanic)
+	sm.updateBroadcastInterval()
+	return err
+}
+
+// timeOp runs a subcomponent operation of a transition
+// and records its wall time.
+func (sm *stateManager) timeOp(name string, f func() error) (err error) {
+	if sm.transitionCtx != nil {
+		span, _ := sm.newSpan(sm.transitionCtx, "stateManager."+name)
+		defer func() {
+			if err != nil {
+				span.Annotate("error", err.Error())
+			}
+			span.Finish()
+		}()
+	}
+
+	sm.enterShutdownPhase(name, "")
+	start := time.Now()
+	err = f()
+	op := transitionOp{Name: name, Wall: time.Since(start)}
+
+	sm.opWallTimings.Add(name, op.Wall)
+	sm.mu.Lock()
+	sm.transitionOps = append(sm.transitionOps, op)
+	sm.recordSubcomponentLocked(name, err)
+	sm.mu.Unlock()
+	return err
+}
+
+// timeCall is timeOp for operations that can't fail.
+func (sm *stateManager) timeCall(name string, f func()) {
+	_ = sm.timeOp(name, func() error {
+		f()
+		return nil
+	})
+}
+
+// traceTransition makes ctx, which carries span, the parent of the
+// spans of the operations until the returned function is called.
+// That function records the error, if any, and finishes span. The
+// caller must hold transitioning.
+func (sm *stateManager) traceTransition(ctx context.Context, span trace.Span, err *error) func() {
+	sm.transitionCtx = ctx
+	return func() {
+		sm.transitionCtx = nil
+		if *err != nil {
+			span.Annotate("error", (*err).Error())
+		}
+		span.Finish()
+	}
+}
+
+// logSlowOps logs the slow operations of a transition.
+func logSlowOps(ops []transitionOp) {
+	for _, op := range ops {
+		if op.Wall >= transitionOpLogThreshold {
+			log.Warningf("Transition operation %s took %v", op.Name, op.Wall)
+		}
+	}
+}
+
+// CheckMySQL verifies that we can connect to mysql.
+// If it fails, then we shutdown the service and initiate
+// the retry loop. If a master can read from mysql but
+// can't write, it keeps serving reads only. The failures
+// that aren't connection errors may be ignored, see
+// suppressCheckMySQL.
+func (sm *stateManager) CheckMySQL() {
+	if !sm.checkMySQLThrottler.TryAcquire() {
+		return
+	}
+	go func() {
+		defer sm.recoverPanic()
+		defer func() {
+			time.Sleep(1 * time.Second)
+			sm.checkMySQLThrottler.Release()
+		}()
+
+		checkWrites := sm.Target().TabletType == topodatapb.TabletType_MASTER
+		err := sm.qe.IsMySQLReachable(context.TODO(), checkWrites)
+		if err == nil || sm.suppressCheckMySQL(err) {
+			return
+		}
+		// There's no incoming request: the span is a root span.
+		span, ctx := sm.newSpan(context.Background(), "stateManager.CheckMySQL")
+		span.Annotate("mysql_error", err.Error())
+		sm.logDecision(log.Warningf, sm.decision(decisionCheckMySQL, err), "")
+		sm.handleMySQLError(ctx, err)
+		span.Finish()
+
+		entry := &TransitionAuditEntry{Event: auditCheckMySQL, MySQLError: err.Error()}
+		if writeErr, ok := err.(*mysqlWriteError); ok {
+			entry.MySQLError, entry.WriteError = writeErr.err.Error(), true
+		}
+		sm.audit(entry, nil)
+	}()
+}
+
+// handleMySQLError reacts to an error found by CheckMySQL. If mysql
+// fails writes, the master serves reads only. Otherwise, the query
+// service is shut down until mysql can be reached again.
+func (sm *stateManager) handleMySQLError(ctx context.Context, err error) {
+	if _, ok := err.(*mysqlWriteError); ok {
+		sm.serveReadOnly(ctx, err)
+		return
+	}
+
+	if !sm.transitioning.TryAcquire() {
+		// If we're already transitioning, don't interfere.
+		return
+	}
+	defer sm.transitioning.Release()
+	sm.transitionCtx = ctx
+	defer func() { sm.transitionCtx = nil }()
+
+	sm.closeAll(NotConnectedByMySQLFailure)
+	sm.retryTransition("mysql unreachable", err, fmt.Sprintf("Cannot connect to MySQL, shutting down query service: %v", err))
+}
+
+// StopService shuts down sm. If the shutdown doesn't complete
+// within timeBombDuration, it crashes the process. Its phases
+// are logged and reported in the status, see shutdownPhase.
+func (sm *stateManager) StopService() {
+	defer close(sm.setTimeBomb())
+
+	log.Info("Stopping TabletServer")
+	sm.mu.Lock()
+	if sm.export != nil {
+		sm.releaseExportLocked(sm.export, "tablet server stopped")
+	}
+	sm.mu.Unlock()
+	sm.clearHealthAnnotations()
+	sm.startShutdownPhases()
+	defer sm.endShutdownPhases()
+	_, err := sm.setServingType(context.Background(), sm.Target().TabletType, time.Time{}, StateNotConnected, "service stopped", NotConnectedShuttingDown, nil)
+	sm.audit(&TransitionAuditEntry{Event: auditStopService}, err)
+	sm.enterShutdownPhase("timers.Stop", "")
+	sm.hcticks.Stop()
+	sm.watchdog.Stop()
+	sm.topoTicks.Stop()
+	sm.mysqlProbeTicks.Stop()
+	sm.readOnlyTicks.Stop()
+	sm.promotionTicks.Stop()
+	sm.roleStatusTicks.Stop()
+	sm.enterShutdownPhase("hs.Close", "")
+	sm.hs.Close()
+}
+
+// StartRequest validates the current state and target and registers
+// the request (a waitgroup) as started. Every StartRequest must be
+// ended with an EndRequest.
+// vtgate decides how to retry from the code of the error, so the
+// codes must stay stable:
+//   - FAILED_PRECONDITION: the tablet doesn't serve the request: it's
+//     not serving, it's shutting down or it's of another type. It can
+//     be retried right away on another tablet.
+//   - UNAVAILABLE: the tablet is not initialized yet, is transitioning
+//     to serving, or its replication is unhealthy. It can be retried, possibly on this
+//     tablet after a backoff. Unless retryableRequestErrors is set,
+//     these are FAILED_PRECONDITION with the message of a tablet that
+//     is not serving instead: the vtgates that predate UNAVAILABLE
+//     only buffer those.
+//   - INVALID_ARGUMENT: the request is for another keyspace or shard,
+//     or has no target. It shouldn't be retried.
+// A degraded replica may also shed the request with UNAVAILABLE, see
+// refreshShedLocked.
+// The requests allowed on shutdown end transactions, or act on
+// transactions that are already open: rejecting them would leave the
+// transactions dangling until they time out. They're only rejected if
+// the tablet doesn't serve at all, in which case it has no
+// transactions, or if they target another tablet. They're admitted
+// while the tablet shuts down, that is when it's still serving but
+// doesn't want to anymore, and while its replication or its
+// subcomponents are unhealthy. They're never shed.
+// A tablet in lameduck still accepts all the requests: lameduck only
+// tells the vtgates to stop sending new ones.
+// While a snapshot export takes its snapshot, the requests are
+// rejected with UNAVAILABLE, except the internal ones and those
+// allowed on shutdown. See StartSnapshotExport.
+func (sm *stateManager) StartRequest(ctx context.Context, target *querypb.Target, allowOnShutdown bool) (err error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	switch {
+	case !sm.initialized:
+		// This specific error string needs to be returned for vtgate buffering to work.
+		return sm.requestErrorLocked(sm.retryableCodeLocked(), "operation not allowed in state NOT_SERVING: tablet server not initialized")
+	case !sm.state.serving() && sm.wantState.serving():
+		// This specific error string needs to be returned for vtgate buffering to work.
+		return sm.requestErrorLocked(sm.retryableCodeLocked(), "operation not allowed in state NOT_SERVING")
+	case !sm.state.serving():
+		// This specific error string needs to be returned for vtgate buffering to work.
+		return sm.requestErrorLocked(vtrpcpb.Code_FAILED_PRECONDITION, "operation not allowed in state NOT_SERVING")
+	case allowOnShutdown:
+		// The checks below don't apply to them.
+	case !sm.replHealthy && !sm.retryableRequestErrors:
+		// This specific error string needs to be returned for vtgate buffering to work.
+		return sm.requestErrorLocked(vtrpcpb.Code_FAILED_PRECONDITION, "operation not allowed in state NOT_SERVING: replication is unhealthy: %v", sm.replUnhealthyCauseLocked())
+	case !sm.replHealthy:
+		return sm.requestErrorLocked(vtrpcpb.Code_UNAVAILABLE, "operation not allowed: replication is unhealthy: %v", sm.replUnhealthyCauseLocked())
+	case !sm.subcomponentsHealthyLocked():
+		return sm.requestErrorLocked(vtrpcpb.Code_UNAVAILABLE, "operation not allowed: %v", sm.subcomponentHealthErrLocked())
+	case !sm.wantState.serving():
+		// This specific error string needs to be returned for vtgate buffering to work.
+		return sm.requestErrorLocked(vtrpcpb.Code_FAILED_PRECONDITION, "operation not allowed in state SHUTTING_DOWN")
+	}
+
+	if err := sm.verifyTargetLocked(ctx, target); err != nil {
+		return err
+	}
+	if !allowOnShutdown && !tabletenv.IsLocalContext(ctx) && sm.exportPausedLocked() {
+		return sm.requestErrorLocked(vtrpcpb.Code_UNAVAILABLE, "operation not allowed: snapshot export %s is taking its snapshot", sm.export.Reason)
+	}
+	if !allowOnShutdown && sm.shedLocked() {
+		return sm.requestErrorLocked(vtrpcpb.Code_UNAVAILABLE, "request shed: replication lag %v is degraded, shedding %d%% of the requests", sm.replLag, shedPercent(sm.shedFraction))
+	}
+	sm.requests.Add(1)
+	sm.requestsInFlight.Add(1)
+	return nil
+}
+
+// CheckReplicationLag returns a retryable error if the replication
+// lag exceeds the max requested by options. It compares against the
+// lag computed by the last refreshReplHealthLocked, which is also
+// the one that decides if the tablet is healthy, so it doesn't cost
+// a round trip to mysql. Masters always pass.
+func (sm *stateManager) CheckReplicationLag(options *querypb.ExecuteOptions) error {
+	maxLag := time.Duration(options.GetMaxReplicationLagMs()) * time.Millisecond
+	if maxLag == 0 {
+		return nil
+	}
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.target.TabletType == topodatapb.TabletType_MASTER || sm.replLag <= maxLag {
+		return nil
+	}
+	sm.replLagRejections.Add(sm.target.Keyspace, 1)
+	return vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "replication lag %v exceeds the max of %v requested", sm.replLag, maxLag)
+}
+
+// interceptorState returns the state passed to the request
+// interceptors.
+func (sm *stateManager) interceptorState() interceptor.State {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return interceptor.State{
+		TabletType:     sm.target.TabletType,
+		Serving:        sm.isServingLocked(),
+		Lameduck:       sm.lameduck,
+		ReplicationLag: sm.replLag,
+	}
+}
+
+// requestErrorLocked returns an error for a rejected request. The
+// message is followed by the state of sm. It's kept intact though,
+// because vtgate buffering looks for it.
+func (sm *stateManager) requestErrorLocked(code vtrpcpb.Code, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	state := fmt.Sprintf("tablet type: %v, state: %v, want: %v", sm.target.TabletType, sm.state, sm.wantState)
+	if sm.reason != "" {
+		state += fmt.Sprintf(", reason: %s", sm.reason)
+	}
+	return vterrors.Errorf(code, "%s (%s)", msg, state)
+}
+
+// retryableCodeLocked returns the code of the rejections that can be
+// retried on this tablet, see StartRequest.
+func (sm *stateManager) retryableCodeLocked() vtrpcpb.Code {
+	if sm.retryableRequestErrors {
+		return vtrpcpb.Code_UNAVAILABLE
+	}
+	return vtrpcpb.Code_FAILED_PRECONDITION
+}
+
+// replUnhealthyCauseLocked returns why the replication is unhealthy.
+func (sm *stateManager) replUnhealthyCauseLocked() string {
+	if sm.replErr != nil {
+		return sm.replErr.Error()
+	}
+	return fmt.Sprintf("lag %v exceeds the unhealthy threshold", sm.replLag)
+}
+
+// EndRequest unregisters the current request (a waitgroup) as done.
+func (sm *stateManager) EndRequest() {
+	sm.requestsInFlight.Add(-1)
+	sm.requests.Done()
+}
+
+// VerifyTarget allows requests to be executed even in non-serving state.
+// Such requests will get terminated without wait on shutdown.
+func (sm *stateManager) VerifyTarget(ctx context.Context, target *querypb.Target) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.verifyTargetLocked(ctx, target)
+}
+
+// verifyTargetLocked checks that target is the target of sm, possibly
+// through a keyspace or shard alias, or of a type it also allows. A
+// nil target is only accepted from a local context.
+func (sm *stateManager) verifyTargetLocked(ctx context.Context, target *querypb.Target) error {
+	if target == nil {
+		if !tabletenv.IsLocalContext(ctx) {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "No target")
+		}
+		return nil
+	}
+	if target.Keyspace != sm.target.Keyspace && !sm.aliasLocked("keyspace", target.Keyspace, sm.target.Keyspace, sm.live.KeyspaceAlias) {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid keyspace %v does not match expected %v", target.Keyspace, sm.target.Keyspace)
+	}
+	if target.Shard != sm.target.Shard && !sm.aliasLocked("shard", target.Shard, sm.target.Shard, sm.live.ShardAlias) {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid shard %v does not match expected %v", target.Shard, sm.target.Shard)
+	}
+	if target.TabletType != sm.target.TabletType {
+		alsoAllow := sm.alsoAllowLocked()
+		for _, otherType := range alsoAllow {
+			if target.TabletType == otherType {
+				return nil
+			}
+		}
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "invalid tablet type: %v, want: %v or %v", target.TabletType, sm.target.TabletType, alsoAllow)
+	}
+	return nil
+}
+
+// aliasLocked returns true if name is an alias of want according
+// to alias, and counts the hit.
+func (sm *stateManager) aliasLocked(kind, name, want string, alias func(string) (string, bool)) bool {
+	if newName, ok := alias(name); !ok || newName != want {
+		return false
+	}
+	sm.targetAliasHits.Add([]string{kind, name}, 1)
+	return true
+}
+
+func (sm *stateManager) serveMaster(ctx context.Context) error {
+	sm.timeCall("watcher.Close", sm.watcher.Close)
+
+	steps := sm.connectSteps(topodatapb.TabletType_MASTER)
+	// The steps that write wait for mysql to be writable.
+	writable := "se.EnsureConnectionAndDB"
+	if !sm.skipReadOnlyCheck {
+		steps = append(steps, transitionStep{
+			name:  "se.WaitWritable",
+			after: []string{"se.EnsureConnectionAndDB"},
+			run:   sm.waitForWritable,
+		})
+		writable = "se.WaitWritable"
+	}
+	steps = append(steps, transitionStep{
+		name:    "se.RegisterNotifier",
+		after:   []string{"se.Open"},
+		run:     func(context.Context) error { sm.se.RegisterNotifier(hsNotifierName, sm.hs.schemaChanged); return nil },
+		untimed: true,
+	}, transitionStep{
+		name:  "rt.MakeMaster",
+		after: []string{writable},
+		run:   func(context.Context) error { sm.rt.MakeMaster(); return nil },
+	}, transitionStep{
+		// The table of the mysql write probe.
+		name:  "qe.CreateSidecarTables",
+		after: []string{writable},
+		run:   sm.qe.CreateSidecarTables,
+	})
+	for _, step := range sm.servingSteps() {
+		step.after = append([]string{writable}, step.after...)
+		steps = append(steps, step)
+	}
+	if err := sm.runSteps(ctx, steps); err != nil {
+		return err
+	}
+	sm.warmPlans()
+	sm.setState(topodatapb.TabletType_MASTER, StateServing)
+	return nil
+}
+
+func (sm *stateManager) unserveMaster(ctx context.Context) error {
+	sm.unserveCommon()
+
+	sm.timeCall("watcher.Close", sm.watcher.Close)
+
+	if err := sm.connect(ctx, topodatapb.TabletType_MASTER); err != nil {
+		return err
+	}
+
+	sm.timeCall("rt.MakeMaster", sm.rt.MakeMaster)
+	sm.setState(topodatapb.TabletType_MASTER, StateNotServing)
+	return nil
+}
+
+func (sm *stateManager) serveNonMaster(ctx context.Context, wantTabletType topodatapb.TabletType) error {
+	sm.pauseHeartbeatWrites()
+	sm.closeServing(true)
+	sm.timeCall("se.MakeNonMaster", sm.se.MakeNonMaster)
+
+	if err := sm.connect(ctx, wantTabletType); err != nil {
+		return err
+	}
+	sm.se.RegisterNotifier(hsNotifierName, sm.hs.schemaChanged)
+
+	if err := sm.timeOp("te.AcceptReadOnly", sm.acceptReadOnly); err != nil {
+		return err
+	}
+	sm.timeCall("rt.MakeNonMaster", sm.rt.MakeNonMaster)
+	sm.timeCall("watcher.Open", sm.watcher.Open)
+	if sm.throttleOnReplicas {
+		if err := sm.timeOp("throttler.Open", sm.throttler.Open); err != nil {
+			return err
+		}
+	}
+	sm.setState(wantTabletType, StateServing)
+	return nil
+}
+
+// acceptReadOnly switches the tx engine to read-only. If this is
+// a master demotion, the tx engine may drain the open transactions
+// first. The progress is broadcast while it does.
+func (sm *stateManager) acceptReadOnly() error {
+	if sm.Target().TabletType != topodatapb.TabletType_MASTER {
+		return sm.te.AcceptReadOnly()
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer sm.recoverPanic()
+		tkr := time.NewTicker(drainBroadcastInterval)
+		defer tkr.Stop()
+		start := time.Now()
+		reported := false
+		for {
+			select {
+			case <-done:
+				return
+			case <-tkr.C:
+				if _, ok := sm.te.Draining(); ok {
+					if !reported && time.Since(start) > slowDrainThreshold {
+						reported = true
+						sm.logDrainBlockers()
+					}
+					if sm.queryKillGracePeriod != 0 && time.Since(start) > sm.queryKillGracePeriod {
+						// Queries can hold up the transactions being drained.
+						sm.qe.KillActiveQueries(sm.queryKillGracePeriod, sm.queryKillReason())
+					}
+					sm.Broadcast()
+				}
+			}
+		}
+	}()
+	return sm.te.AcceptReadOnly()
+}
+
+// logDrainBlockers logs the transactions and the reserved
+// connections that are holding up a drain.
+func (sm *stateManager) logDrainBlockers() {
+	txs := sm.te.OpenTransactions()
+	reserved := sm.te.ReservedConnections()
+	log.Warningf("Transaction drain is taking longer than %v, %d transactions and %d reserved connections are still open", slowDrainThreshold, len(txs), len(reserved))
+	if len(txs) > slowDrainReportCount {
+		txs = txs[:slowDrainReportCount]
+	}
+	for _, tx := range txs {
+		log.Warningf("Open transaction %d: started %v ago as %s by %q, last query: %s", tx.TransactionID, time.Since(tx.StartTime), tx.TabletType, tx.EffectiveCaller, tx.LastQuery)
+	}
+	if len(reserved) > slowDrainReportCount {
+		reserved = reserved[:slowDrainReportCount]
+	}
+	for _, rc := range reserved {
+		log.Warningf("Reserved connection %d: reserved %v ago by %q (immediate caller %q), in a transaction: %v", rc.ConnID, time.Since(rc.StartTime), rc.EffectiveCaller, rc.ImmediateCaller, rc.InTransaction)
+	}
+}
+
+func (sm *stateManager) unserveNonMaster(ctx context.Context, wantTabletType topodatapb.TabletType) error {
+	sm.pauseHeartbeatWrites()
+	sm.unserveCommon()
+
+	sm.timeCall("se.MakeNonMaster", sm.se.MakeNonMaster)
+
+	if err := sm.connect(ctx, wantTabletType); err != nil {
+		return err
+	}
+
+	sm.timeCall("rt.MakeNonMaster", sm.rt.MakeNonMaster)
+	sm.timeCall("watcher.Open", sm.watcher.Open)
+	sm.setState(wantTabletType, StateNotServing)
+	return nil
+}
+
+// backupReason is the reason why a BACKUP tablet
+// that kept its vstreamer open is not serving.
+const backupReason = "backup in progress"
+
+// unserveBackup closes everything but se and vstreamer, for the
+// vstreams to keep running during the backup. The queries are
+// rejected by StartRequest, like those of any tablet that's not
+// serving. se and vstreamer are only opened if the tablet wasn't
+// connected.
+func (sm *stateManager) unserveBackup(ctx context.Context) error {
+	connected := sm.State() != StateNotConnected
+	sm.pauseHeartbeatWrites()
+	sm.unserveCommon()
+	sm.closeCall("txThrottler.Close", sm.txThrottler.Close)
+	sm.closeCall("qe.Close", sm.qe.Close)
+	sm.closeCall("watcher.Close", sm.watcher.Close)
+	sm.closeCall("rt.Close", sm.rt.Close)
+	sm.timeCall("se.MakeNonMaster", sm.se.MakeNonMaster)
+
+	if !connected {
+		var steps []transitionStep
+		for _, step := range sm.connectSteps(topodatapb.TabletType_BACKUP) {
+			switch step.name {
+			case "se.EnsureConnectionAndDB", "se.Open", "vstreamer.Open":
+				steps = append(steps, step)
+			}
+		}
+		if err := sm.runSteps(ctx, steps); err != nil {
+			return err
+		}
+		sm.se.RegisterNotifier(hsNotifierName, sm.hs.schemaChanged)
+	}
+	sm.setState(topodatapb.TabletType_BACKUP, StateNotServing)
+	return nil
+}
+
+// warmPlans builds the plans of the queries that were the most
+// executed before a master starts serving, to avoid the latency
+// spike of a cold plan cache. It's bounded by planWarmupTimeout,
+// and its failures are logged without failing the transition.
+func (sm *stateManager) warmPlans() {
+	if sm.planWarmupTimeout == 0 {
+		return
+	}
+	sm.timeCall("qe.WarmPlans", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), sm.planWarmupTimeout)
+		defer cancel()
+		built, err := sm.qe.WarmPlans(ctx)
+		if err != nil {
+			log.Warningf("Plan warmup stopped after %d plans: %v", built, err)
+			return
+		}
+		log.Infof("Plan warmup built %d plans", built)
+	})
+}
+
+// pauseHeartbeatWrites stops the heartbeat writes of a master that's
+// being demoted, before the tx engine is drained or closed. rt only
+// becomes non-master at the end of the transition, and a failed
+// transition may be retried many times before it gets there.
+func (sm *stateManager) pauseHeartbeatWrites() {
+	if sm.Target().TabletType == topodatapb.TabletType_MASTER {
+		sm.timeCall("rt.PauseWrites", sm.rt.PauseWrites)
+	}
+}
+
+// connect opens the components that are common to all tablet types.
+// If a master doesn't find its database, it creates it, unless it
+// was already created for the same intent by a previous attempt.
+func (sm *stateManager) connect(ctx context.Context, tabletType topodatapb.TabletType) error {
+	return sm.runSteps(ctx, sm.connectSteps(tabletType))
+}
+
+// connectSteps returns the steps that connect to mysql and open the
+// subcomponents that every serving state needs. The schema engine
+// must be open before the components that use the schema.
+func (sm *stateManager) connectSteps(tabletType topodatapb.TabletType) []transitionStep {
+	sm.mu.Lock()
+	intent := sm.intentLocked()
+	createDB := tabletType == topodatapb.TabletType_MASTER && sm.dbCreatedFor != intent
+	sm.mu.Unlock()
+	return []transitionStep{{
+		name: "se.EnsureConnectionAndDB",
+		run: func(ctx context.Context) error {
+			created, err := sm.se.EnsureConnectionAndDB(ctx, createDB)
+			if created {
+				sm.mu.Lock()
+				sm.dbCreatedFor = intent
+				sm.mu.Unlock()
+			}
+			if err != nil {
+				return err
+			}
+			return sm.checkServerIdentity()
+		},
+	}, {
+		name:  "se.Open",
+		after: []string{"se.EnsureConnectionAndDB"},
+		run:   sm.se.Open,
+	}, {
+		name:  "vstreamer.Open",
+		after: []string{"se.Open"},
+		run:   func(context.Context) error { sm.vstreamer.Open(); return nil },
+	}, {
+		name:  "qe.Open",
+		after: []string{"se.Open"},
+		run:   sm.qe.Open,
+	}, {
+		name:  "txThrottler.Open",
+		after: []string{"se.EnsureConnectionAndDB"},
+		run:   func(context.Context) error { return sm.txThrottler.Open() },
+	}}
+}
+
+func (sm *stateManager) unserveCommon() {
+	sm.closeServing(false)
+	sm.timeCall("qe.StopServing", sm.qe.StopServing)
+	sm.timeCall("requests.Wait", sm.waitForRequests)
+}
+
+// openServing opens the serving components. The prerequisites
+// of each component are created before it's opened.
+func (sm *stateManager) openServing(ctx context.Context) error {
+	return sm.runSteps(ctx, sm.servingSteps())
+}
+
+// servingSteps returns the steps that open the serving components.
+// Their dependencies on the steps of connectSteps are only honored
+// if they're run along with them.
+func (sm *stateManager) servingSteps() []transitionStep {
+	var steps []transitionStep
+	for _, c := range sm.servingOrder {
+		after := c.after
+		if prepare := c.prepare; prepare != nil {
+			steps = append(steps, transitionStep{
+				name:  c.name + ".Prepare",
+				after: []string{"se.EnsureConnectionAndDB"},
+				run:   func(context.Context) error { return prepare() },
+			})
+			after = append([]string{c.name + ".Prepare"}, after...)
+		}
+		steps = append(steps, transitionStep{
+			name:  c.name + ".Open",
+			after: after,
+			run:   c.open,
+		})
+	}
+	return steps
+}
+
+// closeServing closes the serving components in reverse order.
+// If demoting, the read-only capable components are left open.
+func (sm *stateManager) closeServing(demoting bool) {
+	for i := len(sm.servingOrder) - 1; i >= 0; i-- {
+		c := sm.servingOrder[i]
+		if demoting && c.readOnly {
+			continue
+		}
+		sm.closeCall(c.name+".Close", c.close)
+	}
+}
+
+// queryKillReason returns the reason reported to the clients
+// of the queries killed by a transition.
+func (sm *stateManager) queryKillReason() string {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return fmt.Sprintf("tablet transitioning to %v", sm.wantTabletType)
+}
+
+// waitForRequests waits for the in-flight requests to complete.
+// If a master is being demoted, the queries that are still running
+// after the query kill grace period are killed.
+func (sm *stateManager) waitForRequests() {
+	sm.mu.Lock()
+	demoting := sm.target.TabletType == topodatapb.TabletType_MASTER &&
+		sm.wantTabletType != topodatapb.TabletType_MASTER &&
+		sm.wantState != StateNotConnected
+	sm.mu.Unlock()
+	if !demoting || sm.queryKillGracePeriod == 0 {
+		sm.requests.Wait()
+		return
+	}
+
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		defer sm.recoverPanic()
+		tmr := time.NewTimer(sm.queryKillGracePeriod)
+		defer tmr.Stop()
+		select {
+		case <-done:
+			return
+		case <-tmr.C:
+		}
+		// Keep killing the queries that reach the grace
+		// period until all requests are done.
+		tkr := time.NewTicker(drainBroadcastInterval)
+		defer tkr.Stop()
+		for {
+			sm.qe.KillActiveQueries(sm.queryKillGracePeriod, sm.queryKillReason())
+			select {
+			case <-done:
+				return
+			case <-tkr.C:
+			}
+		}
+	}()
+	sm.requests.Wait()
+	close(done)
+	<-exited
+}
+
+// closeAll closes all the subcomponents. A subcomponent that panics
+// while closing doesn't prevent the others from being closed, but
+// the shutdown is reported as dirty.
+func (sm *stateManager) closeAll(ncs notConnectedState) {
+	defer close(sm.setTimeBomb())
+
+	sm.mu.Lock()
+	sm.closePanicked = false
+	sm.mu.Unlock()
 
 	sm.unserveCommon()
-	sm.txThrottler.Close()
-	sm.qe.Close()
-	sm.watcher.Close()
-	sm.vstreamer.Close()
-	sm.rt.Close()
-	sm.se.Close()
+	sm.closeCall("txThrottler.Close", sm.txThrottler.Close)
+	sm.closeCall("qe.Close", sm.qe.Close)
+	sm.closeCall("watcher.Close", sm.watcher.Close)
+	if n := sm.vstreamer.ActiveStreams(); n != 0 {
+		log.Infof("Terminating %d active vstreams", n)
+	}
+	sm.closeCall("vstreamer.Close", sm.vstreamer.Close)
+	sm.closeCall("rt.Close", sm.rt.Close)
+	sm.se.UnregisterNotifier(hsNotifierName)
+	sm.closeCall("se.Close", sm.se.Close)
+	sm.mu.Lock()
+	sm.notConnected = ncs
+	sm.dirtyShutdown = sm.closePanicked
+	if sm.dirtyShutdown {
+		log.Warningf("Subcomponents panicked while closing, the shutdown is dirty")
+	}
+	sm.mu.Unlock()
 	sm.setState(topodatapb.TabletType_UNKNOWN, StateNotConnected)
 }
 
@@ -504,17 +2178,33 @@ func (sm *stateManager) setTimeBomb() chan struct{} {
 		if sm.timebombDuration == 0 {
 			return
 		}
-		tmr := time.NewTimer(sm.timebombDuration)
+		tmr := sm.clock.NewTimer(sm.timebombDuration)
 		defer tmr.Stop()
 		select {
-		case <-tmr.C:
-			log.Fatal("Shutdown took too long. Crashing")
+		case <-tmr.C():
+			timebombCrash()
 		case <-done:
 		}
 	}()
 	return done
 }
 
+// updateBroadcastInterval switches hcticks to the interval of the
+// current state once a transition completed. Its wait only restarts
+// if the interval changed: a Timer reuses its goroutine across the
+// changes. It must not be called with sm.mu held, or by hcticks.
+func (sm *stateManager) updateBroadcastInterval() {
+	sm.mu.Lock()
+	interval := sm.notServingInterval
+	if sm.state.serving() {
+		interval = sm.servingInterval
+	}
+	sm.mu.Unlock()
+	if interval != sm.hcticks.Interval() {
+		sm.hcticks.SetInterval(interval)
+	}
+}
+
 // setState changes the state and logs the event.
 func (sm *stateManager) setState(tabletType topodatapb.TabletType, state servingState) {
 	sm.mu.Lock()
@@ -523,105 +2213,345 @@ func (sm *stateManager) setState(tabletType topodatapb.TabletType, state serving
 	if tabletType == topodatapb.TabletType_UNKNOWN {
 		tabletType = sm.wantTabletType
 	}
-	log.Infof("TabletServer transition: %v -> %v", sm.stateStringLocked(sm.target.TabletType, sm.state), sm.stateStringLocked(tabletType, state))
+	// The operations are attached to the next health history entry.
+	// The same transition is logged as a decision.
+	sm.hs.setTransitionOps(sm.transitionOps)
+	d := sm.decisionLocked(decisionTransitionEnd, nil)
+	d.ToState, d.TabletType, d.Reason = state.String(), tabletType.String(), sm.reason
+	d.DurationMs = durationMs(sm.transitionStart)
+	sm.logDecision(log.Infof, d, "TabletServer transition: %v -> %v", sm.stateStringLocked(sm.target.TabletType, sm.state), sm.stateStringLocked(tabletType, state))
+	alsoAllowUntil := sm.alsoAllowUntil
 	sm.handleGracePeriod(tabletType)
+	if tabletType == topodatapb.TabletType_MASTER && sm.target.TabletType != topodatapb.TabletType_MASTER {
+		// Give a new master the full timeout to reach the topo.
+		sm.topoLastSeen = time.Now()
+	}
+	if tabletType != sm.target.TabletType {
+		// The tool that quiesced the tables may not follow the
+		// tablet across a reparent: they'd never be resumed.
+		sm.quiescer.resumeAll(fmt.Sprintf("tablet type changed from %v to %v", sm.target.TabletType, tabletType))
+		// The exports are only taken from the replicas.
+		if sm.export != nil {
+			sm.releaseExportLocked(sm.export, fmt.Sprintf("tablet type changed from %v to %v", sm.target.TabletType, tabletType))
+		}
+	}
+	if state.serving() != sm.state.serving() {
+		sm.flaps.record(sm.clock.Now(), sm.operatorTransition, sm.stateStringLocked(tabletType, state))
+	}
 	sm.target.TabletType = tabletType
+	sm.qe.SetConsolidatorMode(sm.consolidatorMode(tabletType))
 	if sm.state == StateNotConnected {
 		// If we're transitioning out of StateNotConnected, we have
 		// to also ensure replication status is healthy.
 		_, _ = sm.refreshReplHealthLocked()
 	}
 	sm.state = state
+	// The state change is broadcast below.
+	sm.endInitializingLocked()
+	if state == StateServing && !sm.servedOnce {
+		sm.servedOnce = true
+		elapsed := time.Since(sm.processStart)
+		sm.timeToFirstServing.Add(elapsed.Milliseconds())
+		log.Infof("TabletServer serving for the first time, %v after the process start", elapsed)
+	}
+	if !sm.alsoAllowUntil.Equal(alsoAllowUntil) {
+		// The allowed types have changed. Broadcast right away
+		// so that vtgates can update their routing.
+		sm.broadcastLocked()
+		return
+	}
 	// Broadcast also obtains a lock. Trigger in a goroutine to avoid a deadlock.
 	go sm.hcticks.Trigger()
 }
 
+// endInitializingLocked ends the initializing phase, if sm is in it,
+// once the serving type was first set: by the first transition, or by
+// a SetServingType that found sm already in the requested state. The
+// end is recorded in the health history. It returns true if the phase
+// ended, in which case the caller is responsible for broadcasting it.
+func (sm *stateManager) endInitializingLocked() bool {
+	if sm.initialized {
+		return false
+	}
+	sm.initialized = true
+	elapsed := time.Since(sm.processStart)
+	log.Infof("TabletServer initialized as %v, %v after the process start", sm.stateStringLocked(sm.target.TabletType, sm.state), elapsed)
+	sm.hs.recordInitialized(sm.target.TabletType, elapsed)
+	return true
+}
+
 func (sm *stateManager) stateStringLocked(tabletType topodatapb.TabletType, state servingState) string {
-	if tabletType != topodatapb.TabletType_MASTER {
+	return stateString(tabletType.String(), state.String(), sm.terTimestamp)
+}
+
+func stateString(tabletType, state string, terTimestamp time.Time) string {
+	if tabletType != topodatapb.TabletType_MASTER.String() {
 		return fmt.Sprintf("%v: %v", tabletType, state)
 	}
-	return fmt.Sprintf("%v: %v, %v", tabletType, state, sm.terTimestamp.Local().Format("Jan 2, 2006 at 15:04:05 (MST)"))
+	return fmt.Sprintf("%v: %v, %v", tabletType, state, terTimestamp.Local().Format("Jan 2, 2006 at 15:04:05 (MST)"))
 }
 
 func (sm *stateManager) handleGracePeriod(tabletType topodatapb.TabletType) {
 	if tabletType != topodatapb.TabletType_MASTER {
 		// We allow serving of previous type only for a master transition.
 		sm.alsoAllow = nil
+		sm.alsoAllowUntil = time.Time{}
 		return
 	}
 
+	gracePeriod := sm.live.TransitionGracePeriod()
 	if tabletType == topodatapb.TabletType_MASTER &&
 		sm.target.TabletType != topodatapb.TabletType_MASTER &&
-		sm.transitionGracePeriod != 0 {
+		gracePeriod != 0 {
 
-		sm.alsoAllow = []topodatapb.TabletType{sm.target.TabletType}
-		// This is not a perfect solution because multiple back and forth
-		// transitions will launch multiple of these goroutines. But the
-		// system will eventually converge.
-		go func() {
-			time.Sleep(sm.transitionGracePeriod)
+		sm.allowLocked([]topodatapb.TabletType{sm.target.TabletType}, gracePeriod)
+	}
+}
 
-			sm.mu.Lock()
-			defer sm.mu.Unlock()
-			sm.alsoAllow = nil
-		}()
+// allowLocked allows serving of the specified types in addition
+// to the current one for the duration of the grace period.
+// The caller is responsible for broadcasting the change.
+func (sm *stateManager) allowLocked(alsoAllow []topodatapb.TabletType, gracePeriod time.Duration) {
+	until := sm.clock.Now().Add(gracePeriod)
+	sm.alsoAllow = alsoAllow
+	sm.alsoAllowUntil = until
+	// Multiple back and forth transitions will launch multiple
+	// of these goroutines. Only the one that matches the current
+	// grace period is allowed to expire it.
+	go func() {
+		defer sm.recoverPanic()
+		<-sm.clock.After(gracePeriod)
+
+		sm.mu.Lock()
+		defer sm.mu.Unlock()
+		if !sm.alsoAllowUntil.Equal(until) {
+			return
+		}
+		sm.alsoAllow = nil
+		sm.alsoAllowUntil = time.Time{}
+		sm.broadcastLocked()
+	}()
+}
+
+// AcceptedTabletTypes returns the tablet types that the tablet
+// currently wants queries for. See acceptedTabletTypes.
+func (sm *stateManager) AcceptedTabletTypes() []topodatapb.TabletType {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return acceptedTabletTypes(sm.target.TabletType, sm.alsoAllowLocked(), sm.isServingLocked())
+}
+
+// acceptedTabletTypes returns tabletType followed by alsoAllow if
+// the tablet is serving, and nothing otherwise. A tablet in lameduck
+// still executes the queries it gets, but it's not serving: routing
+// layers should stop sending it any.
+func acceptedTabletTypes(tabletType topodatapb.TabletType, alsoAllow []topodatapb.TabletType, serving bool) []topodatapb.TabletType {
+	if !serving {
+		return nil
 	}
+	return append([]topodatapb.TabletType{tabletType}, alsoAllow...)
 }
 
 // Broadcast fetches the replication status and broadcasts
 // the state to all subscribed.
 func (sm *stateManager) Broadcast() {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.broadcastLocked()
+}
+
+func (sm *stateManager) broadcastLocked() {
+	if sm.broadcastsDisabled {
+		return
+	}
+	lag, err := sm.refreshReplHealthLocked()
+	sm.refreshRoleConfidenceLocked()
+	sm.refreshPressureLocked(lag)
+	sm.refreshPromotionLocked(lag, err)
+	sm.refreshSubcomponentHealthLocked()
+	sm.watcherStatus = sm.watcher.Status()
+	sm.flaps.check(sm.clock.Now(), sm.stateStringLocked(sm.target.TabletType, sm.state))
+	sm.changeStateLocked(lag, err)
+}
+
+func (sm *stateManager) changeStateLocked(lag time.Duration, err error) {
+	serving := sm.isServingLocked()
+	if !sm.initialized {
+		// The health of a tablet whose target isn't known yet
+		// is meaningless to the consumers of the health stream.
+		err, serving = errInitializing, false
+	}
+	if err == nil {
+		// The health stream reports the unhealthy subcomponents
+		// even if they don't stop the tablet from serving.
+		err = sm.subcomponentHealthErrLocked()
+	}
+	// The unresolved prepares are only reported by masters:
+	// the TxEngine doesn't track them otherwise.
+	count, maxAge, unresolvedErr := sm.te.UnresolvedPrepares()
+	sm.hs.SetUnresolvedPrepares(count, maxAge)
+	if err == nil {
+		err = unresolvedErr
+	}
+	if err == nil {
+		err = sm.serverIdentityErr
+	}
+	if err == nil {
+		err = sm.writableErr
+	}
+	if err == nil {
+		err = sm.timeoutErr
+	}
+	var transitionStatus string
+	if remaining, ok := sm.te.Draining(); ok {
+		transitionStatus = fmt.Sprintf("draining transactions: %d remaining", remaining)
+	}
+	if sm.readOnlyErr != nil {
+		transitionStatus = fmt.Sprintf("serving reads only: %v", sm.readOnlyErr)
+	}
+	if sm.state == StateServingReadOnly {
+		transitionStatus = "serving reads only for maintenance"
+	}
+	if transitionStatus == "" && sm.shedFraction > 0 {
+		transitionStatus = fmt.Sprintf("shedding %d%% of the requests: replication lag %v is degraded", shedPercent(sm.shedFraction), lag)
+	}
+	sm.hs.setManagerTarget(sm.target)
+	sm.hs.SetWatcherStatus(sm.watcherStatus)
+	sm.hs.ChangeState(healthState{
+		tabletType:       sm.target.TabletType,
+		terTimestamp:     sm.terTimestamp,
+		lag:              lag,
+		err:              err,
+		serving:          serving,
+		pu:               sm.poolUsage(),
+		notConnected:     sm.notConnectedStringLocked(),
+		reason:           sm.reason,
+		alsoAllow:        sm.alsoAllowLocked(),
+		transitionStatus: transitionStatus,
+		roleConfidence:   sm.roleConfidence,
+	})
+}
+
+// RefreshReplHealth refreshes the replication health without waiting
+// for the next health check tick. If this changes the serving status,
+// the new state is broadcast right away. It fails if a transition is
+// in progress.
+func (sm *stateManager) RefreshReplHealth() error {
+	if !sm.transitioning.TryAcquire() {
+		return vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "cannot refresh replication health: a state transition is in progress")
+	}
+	defer sm.transitioning.Release()
+
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
+	sm.replHealthRefreshes.Add(1)
+	wasServing := sm.isServingLocked()
 	lag, err := sm.refreshReplHealthLocked()
-	sm.hs.ChangeState(sm.target.TabletType, sm.terTimestamp, lag, err, sm.isServingLocked())
+	log.Infof("Replication health refreshed on request: lag: %v, err: %v", lag, err)
+	if sm.isServingLocked() != wasServing {
+		sm.changeStateLocked(lag, err)
+	}
+	return nil
+}
+
+// poolUsage samples the utilization of the query
+// and transaction pools.
+func (sm *stateManager) poolUsage() poolUsage {
+	var pu poolUsage
+	pu.queryInUse, pu.queryCapacity = sm.qe.PoolUsage()
+	pu.txInUse, pu.txCapacity = sm.te.PoolUsage()
+	return pu
 }
 
 func (sm *stateManager) refreshReplHealthLocked() (time.Duration, error) {
 	if sm.target.TabletType == topodatapb.TabletType_MASTER {
 		sm.replHealthy = true
+		sm.setLagSourceLocked(repltracker.LagSourceNone)
+		sm.refreshShedLocked(0)
+		sm.recordLagSampleLocked(0, repltracker.LagSourceNone, nil)
 		return 0, nil
 	}
-	lag, err := sm.rt.Status()
+	lag, source, err := sm.rt.Status()
+	if err == repltracker.ErrNotConfigured && sm.serveWithoutReplication {
+		lag, err = 0, nil
+	} else if err == nil {
+		err = sm.rt.CrossCheck(lag)
+	}
+	sm.replLag, sm.replErr = lag, err
+	sm.setLagSourceLocked(source)
 	if err != nil {
 		if sm.replHealthy {
-			log.Infof("Going unhealthy due to replication error: %v", err)
+			d := sm.decisionLocked(decisionReplUnhealthy, err)
+			d.Reason = "replication error"
+			sm.logDecision(log.Infof, d, "Going unhealthy due to replication error: %v", err)
 		}
 		sm.replHealthy = false
 	} else {
-		if lag > sm.unhealthyThreshold {
+		if lag > sm.live.UnhealthyThreshold() {
 			if sm.replHealthy {
-				log.Infof("Going unhealthy due to high replication lag: %v", lag)
+				d := sm.decisionLocked(decisionReplUnhealthy, nil)
+				d.Reason = fmt.Sprintf("high replication lag: %v", lag)
+				sm.logDecision(log.Infof, d, "Going unhealthy due to high replication lag: %v", lag)
 			}
 			sm.replHealthy = false
 		} else {
 			if !sm.replHealthy {
-				log.Infof("Replication is healthy")
+				sm.logDecision(log.Infof, sm.decisionLocked(decisionReplHealthy, nil), "Replication is healthy")
 			}
 			sm.replHealthy = true
 		}
 	}
+	sm.refreshShedLocked(lag)
+	sm.recordLagSampleLocked(lag, source, err)
 	return lag, err
 }
 
+// setLagSourceLocked records the source of the replication lag, and
+// logs when the lag starts or stops coming from a fallback.
+func (sm *stateManager) setLagSourceLocked(source repltracker.LagSource) {
+	if source != sm.replLagSource {
+		if source == repltracker.LagSourceFallback {
+			log.Warningf("Replication lag is read from the replica status: the heartbeat failed")
+		} else if sm.replLagSource == repltracker.LagSourceFallback {
+			log.Infof("Replication lag is read from the %v again", source)
+		}
+	}
+	sm.replLagSource = source
+	sm.hs.SetLagSource(source)
+}
+
 // EnterLameduck causes tabletserver to enter the lameduck state. This
 // state causes health checks to fail, but the behavior of tabletserver
-// otherwise remains the same. Any subsequent calls to SetServingType will
-// cause the tabletserver to exit this mode.
+// otherwise remains the same, except that the running streams are
+// drained if drainStreamsOnLameduck is set. Any subsequent calls to
+// SetServingType will cause the tabletserver to exit this mode.
 func (sm *stateManager) EnterLameduck() {
-	log.Info("State: entering lameduck")
+	sm.enterLameduck()
+	sm.audit(&TransitionAuditEntry{Event: auditEnterLameduck}, nil)
+}
+
+func (sm *stateManager) enterLameduck() {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
+	sm.logDecision(log.Infof, sm.decisionLocked(decisionEnterLameduck, nil), "State: entering lameduck")
 	sm.lameduck = true
+	if sm.drainStreamsOnLameduck {
+		sm.drainStreamsLocked()
+	}
 }
 
 // ExitLameduck causes the tabletserver to exit the lameduck mode.
 func (sm *stateManager) ExitLameduck() {
+	sm.exitLameduck()
+	sm.audit(&TransitionAuditEntry{Event: auditExitLameduck}, nil)
+}
+
+func (sm *stateManager) exitLameduck() {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 	sm.lameduck = false
-	log.Info("State: exiting lameduck")
+	sm.logDecision(log.Infof, sm.decisionLocked(decisionExitLameduck, nil), "State: exiting lameduck")
 }
 
 // IsServing returns true if TabletServer is in SERVING state.
@@ -632,64 +2562,89 @@ func (sm *stateManager) IsServing() bool {
 }
 
 func (sm *stateManager) isServingLocked() bool {
-	return sm.state == StateServing && sm.wantState == StateServing && sm.replHealthy && !sm.lameduck
+	return sm.state.serving() && sm.wantState.serving() && sm.replHealthy && !sm.lameduck && sm.subcomponentsHealthyLocked()
 }
 
-func (sm *stateManager) ApppendDetails(details []*kv) []*kv {
+// probeState is the state reported to readiness and liveness probes.
+type probeState struct {
+	State      string `json:"state"`
+	WantState  string `json:"wantState"`
+	TabletType string `json:"tabletType"`
+	// NotConnected is set if State is not connected.
+	NotConnected string `json:"notConnected,omitempty"`
+	// Lag is the replication lag in seconds.
+	Lag    int64  `json:"lag"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func (sm *stateManager) probeStateLocked() probeState {
+	return probeState{
+		State:        sm.state.String(),
+		WantState:    sm.wantState.String(),
+		TabletType:   sm.target.TabletType.String(),
+		NotConnected: sm.notConnectedStringLocked(),
+		Lag:          int64(sm.replLag.Seconds()),
+	}
+}
+
+// Readiness returns true if the tablet can receive traffic.
+// Otherwise, the returned state contains the reason why it can't.
+func (sm *stateManager) Readiness() (probeState, bool) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	stateClass := func(state servingState) string {
-		switch state {
-		case StateServing:
-			return healthyClass
-		case StateNotServing:
-			return unhappyClass
+	ps := sm.probeStateLocked()
+	if sm.isServingLocked() {
+		return ps, true
+	}
+	switch {
+	case !sm.initialized:
+		ps.Reason = errInitializing.Error()
+	case sm.lameduck:
+		ps.Reason = "lameduck"
+	case !sm.wantState.serving():
+		ps.Reason = sm.reason
+		if ps.Reason == "" {
+			ps.Reason = fmt.Sprintf("desired state is %v", sm.wantState)
 		}
-		return unhealthyClass
+	case !sm.state.serving():
+		switch {
+		case sm.transitionErr != nil:
+			ps.Reason = sm.transitionErr.Error()
+		case sm.state == StateNotConnected:
+			ps.Reason = fmt.Sprintf("not connected: %v", sm.notConnected)
+		default:
+			ps.Reason = "transition in progress"
+		}
+	case sm.replErr != nil:
+		ps.Reason = sm.replErr.Error()
+	case !sm.subcomponentsHealthyLocked():
+		ps.Reason = sm.subcomponentHealthErrLocked().Error()
+	default:
+		ps.Reason = fmt.Sprintf("replication lag %v exceeds unhealthy threshold", sm.replLag)
 	}
+	return ps, false
+}
 
-	details = append(details, &kv{
-		Key:   "Current State",
-		Class: stateClass(sm.state),
-		Value: sm.stateStringLocked(sm.target.TabletType, sm.state),
-	})
-	if sm.target.TabletType != sm.wantTabletType && sm.state != sm.wantState {
-		details = append(details, &kv{
-			Key:   "Desired State",
-			Class: stateClass(sm.wantState),
-			Value: sm.stateStringLocked(sm.wantTabletType, sm.wantState),
-		})
-	}
-	if sm.reason != "" {
-		details = append(details, &kv{
-			Key:   "Reason",
-			Class: unhappyClass,
-			Value: sm.reason,
-		})
-	}
-	if sm.transitionErr != nil {
-		details = append(details, &kv{
-			Key:   "Transition Error",
-			Class: unhealthyClass,
-			Value: sm.transitionErr.Error(),
-		})
-	}
-	if sm.lameduck {
-		details = append(details, &kv{
-			Key:   "Lameduck",
-			Class: unhealthyClass,
-			Value: "ON",
-		})
+// Liveness returns false if a state transition has been in
+// progress for longer than the liveness threshold.
+func (sm *stateManager) Liveness() (probeState, bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	ps := sm.probeStateLocked()
+	if sm.transitionStart.IsZero() || sm.livenessThreshold == 0 {
+		return ps, true
 	}
-	if len(sm.alsoAllow) != 0 {
-		details = append(details, &kv{
-			Key:   "Also Serving",
-			Class: healthyClass,
-			Value: sm.alsoAllow[0].String(),
-		})
+	if elapsed := time.Since(sm.transitionStart); elapsed > sm.livenessThreshold {
+		ps.Reason = fmt.Sprintf("state transition in progress for %v", elapsed.Round(time.Second))
+		return ps, false
 	}
-	return details
+	return ps, true
+}
+
+func (sm *stateManager) ApppendDetails(details []*kv) []*kv {
+	return sm.Status().appendDetails(details)
 }
 
 func (sm *stateManager) State() servingState {
@@ -711,6 +2666,35 @@ func (sm *stateManager) Target() querypb.Target {
 	return target
 }
 
+// NotConnectedState returns the reason why tabletserver is not
+// connected. The second return value is false if it's connected.
+func (sm *stateManager) NotConnectedState() (notConnectedState, bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.notConnected, sm.state == StateNotConnected
+}
+
+func (sm *stateManager) notConnectedStringLocked() string {
+	if sm.state != StateNotConnected {
+		return ""
+	}
+	return sm.notConnected.String()
+}
+
+// DetailedStateString is like IsServingString, but it also
+// qualifies the state if tabletserver is not connected.
+func (sm *stateManager) DetailedStateString() string {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	switch {
+	case sm.state == StateNotConnected:
+		return fmt.Sprintf("NOT_CONNECTED (%v)", sm.notConnected)
+	case sm.isServingLocked():
+		return "SERVING"
+	}
+	return "NOT_SERVING"
+}
+
 // IsServingString returns the name of the current TabletServer state.
 func (sm *stateManager) IsServingString() string {
 	if sm.IsServing() {
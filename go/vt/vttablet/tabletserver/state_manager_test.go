@@ -18,6 +18,9 @@ package tabletserver
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -26,10 +29,19 @@ import (
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
 	"vitess.io/vitess/go/sync2"
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/timer/fakeclock"
+	"vitess.io/vitess/go/trace"
+	"vitess.io/vitess/go/vt/hook"
 	"vitess.io/vitess/go/vt/log"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/repltracker"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
 )
 
 var testNow = time.Now()
@@ -48,6 +60,10 @@ func TestStateManagerStateByName(t *testing.T) {
 	sm.state = StateServing
 	assert.Equal(t, "SERVING", sm.IsServingString())
 
+	sm.state = StateServingReadOnly
+	assert.Equal(t, "SERVING", sm.IsServingString())
+	sm.state = StateServing
+
 	sm.wantState = StateNotServing
 	assert.Equal(t, "NOT_SERVING", sm.IsServingString())
 	sm.wantState = StateServing
@@ -64,7 +80,7 @@ func TestStateManagerServeMaster(t *testing.T) {
 	sm := newTestStateManager(t)
 	defer sm.StopService()
 	sm.EnterLameduck()
-	err := sm.SetServingType(topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
 	require.NoError(t, err)
 
 	assert.Equal(t, false, sm.lameduck)
@@ -72,6 +88,34 @@ func TestStateManagerServeMaster(t *testing.T) {
 
 	verifySubcomponent(t, 1, sm.watcher, testStateClosed)
 
+	// The components that don't depend on each other are
+	// opened concurrently.
+	verifyOpenedAfter(t, sm.se, testStateOpen, sm.watcher)
+	verifyOpenedAfter(t, sm.vstreamer, testStateOpen, sm.se)
+	verifyOpenedAfter(t, sm.qe, testStateOpen, sm.se)
+	verifyOpenedAfter(t, sm.txThrottler, testStateOpen, sm.watcher)
+	verifyOpenedAfter(t, sm.rt, testStateMaster, sm.watcher)
+	verifyOpenedAfter(t, sm.tracker, testStateOpen, sm.se, sm.vstreamer)
+	verifyOpenedAfter(t, sm.te, testStateMaster, sm.qe)
+	verifyOpenedAfter(t, sm.messager, testStateOpen, sm.se, sm.te)
+	verifyOpenedAfter(t, sm.throttler, testStateOpen, sm.rt)
+
+	assert.False(t, sm.se.(*testSchemaEngine).nonMaster)
+	assert.True(t, sm.se.(*testSchemaEngine).ensureCalled)
+	assert.False(t, sm.qe.(*testQueryEngine).stopServing)
+
+	assert.Equal(t, topodatapb.TabletType_MASTER, sm.target.TabletType)
+	assert.Equal(t, StateServing, sm.state)
+}
+
+func TestStateManagerServeMasterSerial(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	sm.serialOpens = true
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	verifySubcomponent(t, 1, sm.watcher, testStateClosed)
 	verifySubcomponent(t, 2, sm.se, testStateOpen)
 	verifySubcomponent(t, 3, sm.vstreamer, testStateOpen)
 	verifySubcomponent(t, 4, sm.qe, testStateOpen)
@@ -81,19 +125,70 @@ func TestStateManagerServeMaster(t *testing.T) {
 	verifySubcomponent(t, 8, sm.te, testStateMaster)
 	verifySubcomponent(t, 9, sm.messager, testStateOpen)
 	verifySubcomponent(t, 10, sm.throttler, testStateOpen)
+	assert.Contains(t, sm.se.(*testSchemaEngine).notifiers, hsNotifierName)
+}
 
-	assert.False(t, sm.se.(*testSchemaEngine).nonMaster)
-	assert.True(t, sm.se.(*testSchemaEngine).ensureCalled)
-	assert.False(t, sm.qe.(*testQueryEngine).stopServing)
+func TestStateManagerServeMasterOpenFailure(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	sm.se.(*testSchemaEngine).failMySQL = true
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "intentional error")
 
-	assert.Equal(t, topodatapb.TabletType_MASTER, sm.target.TabletType)
-	assert.Equal(t, StateServing, sm.state)
+	// What depends on the failed step isn't opened.
+	verifySubcomponent(t, 1, sm.watcher, testStateClosed)
+	for _, component := range []interface{}{sm.se, sm.vstreamer, sm.qe, sm.txThrottler, sm.rt, sm.tracker, sm.te, sm.messager, sm.throttler} {
+		assert.Zero(t, component.(orderState).State(), "%T", component)
+	}
+}
+
+func TestStateManagerTransitionTimeout(t *testing.T) {
+	for _, serial := range []bool{false, true} {
+		t.Run(fmt.Sprintf("serial=%v", serial), func(t *testing.T) {
+			// The retries of the failed transition never fire.
+			sm := newTestStateManagerWithClock(t, fakeclock.New(testNow))
+			defer sm.StopService()
+			sm.serialOpens = serial
+			sm.transitionTimeout = 10 * time.Millisecond
+			sm.se.(*testSchemaEngine).hangOpen = true
+
+			err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+			require.Error(t, err)
+			want := "se.Open timed out: the transition did not complete within 10ms: context deadline exceeded"
+			assert.EqualError(t, err, want)
+
+			// The tablet disconnects, and reports the timeout.
+			assert.Equal(t, StateNotConnected, sm.State())
+			for _, component := range []interface{}{sm.se, sm.vstreamer, sm.qe, sm.txThrottler, sm.te} {
+				assert.Equal(t, testStateClosed, component.(orderState).State(), "%T", component)
+			}
+			assert.Equal(t, want, sm.Status().TransitionError)
+			sm.hs.Open()
+			ch, cancel := testStream(sm.hs)
+			defer cancel()
+			<-ch
+			sm.Broadcast()
+			var shr *querypb.StreamHealthResponse
+			for shr = <-ch; shr.RealtimeStats.HealthError != want; shr = <-ch {
+			}
+
+			// The next transition clears it.
+			err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+			require.NoError(t, err)
+			assert.True(t, sm.IsServing())
+			sm.Broadcast()
+			for shr = <-ch; shr.RealtimeStats.HealthError != ""; shr = <-ch {
+			}
+			assert.True(t, shr.Serving)
+		})
+	}
 }
 
 func TestStateManagerServeNonMaster(t *testing.T) {
 	sm := newTestStateManager(t)
 	defer sm.StopService()
-	err := sm.SetServingType(topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
 	require.NoError(t, err)
 
 	verifySubcomponent(t, 1, sm.throttler, testStateClosed)
@@ -101,10 +196,7 @@ func TestStateManagerServeNonMaster(t *testing.T) {
 	verifySubcomponent(t, 3, sm.tracker, testStateClosed)
 	assert.True(t, sm.se.(*testSchemaEngine).nonMaster)
 
-	verifySubcomponent(t, 4, sm.se, testStateOpen)
-	verifySubcomponent(t, 5, sm.vstreamer, testStateOpen)
-	verifySubcomponent(t, 6, sm.qe, testStateOpen)
-	verifySubcomponent(t, 7, sm.txThrottler, testStateOpen)
+	verifyConnected(t, sm, sm.tracker)
 	verifySubcomponent(t, 8, sm.te, testStateNonMaster)
 	verifySubcomponent(t, 9, sm.rt, testStateNonMaster)
 	verifySubcomponent(t, 10, sm.watcher, testStateOpen)
@@ -113,10 +205,45 @@ func TestStateManagerServeNonMaster(t *testing.T) {
 	assert.Equal(t, StateServing, sm.state)
 }
 
+func TestStateManagerServeNonMasterWithThrottler(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	config := tabletenv.NewDefaultConfig()
+	config.ThrottleOnReplicas = true
+	require.NoError(t, sm.Init(tabletenv.NewEnv(config, "StateManagerTest"), querypb.Target{}))
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	verifySubcomponent(t, 1, sm.messager, testStateClosed)
+	verifySubcomponent(t, 2, sm.tracker, testStateClosed)
+	verifyConnected(t, sm, sm.tracker)
+	verifySubcomponent(t, 7, sm.te, testStateNonMaster)
+	verifySubcomponent(t, 8, sm.rt, testStateNonMaster)
+	verifySubcomponent(t, 9, sm.watcher, testStateOpen)
+	verifySubcomponent(t, 10, sm.throttler, testStateOpen)
+
+	// The throttler stays open when a master is demoted.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	order.Set(0)
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	verifySubcomponent(t, 1, sm.messager, testStateClosed)
+	verifySubcomponent(t, 2, sm.tracker, testStateClosed)
+	assert.Equal(t, testStateOpen, sm.throttler.(*testLagThrottler).state)
+
+	// It's closed once, with the other serving components.
+	closes := sm.throttler.(*testLagThrottler).closes
+	sm.StopService()
+	assert.Equal(t, testStateClosed, sm.throttler.(*testLagThrottler).state)
+	assert.Equal(t, closes+1, sm.throttler.(*testLagThrottler).closes)
+}
+
 func TestStateManagerUnserveMaster(t *testing.T) {
 	sm := newTestStateManager(t)
 	defer sm.StopService()
-	err := sm.SetServingType(topodatapb.TabletType_MASTER, testNow, StateNotServing, "")
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateNotServing, "")
 	require.NoError(t, err)
 
 	verifySubcomponent(t, 1, sm.throttler, testStateClosed)
@@ -126,10 +253,7 @@ func TestStateManagerUnserveMaster(t *testing.T) {
 
 	verifySubcomponent(t, 4, sm.tracker, testStateClosed)
 	verifySubcomponent(t, 5, sm.watcher, testStateClosed)
-	verifySubcomponent(t, 6, sm.se, testStateOpen)
-	verifySubcomponent(t, 7, sm.vstreamer, testStateOpen)
-	verifySubcomponent(t, 8, sm.qe, testStateOpen)
-	verifySubcomponent(t, 9, sm.txThrottler, testStateOpen)
+	verifyConnected(t, sm, sm.watcher)
 
 	verifySubcomponent(t, 10, sm.rt, testStateMaster)
 
@@ -140,7 +264,7 @@ func TestStateManagerUnserveMaster(t *testing.T) {
 func TestStateManagerUnserveNonmaster(t *testing.T) {
 	sm := newTestStateManager(t)
 	defer sm.StopService()
-	err := sm.SetServingType(topodatapb.TabletType_RDONLY, testNow, StateNotServing, "")
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_RDONLY, testNow, StateNotServing, "")
 	require.NoError(t, err)
 
 	verifySubcomponent(t, 1, sm.throttler, testStateClosed)
@@ -151,10 +275,7 @@ func TestStateManagerUnserveNonmaster(t *testing.T) {
 	verifySubcomponent(t, 4, sm.tracker, testStateClosed)
 	assert.True(t, sm.se.(*testSchemaEngine).nonMaster)
 
-	verifySubcomponent(t, 5, sm.se, testStateOpen)
-	verifySubcomponent(t, 6, sm.vstreamer, testStateOpen)
-	verifySubcomponent(t, 7, sm.qe, testStateOpen)
-	verifySubcomponent(t, 8, sm.txThrottler, testStateOpen)
+	verifyConnected(t, sm, sm.tracker)
 
 	verifySubcomponent(t, 9, sm.rt, testStateNonMaster)
 	verifySubcomponent(t, 10, sm.watcher, testStateOpen)
@@ -163,10 +284,119 @@ func TestStateManagerUnserveNonmaster(t *testing.T) {
 	assert.Equal(t, StateNotServing, sm.state)
 }
 
+func TestStateManagerServingOrder(t *testing.T) {
+	names := func(sm *stateManager) []string {
+		var names []string
+		for _, c := range sm.servingOrder {
+			names = append(names, c.name)
+		}
+		return names
+	}
+	// The default order is the one the serve tests verify.
+	sm := newTestStateManager(t)
+	assert.Equal(t, []string{"tracker", "txEngine", "messager", "throttler"}, names(sm))
+	sm.StopService()
+
+	sm = newTestStateManager(t)
+	defer sm.StopService()
+	config := tabletenv.NewDefaultConfig()
+	config.ServingOrder = []string{"tracker", "txEngine", "throttler", "messager"}
+	require.NoError(t, sm.Init(tabletenv.NewEnv(config, "StateManagerTest"), querypb.Target{}))
+	assert.Equal(t, config.ServingOrder, names(sm))
+
+	// A configured order is kept even if the components
+	// don't depend on each other.
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	verifyOpenedAfter(t, sm.tracker, testStateOpen, sm.se, sm.vstreamer)
+	verifyOpenedAfter(t, sm.te, testStateMaster, sm.qe, sm.tracker)
+	verifyOpenedAfter(t, sm.throttler, testStateOpen, sm.rt, sm.te)
+	verifyOpenedAfter(t, sm.messager, testStateOpen, sm.throttler)
+
+	// The components are closed in reverse order.
+	order.Set(0)
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateNotServing, "")
+	require.NoError(t, err)
+	verifySubcomponent(t, 1, sm.messager, testStateClosed)
+	verifySubcomponent(t, 2, sm.throttler, testStateClosed)
+	verifySubcomponent(t, 3, sm.te, testStateClosed)
+	verifySubcomponent(t, 4, sm.tracker, testStateClosed)
+
+	// The tx engine is not closed on demotion.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	order.Set(0)
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	verifySubcomponent(t, 1, sm.messager, testStateClosed)
+	verifySubcomponent(t, 2, sm.throttler, testStateClosed)
+	verifySubcomponent(t, 3, sm.tracker, testStateClosed)
+	verifySubcomponent(t, 8, sm.te, testStateNonMaster)
+}
+
+func TestStateManagerServingOrderValidation(t *testing.T) {
+	testcases := []struct {
+		order []string
+		err   string
+	}{{
+		order: []string{"tracker", "txEngine", "messager", "throttler", "watcher"},
+		err:   `unknown serving component "watcher"`,
+	}, {
+		order: []string{"tracker", "txEngine", "messager", "throttler", "tracker"},
+		err:   `serving component "tracker" is listed more than once`,
+	}, {
+		order: []string{"tracker", "txEngine", "throttler"},
+		err:   `serving component "messager" is missing from the serving order`,
+	}}
+	for _, tcase := range testcases {
+		sm := newTestStateManager(t)
+		config := tabletenv.NewDefaultConfig()
+		config.ServingOrder = tcase.order
+		err := sm.Init(tabletenv.NewEnv(config, "StateManagerTest"), querypb.Target{})
+		assert.EqualError(t, err, tcase.err, "order: %v", tcase.order)
+		sm.StopService()
+	}
+}
+
+func TestStateManagerTransitionOps(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	sm.vstreamer.(*testVStreamer).sleep = 100 * time.Millisecond
+	sm.tracker.(*testSubcomponent).sleep = 100 * time.Millisecond
+	wallCounts := sm.opWallTimings.Counts()
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	ops := make(map[string]transitionOp)
+	for _, op := range sm.transitionOps {
+		ops[op.Name] = op
+	}
+	for _, name := range []string{"watcher.Close", "se.EnsureConnectionAndDB", "se.Open", "vstreamer.Open", "qe.Open", "txThrottler.Open", "rt.MakeMaster", "tracker.Open", "txEngine.Prepare", "txEngine.Open", "messager.Open", "throttler.Open"} {
+		assert.Contains(t, ops, name)
+	}
+	assert.GreaterOrEqual(t, int64(ops["vstreamer.Open"].Wall), int64(100*time.Millisecond))
+	assert.GreaterOrEqual(t, int64(ops["tracker.Open"].Wall), int64(100*time.Millisecond))
+	assert.Equal(t, wallCounts["StateManagerTest.vstreamer_Open"]+1, sm.opWallTimings.Counts()["StateManagerTest.vstreamer_Open"])
+
+	// The operations are recorded in the history.
+	sm.Broadcast()
+	rec := sm.hs.history.Latest().(*historyRecord)
+	assert.Len(t, rec.transition, len(sm.transitionOps))
+	summary := rec.Transition()
+	assert.Regexp(t, "^(vstreamer|tracker).Open: ", summary)
+	assert.Contains(t, summary, "tracker.Open: ")
+	assert.Equal(t, 2, strings.Count(summary, ", "))
+
+	// Only the next record carries them.
+	sm.Broadcast()
+	assert.Nil(t, sm.hs.history.Latest().(*historyRecord).transition)
+}
+
 func TestStateManagerClose(t *testing.T) {
 	sm := newTestStateManager(t)
 	defer sm.StopService()
-	err := sm.SetServingType(topodatapb.TabletType_RDONLY, testNow, StateNotConnected, "")
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_RDONLY, testNow, StateNotConnected, "")
 	require.NoError(t, err)
 
 	verifySubcomponent(t, 1, sm.throttler, testStateClosed)
@@ -189,7 +419,7 @@ func TestStateManagerClose(t *testing.T) {
 func TestStateManagerStopService(t *testing.T) {
 	sm := newTestStateManager(t)
 	defer sm.StopService()
-	err := sm.SetServingType(topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
 	require.NoError(t, err)
 
 	assert.Equal(t, topodatapb.TabletType_REPLICA, sm.target.TabletType)
@@ -200,10 +430,26 @@ func TestStateManagerStopService(t *testing.T) {
 	assert.Equal(t, StateNotConnected, sm.state)
 }
 
-func TestStateManagerGracePeriod(t *testing.T) {
+func TestStateManagerStopServiceActiveVStreams(t *testing.T) {
 	sm := newTestStateManager(t)
 	defer sm.StopService()
-	sm.transitionGracePeriod = 10 * time.Millisecond
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	tl := newTestLogger()
+	defer tl.Close()
+	sm.vstreamer.(*testVStreamer).streams = 2
+	sm.StopService()
+	assert.Contains(t, tl.logs, "Terminating 2 active vstreams")
+}
+
+func TestStateManagerGracePeriod(t *testing.T) {
+	fc := fakeclock.New(testNow)
+	sm := newTestStateManagerWithClock(t, fc)
+	defer sm.StopService()
+	// The grace period is shorter than the health check
+	// interval, so that it expires before the next tick.
+	sm.live.values.TransitionGracePeriod = 10 * time.Second
 
 	alsoAllow := func() topodatapb.TabletType {
 		sm.mu.Lock()
@@ -214,22 +460,122 @@ func TestStateManagerGracePeriod(t *testing.T) {
 		return sm.alsoAllow[0]
 	}
 
-	err := sm.SetServingType(topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	sm.hs.Open()
+	ch, cancel := testStream(sm.hs)
+	defer cancel()
+	<-ch
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
 	require.NoError(t, err)
 
 	assert.Equal(t, topodatapb.TabletType_UNKNOWN, alsoAllow())
 	assert.Equal(t, topodatapb.TabletType_REPLICA, sm.target.TabletType)
 	assert.Equal(t, StateServing, sm.state)
 
-	err = sm.SetServingType(topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	// The regular broadcast for the transition.
+	shr := <-ch
+	assert.Equal(t, topodatapb.TabletType_REPLICA, shr.Target.TabletType)
+	assert.Nil(t, shr.AlsoAllow)
+	assert.Equal(t, []topodatapb.TabletType{topodatapb.TabletType_REPLICA}, shr.AcceptedTabletTypes)
+
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
 	require.NoError(t, err)
 
 	assert.Equal(t, topodatapb.TabletType_REPLICA, alsoAllow())
 	assert.Equal(t, topodatapb.TabletType_MASTER, sm.target.TabletType)
 	assert.Equal(t, StateServing, sm.state)
 
-	time.Sleep(20 * time.Millisecond)
+	// The grace period is broadcast on install.
+	shr = <-ch
+	assert.Equal(t, topodatapb.TabletType_MASTER, shr.Target.TabletType)
+	assert.Equal(t, []topodatapb.TabletType{topodatapb.TabletType_REPLICA}, shr.AlsoAllow)
+	assert.Equal(t, []topodatapb.TabletType{topodatapb.TabletType_MASTER, topodatapb.TabletType_REPLICA}, shr.AcceptedTabletTypes)
+	assert.Equal(t, shr.AcceptedTabletTypes, sm.AcceptedTabletTypes())
+
+	// And on expiry. Wait for the grace period timer to
+	// be pending along with the health check timer.
+	fc.BlockUntil(2)
+	fc.Advance(10 * time.Second)
+	shr = <-ch
+	assert.Equal(t, topodatapb.TabletType_MASTER, shr.Target.TabletType)
+	assert.Nil(t, shr.AlsoAllow)
 	assert.Equal(t, topodatapb.TabletType_UNKNOWN, alsoAllow())
+	assert.Equal(t, []topodatapb.TabletType{topodatapb.TabletType_MASTER}, shr.AcceptedTabletTypes)
+	assert.Equal(t, shr.AcceptedTabletTypes, sm.AcceptedTabletTypes())
+
+	select {
+	case shr := <-ch:
+		t.Errorf("unexpected broadcast: %v", shr)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// A tablet in lameduck accepts no types.
+	sm.EnterLameduck()
+	assert.Nil(t, sm.AcceptedTabletTypes())
+	sm.ExitLameduck()
+	assert.Equal(t, []topodatapb.TabletType{topodatapb.TabletType_MASTER}, sm.AcceptedTabletTypes())
+}
+
+func TestStateManagerBroadcastInterval(t *testing.T) {
+	fc := fakeclock.New(testNow)
+	sm := newTestStateManagerWithClock(t, fc)
+	defer sm.StopService()
+	sm.servingInterval = 20 * time.Second
+	sm.notServingInterval = 5 * time.Second
+	sm.hcticks.SetInterval(sm.notServingInterval)
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateNotServing, "")
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, sm.hcticks.Interval())
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.Equal(t, 20*time.Second, sm.hcticks.Interval())
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServingReadOnly, "")
+	require.NoError(t, err)
+	assert.Equal(t, 20*time.Second, sm.hcticks.Interval())
+
+	// The transitions don't leave timers behind.
+	for i := 0; i < 20; i++ {
+		err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateNotServing, "")
+		require.NoError(t, err)
+		err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+		require.NoError(t, err)
+	}
+	assert.Eventually(t, func() bool {
+		return fc.Pending() == 1
+	}, 5*time.Second, time.Millisecond)
+
+	// The not serving interval is used until the tablet serves again.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateNotConnected, "")
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, sm.hcticks.Interval())
+}
+
+func TestStateManagerGracePeriodReplaced(t *testing.T) {
+	fc := fakeclock.New(testNow)
+	sm := newTestStateManagerWithClock(t, fc)
+	defer sm.StopService()
+	alsoAllow := func() []topodatapb.TabletType {
+		sm.mu.Lock()
+		defer sm.mu.Unlock()
+		return sm.alsoAllow
+	}
+
+	// An expiring grace period must not clear one that replaced it.
+	sm.mu.Lock()
+	sm.allowLocked([]topodatapb.TabletType{topodatapb.TabletType_REPLICA}, 10*time.Second)
+	sm.allowLocked([]topodatapb.TabletType{topodatapb.TabletType_RDONLY}, time.Hour)
+	sm.mu.Unlock()
+
+	fc.BlockUntil(2)
+	fc.Advance(10 * time.Second)
+	assert.Equal(t, []topodatapb.TabletType{topodatapb.TabletType_RDONLY}, alsoAllow())
+
+	// The replacement expires on its own schedule.
+	fc.Advance(time.Hour)
+	for alsoAllow() != nil {
+		time.Sleep(time.Millisecond)
+	}
 }
 
 // testWatcher is used as a hook to invoke another transition
@@ -247,11 +593,15 @@ func (te *testWatcher) Close() {
 	go func() {
 		defer te.wg.Done()
 
-		err := te.sm.SetServingType(topodatapb.TabletType_RDONLY, testNow, StateNotServing, "")
+		err := te.sm.SetServingType(context.Background(), topodatapb.TabletType_RDONLY, testNow, StateNotServing, "")
 		assert.NoError(te.t, err)
 	}()
 }
 
+func (te *testWatcher) Status() WatcherStatus {
+	return WatcherStatus{State: "n/a"}
+}
+
 func TestStateManagerSetServingTypeRace(t *testing.T) {
 	// We don't call StopService because that in turn
 	// will call Close again on testWatcher.
@@ -261,7 +611,7 @@ func TestStateManagerSetServingTypeRace(t *testing.T) {
 		sm: sm,
 	}
 	sm.watcher = te
-	err := sm.SetServingType(topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
 	require.NoError(t, err)
 
 	// Ensure the next call waits and then succeeds.
@@ -276,10 +626,10 @@ func TestStateManagerSetServingTypeNoChange(t *testing.T) {
 	log.Infof("starting")
 	sm := newTestStateManager(t)
 	defer sm.StopService()
-	err := sm.SetServingType(topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
 	require.NoError(t, err)
 
-	err = sm.SetServingType(topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
 	require.NoError(t, err)
 
 	verifySubcomponent(t, 1, sm.throttler, testStateClosed)
@@ -287,10 +637,7 @@ func TestStateManagerSetServingTypeNoChange(t *testing.T) {
 	verifySubcomponent(t, 3, sm.tracker, testStateClosed)
 	assert.True(t, sm.se.(*testSchemaEngine).nonMaster)
 
-	verifySubcomponent(t, 4, sm.se, testStateOpen)
-	verifySubcomponent(t, 5, sm.vstreamer, testStateOpen)
-	verifySubcomponent(t, 6, sm.qe, testStateOpen)
-	verifySubcomponent(t, 7, sm.txThrottler, testStateOpen)
+	verifyConnected(t, sm, sm.tracker)
 	verifySubcomponent(t, 8, sm.te, testStateNonMaster)
 	verifySubcomponent(t, 9, sm.rt, testStateNonMaster)
 	verifySubcomponent(t, 10, sm.watcher, testStateOpen)
@@ -300,26 +647,35 @@ func TestStateManagerSetServingTypeNoChange(t *testing.T) {
 }
 
 func TestStateManagerTransitionFailRetry(t *testing.T) {
-	defer func(saved time.Duration) { transitionRetryInterval = saved }(transitionRetryInterval)
-	transitionRetryInterval = 10 * time.Millisecond
-
-	sm := newTestStateManager(t)
+	fc := fakeclock.New(testNow)
+	sm := newTestStateManagerWithClock(t, fc)
 	defer sm.StopService()
 	sm.se.(*testSchemaEngine).failMySQL = true
 
-	err := sm.SetServingType(topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
 	require.Error(t, err)
 
 	// Calling retryTransition while retrying should be a no-op.
-	sm.retryTransition("")
+	sm.retryTransition("", nil, "")
 
-	// Steal the lock and wait long enough for the retry
-	// to fail, and then release it. The retry will have
-	// to keep retrying.
+	// Steal the lock and let the retry fail. The retry will
+	// have to keep retrying. The retry timer is pending along
+	// with the health check timer.
 	sm.transitioning.Acquire()
-	time.Sleep(30 * time.Millisecond)
+	fc.BlockUntil(2)
+	fc.Advance(transitionRetryInterval)
+	fc.BlockUntil(2)
 	sm.transitioning.Release()
 
+	// The next retry transitions, and the one after
+	// that sees that the state has converged.
+	fc.Advance(transitionRetryInterval)
+	for sm.State() != StateServing {
+		time.Sleep(time.Millisecond)
+	}
+	fc.BlockUntil(2)
+	fc.Advance(transitionRetryInterval)
+
 	for {
 		sm.mu.Lock()
 		retrying := sm.retrying
@@ -334,57 +690,195 @@ func TestStateManagerTransitionFailRetry(t *testing.T) {
 	assert.Equal(t, StateServing, sm.State())
 }
 
-func TestStateManagerNotConnectedType(t *testing.T) {
-	sm := newTestStateManager(t)
+func TestStateManagerStaleRetry(t *testing.T) {
+	fc := fakeclock.New(testNow)
+	sm := newTestStateManagerWithClock(t, fc)
 	defer sm.StopService()
-	sm.EnterLameduck()
-	err := sm.SetServingType(topodatapb.TabletType_RESTORE, testNow, StateNotServing, "")
-	require.NoError(t, err)
+	sm.se.(*testSchemaEngine).failMySQL = true
+	stale := sm.staleRetries.Get()
 
-	assert.Equal(t, topodatapb.TabletType_RESTORE, sm.target.TabletType)
-	assert.Equal(t, StateNotConnected, sm.state)
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.Error(t, err)
 
-	err = sm.SetServingType(topodatapb.TabletType_BACKUP, testNow, StateNotServing, "")
-	require.NoError(t, err)
+	// The tablet is demoted while the transition to master is retrying.
+	// The demotion waits for the semaphore, which we hold.
+	sm.transitioning.Acquire()
+	done := make(chan error)
+	go func() {
+		done <- sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	}()
+	assert.Eventually(t, func() bool {
+		sm.mu.Lock()
+		defer sm.mu.Unlock()
+		return sm.pendingRequests == 1
+	}, 5*time.Second, time.Millisecond)
 
-	assert.Equal(t, topodatapb.TabletType_BACKUP, sm.target.TabletType)
-	assert.Equal(t, StateNotConnected, sm.state)
+	// The retry doesn't attempt the stale goal.
+	fc.BlockUntil(2)
+	fc.Advance(transitionRetryInterval)
+	fc.BlockUntil(2)
+	assert.Equal(t, stale+1, sm.staleRetries.Get())
+
+	sm.se.(*testSchemaEngine).failMySQL = false
+	sm.transitioning.Release()
+	require.NoError(t, <-done)
+	assert.Equal(t, topodatapb.TabletType_REPLICA, sm.Target().TabletType)
+	assert.Equal(t, StateServing, sm.State())
+
+	// The next retry sees that the state has converged.
+	fc.Advance(transitionRetryInterval)
+	assert.Eventually(t, func() bool {
+		sm.mu.Lock()
+		defer sm.mu.Unlock()
+		return !sm.retrying
+	}, 5*time.Second, time.Millisecond)
+	assert.Equal(t, topodatapb.TabletType_REPLICA, sm.Target().TabletType)
+	// The tablet never served as a master.
+	assert.Equal(t, testStateNonMaster, sm.te.(*testTxEngine).State())
+	assert.Equal(t, stale+1, sm.staleRetries.Get())
+
+	// A retry that was scheduled before a request came in is dropped.
+	sm.transitioning.Acquire()
+	sm.mu.Lock()
+	sm.pendingRequests++
+	gen := sm.wantGeneration
+	sm.mu.Unlock()
+	sm.execRetry(gen, topodatapb.TabletType_MASTER, StateServing)
+	sm.mu.Lock()
+	sm.pendingRequests--
+	sm.mu.Unlock()
+	assert.False(t, sm.isTransitioning())
+	assert.Equal(t, topodatapb.TabletType_REPLICA, sm.Target().TabletType)
+	assert.Equal(t, stale+2, sm.staleRetries.Get())
 }
 
-func TestStateManagerCheckMySQL(t *testing.T) {
+func TestStateManagerWatchdog(t *testing.T) {
 	defer func(saved time.Duration) { transitionRetryInterval = saved }(transitionRetryInterval)
 	transitionRetryInterval = 10 * time.Millisecond
+	defer func(saved time.Duration) { transitionWatchdogInterval = saved }(transitionWatchdogInterval)
+	transitionWatchdogInterval = 10 * time.Millisecond
+	defer func(saved func(*hook.Hook) *hook.HookResult) { runStuckTransitionHook = saved }(runStuckTransitionHook)
+	hooks := make(chan *hook.Hook, 10)
+	runStuckTransitionHook = func(h *hook.Hook) *hook.HookResult {
+		hooks <- h
+		return &hook.HookResult{ExitStatus: hook.HOOK_SUCCESS}
+	}
 
 	sm := newTestStateManager(t)
 	defer sm.StopService()
+	sm.mu.Lock()
+	sm.stuckThreshold = 50 * time.Millisecond
+	sm.stuckHook = "stuck_transition"
+	sm.mu.Unlock()
+	stuck := sm.stuckTransitions.Get()
 
-	err := sm.SetServingType(topodatapb.TabletType_MASTER, testNow, StateServing, "")
-	require.NoError(t, err)
+	sm.se.(*testSchemaEngine).failMySQL = true
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.Error(t, err)
 
-	sm.qe.(*testQueryEngine).failMySQL = true
-	order.Set(0)
-	sm.CheckMySQL()
+	// Steal the lock to keep the retries from succeeding.
+	sm.transitioning.Acquire()
+	select {
+	case h := <-hooks:
+		assert.Equal(t, "stuck_transition", h.Name)
+		assert.Equal(t, "MASTER: Serving, "+testNow.Local().Format("Jan 2, 2006 at 15:04:05 (MST)"), h.ExtraEnv["WANT_TABLET_STATE"])
+	case <-time.After(5 * time.Second):
+		sm.transitioning.Release()
+		t.Fatal("stuck transition hook was not run")
+	}
+	assert.Equal(t, stuck+1, sm.stuckTransitions.Get())
 
-	// Rechecking immediately should be a no-op:
-	sm.CheckMySQL()
+	// The watchdog fires only once per stuck transition.
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, stuck+1, sm.stuckTransitions.Get())
+	assert.Len(t, hooks, 0)
 
-	// Wait for closeAll to get under way.
+	// And resets once the transition succeeds.
+	sm.transitioning.Release()
 	for {
-		if order.Get() >= 1 {
+		sm.mu.Lock()
+		reset := !sm.retrying && sm.stuckSince.IsZero() && !sm.stuckReported
+		sm.mu.Unlock()
+		if reset {
 			break
 		}
 		time.Sleep(10 * time.Millisecond)
 	}
+	assert.Equal(t, StateServing, sm.State())
+}
+
+func TestStateManagerTransitionPanic(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	panics := sm.transitionPanics.Get()
+
+	sm.te.(*testTxEngine).panicReadOnly = true
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "transition panicked: intentional panic")
+
+	assert.Equal(t, panics+1, sm.transitionPanics.Get())
+	assert.Equal(t, StateNotConnected, sm.State())
+	assert.Equal(t, NotConnectedByPanic, sm.notConnected)
+	assert.False(t, sm.retrying)
+	assert.Equal(t, err, sm.transitionErr)
+	assert.Equal(t, testStateClosed, sm.se.(*testSchemaEngine).State())
+	assertPanicRecorded(t, sm)
+
+	// A new request recovers.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.Equal(t, StateServing, sm.State())
+}
+
+func TestStateManagerRetryPanic(t *testing.T) {
+	defer func(saved time.Duration) { transitionRetryInterval = saved }(transitionRetryInterval)
+	transitionRetryInterval = 10 * time.Millisecond
+
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	panics := sm.transitionPanics.Get()
+
+	// The first attempt fails, and the retry panics.
+	sm.se.(*testSchemaEngine).failMySQL = true
+	sm.te.(*testTxEngine).panicReadOnly = true
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "intentional error")
 
-	// Wait to get out of transitioning state.
 	for {
-		if !sm.isTransitioning() {
+		sm.mu.Lock()
+		retrying := sm.retrying
+		sm.mu.Unlock()
+		if !retrying {
 			break
 		}
 		time.Sleep(10 * time.Millisecond)
 	}
+	assert.Equal(t, panics+1, sm.transitionPanics.Get())
+	assert.Equal(t, StateNotConnected, sm.State())
+	assertPanicRecorded(t, sm)
+
+	// Give the retry loop time to notice it was abandoned.
+	time.Sleep(50 * time.Millisecond)
+}
+
+func TestStateManagerOpenPanic(t *testing.T) {
+	defer func(saved time.Duration) { transitionRetryInterval = saved }(transitionRetryInterval)
+	transitionRetryInterval = 10 * time.Millisecond
+
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	panics := sm.transitionPanics.Get()
+	opens := sm.componentPanics.Counts()["se.Open"]
+
+	// The panic fails the transition, which is retried.
+	sm.se.(*testSchemaEngine).panicOpen = true
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "se.Open panicked: intentional panic")
+	assert.Equal(t, opens+1, sm.componentPanics.Counts()["se.Open"])
 
-	// Wait for retry to finish.
 	for {
 		sm.mu.Lock()
 		retrying := sm.retrying
@@ -394,185 +888,1479 @@ func TestStateManagerCheckMySQL(t *testing.T) {
 		}
 		time.Sleep(10 * time.Millisecond)
 	}
-
-	assert.Equal(t, topodatapb.TabletType_MASTER, sm.Target().TabletType)
 	assert.Equal(t, StateServing, sm.State())
+	assert.Equal(t, panics, sm.transitionPanics.Get())
 }
 
-func TestStateManagerValidations(t *testing.T) {
+func TestStateManagerClosePanic(t *testing.T) {
 	sm := newTestStateManager(t)
-	target := &querypb.Target{TabletType: topodatapb.TabletType_MASTER}
-	sm.target = *target
+	defer sm.StopService()
+	closes := sm.componentPanics.Counts()["messager.Close"]
 
-	err := sm.StartRequest(ctx, target, false)
-	assert.Contains(t, err.Error(), "operation not allowed")
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
 
-	sm.replHealthy = false
-	sm.state = StateServing
-	sm.wantState = StateServing
-	err = sm.StartRequest(ctx, target, false)
-	assert.Contains(t, err.Error(), "operation not allowed")
+	// The subcomponents are closed despite the panic,
+	// and the shutdown is reported as dirty.
+	sm.messager.(*testSubcomponent).panicClose = true
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateNotConnected, "")
+	require.NoError(t, err)
+	assert.Equal(t, StateNotConnected, sm.State())
+	assert.Equal(t, NotConnectedByOperator, sm.notConnected)
+	for _, component := range []interface{}{sm.throttler, sm.te, sm.tracker, sm.txThrottler, sm.qe, sm.watcher, sm.vstreamer, sm.rt, sm.se} {
+		assert.Equal(t, testStateClosed, component.(orderState).State(), "%T", component)
+	}
+	assert.Equal(t, closes+1, sm.componentPanics.Counts()["messager.Close"])
+	status := sm.Status()
+	assert.True(t, status.DirtyShutdown)
+	assert.Equal(t, "Close failed: messager.Close panicked: intentional panic", status.subcomponent("messager"))
+	assert.Contains(t, status.appendDetails(nil), &kv{
+		Key:   "Dirty Shutdown",
+		Class: unhappyClass,
+		Value: "subcomponents panicked while closing",
+	})
+
+	// The next clean shutdown clears it.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.True(t, sm.Status().DirtyShutdown)
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateNotConnected, "")
+	require.NoError(t, err)
+	assert.False(t, sm.Status().DirtyShutdown)
+}
 
-	sm.replHealthy = true
-	sm.state = StateServing
-	sm.wantState = StateNotServing
-	err = sm.StartRequest(ctx, target, false)
-	assert.Contains(t, err.Error(), "operation not allowed")
+func TestStateManagerCrashOnPanic(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	sm.crashOnPanic = true
 
-	err = sm.StartRequest(ctx, target, true)
-	assert.NoError(t, err)
+	sm.se.(*testSchemaEngine).panicOpen = true
+	assert.PanicsWithValue(t, "intentional panic", func() {
+		_ = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	})
+	assert.False(t, sm.isTransitioning())
+}
 
-	sm.wantState = StateServing
-	target.Keyspace = "a"
-	err = sm.StartRequest(ctx, target, false)
-	assert.Contains(t, err.Error(), "invalid keyspace")
-	err = sm.VerifyTarget(ctx, target)
-	assert.Contains(t, err.Error(), "invalid keyspace")
+func assertPanicRecorded(t *testing.T, sm *stateManager) {
+	t.Helper()
+	for _, rec := range sm.hs.history.Records() {
+		if r := rec.(*historyRecord); r.err != nil && strings.Contains(r.err.Error(), "transition panicked") {
+			return
+		}
+	}
+	t.Errorf("panic not recorded in history: %v", sm.hs.history.Records())
+}
 
-	target.Keyspace = ""
-	target.Shard = "a"
-	err = sm.StartRequest(ctx, target, false)
-	assert.Contains(t, err.Error(), "invalid shard")
-	err = sm.VerifyTarget(ctx, target)
-	assert.Contains(t, err.Error(), "invalid shard")
+func TestStateManagerDemotionDrain(t *testing.T) {
+	defer func(saved time.Duration) { drainBroadcastInterval = saved }(drainBroadcastInterval)
+	drainBroadcastInterval = 10 * time.Millisecond
+	defer func(saved time.Duration) { slowDrainThreshold = saved }(slowDrainThreshold)
+	slowDrainThreshold = 0
 
-	target.Shard = ""
-	target.TabletType = topodatapb.TabletType_REPLICA
-	err = sm.StartRequest(ctx, target, false)
-	assert.Contains(t, err.Error(), "invalid tablet type")
-	err = sm.VerifyTarget(ctx, target)
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	ch, cancel := testStream(sm.hs)
+	defer cancel()
+
+	te := sm.te.(*testTxEngine)
+	te.drain = make(chan struct{})
+	te.remaining.Set(2)
+	done := make(chan error)
+	go func() {
+		done <- sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	}()
+
+	// The tablet is still a master while it drains.
+	for shr := range ch {
+		if shr.RealtimeStats.TransitionStatus != "" {
+			assert.Equal(t, "draining transactions: 2 remaining", shr.RealtimeStats.TransitionStatus)
+			assert.Equal(t, topodatapb.TabletType_MASTER, shr.Target.TabletType)
+			break
+		}
+	}
+	// The slow drain is reported once.
+	for te.listed.Get() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(te.drain)
+	require.NoError(t, <-done)
+	assert.Equal(t, topodatapb.TabletType_REPLICA, sm.Target().TabletType)
+	assert.EqualValues(t, 1, te.listed.Get())
+	assert.EqualValues(t, 1, te.listedReserved.Get())
+
+	sm.Broadcast()
+	for shr := range ch {
+		if shr.Target.TabletType == topodatapb.TabletType_REPLICA {
+			assert.Empty(t, shr.RealtimeStats.TransitionStatus)
+			break
+		}
+	}
+}
+
+func TestStateManagerDemotionKillQueries(t *testing.T) {
+	defer func(saved time.Duration) { drainBroadcastInterval = saved }(drainBroadcastInterval)
+	drainBroadcastInterval = 10 * time.Millisecond
+
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	sm.queryKillGracePeriod = 50 * time.Millisecond
+	qe := sm.qe.(*testQueryEngine)
+
+	// Queries are killed while the transactions are drained.
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	te := sm.te.(*testTxEngine)
+	te.drain = make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	}()
+	for qe.killed.Get() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(te.drain)
+	require.NoError(t, <-done)
+
+	// Queries are killed if in-flight requests hold up the demotion.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	qe.killed.Set(0)
+	target := &querypb.Target{TabletType: topodatapb.TabletType_MASTER}
+	require.NoError(t, sm.StartRequest(ctx, target, false))
+	var once sync.Once
+	qe.onKill = func(olderThan time.Duration) {
+		assert.Equal(t, 50*time.Millisecond, olderThan)
+		once.Do(sm.EndRequest)
+	}
+	start := time.Now()
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateNotServing, "")
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(50*time.Millisecond))
+	assert.NotZero(t, qe.killed.Get())
+	assert.Equal(t, topodatapb.TabletType_REPLICA, sm.Target().TabletType)
+	qe.onKill = nil
+
+	// Queries are not killed if the tablet is not demoted.
+	sm.queryKillGracePeriod = 10 * time.Millisecond
+	qe.killed.Set(0)
+	target = &querypb.Target{TabletType: topodatapb.TabletType_REPLICA}
+	require.NoError(t, sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, ""))
+	require.NoError(t, sm.StartRequest(ctx, target, false))
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		sm.EndRequest()
+	}()
+	require.NoError(t, sm.SetServingType(context.Background(), topodatapb.TabletType_RDONLY, testNow, StateNotServing, ""))
+	assert.Zero(t, qe.killed.Get())
+}
+
+func TestStateManagerDemotionPausesHeartbeat(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	rt := sm.rt.(*testReplTracker)
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.Zero(t, rt.pausedAfter)
+
+	// The writes are paused before any subcomponent is touched.
+	before := order.Get()
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.Equal(t, before, rt.pausedAfter)
+	verifySubcomponent(t, before+1, sm.throttler, testStateClosed)
+
+	// Transitions of a non-master don't pause them.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_RDONLY, testNow, StateNotServing, "")
+	require.NoError(t, err)
+	assert.Equal(t, before, rt.pausedAfter)
+
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	before = order.Get()
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateNotServing, "")
+	require.NoError(t, err)
+	assert.Equal(t, before, rt.pausedAfter)
+	verifySubcomponent(t, before+1, sm.throttler, testStateClosed)
+}
+
+func TestStateManagerTopoIsolation(t *testing.T) {
+	defer func(saved time.Duration) { topoIsolationCheckInterval = saved }(topoIsolationCheckInterval)
+	topoIsolationCheckInterval = 10 * time.Millisecond
+
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	assert.Zero(t, sm.topoIsolationRemaining())
+	sm.topoIsolationTimeout = time.Minute
+	sm.topoTicks.Start(sm.checkTopoIsolation)
+	isolations := sm.topoIsolations.Get()
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.Greater(t, int64(sm.topoIsolationRemaining()), int64(50*time.Second))
+	assert.Contains(t, detailKeys(sm), "Topo Isolation")
+
+	isolate := func() {
+		t.Helper()
+		sm.mu.Lock()
+		sm.topoLastSeen = time.Now().Add(-2 * time.Minute)
+		sm.mu.Unlock()
+		for sm.IsServing() {
+			time.Sleep(10 * time.Millisecond)
+		}
+		for sm.State() != StateNotServing {
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.Equal(t, topodatapb.TabletType_MASTER, sm.Target().TabletType)
+		assert.Equal(t, topoIsolationReason, sm.reason)
+	}
+	isolate()
+	assert.Equal(t, isolations+1, sm.topoIsolations.Get())
+	assert.Zero(t, sm.topoIsolationRemaining())
+
+	// Requests to serve are overridden until the topo is reachable.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.Equal(t, StateNotServing, sm.State())
+	sm.SetTopoLastSeenHealthy(time.Now().Add(-2 * time.Minute))
+	assert.Equal(t, StateNotServing, sm.State())
+
+	sm.SetTopoLastSeenHealthy(time.Now())
+	assert.Equal(t, StateServing, sm.State())
+	assert.True(t, sm.IsServing())
+	assert.Empty(t, sm.reason)
+
+	// An operator can resume serving without the topo.
+	isolate()
+	assert.Equal(t, isolations+2, sm.topoIsolations.Get())
+	require.NoError(t, sm.AcknowledgeTopoIsolation())
+	assert.Equal(t, StateServing, sm.State())
+	err = sm.AcknowledgeTopoIsolation()
+	assert.EqualError(t, err, "tablet is not isolated from the topo")
+
+	// A demotion ends the isolation.
+	isolate()
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.Equal(t, StateServing, sm.State())
+	assert.False(t, sm.topoIsolated)
+}
+
+func detailKeys(sm *stateManager) []string {
+	var keys []string
+	for _, detail := range sm.ApppendDetails(nil) {
+		keys = append(keys, detail.Key)
+	}
+	return keys
+}
+
+func TestStateManagerNotConnectedType(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	sm.EnterLameduck()
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_RESTORE, testNow, StateNotServing, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, topodatapb.TabletType_RESTORE, sm.target.TabletType)
+	assert.Equal(t, StateNotConnected, sm.state)
+
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_BACKUP, testNow, StateNotServing, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, topodatapb.TabletType_BACKUP, sm.target.TabletType)
+	assert.Equal(t, StateNotConnected, sm.state)
+}
+
+func TestStateManagerNotConnectedTypeKeepVStreamer(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	sm.keepVStreamerOnBackup = true
+	sm.EnterLameduck()
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_RESTORE, testNow, StateNotServing, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, topodatapb.TabletType_RESTORE, sm.target.TabletType)
+	assert.Equal(t, StateNotConnected, sm.state)
+
+	// A tablet that wasn't connected opens se and vstreamer.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_BACKUP, testNow, StateNotServing, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, topodatapb.TabletType_BACKUP, sm.target.TabletType)
+	assert.Equal(t, StateNotServing, sm.state)
+	assert.Equal(t, "backup in progress", sm.reason)
+	assert.Equal(t, testStateOpen, sm.se.(orderState).State())
+	assert.Equal(t, testStateOpen, sm.vstreamer.(orderState).State())
+	assert.Equal(t, testStateClosed, sm.qe.(orderState).State())
+
+	// A serving replica keeps them open, and closes everything else.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	vs := sm.vstreamer.(*testVStreamer)
+	vs.streams = 1
+	seOrder, vsOrder := sm.se.(orderState).Order(), vs.Order()
+
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_BACKUP, testNow, StateNotServing, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, topodatapb.TabletType_BACKUP, sm.target.TabletType)
+	assert.Equal(t, StateNotServing, sm.state)
+	verifySubcomponent(t, seOrder, sm.se, testStateOpen)
+	verifySubcomponent(t, vsOrder, sm.vstreamer, testStateOpen)
+	assert.Equal(t, 1, vs.ActiveStreams())
+	for _, c := range []interface{}{sm.qe, sm.txThrottler, sm.te, sm.watcher, sm.rt, sm.tracker, sm.messager, sm.throttler} {
+		assert.Equal(t, testStateClosed, c.(orderState).State())
+	}
+	err = sm.StartRequest(ctx, &sm.target, false)
+	assert.EqualError(t, err, "operation not allowed in state NOT_SERVING (tablet type: BACKUP, state: Not Serving, want: Not Serving, reason: backup in progress)")
+
+	ch, cancel := testStream(sm.hs)
+	defer cancel()
+	<-ch
+	sm.Broadcast()
+	var shr *querypb.StreamHealthResponse
+	for shr = <-ch; shr.RealtimeStats.HealthError == ""; shr = <-ch {
+	}
+	assert.False(t, shr.Serving)
+	assert.Equal(t, "not serving: backup in progress", shr.RealtimeStats.HealthError)
+
+	// The replica serves again with the se and vstreamer it kept.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.True(t, sm.IsServing())
+	assert.Equal(t, testStateOpen, sm.vstreamer.(orderState).State())
+	assert.Equal(t, 1, vs.ActiveStreams())
+	assert.Equal(t, testStateNonMaster, sm.rt.(orderState).State())
+	assert.Equal(t, testStateOpen, sm.watcher.(orderState).State())
+}
+
+func TestStateManagerCheckMySQL(t *testing.T) {
+	defer func(saved time.Duration) { transitionRetryInterval = saved }(transitionRetryInterval)
+	transitionRetryInterval = 10 * time.Millisecond
+
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	sm.qe.(*testQueryEngine).failMySQL = true
+	order.Set(0)
+	sm.CheckMySQL()
+
+	// Rechecking immediately should be a no-op:
+	sm.CheckMySQL()
+
+	// Wait for closeAll to get under way.
+	for {
+		if order.Get() >= 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Wait to get out of transitioning state.
+	for {
+		if !sm.isTransitioning() {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Wait for retry to finish.
+	for {
+		sm.mu.Lock()
+		retrying := sm.retrying
+		sm.mu.Unlock()
+		if !retrying {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	assert.Equal(t, topodatapb.TabletType_MASTER, sm.Target().TabletType)
+	assert.Equal(t, StateServing, sm.State())
+}
+
+func TestStateManagerValidations(t *testing.T) {
+	sm := newTestStateManager(t)
+	target := &querypb.Target{TabletType: topodatapb.TabletType_MASTER}
+	sm.target = *target
+	sm.initialized = true
+
+	err := sm.StartRequest(ctx, target, false)
+	assert.EqualError(t, err, "operation not allowed in state NOT_SERVING (tablet type: MASTER, state: Not connected to mysql, want: Not connected to mysql)")
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+
+	// Transition in progress.
+	sm.wantState = StateServing
+	err = sm.StartRequest(ctx, target, false)
+	assert.EqualError(t, err, "operation not allowed in state NOT_SERVING (tablet type: MASTER, state: Not connected to mysql, want: Serving)")
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	sm.retryableRequestErrors = true
+	err = sm.StartRequest(ctx, target, false)
+	assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err))
+	sm.retryableRequestErrors = false
+
+	sm.replHealthy = false
+	sm.state = StateServing
+	sm.wantState = StateServing
+	sm.replLag = 3 * time.Hour
+	err = sm.StartRequest(ctx, target, false)
+	assert.EqualError(t, err, "operation not allowed in state NOT_SERVING: replication is unhealthy: lag 3h0m0s exceeds the unhealthy threshold (tablet type: MASTER, state: Serving, want: Serving)")
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	sm.retryableRequestErrors = true
+	err = sm.StartRequest(ctx, target, false)
+	assert.EqualError(t, err, "operation not allowed: replication is unhealthy: lag 3h0m0s exceeds the unhealthy threshold (tablet type: MASTER, state: Serving, want: Serving)")
+	assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err))
+	sm.retryableRequestErrors = false
+	sm.replErr = errors.New("replication stopped")
+	err = sm.StartRequest(ctx, target, false)
+	assert.Contains(t, err.Error(), "replication is unhealthy: replication stopped")
+	sm.replErr = nil
+
+	sm.replHealthy = true
+	sm.state = StateServing
+	sm.wantState = StateNotServing
+	err = sm.StartRequest(ctx, target, false)
+	assert.Contains(t, err.Error(), "operation not allowed in state SHUTTING_DOWN")
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+
+	err = sm.StartRequest(ctx, target, true)
+	assert.NoError(t, err)
+
+	sm.reason = "planned reparent"
+	err = sm.StartRequest(ctx, target, false)
+	assert.EqualError(t, err, "operation not allowed in state SHUTTING_DOWN (tablet type: MASTER, state: Serving, want: Not Serving, reason: planned reparent)")
+	sm.state = StateNotServing
+	err = sm.StartRequest(ctx, target, false)
+	assert.EqualError(t, err, "operation not allowed in state NOT_SERVING (tablet type: MASTER, state: Not Serving, want: Not Serving, reason: planned reparent)")
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	sm.state = StateServing
+	sm.reason = ""
+
+	sm.wantState = StateServing
+	target.Keyspace = "a"
+	err = sm.StartRequest(ctx, target, false)
+	assert.Contains(t, err.Error(), "invalid keyspace")
+	assert.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err))
+	err = sm.VerifyTarget(ctx, target)
+	assert.Contains(t, err.Error(), "invalid keyspace")
+
+	target.Keyspace = ""
+	target.Shard = "a"
+	err = sm.StartRequest(ctx, target, false)
+	assert.Contains(t, err.Error(), "invalid shard")
+	assert.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err))
+	err = sm.VerifyTarget(ctx, target)
+	assert.Contains(t, err.Error(), "invalid shard")
+
+	target.Shard = ""
+	target.TabletType = topodatapb.TabletType_REPLICA
+	err = sm.StartRequest(ctx, target, false)
+	assert.Contains(t, err.Error(), "invalid tablet type")
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	err = sm.VerifyTarget(ctx, target)
+	assert.Contains(t, err.Error(), "invalid tablet type")
+
+	sm.alsoAllow = []topodatapb.TabletType{topodatapb.TabletType_REPLICA}
+	err = sm.StartRequest(ctx, target, false)
+	assert.NoError(t, err)
+	err = sm.VerifyTarget(ctx, target)
+	assert.NoError(t, err)
+
+	err = sm.StartRequest(ctx, nil, false)
+	assert.Contains(t, err.Error(), "No target")
+	assert.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err))
+	err = sm.VerifyTarget(ctx, nil)
+	assert.Contains(t, err.Error(), "No target")
+
+	localctx := tabletenv.LocalContext()
+	err = sm.StartRequest(localctx, nil, false)
+	assert.NoError(t, err)
+	err = sm.VerifyTarget(localctx, nil)
+	assert.NoError(t, err)
+}
+
+func TestStateManagerAllowOnShutdown(t *testing.T) {
+	testcases := []struct {
+		state           servingState
+		wantState       servingState
+		replHealthy     bool
+		allowOnShutdown bool
+		// code is OK if the request is admitted.
+		code vtrpcpb.Code
+		err  string
+	}{
+		{StateServing, StateServing, true, false, vtrpcpb.Code_OK, ""},
+		{StateServing, StateServing, true, true, vtrpcpb.Code_OK, ""},
+		{StateServing, StateServing, false, false, vtrpcpb.Code_UNAVAILABLE, "replication is unhealthy"},
+		{StateServing, StateServing, false, true, vtrpcpb.Code_OK, ""},
+		{StateServing, StateNotServing, true, false, vtrpcpb.Code_FAILED_PRECONDITION, "operation not allowed in state SHUTTING_DOWN"},
+		{StateServing, StateNotServing, true, true, vtrpcpb.Code_OK, ""},
+		{StateServing, StateNotServing, false, false, vtrpcpb.Code_UNAVAILABLE, "replication is unhealthy"},
+		{StateServing, StateNotServing, false, true, vtrpcpb.Code_OK, ""},
+		{StateNotServing, StateServing, true, false, vtrpcpb.Code_UNAVAILABLE, "operation not allowed in state NOT_SERVING"},
+		{StateNotServing, StateServing, true, true, vtrpcpb.Code_UNAVAILABLE, "operation not allowed in state NOT_SERVING"},
+		{StateNotServing, StateNotServing, true, false, vtrpcpb.Code_FAILED_PRECONDITION, "operation not allowed in state NOT_SERVING"},
+		{StateNotServing, StateNotServing, true, true, vtrpcpb.Code_FAILED_PRECONDITION, "operation not allowed in state NOT_SERVING"},
+	}
+	sm := newTestStateManager(t)
+	target := &querypb.Target{TabletType: topodatapb.TabletType_MASTER}
+	sm.target = *target
+	sm.initialized = true
+	sm.retryableRequestErrors = true
+	// Lameduck makes no difference.
+	for _, lameduck := range []bool{false, true} {
+		for _, tcase := range testcases {
+			name := fmt.Sprintf("lameduck: %v, state: %v, want: %v, replHealthy: %v, allowOnShutdown: %v", lameduck, tcase.state, tcase.wantState, tcase.replHealthy, tcase.allowOnShutdown)
+			sm.lameduck = lameduck
+			sm.state = tcase.state
+			sm.wantState = tcase.wantState
+			sm.replHealthy = tcase.replHealthy
+			err := sm.StartRequest(ctx, target, tcase.allowOnShutdown)
+			if tcase.code == vtrpcpb.Code_OK {
+				if assert.NoError(t, err, name) {
+					sm.EndRequest()
+				}
+				continue
+			}
+			assert.Equal(t, tcase.code, vterrors.Code(err), name)
+			assert.Contains(t, err.Error(), tcase.err, name)
+		}
+	}
+
+	// The requests allowed on shutdown still verify the target.
+	sm.state = StateServing
+	err := sm.StartRequest(ctx, &querypb.Target{TabletType: topodatapb.TabletType_REPLICA}, true)
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
 	assert.Contains(t, err.Error(), "invalid tablet type")
+}
+
+func TestStateManagerTargetAliases(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	sm.target = querypb.Target{Keyspace: "ks", Shard: "-80", TabletType: topodatapb.TabletType_REPLICA}
+	sm.state = StateServing
+	sm.wantState = StateServing
+	sm.replHealthy = true
+	sm.initialized = true
+
+	oldTarget := &querypb.Target{Keyspace: "oldks", Shard: "0", TabletType: topodatapb.TabletType_REPLICA}
+	err := sm.VerifyTarget(ctx, oldTarget)
+	assert.EqualError(t, err, "invalid keyspace oldks does not match expected ks")
+
+	values := sm.live.Values()
+	values.KeyspaceAliases = map[string]string{"oldks": "ks", "otherks": "other"}
+	values.ShardAliases = map[string]string{"0": "-80"}
+	require.NoError(t, sm.live.Reload("test", values))
+
+	require.NoError(t, sm.StartRequest(ctx, oldTarget, false))
+	sm.EndRequest()
+	require.NoError(t, sm.VerifyTarget(ctx, oldTarget))
+	assert.Equal(t, map[string]int64{"keyspace.oldks": 2, "shard.0": 2}, sm.targetAliasHits.Counts())
+
+	// The target itself doesn't change.
+	assert.Equal(t, querypb.Target{Keyspace: "ks", Shard: "-80", TabletType: topodatapb.TabletType_REPLICA}, sm.Target())
+
+	// An alias of another keyspace isn't accepted.
+	err = sm.StartRequest(ctx, &querypb.Target{Keyspace: "otherks", Shard: "-80", TabletType: topodatapb.TabletType_REPLICA}, false)
+	assert.EqualError(t, err, "invalid keyspace otherks does not match expected ks")
+
+	// Nor are the aliases once they're removed.
+	values.KeyspaceAliases = nil
+	require.NoError(t, sm.live.Reload("test", values))
+	err = sm.StartRequest(ctx, oldTarget, false)
+	assert.EqualError(t, err, "invalid keyspace oldks does not match expected ks")
+	err = sm.StartRequest(ctx, &querypb.Target{Keyspace: "ks", Shard: "0", TabletType: topodatapb.TabletType_REPLICA}, false)
+	require.NoError(t, err)
+	sm.EndRequest()
+}
+
+func TestStateManagerCreateDB(t *testing.T) {
+	fc := fakeclock.New(testNow)
+	sm := newTestStateManagerWithClock(t, fc)
+	defer sm.StopService()
+	se := sm.se.(*testSchemaEngine)
+	te := sm.te.(*testTxEngine)
+
+	// The database gets created, but a later step fails.
+	se.dbMissing = true
+	te.failSidecar = true
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.Error(t, err)
+	assert.Equal(t, []bool{true}, se.createDBCalls)
+	assert.NotEqual(t, testStateMaster, te.state)
+
+	// The retry doesn't create the database again, and creates
+	// the sidecar tables before opening the tx engine.
+	fc.BlockUntil(2)
+	fc.Advance(transitionRetryInterval)
+	for sm.State() != StateServing {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, []bool{true, false}, se.createDBCalls)
+	assert.Equal(t, testStateMaster, te.state)
+	assert.NotZero(t, te.sidecarOrder)
+	assert.Less(t, te.sidecarOrder, te.order)
+
+	// Non-masters don't create the database, and a new
+	// master intent is allowed to.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow.Add(time.Second), StateServing, "")
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, false, true}, se.createDBCalls)
+}
+
+func TestStateManagerTimebomb(t *testing.T) {
+	defer func(saved func()) { timebombCrash = saved }(timebombCrash)
+	crashed := make(chan struct{}, 1)
+	timebombCrash = func() {
+		crashed <- struct{}{}
+	}
+
+	fc := fakeclock.New(testNow)
+	sm := newTestStateManagerWithClock(t, fc)
+	defer sm.StopService()
+	sm.timebombDuration = 10 * time.Second
+
+	// A shutdown that completes in time defuses the timebomb.
+	done := sm.setTimeBomb()
+	fc.BlockUntil(1)
+	close(done)
+	for fc.Pending() != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	fc.Advance(time.Minute)
+	assert.Len(t, crashed, 0)
+
+	done = sm.setTimeBomb()
+	defer close(done)
+	fc.BlockUntil(1)
+	fc.Advance(9 * time.Second)
+	assert.Len(t, crashed, 0)
+	fc.Advance(time.Second)
+	<-crashed
+}
+
+func TestStateManagerWaitForRequests(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	target := &querypb.Target{TabletType: topodatapb.TabletType_MASTER}
+	sm.target = *target
+	sm.timebombDuration = 10 * time.Second
+
+	sm.replHealthy = true
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	err = sm.StartRequest(ctx, target, false)
+	require.NoError(t, err)
+
+	// This will go into transition and wait.
+	// Wait for that state.
+	go sm.StopService()
+	for {
+		if !sm.isTransitioning() {
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+		break
+	}
+
+	// Verify that we're still transitioning.
+	assert.True(t, sm.isTransitioning())
+
+	sm.EndRequest()
+
+	for {
+		if sm.isTransitioning() {
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+		break
+	}
+	assert.Equal(t, StateNotConnected, sm.State())
+}
+
+func TestStateManagerNotify(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+
+	blpFunc = testBlpFunc
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	ch := make(chan *querypb.StreamHealthResponse, 5)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		err := sm.hs.Stream(context.Background(), func(shr *querypb.StreamHealthResponse) error {
+			ch <- shr
+			return nil
+		})
+		assert.Contains(t, err.Error(), "tabletserver is shutdown")
+	}()
+	defer wg.Wait()
+
+	sm.Broadcast()
+
+	gotshr := <-ch
+	// Remove things we don't care about:
+	gotshr.RealtimeStats = nil
+	wantshr := &querypb.StreamHealthResponse{
+		Target: &querypb.Target{
+			TabletType: topodatapb.TabletType_REPLICA,
+		},
+		Serving:             true,
+		TabletAlias:         &topodatapb.TabletAlias{},
+		AcceptedTabletTypes: []topodatapb.TabletType{topodatapb.TabletType_REPLICA},
+	}
+	sm.hcticks.Stop()
+	assert.Equal(t, wantshr, gotshr)
+	sm.StopService()
+}
+
+func TestStateManagerWatcherStatus(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	sm.watcher.(*testBinlogWatcher).secondsBehind = 3
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	ch, cancel := testStream(sm.hs)
+	defer cancel()
+	<-ch
+	sm.Broadcast()
+	var shr *querypb.StreamHealthResponse
+	for shr = <-ch; shr.RealtimeStats.WatcherState != "running"; shr = <-ch {
+	}
+	assert.EqualValues(t, 3, shr.RealtimeStats.WatcherSecondsBehind)
+	assert.Equal(t, WatcherStatus{State: "running", SecondsBehind: 3}, sm.Status().Watcher)
+
+	// Masters close the watcher, and don't report what it last saw.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	sm.Broadcast()
+	for shr = <-ch; shr.RealtimeStats.WatcherState != "n/a"; shr = <-ch {
+	}
+	assert.Zero(t, shr.RealtimeStats.WatcherSecondsBehind)
+	assert.Equal(t, WatcherStatus{State: "n/a"}, sm.Status().Watcher)
+}
+
+func TestRefreshReplHealthLocked(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	rt := sm.rt.(*testReplTracker)
+
+	sm.target.TabletType = topodatapb.TabletType_MASTER
+	sm.replHealthy = false
+	lag, err := sm.refreshReplHealthLocked()
+	assert.Equal(t, time.Duration(0), lag)
+	assert.NoError(t, err)
+	assert.True(t, sm.replHealthy)
+
+	sm.target.TabletType = topodatapb.TabletType_REPLICA
+	sm.replHealthy = false
+	lag, err = sm.refreshReplHealthLocked()
+	assert.Equal(t, 1*time.Second, lag)
+	assert.NoError(t, err)
+	assert.True(t, sm.replHealthy)
+
+	rt.err = errors.New("err")
+	sm.replHealthy = true
+	lag, err = sm.refreshReplHealthLocked()
+	assert.Equal(t, 1*time.Second, lag)
+	assert.Error(t, err)
+	assert.False(t, sm.replHealthy)
+
+	rt.err = nil
+	rt.lag = 3 * time.Hour
+	sm.replHealthy = true
+	lag, err = sm.refreshReplHealthLocked()
+	assert.Equal(t, 3*time.Hour, lag)
+	assert.NoError(t, err)
+	assert.False(t, sm.replHealthy)
+
+	rt.lag = 0
+	rt.crossCheckErr = errors.New("replication stopped (lag unreliable)")
+	sm.replHealthy = true
+	lag, err = sm.refreshReplHealthLocked()
+	assert.Equal(t, time.Duration(0), lag)
+	assert.EqualError(t, err, "replication stopped (lag unreliable)")
+	assert.False(t, sm.replHealthy)
+}
+
+func TestStateManagerCheckReplicationLag(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	sm.target.Keyspace = "ks"
+
+	sm.target.TabletType = topodatapb.TabletType_REPLICA
+	sm.mu.Lock()
+	_, err := sm.refreshReplHealthLocked()
+	sm.mu.Unlock()
+	require.NoError(t, err)
+
+	assert.NoError(t, sm.CheckReplicationLag(nil))
+	assert.NoError(t, sm.CheckReplicationLag(&querypb.ExecuteOptions{}))
+	assert.NoError(t, sm.CheckReplicationLag(&querypb.ExecuteOptions{MaxReplicationLagMs: 1000}))
+	err = sm.CheckReplicationLag(&querypb.ExecuteOptions{MaxReplicationLagMs: 500})
+	assert.EqualError(t, err, "replication lag 1s exceeds the max of 500ms requested")
+	assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err))
+	assert.Equal(t, int64(1), sm.replLagRejections.Counts()["ks"])
+
+	sm.target.TabletType = topodatapb.TabletType_MASTER
+	assert.NoError(t, sm.CheckReplicationLag(&querypb.ExecuteOptions{MaxReplicationLagMs: 500}))
+	assert.Equal(t, int64(1), sm.replLagRejections.Counts()["ks"])
+}
+
+func TestStateManagerTransitionTimings(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	ctx := context.Background()
+	sm.processStart = time.Now().Add(-2 * time.Second)
+	// The timings are shared by the tests.
+	toMaster := "StateManagerTest." + sm.Target().TabletType.String() + ".MASTER"
+	toReplica := "StateManagerTest.MASTER.REPLICA"
+	before := sm.transitionTimings.Counts()
+
+	require.NoError(t, sm.SetServingType(ctx, topodatapb.TabletType_MASTER, testNow, StateServing, ""))
+	require.NoError(t, sm.SetServingType(ctx, topodatapb.TabletType_REPLICA, testNow, StateServing, ""))
+	// A call that doesn't transition isn't recorded.
+	require.NoError(t, sm.SetServingType(ctx, topodatapb.TabletType_REPLICA, testNow, StateServing, ""))
+	after := sm.transitionTimings.Counts()
+	assert.Equal(t, before["All"]+2, after["All"])
+	assert.Equal(t, before[toMaster]+1, after[toMaster])
+	assert.Equal(t, before[toReplica]+1, after[toReplica])
+
+	// Only the first serving is recorded.
+	assert.Equal(t, int64(1), sm.timeToFirstServing.Count())
+	assert.Equal(t, int64(1), sm.timeToFirstServing.Counts()["5000"])
+}
+
+func TestStateManagerTerTimestampRegression(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	ctx := context.Background()
+	older := testNow.Add(-time.Minute)
+
+	// ignore is the default.
+	require.NoError(t, sm.SetServingType(ctx, topodatapb.TabletType_MASTER, testNow, StateServing, ""))
+	require.NoError(t, sm.SetServingType(ctx, topodatapb.TabletType_MASTER, older, StateServing, ""))
+	assert.Equal(t, older, sm.terTimestamp)
+	assert.Empty(t, sm.terRegressions.Counts())
+
+	sm.terRegression = tabletenv.Warn
+	require.NoError(t, sm.SetServingType(ctx, topodatapb.TabletType_MASTER, older.Add(-time.Minute), StateServing, ""))
+	assert.Equal(t, older.Add(-time.Minute), sm.terTimestamp)
+	assert.Equal(t, map[string]int64{"warn": 1}, sm.terRegressions.Counts())
+
+	sm.terRegression = tabletenv.Reject
+	require.NoError(t, sm.SetServingType(ctx, topodatapb.TabletType_MASTER, testNow, StateServing, ""))
+	err := sm.SetServingType(ctx, topodatapb.TabletType_MASTER, older, StateNotServing, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "transition to MASTER rejected: its timestamp")
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	assert.Equal(t, map[string]int64{"warn": 1, "reject": 1}, sm.terRegressions.Counts())
+	// The rejected transition changed nothing.
+	assert.Equal(t, testNow, sm.terTimestamp)
+	assert.Equal(t, StateServing, sm.wantState)
+	assert.Equal(t, StateServing, sm.state)
+
+	// Zero timestamps and the other tablet types are never compared.
+	require.NoError(t, sm.SetServingType(ctx, topodatapb.TabletType_MASTER, testNow, StateServing, ""))
+	require.NoError(t, sm.SetServingType(ctx, topodatapb.TabletType_MASTER, time.Time{}, StateServing, ""))
+	require.NoError(t, sm.SetServingType(ctx, topodatapb.TabletType_MASTER, older, StateServing, ""))
+	require.NoError(t, sm.SetServingType(ctx, topodatapb.TabletType_REPLICA, older.Add(-time.Minute), StateServing, ""))
+	assert.Equal(t, map[string]int64{"warn": 1, "reject": 1}, sm.terRegressions.Counts())
+}
+
+func TestStateManagerShedLoad(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	rt := sm.rt.(*testReplTracker)
+	ctx := context.Background()
+	target := &querypb.Target{TabletType: topodatapb.TabletType_REPLICA}
+
+	values := sm.live.Values()
+	values.DegradedThreshold = 10 * time.Second
+	values.UnhealthyThreshold = 20 * time.Second
+	require.NoError(t, sm.live.Reload("test", values))
+	err := sm.SetServingType(ctx, topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	var draw float64
+	sm.shedRand = func() float64 { return draw }
+
+	// Load shedding is opt-in.
+	rt.lag = 15 * time.Second
+	sm.Broadcast()
+	assert.Zero(t, sm.shedFraction)
+	require.NoError(t, sm.StartRequest(ctx, target, false))
+	sm.EndRequest()
+
+	sm.shedMaxFraction = 0.5
+	rt.lag = 5 * time.Second
+	sm.Broadcast()
+	assert.Zero(t, sm.shedFraction)
+	assert.Empty(t, sm.hs.state.RealtimeStats.TransitionStatus)
+
+	// Halfway between the thresholds, a quarter of the requests are shed.
+	rt.lag = 15 * time.Second
+	sm.Broadcast()
+	assert.Equal(t, 0.25, sm.shedFraction)
+	assert.Equal(t, "shedding 25% of the requests: replication lag 15s is degraded", sm.hs.state.RealtimeStats.TransitionStatus)
+	assert.Equal(t, int64(25), sm.shedGauge())
+
+	draw = 0.3
+	require.NoError(t, sm.StartRequest(ctx, target, false))
+	sm.EndRequest()
+	draw = 0.2
+	err = sm.StartRequest(ctx, target, false)
+	assert.EqualError(t, err, "request shed: replication lag 15s is degraded, shedding 25% of the requests (tablet type: REPLICA, state: Serving, want: Serving)")
+	assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err))
+	assert.Equal(t, int64(1), sm.requestsShed.Get())
+
+	// The requests that end transactions are never shed.
+	require.NoError(t, sm.StartRequest(ctx, target, true))
+	sm.EndRequest()
+	assert.Equal(t, int64(1), sm.requestsShed.Get())
+
+	// Past the unhealthy threshold, the tablet stops serving instead.
+	rt.lag = 25 * time.Second
+	sm.Broadcast()
+	assert.Zero(t, sm.shedFraction)
+	err = sm.StartRequest(ctx, target, false)
+	assert.Contains(t, err.Error(), "replication is unhealthy")
+
+	// Masters never shed requests.
+	rt.lag = 15 * time.Second
+	sm.Broadcast()
+	assert.Equal(t, 0.25, sm.shedFraction)
+	err = sm.SetServingType(ctx, topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	require.NoError(t, sm.StartRequest(ctx, &querypb.Target{TabletType: topodatapb.TabletType_MASTER}, false))
+	sm.EndRequest()
+	sm.Broadcast()
+	assert.Zero(t, sm.shedFraction)
+	assert.Equal(t, int64(1), sm.requestsShed.Get())
+}
+
+func TestRefreshReplHealthLagSource(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	rt := sm.rt.(*testReplTracker)
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	rt.source = repltracker.LagSourceHeartbeat
+	sm.Broadcast()
+	assert.Equal(t, "heartbeat", sm.hs.state.RealtimeStats.LagSource)
+	assert.Equal(t, "heartbeat", sm.Status().LagSource)
+
+	// The replica stays healthy on the fallback.
+	rt.source = repltracker.LagSourceFallback
+	sm.Broadcast()
+	assert.True(t, sm.replHealthy)
+	assert.Empty(t, sm.hs.state.RealtimeStats.HealthError)
+	assert.Equal(t, "replica status (heartbeat fallback)", sm.hs.state.RealtimeStats.LagSource)
+	details := sm.hs.ApppendDetails(nil)
+	require.NotEmpty(t, details)
+	assert.Equal(t, "1s (replica status (heartbeat fallback))", details[0].Value)
+
+	// If the fallback fails too, the health error mentions it.
+	rt.err = errors.New("heartbeat failed: err, and the replica status fallback failed: err")
+	sm.Broadcast()
+	assert.False(t, sm.replHealthy)
+	assert.Contains(t, sm.hs.state.RealtimeStats.HealthError, "replica status fallback failed")
+
+	rt.err = nil
+	sm.target.TabletType = topodatapb.TabletType_MASTER
+	sm.Broadcast()
+	assert.Empty(t, sm.hs.state.RealtimeStats.LagSource)
+	assert.Empty(t, sm.Status().LagSource)
+}
+
+func TestRefreshReplHealthNotConfigured(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	rt := sm.rt.(*testReplTracker)
+	rt.err = repltracker.ErrNotConfigured
+
+	// A replica is unhealthy if replication is not configured.
+	sm.target.TabletType = topodatapb.TabletType_REPLICA
+	sm.replHealthy = true
+	_, err := sm.refreshReplHealthLocked()
+	assert.Equal(t, repltracker.ErrNotConfigured, err)
+	assert.False(t, sm.replHealthy)
+
+	// Unless it's allowed to serve without replication.
+	sm.serveWithoutReplication = true
+	lag, err := sm.refreshReplHealthLocked()
+	assert.Equal(t, time.Duration(0), lag)
+	assert.NoError(t, err)
+	assert.True(t, sm.replHealthy)
+
+	// A master is always healthy.
+	sm.serveWithoutReplication = false
+	sm.target.TabletType = topodatapb.TabletType_MASTER
+	sm.replHealthy = false
+	_, err = sm.refreshReplHealthLocked()
+	assert.NoError(t, err)
+	assert.True(t, sm.replHealthy)
+}
+
+func TestStateManagerRefreshReplHealth(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	rt := sm.rt.(*testReplTracker)
+	refreshes := sm.replHealthRefreshes.Get()
+
+	sm.hs.Open()
+	ch, cancel := testStream(sm.hs)
+	defer cancel()
+	<-ch
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	shr := <-ch
+	assert.True(t, shr.Serving)
+
+	// A change in serving status is broadcast.
+	rt.lag = 3 * time.Hour
+	err = sm.RefreshReplHealth()
+	require.NoError(t, err)
+	assert.False(t, sm.IsServing())
+	shr = <-ch
+	assert.False(t, shr.Serving)
+	assert.Equal(t, uint32(3*time.Hour/time.Second), shr.RealtimeStats.SecondsBehindMaster)
+
+	// No change, no broadcast.
+	err = sm.RefreshReplHealth()
+	require.NoError(t, err)
+	select {
+	case shr := <-ch:
+		t.Errorf("unexpected broadcast: %v", shr)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	rt.lag = time.Second
+	err = sm.RefreshReplHealth()
+	require.NoError(t, err)
+	assert.True(t, sm.IsServing())
+	shr = <-ch
+	assert.True(t, shr.Serving)
+	assert.Equal(t, refreshes+3, sm.replHealthRefreshes.Get())
+
+	// Refreshes are rejected during a transition.
+	sm.transitioning.Acquire()
+	err = sm.RefreshReplHealth()
+	sm.transitioning.Release()
+	assert.EqualError(t, err, "cannot refresh replication health: a state transition is in progress")
+	assert.Equal(t, refreshes+3, sm.replHealthRefreshes.Get())
+}
+
+func TestStateManagerPoolUsage(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+
+	qe := sm.qe.(*testQueryEngine)
+	qe.inUse, qe.capacity = 5, 20
+	te := sm.te.(*testTxEngine)
+	te.inUse, te.capacity = 3, 10
+
+	want := poolUsage{
+		queryInUse:    5,
+		queryCapacity: 20,
+		txInUse:       3,
+		txCapacity:    10,
+	}
+	assert.Equal(t, want, sm.poolUsage())
+
+	sm.Broadcast()
+	sm.hs.mu.Lock()
+	defer sm.hs.mu.Unlock()
+	stats := sm.hs.state.RealtimeStats
+	assert.Equal(t, int64(5), stats.QueryPoolInUse)
+	assert.Equal(t, int64(20), stats.QueryPoolCapacity)
+	assert.Equal(t, int64(3), stats.TransactionPoolInUse)
+	assert.Equal(t, int64(10), stats.TransactionPoolCapacity)
+}
+
+func TestStateManagerInitializing(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+
+	sm.hs.Open()
+	ch, cancel := testStream(sm.hs)
+	defer cancel()
+	<-ch
+
+	// The broadcasts before the first SetServingType
+	// report that the tablet is initializing.
+	sm.Broadcast()
+	shr := <-ch
+	assert.False(t, shr.Serving)
+	assert.Equal(t, "initializing", shr.RealtimeStats.HealthError)
+	assert.Nil(t, shr.AcceptedTabletTypes)
+	status := sm.Status()
+	assert.True(t, status.Initializing)
+	var initializing *kv
+	for _, detail := range status.appendDetails(nil) {
+		if detail.Key == "Initializing" {
+			initializing = detail
+		}
+	}
+	require.NotNil(t, initializing)
+	assert.Equal(t, unhappyClass, initializing.Class)
+
+	err := sm.StartRequest(ctx, &querypb.Target{}, false)
+	assert.EqualError(t, err, "operation not allowed in state NOT_SERVING: tablet server not initialized (tablet type: UNKNOWN, state: Not connected to mysql, want: Not connected to mysql)")
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	sm.retryableRequestErrors = true
+	err = sm.StartRequest(ctx, &querypb.Target{}, false)
+	assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err))
+	sm.retryableRequestErrors = false
+
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	shr = <-ch
+	assert.True(t, shr.Serving)
+	assert.Empty(t, shr.RealtimeStats.HealthError)
+	assert.False(t, sm.Status().Initializing)
+	target := &querypb.Target{TabletType: topodatapb.TabletType_REPLICA}
+	require.NoError(t, sm.StartRequest(ctx, target, false))
+	sm.EndRequest()
+
+	var events []string
+	for _, rec := range sm.hs.history.Records() {
+		if event := rec.(*historyRecord).event; event != "" {
+			events = append(events, event)
+		}
+	}
+	require.Len(t, events, 1)
+	assert.Contains(t, events[0], "initialized")
+}
+
+func TestStateManagerReadiness(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+
+	ps, ok := sm.Readiness()
+	assert.False(t, ok)
+	assert.Equal(t, "initializing", ps.Reason)
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	sm.Broadcast()
+	ps, ok = sm.Readiness()
+	assert.True(t, ok)
+	want := probeState{
+		State:      "Serving",
+		WantState:  "Serving",
+		TabletType: "REPLICA",
+		Lag:        1,
+	}
+	assert.Equal(t, want, ps)
+
+	// Lameduck must flip readiness immediately.
+	sm.EnterLameduck()
+	ps, ok = sm.Readiness()
+	assert.False(t, ok)
+	assert.Equal(t, "lameduck", ps.Reason)
+	sm.ExitLameduck()
 
-	sm.alsoAllow = []topodatapb.TabletType{topodatapb.TabletType_REPLICA}
-	err = sm.StartRequest(ctx, target, false)
-	assert.NoError(t, err)
-	err = sm.VerifyTarget(ctx, target)
-	assert.NoError(t, err)
+	rt := sm.rt.(*testReplTracker)
+	rt.err = errors.New("repl err")
+	sm.Broadcast()
+	ps, ok = sm.Readiness()
+	assert.False(t, ok)
+	assert.Equal(t, "repl err", ps.Reason)
 
-	err = sm.StartRequest(ctx, nil, false)
-	assert.Contains(t, err.Error(), "No target")
-	err = sm.VerifyTarget(ctx, nil)
-	assert.Contains(t, err.Error(), "No target")
+	rt.err = nil
+	rt.lag = 3 * time.Hour
+	sm.Broadcast()
+	ps, ok = sm.Readiness()
+	assert.False(t, ok)
+	assert.Equal(t, "replication lag 3h0m0s exceeds unhealthy threshold", ps.Reason)
 
-	localctx := tabletenv.LocalContext()
-	err = sm.StartRequest(localctx, nil, false)
-	assert.NoError(t, err)
-	err = sm.VerifyTarget(localctx, nil)
-	assert.NoError(t, err)
+	rt.lag = 1 * time.Second
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateNotServing, "drained")
+	require.NoError(t, err)
+	sm.Broadcast()
+	ps, ok = sm.Readiness()
+	assert.False(t, ok)
+	assert.Equal(t, "drained", ps.Reason)
 }
 
-func TestStateManagerWaitForRequests(t *testing.T) {
+func TestStateManagerLiveness(t *testing.T) {
 	sm := newTestStateManager(t)
 	defer sm.StopService()
-	target := &querypb.Target{TabletType: topodatapb.TabletType_MASTER}
-	sm.target = *target
-	sm.timebombDuration = 10 * time.Second
+	sm.livenessThreshold = 10 * time.Millisecond
 
-	sm.replHealthy = true
-	err := sm.SetServingType(topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	_, ok := sm.Liveness()
+	assert.True(t, ok)
+
+	sm.mu.Lock()
+	sm.transitionStart = time.Now()
+	sm.mu.Unlock()
+	_, ok = sm.Liveness()
+	assert.True(t, ok)
+
+	time.Sleep(20 * time.Millisecond)
+	ps, ok := sm.Liveness()
+	assert.False(t, ok)
+	assert.Contains(t, ps.Reason, "state transition in progress for")
+
+	// A completed transition clears the start time.
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
 	require.NoError(t, err)
+	_, ok = sm.Liveness()
+	assert.True(t, ok)
+}
 
-	err = sm.StartRequest(ctx, target, false)
+func TestStateManagerSchemaNotifications(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	se := sm.se.(*testSchemaEngine)
+
+	// On a master, the tracker is open and reloads the schema.
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
 	require.NoError(t, err)
+	verifyOpenedAfter(t, sm.tracker, testStateOpen, sm.se)
+	assert.Contains(t, se.notifiers, hsNotifierName)
 
-	// This will go into transition and wait.
-	// Wait for that state.
-	go sm.StopService()
-	for {
-		if !sm.isTransitioning() {
-			time.Sleep(10 * time.Millisecond)
-			continue
-		}
-		break
-	}
+	// On a replica, the tracker is closed and the watcher reloads the schema.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.Equal(t, testStateClosed, sm.tracker.(orderState).State())
+	assert.Equal(t, testStateOpen, sm.watcher.(orderState).State())
+	assert.Contains(t, se.notifiers, hsNotifierName)
 
-	// Verify that we're still transitioning.
-	assert.True(t, sm.isTransitioning())
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateNotConnected, "")
+	require.NoError(t, err)
+	assert.NotContains(t, se.notifiers, hsNotifierName)
+}
 
-	sm.EndRequest()
+func TestStateManagerNotConnectedState(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+
+	ncs, ok := sm.NotConnectedState()
+	assert.True(t, ok)
+	assert.Equal(t, NotConnectedNeverServed, ncs)
+	assert.Equal(t, "NOT_CONNECTED (NeverServed)", sm.DetailedStateString())
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	_, ok = sm.NotConnectedState()
+	assert.False(t, ok)
+	assert.Equal(t, "SERVING", sm.DetailedStateString())
+
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateNotConnected, "")
+	require.NoError(t, err)
+	ncs, ok = sm.NotConnectedState()
+	assert.True(t, ok)
+	assert.Equal(t, NotConnectedByOperator, ncs)
+	ps, _ := sm.Readiness()
+	assert.Equal(t, "ClosedByOperator", ps.NotConnected)
+
+	sm.StopService()
+	ncs, ok = sm.NotConnectedState()
+	assert.True(t, ok)
+	assert.Equal(t, NotConnectedShuttingDown, ncs)
+	assert.Equal(t, "NOT_CONNECTED (ShuttingDown)", sm.DetailedStateString())
+}
+
+func TestStateManagerNotConnectedByMySQLFailure(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
 
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	sm.qe.(*testQueryEngine).failMySQL = true
+	sm.CheckMySQL()
+
+	// Wait for closeAll to complete. The retry happens
+	// only after transitionRetryInterval.
 	for {
-		if sm.isTransitioning() {
-			time.Sleep(10 * time.Millisecond)
-			continue
+		if _, ok := sm.NotConnectedState(); ok {
+			break
 		}
-		break
+		time.Sleep(10 * time.Millisecond)
 	}
-	assert.Equal(t, StateNotConnected, sm.State())
+	ncs, _ := sm.NotConnectedState()
+	assert.Equal(t, NotConnectedByMySQLFailure, ncs)
+	assert.Equal(t, "NOT_CONNECTED (ClosedByMySQLFailure)", sm.DetailedStateString())
+
+	ps, ok := sm.Readiness()
+	assert.False(t, ok)
+	assert.Equal(t, "not connected: ClosedByMySQLFailure", ps.Reason)
+
+	details := sm.ApppendDetails(nil)
+	assert.Contains(t, details, &kv{Key: "Not Connected", Class: unhealthyClass, Value: "ClosedByMySQLFailure"})
+
+	sm.Broadcast()
+	latest := sm.hs.history.Latest().(*historyRecord)
+	assert.Equal(t, "ClosedByMySQLFailure", latest.notConnected)
+	assert.Equal(t, "not connected: ClosedByMySQLFailure", latest.Status())
 }
 
-func TestStateManagerNotify(t *testing.T) {
+func TestStateManagerTransitionSpans(t *testing.T) {
 	sm := newTestStateManager(t)
 	defer sm.StopService()
+	tracer := &testTracer{}
+	sm.newSpan = tracer.newSpan
 
-	blpFunc = testBlpFunc
+	rpcSpan, rpcCtx := tracer.newSpan(context.Background(), "rpc")
+	err := sm.SetServingType(rpcCtx, topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	rpcSpan.Finish()
+
+	transition := tracer.find("stateManager.transition")
+	require.NotNil(t, transition)
+	assert.Equal(t, rpcSpan, transition.parent)
+	assert.True(t, transition.finished)
+	assert.Equal(t, map[string]interface{}{
+		"tablet_type":  "MASTER",
+		"target_state": "Serving",
+		"reason":       "",
+	}, transition.tags)
+	// Some of the operations run concurrently.
+	assert.ElementsMatch(t, []string{
+		"stateManager.watcher.Close",
+		"stateManager.se.EnsureConnectionAndDB",
+		"stateManager.se.Open",
+		"stateManager.vstreamer.Open",
+		"stateManager.qe.Open",
+		"stateManager.txThrottler.Open",
+		"stateManager.se.WaitWritable",
+		"stateManager.rt.MakeMaster",
+		"stateManager.qe.CreateSidecarTables",
+		"stateManager.tracker.Open",
+		"stateManager.txEngine.Prepare",
+		"stateManager.txEngine.Open",
+		"stateManager.messager.Open",
+		"stateManager.throttler.Open",
+	}, tracer.children(transition))
+
+	// The wait for requests gets its span, and errors are recorded.
+	tracer.reset()
+	sm.se.(*testSchemaEngine).failMySQL = true
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateNotServing, "demotion")
+	require.Error(t, err)
+	transition = tracer.find("stateManager.transition")
+	require.NotNil(t, transition)
+	assert.Nil(t, transition.parent)
+	assert.Equal(t, "demotion", transition.tags["reason"])
+	assert.Contains(t, transition.tags["error"], "intentional")
+	assert.Contains(t, tracer.children(transition), "stateManager.requests.Wait")
+	connect := tracer.find("stateManager.se.EnsureConnectionAndDB")
+	require.NotNil(t, connect)
+	assert.Equal(t, "intentional error", connect.tags["error"])
+}
+
+func TestStateManagerCheckMySQLSpan(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	tracer := &testTracer{}
 
-	err := sm.SetServingType(topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
 	require.NoError(t, err)
+	sm.newSpan = tracer.newSpan
 
-	ch := make(chan *querypb.StreamHealthResponse, 5)
-	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		err := sm.hs.Stream(context.Background(), func(shr *querypb.StreamHealthResponse) error {
-			ch <- shr
-			return nil
-		})
-		assert.Contains(t, err.Error(), "tabletserver is shutdown")
-	}()
-	defer wg.Wait()
+	sm.qe.(*testQueryEngine).failMySQL = true
+	sm.CheckMySQL()
+	var span *testSpan
+	for {
+		if span = tracer.find("stateManager.CheckMySQL"); span != nil && span.isFinished() {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Nil(t, span.parent)
+	assert.Equal(t, "intentional error", span.tags["mysql_error"])
+	assert.Contains(t, tracer.children(span), "stateManager.qe.Close")
+}
 
-	sm.Broadcast()
+// testTracer records the spans created by stateManager.newSpan.
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*testSpan
+}
 
-	gotshr := <-ch
-	// Remove things we don't care about:
-	gotshr.RealtimeStats = nil
-	wantshr := &querypb.StreamHealthResponse{
-		Target: &querypb.Target{
-			TabletType: topodatapb.TabletType_REPLICA,
-		},
-		Serving:     true,
-		TabletAlias: &topodatapb.TabletAlias{},
+type testSpanKey struct{}
+
+type testSpan struct {
+	tracer   *testTracer
+	label    string
+	parent   *testSpan
+	tags     map[string]interface{}
+	finished bool
+}
+
+func (tt *testTracer) newSpan(ctx context.Context, label string) (trace.Span, context.Context) {
+	parent, _ := ctx.Value(testSpanKey{}).(*testSpan)
+	span := &testSpan{tracer: tt, label: label, parent: parent, tags: make(map[string]interface{})}
+	tt.mu.Lock()
+	tt.spans = append(tt.spans, span)
+	tt.mu.Unlock()
+	return span, context.WithValue(ctx, testSpanKey{}, span)
+}
+
+func (tt *testTracer) find(label string) *testSpan {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	for _, span := range tt.spans {
+		if span.label == label {
+			return span
+		}
 	}
-	sm.hcticks.Stop()
-	assert.Equal(t, wantshr, gotshr)
-	sm.StopService()
+	return nil
 }
 
-func TestRefreshReplHealthLocked(t *testing.T) {
-	sm := newTestStateManager(t)
-	defer sm.StopService()
-	rt := sm.rt.(*testReplTracker)
+func (tt *testTracer) children(parent *testSpan) []string {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	var labels []string
+	for _, span := range tt.spans {
+		if span.parent == parent {
+			labels = append(labels, span.label)
+		}
+	}
+	return labels
+}
 
-	sm.target.TabletType = topodatapb.TabletType_MASTER
-	sm.replHealthy = false
-	lag, err := sm.refreshReplHealthLocked()
-	assert.Equal(t, time.Duration(0), lag)
-	assert.NoError(t, err)
-	assert.True(t, sm.replHealthy)
+func (tt *testTracer) reset() {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	tt.spans = nil
+}
 
-	sm.target.TabletType = topodatapb.TabletType_REPLICA
-	sm.replHealthy = false
-	lag, err = sm.refreshReplHealthLocked()
-	assert.Equal(t, 1*time.Second, lag)
-	assert.NoError(t, err)
-	assert.True(t, sm.replHealthy)
+func (span *testSpan) Finish() {
+	span.tracer.mu.Lock()
+	defer span.tracer.mu.Unlock()
+	span.finished = true
+}
 
-	rt.err = errors.New("err")
-	sm.replHealthy = true
-	lag, err = sm.refreshReplHealthLocked()
-	assert.Equal(t, 1*time.Second, lag)
-	assert.Error(t, err)
-	assert.False(t, sm.replHealthy)
+func (span *testSpan) Annotate(key string, value interface{}) {
+	span.tracer.mu.Lock()
+	defer span.tracer.mu.Unlock()
+	span.tags[key] = value
+}
 
-	rt.err = nil
-	rt.lag = 3 * time.Hour
-	sm.replHealthy = true
-	lag, err = sm.refreshReplHealthLocked()
-	assert.Equal(t, 3*time.Hour, lag)
-	assert.NoError(t, err)
-	assert.False(t, sm.replHealthy)
+func (span *testSpan) isFinished() bool {
+	span.tracer.mu.Lock()
+	defer span.tracer.mu.Unlock()
+	return span.finished
 }
 
 func verifySubcomponent(t *testing.T, order int64, component interface{}, state testState) {
@@ -581,7 +2369,34 @@ func verifySubcomponent(t *testing.T, order int64, component interface{}, state
 	assert.Equal(t, state, tos.State())
 }
 
+// verifyOpenedAfter verifies the state of component, and that it
+// was opened after deps.
+func verifyOpenedAfter(t *testing.T, component interface{}, state testState, deps ...interface{}) {
+	t.Helper()
+	tos := component.(orderState)
+	assert.Equal(t, state, tos.State())
+	for _, dep := range deps {
+		assert.Greater(t, tos.Order(), dep.(orderState).Order(), "%T opened before %T", component, dep)
+	}
+}
+
+// verifyConnected verifies that the components opened by connect
+// were opened after last, in the order of their dependencies.
+func verifyConnected(t *testing.T, sm *stateManager, last interface{}) {
+	t.Helper()
+	verifyOpenedAfter(t, sm.se, testStateOpen, last)
+	verifyOpenedAfter(t, sm.vstreamer, testStateOpen, sm.se)
+	verifyOpenedAfter(t, sm.qe, testStateOpen, sm.se)
+	verifyOpenedAfter(t, sm.txThrottler, testStateOpen, last)
+}
+
 func newTestStateManager(t *testing.T) *stateManager {
+	return newTestStateManagerWithClock(t, nil)
+}
+
+// newTestStateManagerWithClock returns a stateManager that uses
+// clock for its timers. If clock is nil, the real clock is used.
+func newTestStateManagerWithClock(t *testing.T, clock timer.Clock) *stateManager {
 	order.Set(0)
 	config := tabletenv.NewDefaultConfig()
 	env := tabletenv.NewEnv(config, "StateManagerTest")
@@ -589,16 +2404,17 @@ func newTestStateManager(t *testing.T) *stateManager {
 		hs:          newHealthStreamer(env, topodatapb.TabletAlias{}),
 		se:          &testSchemaEngine{},
 		rt:          &testReplTracker{lag: 1 * time.Second},
-		vstreamer:   &testSubcomponent{},
+		vstreamer:   &testVStreamer{},
 		tracker:     &testSubcomponent{},
-		watcher:     &testSubcomponent{},
+		watcher:     &testBinlogWatcher{},
 		qe:          &testQueryEngine{},
 		txThrottler: &testTxThrottler{},
 		te:          &testTxEngine{},
 		messager:    &testSubcomponent{},
 		throttler:   &testLagThrottler{},
+		clock:       clock,
 	}
-	sm.Init(env, querypb.Target{})
+	require.NoError(t, sm.Init(env, querypb.Target{}))
 	sm.hs.InitDBConfig(querypb.Target{})
 	log.Infof("returning sm: %p", sm)
 	return sm
@@ -646,20 +2462,74 @@ type testSchemaEngine struct {
 	testOrderState
 	ensureCalled bool
 	nonMaster    bool
+	notifiers    map[string]schema.Notifier
 
 	failMySQL bool
+	panicOpen bool
+	// hangOpen makes the next Open block until its context is done,
+	// like an open against a hung mysql.
+	hangOpen bool
+	// identity is returned by ServerIdentity.
+	identity schema.ServerIdentity
+
+	// If dbMissing is set, EnsureConnectionAndDB fails unless it's
+	// allowed to create the database. createDBCalls records the
+	// createDB argument of the calls.
+	dbMissing     bool
+	createDBCalls []bool
+
+	// IsReadOnly reports mysql as read-only for the
+	// first readOnlyChecks calls.
+	readOnlyChecks int
+
+	// ReloadTables notifies the changed tables as altered.
+	// If reloadStarted is set, it signals it and waits for
+	// reloadProceed before returning.
+	changed       []string
+	reloads       sync2.AtomicInt32
+	reloadStarted chan struct{}
+	reloadProceed chan struct{}
 }
 
-func (te *testSchemaEngine) EnsureConnectionAndDB(tabletType topodatapb.TabletType) error {
+func (te *testSchemaEngine) EnsureConnectionAndDB(ctx context.Context, createDB bool) (bool, error) {
 	if te.failMySQL {
 		te.failMySQL = false
-		return errors.New("intentional error")
+		return false, errors.New("intentional error")
 	}
 	te.ensureCalled = true
-	return nil
+	te.createDBCalls = append(te.createDBCalls, createDB)
+	if te.dbMissing {
+		if !createDB {
+			return false, errors.New("unknown database")
+		}
+		te.dbMissing = false
+		return true, nil
+	}
+	return false, nil
 }
 
-func (te *testSchemaEngine) Open() error {
+func (te *testSchemaEngine) ServerIdentity() schema.ServerIdentity {
+	return te.identity
+}
+
+func (te *testSchemaEngine) IsReadOnly() (bool, error) {
+	if te.readOnlyChecks > 0 {
+		te.readOnlyChecks--
+		return true, nil
+	}
+	return false, nil
+}
+
+func (te *testSchemaEngine) Open(ctx context.Context) error {
+	if te.panicOpen {
+		te.panicOpen = false
+		panic("intentional panic")
+	}
+	if te.hangOpen {
+		te.hangOpen = false
+		<-ctx.Done()
+		return ctx.Err()
+	}
 	te.order = order.Add(1)
 	te.state = testStateOpen
 	return nil
@@ -669,6 +2539,29 @@ func (te *testSchemaEngine) MakeNonMaster() {
 	te.nonMaster = true
 }
 
+func (te *testSchemaEngine) ReloadTables(ctx context.Context) ([]string, error) {
+	te.reloads.Add(1)
+	if te.reloadStarted != nil {
+		te.reloadStarted <- struct{}{}
+		<-te.reloadProceed
+	}
+	for _, f := range te.notifiers {
+		f(nil, nil, te.changed, nil)
+	}
+	return te.changed, nil
+}
+
+func (te *testSchemaEngine) RegisterNotifier(name string, f schema.Notifier) {
+	if te.notifiers == nil {
+		te.notifiers = make(map[string]schema.Notifier)
+	}
+	te.notifiers[name] = f
+}
+
+func (te *testSchemaEngine) UnregisterNotifier(name string) {
+	delete(te.notifiers, name)
+}
+
 func (te *testSchemaEngine) Close() {
 	te.order = order.Add(1)
 	te.state = testStateClosed
@@ -676,8 +2569,16 @@ func (te *testSchemaEngine) Close() {
 
 type testReplTracker struct {
 	testOrderState
-	lag time.Duration
-	err error
+	lag           time.Duration
+	source        repltracker.LagSource
+	err           error
+	crossCheckErr error
+	// pausedAfter is the order of the last operation
+	// before the writes were paused, if they were.
+	pausedAfter int64
+
+	roleStatus repltracker.RoleStatus
+	roleErr    error
 }
 
 func (te *testReplTracker) MakeMaster() {
@@ -690,33 +2591,80 @@ func (te *testReplTracker) MakeNonMaster() {
 	te.state = testStateNonMaster
 }
 
+// PauseWrites doesn't count as an operation: it only records
+// the last operation that happened before it.
+func (te *testReplTracker) PauseWrites() {
+	te.pausedAfter = order.Get()
+}
+
 func (te *testReplTracker) Close() {
 	te.order = order.Add(1)
 	te.state = testStateClosed
 }
 
-func (te *testReplTracker) Status() (time.Duration, error) {
-	return te.lag, te.err
+func (te *testReplTracker) Status() (time.Duration, repltracker.LagSource, error) {
+	return te.lag, te.source, te.err
+}
+
+func (te *testReplTracker) CrossCheck(lag time.Duration) error {
+	return te.crossCheckErr
+}
+
+func (te *testReplTracker) RoleStatus() (repltracker.RoleStatus, error) {
+	return te.roleStatus, te.roleErr
 }
 
 type testQueryEngine struct {
 	testOrderState
 	stopServing bool
 
+	inUse, capacity int64
+
 	failMySQL bool
+	// failWrites makes the write checks fail until it's cleared.
+	failWrites sync2.AtomicBool
+	// reachable replaces the mysql checks if it's set.
+	reachable func(checkWrites bool) error
+	// connErrorTables is returned by ConnErrorTables.
+	connErrorTables int
+
+	// killed counts the calls to KillActiveQueries, and
+	// onKill is invoked by them if set.
+	killed sync2.AtomicInt32
+	onKill func(olderThan time.Duration)
+
+	// pressures records the modes pushed by SetPressureMode.
+	pressures []pressureMode
+	// consolidatorMode is the mode set by SetConsolidatorMode.
+	consolidatorMode string
+
+	// warmups counts the calls to WarmPlans, and warmPlans
+	// is invoked by them if set.
+	warmups   int
+	warmPlans func(ctx context.Context) (int, error)
 }
 
-func (te *testQueryEngine) Open() error {
+func (te *testQueryEngine) Open(ctx context.Context) error {
 	te.order = order.Add(1)
 	te.state = testStateOpen
 	return nil
 }
 
-func (te *testQueryEngine) IsMySQLReachable() error {
+func (te *testQueryEngine) CreateSidecarTables(ctx context.Context) error {
+	return nil
+}
+
+func (te *testQueryEngine) IsMySQLReachable(ctx context.Context, checkWrites bool) error {
+	if te.reachable != nil {
+		return te.reachable(checkWrites)
+	}
 	if te.failMySQL {
 		te.failMySQL = false
 		return errors.New("intentional error")
 	}
+	if checkWrites && te.failWrites.Get() {
+		return &mysqlWriteError{err: errors.New("disk full")}
+	}
 	return nil
 }
 
@@ -724,22 +2672,94 @@ func (te *testQueryEngine) StopServing() {
 	te.stopServing = true
 }
 
+func (te *testQueryEngine) KillActiveQueries(olderThan time.Duration, reason string) {
+	te.killed.Add(1)
+	if te.onKill != nil {
+		te.onKill(olderThan)
+	}
+}
+
 func (te *testQueryEngine) Close() {
 	te.order = order.Add(1)
 	te.state = testStateClosed
 }
 
+func (te *testQueryEngine) SetConsolidatorMode(mode string) {
+	te.consolidatorMode = mode
+}
+
+func (te *testQueryEngine) SetPressureMode(mode pressureMode) {
+	te.pressures = append(te.pressures, mode)
+}
+
+func (te *testQueryEngine) WarmPlans(ctx context.Context) (int, error) {
+	te.warmups++
+	if te.warmPlans != nil {
+		return te.warmPlans(ctx)
+	}
+	return 0, nil
+}
+
+func (te *testQueryEngine) PoolUsage() (int64, int64) {
+	return te.inUse, te.capacity
+}
+
+func (te *testQueryEngine) ConnErrorTables() int {
+	return te.connErrorTables
+}
+
 type testTxEngine struct {
 	testOrderState
+
+	inUse, capacity int64
+
+	// If drain is set, AcceptReadOnly reports draining
+	// until drain is closed.
+	drain     chan struct{}
+	remaining sync2.AtomicInt64
+	draining  sync2.AtomicBool
+
+	listed         sync2.AtomicInt32
+	listedReserved sync2.AtomicInt32
+
+	// sidecarOrder is the value of order when the sidecar
+	// tables were created. failSidecar fails the next creation.
+	sidecarOrder int64
+	failSidecar  bool
+
+	// panicReadOnly makes the next AcceptReadOnly panic.
+	panicReadOnly bool
+
+	unresolved    int64
+	unresolvedAge time.Duration
+	unresolvedErr error
 }
 
-func (te *testTxEngine) AcceptReadWrite() error {
+func (te *testTxEngine) CreateSidecarTables() error {
+	if te.failSidecar {
+		te.failSidecar = false
+		return errors.New("intentional error")
+	}
+	te.sidecarOrder = order.Get()
+	return nil
+}
+
+func (te *testTxEngine) AcceptReadWrite(ctx context.Context) error {
 	te.order = order.Add(1)
 	te.state = testStateMaster
 	return nil
 }
 
 func (te *testTxEngine) AcceptReadOnly() error {
+	if te.panicReadOnly {
+		te.panicReadOnly = false
+		panic("intentional panic")
+	}
+	if te.drain != nil {
+		te.draining.Set(true)
+		<-te.drain
+		te.draining.Set(false)
+	}
 	te.order = order.Add(1)
 	te.state = testStateNonMaster
 	return nil
@@ -750,20 +2770,73 @@ func (te *testTxEngine) Close() {
 	te.state = testStateClosed
 }
 
+func (te *testTxEngine) PoolUsage() (int64, int64) {
+	return te.inUse, te.capacity
+}
+
+func (te *testTxEngine) Draining() (int64, bool) {
+	return te.remaining.Get(), te.draining.Get()
+}
+
+func (te *testTxEngine) OpenTransactions() []OpenTransaction {
+	te.listed.Add(1)
+	return nil
+}
+
+func (te *testTxEngine) ReservedConnections() []ReservedConnection {
+	te.listedReserved.Add(1)
+	return nil
+}
+
+func (te *testTxEngine) UnresolvedPrepares() (int64, time.Duration, error) {
+	return te.unresolved, te.unresolvedAge, te.unresolvedErr
+}
+
 type testSubcomponent struct {
 	testOrderState
+
+	// If set, Open sleeps for sleep.
+	sleep time.Duration
+	// panicClose makes the next Close panic.
+	panicClose bool
 }
 
 func (te *testSubcomponent) Open() {
+	time.Sleep(te.sleep)
 	te.order = order.Add(1)
 	te.state = testStateOpen
 }
 
 func (te *testSubcomponent) Close() {
+	if te.panicClose {
+		te.panicClose = false
+		panic("intentional panic")
+	}
 	te.order = order.Add(1)
 	te.state = testStateClosed
 }
 
+type testVStreamer struct {
+	testSubcomponent
+	streams int
+}
+
+func (te *testVStreamer) ActiveStreams() int {
+	return te.streams
+}
+
+type testBinlogWatcher struct {
+	testSubcomponent
+	secondsBehind int64
+}
+
+func (te *testBinlogWatcher) Status() WatcherStatus {
+	if te.state != testStateOpen {
+		return WatcherStatus{State: "n/a"}
+	}
+	return WatcherStatus{State: "running", SecondsBehind: te.secondsBehind}
+}
+
 type testTxThrottler struct {
 	testOrderState
 }
@@ -781,6 +2854,8 @@ func (te *testTxThrottler) Close() {
 
 type testLagThrottler struct {
 	testOrderState
+	checkedApps []string
+	closes      int
 }
 
 func (te *testLagThrottler) Open() error {
@@ -790,6 +2865,12 @@ func (te *testLagThrottler) Open() error {
 }
 
 func (te *testLagThrottler) Close() {
+	te.closes++
 	te.order = order.Add(1)
 	te.state = testStateClosed
 }
+
+func (te *testLagThrottler) CheckByApp(ctx context.Context, appName string) *throttle.CheckResult {
+	te.checkedApps = append(te.checkedApps, appName)
+	return throttle.NewCheckResult(http.StatusOK, 0, 1, nil)
+}
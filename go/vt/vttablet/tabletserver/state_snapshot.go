@@ -0,0 +1,182 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// stateSnapshotVersion must be incremented every time the
+// layout of stateSnapshot changes incompatibly.
+const stateSnapshotVersion = 1
+
+// stateSnapshot contains the restorable subset of the stateManager
+// variables. It allows a new process to resume serving in the state
+// the previous one left off without waiting for the tablet manager
+// to re-derive it.
+type stateSnapshot struct {
+	Version        int                     `json:"version"`
+	Time           time.Time               `json:"time"`
+	Keyspace       string                  `json:"keyspace"`
+	Shard          string                  `json:"shard"`
+	TabletType     topodatapb.TabletType   `json:"tablet_type"`
+	State          servingState            `json:"state"`
+	TerTimestamp   time.Time               `json:"ter_timestamp"`
+	Lameduck       bool                    `json:"lameduck,omitempty"`
+	Reason         string                  `json:"reason,omitempty"`
+	AlsoAllow      []topodatapb.TabletType `json:"also_allow,omitempty"`
+	AlsoAllowUntil time.Time               `json:"also_allow_until,omitempty"`
+}
+
+// SnapshotState serializes the restorable state of sm into a
+// versioned blob that can be passed to RestoreState.
+func (sm *stateManager) SnapshotState() ([]byte, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	snapshot := &stateSnapshot{
+		Version:      stateSnapshotVersion,
+		Time:         time.Now(),
+		Keyspace:     sm.target.Keyspace,
+		Shard:        sm.target.Shard,
+		TabletType:   sm.wantTabletType,
+		State:        sm.wantState,
+		TerTimestamp: sm.terTimestamp,
+		Lameduck:     sm.lameduck,
+		Reason:       sm.reason,
+	}
+	if len(sm.alsoAllow) != 0 {
+		snapshot.AlsoAllow = sm.alsoAllow
+		snapshot.AlsoAllowUntil = sm.alsoAllowUntil
+	}
+	return json.Marshal(snapshot)
+}
+
+// RestoreState loads a blob produced by SnapshotState. It must be
+// called after Init and before the first SetServingType. It doesn't
+// start a transition: the caller applies the serving type returned
+// by RestoredServingType with SetServingType, which also restores
+// the lameduck and the also-allowed tablet types. Blobs that are
+// incompatible, stale, or belong to a different target are rejected.
+func (sm *stateManager) RestoreState(blob []byte) error {
+	snapshot, err := sm.validateSnapshot(blob)
+	if err != nil {
+		log.Warningf("Ignoring state snapshot: %v", err)
+		return err
+	}
+
+	log.Infof("Loaded state snapshot taken at %v: %v %v", snapshot.Time, snapshot.TabletType, snapshot.State)
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.restored = snapshot
+	return nil
+}
+
+// RestoredServingType returns the serving type of the snapshot
+// loaded by RestoreState. ok is false if there's none, or if it was
+// consumed by a SetServingType.
+func (sm *stateManager) RestoredServingType() (tabletType topodatapb.TabletType, terTimestamp time.Time, state servingState, reason string, ok bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.restored == nil {
+		return topodatapb.TabletType_UNKNOWN, time.Time{}, StateNotConnected, "", false
+	}
+	return sm.restored.TabletType, sm.restored.TerTimestamp, sm.restored.State, sm.restored.Reason, true
+}
+
+// applyRestored consumes the loaded snapshot after the transition
+// to tabletType. The lameduck and the also-allowed tablet types are
+// restored only if the transition succeeded, and if it was to the
+// tablet type of the snapshot.
+func (sm *stateManager) applyRestored(tabletType topodatapb.TabletType, err error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	snapshot := sm.restored
+	if snapshot == nil {
+		return
+	}
+	sm.restored = nil
+	if err != nil || tabletType != snapshot.TabletType {
+		return
+	}
+	sm.lameduck = snapshot.Lameduck
+	if remaining := time.Until(snapshot.AlsoAllowUntil); len(snapshot.AlsoAllow) != 0 && remaining > 0 {
+		sm.allowLocked(snapshot.AlsoAllow, remaining)
+	}
+	sm.broadcastLocked()
+}
+
+func (sm *stateManager) validateSnapshot(blob []byte) (*stateSnapshot, error) {
+	snapshot := &stateSnapshot{}
+	if err := json.Unmarshal(blob, snapshot); err != nil {
+		return nil, fmt.Errorf("could not parse snapshot: %v", err)
+	}
+	if snapshot.Version != stateSnapshotVersion {
+		return nil, fmt.Errorf("incompatible snapshot version %d, want %d", snapshot.Version, stateSnapshotVersion)
+	}
+	if age := time.Since(snapshot.Time); age > sm.snapshotMaxAge || age < 0 {
+		return nil, fmt.Errorf("snapshot is stale: taken at %v, max age %v", snapshot.Time, sm.snapshotMaxAge)
+	}
+
+	target := sm.Target()
+	if snapshot.Keyspace != target.Keyspace || snapshot.Shard != target.Shard {
+		return nil, fmt.Errorf("snapshot is for %s/%s, want %s/%s", snapshot.Keyspace, snapshot.Shard, target.Keyspace, target.Shard)
+	}
+	if _, ok := topodatapb.TabletType_name[int32(snapshot.TabletType)]; !ok || snapshot.TabletType == topodatapb.TabletType_UNKNOWN {
+		return nil, fmt.Errorf("invalid tablet type in snapshot: %v", snapshot.TabletType)
+	}
+	if snapshot.State < StateNotConnected || snapshot.State > StateServingReadOnly {
+		return nil, fmt.Errorf("invalid state in snapshot: %d", snapshot.State)
+	}
+	return snapshot, nil
+}
+
+// saveSnapshot writes the state snapshot to the specified file.
+// The write is atomic: readers see either the old or the new
+// contents.
+func (sm *stateManager) saveSnapshot(file string) error {
+	blob, err := sm.SnapshotState()
+	if err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err := ioutil.WriteFile(tmp, blob, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// restoreSnapshot loads the state from the specified file.
+// The file is removed after being read to prevent it from
+// being applied more than once.
+func (sm *stateManager) restoreSnapshot(file string) error {
+	blob, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(file); err != nil {
+		log.Warningf("Could not remove state snapshot %s: %v", file, err)
+	}
+	return sm.RestoreState(blob)
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestStateSnapshotRoundTrip(t *testing.T) {
+	sm1 := newTestStateManager(t)
+	defer sm1.StopService()
+	sm1.live.values.TransitionGracePeriod = 1 * time.Minute
+
+	err := sm1.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	err = sm1.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "reparent")
+	require.NoError(t, err)
+	sm1.EnterLameduck()
+
+	blob, err := sm1.SnapshotState()
+	require.NoError(t, err)
+
+	sm2 := newTestStateManager(t)
+	defer sm2.StopService()
+	err = sm2.RestoreState(blob)
+	require.NoError(t, err)
+
+	// Loading the snapshot doesn't start a transition: the caller
+	// applies it with SetServingType.
+	assert.Equal(t, StateNotConnected, sm2.state)
+	tabletType, terTimestamp, state, reason, ok := sm2.RestoredServingType()
+	require.True(t, ok)
+	err = sm2.SetServingType(context.Background(), tabletType, terTimestamp, state, reason)
+	require.NoError(t, err)
+	_, _, _, _, ok = sm2.RestoredServingType()
+	assert.False(t, ok)
+
+	assert.Equal(t, topodatapb.TabletType_MASTER, sm2.target.TabletType)
+	assert.Equal(t, StateServing, sm2.state)
+	assert.True(t, testNow.Equal(sm2.terTimestamp))
+	assert.Equal(t, "reparent", sm2.reason)
+	assert.True(t, sm2.lameduck)
+	assert.Equal(t, []topodatapb.TabletType{topodatapb.TabletType_REPLICA}, sm2.alsoAllow)
+	assert.Equal(t, sm1.IsServingString(), sm2.IsServingString())
+
+	// Admission must behave the same way on both.
+	for _, tabletType := range []topodatapb.TabletType{topodatapb.TabletType_MASTER, topodatapb.TabletType_REPLICA, topodatapb.TabletType_RDONLY} {
+		target := &querypb.Target{TabletType: tabletType}
+		err1 := sm1.StartRequest(ctx, target, false)
+		err2 := sm2.StartRequest(ctx, target, false)
+		assert.Equal(t, fmt.Sprint(err1), fmt.Sprint(err2), "%v", tabletType)
+		if err1 == nil {
+			sm1.EndRequest()
+		}
+		if err2 == nil {
+			sm2.EndRequest()
+		}
+	}
+}
+
+func TestStateSnapshotValidation(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+
+	valid := func() *stateSnapshot {
+		return &stateSnapshot{
+			Version:    stateSnapshotVersion,
+			Time:       time.Now(),
+			TabletType: topodatapb.TabletType_REPLICA,
+			State:      StateServing,
+		}
+	}
+	testcases := []struct {
+		name   string
+		modify func(*stateSnapshot)
+		err    string
+	}{{
+		name:   "version",
+		modify: func(ss *stateSnapshot) { ss.Version = stateSnapshotVersion + 1 },
+		err:    "incompatible snapshot version",
+	}, {
+		name:   "stale",
+		modify: func(ss *stateSnapshot) { ss.Time = time.Now().Add(-2 * sm.snapshotMaxAge) },
+		err:    "snapshot is stale",
+	}, {
+		name:   "keyspace",
+		modify: func(ss *stateSnapshot) { ss.Keyspace = "other" },
+		err:    "snapshot is for other/",
+	}, {
+		name:   "tablet type",
+		modify: func(ss *stateSnapshot) { ss.TabletType = topodatapb.TabletType_UNKNOWN },
+		err:    "invalid tablet type",
+	}, {
+		name:   "state",
+		modify: func(ss *stateSnapshot) { ss.State = 10 },
+		err:    "invalid state",
+	}}
+	for _, tcase := range testcases {
+		ss := valid()
+		tcase.modify(ss)
+		blob, err := json.Marshal(ss)
+		require.NoError(t, err)
+		err = sm.RestoreState(blob)
+		require.Error(t, err, tcase.name)
+		assert.Contains(t, err.Error(), tcase.err, tcase.name)
+	}
+	err := sm.RestoreState([]byte("bad"))
+	assert.Contains(t, err.Error(), "could not parse snapshot")
+
+	// Nothing should have been loaded.
+	assert.Equal(t, StateNotConnected, sm.state)
+	_, _, _, _, ok := sm.RestoredServingType()
+	assert.False(t, ok)
+}
+
+func TestStateSnapshotOtherTabletType(t *testing.T) {
+	sm1 := newTestStateManager(t)
+	defer sm1.StopService()
+	err := sm1.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	sm1.EnterLameduck()
+	blob, err := sm1.SnapshotState()
+	require.NoError(t, err)
+
+	// A transition to another tablet type discards the snapshot.
+	sm2 := newTestStateManager(t)
+	defer sm2.StopService()
+	require.NoError(t, sm2.RestoreState(blob))
+	err = sm2.SetServingType(context.Background(), topodatapb.TabletType_RDONLY, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.False(t, sm2.lameduck)
+	_, _, _, _, ok := sm2.RestoredServingType()
+	assert.False(t, ok)
+}
+
+func TestStateSnapshotFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state_snapshot")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := path.Join(dir, "snapshot")
+
+	sm1 := newTestStateManager(t)
+	defer sm1.StopService()
+	err = sm1.SetServingType(context.Background(), topodatapb.TabletType_RDONLY, testNow, StateNotServing, "")
+	require.NoError(t, err)
+	err = sm1.saveSnapshot(file)
+	require.NoError(t, err)
+
+	sm2 := newTestStateManager(t)
+	defer sm2.StopService()
+	err = sm2.restoreSnapshot(file)
+	require.NoError(t, err)
+	tabletType, _, state, _, ok := sm2.RestoredServingType()
+	require.True(t, ok)
+	assert.Equal(t, topodatapb.TabletType_RDONLY, tabletType)
+	assert.Equal(t, StateNotServing, state)
+
+	// The file must be consumed.
+	_, err = os.Stat(file)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestTabletServerRestoredServingType(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state_snapshot")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := path.Join(dir, "snapshot")
+
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	require.NoError(t, sm.saveSnapshot(file))
+
+	db := setupFakeDB(t)
+	defer db.Close()
+	config := tabletenv.NewDefaultConfig()
+	config.StateSnapshot.File = file
+	tsv := NewTabletServer("TabletServerTest", config, memorytopo.NewServer(""), topodatapb.TabletAlias{})
+	defer tsv.StopService()
+	err = tsv.InitDBConfig(querypb.Target{TabletType: topodatapb.TabletType_REPLICA}, newDBConfigs(db), nil)
+	require.NoError(t, err)
+
+	// InitDBConfig only loads the snapshot.
+	assert.Equal(t, StateNotConnected, tsv.sm.State())
+	tabletType, terTimestamp, serving, reason, ok := tsv.RestoredServingType()
+	require.True(t, ok)
+	assert.Equal(t, topodatapb.TabletType_REPLICA, tabletType)
+	assert.True(t, serving)
+	err = tsv.SetServingType(context.Background(), tabletType, terTimestamp, serving, reason)
+	require.NoError(t, err)
+	assert.Equal(t, StateServing, tsv.sm.State())
+}
@@ -0,0 +1,373 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
+)
+
+// subcomponentNames lists the subcomponents of the stateManager
+// in the order in which their status is reported.
+var subcomponentNames = []string{"watcher", "se", "vstreamer", "qe", "txThrottler", "rt", "tracker", "txEngine", "messager", "throttler"}
+
+// stateStatus is the state of the stateManager reported by
+// /debug/status.json. The health details of the status page
+// are rendered from it too. It's gathered under a single lock
+// so that its fields are consistent with each other.
+type stateStatus struct {
+	Keyspace       string    `json:"keyspace"`
+	Shard          string    `json:"shard"`
+	State          string    `json:"state"`
+	WantState      string    `json:"wantState"`
+	TabletType     string    `json:"tabletType"`
+	WantTabletType string    `json:"wantTabletType"`
+	TerTimestamp   time.Time `json:"terTimestamp"`
+	NotConnected   string    `json:"notConnected,omitempty"`
+	// Initializing is set until the serving type is first set.
+	Initializing bool     `json:"initializing,omitempty"`
+	Serving      bool     `json:"serving"`
+	Lameduck     bool     `json:"lameduck"`
+	Retrying     bool     `json:"retrying"`
+	Reason       string   `json:"reason,omitempty"`
+	PausedBy     string   `json:"pausedBy,omitempty"`
+	AlsoAllow    []string `json:"alsoAllow,omitempty"`
+	// AcceptedTabletTypes is empty while the tablet isn't serving.
+	AcceptedTabletTypes []string `json:"acceptedTabletTypes,omitempty"`
+	ReplHealthy         bool     `json:"replHealthy"`
+	// Lag is the replication lag in seconds, and LagSource where
+	// it comes from.
+	Lag             int64     `json:"lag"`
+	LagSource       string    `json:"lagSource,omitempty"`
+	ReplError       string    `json:"replError,omitempty"`
+	TransitionError string    `json:"transitionError,omitempty"`
+	ReadOnlyError   string    `json:"readOnlyError,omitempty"`
+	DirtyShutdown   bool      `json:"dirtyShutdown"`
+	TopoIsolated    bool      `json:"topoIsolated"`
+	TopoLastSeen    time.Time `json:"topoLastSeen"`
+	// TopoIsolationRemaining is the time left in seconds before
+	// a master that can't reach the topo stops serving.
+	TopoIsolationTimeout   int64 `json:"topoIsolationTimeout,omitempty"`
+	TopoIsolationRemaining int64 `json:"topoIsolationRemaining,omitempty"`
+	StreamsRunning         int64 `json:"streamsRunning"`
+	StreamsDraining        int64 `json:"streamsDraining"`
+	// RoleConfidence is only set on a serving master.
+	RoleConfidence *roleConfidence `json:"roleConfidence,omitempty"`
+	// PressureMode is the hot row protection mode, and PressureCause
+	// the condition that caused its last change.
+	PressureMode  string `json:"pressureMode"`
+	PressureCause string `json:"pressureCause,omitempty"`
+	// Promotion is the last promotion verdict, if any.
+	Promotion *promotionVerdict `json:"promotion,omitempty"`
+	// Watcher is the status of the replication streams at
+	// the last broadcast.
+	Watcher WatcherStatus `json:"watcher"`
+	// Subcomponents reports the status that the last transition
+	// operation left each subcomponent in.
+	Subcomponents []*subcomponentStatus `json:"subcomponents"`
+	// ServerIdentity identifies the mysql server the tablet is
+	// expected to be connected to. ServerIdentityError is set if
+	// the server changed since the process started.
+	ServerIdentity      schema.ServerIdentity `json:"serverIdentity"`
+	ServerIdentityError string                `json:"serverIdentityError,omitempty"`
+	// ShutdownPhases are the phases of StopService that started
+	// so far, if it was called since the last state change.
+	ShutdownPhases []shutdownPhase `json:"shutdownPhases,omitempty"`
+}
+
+type subcomponentStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// HealthError is the error the subcomponent reported at the
+	// last broadcast, if it's unhealthy.
+	HealthError string `json:"healthError,omitempty"`
+}
+
+// Status returns the current state of sm.
+func (sm *stateManager) Status() *stateStatus {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	status := &stateStatus{
+		Keyspace:       sm.target.Keyspace,
+		Shard:          sm.target.Shard,
+		State:          sm.state.String(),
+		WantState:      sm.wantState.String(),
+		TabletType:     sm.target.TabletType.String(),
+		WantTabletType: sm.wantTabletType.String(),
+		TerTimestamp:   sm.terTimestamp,
+		NotConnected:   sm.notConnectedStringLocked(),
+		Initializing:   !sm.initialized,
+		Serving:        sm.isServingLocked(),
+		Lameduck:       sm.lameduck,
+		Retrying:       sm.retrying,
+		DirtyShutdown:  sm.dirtyShutdown,
+		Reason:         sm.reason,
+		PausedBy:       sm.pausedBy,
+		ReplHealthy:    sm.replHealthy,
+		Lag:            int64(sm.replLag.Seconds()),
+		LagSource:      sm.replLagSource.String(),
+		TopoIsolated:   sm.topoIsolated,
+		TopoLastSeen:   sm.topoLastSeen,
+		RoleConfidence: sm.roleConfidence,
+		PressureMode:   sm.pressure.String(),
+		PressureCause:  sm.pressureCause,
+		Promotion:      sm.promotion,
+		Watcher:        sm.watcherStatus,
+		Subcomponents:  make([]*subcomponentStatus, 0, len(subcomponentNames)),
+		ShutdownPhases: sm.shutdownPhasesLocked(),
+		ServerIdentity: sm.serverIdentity,
+	}
+	if sm.serverIdentityErr != nil {
+		status.ServerIdentityError = sm.serverIdentityErr.Error()
+	}
+	if sm.replErr != nil {
+		status.ReplError = sm.replErr.Error()
+	}
+	if sm.transitionErr != nil {
+		status.TransitionError = sm.transitionErr.Error()
+	}
+	if sm.readOnlyErr != nil {
+		status.ReadOnlyError = sm.readOnlyErr.Error()
+	}
+	if sm.topoIsolationTimeout != 0 {
+		status.TopoIsolationTimeout = int64(sm.topoIsolationTimeout.Seconds())
+		status.TopoIsolationRemaining = int64(sm.topoIsolationRemainingLocked().Seconds())
+	}
+	for _, tabletType := range sm.alsoAllow {
+		status.AlsoAllow = append(status.AlsoAllow, tabletType.String())
+	}
+	for _, tabletType := range acceptedTabletTypes(sm.target.TabletType, sm.alsoAllowLocked(), sm.isServingLocked()) {
+		status.AcceptedTabletTypes = append(status.AcceptedTabletTypes, tabletType.String())
+	}
+	status.StreamsRunning, status.StreamsDraining = sm.streamCountsLocked()
+	for _, name := range subcomponentNames {
+		sub := &subcomponentStatus{
+			Name:   name,
+			Status: sm.subcomponents[name],
+		}
+		if err, ok := sm.subcomponentErrs[name]; ok {
+			sub.HealthError = err.Error()
+		}
+		status.Subcomponents = append(status.Subcomponents, sub)
+	}
+	return status
+}
+
+// subcomponent returns the status of the named subcomponent.
+func (status *stateStatus) subcomponent(name string) string {
+	for _, sub := range status.Subcomponents {
+		if sub.Name == name {
+			return sub.Status
+		}
+	}
+	return ""
+}
+
+// recordSubcomponentLocked updates the status of the subcomponent
+// changed by the transition operation, if any. Operations are named
+// after the subcomponent and the method they call. A failed operation
+// is recorded whatever its method.
+func (sm *stateManager) recordSubcomponentLocked(op string, err error) {
+	i := strings.IndexByte(op, '.')
+	if i < 0 {
+		return
+	}
+	name, method := op[:i], op[i+1:]
+	if name == "te" {
+		name = "txEngine"
+	}
+	if err != nil {
+		sm.subcomponents[name] = fmt.Sprintf("%s failed: %v", method, err)
+		return
+	}
+	var status string
+	switch method {
+	case "Open":
+		status = "open"
+	case "Close":
+		status = "closed"
+	case "MakeMaster":
+		status = "master"
+	case "MakeNonMaster":
+		status = "non-master"
+	case "AcceptReadOnly":
+		status = "read-only"
+	default:
+		return
+	}
+	sm.subcomponents[name] = status
+}
+
+// appendDetails renders the status as the health details
+// of the status page.
+func (status *stateStatus) appendDetails(details []*kv) []*kv {
+	stateClass := func(state string) string {
+		switch state {
+		case StateServing.String():
+			return healthyClass
+		case StateNotServing.String(), StateServingReadOnly.String():
+			return unhappyClass
+		}
+		return unhealthyClass
+	}
+	details = append(details, &kv{
+		Key:   "Current State",
+		Class: stateClass(status.State),
+		Value: stateString(status.TabletType, status.State, status.TerTimestamp),
+	})
+	if status.Initializing {
+		details = append(details, &kv{
+			Key:   "Initializing",
+			Class: unhappyClass,
+			Value: "waiting for the serving type to be set",
+		})
+	}
+	if status.NotConnected != "" {
+		details = append(details, &kv{
+			Key:   "Not Connected",
+			Class: unhealthyClass,
+			Value: status.NotConnected,
+		})
+	}
+	if status.DirtyShutdown {
+		details = append(details, &kv{
+			Key:   "Dirty Shutdown",
+			Class: unhappyClass,
+			Value: "subcomponents panicked while closing",
+		})
+	}
+	if status.TabletType != status.WantTabletType && status.State != status.WantState {
+		details = append(details, &kv{
+			Key:   "Desired State",
+			Class: stateClass(status.WantState),
+			Value: stateString(status.WantTabletType, status.WantState, status.TerTimestamp),
+		})
+	}
+	if status.Reason != "" {
+		details = append(details, &kv{
+			Key:   "Reason",
+			Class: unhappyClass,
+			Value: status.Reason,
+		})
+	}
+	if status.TransitionError != "" {
+		details = append(details, &kv{
+			Key:   "Transition Error",
+			Class: unhealthyClass,
+			Value: status.TransitionError,
+		})
+	}
+	if status.ServerIdentityError != "" {
+		details = append(details, &kv{
+			Key:   "MySQL Server",
+			Class: unhealthyClass,
+			Value: status.ServerIdentityError,
+		})
+	}
+	if status.Lameduck {
+		details = append(details, &kv{
+			Key:   "Lameduck",
+			Class: unhealthyClass,
+			Value: "ON",
+		})
+	}
+	if len(status.AlsoAllow) != 0 {
+		details = append(details, &kv{
+			Key:   "Also Serving",
+			Class: healthyClass,
+			Value: status.AlsoAllow[0],
+		})
+	}
+	if status.ReadOnlyError != "" {
+		details = append(details, &kv{
+			Key:   "Read Only Serving",
+			Class: unhappyClass,
+			Value: status.ReadOnlyError,
+		})
+	}
+	if status.TopoIsolated {
+		details = append(details, &kv{
+			Key:   "Topo Isolation",
+			Class: unhealthyClass,
+			Value: fmt.Sprintf("not serving, topo last seen %v ago", time.Since(status.TopoLastSeen).Round(time.Second)),
+		})
+	} else if status.TopoIsolationRemaining != 0 {
+		class := healthyClass
+		if status.TopoIsolationRemaining < status.TopoIsolationTimeout/2 {
+			class = unhappyClass
+		}
+		details = append(details, &kv{
+			Key:   "Topo Isolation",
+			Class: class,
+			Value: fmt.Sprintf("stops serving in %v", time.Duration(status.TopoIsolationRemaining)*time.Second),
+		})
+	}
+	if rc := status.RoleConfidence; rc != nil {
+		class := healthyClass
+		switch {
+		case rc.Degraded:
+			class = unhealthyClass
+		case rc.Confidence < 100:
+			class = unhappyClass
+		}
+		details = append(details, &kv{
+			Key:   "Role Confidence",
+			Class: class,
+			Value: rc.String(),
+		})
+	}
+	if status.PressureMode == pressureFailFast.String() {
+		details = append(details, &kv{
+			Key:   "Hot Row Protection",
+			Class: unhappyClass,
+			Value: fmt.Sprintf("failing fast: %s", status.PressureCause),
+		})
+	}
+	for _, sub := range status.Subcomponents {
+		if sub.HealthError != "" {
+			details = append(details, &kv{
+				Key:   "Subcomponent Health",
+				Class: unhappyClass,
+				Value: fmt.Sprintf("%s: %s", sub.Name, sub.HealthError),
+			})
+		}
+	}
+	if n := len(status.ShutdownPhases); n != 0 {
+		phase := status.ShutdownPhases[n-1]
+		value := phase.Name
+		if phase.Detail != "" {
+			value += ": " + phase.Detail
+		}
+		details = append(details, &kv{
+			Key:   "Shutdown",
+			Class: unhealthyClass,
+			Value: fmt.Sprintf("%s (%v after start)", value, phase.Start.Sub(status.ShutdownPhases[0].Start).Round(time.Millisecond)),
+		})
+	}
+	if status.StreamsDraining != 0 {
+		details = append(details, &kv{
+			Key:   "Stream Drain",
+			Class: unhappyClass,
+			Value: fmt.Sprintf("%d of %d running streams signaled to drain", status.StreamsDraining, status.StreamsRunning),
+		})
+	}
+	return details
+}
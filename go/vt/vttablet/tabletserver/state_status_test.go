@@ -0,0 +1,176 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/timer/fakeclock"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func subcomponentStatuses(status *stateStatus) map[string]string {
+	statuses := make(map[string]string)
+	for _, sc := range status.Subcomponents {
+		statuses[sc.Name] = sc.Status
+	}
+	return statuses
+}
+
+func TestStateStatus(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+
+	status := sm.Status()
+	assert.Equal(t, StateNotConnected.String(), status.State)
+	assert.False(t, status.Serving)
+	assert.Len(t, status.Subcomponents, len(subcomponentNames))
+	for _, sc := range status.Subcomponents {
+		assert.Equal(t, "closed", sc.Status, sc.Name)
+	}
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "promoted")
+	require.NoError(t, err)
+	status = sm.Status()
+	assert.Equal(t, StateServing.String(), status.State)
+	assert.Equal(t, StateServing.String(), status.WantState)
+	assert.Equal(t, "MASTER", status.TabletType)
+	assert.Equal(t, "MASTER", status.WantTabletType)
+	assert.Equal(t, testNow, status.TerTimestamp)
+	assert.True(t, status.Serving)
+	assert.Equal(t, "promoted", status.Reason)
+	assert.Empty(t, status.TransitionError)
+	statuses := subcomponentStatuses(status)
+	assert.Equal(t, "open", statuses["txEngine"])
+	assert.Equal(t, "open", statuses["qe"])
+	assert.Equal(t, "master", statuses["rt"])
+	assert.Equal(t, "closed", statuses["watcher"])
+
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	statuses = subcomponentStatuses(sm.Status())
+	assert.Equal(t, "read-only", statuses["txEngine"])
+	assert.Equal(t, "non-master", statuses["rt"])
+	assert.Equal(t, "open", statuses["watcher"])
+
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_RESTORE, testNow, StateNotServing, "")
+	require.NoError(t, err)
+	status = sm.Status()
+	assert.Equal(t, StateNotConnected.String(), status.State)
+	for _, sc := range status.Subcomponents {
+		assert.Equal(t, "closed", sc.Status, sc.Name)
+	}
+}
+
+func TestStateStatusTransitionError(t *testing.T) {
+	fc := fakeclock.New(testNow)
+	sm := newTestStateManagerWithClock(t, fc)
+	defer sm.StopService()
+	sm.te.(*testTxEngine).failSidecar = true
+
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.Error(t, err)
+	status := sm.Status()
+	assert.True(t, status.Retrying)
+	assert.Equal(t, StateServing.String(), status.WantState)
+	assert.Contains(t, status.TransitionError, "intentional error")
+	statuses := subcomponentStatuses(status)
+	assert.Equal(t, "Prepare failed: intentional error", statuses["txEngine"])
+	assert.Equal(t, "open", statuses["qe"])
+}
+
+func TestStateStatusAppendDetails(t *testing.T) {
+	status := &stateStatus{
+		State:           StateNotServing.String(),
+		WantState:       StateServing.String(),
+		TabletType:      "REPLICA",
+		WantTabletType:  "MASTER",
+		TerTimestamp:    testNow,
+		Reason:          "demotion in progress",
+		TransitionError: "mysql down",
+		Lameduck:        true,
+	}
+	details := status.appendDetails(nil)
+	assert.Equal(t, []*kv{{
+		Key:   "Current State",
+		Class: unhappyClass,
+		Value: "REPLICA: Not Serving",
+	}, {
+		Key:   "Desired State",
+		Class: healthyClass,
+		Value: stateString("MASTER", StateServing.String(), testNow),
+	}, {
+		Key:   "Reason",
+		Class: unhappyClass,
+		Value: "demotion in progress",
+	}, {
+		Key:   "Transition Error",
+		Class: unhealthyClass,
+		Value: "mysql down",
+	}, {
+		Key:   "Lameduck",
+		Class: unhealthyClass,
+		Value: "ON",
+	}}, details)
+}
+
+func TestStateStatusJSON(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+	sm.mu.Lock()
+	sm.replErr = errors.New("replication stopped")
+	sm.mu.Unlock()
+
+	b, err := json.Marshal(sm.Status())
+	require.NoError(t, err)
+	got := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(b, &got))
+	assert.Equal(t, "Serving", got["state"])
+	assert.Equal(t, "MASTER", got["tabletType"])
+	assert.Equal(t, "replication stopped", got["replError"])
+	assert.Equal(t, true, got["serving"])
+	assert.NotContains(t, got, "transitionError")
+	assert.Len(t, got["subcomponents"], len(subcomponentNames))
+}
+
+func TestStatusJSONHandler(t *testing.T) {
+	db, tsv := setupTabletServerTest(t)
+	defer tsv.StopService()
+	defer db.Close()
+	tsv.AddStatusPart()
+
+	request := httptest.NewRequest("GET", tsv.exporter.URLPrefix()+"/debug/status.json", nil)
+	response := httptest.NewRecorder()
+	http.DefaultServeMux.ServeHTTP(response, request)
+	assert.Equal(t, "application/json; charset=utf-8", response.Header().Get("Content-Type"))
+
+	status := &stateStatus{}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), status))
+	assert.Equal(t, StateServing.String(), status.State)
+	assert.Equal(t, "MASTER", status.TabletType)
+	assert.Equal(t, "open", subcomponentStatuses(status)["txEngine"])
+}
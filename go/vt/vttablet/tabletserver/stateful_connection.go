@@ -98,6 +98,9 @@ func (sc *StatefulConnection) Exec(ctx context.Context, query string, maxrows in
 			}
 			return nil, err
 		}
+		if isMySQLWriteErr(err) {
+			sc.env.CheckMySQL()
+		}
 		return nil, err
 	}
 	return r, nil
@@ -194,6 +194,11 @@ func (sf *StatefulConnectionPool) Capacity() int {
 	return int(sf.conns.Capacity())
 }
 
+// InUse returns the number of connections of the pool in use.
+func (sf *StatefulConnectionPool) InUse() int64 {
+	return sf.conns.InUse()
+}
+
 //renewConn unregister and registers with new id.
 func (sf *StatefulConnectionPool) renewConn(sc *StatefulConnection) error {
 	sf.active.Unregister(sc.ConnID, "renew existing connection")
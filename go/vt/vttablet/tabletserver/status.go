@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -73,6 +74,7 @@ var (
       <a href="{{.Prefix}}/debug/tablet_plans">Schema&nbsp;Query&nbsp;Plans</a></br>
       <a href="{{.Prefix}}/debug/query_stats">Schema&nbsp;Query&nbsp;Stats</a></br>
       <a href="{{.Prefix}}/queryz">Query&nbsp;Stats</a></br>
+      <a href="{{.Prefix}}/debug/query_plans.json">Query&nbsp;Plan&nbsp;Stats&nbsp;(JSON)</a></br>
       <a href="{{.Prefix}}/streamqueryz">Streaming&nbsp;Query&nbsp;Stats</a></br>
     </td>
     <td width="25%" border="">
@@ -80,12 +82,14 @@ var (
       <a href="{{.Prefix}}/querylogz">Current&nbsp;Query&nbsp;Log</a></br>
       <a href="{{.Prefix}}/txlogz">Current&nbsp;Transaction&nbsp;Log</a></br>
       <a href="{{.Prefix}}/twopcz">In-flight&nbsp;2PC&nbsp;Transactions</a></br>
+      <a href="{{.Prefix}}/debug/tx_throttler">Transaction&nbsp;Throttler&nbsp;Decisions</a></br>
     </td>
     <td width="25%" border="">
       <a href="{{.Prefix}}/healthz">Health Check</a></br>
       <a href="{{.Prefix}}/debug/health">Query Service Health Check</a></br>
       <a href="{{.Prefix}}/streamqueryz">Current Stream Queries</a></br>
       <a href="{{.Prefix}}/debug/status_details">JSON Status Details</a></br>
+      <a href="{{.Prefix}}/debug/status.json">JSON Status</a></br>
     </td>
   </tr>
 </table>
@@ -108,12 +112,14 @@ var (
     <th>Time</th>
     <th>Status</th>
     <th>Tablet Type</th>
+    <th>Slowest Transition Operations</th>
   </tr>
   {{range .History}}
   <tr class="{{.Class}}">
     <td>{{.Time.Format "Jan 2, 2006 at 15:04:05 (MST)"}}</td>
     <td>{{.Status}}</td>
     <td>{{.TabletType}}</td>
+    <td>{{.Transition}}</td>
   </tr>
   {{end}}
 </table>
@@ -268,6 +274,18 @@ func (tsv *TabletServer) AddStatusPart() {
 		json.HTMLEscape(buf, b)
 		w.Write(buf.Bytes())
 	})
+
+	tsv.exporter.HandleFunc("/debug/status.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		b, err := json.MarshalIndent(tsv.sm.Status(), "", " ")
+		if err != nil {
+			w.Write([]byte(err.Error()))
+			return
+		}
+		buf := bytes.NewBuffer(nil)
+		json.HTMLEscape(buf, b)
+		w.Write(buf.Bytes())
+	})
 }
 
 var degradedThreshold sync2.AtomicDuration
@@ -279,6 +297,16 @@ type historyRecord struct {
 	tabletType topodatapb.TabletType
 	lag        time.Duration
 	err        error
+	// notConnected is set if tabletserver was not connected.
+	notConnected string
+	// reason is the reason given by the last SetServingType.
+	reason string
+	// transition lists the operations of the transition
+	// that led to this record, if any.
+	transition []transitionOp
+	// event is set if the record is for an event that
+	// isn't a state change, like the end of initialization.
+	event string
 }
 
 func (r *historyRecord) Class() string {
@@ -292,18 +320,27 @@ func (r *historyRecord) Class() string {
 }
 
 func (r *historyRecord) Status() string {
+	if r.event != "" {
+		return r.event
+	}
 	if r.serving {
 		if r.lag > degradedThreshold.Get() {
 			return fmt.Sprintf("replication delayed: %v", r.lag)
 		}
 		return "healthy"
 	}
+	if r.notConnected != "" {
+		return fmt.Sprintf("not connected: %v", r.notConnected)
+	}
 	if r.lag > unhealthyThreshold.Get() {
 		return fmt.Sprintf("not serving: replication delay %v", r.lag)
 	}
 	if r.err != nil {
 		return fmt.Sprintf("not serving: %v", r.err)
 	}
+	if r.reason != "" {
+		return fmt.Sprintf("not serving (reason: %s)", r.reason)
+	}
 	return "not serving"
 }
 
@@ -311,11 +348,35 @@ func (r *historyRecord) TabletType() string {
 	return strings.ToLower(r.tabletType.String())
 }
 
+// transitionSummaryCount is the number of operations
+// shown in the history for each transition.
+const transitionSummaryCount = 3
+
+// Transition returns the slowest operations of the transition
+// that led to this record, with their wall times.
+func (r *historyRecord) Transition() string {
+	ops := make([]transitionOp, len(r.transition))
+	copy(ops, r.transition)
+	sort.SliceStable(ops, func(i, j int) bool { return ops[i].Wall > ops[j].Wall })
+	if len(ops) > transitionSummaryCount {
+		ops = ops[:transitionSummaryCount]
+	}
+	parts := make([]string, 0, len(ops))
+	for _, op := range ops {
+		parts = append(parts, fmt.Sprintf("%s: %v", op.Name, op.Wall))
+	}
+	return strings.Join(parts, ", ")
+}
+
 // IsDuplicate implements history.Deduplicable
 func (r *historyRecord) IsDuplicate(other interface{}) bool {
 	rother, ok := other.(*historyRecord)
 	if !ok {
 		return false
 	}
-	return r.tabletType == rother.tabletType && r.serving == rother.serving && r.err == rother.err
+	if r.transition != nil {
+		// Every transition is kept.
+		return false
+	}
+	return r.tabletType == rother.tabletType && r.serving == rother.serving && r.err == rother.err && r.notConnected == rother.notConnected && r.event == rother.event
 }
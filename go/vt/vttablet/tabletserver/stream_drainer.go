@@ -0,0 +1,121 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"vitess.io/vitess/go/sync2"
+	"vitess.io/vitess/go/vt/log"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// The memory of the running streams is accounted by chunks
+// of streamsMemChunk tokens, so that it's not updated for
+// every stream.
+const (
+	streamsMemChunk  = 64
+	streamTokenBytes = 64
+)
+
+// streamToken is issued to a streaming request when it starts. The
+// request must revalidate it at every chunk or event boundary, and
+// stop if it's no longer valid. DrainStreams invalidates the tokens
+// of the running streams.
+type streamToken struct {
+	sm      *stateManager
+	drained sync2.AtomicBool
+}
+
+// Revalidate returns a retriable error if the stream was drained.
+func (st *streamToken) Revalidate() error {
+	if st.drained.Get() {
+		return vterrors.New(vtrpcpb.Code_UNAVAILABLE, "server draining")
+	}
+	return nil
+}
+
+// Done unregisters the stream. It must be called when the stream ends.
+func (st *streamToken) Done() {
+	st.sm.mu.Lock()
+	defer st.sm.mu.Unlock()
+	delete(st.sm.streams, st)
+	st.sm.accountStreamsLocked()
+}
+
+// StartStream registers a streaming request, and returns the
+// token it must revalidate before sending each chunk.
+func (sm *stateManager) StartStream() *streamToken {
+	st := &streamToken{sm: sm}
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.streams == nil {
+		sm.streams = make(map[*streamToken]struct{})
+	}
+	sm.streams[st] = struct{}{}
+	sm.accountStreamsLocked()
+	return st
+}
+
+// accountStreamsLocked updates the memory account of the running
+// streams if their number moved to another chunk.
+func (sm *stateManager) accountStreamsLocked() {
+	chunks := (len(sm.streams) + streamsMemChunk - 1) / streamsMemChunk
+	if chunks == sm.streamsMemChunks {
+		return
+	}
+	sm.streamsMemChunks = chunks
+	sm.streamsMem.Set(int64(chunks * streamsMemChunk * streamTokenBytes))
+}
+
+// DrainStreams asks all running streams to end at their next chunk
+// boundary with a retriable error. Streams that start later are not
+// affected. It returns the number of streams that were signaled.
+func (sm *stateManager) DrainStreams() int {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.drainStreamsLocked()
+}
+
+func (sm *stateManager) drainStreamsLocked() int {
+	signaled := 0
+	for st := range sm.streams {
+		if st.drained.CompareAndSwap(false, true) {
+			signaled++
+		}
+	}
+	sm.streamsDrained.Add(int64(signaled))
+	log.Infof("State: draining %d streams", signaled)
+	return signaled
+}
+
+// streamCounts returns the number of running streams,
+// and how many of them were asked to drain.
+func (sm *stateManager) streamCounts() (running, draining int64) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.streamCountsLocked()
+}
+
+func (sm *stateManager) streamCountsLocked() (running, draining int64) {
+	for st := range sm.streams {
+		running++
+		if st.drained.Get() {
+			draining++
+		}
+	}
+	return running, draining
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// runTestStream runs a stream that revalidates its token at every
+// chunk boundary. It reports the start of each chunk on started, and
+// then waits for proceed to finish it.
+func runTestStream(st *streamToken) (started chan int, proceed chan struct{}, done chan error) {
+	started = make(chan int)
+	proceed = make(chan struct{})
+	done = make(chan error, 1)
+	go func() {
+		for i := 0; ; i++ {
+			if err := st.Revalidate(); err != nil {
+				st.Done()
+				done <- err
+				return
+			}
+			started <- i
+			<-proceed
+		}
+	}()
+	return started, proceed, done
+}
+
+func TestStreamDrain(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	drained := sm.streamsDrained.Get()
+
+	started1, proceed1, done1 := runTestStream(sm.StartStream())
+	started2, proceed2, done2 := runTestStream(sm.StartStream())
+	assert.Equal(t, 0, <-started1)
+	assert.Equal(t, 0, <-started2)
+	running, draining := sm.streamCounts()
+	assert.EqualValues(t, 2, running)
+	assert.EqualValues(t, 0, draining)
+	assert.NotContains(t, detailKeys(sm), "Stream Drain")
+
+	// Both streams are in the middle of a chunk. They
+	// complete it, and end at the next boundary.
+	assert.Equal(t, 2, sm.DrainStreams())
+	assert.Equal(t, drained+2, sm.streamsDrained.Get())
+	running, draining = sm.streamCounts()
+	assert.EqualValues(t, 2, running)
+	assert.EqualValues(t, 2, draining)
+	assert.Contains(t, detailKeys(sm), "Stream Drain")
+
+	proceed1 <- struct{}{}
+	err := <-done1
+	assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err))
+	assert.EqualError(t, err, "server draining")
+	proceed2 <- struct{}{}
+	assert.EqualError(t, <-done2, "server draining")
+
+	// Streams that start after the drain are not affected.
+	st := sm.StartStream()
+	defer st.Done()
+	assert.NoError(t, st.Revalidate())
+	running, draining = sm.streamCounts()
+	assert.EqualValues(t, 1, running)
+	assert.EqualValues(t, 0, draining)
+}
+
+func TestStreamDrainOnLameduck(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+
+	st := sm.StartStream()
+	defer st.Done()
+	sm.EnterLameduck()
+	assert.NoError(t, st.Revalidate())
+	sm.ExitLameduck()
+
+	sm.drainStreamsOnLameduck = true
+	sm.EnterLameduck()
+	assert.EqualError(t, st.Revalidate(), "server draining")
+}
+
+func TestTabletServerStreamDrain(t *testing.T) {
+	db, tsv := setupTabletServerTest(t)
+	defer tsv.StopService()
+	defer db.Close()
+
+	executeSQL := "select * from test_table limit 1000"
+	db.AddQuery(executeSQL, &sqltypes.Result{
+		Fields: []*querypb.Field{{Type: sqltypes.VarBinary}},
+		Rows:   [][]sqltypes.Value{{sqltypes.NewVarBinary("row01")}},
+	})
+	target := querypb.Target{TabletType: topodatapb.TabletType_MASTER}
+
+	// The stream is drained after its first chunk.
+	chunks := 0
+	err := tsv.StreamExecute(ctx, &target, executeSQL, nil, 0, nil, func(*sqltypes.Result) error {
+		chunks++
+		assert.Equal(t, 1, tsv.DrainStreams())
+		return nil
+	})
+	assert.Equal(t, 1, chunks)
+	assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err))
+	running, _ := tsv.sm.streamCounts()
+	assert.Zero(t, running)
+
+	// Point queries and new streams are unaffected.
+	_, err = tsv.Execute(ctx, &target, executeSQL, nil, 0, 0, nil)
+	require.NoError(t, err)
+	err = tsv.StreamExecute(ctx, &target, executeSQL, nil, 0, nil, func(*sqltypes.Result) error { return nil })
+	require.NoError(t, err)
+}
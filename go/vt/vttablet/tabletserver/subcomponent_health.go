@@ -0,0 +1,101 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"fmt"
+	"strings"
+
+	"vitess.io/vitess/go/vt/log"
+)
+
+// healthChecker is implemented by the subcomponents that can tell
+// if they work, beyond being open: e.g. a messager whose poller
+// died, or a tracker that lost its binlog connection. HealthCheck is
+// called under the lock of the stateManager at every broadcast, so it
+// must be cheap, and must not call back into the stateManager. The
+// subcomponents that don't implement it are assumed to be healthy.
+type healthChecker interface {
+	HealthCheck() error
+}
+
+// subcomponentsByName returns the subcomponents of sm
+// by the names of subcomponentNames.
+func (sm *stateManager) subcomponentsByName() map[string]interface{} {
+	return map[string]interface{}{
+		"watcher":     sm.watcher,
+		"se":          sm.se,
+		"vstreamer":   sm.vstreamer,
+		"qe":          sm.qe,
+		"txThrottler": sm.txThrottler,
+		"rt":          sm.rt,
+		"tracker":     sm.tracker,
+		"txEngine":    sm.te,
+		"messager":    sm.messager,
+		"throttler":   sm.throttler,
+	}
+}
+
+// refreshSubcomponentHealthLocked polls the subcomponents that
+// implement healthChecker, and records the errors they report.
+// The closed subcomponents aren't polled.
+func (sm *stateManager) refreshSubcomponentHealthLocked() {
+	components := sm.subcomponentsByName()
+	for _, name := range subcomponentNames {
+		hc, ok := components[name].(healthChecker)
+		if !ok || sm.subcomponents[name] == "closed" {
+			sm.setSubcomponentHealthLocked(name, nil)
+			continue
+		}
+		sm.setSubcomponentHealthLocked(name, hc.HealthCheck())
+	}
+}
+
+func (sm *stateManager) setSubcomponentHealthLocked(name string, err error) {
+	prev, unhealthy := sm.subcomponentErrs[name]
+	switch {
+	case err == nil && unhealthy:
+		log.Infof("Subcomponent %s is healthy again", name)
+		delete(sm.subcomponentErrs, name)
+	case err != nil && !unhealthy:
+		log.Warningf("Subcomponent %s is unhealthy: %v", name, err)
+		sm.subcomponentErrs[name] = err
+	case err != nil && err.Error() != prev.Error():
+		sm.subcomponentErrs[name] = err
+	}
+}
+
+// subcomponentHealthErrLocked returns an error that lists the
+// unhealthy subcomponents, or nil if there are none.
+func (sm *stateManager) subcomponentHealthErrLocked() error {
+	if len(sm.subcomponentErrs) == 0 {
+		return nil
+	}
+	var unhealthy []string
+	for _, name := range subcomponentNames {
+		if err, ok := sm.subcomponentErrs[name]; ok {
+			unhealthy = append(unhealthy, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	return fmt.Errorf("unhealthy subcomponents: %s", strings.Join(unhealthy, "; "))
+}
+
+// subcomponentsHealthyLocked returns false if a subcomponent is
+// unhealthy and the tablet must stop serving because of it.
+func (sm *stateManager) subcomponentsHealthyLocked() bool {
+	return !sm.unhealthySubcomponentsStopServing || len(sm.subcomponentErrs) == 0
+}
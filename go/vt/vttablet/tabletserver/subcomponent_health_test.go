@@ -0,0 +1,112 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// testHealthCheckedSubcomponent is a subcomponent that
+// reports the health set by setHealth.
+type testHealthCheckedSubcomponent struct {
+	testSubcomponent
+
+	mu  sync.Mutex
+	err error
+}
+
+func (tc *testHealthCheckedSubcomponent) HealthCheck() error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.err
+}
+
+func (tc *testHealthCheckedSubcomponent) setHealth(err error) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.err = err
+}
+
+func TestStateManagerSubcomponentHealth(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	messager := &testHealthCheckedSubcomponent{}
+	sm.messager = messager
+	ctx := context.Background()
+	target := &querypb.Target{TabletType: topodatapb.TabletType_MASTER}
+
+	require.NoError(t, sm.SetServingType(ctx, topodatapb.TabletType_MASTER, testNow, StateServing, ""))
+	sm.Broadcast()
+	assert.Empty(t, sm.hs.state.RealtimeStats.HealthError)
+
+	// An unhealthy subcomponent is reported, but the tablet keeps serving.
+	messager.setHealth(errors.New("poller died"))
+	sm.Broadcast()
+	assert.Equal(t, "unhealthy subcomponents: messager: poller died", sm.hs.state.RealtimeStats.HealthError)
+	assert.True(t, sm.hs.state.Serving)
+	require.NoError(t, sm.StartRequest(ctx, target, false))
+	sm.EndRequest()
+	status := sm.Status()
+	for _, sub := range status.Subcomponents {
+		if sub.Name == "messager" {
+			assert.Equal(t, "poller died", sub.HealthError)
+		} else {
+			assert.Empty(t, sub.HealthError, sub.Name)
+		}
+	}
+	assert.Contains(t, status.appendDetails(nil), &kv{
+		Key:   "Subcomponent Health",
+		Class: unhappyClass,
+		Value: "messager: poller died",
+	})
+
+	// Unless it's configured to stop serving.
+	sm.unhealthySubcomponentsStopServing = true
+	sm.Broadcast()
+	assert.False(t, sm.hs.state.Serving)
+	err := sm.StartRequest(ctx, target, false)
+	assert.EqualError(t, err, "operation not allowed: unhealthy subcomponents: messager: poller died (tablet type: MASTER, state: Serving, want: Serving)")
+	assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err))
+	ps, ready := sm.Readiness()
+	assert.False(t, ready)
+	assert.Equal(t, "unhealthy subcomponents: messager: poller died", ps.Reason)
+
+	messager.setHealth(nil)
+	sm.Broadcast()
+	assert.True(t, sm.hs.state.Serving)
+	assert.Empty(t, sm.hs.state.RealtimeStats.HealthError)
+	assert.Empty(t, sm.subcomponentErrs)
+
+	// A closed subcomponent isn't polled.
+	require.NoError(t, sm.SetServingType(ctx, topodatapb.TabletType_REPLICA, testNow, StateServing, ""))
+	messager.setHealth(errors.New("poller died"))
+	sm.Broadcast()
+	assert.True(t, sm.hs.state.Serving)
+	assert.Empty(t, sm.hs.state.RealtimeStats.HealthError)
+}
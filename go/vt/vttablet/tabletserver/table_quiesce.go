@@ -0,0 +1,311 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// QuiesceMode is what happens to the new queries
+// against a quiesced table.
+type QuiesceMode string
+
+const (
+	// QuiesceReject rejects the queries with a retryable error.
+	QuiesceReject = QuiesceMode("reject")
+	// QuiesceBuffer holds the queries until the table is resumed.
+	// The ones still held at the deadline of the quiesce are
+	// rejected with a retryable error.
+	QuiesceBuffer = QuiesceMode("buffer")
+)
+
+// quiesceDrainPollInterval is how often QuiesceTable checks if the
+// queries running against the table have completed. It's a var for tests.
+var quiesceDrainPollInterval = 10 * time.Millisecond
+
+// tableQuiesce is a quiesced table.
+type tableQuiesce struct {
+	mode     QuiesceMode
+	since    time.Time
+	deadline time.Time
+	// resumed is closed when the table is resumed.
+	resumed chan struct{}
+	// buffered is the number of queries held by the quiesce.
+	buffered int
+}
+
+// tableQuiescer holds the quiesced tables, and the number of queries
+// running against each table, which QuiesceTable waits for.
+type tableQuiescer struct {
+	mu       sync.Mutex
+	quiesces map[string]*tableQuiesce
+	running  map[string]int
+
+	buffered *stats.CountersWithSingleLabel
+	rejected *stats.CountersWithSingleLabel
+}
+
+func newTableQuiescer(env tabletenv.Env) *tableQuiescer {
+	tq := &tableQuiescer{
+		quiesces: make(map[string]*tableQuiesce),
+		running:  make(map[string]int),
+		buffered: env.Exporter().NewCountersWithSingleLabel("QuiescedTableQueriesBuffered", "Count of queries held because their table was quiesced, by table", "table"),
+		rejected: env.Exporter().NewCountersWithSingleLabel("QuiescedTableQueriesRejected", "Count of queries rejected because their table was quiesced, by table", "table"),
+	}
+	env.Exporter().NewGaugeFunc("QuiescedTables", "Number of quiesced tables", func() int64 {
+		tq.mu.Lock()
+		defer tq.mu.Unlock()
+		return int64(len(tq.quiesces))
+	})
+	return tq
+}
+
+// quiesce stops admitting the new queries against table. It then
+// waits for the queries already running against it to complete. If
+// ctx is done first, an error is returned, but the table stays
+// quiesced until it's resumed.
+func (tq *tableQuiescer) quiesce(ctx context.Context, table string, mode QuiesceMode, deadline time.Time) error {
+	if table == "" {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cannot quiesce table: the table must be named")
+	}
+	switch mode {
+	case QuiesceReject:
+	case QuiesceBuffer:
+		if deadline.IsZero() {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cannot quiesce table %s: mode %s requires a deadline", table, mode)
+		}
+	default:
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cannot quiesce table %s: unknown mode %q, must be %s or %s", table, mode, QuiesceReject, QuiesceBuffer)
+	}
+
+	tq.mu.Lock()
+	if q, ok := tq.quiesces[table]; ok {
+		tq.mu.Unlock()
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "table %s is already quiesced since %v", table, q.since.Format(time.RFC3339))
+	}
+	tq.quiesces[table] = &tableQuiesce{
+		mode:     mode,
+		since:    time.Now(),
+		deadline: deadline,
+		resumed:  make(chan struct{}),
+	}
+	tq.mu.Unlock()
+	log.Infof("Table %s quiesced, mode: %s, deadline: %v", table, mode, deadline)
+
+	for {
+		tq.mu.Lock()
+		running := tq.running[table]
+		tq.mu.Unlock()
+		if running == 0 {
+			return nil
+		}
+		select {
+		case <-time.After(quiesceDrainPollInterval):
+		case <-ctx.Done():
+			return vterrors.Wrapf(ctx.Err(), "table %s is quiesced, but %d queries are still running against it", table, running)
+		}
+	}
+}
+
+// resume admits the queries against table again. The held queries
+// resume right away.
+func (tq *tableQuiescer) resume(table string) error {
+	tq.mu.Lock()
+	defer tq.mu.Unlock()
+	q, ok := tq.quiesces[table]
+	if !ok {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot resume table %s: it's not quiesced", table)
+	}
+	tq.resumeLocked(table, q)
+	log.Infof("Table %s resumed after %v", table, time.Since(q.since))
+	return nil
+}
+
+// resumeAll resumes all the quiesced tables.
+func (tq *tableQuiescer) resumeAll(reason string) {
+	tq.mu.Lock()
+	defer tq.mu.Unlock()
+	if len(tq.quiesces) == 0 {
+		return
+	}
+	log.Infof("Resuming %d quiesced tables: %s", len(tq.quiesces), reason)
+	for table, q := range tq.quiesces {
+		tq.resumeLocked(table, q)
+	}
+}
+
+func (tq *tableQuiescer) resumeLocked(table string, q *tableQuiesce) {
+	delete(tq.quiesces, table)
+	close(q.resumed)
+}
+
+// admit waits until none of the tables of plan is quiesced, and
+// records that the query runs against them. The returned func must
+// be called when the query completes. If a table is quiesced in
+// reject mode, or still is at the deadline of a quiesce in buffer
+// mode, a retryable error is returned.
+func (tq *tableQuiescer) admit(ctx context.Context, plan *TabletPlan) (func(), error) {
+	tables := make([]string, 0, len(plan.Permissions))
+	for _, perm := range plan.Permissions {
+		tables = append(tables, perm.TableName)
+	}
+
+	tq.mu.Lock()
+	// Another table may be quiesced while the query is held:
+	// all of them are checked again after each wait.
+	for {
+		table, q := tq.firstQuiescedLocked(tables)
+		if q == nil {
+			break
+		}
+		if err := tq.waitLocked(ctx, table, q); err != nil {
+			tq.mu.Unlock()
+			return nil, err
+		}
+	}
+	for _, table := range tables {
+		tq.running[table]++
+	}
+	tq.mu.Unlock()
+
+	return func() {
+		tq.mu.Lock()
+		defer tq.mu.Unlock()
+		for _, table := range tables {
+			if tq.running[table]--; tq.running[table] == 0 {
+				delete(tq.running, table)
+			}
+		}
+	}, nil
+}
+
+func (tq *tableQuiescer) firstQuiescedLocked(tables []string) (string, *tableQuiesce) {
+	for _, table := range tables {
+		if q, ok := tq.quiesces[table]; ok {
+			return table, q
+		}
+	}
+	return "", nil
+}
+
+// waitLocked holds a query against the quiesced table until it's
+// resumed, or returns the error that rejects the query. tq.mu is
+// released while the query is held.
+func (tq *tableQuiescer) waitLocked(ctx context.Context, table string, q *tableQuiesce) error {
+	wait := time.Until(q.deadline)
+	if q.mode == QuiesceReject || wait <= 0 {
+		tq.rejected.Add(table, 1)
+		return vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "table %s is quiesced: retry later", table)
+	}
+
+	tq.buffered.Add(table, 1)
+	q.buffered++
+	tq.mu.Unlock()
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	var err error
+	select {
+	case <-q.resumed:
+	case <-timer.C:
+		tq.rejected.Add(table, 1)
+		err = vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "table %s is still quiesced at the deadline: retry later", table)
+	case <-ctx.Done():
+		err = vterrors.Wrapf(ctx.Err(), "query held because table %s is quiesced", table)
+	}
+	tq.mu.Lock()
+	q.buffered--
+	return err
+}
+
+// QuiescedTable is a quiesced table, as listed by /debug/quiesced_tables.
+type QuiescedTable struct {
+	Table    string
+	Mode     QuiesceMode
+	Since    time.Time
+	Age      string
+	Deadline time.Time `json:",omitempty"`
+	// Buffered is the number of queries held.
+	Buffered int
+}
+
+// list returns the quiesced tables, sorted by name.
+func (tq *tableQuiescer) list() []QuiescedTable {
+	tq.mu.Lock()
+	defer tq.mu.Unlock()
+	tables := make([]QuiescedTable, 0, len(tq.quiesces))
+	for table, q := range tq.quiesces {
+		tables = append(tables, QuiescedTable{
+			Table:    table,
+			Mode:     q.mode,
+			Since:    q.since,
+			Age:      time.Since(q.since).Round(time.Millisecond).String(),
+			Deadline: q.deadline,
+			Buffered: q.buffered,
+		})
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Table < tables[j].Table })
+	return tables
+}
+
+// QuiesceTable stops admitting the new queries against table, without
+// changing the serving state of the tablet, until ResumeTable is
+// called. In QuiesceReject mode, the queries are rejected with a
+// retryable error. In QuiesceBuffer mode, they're held until the table
+// is resumed, or rejected if it's still quiesced at deadline. It
+// returns once the queries already running against the table have
+// completed, or with an error if ctx is done first, in which case the
+// table still is quiesced. A change of tablet type resumes all the
+// quiesced tables. The internal queries are not affected.
+func (tsv *TabletServer) QuiesceTable(ctx context.Context, table string, mode QuiesceMode, deadline time.Time) error {
+	if tsv.se.GetTable(sqlparser.NewTableIdent(table)) == nil {
+		return vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "cannot quiesce table %s: table not found in schema", table)
+	}
+	return tsv.sm.quiescer.quiesce(ctx, table, mode, deadline)
+}
+
+// ResumeTable admits the queries against a table quiesced by
+// QuiesceTable again.
+func (tsv *TabletServer) ResumeTable(table string) error {
+	return tsv.sm.quiescer.resume(table)
+}
+
+// registerQuiescedTablesHandler registers a handler that lists
+// the quiesced tables as JSON.
+func (tsv *TabletServer) registerQuiescedTablesHandler() {
+	tsv.exporter.HandleFunc("/debug/quiesced_tables", func(w http.ResponseWriter, r *http.Request) {
+		if err := acl.CheckAccessHTTP(r, acl.DEBUGGING); err != nil {
+			acl.SendError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(tsv.sm.quiescer.list())
+	})
+}
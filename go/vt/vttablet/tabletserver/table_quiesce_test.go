@@ -0,0 +1,187 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func testQuiescePlan(tables ...string) *TabletPlan {
+	plan := &TabletPlan{Plan: &planbuilder.Plan{}}
+	for _, table := range tables {
+		plan.Permissions = append(plan.Permissions, planbuilder.Permission{TableName: table})
+	}
+	return plan
+}
+
+func TestTableQuiesceReject(t *testing.T) {
+	db, tsv := setupTabletServerTest(t)
+	defer tsv.StopService()
+	defer db.Close()
+	executeSQL := "select * from test_table limit 1000"
+	db.AddQuery(executeSQL, &sqltypes.Result{})
+	target := querypb.Target{TabletType: topodatapb.TabletType_MASTER}
+	rejected := tsv.sm.quiescer.rejected.Counts()["test_table"]
+
+	err := tsv.QuiesceTable(context.Background(), "unknown", QuiesceReject, time.Time{})
+	assert.Equal(t, vtrpcpb.Code_NOT_FOUND, vterrors.Code(err))
+	err = tsv.QuiesceTable(context.Background(), "test_table", QuiesceMode("drop"), time.Time{})
+	assert.EqualError(t, err, `cannot quiesce table test_table: unknown mode "drop", must be reject or buffer`)
+
+	require.NoError(t, tsv.QuiesceTable(context.Background(), "test_table", QuiesceReject, time.Time{}))
+	err = tsv.QuiesceTable(context.Background(), "test_table", QuiesceReject, time.Time{})
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+
+	_, err = tsv.Execute(ctx, &target, executeSQL, nil, 0, 0, nil)
+	require.Error(t, err)
+	assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err))
+	assert.Contains(t, err.Error(), "table test_table is quiesced: retry later")
+	err = tsv.StreamExecute(ctx, &target, executeSQL, nil, 0, nil, func(*sqltypes.Result) error { return nil })
+	assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err))
+	assert.Equal(t, rejected+2, tsv.sm.quiescer.rejected.Counts()["test_table"])
+
+	// The tablet keeps serving.
+	assert.True(t, tsv.sm.IsServing())
+	tables := tsv.sm.quiescer.list()
+	require.Len(t, tables, 1)
+	assert.Equal(t, "test_table", tables[0].Table)
+	assert.Equal(t, QuiesceReject, tables[0].Mode)
+
+	require.NoError(t, tsv.ResumeTable("test_table"))
+	_, err = tsv.Execute(ctx, &target, executeSQL, nil, 0, 0, nil)
+	require.NoError(t, err)
+	assert.EqualError(t, tsv.ResumeTable("test_table"), "cannot resume table test_table: it's not quiesced")
+}
+
+func TestTableQuiesceBuffer(t *testing.T) {
+	db, tsv := setupTabletServerTest(t)
+	defer tsv.StopService()
+	defer db.Close()
+	executeSQL := "select * from test_table limit 1000"
+	db.AddQuery(executeSQL, &sqltypes.Result{})
+	target := querypb.Target{TabletType: topodatapb.TabletType_MASTER}
+	buffered := tsv.sm.quiescer.buffered.Counts()["test_table"]
+
+	err := tsv.QuiesceTable(context.Background(), "test_table", QuiesceBuffer, time.Time{})
+	assert.EqualError(t, err, "cannot quiesce table test_table: mode buffer requires a deadline")
+
+	// The held query runs once the table is resumed.
+	require.NoError(t, tsv.QuiesceTable(context.Background(), "test_table", QuiesceBuffer, time.Now().Add(time.Hour)))
+	done := make(chan error)
+	go func() {
+		_, err := tsv.Execute(ctx, &target, executeSQL, nil, 0, 0, nil)
+		done <- err
+	}()
+	for tables := tsv.sm.quiescer.list(); tables[0].Buffered == 0; tables = tsv.sm.quiescer.list() {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("the query wasn't held: %v", err)
+	default:
+	}
+	require.NoError(t, tsv.ResumeTable("test_table"))
+	require.NoError(t, <-done)
+	assert.Equal(t, buffered+1, tsv.sm.quiescer.buffered.Counts()["test_table"])
+
+	// The query held at the deadline is rejected.
+	require.NoError(t, tsv.QuiesceTable(context.Background(), "test_table", QuiesceBuffer, time.Now().Add(10*time.Millisecond)))
+	_, err = tsv.Execute(ctx, &target, executeSQL, nil, 0, 0, nil)
+	require.Error(t, err)
+	assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err))
+	assert.Contains(t, err.Error(), "table test_table is still quiesced at the deadline: retry later")
+}
+
+func TestTableQuiesceDrain(t *testing.T) {
+	defer func(saved time.Duration) { quiesceDrainPollInterval = saved }(quiesceDrainPollInterval)
+	quiesceDrainPollInterval = time.Millisecond
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	tq := sm.quiescer
+
+	release, err := tq.admit(context.Background(), testQuiescePlan("t1", "t2"))
+	require.NoError(t, err)
+
+	// The running query isn't done in time, but t1 stays quiesced.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = tq.quiesce(ctx, "t1", QuiesceReject, time.Time{})
+	assert.Equal(t, vtrpcpb.Code_DEADLINE_EXCEEDED, vterrors.Code(err))
+	assert.Contains(t, err.Error(), "table t1 is quiesced, but 1 queries are still running against it")
+	_, err = tq.admit(context.Background(), testQuiescePlan("t2", "t1"))
+	assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err))
+	require.NoError(t, tq.resume("t1"))
+
+	done := make(chan error)
+	go func() {
+		done <- tq.quiesce(context.Background(), "t2", QuiesceReject, time.Time{})
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("quiesce returned before the query completed: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	release()
+	require.NoError(t, <-done)
+
+	// The other tables are not affected.
+	release, err = tq.admit(context.Background(), testQuiescePlan("t1"))
+	require.NoError(t, err)
+	release()
+}
+
+func TestStateManagerTypeChangeResumesTables(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "")
+	require.NoError(t, err)
+
+	require.NoError(t, sm.quiescer.quiesce(context.Background(), "t1", QuiesceReject, time.Time{}))
+	require.NoError(t, sm.quiescer.quiesce(context.Background(), "t2", QuiesceBuffer, time.Now().Add(time.Hour)))
+	done := make(chan error)
+	go func() {
+		release, err := sm.quiescer.admit(context.Background(), testQuiescePlan("t2"))
+		if err == nil {
+			release()
+		}
+		done <- err
+	}()
+
+	// The tables stay quiesced across the transitions
+	// that keep the tablet type.
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateNotServing, "")
+	require.NoError(t, err)
+	assert.Len(t, sm.quiescer.list(), 2)
+
+	err = sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "")
+	require.NoError(t, err)
+	assert.Empty(t, sm.quiescer.list())
+	require.NoError(t, <-done)
+}
@@ -20,6 +20,9 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang/protobuf/proto"
@@ -29,6 +32,9 @@ import (
 	"vitess.io/vitess/go/vt/dbconfigs"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/throttler"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
 // These constants represent values for various config parameters.
@@ -39,6 +45,9 @@ const (
 	NotOnMaster = "notOnMaster"
 	Polling     = "polling"
 	Heartbeat   = "heartbeat"
+	Reject      = "reject"
+	Warn        = "warn"
+	Ignore      = "ignore"
 )
 
 var (
@@ -79,6 +88,13 @@ var (
 	unhealthyThreshold           time.Duration
 	transitionGracePeriod        time.Duration
 	enableReplicationReporter    bool
+	queryTimeoutByTabletType     flagutil.StringMapValue
+	consolidatorByTabletType     flagutil.StringMapValue
+	throttleAppThresholds        flagutil.StringMapValue
+	keyspaceAliases              flagutil.StringMapValue
+	shardAliases                 flagutil.StringMapValue
+	tableMaxRows                 flagutil.StringMapValue
+	transactionCapsByCaller      flagutil.StringMapValue
 )
 
 func init() {
@@ -89,11 +105,19 @@ func init() {
 	flag.IntVar(&deprecatedMessagePoolSize, "queryserver-config-message-conn-pool-size", 0, "DEPRECATED")
 	flag.IntVar(&deprecatedMessagePoolPrefillParallelism, "queryserver-config-message-conn-pool-prefill-parallelism", 0, "DEPRECATED: Unused.")
 	flag.IntVar(&currentConfig.TxPool.Size, "queryserver-config-transaction-cap", defaultConfig.TxPool.Size, "query server transaction cap is the maximum number of transactions allowed to happen at any given point of a time for a single vttablet. E.g. by setting transaction cap to 100, there are at most 100 transactions will be processed by a vttablet and the 101th transaction will be blocked (and fail if it cannot get connection within specified timeout)")
+	flag.IntVar(&currentConfig.ReadOnlyTransactionCap, "queryserver-config-read-only-transaction-cap", defaultConfig.ReadOnlyTransactionCap, "query server read-only transaction cap is the maximum number of read-only transactions, like the ones of a replica, allowed to happen at any given point of a time. They still count towards -queryserver-config-transaction-cap. 0 means no separate cap.")
 	flag.IntVar(&currentConfig.TxPool.PrefillParallelism, "queryserver-config-transaction-prefill-parallelism", defaultConfig.TxPool.PrefillParallelism, "query server transaction prefill parallelism, a non-zero value will prefill the pool using the specified parallism.")
+	flag.Float64Var(&currentConfig.MessageMaxSendRate, "queryserver-config-message-max-send-rate", defaultConfig.MessageMaxSendRate, "query server message max send rate is the maximum number of rows per second sent for a message table, unless the table comment sets vt_max_send_rate. 0 means no limit.")
 	flag.IntVar(&currentConfig.MessagePostponeParallelism, "queryserver-config-message-postpone-cap", defaultConfig.MessagePostponeParallelism, "query server message postpone cap is the maximum number of messages that can be postponed at any given time. Set this number to substantially lower than transaction cap, so that the transaction pool isn't exhausted by the message subsystem.")
 	flag.IntVar(&deprecatedFoundRowsPoolSize, "client-found-rows-pool-size", 0, "DEPRECATED: queryserver-config-transaction-cap will be used instead.")
 	SecondsVar(&currentConfig.Oltp.TxTimeoutSeconds, "queryserver-config-transaction-timeout", defaultConfig.Oltp.TxTimeoutSeconds, "query server transaction timeout (in seconds), a transaction will be killed if it takes longer than this value")
 	SecondsVar(&currentConfig.GracePeriods.TransactionShutdownSeconds, "transaction_shutdown_grace_period", defaultConfig.GracePeriods.TransactionShutdownSeconds, "how long to wait (in seconds) for transactions to complete during graceful shutdown.")
+	SecondsVar(&currentConfig.GracePeriods.TransactionDrainSeconds, "transaction_drain_grace_period", defaultConfig.GracePeriods.TransactionDrainSeconds, "how long to wait (in seconds) for open transactions to complete when a master is demoted. New transactions are rejected in the meantime. Transactions still open after this period are rolled back. If 0, they're rolled back immediately.")
+	SecondsVar(&currentConfig.GracePeriods.QueryKillSeconds, "demotion_query_kill_grace_period", defaultConfig.GracePeriods.QueryKillSeconds, "how long to wait (in seconds) for running queries to complete when a master is demoted. Queries still running after this period are killed, and fail with a tablet type changed error. If 0, queries are not killed.")
+	SecondsVar(&currentConfig.GracePeriods.LameduckOnTermSeconds, "lameduck_on_term", defaultConfig.GracePeriods.LameduckOnTermSeconds, "how long (in seconds) vttablet stays in lameduck after SIGTERM before it stops the query service, so that the vtgates stop sending it new queries while the running ones complete. A second SIGTERM ends the lameduck early. It must be shorter than -onterm_timeout. If 0, the query service is stopped right away.")
+	SecondsVar(&currentConfig.GracePeriods.TerminationSeconds, "termination_grace_period", defaultConfig.GracePeriods.TerminationSeconds, "how long (in seconds) vttablet is given to exit after SIGTERM before it's killed, e.g. the termination grace period of its pod. If set, -lameduck_on_term and -transaction_shutdown_grace_period must fit in it.")
+	SecondsVar(&currentConfig.GracePeriods.MasterWritableWaitSeconds, "master_writable_wait", defaultConfig.GracePeriods.MasterWritableWaitSeconds, "how long (in seconds) a tablet that becomes master waits for mysql to stop being read-only before it fails the transition, which is then retried. The health stream reports 'mysql still read-only' in the meantime. If 0, the transition fails right away.")
+	SecondsVar(&currentConfig.GracePeriods.TransitionTimeoutSeconds, "transition_timeout", defaultConfig.GracePeriods.TransitionTimeoutSeconds, "how long (in seconds) a state transition may take to open the subcomponents, including its connection attempts to mysql. A transition that times out disconnects from mysql, reports the subcomponent that timed out in the health stream, and is retried. If 0, there is no timeout.")
 	flag.IntVar(&currentConfig.Oltp.MaxRows, "queryserver-config-max-result-size", defaultConfig.Oltp.MaxRows, "query server max result size, maximum number of rows allowed to return from vttablet for non-streaming queries.")
 	flag.IntVar(&currentConfig.Oltp.WarnRows, "queryserver-config-warn-result-size", defaultConfig.Oltp.WarnRows, "query server result size warning threshold, warn if number of rows returned from vttablet for non-streaming queries exceeds this")
 	flag.IntVar(&deprecatedMaxDMLRows, "queryserver-config-max-dml-rows", 0, "query server max dml rows per statement, maximum number of rows allowed to return at a time for an update or delete with either 1) an equality where clauses on primary keys, or 2) a subselect statement. For update and delete statements in above two categories, vttablet will split the original query into multiple small queries based on this configuration value. ")
@@ -104,6 +128,7 @@ func init() {
 	flag.IntVar(&currentConfig.QueryCacheSize, "queryserver-config-query-cache-size", defaultConfig.QueryCacheSize, "query server query cache size, maximum number of queries to be cached. vttablet analyzes every incoming query and generate a query plan, these plans are being cached in a lru cache. This config controls the capacity of the lru cache.")
 	SecondsVar(&currentConfig.SchemaReloadIntervalSeconds, "queryserver-config-schema-reload-time", defaultConfig.SchemaReloadIntervalSeconds, "query server schema reload time, how often vttablet reloads schemas from underlying MySQL instance in seconds. vttablet keeps table schemas in its own memory and periodically refreshes it from MySQL. This config controls the reload time.")
 	SecondsVar(&currentConfig.Oltp.QueryTimeoutSeconds, "queryserver-config-query-timeout", defaultConfig.Oltp.QueryTimeoutSeconds, "query server query timeout (in seconds), this is the query timeout in vttablet side. If a query takes more than this timeout, it will be killed.")
+	flag.Var(&queryTimeoutByTabletType, "queryserver-config-query-timeout-by-tablet-type", "comma separated list of tablet_type:seconds pairs that override -queryserver-config-query-timeout for the tablet types listed, e.g. rdonly:3600. While a tablet serves two types during a transition grace period, the stricter timeout applies.")
 	SecondsVar(&currentConfig.OltpReadPool.TimeoutSeconds, "queryserver-config-query-pool-timeout", defaultConfig.OltpReadPool.TimeoutSeconds, "query server query pool timeout (in seconds), it is how long vttablet waits for a connection from the query pool. If set to 0 (default) then the overall query timeout is used instead.")
 	SecondsVar(&currentConfig.OlapReadPool.TimeoutSeconds, "queryserver-config-stream-pool-timeout", defaultConfig.OlapReadPool.TimeoutSeconds, "query server stream pool timeout (in seconds), it is how long vttablet waits for a connection from the stream pool. If set to 0 (default) then there is no timeout.")
 	SecondsVar(&currentConfig.TxPool.TimeoutSeconds, "queryserver-config-txpool-timeout", defaultConfig.TxPool.TimeoutSeconds, "query server transaction pool timeout, it is how long vttablet waits if tx pool is full")
@@ -118,12 +143,23 @@ func init() {
 	flag.StringVar(&deprecatedPoolNamePrefix, "pool-name-prefix", "", "Deprecated")
 	flag.BoolVar(&currentConfig.WatchReplication, "watch_replication_stream", false, "When enabled, vttablet will stream the MySQL replication stream from the local server, and use it to update schema when it sees a DDL.")
 	flag.BoolVar(&currentConfig.TrackSchemaVersions, "track_schema_versions", false, "When enabled, vttablet will store versions of schemas at each position that a DDL is applied and allow retrieval of the schema corresponding to a position")
+	flag.StringVar(&currentConfig.DBCreateCharset, "db_create_charset", defaultConfig.DBCreateCharset, "default character set of the database when a master creates it, e.g. utf8mb4. If empty, the MySQL default is used.")
+	flag.StringVar(&currentConfig.DBCreateCollation, "db_create_collation", defaultConfig.DBCreateCollation, "default collation of the database when a master creates it, e.g. utf8mb4_general_ci. If empty, the MySQL default is used.")
 	flag.BoolVar(&deprecatedAutocommit, "enable-autocommit", true, "This flag is deprecated. Autocommit is always allowed.")
 	flag.BoolVar(&currentConfig.TwoPCEnable, "twopc_enable", defaultConfig.TwoPCEnable, "if the flag is on, 2pc is enabled. Other 2pc flags must be supplied.")
 	flag.StringVar(&currentConfig.TwoPCCoordinatorAddress, "twopc_coordinator_address", defaultConfig.TwoPCCoordinatorAddress, "address of the (VTGate) process(es) that will be used to notify of abandoned transactions.")
 	SecondsVar(&currentConfig.TwoPCAbandonAge, "twopc_abandon_age", defaultConfig.TwoPCAbandonAge, "time in seconds. Any unresolved transaction older than this time will be sent to the coordinator to be resolved.")
+	flag.Var(&keyspaceAliases, "keyspace_aliases", "comma separated list of old:new pairs of keyspace names. Requests that target an old name are served as if they targeted the new one, e.g. while the clients migrate after a keyspace rename. They can be reloaded at /debug/config/reload.")
+	flag.Var(&shardAliases, "shard_aliases", "comma separated list of old:new pairs of shard names, that work like -keyspace_aliases for the shard of the tablet. They can be reloaded at /debug/config/reload.")
+	flag.Var(&tableMaxRows, "table_max_rows", "comma separated list of table:rows pairs that override -queryserver-config-max-result-size for the queries of the tables listed, e.g. events:50000. Unlike the global limit, they also cap the rows returned by streaming queries. They can be reloaded at /debug/config/reload.")
+	flag.Var(&throttleAppThresholds, "throttle_app_thresholds", "comma separated list of app:seconds pairs that override -throttle_threshold for the apps listed, e.g. vreplication:0.5,online-ddl:5. An app with a lower threshold is throttled earlier. They can be reloaded at /debug/config/reload.")
+	SecondsVar(&currentConfig.VStreamCopyProgressIntervalSeconds, "vstream_copy_progress_interval", defaultConfig.VStreamCopyProgressIntervalSeconds, "interval (in seconds) at which the copy phase of a vstream saves its progress in _vt.vstream_copy_state, so that a consumer that reconnects with the same filter and the position it last received resumes the copy after a tablet restart. 0 disables it.")
+	flag.Float64Var(&currentConfig.VStreamCopyMaxRowRate, "vstream_copy_max_row_rate", defaultConfig.VStreamCopyMaxRowRate, "max number of rows per second the copy phase of a vstream reads from a table when the lag throttler isn't open. 0 means no limit.")
+	SecondsVar(&currentConfig.VStreamHeartbeatIntervalSeconds, "vstream_heartbeat_interval", defaultConfig.VStreamHeartbeatIntervalSeconds, "interval (in seconds) after which an idle vstream sends a heartbeat, including while it copies tables. 0 means the default of 0.9s")
+	flag.BoolVar(&currentConfig.ThrottleOnReplicas, "throttle_on_replicas", defaultConfig.ThrottleOnReplicas, "If true, the lag throttler is also opened on non-master tablets, so that the apps running against them can use its check endpoint. It then checks the replication lag of the tablet's own mysql instead of probing the replicas of the shard.")
 	flag.BoolVar(&currentConfig.EnableTxThrottler, "enable-tx-throttler", defaultConfig.EnableTxThrottler, "If true replication-lag-based throttling on transactions will be enabled.")
 	flag.StringVar(&currentConfig.TxThrottlerConfig, "tx-throttler-config", defaultConfig.TxThrottlerConfig, "The configuration of the transaction throttler as a text formatted throttlerdata.Configuration protocol buffer message")
+	flag.BoolVar(&currentConfig.TxThrottlerDryRun, "tx-throttler-dry-run", defaultConfig.TxThrottlerDryRun, "If true, the transaction throttler only records the transactions it would have throttled, with the replication lag that caused it, and lets them proceed. The decisions are shown at /debug/tx_throttler.")
 	flagutil.StringListVar(&currentConfig.TxThrottlerHealthCheckCells, "tx-throttler-healthcheck-cells", defaultConfig.TxThrottlerHealthCheckCells, "A comma-separated list of cells. Only tabletservers running in these cells will be monitored for replication lag by the transaction throttler.")
 
 	flag.BoolVar(&enableHotRowProtection, "enable_hot_row_protection", false, "If true, incoming transactions for the same row (range) will be queued and cannot consume all txpool slots.")
@@ -131,6 +167,7 @@ func init() {
 	flag.IntVar(&currentConfig.HotRowProtection.MaxQueueSize, "hot_row_protection_max_queue_size", defaultConfig.HotRowProtection.MaxQueueSize, "Maximum number of BeginExecute RPCs which will be queued for the same row (range).")
 	flag.IntVar(&currentConfig.HotRowProtection.MaxGlobalQueueSize, "hot_row_protection_max_global_queue_size", defaultConfig.HotRowProtection.MaxGlobalQueueSize, "Global queue limit across all row (ranges). Useful to prevent that the queue can grow unbounded.")
 	flag.IntVar(&currentConfig.HotRowProtection.MaxConcurrency, "hot_row_protection_concurrent_transactions", defaultConfig.HotRowProtection.MaxConcurrency, "Number of concurrent transactions let through to the txpool/MySQL for the same hot row. Should be > 1 to have enough 'ready' transactions in MySQL and benefit from a pipelining effect.")
+	flag.BoolVar(&currentConfig.HotRowProtection.FailFastUnderPressure, "hot_row_protection_fail_fast_under_pressure", defaultConfig.HotRowProtection.FailFastUnderPressure, "If true, transactions for a hot row are rejected instead of queued while replication lags beyond -degraded_threshold or the txpool is exhausted, to avoid that they pile up.")
 
 	flag.BoolVar(&currentConfig.EnableTransactionLimit, "enable_transaction_limit", defaultConfig.EnableTransactionLimit, "If true, limit on number of transactions open at the same time will be enforced for all users. User trying to open a new transaction after exhausting their limit will receive an error immediately, regardless of whether there are available slots or not.")
 	flag.BoolVar(&currentConfig.EnableTransactionLimitDryRun, "enable_transaction_limit_dry_run", defaultConfig.EnableTransactionLimitDryRun, "If true, limit on number of transactions open at the same time will be tracked for all users, but not enforced.")
@@ -138,22 +175,74 @@ func init() {
 	flag.BoolVar(&currentConfig.TransactionLimitByUsername, "transaction_limit_by_username", defaultConfig.TransactionLimitByUsername, "Include VTGateCallerID.username when considering who the user is for the purpose of transaction limit.")
 	flag.BoolVar(&currentConfig.TransactionLimitByPrincipal, "transaction_limit_by_principal", defaultConfig.TransactionLimitByPrincipal, "Include CallerID.principal when considering who the user is for the purpose of transaction limit.")
 	flag.BoolVar(&currentConfig.TransactionLimitByComponent, "transaction_limit_by_component", defaultConfig.TransactionLimitByComponent, "Include CallerID.component when considering who the user is for the purpose of transaction limit.")
+	flag.Var(&transactionCapsByCaller, "transaction_caps_by_caller", "comma separated list of caller:count pairs that cap the number of concurrent transactions of the callers listed, e.g. batch-service:10. The caller is the principal of the effective caller ID, or the username of the immediate caller ID if there's none. Unlike -enable_transaction_limit, the other callers aren't limited. They can be reloaded at /debug/config/reload.")
 	flag.BoolVar(&currentConfig.TransactionLimitBySubcomponent, "transaction_limit_by_subcomponent", defaultConfig.TransactionLimitBySubcomponent, "Include CallerID.subcomponent when considering who the user is for the purpose of transaction limit.")
 
+	flag.BoolVar(&currentConfig.FairShare.Enable, "enable_fair_share_admission", defaultConfig.FairShare.Enable, "If true, the requests that may execute at the same time are divided across the effective callers. A caller can use the capacity nobody else needs, but once it's all in use, a caller at or above its share is rejected, and the others wait for the next free slot.")
+	flag.IntVar(&currentConfig.FairShare.Concurrency, "fair_share_concurrency", defaultConfig.FairShare.Concurrency, "Maximum number of requests that may execute at the same time if -enable_fair_share_admission is set.")
+	flag.Float64Var(&currentConfig.FairShare.MaxShare, "fair_share_max_share", defaultConfig.FairShare.MaxShare, "Maximum number of requests a single effective caller may hold while others are waiting, represented as fraction of -fair_share_concurrency.")
+	flag.IntVar(&currentConfig.FairShare.CallerCacheSize, "fair_share_caller_cache_size", defaultConfig.FairShare.CallerCacheSize, "Number of recently seen effective callers for which the fair share stats are exported.")
+	flag.IntVar(&currentConfig.MaxConcurrentRequestsPerCaller, "max_concurrent_requests_per_caller", defaultConfig.MaxConcurrentRequestsPerCaller, "Maximum number of requests of a single effective caller that may execute at the same time. The requests above it are rejected. 0 means no limit.")
+
 	flag.BoolVar(&enableHeartbeat, "heartbeat_enable", false, "If true, vttablet records (if master) or checks (if replica) the current time of a replication heartbeat in the table _vt.heartbeat. The result is used to inform the serving state of the vttablet via healthchecks.")
 	flag.DurationVar(&heartbeatInterval, "heartbeat_interval", 1*time.Second, "How frequently to read and write replication heartbeat.")
 
 	flag.BoolVar(&currentConfig.EnforceStrictTransTables, "enforce_strict_trans_tables", defaultConfig.EnforceStrictTransTables, "If true, vttablet requires MySQL to run with STRICT_TRANS_TABLES or STRICT_ALL_TABLES on. It is recommended to not turn this flag off. Otherwise MySQL may alter your supplied values before saving them to the database.")
 	flag.BoolVar(&enableConsolidator, "enable-consolidator", true, "This option enables the query consolidator.")
 	flag.BoolVar(&enableConsolidatorReplicas, "enable-consolidator-replicas", false, "This option enables the query consolidator only on replicas.")
+	flag.Var(&consolidatorByTabletType, "consolidator-by-tablet-type", "comma separated list of tablet_type:mode pairs that override the consolidator mode set by -enable-consolidator and -enable-consolidator-replicas for the tablet types listed, e.g. master:disable,replica:enable. The modes are enable and disable. The mode changes along with the tablet type.")
 	flag.BoolVar(&currentConfig.CacheResultFields, "enable-query-plan-field-caching", defaultConfig.CacheResultFields, "This option fetches & caches fields (columns) when storing query plans")
 
 	flag.DurationVar(&healthCheckInterval, "health_check_interval", 20*time.Second, "Interval between health checks")
+	SecondsVar(&currentConfig.Healthcheck.NotServingIntervalSeconds, "health_check_interval_not_serving", defaultConfig.Healthcheck.NotServingIntervalSeconds, "interval (in seconds) between the health broadcasts of a tablet that's not serving, so that the vtgates notice its recovery sooner. -health_check_interval is then only used while serving. 0 means -health_check_interval")
 	flag.DurationVar(&degradedThreshold, "degraded_threshold", 30*time.Second, "replication lag after which a replica is considered degraded")
 	flag.DurationVar(&unhealthyThreshold, "unhealthy_threshold", 2*time.Hour, "replication lag after which a replica is considered unhealthy")
 	flag.DurationVar(&transitionGracePeriod, "serving_state_grace_period", 0, "how long to pause after broadcasting health to vtgate, before enforcing a new serving state")
+	SecondsVar(&currentConfig.Healthcheck.LivenessThresholdSeconds, "liveness_transition_threshold", defaultConfig.Healthcheck.LivenessThresholdSeconds, "how long (in seconds) a serving state transition can be in progress before the liveness probe reports vttablet as wedged")
+	SecondsVar(&currentConfig.Healthcheck.StuckTransitionThresholdSeconds, "stuck_transition_threshold", defaultConfig.Healthcheck.StuckTransitionThresholdSeconds, "how long (in seconds) the serving state can differ from the desired one before it's reported as stuck. 0 disables the check")
+	flag.StringVar(&currentConfig.Healthcheck.StuckTransitionHook, "stuck_transition_hook", defaultConfig.Healthcheck.StuckTransitionHook, "name of the vthook to run when the serving state is reported as stuck")
+	flag.IntVar(&currentConfig.Healthcheck.FlapThreshold, "serving_flap_threshold", defaultConfig.Healthcheck.FlapThreshold, "number of changes between serving and not serving within -serving_flap_window above which the tablet is reported as flapping. It's logged, counted and runs -serving_flap_hook when it starts and stops flapping. 0 disables the detection")
+	SecondsVar(&currentConfig.Healthcheck.FlapWindowSeconds, "serving_flap_window", defaultConfig.Healthcheck.FlapWindowSeconds, "the window (in seconds) over which the serving changes are counted for -serving_flap_threshold")
+	flag.StringVar(&currentConfig.Healthcheck.FlapHook, "serving_flap_hook", defaultConfig.Healthcheck.FlapHook, "name of the vthook to run when the tablet starts and stops flapping between serving and not serving")
+	flag.BoolVar(&currentConfig.Healthcheck.FlapIgnoreOperator, "serving_flap_ignore_operator", defaultConfig.Healthcheck.FlapIgnoreOperator, "If true, the serving changes requested through SetServingType are not counted for -serving_flap_threshold. Only the ones caused by failures and their recoveries are")
+	flag.IntVar(&currentConfig.Healthcheck.LagHistorySize, "repl_lag_history_size", defaultConfig.Healthcheck.LagHistorySize, "number of replication lag samples kept for /debug/repl_lag_history. A sample is taken at every health check, whether the tablet serves or not. 0 disables the history")
+	SecondsVar(&currentConfig.Healthcheck.TopoIsolationTimeoutSeconds, "topo_isolation_timeout", defaultConfig.Healthcheck.TopoIsolationTimeoutSeconds, "how long (in seconds) a master can be unable to reach the topo before it stops serving. It resumes once the topo is reachable or the isolation is acknowledged at /debug/topo_isolation/ack. 0 disables the check")
+	SecondsVar(&currentConfig.Healthcheck.MySQLProbeIntervalSeconds, "mysql_probe_interval", defaultConfig.Healthcheck.MySQLProbeIntervalSeconds, "interval (in seconds) at which a serving vttablet checks that mysql is reachable, and shuts down the query service if it's not. 0 disables the probe: mysql is then only checked after query errors")
+	flag.Float64Var(&currentConfig.Healthcheck.DegradedShedMaxFraction, "degraded_shed_max_fraction", defaultConfig.Healthcheck.DegradedShedMaxFraction, "fraction of the requests a replica rejects with a retryable error when its replication lag reaches -unhealthy_threshold. Between -degraded_threshold and -unhealthy_threshold, the fraction grows linearly from 0, so that vtgate shifts the load to healthier replicas. 0 disables load shedding")
+	flag.BoolVar(&currentConfig.Healthcheck.UnhealthySubcomponentsStopServing, "unhealthy_subcomponents_stop_serving", defaultConfig.Healthcheck.UnhealthySubcomponentsStopServing, "If true, the tablet stops serving while one of its subcomponents reports itself unhealthy. Otherwise, the unhealthy subcomponents are only reported in the health stream.")
+	flag.IntVar(&currentConfig.Healthcheck.CheckMySQLMinErrorTables, "check_mysql_min_error_tables", defaultConfig.Healthcheck.CheckMySQLMinErrorTables, "number of tables whose queries must have failed with connection errors in the last minute for a mysql check that fails without a connection error to shut down the query service. The checks that can't connect to mysql always do. 0 makes every failed check shut it down")
+	flag.IntVar(&currentConfig.Healthcheck.MaxStreamSubscribers, "health_stream_max_subscribers", defaultConfig.Healthcheck.MaxStreamSubscribers, "maximum number of concurrent health streams. The streams opened beyond it are rejected with RESOURCE_EXHAUSTED. 0 means no limit")
+	flag.IntVar(&currentConfig.Healthcheck.StreamIdleBroadcasts, "health_stream_idle_broadcasts", defaultConfig.Healthcheck.StreamIdleBroadcasts, "number of consecutive health broadcasts that can't be delivered to a health stream, because its subscriber didn't consume the previous one, after which the stream is closed with RESOURCE_EXHAUSTED. 0 never closes the streams")
+	flag.IntVar(&currentConfig.Healthcheck.RoleConfidenceDegradedThreshold, "role_confidence_degraded_threshold", defaultConfig.Healthcheck.RoleConfidenceDegradedThreshold, "role confidence (in percent) below which a master reports itself as degraded in its health stream. The tablet keeps serving. The replication state it depends on is sampled at the health check interval. 0 disables the role confidence")
+	flag.BoolVar(&currentConfig.Healthcheck.PromotionCheck, "enable_promotion_check", defaultConfig.Healthcheck.PromotionCheck, "If true, vttablet checks at every health check interval that mysql could serve it as a master, and publishes the verdict at /debug/promotable and in its health stream for failover tools. The check doesn't write to mysql.")
+	flag.BoolVar(&currentConfig.CrashOnTransitionPanic, "crash_on_transition_panic", defaultConfig.CrashOnTransitionPanic, "If true, a panic during a serving state transition crashes vttablet. Otherwise, the panic is recovered and vttablet disconnects from mysql.")
+	flag.BoolVar(&currentConfig.MySQLServerIdentityChangeFatal, "mysql_server_identity_change_fatal", defaultConfig.MySQLServerIdentityChangeFatal, "If true, vttablet refuses to serve if the server_uuid or server_id of its mysql server changes without a restart, which means it was pointed to another mysqld. Otherwise, the change is only reported as a health error.")
+	flag.BoolVar(&currentConfig.SkipMasterReadOnlyCheck, "skip_master_read_only_check", defaultConfig.SkipMasterReadOnlyCheck, "If true, a tablet that becomes master doesn't check that mysql is writable before it serves. Set it if read_only is managed outside of vitess, e.g. for an external mysql.")
+	flag.BoolVar(&currentConfig.RetryableRequestErrors, "retryable_request_errors", defaultConfig.RetryableRequestErrors, "If true, the requests rejected while the tablet transitions to serving, or while its replication is unhealthy, fail with UNAVAILABLE instead of FAILED_PRECONDITION. Only set it once all the vtgates buffer on UNAVAILABLE: the older ones only buffer on FAILED_PRECONDITION.")
+	flag.BoolVar(&currentConfig.HealthIdentityMismatchFatal, "health_identity_mismatch_panic", defaultConfig.HealthIdentityMismatchFatal, "If true, vttablet panics if a health response would advertise another tablet alias, keyspace, shard or cell than the ones it was initialized with. Otherwise, the mismatch is counted, logged, and the tablet is reported as not serving.")
+	flag.BoolVar(&currentConfig.DrainStreamsOnLameduck, "lameduck_drain_streams", defaultConfig.DrainStreamsOnLameduck, "If true, entering lameduck asks the running streaming queries and vstreams to end at their next chunk boundary with a retriable error instead of letting them run to completion.")
+	flag.BoolVar(&currentConfig.KeepVStreamerOnBackup, "keep_vstreamer_on_backup", defaultConfig.KeepVStreamerOnBackup, "If true, a tablet that becomes BACKUP keeps its schema engine and vstreamer open, so that the vstreams against it keep running during the backup. The queries are still rejected, and the tablet reports itself as not serving. Only useful with the backup engines that keep mysqld running, such as xtrabackup.")
+	flag.Int64Var(&currentConfig.StateBuffersCapBytes, "state_buffers_cap_bytes", defaultConfig.StateBuffersCapBytes, "Cap on the estimated memory used by the health stream subscribers, the health history, the replication lag history and the request tracker. If it's exceeded, the replication lag history, then the health history are shrunk. 0 means no cap.")
+	flagutil.StringListVar(&currentConfig.ServingOrder, "serving_order", defaultConfig.ServingOrder, "A comma-separated list of the master-only subcomponents in the order in which they must be opened. They're closed in reverse order. All of tracker, txEngine, messager and throttler must be listed exactly once. If empty, that's the order used, but the subcomponents that don't depend on each other are opened concurrently unless -serial_transition_opens is set.")
+	flag.BoolVar(&currentConfig.SerialTransitionOpens, "serial_transition_opens", defaultConfig.SerialTransitionOpens, "If true, the subcomponents are opened one at a time during the serving state transitions. Otherwise, the ones that don't depend on each other are opened concurrently.")
 
 	flag.BoolVar(&enableReplicationReporter, "enable_replication_reporter", false, "Use polling to track replication lag.")
+	flag.BoolVar(&currentConfig.ReplicationTracker.CrossCheck, "enable_replication_lag_cross_check", defaultConfig.ReplicationTracker.CrossCheck, "If true, a low replication lag is verified against the replication threads and the retrieved GTID set. The replica is reported unhealthy if replication is not running.")
+	SecondsVar(&currentConfig.ReplicationTracker.CrossCheckIntervalSeconds, "replication_lag_cross_check_interval", defaultConfig.ReplicationTracker.CrossCheckIntervalSeconds, "minimum interval (in seconds) between two replication lag cross-checks.")
+	flag.BoolVar(&currentConfig.ReplicationTracker.ServeWithoutReplication, "serve_without_replication", defaultConfig.ReplicationTracker.ServeWithoutReplication, "If true, replica and rdonly tablets serve even if mysql is not configured to replicate. Use this for unmanaged or master-only setups.")
+
+	flag.StringVar(&currentConfig.TerTimestampRegression, "ter_timestamp_regression", defaultConfig.TerTimestampRegression, "What to do when a tablet is made master with an externally reparented timestamp older than its current one: reject fails the transition, warn accepts it but logs and counts it, ignore accepts it silently.")
+	flag.StringVar(&currentConfig.TransitionAuditLog, "transition_audit_log", defaultConfig.TransitionAuditLog, "If set, the events that drive the serving state transitions are appended to this file, one JSON object per line. The log can be replayed to reproduce the transitions.")
+	flag.BoolVar(&currentConfig.StructuredStateLogs, "structured_state_logs", defaultConfig.StructuredStateLogs, "If true, the decisions of the state manager (transition start and end, retries, lameduck, mysql checks and replication health changes) are logged as JSON objects with the fields event, from_state, to_state, tablet_type, reason, error and duration_ms, instead of human-readable messages.")
+	flag.StringVar(&currentConfig.StateSnapshot.File, "state_snapshot_file", defaultConfig.StateSnapshot.File, "If set, the serving state is saved to this file on shutdown and restored from it on startup, shrinking the not-serving window of a binary upgrade.")
+	SecondsVar(&currentConfig.StateSnapshot.MaxAgeSeconds, "state_snapshot_max_age", defaultConfig.StateSnapshot.MaxAgeSeconds, "state snapshots older than this (in seconds) are ignored on startup.")
+
+	flag.IntVar(&currentConfig.PlanWarmup.Count, "plan_warmup_count", defaultConfig.PlanWarmup.Count, "If > 0, the query plans of the most executed queries, up to this count, are periodically saved to -plan_warmup_file, and built again before the tablet starts serving as a master. This avoids the latency spike of a cold plan cache.")
+	flag.StringVar(&currentConfig.PlanWarmup.File, "plan_warmup_file", defaultConfig.PlanWarmup.File, "the file the queries of -plan_warmup_count are saved to.")
+	SecondsVar(&currentConfig.PlanWarmup.SaveIntervalSeconds, "plan_warmup_save_interval", defaultConfig.PlanWarmup.SaveIntervalSeconds, "how often (in seconds) the queries of -plan_warmup_count are saved. They're also saved when the query engine closes.")
+	SecondsVar(&currentConfig.PlanWarmup.TimeoutSeconds, "plan_warmup_timeout", defaultConfig.PlanWarmup.TimeoutSeconds, "maximum time (in seconds) the plan warmup may delay a transition to master. The plans left are built by the first queries that need them.")
+	SecondsVar(&currentConfig.SnapshotExport.MaxPauseSeconds, "snapshot_export_max_pause", defaultConfig.SnapshotExport.MaxPauseSeconds, "maximum time (in seconds) the new requests are rejected while a snapshot export takes its consistent snapshot. The export fails if the snapshot isn't taken in time.")
+	SecondsVar(&currentConfig.SnapshotExport.TTLSeconds, "snapshot_export_ttl", defaultConfig.SnapshotExport.TTLSeconds, "how long (in seconds) a snapshot export lasts without a keepalive from its caller before it's released, so that the snapshot of a caller that died isn't held forever.")
 }
 
 // Init must be called after flag.Parse, and before doing any other operations.
@@ -204,6 +293,59 @@ func Init() {
 	currentConfig.Healthcheck.UnhealthyThresholdSeconds.Set(unhealthyThreshold)
 	currentConfig.GracePeriods.TransitionSeconds.Set(transitionGracePeriod)
 
+	if len(queryTimeoutByTabletType) != 0 {
+		currentConfig.Oltp.QueryTimeoutByTabletType = make(map[string]Seconds, len(queryTimeoutByTabletType))
+		for tabletType, value := range queryTimeoutByTabletType {
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				log.Exitf("Invalid -queryserver-config-query-timeout-by-tablet-type value for %v: %v", tabletType, err)
+			}
+			currentConfig.Oltp.QueryTimeoutByTabletType[strings.ToLower(tabletType)] = Seconds(seconds)
+		}
+	}
+	if len(consolidatorByTabletType) != 0 {
+		currentConfig.ConsolidatorByTabletType = make(map[string]string, len(consolidatorByTabletType))
+		for tabletType, mode := range consolidatorByTabletType {
+			currentConfig.ConsolidatorByTabletType[strings.ToLower(tabletType)] = mode
+		}
+	}
+	if len(throttleAppThresholds) != 0 {
+		currentConfig.ThrottleAppThresholds = make(map[string]Seconds, len(throttleAppThresholds))
+		for appName, value := range throttleAppThresholds {
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				log.Exitf("Invalid -throttle_app_thresholds value for %v: %v", appName, err)
+			}
+			currentConfig.ThrottleAppThresholds[appName] = Seconds(seconds)
+		}
+	}
+	if len(keyspaceAliases) != 0 {
+		currentConfig.KeyspaceAliases = map[string]string(keyspaceAliases)
+	}
+	if len(shardAliases) != 0 {
+		currentConfig.ShardAliases = map[string]string(shardAliases)
+	}
+	if len(tableMaxRows) != 0 {
+		currentConfig.TableMaxRows = make(map[string]int, len(tableMaxRows))
+		for table, value := range tableMaxRows {
+			rows, err := strconv.Atoi(value)
+			if err != nil {
+				log.Exitf("Invalid -table_max_rows value for %v: %v", table, err)
+			}
+			currentConfig.TableMaxRows[table] = rows
+		}
+	}
+	if len(transactionCapsByCaller) != 0 {
+		currentConfig.TransactionCapsByCaller = make(map[string]int, len(transactionCapsByCaller))
+		for caller, value := range transactionCapsByCaller {
+			max, err := strconv.Atoi(value)
+			if err != nil {
+				log.Exitf("Invalid -transaction_caps_by_caller value for %v: %v", caller, err)
+			}
+			currentConfig.TransactionCapsByCaller[caller] = max
+		}
+	}
+
 	switch *streamlog.QueryLogFormat {
 	case streamlog.QueryLogFormatText:
 	case streamlog.QueryLogFormatJSON:
@@ -230,12 +372,25 @@ type TabletConfig struct {
 
 	Oltp             OltpConfig             `json:"oltp,omitempty"`
 	HotRowProtection HotRowProtectionConfig `json:"hotRowProtection,omitempty"`
+	FairShare        FairShareConfig        `json:"fairShare,omitempty"`
+	// MaxConcurrentRequestsPerCaller caps the requests in flight of
+	// every effective caller. 0 means no cap.
+	MaxConcurrentRequestsPerCaller int `json:"maxConcurrentRequestsPerCaller,omitempty"`
 
 	Healthcheck  HealthcheckConfig  `json:"healthcheck,omitempty"`
 	GracePeriods GracePeriodsConfig `json:"gracePeriods,omitempty"`
 
 	ReplicationTracker ReplicationTrackerConfig `json:"replicationTracker,omitempty"`
 
+	StateSnapshot  StateSnapshotConfig  `json:"stateSnapshot,omitempty"`
+	PlanWarmup     PlanWarmupConfig     `json:"planWarmup,omitempty"`
+	SnapshotExport SnapshotExportConfig `json:"snapshotExport,omitempty"`
+
+	// ConsolidatorByTabletType overrides Consolidator for the tablet
+	// types it lists. Its keys are lower case tablet type names, and
+	// its values enable or disable.
+	ConsolidatorByTabletType map[string]string `json:"consolidatorByTabletType,omitempty"`
+
 	// Consolidator can be enable, disable, or notOnMaster. Default is enable.
 	Consolidator                string  `json:"consolidator,omitempty"`
 	PassthroughDML              bool    `json:"passthroughDML,omitempty"`
@@ -247,6 +402,93 @@ type TabletConfig struct {
 	TerseErrors                 bool    `json:"terseErrors,omitempty"`
 	MessagePostponeParallelism  int     `json:"messagePostponeParallelism,omitempty"`
 	CacheResultFields           bool    `json:"cacheResultFields,omitempty"`
+	CrashOnTransitionPanic      bool    `json:"crashOnTransitionPanic,omitempty"`
+	// MySQLServerIdentityChangeFatal makes the tablet refuse to serve
+	// if the identity of its mysql server changes without a restart.
+	// Otherwise, the change is only reported in the health stream.
+	MySQLServerIdentityChangeFatal bool `json:"mysqlServerIdentityChangeFatal,omitempty"`
+	// SkipMasterReadOnlyCheck disables the check that mysql is
+	// writable before a master serves.
+	SkipMasterReadOnlyCheck bool `json:"skipMasterReadOnlyCheck,omitempty"`
+	// RetryableRequestErrors makes the requests rejected while the
+	// tablet transitions to serving, or while its replication is
+	// unhealthy, fail with UNAVAILABLE instead of FAILED_PRECONDITION.
+	RetryableRequestErrors bool `json:"retryableRequestErrors,omitempty"`
+	// HealthIdentityMismatchFatal makes the tablet panic if a health
+	// response would advertise another tablet alias, keyspace, shard
+	// or cell than the ones it was initialized with.
+	HealthIdentityMismatchFatal bool `json:"healthIdentityMismatchFatal,omitempty"`
+	// MessageMaxSendRate is the max number of rows per second the
+	// messager sends for a message table that doesn't set its own
+	// vt_max_send_rate. 0 means no limit.
+	MessageMaxSendRate float64 `json:"messageMaxSendRate,omitempty"`
+	// DrainStreamsOnLameduck makes EnterLameduck ask the running
+	// streams to end at their next chunk boundary.
+	DrainStreamsOnLameduck bool `json:"drainStreamsOnLameduck,omitempty"`
+	// KeepVStreamerOnBackup keeps the schema engine and the vstreamer
+	// open while the tablet is a BACKUP, so that the vstreams against
+	// it survive the backup. Everything else is closed as usual.
+	KeepVStreamerOnBackup bool `json:"keepVStreamerOnBackup,omitempty"`
+	// ServingOrder is the order in which the master-only subcomponents
+	// are opened. They're closed in reverse. If empty, the default
+	// order is used.
+	ServingOrder []string `json:"servingOrder,omitempty"`
+	// SerialTransitionOpens makes the transitions open the
+	// subcomponents one at a time, in order, instead of opening
+	// the ones that don't depend on each other concurrently.
+	SerialTransitionOpens bool `json:"serialTransitionOpens,omitempty"`
+	// StateBuffersCapBytes caps the estimated memory used by the
+	// health stream subscribers, the health history, the replication
+	// lag history and the request tracker. The histories are shrunk
+	// to fit. 0 means no cap.
+	StateBuffersCapBytes int64 `json:"stateBuffersCapBytes,omitempty"`
+	// TransitionAuditLog is the file the events that drive
+	// the state transitions are appended to, if set.
+	TransitionAuditLog string `json:"transitionAuditLog,omitempty"`
+	// StructuredStateLogs makes the state manager log its
+	// decisions as JSON objects with fixed field names.
+	StructuredStateLogs bool `json:"structuredStateLogs,omitempty"`
+	// TerTimestampRegression can be reject, warn or ignore. It
+	// decides what happens when a tablet is made master with a
+	// timestamp older than its current one. Default is ignore.
+	TerTimestampRegression string `json:"terTimestampRegression,omitempty"`
+	// ThrottleAppThresholds overrides the lag throttler threshold
+	// for the apps listed. An app with a lower threshold is
+	// throttled earlier.
+	ThrottleAppThresholds map[string]Seconds `json:"throttleAppThresholds,omitempty"`
+	// KeyspaceAliases and ShardAliases map old keyspace and shard
+	// names to the current ones. Requests that target an old name
+	// are accepted as if they targeted the current one.
+	KeyspaceAliases map[string]string `json:"keyspaceAliases,omitempty"`
+	ShardAliases    map[string]string `json:"shardAliases,omitempty"`
+	// TableMaxRows overrides Oltp.MaxRows for the queries of the
+	// tables listed, streaming queries included.
+	TableMaxRows map[string]int `json:"tableMaxRows,omitempty"`
+	// TransactionCapsByCaller caps the number of concurrent
+	// transactions of the callers listed.
+	TransactionCapsByCaller map[string]int `json:"transactionCapsByCaller,omitempty"`
+	// ReadOnlyTransactionCap caps the number of concurrent read-only
+	// transactions. 0 means no cap other than the transaction pool.
+	ReadOnlyTransactionCap int `json:"readOnlyTransactionCap,omitempty"`
+	// ThrottleOnReplicas opens the lag throttler on replicas too.
+	// It then checks the replication lag of its own mysql.
+	ThrottleOnReplicas bool `json:"throttleOnReplicas,omitempty"`
+	// VStreamHeartbeatIntervalSeconds is the interval after which
+	// an idle vstream sends a heartbeat. 0 means the default.
+	VStreamHeartbeatIntervalSeconds Seconds `json:"vstreamHeartbeatIntervalSeconds,omitempty"`
+	// VStreamCopyMaxRowRate is the max number of rows per second
+	// the copy phase of a vstream reads when the lag throttler
+	// isn't open. 0 means no limit.
+	VStreamCopyMaxRowRate float64 `json:"vstreamCopyMaxRowRate,omitempty"`
+	// VStreamCopyProgressIntervalSeconds is the interval at which
+	// the copy phase of a vstream saves its progress in the sidecar
+	// database. 0 disables it.
+	VStreamCopyProgressIntervalSeconds Seconds `json:"vstreamCopyProgressIntervalSeconds,omitempty"`
+	// DBCreateCharset and DBCreateCollation are the default character
+	// set and collation of the database when a master creates it.
+	// If empty, the MySQL defaults are used.
+	DBCreateCharset   string `json:"dbCreateCharset,omitempty"`
+	DBCreateCollation string `json:"dbCreateCollation,omitempty"`
 
 	ExternalConnections map[string]*dbconfigs.DBConfigs `json:"externalConnections,omitempty"`
 
@@ -260,6 +502,7 @@ type TabletConfig struct {
 	EnableTxThrottler           bool     `json:"-"`
 	TxThrottlerConfig           string   `json:"-"`
 	TxThrottlerHealthCheckCells []string `json:"-"`
+	TxThrottlerDryRun           bool     `json:"-"`
 
 	TransactionLimitConfig `json:"-"`
 
@@ -281,6 +524,34 @@ type OltpConfig struct {
 	TxTimeoutSeconds    Seconds `json:"txTimeoutSeconds,omitempty"`
 	MaxRows             int     `json:"maxRpws,omitempty"`
 	WarnRows            int     `json:"warnRows,omitempty"`
+	// QueryTimeoutByTabletType overrides QueryTimeoutSeconds for
+	// the tablet types it lists. Its keys are lower case tablet
+	// type names.
+	QueryTimeoutByTabletType map[string]Seconds `json:"queryTimeoutByTabletType,omitempty"`
+}
+
+// QueryTimeoutOverrides returns QueryTimeoutByTabletType keyed by
+// tablet type. Invalid tablet types are rejected by Verify, and
+// skipped here.
+func (c *OltpConfig) QueryTimeoutOverrides() map[topodatapb.TabletType]time.Duration {
+	overrides := make(map[topodatapb.TabletType]time.Duration, len(c.QueryTimeoutByTabletType))
+	for name, timeout := range c.QueryTimeoutByTabletType {
+		tabletType, err := topoproto.ParseTabletType(name)
+		if err != nil {
+			continue
+		}
+		overrides[tabletType] = timeout.Get()
+	}
+	return overrides
+}
+
+// ConsolidatorMode returns the consolidator mode of tabletType: its
+// override in ConsolidatorByTabletType if any, Consolidator otherwise.
+func (c *TabletConfig) ConsolidatorMode(tabletType topodatapb.TabletType) string {
+	if mode, ok := c.ConsolidatorByTabletType[strings.ToLower(tabletType.String())]; ok {
+		return mode
+	}
+	return c.Consolidator
 }
 
 // HotRowProtectionConfig contains the config for hot row protection.
@@ -290,6 +561,21 @@ type HotRowProtectionConfig struct {
 	MaxQueueSize       int    `json:"maxQueueSize,omitempty"`
 	MaxGlobalQueueSize int    `json:"maxGlobalQueueSize,omitempty"`
 	MaxConcurrency     int    `json:"maxConcurrency,omitempty"`
+	// FailFastUnderPressure rejects the transactions for a hot row
+	// instead of queueing them while replication lags beyond the
+	// degraded threshold or the transaction pool is exhausted.
+	FailFastUnderPressure bool `json:"failFastUnderPressure,omitempty"`
+}
+
+// FairShareConfig contains the config for dividing the request
+// concurrency across the effective callers.
+type FairShareConfig struct {
+	Enable      bool `json:"enable,omitempty"`
+	Concurrency int  `json:"concurrency,omitempty"`
+	// MaxShare is the fraction of Concurrency a single caller
+	// can hold while others are waiting.
+	MaxShare        float64 `json:"maxShare,omitempty"`
+	CallerCacheSize int     `json:"callerCacheSize,omitempty"`
 }
 
 // HealthcheckConfig contains the config for healthcheck.
@@ -297,6 +583,62 @@ type HealthcheckConfig struct {
 	IntervalSeconds           Seconds `json:"intervalSeconds,omitempty"`
 	DegradedThresholdSeconds  Seconds `json:"degradedThresholdSeconds,omitempty"`
 	UnhealthyThresholdSeconds Seconds `json:"unhealthyThresholdSeconds,omitempty"`
+	// NotServingIntervalSeconds replaces IntervalSeconds as the
+	// health broadcast interval while the tablet is not serving.
+	NotServingIntervalSeconds Seconds `json:"notServingIntervalSeconds,omitempty"`
+	// LivenessThresholdSeconds is how long a state transition can
+	// be in progress before the liveness probe starts failing.
+	LivenessThresholdSeconds Seconds `json:"livenessThresholdSeconds,omitempty"`
+	// StuckTransitionThresholdSeconds is how long the serving state can
+	// differ from the desired one before the watchdog reports it.
+	// StuckTransitionHook is an optional vthook to run when it does.
+	StuckTransitionThresholdSeconds Seconds `json:"stuckTransitionThresholdSeconds,omitempty"`
+	StuckTransitionHook             string  `json:"stuckTransitionHook,omitempty"`
+	// FlapThreshold is the number of changes between serving and not
+	// serving within FlapWindowSeconds above which the tablet is
+	// flapping. FlapHook is an optional vthook to run when it starts
+	// and stops flapping. FlapIgnoreOperator leaves out the changes
+	// requested through SetServingType.
+	FlapThreshold      int     `json:"flapThreshold,omitempty"`
+	FlapWindowSeconds  Seconds `json:"flapWindowSeconds,omitempty"`
+	FlapHook           string  `json:"flapHook,omitempty"`
+	FlapIgnoreOperator bool    `json:"flapIgnoreOperator,omitempty"`
+	// LagHistorySize is the number of replication lag samples
+	// kept for /debug/repl_lag_history.
+	LagHistorySize int `json:"lagHistorySize,omitempty"`
+	// TopoIsolationTimeoutSeconds is how long a master can go without
+	// the tablet manager confirming that the topo is reachable before
+	// it stops serving.
+	TopoIsolationTimeoutSeconds Seconds `json:"topoIsolationTimeoutSeconds,omitempty"`
+	// RoleConfidenceDegradedThreshold is the role confidence, in
+	// percent, below which a master reports itself as degraded.
+	// The role confidence is only computed if it's set.
+	RoleConfidenceDegradedThreshold int `json:"roleConfidenceDegradedThreshold,omitempty"`
+	// PromotionCheck enables the promotion dry run at the health
+	// check interval, see /debug/promotable.
+	PromotionCheck bool `json:"promotionCheck,omitempty"`
+	// MySQLProbeIntervalSeconds is the interval at which a serving
+	// tablet checks that mysql is reachable, even without traffic.
+	MySQLProbeIntervalSeconds Seconds `json:"mysqlProbeIntervalSeconds,omitempty"`
+	// DegradedShedMaxFraction is the fraction of the requests a
+	// replica sheds when its lag reaches the unhealthy threshold.
+	// It sheds none at the degraded threshold.
+	DegradedShedMaxFraction float64 `json:"degradedShedMaxFraction,omitempty"`
+	// UnhealthySubcomponentsStopServing makes the tablet stop
+	// serving while a subcomponent reports itself unhealthy.
+	UnhealthySubcomponentsStopServing bool `json:"unhealthySubcomponentsStopServing,omitempty"`
+	// CheckMySQLMinErrorTables is the number of tables whose queries
+	// must have failed with connection errors recently for a mysql
+	// check that can still connect to mysql to shut down the query
+	// service.
+	CheckMySQLMinErrorTables int `json:"checkMySQLMinErrorTables,omitempty"`
+	// MaxStreamSubscribers caps the number of concurrent health
+	// streams. 0 means no limit.
+	MaxStreamSubscribers int `json:"maxStreamSubscribers,omitempty"`
+	// StreamIdleBroadcasts is the number of consecutive broadcasts
+	// that can't be delivered to a health stream before it's closed.
+	// 0 never closes the streams.
+	StreamIdleBroadcasts int `json:"streamIdleBroadcasts,omitempty"`
 }
 
 // GracePeriodsConfig contains various grace periods.
@@ -304,6 +646,24 @@ type HealthcheckConfig struct {
 type GracePeriodsConfig struct {
 	TransactionShutdownSeconds Seconds `json:"transactionShutdownSeconds,omitempty"`
 	TransitionSeconds          Seconds `json:"transitionSeconds,omitempty"`
+	// TransactionDrainSeconds is how long a demoted master waits
+	// for open transactions to complete before rolling them back.
+	TransactionDrainSeconds Seconds `json:"transactionDrainSeconds,omitempty"`
+	// QueryKillSeconds is how long a demoted master waits for
+	// running queries to complete before killing them.
+	QueryKillSeconds Seconds `json:"queryKillSeconds,omitempty"`
+	// LameduckOnTermSeconds is how long the tablet stays in lameduck
+	// on termination, before it stops the query service.
+	LameduckOnTermSeconds Seconds `json:"lameduckOnTermSeconds,omitempty"`
+	// TerminationSeconds is how long the process is given to exit
+	// on termination before it's killed.
+	TerminationSeconds Seconds `json:"terminationSeconds,omitempty"`
+	// MasterWritableWaitSeconds is how long a tablet that becomes
+	// master waits for mysql to stop being read-only.
+	MasterWritableWaitSeconds Seconds `json:"masterWritableWaitSeconds,omitempty"`
+	// TransitionTimeoutSeconds bounds the opens of a state
+	// transition. 0 means no timeout.
+	TransitionTimeoutSeconds Seconds `json:"transitionTimeoutSeconds,omitempty"`
 }
 
 // ReplicationTrackerConfig contains the config for the replication tracker.
@@ -311,6 +671,42 @@ type ReplicationTrackerConfig struct {
 	// Mode can be disable, polling or heartbeat. Default is disable.
 	Mode                     string  `json:"mode,omitempty"`
 	HeartbeatIntervalSeconds Seconds `json:"heartbeatIntervalSeconds,omitempty"`
+	// CrossCheck enables verifying a low lag against the replication
+	// threads and the retrieved GTID set, at most once per interval.
+	CrossCheck                bool    `json:"crossCheck,omitempty"`
+	CrossCheckIntervalSeconds Seconds `json:"crossCheckIntervalSeconds,omitempty"`
+	// ServeWithoutReplication allows non-master tablets to serve
+	// if mysql is not configured to replicate.
+	ServeWithoutReplication bool `json:"serveWithoutReplication,omitempty"`
+}
+
+// StateSnapshotConfig contains the config for carrying the serving
+// state over to a restarted process.
+type StateSnapshotConfig struct {
+	File          string  `json:"file,omitempty"`
+	MaxAgeSeconds Seconds `json:"maxAgeSeconds,omitempty"`
+}
+
+// PlanWarmupConfig contains the config for warming up the
+// plan cache of a new master. It's disabled if Count or File
+// is not set.
+type PlanWarmupConfig struct {
+	Count               int     `json:"count,omitempty"`
+	File                string  `json:"file,omitempty"`
+	SaveIntervalSeconds Seconds `json:"saveIntervalSeconds,omitempty"`
+	TimeoutSeconds      Seconds `json:"timeoutSeconds,omitempty"`
+}
+
+// Enabled returns true if the plan warmup is configured.
+func (c *PlanWarmupConfig) Enabled() bool {
+	return c.Count > 0 && c.File != ""
+}
+
+// SnapshotExportConfig contains the config of the
+// consistent snapshot exports.
+type SnapshotExportConfig struct {
+	MaxPauseSeconds Seconds `json:"maxPauseSeconds,omitempty"`
+	TTLSeconds      Seconds `json:"ttlSeconds,omitempty"`
 }
 
 // TransactionLimitConfig captures configuration of transaction pool slots
@@ -341,6 +737,38 @@ func (c *TabletConfig) Clone() *TabletConfig {
 	if tc.DB != nil {
 		tc.DB = c.DB.Clone()
 	}
+	if c.Oltp.QueryTimeoutByTabletType != nil {
+		tc.Oltp.QueryTimeoutByTabletType = make(map[string]Seconds, len(c.Oltp.QueryTimeoutByTabletType))
+		for tabletType, timeout := range c.Oltp.QueryTimeoutByTabletType {
+			tc.Oltp.QueryTimeoutByTabletType[tabletType] = timeout
+		}
+	}
+	if c.ThrottleAppThresholds != nil {
+		tc.ThrottleAppThresholds = make(map[string]Seconds, len(c.ThrottleAppThresholds))
+		for appName, threshold := range c.ThrottleAppThresholds {
+			tc.ThrottleAppThresholds[appName] = threshold
+		}
+	}
+	if c.ConsolidatorByTabletType != nil {
+		tc.ConsolidatorByTabletType = make(map[string]string, len(c.ConsolidatorByTabletType))
+		for tabletType, mode := range c.ConsolidatorByTabletType {
+			tc.ConsolidatorByTabletType[tabletType] = mode
+		}
+	}
+	tc.KeyspaceAliases = CloneAliases(c.KeyspaceAliases)
+	tc.ShardAliases = CloneAliases(c.ShardAliases)
+	if c.TableMaxRows != nil {
+		tc.TableMaxRows = make(map[string]int, len(c.TableMaxRows))
+		for table, rows := range c.TableMaxRows {
+			tc.TableMaxRows[table] = rows
+		}
+	}
+	if c.TransactionCapsByCaller != nil {
+		tc.TransactionCapsByCaller = make(map[string]int, len(c.TransactionCapsByCaller))
+		for caller, max := range c.TransactionCapsByCaller {
+			tc.TransactionCapsByCaller[caller] = max
+		}
+	}
 	return &tc
 }
 
@@ -361,6 +789,268 @@ func (c *TabletConfig) Verify() error {
 	if v := c.HotRowProtection.MaxConcurrency; v <= 0 {
 		return fmt.Errorf("-hot_row_protection_concurrent_transactions must be > 0 (specified value: %v)", v)
 	}
+	if err := c.verifyFairShareConfig(); err != nil {
+		return err
+	}
+	if v := c.MaxConcurrentRequestsPerCaller; v < 0 {
+		return fmt.Errorf("-max_concurrent_requests_per_caller must be >= 0 (specified value: %v)", v)
+	}
+	if err := c.verifyPoolConfig(); err != nil {
+		return err
+	}
+	if err := c.verifyHealthcheckConfig(); err != nil {
+		return err
+	}
+	if err := c.verifyGracePeriodsConfig(); err != nil {
+		return err
+	}
+	if err := c.verifyQueryTimeoutsConfig(); err != nil {
+		return err
+	}
+	if err := c.verifyConsolidatorConfig(); err != nil {
+		return err
+	}
+	for appName, threshold := range c.ThrottleAppThresholds {
+		if threshold <= 0 {
+			return fmt.Errorf("-throttle_app_thresholds must be > 0 (specified value for %v: %v)", appName, threshold)
+		}
+	}
+	switch c.TerTimestampRegression {
+	case Reject, Warn, Ignore:
+	default:
+		return fmt.Errorf("-ter_timestamp_regression must be one of %s, %s or %s (specified value: %q)", Reject, Warn, Ignore, c.TerTimestampRegression)
+	}
+	if err := VerifyAliases(c.KeyspaceAliases); err != nil {
+		return fmt.Errorf("-keyspace_aliases: %v", err)
+	}
+	if err := VerifyAliases(c.ShardAliases); err != nil {
+		return fmt.Errorf("-shard_aliases: %v", err)
+	}
+	for table, rows := range c.TableMaxRows {
+		if rows <= 0 {
+			return fmt.Errorf("-table_max_rows must be > 0 (specified value for %v: %v)", table, rows)
+		}
+	}
+	for caller, max := range c.TransactionCapsByCaller {
+		if max <= 0 {
+			return fmt.Errorf("-transaction_caps_by_caller must be > 0 (specified value for %v: %v)", caller, max)
+		}
+	}
+	if v := c.VStreamHeartbeatIntervalSeconds.Get(); v < 0 {
+		return fmt.Errorf("-vstream_heartbeat_interval must be >= 0 (specified value: %v)", v)
+	}
+	if v := c.VStreamCopyProgressIntervalSeconds.Get(); v < 0 {
+		return fmt.Errorf("-vstream_copy_progress_interval must be >= 0 (specified value: %v)", v)
+	}
+	if v := c.VStreamCopyMaxRowRate; v < 0 {
+		return fmt.Errorf("-vstream_copy_max_row_rate must be >= 0 (specified value: %v)", v)
+	}
+	if v := c.MessageMaxSendRate; v < 0 {
+		return fmt.Errorf("-queryserver-config-message-max-send-rate must be >= 0 (specified value: %v)", v)
+	}
+	if v := c.PlanWarmup.Count; v < 0 {
+		return fmt.Errorf("-plan_warmup_count must be >= 0 (specified value: %v)", v)
+	}
+	if v := c.SnapshotExport.MaxPauseSeconds.Get(); v <= 0 {
+		return fmt.Errorf("-snapshot_export_max_pause must be > 0 (specified value: %v)", v)
+	}
+	if v := c.SnapshotExport.TTLSeconds.Get(); v <= 0 {
+		return fmt.Errorf("-snapshot_export_ttl must be > 0 (specified value: %v)", v)
+	}
+	if v := c.DBCreateCharset; !charsetNameRegexp.MatchString(v) {
+		return fmt.Errorf("-db_create_charset must be a character set name (specified value: %v)", v)
+	}
+	if v := c.DBCreateCollation; !charsetNameRegexp.MatchString(v) {
+		return fmt.Errorf("-db_create_collation must be a collation name (specified value: %v)", v)
+	}
+	return nil
+}
+
+// charsetNameRegexp matches the names of the character sets
+// and collations, or an empty string.
+var charsetNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_]*$`)
+
+// VerifyAliases checks that aliases maps non-empty old names to
+// other names, which aren't aliases themselves.
+func VerifyAliases(aliases map[string]string) error {
+	for oldName, newName := range aliases {
+		switch {
+		case oldName == "" || newName == "":
+			return fmt.Errorf("empty name in %q:%q", oldName, newName)
+		case oldName == newName:
+			return fmt.Errorf("%v is an alias of itself", oldName)
+		}
+		if _, ok := aliases[newName]; ok {
+			return fmt.Errorf("%v is an alias of %v, which is an alias too", oldName, newName)
+		}
+	}
+	return nil
+}
+
+// CloneAliases returns a copy of aliases.
+func CloneAliases(aliases map[string]string) map[string]string {
+	if aliases == nil {
+		return nil
+	}
+	clone := make(map[string]string, len(aliases))
+	for oldName, newName := range aliases {
+		clone[oldName] = newName
+	}
+	return clone
+}
+
+// verifyPoolConfig checks the pool sizes for sanity.
+func (c *TabletConfig) verifyQueryTimeoutsConfig() error {
+	for name, timeout := range c.Oltp.QueryTimeoutByTabletType {
+		if _, err := topoproto.ParseTabletType(name); err != nil {
+			return fmt.Errorf("-queryserver-config-query-timeout-by-tablet-type: %v", err)
+		}
+		if timeout < 0 {
+			return fmt.Errorf("-queryserver-config-query-timeout-by-tablet-type must be >= 0 (specified value for %v: %v)", name, timeout)
+		}
+	}
+	return nil
+}
+
+func (c *TabletConfig) verifyConsolidatorConfig() error {
+	for name, mode := range c.ConsolidatorByTabletType {
+		if _, err := topoproto.ParseTabletType(name); err != nil {
+			return fmt.Errorf("-consolidator-by-tablet-type: %v", err)
+		}
+		if mode != Enable && mode != Disable {
+			return fmt.Errorf("-consolidator-by-tablet-type must be enable or disable (specified value for %v: %q)", name, mode)
+		}
+	}
+	return nil
+}
+
+func (c *TabletConfig) verifyPoolConfig() error {
+	if v := c.OltpReadPool.Size; v < 0 {
+		return fmt.Errorf("-queryserver-config-pool-size must be >= 0 (specified value: %v)", v)
+	}
+	if v := c.OlapReadPool.Size; v < 0 {
+		return fmt.Errorf("-queryserver-config-stream-pool-size must be >= 0 (specified value: %v)", v)
+	}
+	if v := c.TxPool.Size; v < 0 {
+		return fmt.Errorf("-queryserver-config-transaction-cap must be >= 0 (specified value: %v)", v)
+	}
+	if v := c.ReadOnlyTransactionCap; v < 0 {
+		return fmt.Errorf("-queryserver-config-read-only-transaction-cap must be >= 0 (specified value: %v)", v)
+	}
+	return nil
+}
+
+// verifyHealthcheckConfig checks HealthcheckConfig for sanity.
+func (c *TabletConfig) verifyHealthcheckConfig() error {
+	if v := c.Healthcheck.IntervalSeconds.Get(); v <= 0 {
+		return fmt.Errorf("-health_check_interval must be > 0 (specified value: %v)", v)
+	}
+	if v := c.Healthcheck.NotServingIntervalSeconds.Get(); v < 0 {
+		return fmt.Errorf("-health_check_interval_not_serving must be >= 0 (specified value: %v)", v)
+	}
+	degraded, unhealthy := c.Healthcheck.DegradedThresholdSeconds.Get(), c.Healthcheck.UnhealthyThresholdSeconds.Get()
+	if degraded <= 0 {
+		return fmt.Errorf("-degraded_threshold must be > 0 (specified value: %v)", degraded)
+	}
+	if unhealthy <= degraded {
+		return fmt.Errorf("-unhealthy_threshold must be > -degraded_threshold (%v <= %v)", unhealthy, degraded)
+	}
+	if v := c.Healthcheck.MySQLProbeIntervalSeconds.Get(); v < 0 {
+		return fmt.Errorf("-mysql_probe_interval must be >= 0 (specified value: %v)", v)
+	}
+	if v := c.Healthcheck.CheckMySQLMinErrorTables; v < 0 {
+		return fmt.Errorf("-check_mysql_min_error_tables must be >= 0 (specified value: %v)", v)
+	}
+	if v := c.Healthcheck.DegradedShedMaxFraction; v < 0 || v > 1 {
+		return fmt.Errorf("-degraded_shed_max_fraction must be between 0 and 1 (specified value: %v)", v)
+	}
+	if v := c.Healthcheck.FlapThreshold; v < 0 {
+		return fmt.Errorf("-serving_flap_threshold must be >= 0 (specified value: %v)", v)
+	}
+	if v := c.Healthcheck.FlapWindowSeconds.Get(); c.Healthcheck.FlapThreshold != 0 && v <= 0 {
+		return fmt.Errorf("-serving_flap_window must be > 0 if -serving_flap_threshold is set (specified value: %v)", v)
+	}
+	if v := c.Healthcheck.LagHistorySize; v < 0 {
+		return fmt.Errorf("-repl_lag_history_size must be >= 0 (specified value: %v)", v)
+	}
+	if v := c.Healthcheck.MaxStreamSubscribers; v < 0 {
+		return fmt.Errorf("-health_stream_max_subscribers must be >= 0 (specified value: %v)", v)
+	}
+	if v := c.Healthcheck.StreamIdleBroadcasts; v < 0 {
+		return fmt.Errorf("-health_stream_idle_broadcasts must be >= 0 (specified value: %v)", v)
+	}
+	return nil
+}
+
+// verifyGracePeriodsConfig checks the grace periods against each
+// other. The state manager relies on a transition grace period that
+// ends before the transactions are rolled back at shutdown, and on a
+// shutdown that completes before the transition timebomb, which is
+// 10 times -queryserver-config-query-pool-timeout, crashes vttablet.
+// A zero value disables the corresponding mechanism, and isn't checked.
+func (c *TabletConfig) verifyGracePeriodsConfig() error {
+	transition := c.GracePeriods.TransitionSeconds.Get()
+	shutdown := c.GracePeriods.TransactionShutdownSeconds.Get()
+	timebomb := c.OltpReadPool.TimeoutSeconds.Get() * 10
+	if transition < 0 {
+		return fmt.Errorf("-serving_state_grace_period must be >= 0 (specified value: %v)", transition)
+	}
+	if shutdown < 0 {
+		return fmt.Errorf("-transaction_shutdown_grace_period must be >= 0 (specified value: %v)", shutdown)
+	}
+	if transition != 0 && shutdown != 0 && transition >= shutdown {
+		return fmt.Errorf("-serving_state_grace_period must be < -transaction_shutdown_grace_period (%v >= %v)", transition, shutdown)
+	}
+	lameduck := c.GracePeriods.LameduckOnTermSeconds.Get()
+	termination := c.GracePeriods.TerminationSeconds.Get()
+	if lameduck < 0 {
+		return fmt.Errorf("-lameduck_on_term must be >= 0 (specified value: %v)", lameduck)
+	}
+	if termination < 0 {
+		return fmt.Errorf("-termination_grace_period must be >= 0 (specified value: %v)", termination)
+	}
+	if termination != 0 && lameduck+shutdown >= termination {
+		return fmt.Errorf("-lameduck_on_term + -transaction_shutdown_grace_period must be < -termination_grace_period (%v + %v >= %v)", lameduck, shutdown, termination)
+	}
+	writableWait := c.GracePeriods.MasterWritableWaitSeconds.Get()
+	if writableWait < 0 {
+		return fmt.Errorf("-master_writable_wait must be >= 0 (specified value: %v)", writableWait)
+	}
+	if timeout := c.GracePeriods.TransitionTimeoutSeconds.Get(); timeout < 0 {
+		return fmt.Errorf("-transition_timeout must be >= 0 (specified value: %v)", timeout)
+	}
+	if timebomb == 0 {
+		return nil
+	}
+	if writableWait >= timebomb {
+		return fmt.Errorf("-master_writable_wait must be < 10 * -queryserver-config-query-pool-timeout, the transition timebomb (%v >= %v)", writableWait, timebomb)
+	}
+	if shutdown >= timebomb {
+		return fmt.Errorf("-transaction_shutdown_grace_period must be < 10 * -queryserver-config-query-pool-timeout, the transition timebomb (%v >= %v)", shutdown, timebomb)
+	}
+	if transition >= timebomb {
+		return fmt.Errorf("-serving_state_grace_period must be < 10 * -queryserver-config-query-pool-timeout, the transition timebomb (%v >= %v)", transition, timebomb)
+	}
+	return nil
+}
+
+// verifyFairShareConfig checks FairShareConfig for sanity
+func (c *TabletConfig) verifyFairShareConfig() error {
+	if !c.FairShare.Enable {
+		return nil
+	}
+	if v := c.FairShare.Concurrency; v <= 0 {
+		return fmt.Errorf("-fair_share_concurrency must be > 0 (specified value: %v)", v)
+	}
+	if v := c.FairShare.MaxShare; v <= 0 || v > 1 {
+		return fmt.Errorf("-fair_share_max_share should be a fraction within range (0, 1] (specified value: %v)", v)
+	}
+	if share := int(c.FairShare.MaxShare * float64(c.FairShare.Concurrency)); share == 0 {
+		return fmt.Errorf("effective fair share per caller is 0 due to rounding, increase -fair_share_max_share")
+	}
+	if v := c.FairShare.CallerCacheSize; v <= 0 {
+		return fmt.Errorf("-fair_share_caller_cache_size must be > 0 (specified value: %v)", v)
+	}
 	return nil
 }
 
@@ -418,13 +1108,33 @@ var defaultConfig = TabletConfig{
 		MaxRows:             10000,
 	},
 	Healthcheck: HealthcheckConfig{
-		IntervalSeconds:           20,
-		DegradedThresholdSeconds:  30,
-		UnhealthyThresholdSeconds: 7200,
+		IntervalSeconds:                 20,
+		DegradedThresholdSeconds:        30,
+		UnhealthyThresholdSeconds:       7200,
+		LivenessThresholdSeconds:        300,
+		StuckTransitionThresholdSeconds: 600,
+		FlapWindowSeconds:               600,
+		LagHistorySize:                  300,
+		CheckMySQLMinErrorTables:        2,
+	},
+	GracePeriods: GracePeriodsConfig{
+		MasterWritableWaitSeconds: 5,
 	},
 	ReplicationTracker: ReplicationTrackerConfig{
-		Mode:                     Disable,
-		HeartbeatIntervalSeconds: 0.25,
+		Mode:                      Disable,
+		HeartbeatIntervalSeconds:  0.25,
+		CrossCheckIntervalSeconds: 20,
+	},
+	StateSnapshot: StateSnapshotConfig{
+		MaxAgeSeconds: 60,
+	},
+	PlanWarmup: PlanWarmupConfig{
+		SaveIntervalSeconds: 60,
+		TimeoutSeconds:      5,
+	},
+	SnapshotExport: SnapshotExportConfig{
+		MaxPauseSeconds: 5,
+		TTLSeconds:      600,
 	},
 	HotRowProtection: HotRowProtectionConfig{
 		Mode: Disable,
@@ -435,7 +1145,15 @@ var defaultConfig = TabletConfig{
 		// of them ready in MySQL and profit from a pipelining effect.
 		MaxConcurrency: 5,
 	},
-	Consolidator: Enable,
+	FairShare: FairShareConfig{
+		Concurrency: 100,
+		// A single caller can hold up to half of the capacity
+		// while others are waiting.
+		MaxShare:        0.5,
+		CallerCacheSize: 1000,
+	},
+	Consolidator:           Enable,
+	TerTimestampRegression: Ignore,
 	// The value for StreamBufferSize was chosen after trying out a few of
 	// them. Too small buffers force too many packets to be sent. Too big
 	// buffers force the clients to read them in multiple chunks and make
@@ -24,6 +24,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"vitess.io/vitess/go/vt/dbconfigs"
 	"vitess.io/vitess/go/yaml2"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
 func TestConfigParse(t *testing.T) {
@@ -63,6 +65,7 @@ func TestConfigParse(t *testing.T) {
   repl:
     password: '****'
   socket: a
+fairShare: {}
 gracePeriods: {}
 healthcheck: {}
 hotRowProtection: {}
@@ -74,7 +77,10 @@ oltpReadPool:
   prefillParallelism: 30
   size: 16
   timeoutSeconds: 10
+planWarmup: {}
 replicationTracker: {}
+snapshotExport: {}
+stateSnapshot: {}
 txPool: {}
 `
 	assert.Equal(t, wantBytes, string(gotBytes))
@@ -107,10 +113,20 @@ func TestDefaultConfig(t *testing.T) {
 	require.NoError(t, err)
 	want := `cacheResultFields: true
 consolidator: enable
-gracePeriods: {}
+fairShare:
+  callerCacheSize: 1000
+  concurrency: 100
+  maxShare: 0.5
+gracePeriods:
+  masterWritableWaitSeconds: 5
 healthcheck:
+  checkMySQLMinErrorTables: 2
   degradedThresholdSeconds: 30
+  flapWindowSeconds: 600
   intervalSeconds: 20
+  lagHistorySize: 300
+  livenessThresholdSeconds: 300
+  stuckTransitionThresholdSeconds: 600
   unhealthyThresholdSeconds: 7200
 hotRowProtection:
   maxConcurrency: 5
@@ -129,12 +145,22 @@ oltpReadPool:
   idleTimeoutSeconds: 1800
   maxWaiters: 5000
   size: 16
+planWarmup:
+  saveIntervalSeconds: 60
+  timeoutSeconds: 5
 queryCacheSize: 5000
 replicationTracker:
+  crossCheckIntervalSeconds: 20
   heartbeatIntervalSeconds: 0.25
   mode: disable
 schemaReloadIntervalSeconds: 1800
+snapshotExport:
+  maxPauseSeconds: 5
+  ttlSeconds: 600
+stateSnapshot:
+  maxAgeSeconds: 60
 streamBufferSize: 32768
+terTimestampRegression: ignore
 txPool:
   idleTimeoutSeconds: 1800
   maxWaiters: 5000
@@ -188,6 +214,35 @@ func TestFlags(t *testing.T) {
 			MaxGlobalQueueSize: 1000,
 			MaxConcurrency:     5,
 		},
+		FairShare: FairShareConfig{
+			Concurrency:     100,
+			MaxShare:        0.5,
+			CallerCacheSize: 1000,
+		},
+		Healthcheck: HealthcheckConfig{
+			LivenessThresholdSeconds:        300,
+			StuckTransitionThresholdSeconds: 600,
+			FlapWindowSeconds:               600,
+			LagHistorySize:                  300,
+			CheckMySQLMinErrorTables:        2,
+		},
+		GracePeriods: GracePeriodsConfig{
+			MasterWritableWaitSeconds: 5,
+		},
+		ReplicationTracker: ReplicationTrackerConfig{
+			CrossCheckIntervalSeconds: 20,
+		},
+		StateSnapshot: StateSnapshotConfig{
+			MaxAgeSeconds: 60,
+		},
+		PlanWarmup: PlanWarmupConfig{
+			SaveIntervalSeconds: 60,
+			TimeoutSeconds:      5,
+		},
+		SnapshotExport: SnapshotExportConfig{
+			MaxPauseSeconds: 5,
+			TTLSeconds:      600,
+		},
 		StreamBufferSize:            32768,
 		QueryCacheSize:              5000,
 		SchemaReloadIntervalSeconds: 1800,
@@ -202,6 +257,7 @@ func TestFlags(t *testing.T) {
 			TransactionLimitByPrincipal: true,
 		},
 		EnforceStrictTransTables: true,
+		TerTimestampRegression:   Ignore,
 		DB:                       &dbconfigs.DBConfigs{},
 	}
 	assert.Equal(t, want.DB, currentConfig.DB)
@@ -320,3 +376,289 @@ func TestFlags(t *testing.T) {
 	want.GracePeriods.TransitionSeconds = 4
 	assert.Equal(t, want, currentConfig)
 }
+
+func TestVerify(t *testing.T) {
+	testcases := []struct {
+		name   string
+		update func(*TabletConfig)
+		err    string
+	}{{
+		name:   "default",
+		update: func(*TabletConfig) {},
+	}, {
+		name: "limit and dry run",
+		update: func(c *TabletConfig) {
+			c.EnableTransactionLimit = true
+			c.EnableTransactionLimitDryRun = true
+		},
+		err: "only one of two flags allowed: -enable_transaction_limit or -enable_transaction_limit_dry_run",
+	}, {
+		name:   "hot row queue size",
+		update: func(c *TabletConfig) { c.HotRowProtection.MaxQueueSize = 0 },
+		err:    "-hot_row_protection_max_queue_size must be > 0 (specified value: 0)",
+	}, {
+		name:   "hot row global queue size",
+		update: func(c *TabletConfig) { c.HotRowProtection.MaxGlobalQueueSize = 10 },
+		err:    "global queue size must be >= per row (range) queue size: -hot_row_protection_max_global_queue_size < hot_row_protection_max_queue_size (10 < 20)",
+	}, {
+		name: "fair share",
+		update: func(c *TabletConfig) {
+			c.FairShare.Enable = true
+			c.FairShare.Concurrency = 0
+		},
+		err: "-fair_share_concurrency must be > 0 (specified value: 0)",
+	}, {
+		name:   "pool size",
+		update: func(c *TabletConfig) { c.OltpReadPool.Size = -1 },
+		err:    "-queryserver-config-pool-size must be >= 0 (specified value: -1)",
+	}, {
+		name:   "stream pool size",
+		update: func(c *TabletConfig) { c.OlapReadPool.Size = -1 },
+		err:    "-queryserver-config-stream-pool-size must be >= 0 (specified value: -1)",
+	}, {
+		name:   "transaction cap",
+		update: func(c *TabletConfig) { c.TxPool.Size = -1 },
+		err:    "-queryserver-config-transaction-cap must be >= 0 (specified value: -1)",
+	}, {
+		name:   "read-only transaction cap",
+		update: func(c *TabletConfig) { c.ReadOnlyTransactionCap = -1 },
+		err:    "-queryserver-config-read-only-transaction-cap must be >= 0 (specified value: -1)",
+	}, {
+		name:   "health check interval",
+		update: func(c *TabletConfig) { c.Healthcheck.IntervalSeconds = 0 },
+		err:    "-health_check_interval must be > 0 (specified value: 0s)",
+	}, {
+		name:   "not serving health check interval",
+		update: func(c *TabletConfig) { c.Healthcheck.NotServingIntervalSeconds = -1 },
+		err:    "-health_check_interval_not_serving must be >= 0 (specified value: -1s)",
+	}, {
+		name:   "degraded threshold",
+		update: func(c *TabletConfig) { c.Healthcheck.DegradedThresholdSeconds = 0 },
+		err:    "-degraded_threshold must be > 0 (specified value: 0s)",
+	}, {
+		name:   "unhealthy threshold",
+		update: func(c *TabletConfig) { c.Healthcheck.UnhealthyThresholdSeconds = 10 },
+		err:    "-unhealthy_threshold must be > -degraded_threshold (10s <= 30s)",
+	}, {
+		name:   "throttle app threshold",
+		update: func(c *TabletConfig) { c.ThrottleAppThresholds = map[string]Seconds{"vreplication": 0} },
+		err:    "-throttle_app_thresholds must be > 0 (specified value for vreplication: 0)",
+	}, {
+		name:   "negative max concurrent requests per caller",
+		update: func(c *TabletConfig) { c.MaxConcurrentRequestsPerCaller = -1 },
+		err:    "-max_concurrent_requests_per_caller must be >= 0 (specified value: -1)",
+	}, {
+		name:   "keyspace alias of itself",
+		update: func(c *TabletConfig) { c.KeyspaceAliases = map[string]string{"ks": "ks"} },
+		err:    "-keyspace_aliases: ks is an alias of itself",
+	}, {
+		name:   "chained shard aliases",
+		update: func(c *TabletConfig) { c.ShardAliases = map[string]string{"0": "-", "-": "-80"} },
+		err:    "-shard_aliases: 0 is an alias of -, which is an alias too",
+	}, {
+		name:   "empty keyspace alias",
+		update: func(c *TabletConfig) { c.KeyspaceAliases = map[string]string{"old": ""} },
+		err:    `-keyspace_aliases: empty name in "old":""`,
+	}, {
+		name:   "table max rows",
+		update: func(c *TabletConfig) { c.TableMaxRows = map[string]int{"events": 0} },
+		err:    "-table_max_rows must be > 0 (specified value for events: 0)",
+	}, {
+		name:   "transaction caps by caller",
+		update: func(c *TabletConfig) { c.TransactionCapsByCaller = map[string]int{"batch": -1} },
+		err:    "-transaction_caps_by_caller must be > 0 (specified value for batch: -1)",
+	}, {
+		name:   "negative vstream heartbeat interval",
+		update: func(c *TabletConfig) { c.VStreamHeartbeatIntervalSeconds = -1 },
+		err:    "-vstream_heartbeat_interval must be >= 0 (specified value: -1s)",
+	}, {
+		name:   "negative vstream copy progress interval",
+		update: func(c *TabletConfig) { c.VStreamCopyProgressIntervalSeconds = -1 },
+		err:    "-vstream_copy_progress_interval must be >= 0 (specified value: -1s)",
+	}, {
+		name:   "negative vstream copy row rate",
+		update: func(c *TabletConfig) { c.VStreamCopyMaxRowRate = -1 },
+		err:    "-vstream_copy_max_row_rate must be >= 0 (specified value: -1)",
+	}, {
+		name:   "negative message send rate",
+		update: func(c *TabletConfig) { c.MessageMaxSendRate = -1 },
+		err:    "-queryserver-config-message-max-send-rate must be >= 0 (specified value: -1)",
+	}, {
+		name:   "zero snapshot export max pause",
+		update: func(c *TabletConfig) { c.SnapshotExport.MaxPauseSeconds = 0 },
+		err:    "-snapshot_export_max_pause must be > 0 (specified value: 0s)",
+	}, {
+		name:   "negative snapshot export ttl",
+		update: func(c *TabletConfig) { c.SnapshotExport.TTLSeconds = -1 },
+		err:    "-snapshot_export_ttl must be > 0 (specified value: -1s)",
+	}, {
+		name:   "invalid db create charset",
+		update: func(c *TabletConfig) { c.DBCreateCharset = "utf8mb4; drop" },
+		err:    "-db_create_charset must be a character set name (specified value: utf8mb4; drop)",
+	}, {
+		name:   "invalid db create collation",
+		update: func(c *TabletConfig) { c.DBCreateCollation = "utf8mb4-bin" },
+		err:    "-db_create_collation must be a collation name (specified value: utf8mb4-bin)",
+	}, {
+		name:   "negative mysql probe interval",
+		update: func(c *TabletConfig) { c.Healthcheck.MySQLProbeIntervalSeconds = -1 },
+		err:    "-mysql_probe_interval must be >= 0 (specified value: -1s)",
+	}, {
+		name:   "negative check mysql min error tables",
+		update: func(c *TabletConfig) { c.Healthcheck.CheckMySQLMinErrorTables = -1 },
+		err:    "-check_mysql_min_error_tables must be >= 0 (specified value: -1)",
+	}, {
+		name:   "unknown ter timestamp regression",
+		update: func(c *TabletConfig) { c.TerTimestampRegression = "fail" },
+		err:    `-ter_timestamp_regression must be one of reject, warn or ignore (specified value: "fail")`,
+	}, {
+		name:   "degraded shed fraction over 1",
+		update: func(c *TabletConfig) { c.Healthcheck.DegradedShedMaxFraction = 1.5 },
+		err:    "-degraded_shed_max_fraction must be between 0 and 1 (specified value: 1.5)",
+	}, {
+		name:   "negative serving flap threshold",
+		update: func(c *TabletConfig) { c.Healthcheck.FlapThreshold = -1 },
+		err:    "-serving_flap_threshold must be >= 0 (specified value: -1)",
+	}, {
+		name: "serving flap threshold without window",
+		update: func(c *TabletConfig) {
+			c.Healthcheck.FlapThreshold = 3
+			c.Healthcheck.FlapWindowSeconds = 0
+		},
+		err: "-serving_flap_window must be > 0 if -serving_flap_threshold is set (specified value: 0s)",
+	}, {
+		name:   "negative repl lag history size",
+		update: func(c *TabletConfig) { c.Healthcheck.LagHistorySize = -1 },
+		err:    "-repl_lag_history_size must be >= 0 (specified value: -1)",
+	}, {
+		name:   "negative health stream max subscribers",
+		update: func(c *TabletConfig) { c.Healthcheck.MaxStreamSubscribers = -1 },
+		err:    "-health_stream_max_subscribers must be >= 0 (specified value: -1)",
+	}, {
+		name:   "negative health stream idle broadcasts",
+		update: func(c *TabletConfig) { c.Healthcheck.StreamIdleBroadcasts = -1 },
+		err:    "-health_stream_idle_broadcasts must be >= 0 (specified value: -1)",
+	}, {
+		name:   "negative transition grace period",
+		update: func(c *TabletConfig) { c.GracePeriods.TransitionSeconds = -1 },
+		err:    "-serving_state_grace_period must be >= 0 (specified value: -1s)",
+	}, {
+		name:   "negative master writable wait",
+		update: func(c *TabletConfig) { c.GracePeriods.MasterWritableWaitSeconds = -1 },
+		err:    "-master_writable_wait must be >= 0 (specified value: -1s)",
+	}, {
+		name:   "negative transition timeout",
+		update: func(c *TabletConfig) { c.GracePeriods.TransitionTimeoutSeconds = -1 },
+		err:    "-transition_timeout must be >= 0 (specified value: -1s)",
+	}, {
+		name:   "negative shutdown grace period",
+		update: func(c *TabletConfig) { c.GracePeriods.TransactionShutdownSeconds = -1 },
+		err:    "-transaction_shutdown_grace_period must be >= 0 (specified value: -1s)",
+	}, {
+		name: "transition grace period after shutdown",
+		update: func(c *TabletConfig) {
+			c.GracePeriods.TransitionSeconds = 10
+			c.GracePeriods.TransactionShutdownSeconds = 5
+		},
+		err: "-serving_state_grace_period must be < -transaction_shutdown_grace_period (10s >= 5s)",
+	}, {
+		name: "shutdown after timebomb",
+		update: func(c *TabletConfig) {
+			c.OltpReadPool.TimeoutSeconds = 1
+			c.GracePeriods.TransactionShutdownSeconds = 10
+		},
+		err: "-transaction_shutdown_grace_period must be < 10 * -queryserver-config-query-pool-timeout, the transition timebomb (10s >= 10s)",
+	}, {
+		name: "transition grace period after timebomb",
+		update: func(c *TabletConfig) {
+			c.OltpReadPool.TimeoutSeconds = 1
+			c.GracePeriods.TransitionSeconds = 20
+		},
+		err: "-serving_state_grace_period must be < 10 * -queryserver-config-query-pool-timeout, the transition timebomb (20s >= 10s)",
+	}, {
+		name: "master writable wait after timebomb",
+		update: func(c *TabletConfig) {
+			c.OltpReadPool.TimeoutSeconds = 1
+			c.GracePeriods.MasterWritableWaitSeconds = 10
+		},
+		err: "-master_writable_wait must be < 10 * -queryserver-config-query-pool-timeout, the transition timebomb (10s >= 10s)",
+	}, {
+		name: "grace periods in order",
+		update: func(c *TabletConfig) {
+			c.OltpReadPool.TimeoutSeconds = 3
+			c.GracePeriods.TransitionSeconds = 5
+			c.GracePeriods.TransactionShutdownSeconds = 20
+		},
+	}, {
+		name:   "negative lameduck on term",
+		update: func(c *TabletConfig) { c.GracePeriods.LameduckOnTermSeconds = -1 },
+		err:    "-lameduck_on_term must be >= 0 (specified value: -1s)",
+	}, {
+		name: "lameduck on term beyond termination",
+		update: func(c *TabletConfig) {
+			c.GracePeriods.LameduckOnTermSeconds = 20
+			c.GracePeriods.TransactionShutdownSeconds = 10
+			c.GracePeriods.TerminationSeconds = 30
+		},
+		err: "-lameduck_on_term + -transaction_shutdown_grace_period must be < -termination_grace_period (20s + 10s >= 30s)",
+	}, {
+		name: "lameduck on term within termination",
+		update: func(c *TabletConfig) {
+			c.GracePeriods.LameduckOnTermSeconds = 15
+			c.GracePeriods.TransactionShutdownSeconds = 10
+			c.GracePeriods.TerminationSeconds = 30
+		},
+	}, {
+		name:   "query timeout tablet type",
+		update: func(c *TabletConfig) { c.Oltp.QueryTimeoutByTabletType = map[string]Seconds{"reader": 10} },
+		err:    "-queryserver-config-query-timeout-by-tablet-type: unknown TabletType reader",
+	}, {
+		name:   "negative query timeout override",
+		update: func(c *TabletConfig) { c.Oltp.QueryTimeoutByTabletType = map[string]Seconds{"rdonly": -1} },
+		err:    "-queryserver-config-query-timeout-by-tablet-type must be >= 0 (specified value for rdonly: -1)",
+	}, {
+		name: "query timeout overrides",
+		update: func(c *TabletConfig) {
+			c.Oltp.QueryTimeoutByTabletType = map[string]Seconds{"rdonly": 3600, "replica": 0}
+		},
+	}, {
+		name:   "consolidator tablet type",
+		update: func(c *TabletConfig) { c.ConsolidatorByTabletType = map[string]string{"reader": Enable} },
+		err:    "-consolidator-by-tablet-type: unknown TabletType reader",
+	}, {
+		name:   "consolidator mode override",
+		update: func(c *TabletConfig) { c.ConsolidatorByTabletType = map[string]string{"master": NotOnMaster} },
+		err:    `-consolidator-by-tablet-type must be enable or disable (specified value for master: "notOnMaster")`,
+	}, {
+		name: "consolidator overrides",
+		update: func(c *TabletConfig) {
+			c.ConsolidatorByTabletType = map[string]string{"master": Disable, "replica": Enable}
+		},
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			cfg := NewDefaultConfig()
+			tcase.update(cfg)
+			err := cfg.Verify()
+			if tcase.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tcase.err)
+		})
+	}
+}
+
+func TestConsolidatorMode(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.Consolidator = NotOnMaster
+	cfg.ConsolidatorByTabletType = map[string]string{"master": Disable, "rdonly": Enable}
+	assert.Equal(t, Disable, cfg.ConsolidatorMode(topodatapb.TabletType_MASTER))
+	assert.Equal(t, Enable, cfg.ConsolidatorMode(topodatapb.TabletType_RDONLY))
+	assert.Equal(t, NotOnMaster, cfg.ConsolidatorMode(topodatapb.TabletType_REPLICA))
+
+	// The overrides aren't shared by the clones.
+	clone := cfg.Clone()
+	clone.ConsolidatorByTabletType["master"] = Enable
+	assert.Equal(t, Disable, cfg.ConsolidatorMode(topodatapb.TabletType_MASTER))
+}
@@ -30,7 +30,9 @@ type Stats struct {
 	QueryTimings           *servenv.TimingsWrapper        // Query timings
 	QPSRates               *stats.Rates                   // Human readable QPS rates
 	WaitTimings            *servenv.TimingsWrapper        // waits like Consolidations etc
+	AdmissionTimings       *servenv.TimingsWrapper        // Time spent in StartRequest before execution
 	KillCounters           *stats.CountersWithSingleLabel // Connection and transaction kills
+	KillReasons            *stats.CountersWithSingleLabel // Query and transaction kills by reason
 	ErrorCounters          *stats.CountersWithSingleLabel
 	InternalErrors         *stats.CountersWithSingleLabel
 	Warnings               *stats.CountersWithSingleLabel
@@ -52,10 +54,12 @@ type Stats struct {
 // NewStats instantiates a new set of stats scoped by exporter.
 func NewStats(exporter *servenv.Exporter) *Stats {
 	stats := &Stats{
-		MySQLTimings: exporter.NewTimings("Mysql", "MySQl query time", "operation"),
-		QueryTimings: exporter.NewTimings("Queries", "MySQL query timings", "plan_type"),
-		WaitTimings:  exporter.NewTimings("Waits", "Wait operations", "type"),
-		KillCounters: exporter.NewCountersWithSingleLabel("Kills", "Number of connections being killed", "query_type", "Transactions", "Queries", "ReservedConnection"),
+		MySQLTimings:     exporter.NewTimings("Mysql", "MySQl query time", "operation"),
+		QueryTimings:     exporter.NewTimings("Queries", "MySQL query timings", "plan_type"),
+		WaitTimings:      exporter.NewTimings("Waits", "Wait operations", "type"),
+		AdmissionTimings: exporter.NewTimings("Admissions", "Time spent admitting requests", "request"),
+		KillCounters:     exporter.NewCountersWithSingleLabel("Kills", "Number of connections being killed", "query_type", "Transactions", "Queries", "ReservedConnection"),
+		KillReasons:      exporter.NewCountersWithSingleLabel("KillReasons", "Number of queries and transactions killed by the tablet, by reason", "reason", "Transition", "QueryTimeout", "TransactionTimeout"),
 		ErrorCounters: exporter.NewCountersWithSingleLabel(
 			"Errors",
 			"Critical errors",
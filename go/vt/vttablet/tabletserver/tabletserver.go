@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"os/signal"
@@ -53,6 +54,8 @@ import (
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vttablet/queryservice"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/fairshare"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/interceptor"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/messager"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/repltracker"
@@ -63,6 +66,7 @@ import (
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/txserializer"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/txthrottler"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/vstreamer"
+	"vitess.io/vitess/go/yaml2"
 )
 
 // logPoolFull is for throttling transaction / query pool full messages in the log.
@@ -89,6 +93,7 @@ type TabletServer struct {
 	config                 *tabletenv.TabletConfig
 	stats                  *tabletenv.Stats
 	QueryTimeout           sync2.AtomicDuration
+	queryTimeoutOverrides  map[topodatapb.TabletType]time.Duration
 	TerseErrors            bool
 	enableHotRowProtection bool
 	topoServer             *topo.Server
@@ -106,6 +111,19 @@ type TabletServer struct {
 	hs           *healthStreamer
 	lagThrottler *throttle.Throttler
 
+	// fairShare divides the request concurrency across callers.
+	fairShare *fairshare.Limiter
+
+	// interceptors are called for every request admitted by
+	// execRequest. See RegisterInterceptor.
+	interceptors *interceptor.Chain
+
+	// live holds the config fields that can be reloaded at runtime.
+	// It's shared by the subcomponents that read them. configFile is
+	// the file re-read by /debug/config/reload_file, if set.
+	live       *liveConfig
+	configFile string
+
 	// sm manages state transitions.
 	sm *stateManager
 
@@ -139,6 +157,7 @@ func NewTabletServer(name string, config *tabletenv.TabletConfig, topoServer *to
 		stats:                  tabletenv.NewStats(exporter),
 		config:                 config,
 		QueryTimeout:           sync2.NewAtomicDuration(config.Oltp.QueryTimeoutSeconds.Get()),
+		queryTimeoutOverrides:  config.Oltp.QueryTimeoutOverrides(),
 		TerseErrors:            config.TerseErrors,
 		enableHotRowProtection: config.HotRowProtection.Mode != tabletenv.Disable,
 		topoServer:             topoServer,
@@ -147,24 +166,32 @@ func NewTabletServer(name string, config *tabletenv.TabletConfig, topoServer *to
 
 	tsOnce.Do(func() { srvTopoServer = srvtopo.NewResilientServer(topoServer, "TabletSrvTopo") })
 
+	tsv.live = newLiveConfig(config)
 	tsv.hs = newHealthStreamer(tsv, alias)
+	tsv.hs.live = tsv.live
 	tsv.se = schema.NewEngine(tsv)
 	tsv.rt = repltracker.NewReplTracker(tsv, alias)
 	tsv.vstreamer = vstreamer.NewEngine(tsv, srvTopoServer, tsv.se, alias.Cell)
 	tsv.tracker = schema.NewTracker(tsv, tsv.vstreamer, tsv.se)
 	tsv.watcher = NewBinlogWatcher(tsv, tsv.vstreamer, tsv.config)
 	tsv.qe = NewQueryEngine(tsv, tsv.se)
+	tsv.qe.live = tsv.live
+	tsv.live.tableExists = func(table string) bool {
+		return tsv.se.GetTable(sqlparser.NewTableIdent(table)) != nil
+	}
 	tsv.txThrottler = txthrottler.NewTxThrottler(tsv.config, topoServer)
 	tsv.te = NewTxEngine(tsv)
+	tsv.te.txPool.tabletType = tsv.currentTabletType
+	tsv.te.live = tsv.live
 	tsv.messager = messager.NewEngine(tsv, tsv.se, tsv.vstreamer)
-	tsv.lagThrottler = throttle.NewThrottler(tsv, topoServer,
-		func() topodatapb.TabletType {
-			if tsv.sm == nil {
-				return topodatapb.TabletType_UNKNOWN
-			}
-			return tsv.sm.Target().TabletType
-		},
-	)
+	tsv.lagThrottler = throttle.NewThrottler(tsv, topoServer, tsv.currentTabletType)
+	tsv.lagThrottler.InitAppThresholds(tsv.live.ThrottleAppThreshold)
+	tsv.vstreamer.SetThrottler(tsv.lagThrottler)
+	tsv.fairShare = fairshare.New(tsv)
+	tsv.interceptors = interceptor.NewChain(tsv)
+	if max := config.MaxConcurrentRequestsPerCaller; max > 0 {
+		tsv.interceptors.Register(interceptor.NewCallerConcurrency(max))
+	}
 
 	tsv.sm = &stateManager{
 		hs:          tsv.hs,
@@ -178,6 +205,7 @@ func NewTabletServer(name string, config *tabletenv.TabletConfig, topoServer *to
 		te:          tsv.te,
 		messager:    tsv.messager,
 		throttler:   tsv.lagThrottler,
+		live:        tsv.live,
 	}
 
 	tsv.exporter.NewGaugeFunc("TabletState", "Tablet server state", func() int64 { return int64(tsv.sm.State()) })
@@ -188,27 +216,69 @@ func NewTabletServer(name string, config *tabletenv.TabletConfig, topoServer *to
 	tsv.exporter.NewGaugesFuncWithMultiLabels("TabletServerState", "Tablet server state labeled by state name", []string{"name"}, func() map[string]int64 {
 		return map[string]int64{tsv.sm.IsServingString(): 1}
 	})
+	tsv.exporter.NewGaugesFuncWithMultiLabels("TabletServerNotConnectedState", "Reason why the tablet server is not connected to mysql", []string{"reason"}, func() map[string]int64 {
+		ncs, ok := tsv.sm.NotConnectedState()
+		if !ok {
+			return nil
+		}
+		return map[string]int64{ncs.String(): 1}
+	})
 	tsv.exporter.NewGaugeDurationFunc("QueryTimeout", "Tablet server query timeout", tsv.QueryTimeout.Get)
+	tsv.exporter.NewCounterFunc("TransactionThrottlerDryRunThrottled", "Count of transactions that the transaction throttler would have throttled in dry-run mode", func() int64 {
+		_, dryRun := tsv.txThrottler.DecisionCounts()
+		return dryRun
+	})
 
 	tsv.registerHealthzHealthHandler()
 	tsv.registerDebugHealthHandler()
+	tsv.registerHealthStreamHandler()
+	tsv.registerReplLagHistoryHandler()
+	tsv.registerProbeHandlers()
+	tsv.registerReplHealthRefreshHandler()
+	tsv.registerTopoIsolationAckHandler()
+	tsv.registerMaintenanceHandler()
+	tsv.registerPauseServingHandlers()
+	tsv.registerPromotableHandler()
+	tsv.registerConfigHandlers()
 	tsv.registerQueryzHandler()
+	tsv.registerQueryPlansHandler()
 	tsv.registerStreamQueryzHandlers()
 	tsv.registerTwopczHandler()
+	tsv.registerUnresolvedPreparesHandlers()
+	tsv.registerTransactionsHandler()
+	tsv.registerReservedConnectionsHandler()
+	tsv.registerTxThrottlerHandler()
+	tsv.registerPurgeMessagesHandler()
+	tsv.registerQuiescedTablesHandler()
+	tsv.registerHealthAnnotationsHandler()
 	tsv.registerThrottlerHandlers()
 
 	return tsv
 }
 
+// currentTabletType returns the tablet type of the state manager,
+// or UNKNOWN if it has not been created yet.
+func (tsv *TabletServer) currentTabletType() topodatapb.TabletType {
+	if tsv.sm == nil {
+		return topodatapb.TabletType_UNKNOWN
+	}
+	return tsv.sm.Target().TabletType
+}
+
 // InitDBConfig initializes the db config variables for TabletServer. You must call this function
 // to complete the creation of TabletServer.
 func (tsv *TabletServer) InitDBConfig(target querypb.Target, dbcfgs *dbconfigs.DBConfigs, mysqld mysqlctl.MysqlDaemon) error {
 	if tsv.sm.State() != StateNotConnected {
 		return vterrors.Errorf(vtrpcpb.Code_UNKNOWN, "InitDBConfig failed, current state: %s", tsv.sm.IsServingString())
 	}
-	tsv.sm.Init(tsv, target)
-	tsv.sm.target = target
+	if err := tsv.config.Verify(); err != nil {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid config: %v", err)
+	}
 	tsv.config.DB = dbcfgs
+	if err := tsv.sm.Init(tsv, target); err != nil {
+		return err
+	}
+	tsv.sm.target = target
 
 	tsv.se.InitDBConfig(tsv.config.DB.DbaWithDB())
 	tsv.rt.InitDBConfig(target, mysqld)
@@ -216,9 +286,26 @@ func (tsv *TabletServer) InitDBConfig(target querypb.Target, dbcfgs *dbconfigs.D
 	tsv.vstreamer.InitDBConfig(target.Keyspace)
 	tsv.hs.InitDBConfig(target)
 	tsv.lagThrottler.InitDBConfig(target.Keyspace, target.Shard)
+
+	if file := tsv.config.StateSnapshot.File; file != "" {
+		if _, err := os.Stat(file); err == nil {
+			if err := tsv.sm.restoreSnapshot(file); err != nil {
+				log.Warningf("Could not restore state from %s, waiting for tablet manager: %v", file, err)
+			}
+		}
+	}
 	return nil
 }
 
+// RestoredServingType returns the serving type saved by the previous
+// process in the state snapshot loaded by InitDBConfig. ok is false if
+// there's none. The caller applies it with SetServingType once it's
+// done initializing.
+func (tsv *TabletServer) RestoredServingType() (tabletType topodatapb.TabletType, terTimestamp time.Time, serving bool, reason string, ok bool) {
+	tabletType, terTimestamp, state, reason, ok := tsv.sm.RestoredServingType()
+	return tabletType, terTimestamp, state == StateServing || state == StateServingReadOnly, reason, ok
+}
+
 // Register prepares TabletServer for serving by calling
 // all the registrations functions.
 func (tsv *TabletServer) Register() {
@@ -311,12 +398,89 @@ func (tsv *TabletServer) InitACL(tableACLConfigFile string, enforceTableACLConfi
 // SetServingType changes the serving type of the tabletserver. It starts or
 // stops internal services as deemed necessary.
 // Returns true if the state of QueryService or the tablet type changed.
-func (tsv *TabletServer) SetServingType(tabletType topodatapb.TabletType, terTimestamp time.Time, serving bool, reason string) error {
+func (tsv *TabletServer) SetServingType(ctx context.Context, tabletType topodatapb.TabletType, terTimestamp time.Time, serving bool, reason string) error {
+	return tsv.sm.SetServingType(ctx, tabletType, terTimestamp, tsv.servingState(serving), reason)
+}
+
+// SetServingTypeAsync is like SetServingType, but it returns as soon as
+// the transition is queued. The returned handle resolves with its result.
+// If waitRetries is set, a failed transition only resolves once its
+// retries succeed. A transition replaced by a newer request resolves
+// with ErrTransitionSuperseded.
+func (tsv *TabletServer) SetServingTypeAsync(ctx context.Context, tabletType topodatapb.TabletType, terTimestamp time.Time, serving bool, reason string, waitRetries bool) *TransitionHandle {
+	return tsv.sm.SetServingTypeAsync(ctx, tabletType, terTimestamp, tsv.servingState(serving), reason, waitRetries)
+}
+
+// TransitionStatus returns the status of the asynchronous transition
+// identified by token. It returns false if the token is unknown, or if
+// its result was forgotten.
+func (tsv *TabletServer) TransitionStatus(token int64) (AsyncTransitionStatus, bool) {
+	return tsv.sm.TransitionStatus(token)
+}
+
+// servingState returns the state a SetServingType transitions to.
+func (tsv *TabletServer) servingState(serving bool) servingState {
+	if !serving {
+		return StateNotServing
+	}
+	// Maintenance lasts until it's explicitly ended.
+	if tsv.sm.inMaintenance() {
+		return StateServingReadOnly
+	}
+	return StateServing
+}
+
+// CanTransition checks if the query service could transition to
+// the serving type right now, without changing anything.
+func (tsv *TabletServer) CanTransition(tabletType topodatapb.TabletType, serving bool) error {
 	state := StateNotServing
 	if serving {
 		state = StateServing
 	}
-	return tsv.sm.SetServingType(tabletType, terTimestamp, state, reason)
+	return tsv.sm.CanTransition(tabletType, state)
+}
+
+// SetMaintenance switches a serving tabletserver to or from the
+// read-only maintenance state, without changing its tablet type.
+// Reads are served, but new transactions and writes are rejected.
+func (tsv *TabletServer) SetMaintenance(enable bool) error {
+	return tsv.sm.SetMaintenance(enable)
+}
+
+// ReloadConfig applies the fields of config that can be changed at
+// runtime: the replication lag thresholds, the transition and
+// shutdown grace periods, the lag throttler thresholds of the apps,
+// the max result sizes of the tables, and the transaction caps of
+// the callers. The other fields are ignored. If one of
+// the fields is invalid, none is applied. source is recorded in the
+// changelog of /debug/config. The new lag thresholds take effect at
+// the next health check.
+func (tsv *TabletServer) ReloadConfig(source string, config *tabletenv.TabletConfig) error {
+	return tsv.live.Reload(source, liveConfigValuesFrom(config))
+}
+
+// SetConfigFile sets the YAML tablet config file that
+// ReloadConfigFile re-reads. It must be called before Register.
+func (tsv *TabletServer) SetConfigFile(path string) {
+	tsv.configFile = path
+}
+
+// ReloadConfigFile re-reads the tablet config file, and applies it with
+// ReloadConfig. It's triggered by /debug/config/reload_file. A file
+// that can't be read or parsed is ignored: the current config is kept.
+func (tsv *TabletServer) ReloadConfigFile() error {
+	if tsv.configFile == "" {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "no tablet config file to reload: -tablet_config is not set")
+	}
+	data, err := ioutil.ReadFile(tsv.configFile)
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot read config file %s", tsv.configFile)
+	}
+	config := tabletenv.NewCurrentConfig()
+	if err := yaml2.Unmarshal(data, config); err != nil {
+		return vterrors.Wrapf(err, "cannot parse config file %s", tsv.configFile)
+	}
+	return tsv.ReloadConfig(tsv.configFile, config)
 }
 
 // StartService is a convenience function for InitDBConfig->SetServingType
@@ -326,7 +490,7 @@ func (tsv *TabletServer) StartService(target querypb.Target, dbcfgs *dbconfigs.D
 		return err
 	}
 	// StartService is only used for testing. So, we cheat by aggressively setting replication to healthy.
-	return tsv.sm.SetServingType(target.TabletType, time.Time{}, StateServing, "")
+	return tsv.sm.SetServingType(context.Background(), target.TabletType, time.Time{}, StateServing, "")
 }
 
 // StopService shuts down the tabletserver to the uninitialized state.
@@ -334,10 +498,38 @@ func (tsv *TabletServer) StartService(target querypb.Target, dbcfgs *dbconfigs.D
 // services to shut down. Then it shuts down the rest. This function
 // should be called before process termination, or if MySQL is unreachable.
 // Under normal circumstances, SetServingType should be called.
+// If a state snapshot file is configured, the current state is
+// saved to it before shutting down.
 func (tsv *TabletServer) StopService() {
+	if file := tsv.config.StateSnapshot.File; file != "" {
+		if err := tsv.sm.saveSnapshot(file); err != nil {
+			log.Errorf("Could not save state to %s: %v", file, err)
+		}
+	}
 	tsv.sm.StopService()
 }
 
+// StopServiceOnTerm stops the service when the process is asked to
+// terminate. If -lameduck_on_term is set, the tablet first enters
+// lameduck and broadcasts it, so that the vtgates stop sending it new
+// queries while the running ones complete. The lameduck lasts for
+// the configured duration, or until skip is closed.
+func (tsv *TabletServer) StopServiceOnTerm(skip <-chan struct{}) {
+	if lameduck := tsv.config.GracePeriods.LameduckOnTermSeconds.Get(); lameduck != 0 {
+		log.Infof("Entering lameduck for %v before stopping the query service", lameduck)
+		tsv.sm.EnterLameduck()
+		tsv.sm.Broadcast()
+		tmr := time.NewTimer(lameduck)
+		select {
+		case <-tmr.C:
+		case <-skip:
+			tmr.Stop()
+			log.Info("Lameduck ended early")
+		}
+	}
+	tsv.StopService()
+}
+
 // IsHealthy returns nil for non-serving types or if the query service is healthy (able to
 // connect to the database and serving traffic), or an error explaining
 // the unhealthiness otherwise.
@@ -359,9 +551,11 @@ func (tsv *TabletServer) IsHealthy() error {
 	}
 }
 
-// ReloadSchema reloads the schema.
-func (tsv *TabletServer) ReloadSchema(ctx context.Context) error {
-	return tsv.se.Reload(ctx)
+// ReloadSchema reloads the schema, and returns the names of the
+// tables that changed. It fails if tabletserver is not connected
+// to mysql.
+func (tsv *TabletServer) ReloadSchema(ctx context.Context) ([]string, error) {
+	return tsv.sm.ReloadSchema(ctx)
 }
 
 // ClearQueryPlanCache clears internal query plan cache
@@ -382,6 +576,13 @@ func (tsv *TabletServer) LagThrottler() *throttle.Throttler {
 	return tsv.lagThrottler
 }
 
+// CheckThrottler checks the replication lag on behalf of an app
+// running in this process, such as vreplication or online DDL. It
+// returns http.StatusOK if the app can proceed.
+func (tsv *TabletServer) CheckThrottler(ctx context.Context, appName string) *throttle.CheckResult {
+	return tsv.sm.throttler.CheckByApp(ctx, appName)
+}
+
 // SchemaEngine returns the SchemaEngine part of TabletServer.
 func (tsv *TabletServer) SchemaEngine() *schema.Engine {
 	return tsv.se
@@ -394,11 +595,18 @@ func (tsv *TabletServer) Begin(ctx context.Context, target *querypb.Target, opti
 
 func (tsv *TabletServer) begin(ctx context.Context, target *querypb.Target, preQueries []string, reservedID int64, options *querypb.ExecuteOptions) (transactionID int64, tablet *topodatapb.TabletAlias, err error) {
 	err = tsv.execRequest(
-		ctx, tsv.QueryTimeout.Get(),
+		ctx, tsv.loadQueryTimeout(),
 		"Begin", "begin", nil,
 		target, options, false, /* allowOnShutdown */
 		func(ctx context.Context, logStats *tabletenv.LogStats) error {
 			startTime := time.Now()
+			// Read-only transactions are allowed on
+			// tablets that don't accept writes.
+			if !isReadOnlyTransaction(options) {
+				if err := tsv.sm.VerifyWritable(); err != nil {
+					return err
+				}
+			}
 			if tsv.txThrottler.Throttle() {
 				return vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "Transaction throttled")
 			}
@@ -426,7 +634,7 @@ func (tsv *TabletServer) begin(ctx context.Context, target *querypb.Target, preQ
 // Commit commits the specified transaction.
 func (tsv *TabletServer) Commit(ctx context.Context, target *querypb.Target, transactionID int64) (newReservedID int64, err error) {
 	err = tsv.execRequest(
-		ctx, tsv.QueryTimeout.Get(),
+		ctx, tsv.loadQueryTimeout(),
 		"Commit", "commit", nil,
 		target, nil, true, /* allowOnShutdown */
 		func(ctx context.Context, logStats *tabletenv.LogStats) error {
@@ -457,7 +665,7 @@ func (tsv *TabletServer) Commit(ctx context.Context, target *querypb.Target, tra
 // Rollback rollsback the specified transaction.
 func (tsv *TabletServer) Rollback(ctx context.Context, target *querypb.Target, transactionID int64) (newReservedID int64, err error) {
 	err = tsv.execRequest(
-		ctx, tsv.QueryTimeout.Get(),
+		ctx, tsv.loadQueryTimeout(),
 		"Rollback", "rollback", nil,
 		target, nil, true, /* allowOnShutdown */
 		func(ctx context.Context, logStats *tabletenv.LogStats) error {
@@ -477,7 +685,7 @@ func (tsv *TabletServer) Rollback(ctx context.Context, target *querypb.Target, t
 // Prepare prepares the specified transaction.
 func (tsv *TabletServer) Prepare(ctx context.Context, target *querypb.Target, transactionID int64, dtid string) (err error) {
 	return tsv.execRequest(
-		ctx, tsv.QueryTimeout.Get(),
+		ctx, tsv.loadQueryTimeout(),
 		"Prepare", "prepare", nil,
 		target, nil, true, /* allowOnShutdown */
 		func(ctx context.Context, logStats *tabletenv.LogStats) error {
@@ -494,7 +702,7 @@ func (tsv *TabletServer) Prepare(ctx context.Context, target *querypb.Target, tr
 // CommitPrepared commits the prepared transaction.
 func (tsv *TabletServer) CommitPrepared(ctx context.Context, target *querypb.Target, dtid string) (err error) {
 	return tsv.execRequest(
-		ctx, tsv.QueryTimeout.Get(),
+		ctx, tsv.loadQueryTimeout(),
 		"CommitPrepared", "commit_prepared", nil,
 		target, nil, true, /* allowOnShutdown */
 		func(ctx context.Context, logStats *tabletenv.LogStats) error {
@@ -511,7 +719,7 @@ func (tsv *TabletServer) CommitPrepared(ctx context.Context, target *querypb.Tar
 // RollbackPrepared commits the prepared transaction.
 func (tsv *TabletServer) RollbackPrepared(ctx context.Context, target *querypb.Target, dtid string, originalID int64) (err error) {
 	return tsv.execRequest(
-		ctx, tsv.QueryTimeout.Get(),
+		ctx, tsv.loadQueryTimeout(),
 		"RollbackPrepared", "rollback_prepared", nil,
 		target, nil, true, /* allowOnShutdown */
 		func(ctx context.Context, logStats *tabletenv.LogStats) error {
@@ -528,7 +736,7 @@ func (tsv *TabletServer) RollbackPrepared(ctx context.Context, target *querypb.T
 // CreateTransaction creates the metadata for a 2PC transaction.
 func (tsv *TabletServer) CreateTransaction(ctx context.Context, target *querypb.Target, dtid string, participants []*querypb.Target) (err error) {
 	return tsv.execRequest(
-		ctx, tsv.QueryTimeout.Get(),
+		ctx, tsv.loadQueryTimeout(),
 		"CreateTransaction", "create_transaction", nil,
 		target, nil, true, /* allowOnShutdown */
 		func(ctx context.Context, logStats *tabletenv.LogStats) error {
@@ -546,7 +754,7 @@ func (tsv *TabletServer) CreateTransaction(ctx context.Context, target *querypb.
 // decision to commit the associated 2pc transaction.
 func (tsv *TabletServer) StartCommit(ctx context.Context, target *querypb.Target, transactionID int64, dtid string) (err error) {
 	return tsv.execRequest(
-		ctx, tsv.QueryTimeout.Get(),
+		ctx, tsv.loadQueryTimeout(),
 		"StartCommit", "start_commit", nil,
 		target, nil, true, /* allowOnShutdown */
 		func(ctx context.Context, logStats *tabletenv.LogStats) error {
@@ -564,7 +772,7 @@ func (tsv *TabletServer) StartCommit(ctx context.Context, target *querypb.Target
 // If a transaction id is provided, that transaction is also rolled back.
 func (tsv *TabletServer) SetRollback(ctx context.Context, target *querypb.Target, dtid string, transactionID int64) (err error) {
 	return tsv.execRequest(
-		ctx, tsv.QueryTimeout.Get(),
+		ctx, tsv.loadQueryTimeout(),
 		"SetRollback", "set_rollback", nil,
 		target, nil, true, /* allowOnShutdown */
 		func(ctx context.Context, logStats *tabletenv.LogStats) error {
@@ -582,7 +790,7 @@ func (tsv *TabletServer) SetRollback(ctx context.Context, target *querypb.Target
 // essentially resolving it.
 func (tsv *TabletServer) ConcludeTransaction(ctx context.Context, target *querypb.Target, dtid string) (err error) {
 	return tsv.execRequest(
-		ctx, tsv.QueryTimeout.Get(),
+		ctx, tsv.loadQueryTimeout(),
 		"ConcludeTransaction", "conclude_transaction", nil,
 		target, nil, true, /* allowOnShutdown */
 		func(ctx context.Context, logStats *tabletenv.LogStats) error {
@@ -596,10 +804,33 @@ func (tsv *TabletServer) ConcludeTransaction(ctx context.Context, target *queryp
 	)
 }
 
+// ResolvePrepared commits or rolls back a prepared transaction by
+// hand. action must be commit or rollback.
+func (tsv *TabletServer) ResolvePrepared(dtid, action string) error {
+	if dtid == "" {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cannot resolve a prepared transaction: the dtid must be specified")
+	}
+	var commit bool
+	switch action {
+	case "commit":
+		commit = true
+	case "rollback":
+	default:
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cannot resolve prepared transaction %s: invalid action %q, must be commit or rollback", dtid, action)
+	}
+	ctx := tabletenv.LocalContext()
+	txe := &TxExecutor{
+		ctx:      ctx,
+		logStats: tabletenv.NewLogStats(ctx, "ResolvePrepared"),
+		te:       tsv.te,
+	}
+	return txe.ResolvePrepared(dtid, commit)
+}
+
 // ReadTransaction returns the metadata for the specified dtid.
 func (tsv *TabletServer) ReadTransaction(ctx context.Context, target *querypb.Target, dtid string) (metadata *querypb.TransactionMetadata, err error) {
 	err = tsv.execRequest(
-		ctx, tsv.QueryTimeout.Get(),
+		ctx, tsv.loadQueryTimeout(),
 		"ReadTransaction", "read_transaction", nil,
 		target, nil, true, /* allowOnShutdown */
 		func(ctx context.Context, logStats *tabletenv.LogStats) error {
@@ -627,7 +858,7 @@ func (tsv *TabletServer) Execute(ctx context.Context, target *querypb.Target, sq
 
 	allowOnShutdown := transactionID != 0
 	err = tsv.execRequest(
-		ctx, tsv.QueryTimeout.Get(),
+		ctx, tsv.loadQueryTimeout(),
 		"Execute", sql, bindVariables,
 		target, options, allowOnShutdown,
 		func(ctx context.Context, logStats *tabletenv.LogStats) error {
@@ -698,7 +929,14 @@ func (tsv *TabletServer) StreamExecute(ctx context.Context, target *querypb.Targ
 				logStats:       logStats,
 				tsv:            tsv,
 			}
-			return qre.Stream(callback)
+			st := tsv.sm.StartStream()
+			defer st.Done()
+			return qre.Stream(func(qr *sqltypes.Result) error {
+				if err := st.Revalidate(); err != nil {
+					return err
+				}
+				return callback(qr)
+			})
 		},
 	)
 }
@@ -746,6 +984,14 @@ func (tsv *TabletServer) ExecuteBatch(ctx context.Context, target *querypb.Targe
 	}
 	defer tsv.sm.EndRequest()
 	defer tsv.handlePanicAndSendLogStats("batch", nil, nil)
+	if err = tsv.sm.CheckReplicationLag(options); err != nil {
+		return nil, err
+	}
+	var admitted func()
+	if ctx, admitted, err = tsv.admit(ctx, "ExecuteBatch", target); err != nil {
+		return nil, err
+	}
+	defer admitted()
 
 	if options == nil {
 		options = &querypb.ExecuteOptions{}
@@ -834,7 +1080,7 @@ func (tsv *TabletServer) beginWaitForSameRangeTransactions(ctx context.Context,
 	err := tsv.execRequest(
 		// Use (potentially longer) -queryserver-config-query-timeout and not
 		// -queryserver-config-txpool-timeout (defaults to 1s) to limit the waiting.
-		ctx, tsv.QueryTimeout.Get(),
+		ctx, tsv.loadQueryTimeout(),
 		"", "waitForSameRangeTransactions", nil,
 		target, options, false, /* allowOnShutdown */
 		func(ctx context.Context, logStats *tabletenv.LogStats) error {
@@ -938,7 +1184,7 @@ func (tsv *TabletServer) MessageAck(ctx context.Context, target *querypb.Target,
 	for _, val := range ids {
 		sids = append(sids, sqltypes.ProtoToValue(val).ToString())
 	}
-	count, err = tsv.execDML(ctx, target, func() (string, map[string]*querypb.BindVariable, error) {
+	count, err = tsv.execDML(ctx, target, "MessageAck", func() (string, map[string]*querypb.BindVariable, error) {
 		return tsv.messager.GenerateAckQuery(name, sids)
 	})
 	if err != nil {
@@ -951,7 +1197,7 @@ func (tsv *TabletServer) MessageAck(ctx context.Context, target *querypb.Target,
 // PostponeMessages postpones the list of messages for a given message table.
 // It returns the number of messages successfully postponed.
 func (tsv *TabletServer) PostponeMessages(ctx context.Context, target *querypb.Target, name string, ids []string) (count int64, err error) {
-	return tsv.execDML(ctx, target, func() (string, map[string]*querypb.BindVariable, error) {
+	return tsv.execDML(ctx, target, "PostponeMessages", func() (string, map[string]*querypb.BindVariable, error) {
 		return tsv.messager.GeneratePostponeQuery(name, ids)
 	})
 }
@@ -959,17 +1205,22 @@ func (tsv *TabletServer) PostponeMessages(ctx context.Context, target *querypb.T
 // PurgeMessages purges messages older than specified time in Unix Nanoseconds.
 // It purges at most 500 messages. It returns the number of messages successfully purged.
 func (tsv *TabletServer) PurgeMessages(ctx context.Context, target *querypb.Target, name string, timeCutoff int64) (count int64, err error) {
-	return tsv.execDML(ctx, target, func() (string, map[string]*querypb.BindVariable, error) {
+	return tsv.execDML(ctx, target, "PurgeMessages", func() (string, map[string]*querypb.BindVariable, error) {
 		return tsv.messager.GeneratePurgeQuery(name, timeCutoff)
 	})
 }
 
-func (tsv *TabletServer) execDML(ctx context.Context, target *querypb.Target, queryGenerator func() (string, map[string]*querypb.BindVariable, error)) (count int64, err error) {
+func (tsv *TabletServer) execDML(ctx context.Context, target *querypb.Target, requestName string, queryGenerator func() (string, map[string]*querypb.BindVariable, error)) (count int64, err error) {
 	if err = tsv.sm.StartRequest(ctx, target, false /* allowOnShutdown */); err != nil {
 		return 0, err
 	}
 	defer tsv.sm.EndRequest()
 	defer tsv.handlePanicAndSendLogStats("ack", nil, nil)
+	var admitted func()
+	if ctx, admitted, err = tsv.admit(ctx, requestName, target); err != nil {
+		return 0, err
+	}
+	defer admitted()
 
 	query, bv, err := queryGenerator()
 	if err != nil {
@@ -1004,7 +1255,14 @@ func (tsv *TabletServer) VStream(ctx context.Context, target *querypb.Target, st
 	if err := tsv.sm.VerifyTarget(ctx, target); err != nil {
 		return err
 	}
-	return tsv.vstreamer.Stream(ctx, startPos, tablePKs, filter, send)
+	st := tsv.sm.StartStream()
+	defer st.Done()
+	return tsv.vstreamer.Stream(ctx, startPos, tablePKs, filter, func(events []*binlogdatapb.VEvent) error {
+		if err := st.Revalidate(); err != nil {
+			return err
+		}
+		return send(events)
+	})
 }
 
 // VStreamRows streams rows from the specified starting point.
@@ -1020,7 +1278,14 @@ func (tsv *TabletServer) VStreamRows(ctx context.Context, target *querypb.Target
 		}
 		row = r.Rows[0]
 	}
-	return tsv.vstreamer.StreamRows(ctx, query, row, send)
+	st := tsv.sm.StartStream()
+	defer st.Done()
+	return tsv.vstreamer.StreamRows(ctx, query, row, func(response *binlogdatapb.VStreamRowsResponse) error {
+		if err := st.Revalidate(); err != nil {
+			return err
+		}
+		return send(response)
+	})
 }
 
 // VStreamResults streams rows from the specified starting point.
@@ -1028,7 +1293,14 @@ func (tsv *TabletServer) VStreamResults(ctx context.Context, target *querypb.Tar
 	if err := tsv.sm.VerifyTarget(ctx, target); err != nil {
 		return err
 	}
-	return tsv.vstreamer.StreamResults(ctx, query, send)
+	st := tsv.sm.StartStream()
+	defer st.Done()
+	return tsv.vstreamer.StreamResults(ctx, query, func(response *binlogdatapb.VStreamResultsResponse) error {
+		if err := st.Revalidate(); err != nil {
+			return err
+		}
+		return send(response)
+	})
 }
 
 //ReserveBeginExecute implements the QueryService interface
@@ -1038,7 +1310,7 @@ func (tsv *TabletServer) ReserveBeginExecute(ctx context.Context, target *queryp
 	var err error
 
 	err = tsv.execRequest(
-		ctx, tsv.QueryTimeout.Get(),
+		ctx, tsv.loadQueryTimeout(),
 		"ReserveBegin", "begin", bindVariables,
 		target, options, false, /* allowOnShutdown */
 		func(ctx context.Context, logStats *tabletenv.LogStats) error {
@@ -1067,7 +1339,7 @@ func (tsv *TabletServer) ReserveExecute(ctx context.Context, target *querypb.Tar
 	var err error
 
 	err = tsv.execRequest(
-		ctx, tsv.QueryTimeout.Get(),
+		ctx, tsv.loadQueryTimeout(),
 		"Reserve", "", bindVariables,
 		target, options, false, /* allowOnShutdown */
 		func(ctx context.Context, logStats *tabletenv.LogStats) error {
@@ -1096,7 +1368,7 @@ func (tsv *TabletServer) Release(ctx context.Context, target *querypb.Target, tr
 		return vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, "Connection Id and Transaction ID does not exists")
 	}
 	return tsv.execRequest(
-		ctx, tsv.QueryTimeout.Get(),
+		ctx, tsv.loadQueryTimeout(),
 		"Release", "", nil,
 		target, nil, true, /* allowOnShutdown */
 		func(ctx context.Context, logStats *tabletenv.LogStats) error {
@@ -1139,13 +1411,31 @@ func (tsv *TabletServer) execRequest(
 	logStats.OriginalSQL = sql
 	logStats.BindVariables = bindVariables
 	defer tsv.handlePanicAndSendLogStats(sql, bindVariables, logStats)
-	if err = tsv.sm.StartRequest(ctx, target, allowOnShutdown); err != nil {
+	err = tsv.sm.StartRequest(ctx, target, allowOnShutdown)
+	if err == nil {
+		if err = tsv.sm.CheckReplicationLag(options); err != nil {
+			tsv.sm.EndRequest()
+		}
+	}
+	var admitted func()
+	if err == nil {
+		ctx, admitted, err = tsv.admit(ctx, requestName, target)
+		if err != nil {
+			tsv.sm.EndRequest()
+		}
+	}
+	// StartTime was read just before admission, which keeps the
+	// measurement down to a single time read.
+	logStats.AdmissionTime = time.Since(logStats.StartTime)
+	tsv.stats.AdmissionTimings.Add(requestName, logStats.AdmissionTime)
+	if err != nil {
 		return err
 	}
 
 	ctx, cancel := withTimeout(ctx, timeout, options)
 	defer func() {
 		cancel()
+		admitted()
 		tsv.sm.EndRequest()
 	}()
 
@@ -1156,6 +1446,57 @@ func (tsv *TabletServer) execRequest(
 	return nil
 }
 
+// admittedKey marks the context of a request that went through admit.
+// The requests it makes itself, like the Executes of ExecuteBatch, are
+// not admitted again: they would count twice against their caller.
+type admittedKey struct{}
+
+// admit calls the interceptors, then the fair-share limiter, for a
+// request that StartRequest let through. It returns the context of the
+// request, marked as admitted, and the func to call once it's done.
+// It's a no-op for the requests made by an admitted request.
+func (tsv *TabletServer) admit(ctx context.Context, requestName string, target *querypb.Target) (context.Context, func(), error) {
+	if ctx.Value(admittedKey{}) != nil {
+		return ctx, func() {}, nil
+	}
+	interceptorsDone, err := tsv.intercept(ctx, requestName, target)
+	if err != nil {
+		return ctx, nil, err
+	}
+	fairShareDone, err := tsv.fairShare.Admit(ctx, callerid.EffectiveCallerIDFromContext(ctx))
+	if err != nil {
+		interceptorsDone()
+		return ctx, nil, err
+	}
+	return context.WithValue(ctx, admittedKey{}, true), func() {
+		fairShareDone()
+		interceptorsDone()
+	}, nil
+}
+
+// RegisterInterceptor adds an interceptor that's called for every
+// request the tablet admits, after the previously registered ones.
+// See the interceptor package.
+func (tsv *TabletServer) RegisterInterceptor(i interceptor.Interceptor) {
+	tsv.interceptors.Register(i)
+}
+
+// intercept calls the registered interceptors for the request. The
+// state of the tablet is read before they're called, so that no lock
+// is held while they run.
+func (tsv *TabletServer) intercept(ctx context.Context, requestName string, target *querypb.Target) (interceptor.DoneFunc, error) {
+	if tsv.interceptors.Len() == 0 {
+		return func() {}, nil
+	}
+	return tsv.interceptors.Intercept(ctx, &interceptor.Request{
+		Name:              requestName,
+		Target:            target,
+		ImmediateCallerID: callerid.ImmediateCallerIDFromContext(ctx),
+		EffectiveCallerID: callerid.EffectiveCallerIDFromContext(ctx),
+		State:             tsv.sm.interceptorState(),
+	})
+}
+
 func (tsv *TabletServer) handlePanicAndSendLogStats(
 	sql string,
 	bindVariables map[string]*querypb.BindVariable,
@@ -1372,6 +1713,42 @@ func (tsv *TabletServer) BroadcastHealth() {
 	tsv.sm.Broadcast()
 }
 
+// RefreshReplHealth refreshes the replication health right away
+// and broadcasts the new state if the serving status changed.
+func (tsv *TabletServer) RefreshReplHealth() error {
+	return tsv.sm.RefreshReplHealth()
+}
+
+// SetTopoLastSeenHealthy records the last time the topo was reachable.
+func (tsv *TabletServer) SetTopoLastSeenHealthy(lastSeen time.Time) {
+	tsv.sm.SetTopoLastSeenHealthy(lastSeen)
+}
+
+// TopoIsolationTimeout returns how long a master can go without
+// reaching the topo before it stops serving. It's 0 if disabled.
+func (tsv *TabletServer) TopoIsolationTimeout() time.Duration {
+	return tsv.config.Healthcheck.TopoIsolationTimeoutSeconds.Get()
+}
+
+// AcknowledgeTopoIsolation resumes serving after a master stopped
+// serving because it could not reach the topo.
+func (tsv *TabletServer) AcknowledgeTopoIsolation() error {
+	return tsv.sm.AcknowledgeTopoIsolation()
+}
+
+// PauseServing makes tabletserver stop serving without changing its
+// type, until ResumeServing is called. The requests to serve that come
+// in the meantime are deferred until then.
+func (tsv *TabletServer) PauseServing(operator string) error {
+	return tsv.sm.PauseServing(operator)
+}
+
+// ResumeServing ends the pause started by PauseServing, if mysql
+// and the replication are healthy.
+func (tsv *TabletServer) ResumeServing(operator string) error {
+	return tsv.sm.ResumeServing(operator)
+}
+
 // EnterLameduck causes tabletserver to enter the lameduck state. This
 // state causes health checks to fail, but the behavior of tabletserver
 // otherwise remains the same. Any subsequent calls to SetServingType will
@@ -1380,6 +1757,13 @@ func (tsv *TabletServer) EnterLameduck() {
 	tsv.sm.EnterLameduck()
 }
 
+// DrainStreams asks the running streaming queries and vstreams to end
+// at their next chunk boundary with a retriable error. It returns
+// the number of streams that were signaled.
+func (tsv *TabletServer) DrainStreams() int {
+	return tsv.sm.DrainStreams()
+}
+
 // ExitLameduck causes the tabletserver to exit the lameduck mode.
 func (tsv *TabletServer) ExitLameduck() {
 	tsv.sm.ExitLameduck()
@@ -1448,12 +1832,184 @@ func (tsv *TabletServer) registerDebugHealthHandler() {
 	})
 }
 
+// registerProbeHandlers registers handlers meant for kubernetes probes.
+// /readyz fails if the tablet should not receive traffic, and /livez
+// fails if a state transition appears to be wedged.
+func (tsv *TabletServer) registerProbeHandlers() {
+	tsv.exporter.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		writeProbeResponse(w, r, tsv.sm.Readiness)
+	})
+	tsv.exporter.HandleFunc("/livez", func(w http.ResponseWriter, r *http.Request) {
+		writeProbeResponse(w, r, tsv.sm.Liveness)
+	})
+}
+
+func writeProbeResponse(w http.ResponseWriter, r *http.Request, probe func() (probeState, bool)) {
+	if err := acl.CheckAccessHTTP(r, acl.MONITORING); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+	ps, ok := probe()
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(ps)
+}
+
+// registerReplHealthRefreshHandler registers an admin action that
+// refreshes the replication health without waiting for the next
+// health check tick.
+func (tsv *TabletServer) registerReplHealthRefreshHandler() {
+	tsv.exporter.HandleFunc("/debug/health/refresh", func(w http.ResponseWriter, r *http.Request) {
+		adminActionHandler(w, r, tsv.RefreshReplHealth)
+	})
+}
+
+// registerTopoIsolationAckHandler registers an admin action that
+// lets a master isolated from the topo resume serving.
+func (tsv *TabletServer) registerTopoIsolationAckHandler() {
+	tsv.exporter.HandleFunc("/debug/topo_isolation/ack", func(w http.ResponseWriter, r *http.Request) {
+		adminActionHandler(w, r, tsv.AcknowledgeTopoIsolation)
+	})
+}
+
+// registerMaintenanceHandler registers an admin action that starts
+// the read-only maintenance state, or ends it if enable is false.
+func (tsv *TabletServer) registerMaintenanceHandler() {
+	tsv.exporter.HandleFunc("/debug/maintenance", func(w http.ResponseWriter, r *http.Request) {
+		adminActionHandler(w, r, func() error {
+			return tsv.SetMaintenance(r.FormValue("enable") != "false")
+		})
+	})
+}
+
+// registerPauseServingHandlers registers the admin actions that pause
+// and resume serving. The operator parameter names who's acting.
+func (tsv *TabletServer) registerPauseServingHandlers() {
+	tsv.exporter.HandleFunc("/debug/serving/pause", func(w http.ResponseWriter, r *http.Request) {
+		adminActionHandler(w, r, func() error {
+			return tsv.PauseServing(r.FormValue("operator"))
+		})
+	})
+	tsv.exporter.HandleFunc("/debug/serving/resume", func(w http.ResponseWriter, r *http.Request) {
+		adminActionHandler(w, r, func() error {
+			return tsv.ResumeServing(r.FormValue("operator"))
+		})
+	})
+}
+
+// registerPromotableHandler registers a handler for failover tools
+// that returns the last promotion verdict. It fails with 503 if the
+// tablet is not a viable reparent target.
+func (tsv *TabletServer) registerPromotableHandler() {
+	tsv.exporter.HandleFunc("/debug/promotable", func(w http.ResponseWriter, r *http.Request) {
+		if err := acl.CheckAccessHTTP(r, acl.MONITORING); err != nil {
+			acl.SendError(w, err)
+			return
+		}
+		pv := tsv.sm.PromotionVerdict()
+		if pv == nil {
+			pv = &promotionVerdict{Blockers: []string{"no promotion check ran yet: it's enabled by -enable_promotion_check"}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if !pv.Promotable {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(pv)
+	})
+}
+
+// registerConfigHandlers registers /debug/config, which shows the
+// config fields that can be reloaded at runtime with the changelog of
+// their reloads, /debug/config/effective, which shows the whole config
+// the tablet server was initialized with, /debug/config/reload,
+// which changes the former, and /debug/config/reload_file, which
+// re-reads them from the tablet config file. /debug/config/reload
+// takes the flag names as parameters, with durations as values.
+func (tsv *TabletServer) registerConfigHandlers() {
+	tsv.exporter.HandleFunc("/debug/config", func(w http.ResponseWriter, r *http.Request) {
+		if err := acl.CheckAccessHTTP(r, acl.DEBUGGING); err != nil {
+			acl.SendError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			Values  liveConfigValues `json:"values"`
+			Reloads []*configReload  `json:"reloads"`
+		}{
+			Values:  tsv.live.Values(),
+			Reloads: tsv.live.Reloads(),
+		})
+	})
+	tsv.exporter.HandleFunc("/debug/config/effective", func(w http.ResponseWriter, r *http.Request) {
+		if err := acl.CheckAccessHTTP(r, acl.DEBUGGING); err != nil {
+			acl.SendError(w, err)
+			return
+		}
+		snapshot := tsv.sm.ConfigSnapshot()
+		if snapshot == nil {
+			http.Error(w, "the tablet server is not initialized", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&effectiveConfig{
+			configSnapshot: *snapshot,
+			Settings:       tsv.sm.settings(),
+			Live:           tsv.live.Values(),
+			Reloads:        tsv.live.Reloads(),
+		})
+	})
+	tsv.exporter.HandleFunc("/debug/config/reload", func(w http.ResponseWriter, r *http.Request) {
+		adminActionHandler(w, r, func() error {
+			if err := r.ParseForm(); err != nil {
+				return err
+			}
+			values, err := tsv.live.Values().update(r.Form)
+			if err != nil {
+				return err
+			}
+			return tsv.live.Reload("/debug/config/reload", values)
+		})
+	})
+	tsv.exporter.HandleFunc("/debug/config/reload_file", func(w http.ResponseWriter, r *http.Request) {
+		adminActionHandler(w, r, tsv.ReloadConfigFile)
+	})
+}
+
+// adminActionHandler runs action for a POST request of an admin.
+// The other methods are rejected: a crawler or a browser prefetching
+// a link must not change the state of the tablet.
+func adminActionHandler(w http.ResponseWriter, r *http.Request, action func() error) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, fmt.Sprintf("method %s not allowed: use POST", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	if err := acl.CheckAccessHTTP(r, acl.ADMIN); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	if err := action(); err != nil {
+		http.Error(w, fmt.Sprintf("not ok: %v", err), http.StatusServiceUnavailable)
+		return
+	}
+	w.Write(okMessage)
+}
+
 func (tsv *TabletServer) registerQueryzHandler() {
 	tsv.exporter.HandleFunc("/queryz", func(w http.ResponseWriter, r *http.Request) {
 		queryzHandler(tsv.qe, w, r)
 	})
 }
 
+func (tsv *TabletServer) registerQueryPlansHandler() {
+	tsv.exporter.HandleFunc("/debug/query_plans.json", func(w http.ResponseWriter, r *http.Request) {
+		queryPlansHandler(tsv.qe, tsv.sm, w, r)
+	})
+}
+
 func (tsv *TabletServer) registerStreamQueryzHandlers() {
 	tsv.exporter.HandleFunc("/streamqueryz", func(w http.ResponseWriter, r *http.Request) {
 		streamQueryzHandler(tsv.qe.streamQList, w, r)
@@ -1475,6 +2031,91 @@ func (tsv *TabletServer) registerTwopczHandler() {
 	})
 }
 
+// registerUnresolvedPreparesHandlers registers /debug/twopc/unresolved,
+// which lists the transactions of the redo log as JSON, oldest first,
+// and /debug/twopc/resolve, which commits or rolls back one of them by
+// hand. The latter takes the dtid, and commit or rollback as action.
+func (tsv *TabletServer) registerUnresolvedPreparesHandlers() {
+	tsv.exporter.HandleFunc("/debug/twopc/unresolved", func(w http.ResponseWriter, r *http.Request) {
+		if err := acl.CheckAccessHTTP(r, acl.DEBUGGING); err != nil {
+			acl.SendError(w, err)
+			return
+		}
+		list, err := tsv.te.ListUnresolvedPrepares(tabletenv.LocalContext())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+	})
+	tsv.exporter.HandleFunc("/debug/twopc/resolve", func(w http.ResponseWriter, r *http.Request) {
+		adminActionHandler(w, r, func() error {
+			return tsv.ResolvePrepared(r.FormValue("dtid"), r.FormValue("action"))
+		})
+	})
+}
+
+// registerTransactionsHandler registers a handler that lists
+// the open transactions as JSON, oldest first.
+func (tsv *TabletServer) registerTransactionsHandler() {
+	tsv.exporter.HandleFunc("/debug/transactions", func(w http.ResponseWriter, r *http.Request) {
+		transactionsHandler(w, r, tsv.te.OpenTransactions)
+	})
+}
+
+// registerReservedConnectionsHandler registers a handler that lists
+// the reserved connections as JSON, oldest first.
+func (tsv *TabletServer) registerReservedConnectionsHandler() {
+	tsv.exporter.HandleFunc("/debug/reserved_connections", func(w http.ResponseWriter, r *http.Request) {
+		if err := acl.CheckAccessHTTP(r, acl.DEBUGGING); err != nil {
+			acl.SendError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(tsv.te.ReservedConnections())
+	})
+}
+
+func transactionsHandler(w http.ResponseWriter, r *http.Request, list func() []OpenTransaction) {
+	if err := acl.CheckAccessHTTP(r, acl.DEBUGGING); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list())
+}
+
+// registerTxThrottlerHandler registers a handler that shows the recent
+// decisions of the transaction throttler as JSON, most recent first.
+// In dry-run mode, they're the transactions that would have been
+// throttled, which helps tune the thresholds before enforcing them.
+func (tsv *TabletServer) registerTxThrottlerHandler() {
+	tsv.exporter.HandleFunc("/debug/tx_throttler", func(w http.ResponseWriter, r *http.Request) {
+		txThrottlerHandler(w, r, tsv.txThrottler)
+	})
+}
+
+func txThrottlerHandler(w http.ResponseWriter, r *http.Request, t *txthrottler.TxThrottler) {
+	if err := acl.CheckAccessHTTP(r, acl.DEBUGGING); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+	throttled, dryRun := t.DecisionCounts()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		DryRun          bool
+		Throttled       int64
+		DryRunThrottled int64
+		Decisions       []txthrottler.ThrottleDecision
+	}{
+		DryRun:          t.DryRun(),
+		Throttled:       throttled,
+		DryRunThrottled: dryRun,
+		Decisions:       t.RecentDecisions(),
+	})
+}
+
 // registerThrottlerCheckHandler registers a throttler "check" request
 func (tsv *TabletServer) registerThrottlerCheckHandler() {
 	tsv.exporter.HandleFunc("/throttler/check", func(w http.ResponseWriter, r *http.Request) {
@@ -1626,7 +2267,7 @@ func (tsv *TabletServer) SetPassthroughDMLs(val bool) {
 // SetConsolidatorMode sets the consolidator mode.
 // This function should only be used for testing.
 func (tsv *TabletServer) SetConsolidatorMode(mode string) {
-	tsv.qe.consolidatorMode = mode
+	tsv.qe.SetConsolidatorMode(mode)
 }
 
 // queryAsString returns a readable version of query+bind variables.
@@ -17,11 +17,14 @@ limitations under the License.
 package tabletserver
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"reflect"
 	"strings"
@@ -48,6 +51,7 @@ import (
 	"vitess.io/vitess/go/vt/tableacl/simpleacl"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/interceptor"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -62,7 +66,7 @@ func TestBeginOnReplica(t *testing.T) {
 
 	db.AddQueryPattern(".*", &sqltypes.Result{})
 	target := querypb.Target{TabletType: topodatapb.TabletType_REPLICA}
-	err := tsv.SetServingType(topodatapb.TabletType_REPLICA, time.Time{}, true, "")
+	err := tsv.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, time.Time{}, true, "")
 	require.NoError(t, err)
 
 	options := querypb.ExecuteOptions{
@@ -103,7 +107,7 @@ func TestTabletServerMasterToReplica(t *testing.T) {
 	require.NoError(t, err)
 	ch := make(chan bool)
 	go func() {
-		tsv.SetServingType(topodatapb.TabletType_REPLICA, time.Time{}, true, "")
+		tsv.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, time.Time{}, true, "")
 		ch <- true
 	}()
 
@@ -126,13 +130,13 @@ func TestTabletServerRedoLogIsKeptBetweenRestarts(t *testing.T) {
 	_, tsv, db := newTestTxExecutor(t)
 	defer tsv.StopService()
 	defer db.Close()
-	tsv.SetServingType(topodatapb.TabletType_REPLICA, time.Time{}, true, "")
+	tsv.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, time.Time{}, true, "")
 
 	turnOnTxEngine := func() {
-		tsv.SetServingType(topodatapb.TabletType_MASTER, time.Time{}, true, "")
+		tsv.SetServingType(context.Background(), topodatapb.TabletType_MASTER, time.Time{}, true, "")
 	}
 	turnOffTxEngine := func() {
-		tsv.SetServingType(topodatapb.TabletType_REPLICA, time.Time{}, true, "")
+		tsv.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, time.Time{}, true, "")
 	}
 
 	tpc := tsv.te.twoPC
@@ -672,6 +676,40 @@ func TestTabletServerStreamExecuteComments(t *testing.T) {
 		t.Fatal("stats are empty")
 	}
 }
+func TestTabletServerAdmissionTime(t *testing.T) {
+	db, tsv := setupTabletServerTest(t)
+	defer tsv.StopService()
+	defer db.Close()
+
+	executeSQL := "select * from test_table limit 1000"
+	db.AddQuery(executeSQL, &sqltypes.Result{})
+	target := querypb.Target{TabletType: topodatapb.TabletType_MASTER}
+	admissions := tsv.stats.AdmissionTimings.Counts()["TabletServerTest.Execute"]
+
+	ch := tabletenv.StatsLogger.Subscribe("test stats logging")
+	defer tabletenv.StatsLogger.Unsubscribe(ch)
+
+	// Delay admission by holding the state manager lock.
+	delay := 50 * time.Millisecond
+	tsv.sm.mu.Lock()
+	go func() {
+		time.Sleep(delay)
+		tsv.sm.mu.Unlock()
+	}()
+	_, err := tsv.Execute(ctx, &target, executeSQL, nil, 0, 0, nil)
+	require.NoError(t, err)
+
+	select {
+	case out := <-ch:
+		stats := out.(*tabletenv.LogStats)
+		assert.GreaterOrEqual(t, int64(stats.AdmissionTime), int64(delay))
+		assert.LessOrEqual(t, int64(stats.AdmissionTime), int64(stats.TotalTime()))
+	default:
+		t.Fatal("stats are empty")
+	}
+	assert.Equal(t, admissions+1, tsv.stats.AdmissionTimings.Counts()["TabletServerTest.Execute"])
+}
+
 func TestTabletServerExecuteBatch(t *testing.T) {
 	db, tsv := setupTabletServerTest(t)
 	defer tsv.StopService()
@@ -2154,6 +2192,282 @@ func TestReserveStats(t *testing.T) {
 	assert.NotEmpty(t, tsv.te.txPool.env.Stats().UserReservedTimesNs.Counts()["test"])
 }
 
+func TestProbeHandlers(t *testing.T) {
+	db, tsv := setupTabletServerTest(t)
+	defer tsv.StopService()
+	defer db.Close()
+
+	probe := func(f func() (probeState, bool)) (int, probeState) {
+		t.Helper()
+		request, _ := http.NewRequest("GET", "/readyz", nil)
+		response := httptest.NewRecorder()
+		writeProbeResponse(response, request, f)
+		var ps probeState
+		err := json.Unmarshal(response.Body.Bytes(), &ps)
+		require.NoError(t, err)
+		return response.Code, ps
+	}
+
+	code, ps := probe(tsv.sm.Readiness)
+	assert.Equal(t, http.StatusOK, code)
+	want := probeState{
+		State:      "Serving",
+		WantState:  "Serving",
+		TabletType: "MASTER",
+	}
+	assert.Equal(t, want, ps)
+
+	tsv.EnterLameduck()
+	code, ps = probe(tsv.sm.Readiness)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "lameduck", ps.Reason)
+
+	// Liveness is unaffected by lameduck.
+	code, _ = probe(tsv.sm.Liveness)
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestAdminActionHandler(t *testing.T) {
+	called := 0
+	refresh := func(method string, err error) *httptest.ResponseRecorder {
+		request, _ := http.NewRequest(method, "/debug/health/refresh", nil)
+		response := httptest.NewRecorder()
+		adminActionHandler(response, request, func() error {
+			called++
+			return err
+		})
+		return response
+	}
+
+	response := refresh("POST", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "ok\n", response.Body.String())
+
+	response = refresh("POST", errors.New("transition in progress"))
+	assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+	assert.Equal(t, "not ok: transition in progress\n", response.Body.String())
+	assert.Equal(t, 2, called)
+
+	// The action only runs on POST.
+	for _, method := range []string{"GET", "HEAD", "PUT"} {
+		response = refresh(method, nil)
+		assert.Equal(t, http.StatusMethodNotAllowed, response.Code, method)
+		assert.Equal(t, "POST", response.Header().Get("Allow"), method)
+	}
+	assert.Equal(t, 2, called)
+}
+
+func TestTransactionsHandler(t *testing.T) {
+	db, tsv := setupTabletServerTest(t)
+	defer tsv.StopService()
+	defer db.Close()
+
+	db.AddQuery("update test_table set name = 2 where pk = 1 limit 10001", &sqltypes.Result{})
+	target := querypb.Target{TabletType: topodatapb.TabletType_MASTER}
+	txid, _, err := tsv.Begin(ctx, &target, nil)
+	require.NoError(t, err)
+	defer tsv.Rollback(ctx, &target, txid)
+	_, err = tsv.Execute(ctx, &target, "update test_table set name = 2 where pk = 1", nil, txid, 0, nil)
+	require.NoError(t, err)
+
+	request, _ := http.NewRequest("GET", "/debug/transactions", nil)
+	response := httptest.NewRecorder()
+	transactionsHandler(response, request, tsv.te.OpenTransactions)
+	assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+
+	var txs []OpenTransaction
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &txs))
+	require.Len(t, txs, 1)
+	assert.Equal(t, txid, txs[0].TransactionID)
+	assert.Equal(t, "MASTER", txs[0].TabletType)
+	assert.Contains(t, txs[0].LastQuery, "update test_table")
+}
+
+func TestReservedConnectionsHandler(t *testing.T) {
+	db, tsv := setupTabletServerTest(t)
+	defer tsv.StopService()
+	defer db.Close()
+
+	rid, err := tsv.te.Reserve(ctx, &querypb.ExecuteOptions{}, 0, nil)
+	require.NoError(t, err)
+	defer tsv.te.Release(rid)
+
+	request := httptest.NewRequest("GET", tsv.exporter.URLPrefix()+"/debug/reserved_connections", nil)
+	response := httptest.NewRecorder()
+	http.DefaultServeMux.ServeHTTP(response, request)
+	assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+
+	var reserved []ReservedConnection
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &reserved))
+	require.Len(t, reserved, 1)
+	assert.Equal(t, rid, reserved[0].ConnID)
+	assert.False(t, reserved[0].InTransaction)
+}
+
+func TestTxThrottlerHandler(t *testing.T) {
+	_, tsv := setupTabletServerTest(t)
+	defer tsv.StopService()
+
+	request, _ := http.NewRequest("GET", "/debug/tx_throttler", nil)
+	response := httptest.NewRecorder()
+	txThrottlerHandler(response, request, tsv.txThrottler)
+	assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+	assert.Equal(t, `{"DryRun":false,"Throttled":0,"DryRunThrottled":0,"Decisions":[]}`+"\n", response.Body.String())
+}
+
+func TestTabletServerFairShare(t *testing.T) {
+	config := tabletenv.NewDefaultConfig()
+	config.FairShare.Enable = true
+	config.FairShare.Concurrency = 2
+	config.FairShare.MaxShare = 0.5
+	db, tsv := setupTabletServerTestCustom(t, config)
+	defer tsv.StopService()
+	defer db.Close()
+
+	// Fill up the capacity on behalf of the noisy caller.
+	noisyCtx := callerid.NewContext(ctx, callerid.NewEffectiveCallerID("noisy", "", ""), nil)
+	for i := 0; i < 2; i++ {
+		done, err := tsv.fairShare.Admit(noisyCtx, callerid.EffectiveCallerIDFromContext(noisyCtx))
+		require.NoError(t, err)
+		defer done()
+	}
+
+	target := querypb.Target{TabletType: topodatapb.TabletType_MASTER}
+	_, err := tsv.Execute(noisyCtx, &target, "select * from test_table limit 1000", nil, 0, 0, nil)
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	_, err = tsv.ExecuteBatch(noisyCtx, &target, []*querypb.BoundQuery{{Sql: "select * from test_table limit 1000"}}, false, 0, nil)
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	_, err = tsv.PurgeMessages(noisyCtx, &target, "msg", 0)
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+
+	// The quiet caller waits for a slot until its deadline.
+	quietCtx, cancel := context.WithTimeout(callerid.NewContext(ctx, callerid.NewEffectiveCallerID("quiet", "", ""), nil), 10*time.Millisecond)
+	defer cancel()
+	_, err = tsv.Execute(quietCtx, &target, "select * from test_table limit 1000", nil, 0, 0, nil)
+	assert.Equal(t, vtrpcpb.Code_DEADLINE_EXCEEDED, vterrors.Code(err))
+}
+
+// recordingInterceptor records the requests it intercepts, and
+// vetoes them if err is set.
+type recordingInterceptor struct {
+	mu       sync.Mutex
+	requests []*interceptor.Request
+	done     int
+	err      error
+}
+
+func (ri *recordingInterceptor) Name() string {
+	return "recording"
+}
+
+func (ri *recordingInterceptor) Intercept(ctx context.Context, req *interceptor.Request) (interceptor.DoneFunc, error) {
+	ri.mu.Lock()
+	defer ri.mu.Unlock()
+	ri.requests = append(ri.requests, req)
+	if ri.err != nil {
+		return nil, ri.err
+	}
+	return func() {
+		ri.mu.Lock()
+		defer ri.mu.Unlock()
+		ri.done++
+	}, nil
+}
+
+func TestTabletServerInterceptors(t *testing.T) {
+	config := tabletenv.NewDefaultConfig()
+	config.MaxConcurrentRequestsPerCaller = 1
+	db, tsv := setupTabletServerTestCustom(t, config)
+	defer tsv.StopService()
+	defer db.Close()
+	db.AddQuery("select * from test_table limit 1000", &sqltypes.Result{})
+	ri := &recordingInterceptor{}
+	tsv.RegisterInterceptor(ri)
+
+	callerCtx := callerid.NewContext(ctx, callerid.NewEffectiveCallerID("app", "", ""), callerid.NewImmediateCallerID("user"))
+	target := querypb.Target{TabletType: topodatapb.TabletType_MASTER}
+	_, err := tsv.Execute(callerCtx, &target, "select * from test_table limit 1000", nil, 0, 0, nil)
+	require.NoError(t, err)
+	require.Len(t, ri.requests, 1)
+	req := ri.requests[0]
+	assert.Equal(t, "Execute", req.Name)
+	assert.Equal(t, &target, req.Target)
+	assert.Equal(t, "app", req.EffectiveCallerID.Principal)
+	assert.Equal(t, "user", req.ImmediateCallerID.Username)
+	assert.Equal(t, interceptor.State{TabletType: topodatapb.TabletType_MASTER, Serving: true}, req.State)
+	assert.Equal(t, 1, ri.done)
+
+	// A batch is intercepted once: its queries are not intercepted
+	// again, so it runs within the cap.
+	queries := []*querypb.BoundQuery{{Sql: "select * from test_table limit 1000"}, {Sql: "select * from test_table limit 1000"}}
+	_, err = tsv.ExecuteBatch(callerCtx, &target, queries, true, 0, nil)
+	require.NoError(t, err)
+	require.Len(t, ri.requests, 2)
+	assert.Equal(t, "ExecuteBatch", ri.requests[1].Name)
+	assert.Equal(t, 2, ri.done)
+	_, err = tsv.PurgeMessages(callerCtx, &target, "msg", 0)
+	require.Error(t, err)
+	require.Len(t, ri.requests, 3)
+	assert.Equal(t, "PurgeMessages", ri.requests[2].Name)
+	assert.Equal(t, 3, ri.done)
+
+	// The built-in cap rejects the requests of a caller at its cap,
+	// before the recording interceptor sees them.
+	done, err := tsv.intercept(callerCtx, "Execute", &target)
+	require.NoError(t, err)
+	_, err = tsv.Execute(callerCtx, &target, "select * from test_table limit 1000", nil, 0, 0, nil)
+	assert.EqualError(t, err, "caller app is at its cap of 1 concurrent requests")
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	done()
+	assert.Equal(t, 4, ri.done)
+
+	// A veto is returned to the client.
+	ri.err = vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "over quota")
+	_, err = tsv.Execute(callerCtx, &target, "select * from test_table limit 1000", nil, 0, 0, nil)
+	assert.EqualError(t, err, "over quota")
+}
+
+func TestTabletServerStopServiceOnTerm(t *testing.T) {
+	config := tabletenv.NewDefaultConfig()
+	config.GracePeriods.LameduckOnTermSeconds = 60
+	db, tsv := setupTabletServerTestCustom(t, config)
+	defer db.Close()
+
+	skip := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		tsv.StopServiceOnTerm(skip)
+	}()
+
+	// The tablet reports itself as not serving, but still
+	// serves until the lameduck ends.
+	assert.Eventually(t, func() bool {
+		tsv.hs.mu.Lock()
+		defer tsv.hs.mu.Unlock()
+		return !tsv.hs.state.Serving
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.True(t, tsv.sm.Status().Lameduck)
+	assert.Equal(t, StateServing, tsv.sm.State())
+	select {
+	case <-stopped:
+		t.Fatal("the service stopped before the end of the lameduck")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(skip)
+	<-stopped
+	assert.Equal(t, StateNotConnected, tsv.sm.State())
+	assert.False(t, tsv.sm.Status().Lameduck)
+
+	// Without lameduck, the service is stopped right away.
+	config = tabletenv.NewDefaultConfig()
+	db, tsv = setupTabletServerTestCustom(t, config)
+	defer db.Close()
+	tsv.StopServiceOnTerm(nil)
+	assert.Equal(t, StateNotConnected, tsv.sm.State())
+}
+
 func setupTabletServerTest(t *testing.T) (*fakesqldb.DB, *TabletServer) {
 	config := tabletenv.NewDefaultConfig()
 	return setupTabletServerTestCustom(t, config)
@@ -2194,6 +2508,9 @@ func getSupportedQueries() map[string]*sqltypes.Result {
 		"update test_table set name_string = 'tx3' where pk = 2 and name = 1 limit 10001": {
 			RowsAffected: 1,
 		},
+		// queries for the mysql write check
+		sqlCreateWriteProbeTable: {},
+		sqlWriteProbe:            {RowsAffected: 1},
 		// queries for twopc
 		fmt.Sprintf(sqlCreateSidecarDB, "_vt"):          {},
 		fmt.Sprintf(sqlDropLegacy1, "_vt"):              {},
@@ -2221,6 +2538,14 @@ func getSupportedQueries() map[string]*sqltypes.Result {
 				{sqltypes.NewVarBinary("STRICT_TRANS_TABLES")},
 			},
 		},
+		"select @@global.read_only": {
+			Fields: []*querypb.Field{{
+				Type: sqltypes.Int64,
+			}},
+			Rows: [][]sqltypes.Value{
+				{sqltypes.NewInt64(0)},
+			},
+		},
 		"select @@autocommit": {
 			Fields: []*querypb.Field{{
 				Type: sqltypes.Uint64,
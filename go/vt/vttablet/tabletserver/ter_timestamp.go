@@ -0,0 +1,51 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// checkTerTimestampLocked compares the terTimestamp of a transition
+// to master with the current one. vtgate breaks the ties between
+// masters with it, so a timestamp that goes backwards can make it
+// pick a stale master. If it does, the action configured in
+// terRegression is returned, and an error if it's reject. Zero
+// timestamps, which tests and non-master transitions use, are never
+// compared.
+func (sm *stateManager) checkTerTimestampLocked(tabletType topodatapb.TabletType, terTimestamp time.Time) (string, error) {
+	if tabletType != topodatapb.TabletType_MASTER || terTimestamp.IsZero() || sm.terTimestamp.IsZero() || !terTimestamp.Before(sm.terTimestamp) {
+		return "", nil
+	}
+	switch sm.terRegression {
+	case tabletenv.Reject:
+		sm.terRegressions.Add(tabletenv.Reject, 1)
+		return tabletenv.Reject, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "transition to MASTER rejected: its timestamp %v is older than the current one %v", terTimestamp, sm.terTimestamp)
+	case tabletenv.Warn:
+		sm.terRegressions.Add(tabletenv.Warn, 1)
+		log.Warningf("Transition to MASTER with a timestamp %v older than the current one %v", terTimestamp, sm.terTimestamp)
+		return tabletenv.Warn, nil
+	}
+	return tabletenv.Ignore, nil
+}
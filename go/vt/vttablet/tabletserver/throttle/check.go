@@ -64,6 +64,9 @@ func (check *ThrottlerCheck) checkAppMetricResult(ctx context.Context, appName s
 	}
 	//
 	metricResult, threshold := check.throttler.AppRequestMetricResult(ctx, appName, metricResultFunc, denyApp)
+	if override := check.throttler.appThresholdOverride(appName); override > 0 {
+		threshold = override
+	}
 	if flags.OverrideThreshold > 0 {
 		threshold = flags.OverrideThreshold
 	}
@@ -116,6 +119,10 @@ func (check *ThrottlerCheck) Check(ctx context.Context, appName string, storeTyp
 
 	checkResult = check.checkAppMetricResult(ctx, appName, storeType, storeName, metricResultFunc, flags)
 	atomic.StoreInt64(&check.throttler.lastCheckTimeNano, time.Now().UnixNano())
+	check.throttler.appChecks.Add(appName, 1)
+	if checkResult.StatusCode != http.StatusOK {
+		check.throttler.appRejections.Add(appName, 1)
+	}
 
 	go func(statusCode int) {
 		metrics.GetOrRegisterCounter("check.any.total", nil).Inc(1)
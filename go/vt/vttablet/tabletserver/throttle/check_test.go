@@ -0,0 +1,63 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package throttle
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/base"
+)
+
+func TestCheckByAppThresholds(t *testing.T) {
+	env := tabletenv.NewEnv(tabletenv.NewDefaultConfig(), "ThrottlerTest")
+	throttler := NewThrottler(env, nil, nil)
+	throttler.mysqlClusterThresholds.Set(localStoreName, 1.0, cache.DefaultExpiration)
+	throttler.aggregatedMetrics.Set("mysql/"+localStoreName, base.NewSimpleMetricResult(0.7), cache.DefaultExpiration)
+
+	thresholds := map[string]time.Duration{}
+	throttler.InitAppThresholds(func(appName string) (time.Duration, bool) {
+		threshold, ok := thresholds[appName]
+		return threshold, ok
+	})
+	ctx := context.Background()
+	checks, rejections := throttler.appChecks.Counts()["vreplication"], throttler.appRejections.Counts()["vreplication"]
+
+	result := throttler.CheckByApp(ctx, "vreplication")
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.Equal(t, 1.0, result.Threshold)
+
+	// The overrides are read at every check.
+	thresholds["vreplication"] = 500 * time.Millisecond
+	result = throttler.CheckByApp(ctx, "vreplication")
+	assert.Equal(t, http.StatusTooManyRequests, result.StatusCode)
+	assert.Equal(t, 0.5, result.Threshold)
+	assert.Equal(t, http.StatusOK, throttler.CheckByApp(ctx, "online-ddl").StatusCode)
+
+	// An explicit threshold in the request wins.
+	result = throttler.Check(ctx, "vreplication", "", &CheckFlags{OverrideThreshold: 2})
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+
+	assert.Equal(t, checks+3, throttler.appChecks.Counts()["vreplication"])
+	assert.Equal(t, rejections+1, throttler.appRejections.Counts()["vreplication"])
+}
@@ -12,11 +12,13 @@ import (
 	"fmt"
 	"math/rand"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/vt/dbconnpool"
 	"vitess.io/vitess/go/vt/log"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
@@ -85,6 +87,9 @@ type Throttler struct {
 	check    *ThrottlerCheck
 	isLeader int64
 	isOpen   int64
+	// selfCheckInProgress is set while the lag of this tablet's own
+	// mysql is being read, when the throttler is open on a replica.
+	selfCheckInProgress int64
 
 	env            tabletenv.Env
 	pool           *connpool.Pool
@@ -113,6 +118,14 @@ type Throttler struct {
 
 	nonLowPriorityAppRequestsThrottled *cache.Cache
 	httpClient                         *http.Client
+
+	// appThreshold returns the threshold override of an app, if
+	// any. It's read at every check, so that the overrides can be
+	// reloaded at runtime.
+	appThreshold func(appName string) (time.Duration, bool)
+
+	appChecks     *stats.CountersWithSingleLabel
+	appRejections *stats.CountersWithSingleLabel
 }
 
 // ThrottlerStatus published some status values from the throttler
@@ -159,6 +172,9 @@ func NewThrottler(env tabletenv.Env, ts *topo.Server, tabletTypeFunc func() topo
 		nonLowPriorityAppRequestsThrottled: cache.New(nonDeprioritizedAppMapExpiration, nonDeprioritizedAppMapInterval),
 
 		httpClient: base.SetupHTTPClient(0),
+
+		appChecks:     env.Exporter().NewCountersWithSingleLabel("ThrottlerAppChecks", "Count of lag throttler checks by app", "app"),
+		appRejections: env.Exporter().NewCountersWithSingleLabel("ThrottlerAppRejections", "Count of lag throttler checks that did not return OK, by app", "app"),
 	}
 	throttler.initThrottleTabletTypes()
 	throttler.ThrottleApp("abusing-app", time.Now().Add(time.Hour*24*365*10), defaultThrottleRatio)
@@ -182,6 +198,25 @@ func (throttler *Throttler) initThrottleTabletTypes() {
 	throttler.throttleTabletTypesMap[topodatapb.TabletType_REPLICA] = true
 }
 
+// InitAppThresholds sets the function that returns the threshold
+// override of an app. An app with a lower threshold is throttled
+// earlier. It must be called before the first check.
+func (throttler *Throttler) InitAppThresholds(appThreshold func(appName string) (time.Duration, bool)) {
+	throttler.appThreshold = appThreshold
+}
+
+// appThresholdOverride returns the threshold of appName in seconds,
+// or 0 if it has no override.
+func (throttler *Throttler) appThresholdOverride(appName string) float64 {
+	if throttler.appThreshold == nil {
+		return 0
+	}
+	if threshold, ok := throttler.appThreshold(appName); ok {
+		return threshold.Seconds()
+	}
+	return 0
+}
+
 // InitDBConfig initializes keyspace and shard
 func (throttler *Throttler) InitDBConfig(keyspace, shard string) {
 	throttler.keyspace = keyspace
@@ -246,6 +281,11 @@ func (throttler *Throttler) Close() {
 	atomic.StoreInt64(&throttler.isOpen, 0)
 }
 
+// IsOpen returns true if the throttler is open.
+func (throttler *Throttler) IsOpen() bool {
+	return atomic.LoadInt64(&throttler.isOpen) > 0
+}
+
 // createThrottlerUser creates or updates the throttler account and assigns it a random password
 func (throttler *Throttler) createThrottlerUser(ctx context.Context) (password string, err error) {
 	if atomic.LoadInt64(&throttler.isOpen) == 0 {
@@ -381,6 +421,8 @@ func (throttler *Throttler) Operate(ctx context.Context) {
 					if !throttler.isDormant() {
 						throttler.collectMySQLMetrics(ctx)
 					}
+				} else if throttler.isSelfMode() && !throttler.isDormant() {
+					go throttler.collectSelfMetric(ctx)
 				}
 			}
 		case <-mysqlDormantCollectTicker.C:
@@ -390,6 +432,8 @@ func (throttler *Throttler) Operate(ctx context.Context) {
 					if throttler.isDormant() {
 						throttler.collectMySQLMetrics(ctx)
 					}
+				} else if throttler.isSelfMode() && throttler.isDormant() {
+					go throttler.collectSelfMetric(ctx)
 				}
 			}
 		case metric := <-throttler.mysqlThrottleMetricChan:
@@ -451,6 +495,48 @@ func (throttler *Throttler) collectMySQLMetrics(ctx context.Context) error {
 	return nil
 }
 
+// isSelfMode returns true if the throttler is open on a replica. It
+// then checks the replication lag of its own mysql rather than
+// probing the replicas of the shard.
+func (throttler *Throttler) isSelfMode() bool {
+	return atomic.LoadInt64(&throttler.isOpen) > 0 && throttler.tabletTypeFunc() != topodatapb.TabletType_MASTER
+}
+
+// collectSelfMetric reads the replication lag of this tablet's own
+// mysql, and stores it as the metric of the local store. A read is
+// skipped if the previous one is still running.
+func (throttler *Throttler) collectSelfMetric(ctx context.Context) {
+	if !atomic.CompareAndSwapInt64(&throttler.selfCheckInProgress, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt64(&throttler.selfCheckInProgress, 0)
+
+	metric := mysql.NewMySQLThrottleMetric()
+	metric.ClusterName = localStoreName
+	metric.Value, metric.Err = throttler.readSelfLag(ctx)
+	throttler.mysqlClusterThresholds.Set(localStoreName, throttleThreshold.Seconds(), cache.DefaultExpiration)
+	throttler.aggregatedMetrics.Set(fmt.Sprintf("mysql/%s", localStoreName), metric, cache.DefaultExpiration)
+}
+
+func (throttler *Throttler) readSelfLag(ctx context.Context) (float64, error) {
+	conn, err := throttler.pool.Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Recycle()
+	qr, err := conn.Exec(ctx, replicationLagQuery, 1, false)
+	if err != nil {
+		return 0, err
+	}
+	if len(qr.Rows) != 1 || len(qr.Rows[0]) != 1 {
+		return 0, fmt.Errorf("unexpected result for the replication lag: %v", qr.Rows)
+	}
+	if qr.Rows[0][0].IsNull() {
+		return 0, fmt.Errorf("no heartbeat found to measure the replication lag")
+	}
+	return strconv.ParseFloat(qr.Rows[0][0].ToString(), 64)
+}
+
 // refreshMySQLInventory will re-structure the inventory based on reading config settings, and potentially
 // re-querying dynamic data such as HAProxy list of hosts
 func (throttler *Throttler) refreshMySQLInventory(ctx context.Context) error {
@@ -695,6 +781,12 @@ func (throttler *Throttler) Check(ctx context.Context, appName string, remoteAdd
 	return throttler.check.Check(ctx, appName, "mysql", localStoreName, remoteAddr, flags)
 }
 
+// CheckByApp checks the lag on behalf of an app running in this
+// process, with the threshold override of the app if it has one.
+func (throttler *Throttler) CheckByApp(ctx context.Context, appName string) *CheckResult {
+	return throttler.Check(ctx, appName, "local", StandardCheckFlags)
+}
+
 // Status exports a status breakdown
 func (throttler *Throttler) Status() *ThrottlerStatus {
 	return &ThrottlerStatus{
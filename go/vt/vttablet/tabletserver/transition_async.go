@@ -0,0 +1,179 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"time"
+
+	"vitess.io/vitess/go/trace"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// maxAsyncTransitionResults is the number of results of completed
+// asynchronous transitions kept for TransitionStatus. The older
+// ones are forgotten.
+const maxAsyncTransitionResults = 100
+
+// ErrTransitionSuperseded is the result of an asynchronous
+// transition whose intent was replaced by a newer request
+// before it completed.
+var ErrTransitionSuperseded = vterrors.New(vtrpcpb.Code_ABORTED, "the transition was superseded by a newer request")
+
+// TransitionHandle tracks an asynchronous SetServingType. It
+// resolves once the transition completes, fails, or is superseded.
+// Nobody has to wait on it: the result is kept until it's evicted
+// by the results of newer transitions.
+type TransitionHandle struct {
+	token       int64
+	waitRetries bool
+	done        chan struct{}
+	// err is set before done is closed, under the mutex
+	// of the stateManager.
+	err error
+}
+
+// Token identifies the transition for TransitionStatus.
+func (h *TransitionHandle) Token() int64 {
+	return h.token
+}
+
+// Done is closed once the transition has a result.
+func (h *TransitionHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// NewResolvedTransitionHandle returns a handle that's already resolved
+// with err. It's meant for the implementations of Controller that
+// transition synchronously.
+func NewResolvedTransitionHandle(token int64, err error) *TransitionHandle {
+	h := &TransitionHandle{token: token, done: make(chan struct{}), err: err}
+	close(h.done)
+	return h
+}
+
+// Err returns the result of the transition. It's only valid
+// once Done is closed. It's ErrTransitionSuperseded if a newer
+// request replaced the transition.
+func (h *TransitionHandle) Err() error {
+	return h.err
+}
+
+// AsyncTransitionStatus is the status of an asynchronous transition.
+type AsyncTransitionStatus struct {
+	Token      int64  `json:"token"`
+	Done       bool   `json:"done"`
+	Superseded bool   `json:"superseded,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// SetServingTypeAsync is like SetServingType, but it returns as soon
+// as the request is queued. The handle resolves with the error of the
+// transition once it completes. If waitRetries is set, a failed
+// transition only resolves once its retries succeed. A transition
+// whose intent is replaced by a newer request, including the retries
+// it's waiting for, resolves with ErrTransitionSuperseded.
+func (sm *stateManager) SetServingTypeAsync(ctx context.Context, tabletType topodatapb.TabletType, terTimestamp time.Time, state servingState, reason string, waitRetries bool) *TransitionHandle {
+	sm.mu.Lock()
+	sm.nextTransitionToken++
+	h := &TransitionHandle{
+		token:       sm.nextTransitionToken,
+		waitRetries: waitRetries,
+		done:        make(chan struct{}),
+	}
+	if sm.asyncTransitions == nil {
+		sm.asyncTransitions = make(map[int64]*TransitionHandle)
+	}
+	sm.asyncTransitions[h.token] = h
+	sm.mu.Unlock()
+
+	// The transition must not be canceled when the caller
+	// returns, but it's still traced as a child of its span.
+	ctx = trace.CopySpan(context.Background(), ctx)
+	go func() {
+		defer sm.recoverPanic()
+		err := sm.requestServingType(ctx, tabletType, terTimestamp, state, reason, h)
+
+		sm.mu.Lock()
+		defer sm.mu.Unlock()
+		if err != nil && waitRetries && sm.asyncCurrent == h && sm.retrying {
+			// recheckState resolves h once the retries succeed.
+			return
+		}
+		sm.resolveAsyncLocked(h, err)
+	}()
+	return h
+}
+
+// TransitionStatus returns the status of the asynchronous transition
+// identified by token. It returns false if the token is unknown, or
+// if its result was forgotten.
+func (sm *stateManager) TransitionStatus(token int64) (AsyncTransitionStatus, bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	h, ok := sm.asyncTransitions[token]
+	if !ok {
+		return AsyncTransitionStatus{}, false
+	}
+	status := AsyncTransitionStatus{Token: token}
+	select {
+	case <-h.done:
+		status.Done = true
+	default:
+		return status, true
+	}
+	if h.err != nil {
+		status.Superseded = h.err == ErrTransitionSuperseded
+		status.Error = h.err.Error()
+	}
+	return status, true
+}
+
+// acceptAsyncLocked is called when a request replaces the intent of
+// sm. h is the handle of the request if it's asynchronous. The handle
+// of the previous intent, if any, is superseded.
+func (sm *stateManager) acceptAsyncLocked(h *TransitionHandle) {
+	if current := sm.asyncCurrent; current != nil && current != h {
+		sm.resolveAsyncLocked(current, ErrTransitionSuperseded)
+	}
+	sm.asyncCurrent = h
+}
+
+// resolveAsyncLocked resolves h with err, unless it's already
+// resolved. The results beyond maxAsyncTransitionResults are
+// forgotten, oldest first.
+func (sm *stateManager) resolveAsyncLocked(h *TransitionHandle, err error) {
+	if sm.asyncCurrent == h {
+		sm.asyncCurrent = nil
+	}
+	select {
+	case <-h.done:
+		return
+	default:
+	}
+	h.err = err
+	close(h.done)
+
+	sm.asyncResolved = append(sm.asyncResolved, h.token)
+	if len(sm.asyncResolved) > maxAsyncTransitionResults {
+		delete(sm.asyncTransitions, sm.asyncResolved[0])
+		sm.asyncResolved = sm.asyncResolved[1:]
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/timer/fakeclock"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestTabletServerSetServingTypeAsync(t *testing.T) {
+	db, tsv := setupTabletServerTest(t)
+	defer tsv.StopService()
+	defer db.Close()
+
+	h := tsv.SetServingTypeAsync(context.Background(), topodatapb.TabletType_REPLICA, time.Time{}, true, "", false)
+	<-h.Done()
+	require.NoError(t, h.Err())
+	assert.Equal(t, topodatapb.TabletType_REPLICA, tsv.sm.Target().TabletType)
+	status, ok := tsv.TransitionStatus(h.Token())
+	require.True(t, ok)
+	assert.Equal(t, AsyncTransitionStatus{Token: h.Token(), Done: true}, status)
+	_, ok = tsv.TransitionStatus(h.Token() + 1)
+	assert.False(t, ok)
+}
+
+func TestStateManagerSetServingTypeAsync(t *testing.T) {
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+
+	h := sm.SetServingTypeAsync(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "", false)
+	<-h.Done()
+	require.NoError(t, h.Err())
+	assert.Equal(t, StateServing, sm.State())
+	status, ok := sm.TransitionStatus(h.Token())
+	require.True(t, ok)
+	assert.Equal(t, AsyncTransitionStatus{Token: h.Token(), Done: true}, status)
+
+	// Without waitRetries, a failed transition resolves with its error.
+	sm.se.(*testSchemaEngine).failMySQL = true
+	h = sm.SetServingTypeAsync(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "", false)
+	<-h.Done()
+	require.Error(t, h.Err())
+	assert.Contains(t, h.Err().Error(), "intentional error")
+	status, ok = sm.TransitionStatus(h.Token())
+	require.True(t, ok)
+	assert.True(t, status.Done)
+	assert.False(t, status.Superseded)
+	assert.Contains(t, status.Error, "intentional error")
+
+	// Only the last results are kept.
+	first := h.Token()
+	for i := 0; i < maxAsyncTransitionResults; i++ {
+		<-sm.SetServingTypeAsync(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "", false).Done()
+	}
+	_, ok = sm.TransitionStatus(first)
+	assert.False(t, ok)
+	_, ok = sm.TransitionStatus(first + 1)
+	assert.True(t, ok)
+	assert.Len(t, sm.asyncTransitions, maxAsyncTransitionResults)
+	assert.Nil(t, sm.asyncCurrent)
+}
+
+func TestStateManagerSetServingTypeAsyncRetries(t *testing.T) {
+	fc := fakeclock.New(testNow)
+	sm := newTestStateManagerWithClock(t, fc)
+	defer sm.StopService()
+	sm.se.(*testSchemaEngine).failMySQL = true
+
+	h := sm.SetServingTypeAsync(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "", true)
+	assert.Eventually(t, func() bool {
+		sm.mu.Lock()
+		defer sm.mu.Unlock()
+		return sm.retrying && sm.transitionErr != nil
+	}, 5*time.Second, time.Millisecond)
+	status, ok := sm.TransitionStatus(h.Token())
+	require.True(t, ok)
+	assert.False(t, status.Done)
+
+	// The retry transitions, and the one after that sees
+	// that the state has converged. The retry timer is
+	// pending along with the health check timer.
+	fc.BlockUntil(2)
+	fc.Advance(transitionRetryInterval)
+	for sm.State() != StateServing {
+		time.Sleep(time.Millisecond)
+	}
+	fc.BlockUntil(2)
+	fc.Advance(transitionRetryInterval)
+	<-h.Done()
+	require.NoError(t, h.Err())
+	status, _ = sm.TransitionStatus(h.Token())
+	assert.Equal(t, AsyncTransitionStatus{Token: h.Token(), Done: true}, status)
+}
+
+func TestStateManagerSetServingTypeAsyncSuperseded(t *testing.T) {
+	fc := fakeclock.New(testNow)
+	sm := newTestStateManagerWithClock(t, fc)
+	defer sm.StopService()
+	sm.se.(*testSchemaEngine).failMySQL = true
+
+	h := sm.SetServingTypeAsync(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "", true)
+	assert.Eventually(t, func() bool {
+		sm.mu.Lock()
+		defer sm.mu.Unlock()
+		return sm.retrying && sm.transitionErr != nil
+	}, 5*time.Second, time.Millisecond)
+
+	// A newer request replaces the intent the retries were for.
+	err := sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateNotServing, "")
+	require.NoError(t, err)
+	<-h.Done()
+	assert.Equal(t, ErrTransitionSuperseded, h.Err())
+	status, ok := sm.TransitionStatus(h.Token())
+	require.True(t, ok)
+	assert.True(t, status.Done)
+	assert.True(t, status.Superseded)
+
+	// So does a shutdown.
+	sm.se.(*testSchemaEngine).failMySQL = true
+	h = sm.SetServingTypeAsync(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, "", true)
+	assert.Eventually(t, func() bool {
+		sm.mu.Lock()
+		defer sm.mu.Unlock()
+		return sm.asyncCurrent == h && sm.transitionErr != nil
+	}, 5*time.Second, time.Millisecond)
+	sm.StopService()
+	<-h.Done()
+	assert.Equal(t, ErrTransitionSuperseded, h.Err())
+}
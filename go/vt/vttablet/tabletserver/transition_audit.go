@@ -0,0 +1,212 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// The events recorded in the transition audit log.
+const (
+	auditSetServingType = "SetServingType"
+	auditCheckMySQL     = "CheckMySQL"
+	auditEnterLameduck  = "EnterLameduck"
+	auditExitLameduck   = "ExitLameduck"
+	auditStopService    = "StopService"
+)
+
+// TransitionAuditEntry is a line of the transition audit log. It
+// records an event that drives the state transitions, with the inputs
+// needed to replay it, and the state that the event left the tablet in.
+type TransitionAuditEntry struct {
+	Time  time.Time `json:"time"`
+	Event string    `json:"event"`
+
+	// WantTabletType, WantState, TerTimestamp and Reason
+	// are the arguments of SetServingType.
+	WantTabletType string    `json:"wantTabletType,omitempty"`
+	WantState      string    `json:"wantState,omitempty"`
+	TerTimestamp   time.Time `json:"terTimestamp"`
+	Reason         string    `json:"reason,omitempty"`
+	// TerRegression is set if TerTimestamp was older than the
+	// current one. It's the action taken: reject, warn or ignore.
+	TerRegression string `json:"terRegression,omitempty"`
+	// MySQLError is the error found by CheckMySQL. WriteError is
+	// set if mysql could be reached, but failed writes.
+	MySQLError string `json:"mysqlError,omitempty"`
+	WriteError bool   `json:"writeError,omitempty"`
+
+	// TabletType, State and Lameduck are the state after the event.
+	TabletType string `json:"tabletType"`
+	State      string `json:"state"`
+	Lameduck   bool   `json:"lameduck,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// transitionAudit appends the entries of the transition
+// audit log to a file, one JSON object per line.
+type transitionAudit struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func openTransitionAudit(path string) (*transitionAudit, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &transitionAudit{file: file}, nil
+}
+
+// record appends entry to the log and syncs it, so that the log
+// survives a crash. Failures are logged, but don't fail the event.
+func (ta *transitionAudit) record(entry *TransitionAuditEntry) {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		log.Errorf("Could not marshal transition audit entry: %v", err)
+		return
+	}
+	ta.mu.Lock()
+	defer ta.mu.Unlock()
+	if _, err := ta.file.Write(append(b, '\n')); err != nil {
+		log.Errorf("Could not write transition audit entry: %v", err)
+		return
+	}
+	if err := ta.file.Sync(); err != nil {
+		log.Errorf("Could not sync transition audit log: %v", err)
+	}
+}
+
+// audit completes entry with the current state of sm
+// and the outcome of the event, and records it.
+func (sm *stateManager) audit(entry *TransitionAuditEntry, err error) {
+	if sm.transitionAudit == nil {
+		return
+	}
+	sm.mu.Lock()
+	entry.Time = sm.clock.Now()
+	entry.TabletType = sm.target.TabletType.String()
+	entry.State = sm.state.String()
+	entry.Lameduck = sm.lameduck
+	sm.mu.Unlock()
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	sm.transitionAudit.record(entry)
+}
+
+// ParseTransitionAudit reads the entries of a transition audit log.
+func ParseTransitionAudit(r io.Reader) ([]*TransitionAuditEntry, error) {
+	var entries []*TransitionAuditEntry
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		entry := &TransitionAuditEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), entry); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ReplayTransitions applies the events of a transition audit log to
+// sm, typically built with fake subcomponents, to reproduce ordering
+// bugs. The events keep their original relative timing, compressed by
+// speedup. If speedup is 0, they're applied back to back. It returns
+// an error describing the first event that left sm in a different
+// state than the recorded one.
+func (sm *stateManager) ReplayTransitions(entries []*TransitionAuditEntry, speedup float64) error {
+	for i, entry := range entries {
+		if i > 0 && speedup > 0 {
+			<-sm.clock.After(time.Duration(float64(entry.Time.Sub(entries[i-1].Time)) / speedup))
+		}
+		err := sm.replayEvent(entry)
+		if err != nil && entry.Error == "" {
+			return fmt.Errorf("entry %d (%s at %v) diverged: %v", i+1, entry.Event, entry.Time, err)
+		}
+		if err == nil && entry.Error != "" {
+			return fmt.Errorf("entry %d (%s at %v) diverged: succeeded, recorded error: %s", i+1, entry.Event, entry.Time, entry.Error)
+		}
+
+		sm.mu.Lock()
+		tabletType, state, lameduck := sm.target.TabletType.String(), sm.state.String(), sm.lameduck
+		sm.mu.Unlock()
+		if tabletType != entry.TabletType || state != entry.State || lameduck != entry.Lameduck {
+			return fmt.Errorf("entry %d (%s at %v) diverged: got %s %s (lameduck: %v), recorded %s %s (lameduck: %v)",
+				i+1, entry.Event, entry.Time, tabletType, state, lameduck, entry.TabletType, entry.State, entry.Lameduck)
+		}
+	}
+	return nil
+}
+
+func (sm *stateManager) replayEvent(entry *TransitionAuditEntry) error {
+	switch entry.Event {
+	case auditSetServingType:
+		tabletType, ok := topodatapb.TabletType_value[entry.WantTabletType]
+		if !ok {
+			return fmt.Errorf("unknown tablet type %q", entry.WantTabletType)
+		}
+		state, ok := servingStateByName(entry.WantState)
+		if !ok {
+			return fmt.Errorf("unknown state %q", entry.WantState)
+		}
+		return sm.SetServingType(context.Background(), topodatapb.TabletType(tabletType), entry.TerTimestamp, state, entry.Reason)
+	case auditCheckMySQL:
+		var err error = errors.New(entry.MySQLError)
+		if entry.WriteError {
+			err = &mysqlWriteError{err: err}
+		}
+		sm.handleMySQLError(context.Background(), err)
+	case auditEnterLameduck:
+		sm.EnterLameduck()
+	case auditExitLameduck:
+		sm.ExitLameduck()
+	case auditStopService:
+		sm.StopService()
+	default:
+		return fmt.Errorf("unknown event %q", entry.Event)
+	}
+	return nil
+}
+
+// servingStateByName returns the servingState whose String is name.
+func servingStateByName(name string) (servingState, bool) {
+	for state := StateNotConnected; state <= StateServingReadOnly; state++ {
+		if state.String() == name {
+			return state, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/timer/fakeclock"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// readTransitionAudit returns the entries of the audit log at file.
+func readTransitionAudit(t *testing.T, file string) []*TransitionAuditEntry {
+	t.Helper()
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+	entries, err := ParseTransitionAudit(f)
+	require.NoError(t, err)
+	return entries
+}
+
+// replayTransitionAudit replays entries against a
+// stateManager built with the test fakes.
+func replayTransitionAudit(t *testing.T, entries []*TransitionAuditEntry, speedup float64) error {
+	t.Helper()
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	return sm.ReplayTransitions(entries, speedup)
+}
+
+func TestTransitionAuditReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transition_audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := path.Join(dir, "audit.jsonl")
+
+	sm := newTestStateManager(t)
+	sm.transitionAudit, err = openTransitionAudit(file)
+	require.NoError(t, err)
+
+	require.NoError(t, sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, ""))
+	sm.EnterLameduck()
+	require.NoError(t, sm.SetServingType(context.Background(), topodatapb.TabletType_REPLICA, testNow, StateServing, "planned reparent"))
+	sm.qe.(*testQueryEngine).failMySQL = true
+	sm.CheckMySQL()
+	for len(readTransitionAudit(t, file)) < 4 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.NoError(t, sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, ""))
+	sm.ExitLameduck()
+	sm.StopService()
+
+	entries := readTransitionAudit(t, file)
+	var events []string
+	for _, entry := range entries {
+		events = append(events, entry.Event+": "+entry.TabletType+" "+entry.State)
+	}
+	want := []string{
+		"SetServingType: MASTER Serving",
+		"EnterLameduck: MASTER Serving",
+		"SetServingType: REPLICA Serving",
+		"CheckMySQL: REPLICA Not connected to mysql",
+		"SetServingType: MASTER Serving",
+		"ExitLameduck: MASTER Serving",
+		"StopService: MASTER Not connected to mysql",
+	}
+	assert.Equal(t, want, events)
+	assert.True(t, entries[1].Lameduck)
+	assert.False(t, entries[2].Lameduck)
+	assert.Equal(t, "planned reparent", entries[2].Reason)
+	assert.Equal(t, "intentional error", entries[3].MySQLError)
+
+	assert.NoError(t, replayTransitionAudit(t, entries, 0))
+
+	// The first divergence is reported.
+	entries[2].TabletType = "RDONLY"
+	entries[4].State = "Not Serving"
+	err = replayTransitionAudit(t, entries, 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "entry 3 (SetServingType at")
+	assert.Contains(t, err.Error(), "diverged: got REPLICA Serving (lameduck: false), recorded RDONLY Serving (lameduck: false)")
+}
+
+func TestTransitionAuditTerRegression(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transition_audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := path.Join(dir, "audit.jsonl")
+
+	sm := newTestStateManager(t)
+	defer sm.StopService()
+	sm.transitionAudit, err = openTransitionAudit(file)
+	require.NoError(t, err)
+	sm.terRegression = tabletenv.Reject
+
+	require.NoError(t, sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow, StateServing, ""))
+	require.Error(t, sm.SetServingType(context.Background(), topodatapb.TabletType_MASTER, testNow.Add(-time.Minute), StateServing, ""))
+
+	entries := readTransitionAudit(t, file)
+	require.Len(t, entries, 2)
+	assert.Empty(t, entries[0].TerRegression)
+	assert.Equal(t, "reject", entries[1].TerRegression)
+	assert.Contains(t, entries[1].Error, "transition to MASTER rejected")
+}
+
+func TestTransitionAuditReplayTiming(t *testing.T) {
+	fc := fakeclock.New(testNow)
+	sm := newTestStateManagerWithClock(t, fc)
+	defer sm.StopService()
+
+	entries := []*TransitionAuditEntry{{
+		Time:           testNow,
+		Event:          auditSetServingType,
+		WantTabletType: "MASTER",
+		WantState:      "Serving",
+		TabletType:     "MASTER",
+		State:          "Serving",
+	}, {
+		Time:       testNow.Add(time.Hour),
+		Event:      auditEnterLameduck,
+		TabletType: "MASTER",
+		State:      "Serving",
+		Lameduck:   true,
+	}}
+	done := make(chan error)
+	go func() {
+		done <- sm.ReplayTransitions(entries, 3600)
+	}()
+
+	// The hour between the events is compressed to a second.
+	fc.BlockUntil(1)
+	for !sm.IsServing() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	fc.Advance(999 * time.Millisecond)
+	assert.True(t, sm.IsServing())
+	fc.Advance(time.Millisecond)
+	assert.NoError(t, <-done)
+	assert.False(t, sm.IsServing())
+}
+
+func TestParseTransitionAudit(t *testing.T) {
+	entries, err := ParseTransitionAudit(strings.NewReader(`{"event":"EnterLameduck","tabletType":"MASTER","state":"Serving","lameduck":true}
+
+{"event":"ExitLameduck","tabletType":"MASTER","state":"Serving"}
+`))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, auditEnterLameduck, entries[0].Event)
+	assert.True(t, entries[0].Lameduck)
+
+	_, err = ParseTransitionAudit(strings.NewReader("{}\nnot json\n"))
+	assert.Contains(t, err.Error(), "line 2:")
+
+	err = newTestStateManager(t).ReplayTransitions([]*TransitionAuditEntry{{Event: "Reboot"}}, 0)
+	assert.Contains(t, err.Error(), `diverged: unknown event "Reboot"`)
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// transitionStep is an operation of a transition that opens a
+// subcomponent. It runs once the steps named in after succeeded.
+// Those must come before it in the list of steps, so that running
+// the list in order satisfies the dependencies too.
+type transitionStep struct {
+	name  string
+	after []string
+	run   func(ctx context.Context) error
+	// untimed is set for the steps that aren't recorded as
+	// transition operations, because they only wire components.
+	untimed bool
+}
+
+// runSteps runs steps with ctx, and returns the first error. If
+// serialOpens is set, they run one at a time, in order. Otherwise,
+// every step starts as soon as the steps it depends on succeeded. The
+// dependencies that aren't part of steps are ignored. After a
+// failure, the steps that didn't start yet are skipped, and the ones
+// that are running are canceled, and complete before runSteps
+// returns. If ctx expires, the steps that were running, or else the
+// first one that was skipped, fail with a transitionTimeoutError. A panic in a step is raised again in the
+// calling goroutine, for the transition to recover it. That only
+// happens if crashOnPanic is set: otherwise, runStep returns the
+// panics as errors, for the transition to be retried.
+func (sm *stateManager) runSteps(ctx context.Context, steps []transitionStep) error {
+	if sm.serialOpens {
+		for _, step := range steps {
+			if ctx.Err() != nil {
+				return sm.stepTimeout(ctx, step.name, nil)
+			}
+			if err := sm.runStep(ctx, step); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	done := make(map[string]chan struct{}, len(steps))
+	for _, step := range steps {
+		done[step.name] = make(chan struct{})
+	}
+	var (
+		panicOnce sync.Once
+		panicked  interface{}
+		skipOnce  sync.Once
+		skipped   string
+	)
+	skip := func(name string) {
+		skipOnce.Do(func() { skipped = name })
+	}
+	g, gctx := errgroup.WithContext(ctx)
+	for _, step := range steps {
+		step := step
+		g.Go(func() (err error) {
+			defer func() {
+				if x := recover(); x != nil {
+					panicOnce.Do(func() { panicked = x })
+					err = fmt.Errorf("%s panicked: %v", step.name, x)
+				}
+			}()
+			for _, dep := range step.after {
+				ch, ok := done[dep]
+				if !ok {
+					continue
+				}
+				select {
+				case <-ch:
+				case <-gctx.Done():
+					skip(step.name)
+					return nil
+				}
+			}
+			if gctx.Err() != nil {
+				skip(step.name)
+				return nil
+			}
+			if err := sm.runStep(gctx, step); err != nil {
+				return err
+			}
+			close(done[step.name])
+			return nil
+		})
+	}
+	err := g.Wait()
+	if panicked != nil {
+		panic(panicked)
+	}
+	if err == nil && skipped != "" {
+		// ctx expired while the steps that were running
+		// completed without noticing.
+		return sm.stepTimeout(ctx, skipped, nil)
+	}
+	return err
+}
+
+func (sm *stateManager) runStep(ctx context.Context, step transitionStep) error {
+	run := func() (err error) {
+		if !sm.crashOnPanic {
+			defer sm.recoverComponentPanic(step.name, &err)
+		}
+		err = step.run(ctx)
+		if ctx.Err() == context.DeadlineExceeded {
+			return sm.stepTimeout(ctx, step.name, err)
+		}
+		return err
+	}
+	if step.untimed {
+		return run()
+	}
+	return sm.timeOp(step.name, run)
+}
+
+// transitionTimeoutError is returned by the steps that were running,
+// or waiting to run, when the context of a transition expired. It's
+// reported as a health error until a transition succeeds.
+type transitionTimeoutError struct {
+	step    string
+	timeout time.Duration
+	err     error
+}
+
+func (e *transitionTimeoutError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("%s timed out: the transition did not complete within %v", e.step, e.timeout)
+	}
+	return fmt.Sprintf("%s timed out: the transition did not complete within %v: %v", e.step, e.timeout, e.err)
+}
+
+// stepTimeout returns the error of the step name, which completed
+// with err, or didn't run if err is nil, once ctx is done. Unless
+// ctx expired, that's err: the steps are canceled after a failure,
+// which is then the one runSteps returns.
+func (sm *stateManager) stepTimeout(ctx context.Context, name string, err error) error {
+	if ctx.Err() != context.DeadlineExceeded {
+		return err
+	}
+	return &transitionTimeoutError{step: name, timeout: sm.transitionTimeout, err: err}
+}
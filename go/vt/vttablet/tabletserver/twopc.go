@@ -108,6 +108,7 @@ type TwoPC struct {
 	deleteRedoStmt      *sqlparser.ParsedQuery
 	readAllRedo         string
 	countUnresolvedRedo *sqlparser.ParsedQuery
+	unresolvedRedoStats string
 
 	insertTransaction   *sqlparser.ParsedQuery
 	insertParticipants  *sqlparser.ParsedQuery
@@ -143,6 +144,8 @@ func NewTwoPC(readPool *connpool.Pool) *TwoPC {
 	tpc.countUnresolvedRedo = sqlparser.BuildParsedQuery(
 		"select count(*) from %s.redo_state where time_created < %a",
 		dbname, ":time_created")
+	tpc.unresolvedRedoStats = fmt.Sprintf(
+		"select count(*), min(time_created) from %s.redo_state", dbname)
 
 	tpc.insertTransaction = sqlparser.BuildParsedQuery(
 		"insert into %s.dt_state(dtid, state, time_created) values (%a, %a, %a)",
@@ -172,8 +175,9 @@ func NewTwoPC(readPool *connpool.Pool) *TwoPC {
 	return tpc
 }
 
-// Open starts the TwoPC service.
-func (tpc *TwoPC) Open(dbconfigs *dbconfigs.DBConfigs) error {
+// CreateTables creates the sidecar tables of the TwoPC
+// service. It must be called before Open.
+func (tpc *TwoPC) CreateTables(dbconfigs *dbconfigs.DBConfigs) error {
 	dbname := "_vt"
 	conn, err := dbconnpool.NewDBConnection(context.TODO(), dbconfigs.DbaWithDB())
 	if err != nil {
@@ -196,10 +200,14 @@ func (tpc *TwoPC) Open(dbconfigs *dbconfigs.DBConfigs) error {
 			return err
 		}
 	}
-	tpc.readPool.Open(dbconfigs.AppWithDB(), dbconfigs.DbaWithDB(), dbconfigs.DbaWithDB())
 	return nil
 }
 
+// Open starts the TwoPC service.
+func (tpc *TwoPC) Open(dbconfigs *dbconfigs.DBConfigs) {
+	tpc.readPool.Open(dbconfigs.AppWithDB(), dbconfigs.DbaWithDB(), dbconfigs.DbaWithDB())
+}
+
 // Close closes the TwoPC service.
 func (tpc *TwoPC) Close() {
 	tpc.readPool.Close()
@@ -325,6 +333,30 @@ func (tpc *TwoPC) CountUnresolvedRedo(ctx context.Context, unresolvedTime time.T
 	return v, nil
 }
 
+// UnresolvedRedoStats returns the number of transactions in the redo
+// log, and the time the oldest one was created.
+func (tpc *TwoPC) UnresolvedRedoStats(ctx context.Context) (count int64, oldest time.Time, err error) {
+	conn, err := tpc.readPool.Get(ctx)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	defer conn.Recycle()
+
+	qr, err := conn.Exec(ctx, tpc.unresolvedRedoStats, 1, false)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	if len(qr.Rows) < 1 {
+		return 0, time.Time{}, nil
+	}
+	count, _ = evalengine.ToInt64(qr.Rows[0][0])
+	if count == 0 {
+		return 0, time.Time{}, nil
+	}
+	tm, _ := evalengine.ToInt64(qr.Rows[0][1])
+	return count, time.Unix(0, tm), nil
+}
+
 // CreateTransaction saves the metadata of a 2pc transaction as Prepared.
 func (tpc *TwoPC) CreateTransaction(ctx context.Context, conn *StatefulConnection, dtid string, participants []*querypb.Target) error {
 	bindVars := map[string]*querypb.BindVariable{
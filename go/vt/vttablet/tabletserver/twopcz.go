@@ -139,9 +139,9 @@ func twopczHandler(txe *TxExecutor, w http.ResponseWriter, r *http.Request) {
 	action := r.FormValue("Action")
 	switch action {
 	case "Discard", "Rollback":
-		err = txe.RollbackPrepared(dtid, 0)
+		err = txe.ResolvePrepared(dtid, false)
 	case "Commit":
-		err = txe.CommitPrepared(dtid)
+		err = txe.ResolvePrepared(dtid, true)
 	case "Conclude":
 		err = txe.ConcludeTransaction(dtid)
 	}
@@ -17,11 +17,13 @@ limitations under the License.
 package tx
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/servenv"
 )
@@ -37,7 +39,7 @@ type (
 	//whether new connections and/or transactions are allowed or not.
 	EngineStateMachine interface {
 		Init() error
-		AcceptReadWrite() error
+		AcceptReadWrite(ctx context.Context) error
 		AcceptReadOnly() error
 		StopGently()
 	}
@@ -52,12 +54,17 @@ type (
 		ImmediateCaller *querypb.VTGateCallerID
 		StartTime       time.Time
 		EndTime         time.Time
+		TabletType      topodatapb.TabletType
 		Queries         []string
 		Autocommit      bool
 		Conclusion      string
 		LogToFile       bool
 
 		Stats *servenv.TimingsWrapper
+
+		// ReadOnly is set if the transaction was started read-only.
+		// Its writes are rejected.
+		ReadOnly bool
 	}
 )
 
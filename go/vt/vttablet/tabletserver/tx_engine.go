@@ -18,6 +18,7 @@ package tabletserver
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -27,12 +28,17 @@ import (
 
 	"golang.org/x/net/context"
 
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/streamlog"
+	"vitess.io/vitess/go/sync2"
 	"vitess.io/vitess/go/timer"
 	"vitess.io/vitess/go/trace"
+	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/concurrency"
 	"vitess.io/vitess/go/vt/dtids"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/vtgateconn"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/connpool"
@@ -89,11 +95,28 @@ type TxEngine struct {
 	beginRequests sync.WaitGroup
 
 	twopcEnabled        bool
-	shutdownGracePeriod time.Duration
+	// live provides the shutdown grace period, which can be
+	// reloaded at runtime.
+	live                *liveConfig
+	drainGracePeriod    time.Duration
+	// draining is set while a transition out of AcceptingReadAndWrite
+	// waits for the open transactions to complete.
+	draining sync2.AtomicBool
 	coordinatorAddress  string
 	abandonAge          time.Duration
 	ticks               *timer.Timer
 
+	// unresolved is refreshed by the watchdog, so it's only set
+	// while the TxEngine accepts writes with 2pc enabled.
+	unresolvedMu sync.Mutex
+	unresolved   unresolvedPrepares
+	// resolutions counts the abandoned transactions that were
+	// resolved by the watchdog, or by hand.
+	resolutions *stats.CountersWithSingleLabel
+	// accessModes counts the transactions begun by
+	// access mode, ReadOnly or ReadWrite.
+	accessModes *stats.CountersWithSingleLabel
+
 	// reservedConnStats keeps statistics about reserved connections
 	reservedConnStats *servenv.TimingsWrapper
 
@@ -107,11 +130,18 @@ func NewTxEngine(env tabletenv.Env) *TxEngine {
 	config := env.Config()
 	te := &TxEngine{
 		env:                 env,
-		shutdownGracePeriod: config.GracePeriods.TransactionShutdownSeconds.Get(),
+		live:                newLiveConfig(config),
+		drainGracePeriod:    config.GracePeriods.TransactionDrainSeconds.Get(),
 		reservedConnStats:   env.Exporter().NewTimings("ReservedConnections", "Reserved connections stats", "operation"),
+		resolutions:         env.Exporter().NewCountersWithSingleLabel("TwopcResolutions", "Number of abandoned 2pc transactions resolved by the watchdog (Auto) or by hand (Manual)", "Type"),
+		accessModes:         env.Exporter().NewCountersWithSingleLabel("TransactionAccessModes", "Number of transactions begun, read-only (ReadOnly) or not (ReadWrite)", "Mode"),
 	}
 	limiter := txlimiter.New(env)
 	te.txPool = NewTxPool(env, limiter)
+	te.txPool.callerLimiter.InitCaps(func(caller string) (int64, bool) {
+		// te.live is replaced by the one of the TabletServer.
+		return te.live.TransactionCap(caller)
+	})
 	te.twopcEnabled = config.TwoPCEnable
 	if te.twopcEnabled {
 		if config.TwoPCCoordinatorAddress == "" {
@@ -148,10 +178,23 @@ func NewTxEngine(env tabletenv.Env) *TxEngine {
 	return te
 }
 
+// CreateSidecarTables creates the sidecar tables needed by 2pc.
+// It must succeed before AcceptReadWrite is called. It's a no-op
+// if 2pc is disabled.
+func (te *TxEngine) CreateSidecarTables() error {
+	if !te.twopcEnabled {
+		return nil
+	}
+	return te.twoPC.CreateTables(te.env.Config().DB)
+}
+
 // AcceptReadWrite will start accepting all transactions.
 // If transitioning from RO mode, transactions might need to be
 // rolled back before new transactions can be accepts.
-func (te *TxEngine) AcceptReadWrite() error {
+// If 2pc is enabled, the prepared transactions are read back
+// from the redo log with ctx. If it's done before they are,
+// AcceptReadWrite fails, and the engine must be closed.
+func (te *TxEngine) AcceptReadWrite(ctx context.Context) error {
 	te.beginRequests.Wait()
 	te.stateLock.Lock()
 	log.Info("TxEngine: AcceptReadWrite")
@@ -164,9 +207,9 @@ func (te *TxEngine) AcceptReadWrite() error {
 
 	case NotServing:
 		te.state = AcceptingReadAndWrite
-		te.open()
+		err := te.open(ctx)
 		te.stateLock.Unlock()
-		return nil
+		return err
 
 	case Transitioning:
 		te.nextState = AcceptingReadAndWrite
@@ -178,9 +221,9 @@ func (te *TxEngine) AcceptReadWrite() error {
 		// We need to restart the tx-pool to make sure we handle 2PC correctly
 		te.shutdown(true)
 		te.state = AcceptingReadAndWrite
-		te.open()
+		err := te.open(ctx)
 		te.stateLock.Unlock()
-		return nil
+		return err
 
 	default:
 		return te.unknownStateError()
@@ -202,7 +245,7 @@ func (te *TxEngine) AcceptReadOnly() error {
 
 	case NotServing:
 		te.state = AcceptingReadOnly
-		te.open()
+		_ = te.open(tabletenv.LocalContext())
 		te.stateLock.Unlock()
 		return nil
 
@@ -221,6 +264,12 @@ func (te *TxEngine) AcceptReadOnly() error {
 	}
 }
 
+// PoolUsage returns the number of connections of the transaction
+// pool that are in use, and its capacity.
+func (te *TxEngine) PoolUsage() (inUse, capacity int64) {
+	return te.txPool.scp.InUse(), int64(te.txPool.scp.Capacity())
+}
+
 // Begin begins a transaction, and returns the associated transaction id and the
 // statement(s) used to execute the begin (if any).
 //
@@ -237,20 +286,44 @@ func (te *TxEngine) Begin(ctx context.Context, preQueries []string, reservedID i
 		return 0, "", vterrors.Errorf(vtrpc.Code_UNAVAILABLE, "tx engine can't accept new transactions in state %v", te.state)
 	}
 
+	readOnly := te.readOnlyLocked(options)
+
 	// By Add() to beginRequests, we block others from initiating state
 	// changes until we have finished adding this transaction
 	te.beginRequests.Add(1)
 	te.stateLock.Unlock()
 
 	defer te.beginRequests.Done()
-	conn, beginSQL, err := te.txPool.Begin(ctx, options, te.state == AcceptingReadOnly, reservedID, preQueries)
+	conn, beginSQL, err := te.txPool.Begin(ctx, options, readOnly, reservedID, preQueries)
 	if err != nil {
 		return 0, "", err
 	}
 	defer conn.UnlockUpdateTime()
+	te.accessModes.Add(accessMode(readOnly), 1)
 	return conn.ID(), beginSQL, err
 }
 
+// readOnlyLocked returns true if a transaction begun with options is
+// read-only: all of them if the TxEngine only accepts read-only
+// transactions, or those that ask for a read-only snapshot.
+func (te *TxEngine) readOnlyLocked(options *querypb.ExecuteOptions) bool {
+	return te.state == AcceptingReadOnly || isReadOnlyTransaction(options)
+}
+
+// isReadOnlyTransaction returns true if options ask for a read-only
+// transaction, which is also accepted by a TxEngine that only accepts
+// read-only transactions.
+func isReadOnlyTransaction(options *querypb.ExecuteOptions) bool {
+	return options.GetTransactionIsolation() == querypb.ExecuteOptions_CONSISTENT_SNAPSHOT_READ_ONLY
+}
+
+func accessMode(readOnly bool) string {
+	if readOnly {
+		return "ReadOnly"
+	}
+	return "ReadWrite"
+}
+
 // Commit commits the specified transaction and renews connection id if one exists.
 func (te *TxEngine) Commit(ctx context.Context, transactionID int64) (int64, string, error) {
 	span, ctx := trace.NewSpan(ctx, "TxEngine.Commit")
@@ -303,13 +376,25 @@ func (te *TxEngine) blockUntilEndOfTransition() error {
 }
 
 func (te *TxEngine) transitionTo(nextState txEngineState) error {
+	drain := te.state == AcceptingReadAndWrite && nextState == AcceptingReadOnly && te.drainGracePeriod > 0
 	te.state = Transitioning
 	te.nextState = nextState
 	te.transitionSignal = make(chan struct{})
 	te.stateLock.Unlock()
 
 	// We do this outside the lock so others can see our state while we close up waiting transactions
-	te.shutdown(true)
+	if drain {
+		deadline := time.Now().Add(te.drainGracePeriod)
+		te.drain()
+		// The shutdown only releases the reserved connections that
+		// are not in use. The others would block it for as long as
+		// their clients hold them.
+		stopForcedRelease := te.startForcedRelease(deadline)
+		te.shutdown(true)
+		stopForcedRelease()
+	} else {
+		te.shutdown(true)
+	}
 
 	te.stateLock.Lock()
 	defer func() {
@@ -327,7 +412,7 @@ func (te *TxEngine) transitionTo(nextState txEngineState) error {
 	switch te.nextState {
 	case AcceptingReadAndWrite, AcceptingReadOnly:
 		te.state = te.nextState
-		te.open()
+		_ = te.open(tabletenv.LocalContext())
 	case NotServing:
 		te.state = NotServing
 	case Transitioning:
@@ -339,26 +424,169 @@ func (te *TxEngine) transitionTo(nextState txEngineState) error {
 	return nil
 }
 
-// open opens the TxEngine. If 2pc is enabled, it restores
-// all previously prepared transactions from the redo log.
-// this should only be called when the state is already locked
-func (te *TxEngine) open() {
+// open opens the pools. If 2pc is enabled, it restores all
+// previously prepared transactions from the redo log. It only fails
+// if ctx is done before they're read back: the other errors of the
+// restore are logged, and the engine opens anyway. It must be called
+// while the state is locked.
+func (te *TxEngine) open(ctx context.Context) error {
 	te.txPool.Open(te.env.Config().DB.AppWithDB(), te.env.Config().DB.DbaWithDB(), te.env.Config().DB.AppDebugWithDB())
 
 	if te.twopcEnabled && te.state == AcceptingReadAndWrite {
+		te.twoPC.Open(te.env.Config().DB)
 		// If there are errors, we choose to raise an alert and
 		// continue anyway. Serving traffic is considered more important
 		// than blocking everything for the sake of a few transactions.
-		if err := te.twoPC.Open(te.env.Config().DB); err != nil {
-			te.env.Stats().InternalErrors.Add("TwopcOpen", 1)
-			log.Errorf("Could not open TwoPC engine: %v", err)
-		}
-		if err := te.prepareFromRedo(); err != nil {
+		// A canceled read is retried by the next open instead: the
+		// transactions it missed are still in the redo log.
+		if err := te.prepareFromRedo(ctx); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return vterrors.Wrap(ctxErr, "could not prepare transactions")
+			}
 			te.env.Stats().InternalErrors.Add("TwopcResurrection", 1)
 			log.Errorf("Could not prepare transactions: %v", err)
 		}
 		te.startWatchdog()
 	}
+	return nil
+}
+
+// drain waits up to drainGracePeriod for the open transactions to
+// complete. It must be called while Transitioning, which prevents
+// new transactions from being started.
+func (te *TxEngine) drain() {
+	te.draining.Set(true)
+	defer te.draining.Set(false)
+
+	log.Infof("TxEngine: draining %d transactions and reserved connections, of which %d reserved, for up to %v", te.txPool.scp.active.Size(), len(te.ReservedConnections()), te.drainGracePeriod)
+	poolEmpty := make(chan struct{})
+	go func() {
+		// This returns once the remaining transactions
+		// have been rolled back by shutdown.
+		te.txPool.WaitForEmpty()
+		close(poolEmpty)
+	}()
+	tmr := time.NewTimer(te.drainGracePeriod)
+	defer tmr.Stop()
+	select {
+	case <-poolEmpty:
+		log.Info("TxEngine: transactions drained")
+	case <-tmr.C:
+		log.Infof("TxEngine: drain period exceeded, rolling back %d transactions and reserved connections, of which %d reserved", te.txPool.scp.active.Size(), len(te.ReservedConnections()))
+	}
+}
+
+// forcedReleaseInterval is for tests.
+var forcedReleaseInterval = 100 * time.Millisecond
+
+// startForcedRelease releases the reserved connections reserved before
+// the drain deadline as soon as they're not in use anymore, until the
+// returned function is called.
+func (te *TxEngine) startForcedRelease(deadline time.Time) (stop func()) {
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		defer te.env.LogError()
+		tkr := time.NewTicker(forcedReleaseInterval)
+		defer tkr.Stop()
+		for {
+			if released := te.txPool.ForceReleaseReserved(deadline); released != 0 {
+				log.Warningf("TxEngine: released %d reserved connections held past the drain deadline", released)
+			}
+			select {
+			case <-done:
+				return
+			case <-tkr.C:
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
+}
+
+// Draining returns the number of open transactions if a transition
+// is waiting for them to complete.
+func (te *TxEngine) Draining() (remaining int64, ok bool) {
+	if !te.draining.Get() {
+		return 0, false
+	}
+	return te.txPool.scp.active.Size(), true
+}
+
+// OpenTransaction describes a transaction that has not completed yet.
+type OpenTransaction struct {
+	TransactionID   int64
+	StartTime       time.Time
+	LastQuery       string
+	EffectiveCaller string
+	TabletType      string
+}
+
+// OpenTransactions returns the open transactions, oldest first.
+// The queries are redacted if redact-debug-ui-queries is set.
+func (te *TxEngine) OpenTransactions() []OpenTransaction {
+	conns := mapToTxConn(te.txPool.scp.active.GetAll())
+	txs := make([]OpenTransaction, 0, len(conns))
+	for _, conn := range conns {
+		props := conn.txProps
+		if props == nil {
+			// A reserved connection without a transaction.
+			continue
+		}
+		var lastQuery string
+		if len(props.Queries) != 0 {
+			lastQuery = props.Queries[len(props.Queries)-1]
+			if *streamlog.RedactDebugUIQueries {
+				lastQuery, _ = sqlparser.RedactSQLQuery(lastQuery)
+			}
+		}
+		txs = append(txs, OpenTransaction{
+			TransactionID:   conn.ConnID,
+			StartTime:       props.StartTime,
+			LastQuery:       sqlparser.TruncateForUI(lastQuery),
+			EffectiveCaller: callerid.GetPrincipal(props.EffectiveCaller),
+			TabletType:      props.TabletType.String(),
+		})
+	}
+	sort.Slice(txs, func(i, j int) bool {
+		return txs[i].StartTime.Before(txs[j].StartTime)
+	})
+	return txs
+}
+
+// ReservedConnection describes a reserved connection.
+type ReservedConnection struct {
+	ConnID          int64
+	StartTime       time.Time
+	EffectiveCaller string
+	ImmediateCaller string
+	InTransaction   bool
+}
+
+// ReservedConnections returns the reserved connections, oldest first.
+func (te *TxEngine) ReservedConnections() []ReservedConnection {
+	conns := mapToTxConn(te.txPool.scp.active.GetAll())
+	reserved := make([]ReservedConnection, 0, len(conns))
+	for _, conn := range conns {
+		props := conn.reservedProps
+		if props == nil {
+			continue
+		}
+		reserved = append(reserved, ReservedConnection{
+			ConnID:          conn.ConnID,
+			StartTime:       props.StartTime,
+			EffectiveCaller: callerid.GetPrincipal(props.EffectiveCaller),
+			ImmediateCaller: callerid.GetUsername(props.ImmediateCaller),
+			InTransaction:   conn.IsInTransaction(),
+		})
+	}
+	sort.Slice(reserved, func(i, j int) bool {
+		return reserved[i].StartTime.Before(reserved[j].StartTime)
+	})
+	return reserved
 }
 
 // Close will disregard common rules for when to kill transactions
@@ -399,13 +627,14 @@ func (te *TxEngine) shutdown(immediate bool) {
 			te.rollbackTransactions()
 			return
 		}
-		if te.shutdownGracePeriod <= 0 {
+		shutdownGracePeriod := te.live.ShutdownGracePeriod()
+		if shutdownGracePeriod <= 0 {
 			// No grace period was specified. Never rollback.
 			te.rollbackPrepared()
 			log.Info("No grace period specified: performing normal wait.")
 			return
 		}
-		tmr := time.NewTimer(te.shutdownGracePeriod)
+		tmr := time.NewTimer(shutdownGracePeriod)
 		defer tmr.Stop()
 		select {
 		case <-tmr.C:
@@ -424,14 +653,16 @@ func (te *TxEngine) shutdown(immediate bool) {
 
 	te.txPool.Close()
 	te.twoPC.Close()
+	// All the transactions are done: the usage of their callers
+	// starts over at the next open.
+	te.txPool.callerLimiter.Reset()
 }
 
 // prepareFromRedo replays and prepares the transactions
 // from the redo log, loads previously failed transactions
 // into the reserved list, and adjusts the txPool LastID
 // to ensure there are no future collisions.
-func (te *TxEngine) prepareFromRedo() error {
-	ctx := tabletenv.LocalContext()
+func (te *TxEngine) prepareFromRedo(ctx context.Context) error {
 	var allErr concurrency.AllErrorRecorder
 	prepared, failed, err := te.twoPC.ReadAllRedo(ctx)
 	if err != nil {
@@ -522,6 +753,7 @@ func (te *TxEngine) startWatchdog() {
 			log.Errorf("Error reading unresolved prepares: '%v': %v", te.coordinatorAddress, err)
 		}
 		te.env.Stats().Unresolved.Set("Prepares", count)
+		te.refreshUnresolved(ctx)
 
 		// Resolve lingering distributed transactions.
 		txs, err := te.twoPC.ReadAbandoned(ctx, time.Now().Add(-te.abandonAge))
@@ -550,7 +782,9 @@ func (te *TxEngine) startWatchdog() {
 				if err := coordConn.ResolveTransaction(ctx, dtid); err != nil {
 					te.env.Stats().InternalErrors.Add("WatchdogFail", 1)
 					log.Errorf("Error notifying for dtid %s: %v", dtid, err)
+					return
 				}
+				te.resolutions.Add(resolvedAuto, 1)
 			}(tx)
 		}
 		wg.Wait()
@@ -560,6 +794,106 @@ func (te *TxEngine) startWatchdog() {
 // stopWatchdog stops the watchdog goroutine.
 func (te *TxEngine) stopWatchdog() {
 	te.ticks.Stop()
+	// Stop waits for the running tick, if any: the stats
+	// can't be refreshed again until the next start.
+	te.unresolvedMu.Lock()
+	te.unresolved = unresolvedPrepares{}
+	te.unresolvedMu.Unlock()
+}
+
+// unresolvedPrepares describes the transactions of the
+// redo log that are not resolved yet.
+type unresolvedPrepares struct {
+	count  int64
+	oldest time.Time
+}
+
+const (
+	resolvedAuto   = "Auto"
+	resolvedManual = "Manual"
+)
+
+// refreshUnresolved reads the stats of the redo log. They're kept
+// as they were if the read fails.
+func (te *TxEngine) refreshUnresolved(ctx context.Context) {
+	count, oldest, err := te.twoPC.UnresolvedRedoStats(ctx)
+	if err != nil {
+		te.env.Stats().InternalErrors.Add("WatchdogFail", 1)
+		log.Errorf("Error reading the unresolved prepares stats: %v", err)
+		return
+	}
+	te.unresolvedMu.Lock()
+	defer te.unresolvedMu.Unlock()
+	te.unresolved = unresolvedPrepares{count: count, oldest: oldest}
+}
+
+// UnresolvedPrepares returns the number of transactions of the redo
+// log that are not resolved yet, and the age of the oldest one. It
+// returns an error once that age exceeds five times the abandon age,
+// which gives the watchdog time to resolve them. They're only reported
+// while the TxEngine accepts writes with 2pc enabled.
+func (te *TxEngine) UnresolvedPrepares() (count int64, maxAge time.Duration, err error) {
+	te.unresolvedMu.Lock()
+	unresolved := te.unresolved
+	te.unresolvedMu.Unlock()
+
+	if unresolved.count == 0 {
+		return 0, 0, nil
+	}
+	maxAge = time.Since(unresolved.oldest)
+	if maxAge > te.abandonAge*5 {
+		err = fmt.Errorf("%d unresolved prepared transactions, the oldest for %v", unresolved.count, maxAge.Truncate(time.Second))
+	}
+	return unresolved.count, maxAge, err
+}
+
+// UnresolvedPrepare describes a transaction of the redo log
+// that is not resolved yet.
+type UnresolvedPrepare struct {
+	Dtid    string
+	State   string
+	Created time.Time
+	Age     string
+	Queries []string
+}
+
+// ListUnresolvedPrepares returns the transactions of the redo log,
+// oldest first. The queries are redacted if redact-debug-ui-queries
+// is set.
+func (te *TxEngine) ListUnresolvedPrepares(ctx context.Context) ([]UnresolvedPrepare, error) {
+	if !te.twopcEnabled {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "2pc is not enabled")
+	}
+	prepared, failed, err := te.twoPC.ReadAllRedo(ctx)
+	if err != nil {
+		return nil, vterrors.Errorf(vtrpc.Code_UNKNOWN, "could not read redo: %v", err)
+	}
+	now := time.Now()
+	list := make([]UnresolvedPrepare, 0, len(prepared)+len(failed))
+	add := func(txs []*tx.PreparedTx, state string) {
+		for _, ptx := range txs {
+			queries := make([]string, 0, len(ptx.Queries))
+			for _, query := range ptx.Queries {
+				if *streamlog.RedactDebugUIQueries {
+					query, _ = sqlparser.RedactSQLQuery(query)
+				}
+				queries = append(queries, sqlparser.TruncateForUI(query))
+			}
+			list = append(list, UnresolvedPrepare{
+				Dtid:    ptx.Dtid,
+				State:   state,
+				Created: ptx.Time,
+				Age:     now.Sub(ptx.Time).Truncate(time.Second).String(),
+				Queries: queries,
+			})
+		}
+	}
+	add(prepared, "PREPARED")
+	add(failed, "FAILED")
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Created.Before(list[j].Created)
+	})
+	return list, nil
 }
 
 //ReserveBegin creates a reserved connection, and in it opens a transaction
@@ -571,12 +905,16 @@ func (te *TxEngine) ReserveBegin(ctx context.Context, options *querypb.ExecuteOp
 		return 0, vterrors.Wrap(err, "TxEngine.ReserveBegin")
 	}
 	defer conn.UnlockUpdateTime()
-	_, err = te.txPool.begin(ctx, options, te.state == AcceptingReadOnly, conn, nil)
+	te.stateLock.Lock()
+	readOnly := te.readOnlyLocked(options)
+	te.stateLock.Unlock()
+	_, err = te.txPool.begin(ctx, options, readOnly, conn, nil)
 	if err != nil {
 		conn.Close()
 		conn.Release(tx.ConnInitFail)
 		return 0, err
 	}
+	te.accessModes.Add(accessMode(readOnly), 1)
 	return conn.ID(), nil
 }
 
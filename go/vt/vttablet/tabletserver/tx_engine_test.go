@@ -32,11 +32,16 @@ import (
 
 	"vitess.io/vitess/go/mysql/fakesqldb"
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/streamlog"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/vterrors"
 
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 
 	"golang.org/x/net/context"
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
 func TestTxEngineClose(t *testing.T) {
@@ -51,13 +56,13 @@ func TestTxEngineClose(t *testing.T) {
 	te := NewTxEngine(tabletenv.NewEnv(config, "TabletServerTest"))
 
 	// Normal close.
-	te.open()
+	require.NoError(t, te.open(context.Background()))
 	start := time.Now()
 	te.shutdown(false)
 	assert.Greater(t, int64(50*time.Millisecond), int64(time.Since(start)))
 
 	// Normal close with timeout wait.
-	te.open()
+	require.NoError(t, te.open(context.Background()))
 	c, beginSQL, err := te.txPool.Begin(ctx, &querypb.ExecuteOptions{}, false, 0, nil)
 	require.NoError(t, err)
 	require.Equal(t, "begin", beginSQL)
@@ -73,7 +78,7 @@ func TestTxEngineClose(t *testing.T) {
 	te.txPool.env.Stats().KillCounters.ResetAll()
 
 	// Immediate close.
-	te.open()
+	require.NoError(t, te.open(context.Background()))
 	c, _, err = te.txPool.Begin(ctx, &querypb.ExecuteOptions{}, false, 0, nil)
 	if err != nil {
 		t.Fatal(err)
@@ -84,8 +89,8 @@ func TestTxEngineClose(t *testing.T) {
 	assert.Greater(t, int64(50*time.Millisecond), int64(time.Since(start)))
 
 	// Normal close with short grace period.
-	te.shutdownGracePeriod = 25 * time.Millisecond
-	te.open()
+	te.live.values.ShutdownGracePeriod = 25 * time.Millisecond
+	require.NoError(t, te.open(context.Background()))
 	c, _, err = te.txPool.Begin(ctx, &querypb.ExecuteOptions{}, false, 0, nil)
 	require.NoError(t, err)
 	c.Unlock()
@@ -95,8 +100,8 @@ func TestTxEngineClose(t *testing.T) {
 	assert.Greater(t, int64(50*time.Millisecond), int64(time.Since(start)))
 
 	// Normal close with short grace period, but pool gets empty early.
-	te.shutdownGracePeriod = 25 * time.Millisecond
-	te.open()
+	te.live.values.ShutdownGracePeriod = 25 * time.Millisecond
+	require.NoError(t, te.open(context.Background()))
 	c, _, err = te.txPool.Begin(ctx, &querypb.ExecuteOptions{}, false, 0, nil)
 	require.NoError(t, err)
 	c.Unlock()
@@ -112,7 +117,7 @@ func TestTxEngineClose(t *testing.T) {
 	assert.Greater(t, int64(25*time.Millisecond), int64(time.Since(start)))
 
 	// Immediate close, but connection is in use.
-	te.open()
+	require.NoError(t, te.open(context.Background()))
 	c, _, err = te.txPool.Begin(ctx, &querypb.ExecuteOptions{}, false, 0, nil)
 	require.NoError(t, err)
 	go func() {
@@ -129,9 +134,9 @@ func TestTxEngineClose(t *testing.T) {
 	}
 
 	// Normal close with Reserved connection timeout wait.
-	te.shutdownGracePeriod = 0 * time.Millisecond
-	te.open()
-	te.AcceptReadWrite()
+	te.live.values.ShutdownGracePeriod = 0 * time.Millisecond
+	require.NoError(t, te.open(context.Background()))
+	te.AcceptReadWrite(context.Background())
 	_, err = te.Reserve(ctx, &querypb.ExecuteOptions{}, 0, nil)
 	require.NoError(t, err)
 	_, err = te.ReserveBegin(ctx, &querypb.ExecuteOptions{}, nil)
@@ -144,6 +149,198 @@ func TestTxEngineClose(t *testing.T) {
 
 }
 
+func TestTxEngineDrain(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	ctx := context.Background()
+	config := tabletenv.NewDefaultConfig()
+	config.DB = newDBConfigs(db)
+	config.GracePeriods.TransactionDrainSeconds = 5
+	te := NewTxEngine(tabletenv.NewEnv(config, "TabletServerTest"))
+	defer te.Close()
+
+	waitForDrain := func() int64 {
+		t.Helper()
+		for i := 0; i < 500; i++ {
+			if remaining, ok := te.Draining(); ok {
+				return remaining
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatal("tx engine did not start draining")
+		return 0
+	}
+
+	// Transactions are allowed to complete.
+	require.NoError(t, te.AcceptReadWrite(context.Background()))
+	txid, _, err := te.Begin(ctx, nil, 0, &querypb.ExecuteOptions{})
+	require.NoError(t, err)
+	done := make(chan error)
+	go func() {
+		done <- te.AcceptReadOnly()
+	}()
+	assert.EqualValues(t, 1, waitForDrain())
+	_, _, err = te.Begin(ctx, nil, 0, &querypb.ExecuteOptions{})
+	assert.Contains(t, err.Error(), "tx engine can't accept new transactions in state Transitioning")
+	_, _, err = te.Commit(ctx, txid)
+	require.NoError(t, err)
+	require.NoError(t, <-done)
+	_, ok := te.Draining()
+	assert.False(t, ok)
+	assert.Equal(t, AcceptingReadOnly, te.state)
+
+	// The remaining ones are rolled back after the grace period.
+	te.drainGracePeriod = 10 * time.Millisecond
+	require.NoError(t, te.AcceptReadWrite(context.Background()))
+	txid, _, err = te.Begin(ctx, nil, 0, &querypb.ExecuteOptions{})
+	require.NoError(t, err)
+	require.NoError(t, te.AcceptReadOnly())
+	_, _, err = te.Commit(ctx, txid)
+	assert.Error(t, err)
+	assert.Equal(t, AcceptingReadOnly, te.state)
+
+	// No draining without a grace period.
+	te.drainGracePeriod = 0
+	require.NoError(t, te.AcceptReadWrite(context.Background()))
+	txid, _, err = te.Begin(ctx, nil, 0, &querypb.ExecuteOptions{})
+	require.NoError(t, err)
+	start := time.Now()
+	require.NoError(t, te.AcceptReadOnly())
+	assert.Greater(t, int64(50*time.Millisecond), int64(time.Since(start)))
+	_, _, err = te.Commit(ctx, txid)
+	assert.Error(t, err)
+}
+
+func TestTxEngineOpenTransactions(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	db.AddQueryPattern(".*", &sqltypes.Result{})
+	config := tabletenv.NewDefaultConfig()
+	config.DB = newDBConfigs(db)
+	te := NewTxEngine(tabletenv.NewEnv(config, "TabletServerTest"))
+	te.txPool.tabletType = func() topodatapb.TabletType { return topodatapb.TabletType_MASTER }
+	require.NoError(t, te.AcceptReadWrite(context.Background()))
+	defer te.Close()
+
+	ctx := callerid.NewContext(context.Background(), callerid.NewEffectiveCallerID("principal", "component", "subcomponent"), nil)
+	tx1, _, err := te.Begin(ctx, nil, 0, &querypb.ExecuteOptions{})
+	require.NoError(t, err)
+	conn, err := te.txPool.GetAndLock(tx1, "for test")
+	require.NoError(t, err)
+	conn.TxProperties().RecordQuery("update t set a = 1 where id = 2")
+	conn.Unlock()
+	time.Sleep(time.Millisecond)
+	tx2, _, err := te.Begin(context.Background(), nil, 0, &querypb.ExecuteOptions{})
+	require.NoError(t, err)
+	rid, err := te.Reserve(context.Background(), &querypb.ExecuteOptions{}, 0, nil)
+	require.NoError(t, err)
+	defer func() {
+		_, _ = te.Rollback(ctx, tx1)
+		_, _ = te.Rollback(ctx, tx2)
+		_ = te.Release(rid)
+	}()
+
+	// Oldest first, and reserved connections are skipped.
+	txs := te.OpenTransactions()
+	require.Len(t, txs, 2)
+	assert.Equal(t, tx1, txs[0].TransactionID)
+	assert.Equal(t, "update t set a = 1 where id = 2", txs[0].LastQuery)
+	assert.Equal(t, "principal", txs[0].EffectiveCaller)
+	assert.Equal(t, "MASTER", txs[0].TabletType)
+	assert.Equal(t, tx2, txs[1].TransactionID)
+	assert.Equal(t, "", txs[1].LastQuery)
+	assert.Equal(t, "", txs[1].EffectiveCaller)
+	assert.False(t, txs[1].StartTime.Before(txs[0].StartTime))
+
+	defer func(saved bool) { *streamlog.RedactDebugUIQueries = saved }(*streamlog.RedactDebugUIQueries)
+	*streamlog.RedactDebugUIQueries = true
+	txs = te.OpenTransactions()
+	require.Len(t, txs, 2)
+	assert.Equal(t, "update t set a = :redacted1 where id = :redacted2", txs[0].LastQuery)
+}
+
+func TestTxEngineReservedConnections(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	db.AddQueryPattern(".*", &sqltypes.Result{})
+	config := tabletenv.NewDefaultConfig()
+	config.DB = newDBConfigs(db)
+	te := NewTxEngine(tabletenv.NewEnv(config, "TabletServerTest"))
+	require.NoError(t, te.AcceptReadWrite(context.Background()))
+	defer te.Close()
+
+	ctx := callerid.NewContext(context.Background(), callerid.NewEffectiveCallerID("principal", "component", "subcomponent"), callerid.NewImmediateCallerID("user"))
+	rid1, err := te.Reserve(ctx, &querypb.ExecuteOptions{}, 0, nil)
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	rid2, err := te.ReserveBegin(context.Background(), &querypb.ExecuteOptions{}, nil)
+	require.NoError(t, err)
+	txid, _, err := te.Begin(ctx, nil, 0, &querypb.ExecuteOptions{})
+	require.NoError(t, err)
+	defer func() {
+		_, _ = te.Rollback(ctx, txid)
+		_ = te.Release(rid1)
+		_ = te.Release(rid2)
+	}()
+
+	// Oldest first, and the transactions that aren't reserved are skipped.
+	reserved := te.ReservedConnections()
+	require.Len(t, reserved, 2)
+	assert.Equal(t, rid1, reserved[0].ConnID)
+	assert.Equal(t, "principal", reserved[0].EffectiveCaller)
+	assert.Equal(t, "user", reserved[0].ImmediateCaller)
+	assert.False(t, reserved[0].InTransaction)
+	assert.Equal(t, rid2, reserved[1].ConnID)
+	assert.True(t, reserved[1].InTransaction)
+}
+
+func TestTxEngineForcedReservedRelease(t *testing.T) {
+	defer func(saved time.Duration) { forcedReleaseInterval = saved }(forcedReleaseInterval)
+	forcedReleaseInterval = 10 * time.Millisecond
+
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	db.AddQueryPattern(".*", &sqltypes.Result{})
+	config := tabletenv.NewDefaultConfig()
+	config.DB = newDBConfigs(db)
+	te := NewTxEngine(tabletenv.NewEnv(config, "TabletServerTest"))
+	te.drainGracePeriod = 10 * time.Millisecond
+	require.NoError(t, te.AcceptReadWrite(context.Background()))
+	defer te.Close()
+	released := te.env.Stats().KillCounters.Counts()["ReservedConnectionRelease"]
+
+	// The connection is in use, as if it was running a query,
+	// so the shutdown can't release it.
+	rid, err := te.Reserve(ctx, &querypb.ExecuteOptions{}, 0, nil)
+	require.NoError(t, err)
+	conn, err := te.txPool.GetAndLock(rid, "for query")
+	require.NoError(t, err)
+	done := make(chan error)
+	go func() {
+		done <- te.AcceptReadOnly()
+	}()
+	select {
+	case <-done:
+		t.Fatal("the transition completed while the reserved connection was in use")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// The transition completes once the connection is released,
+	// and the client gets a retryable error.
+	conn.Unlock()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the reserved connection wasn't released")
+	}
+	assert.Equal(t, AcceptingReadOnly, te.state)
+	assert.Equal(t, released+1, te.env.Stats().KillCounters.Counts()["ReservedConnectionRelease"])
+	_, err = te.txPool.GetAndLock(rid, "for query")
+	assert.Equal(t, vtrpcpb.Code_UNAVAILABLE, vterrors.Code(err))
+	assert.EqualError(t, err, fmt.Sprintf("reserved connection %d was released by a master demotion: retry with a new connection", rid))
+}
+
 func TestTxEngineBegin(t *testing.T) {
 	db := setUpQueryExecutorTest(t)
 	defer db.Close()
@@ -159,7 +356,7 @@ func TestTxEngineBegin(t *testing.T) {
 	require.Equal(t, "start transaction read only;commit", db.QueryLog())
 	db.ResetQueryLog()
 
-	te.AcceptReadWrite()
+	te.AcceptReadWrite(context.Background())
 	tx2, _, err := te.Begin(ctx, nil, 0, &querypb.ExecuteOptions{})
 	require.NoError(t, err)
 	_, _, err = te.Commit(ctx, tx2)
@@ -167,6 +364,110 @@ func TestTxEngineBegin(t *testing.T) {
 	require.Equal(t, "begin;commit", db.QueryLog())
 }
 
+func TestTxEngineReadOnlyTransactions(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	db.AddQueryPattern(".*", &sqltypes.Result{})
+	config := tabletenv.NewDefaultConfig()
+	config.DB = newDBConfigs(db)
+	config.ReadOnlyTransactionCap = 1
+	te := NewTxEngine(tabletenv.NewEnv(config, "TabletServerTest"))
+	defer te.Close()
+	modes := te.accessModes.Counts()
+	readOnly := &querypb.ExecuteOptions{TransactionIsolation: querypb.ExecuteOptions_CONSISTENT_SNAPSHOT_READ_ONLY}
+
+	// All the transactions of a replica are read-only, and capped.
+	te.AcceptReadOnly()
+	tx1, _, err := te.Begin(ctx, nil, 0, &querypb.ExecuteOptions{})
+	require.NoError(t, err)
+	conn, err := te.txPool.GetAndLock(tx1, "test")
+	require.NoError(t, err)
+	assert.True(t, conn.TxProperties().ReadOnly)
+	conn.Unlock()
+	_, _, err = te.Begin(ctx, nil, 0, readOnly)
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	assert.EqualError(t, err, "read-only transaction limit exceeded")
+	_, err = te.Rollback(ctx, tx1)
+	require.NoError(t, err)
+	tx1, _, err = te.Begin(ctx, nil, 0, readOnly)
+	require.NoError(t, err)
+	_, err = te.Rollback(ctx, tx1)
+	require.NoError(t, err)
+
+	// A master only caps the transactions that ask to be read-only.
+	te.AcceptReadWrite(context.Background())
+	tx1, _, err = te.Begin(ctx, nil, 0, readOnly)
+	require.NoError(t, err)
+	_, _, err = te.Begin(ctx, nil, 0, readOnly)
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	tx2, _, err := te.Begin(ctx, nil, 0, &querypb.ExecuteOptions{})
+	require.NoError(t, err)
+	conn, err = te.txPool.GetAndLock(tx2, "test")
+	require.NoError(t, err)
+	assert.False(t, conn.TxProperties().ReadOnly)
+	conn.Unlock()
+	_, err = te.Rollback(ctx, tx1)
+	require.NoError(t, err)
+	_, err = te.Rollback(ctx, tx2)
+	require.NoError(t, err)
+
+	got := te.accessModes.Counts()
+	assert.EqualValues(t, 3, got["ReadOnly"]-modes["ReadOnly"])
+	assert.EqualValues(t, 1, got["ReadWrite"]-modes["ReadWrite"])
+}
+
+func TestTxEngineCallerCaps(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	db.AddQueryPattern(".*", &sqltypes.Result{})
+	config := tabletenv.NewDefaultConfig()
+	config.DB = newDBConfigs(db)
+	config.TransactionCapsByCaller = map[string]int{"batch": 1}
+	te := NewTxEngine(tabletenv.NewEnv(config, "TabletServerTest"))
+	te.AcceptReadWrite(context.Background())
+	defer te.Close()
+
+	batchCtx := callerid.NewContext(context.Background(), callerid.NewEffectiveCallerID("batch", "", ""), callerid.NewImmediateCallerID("user"))
+	webCtx := callerid.NewContext(context.Background(), callerid.NewEffectiveCallerID("web", "", ""), callerid.NewImmediateCallerID("user"))
+	tx1, _, err := te.Begin(batchCtx, nil, 0, &querypb.ExecuteOptions{})
+	require.NoError(t, err)
+	_, _, err = te.Begin(batchCtx, nil, 0, &querypb.ExecuteOptions{})
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	assert.EqualError(t, err, "transaction limit exceeded for caller batch: 1 concurrent transactions")
+	tx2, _, err := te.Begin(webCtx, nil, 0, &querypb.ExecuteOptions{})
+	require.NoError(t, err)
+	_, err = te.Rollback(webCtx, tx2)
+	require.NoError(t, err)
+
+	// The cap is released once the transaction is done.
+	_, err = te.Rollback(batchCtx, tx1)
+	require.NoError(t, err)
+	tx1, _, err = te.Begin(batchCtx, nil, 0, &querypb.ExecuteOptions{})
+	require.NoError(t, err)
+
+	// A transition rolls back the transactions, and the usage of
+	// their callers starts over.
+	te.AcceptReadOnly()
+	te.AcceptReadWrite(context.Background())
+	assert.Zero(t, te.txPool.callerLimiter.Usage("batch"))
+	_, err = te.Rollback(batchCtx, tx1)
+	assert.Error(t, err)
+	tx1, _, err = te.Begin(batchCtx, nil, 0, &querypb.ExecuteOptions{})
+	require.NoError(t, err)
+
+	// The caps can be reloaded.
+	values := te.live.Values()
+	values.TransactionCapsByCaller = map[string]int64{"batch": 2}
+	require.NoError(t, te.live.Reload("test", values))
+	tx2, _, err = te.Begin(batchCtx, nil, 0, &querypb.ExecuteOptions{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, te.txPool.callerLimiter.Usage("batch"))
+	for _, tx := range []int64{tx1, tx2} {
+		_, err = te.Rollback(batchCtx, tx)
+		require.NoError(t, err)
+	}
+}
+
 func TestTxEngineRenewFails(t *testing.T) {
 	db := setUpQueryExecutorTest(t)
 	defer db.Close()
@@ -248,7 +549,7 @@ func (test TestCase) String() string {
 func changeState(te *TxEngine, state txEngineState) error {
 	switch state {
 	case AcceptingReadAndWrite:
-		return te.AcceptReadWrite()
+		return te.AcceptReadWrite(context.Background())
 	case AcceptingReadOnly:
 		return te.AcceptReadOnly()
 	case NotServing:
@@ -171,6 +171,23 @@ func (txe *TxExecutor) RollbackPrepared(dtid string, originalID int64) error {
 	})
 }
 
+// ResolvePrepared commits or rolls back a prepared transaction by
+// hand, e.g. one that the watchdog couldn't resolve.
+func (txe *TxExecutor) ResolvePrepared(dtid string, commit bool) error {
+	var err error
+	if commit {
+		err = txe.CommitPrepared(dtid)
+	} else {
+		err = txe.RollbackPrepared(dtid, 0)
+	}
+	if err != nil {
+		return err
+	}
+	log.Infof("Prepared transaction %s resolved by hand, commit: %v", dtid, commit)
+	txe.te.resolutions.Add(resolvedManual, 1)
+	return nil
+}
+
 // CreateTransaction creates the metadata for a 2PC transaction.
 func (txe *TxExecutor) CreateTransaction(dtid string, participants []*querypb.Target) error {
 	if !txe.te.twopcEnabled {
@@ -19,6 +19,8 @@ package tabletserver
 import (
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 	"time"
@@ -26,6 +28,7 @@ import (
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tx"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
 
@@ -442,6 +445,81 @@ func TestExecutorResolveTransaction(t *testing.T) {
 	}
 }
 
+func TestExecutorUnresolvedPrepares(t *testing.T) {
+	_, tsv, db := newShortAgeExecutor(t)
+	defer db.Close()
+	defer tsv.StopService()
+	oldest := time.Now().Add(-time.Hour)
+	db.AddQuery("select count(*), min(time_created) from _vt.redo_state", sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("count(*)|min(time_created)", "int64|int64"),
+		fmt.Sprintf("2|%d", oldest.UnixNano()),
+	))
+
+	var count int64
+	var maxAge time.Duration
+	var err error
+	for start := time.Now(); count == 0; time.Sleep(10 * time.Millisecond) {
+		require.Less(t, int64(time.Since(start)), int64(10*time.Second), "the watchdog didn't report the unresolved prepares")
+		count, maxAge, err = tsv.te.UnresolvedPrepares()
+	}
+	assert.EqualValues(t, 2, count)
+	assert.GreaterOrEqual(t, int64(maxAge), int64(time.Hour))
+	assert.EqualError(t, err, "2 unresolved prepared transactions, the oldest for 1h0m0s")
+
+	tsv.sm.Broadcast()
+	tsv.hs.mu.Lock()
+	assert.EqualValues(t, 2, tsv.hs.state.RealtimeStats.UnresolvedPrepares)
+	assert.EqualValues(t, 3600, tsv.hs.state.RealtimeStats.UnresolvedPreparesMaxAgeSeconds)
+	assert.Equal(t, "2 unresolved prepared transactions, the oldest for 1h0m0s", tsv.hs.state.RealtimeStats.HealthError)
+	tsv.hs.mu.Unlock()
+
+	// They're not reported once the tablet isn't a master anymore.
+	err = tsv.SetServingType(ctx, topodatapb.TabletType_REPLICA, time.Time{}, true, "")
+	require.NoError(t, err)
+	count, _, err = tsv.te.UnresolvedPrepares()
+	assert.Zero(t, count)
+	assert.NoError(t, err)
+	tsv.sm.Broadcast()
+	tsv.hs.mu.Lock()
+	assert.Zero(t, tsv.hs.state.RealtimeStats.UnresolvedPrepares)
+	assert.Empty(t, tsv.hs.state.RealtimeStats.HealthError)
+	tsv.hs.mu.Unlock()
+}
+
+func TestExecutorResolvePrepared(t *testing.T) {
+	txe, tsv, db := newTestTxExecutor(t)
+	defer db.Close()
+	defer tsv.StopService()
+	manual := tsv.te.resolutions.Counts()[resolvedManual]
+
+	err := tsv.ResolvePrepared("aa", "conclude")
+	assert.EqualError(t, err, `cannot resolve prepared transaction aa: invalid action "conclude", must be commit or rollback`)
+	err = tsv.ResolvePrepared("", "commit")
+	assert.Error(t, err)
+
+	txid := newTxForPrep(tsv)
+	require.NoError(t, txe.Prepare(txid, "aa"))
+	created := time.Now().Add(-time.Minute).UnixNano()
+	db.AddQuery(tsv.te.twoPC.readAllRedo, sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("dtid|state|time_created|statement", "varchar|int64|int64|varchar"),
+		fmt.Sprintf("aa|%d|%d|update test_table set name = 2 where pk = 1", RedoStatePrepared, created),
+	))
+	list, err := tsv.te.ListUnresolvedPrepares(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "aa", list[0].Dtid)
+	assert.Equal(t, "PREPARED", list[0].State)
+	assert.Equal(t, "1m0s", list[0].Age)
+	assert.Equal(t, []string{"update test_table set name = 2 where pk = 1"}, list[0].Queries)
+
+	request := httptest.NewRequest("POST", tsv.exporter.URLPrefix()+"/debug/twopc/resolve?dtid=aa&action=commit", nil)
+	response := httptest.NewRecorder()
+	http.DefaultServeMux.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, manual+1, tsv.te.resolutions.Counts()[resolvedManual])
+	assert.Nil(t, tsv.te.preparedPool.conns["aa"])
+}
+
 func TestNoTwopc(t *testing.T) {
 	txe, tsv, db := newNoTwopcExecutor(t)
 	defer db.Close()
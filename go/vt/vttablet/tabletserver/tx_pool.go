@@ -17,9 +17,11 @@ limitations under the License.
 package tabletserver
 
 import (
+	"strconv"
 	"sync"
 	"time"
 
+	"vitess.io/vitess/go/cache"
 	"vitess.io/vitess/go/pools"
 
 	"vitess.io/vitess/go/vt/servenv"
@@ -39,6 +41,7 @@ import (
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/txlimiter"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
@@ -63,10 +66,25 @@ type (
 		transactionTimeout sync2.AtomicDuration
 		ticks              *timer.Timer
 		limiter            txlimiter.TxLimiter
+		callerLimiter      *txlimiter.CallerLimiter
+		// forceReleased remembers the reserved connections released
+		// by ForceReleaseReserved, so that their clients get a
+		// retryable error instead of a not found one.
+		forceReleased *cache.LRUCache
 
 		logMu   sync.Mutex
 		lastLog time.Time
 		txStats *servenv.TimingsWrapper
+
+		// tabletType returns the current tablet type,
+		// which is recorded when a transaction starts.
+		tabletType func() topodatapb.TabletType
+
+		// readOnlyCap caps the open read-only transactions, if set.
+		// readOnlyPending counts the ones that are being begun.
+		readOnlyCap     int
+		readOnlyMu      sync.Mutex
+		readOnlyPending int
 	}
 	queries struct {
 		setIsolationLevel string
@@ -84,7 +102,10 @@ func NewTxPool(env tabletenv.Env, limiter txlimiter.TxLimiter) *TxPool {
 		transactionTimeout: sync2.NewAtomicDuration(transactionTimeout),
 		ticks:              timer.NewTimer(transactionTimeout / 10),
 		limiter:            limiter,
+		callerLimiter:      txlimiter.NewCallerLimiter(env),
+		forceReleased:      cache.NewLRUCache(1000),
 		txStats:            env.Exporter().NewTimings("Transactions", "Transaction stats", "operation"),
+		readOnlyCap:        config.ReadOnlyTransactionCap,
 	}
 	// Careful: conns also exports name+"xxx" vars,
 	// but we know it doesn't export Timeout.
@@ -140,6 +161,7 @@ func (tp *TxPool) transactionKiller() {
 		if conn.IsTainted() && conn.IsInTransaction() {
 			tp.env.Stats().KillCounters.Add("Transactions", 1)
 		}
+		tp.env.Stats().KillReasons.Add("TransactionTimeout", 1)
 		conn.Releasef("exceeded timeout: %v", tp.Timeout())
 	}
 }
@@ -151,13 +173,17 @@ func (tp *TxPool) WaitForEmpty() {
 
 //NewTxProps creates a new TxProperties struct
 func (tp *TxPool) NewTxProps(immediateCaller *querypb.VTGateCallerID, effectiveCaller *vtrpcpb.CallerID, autocommit bool) *tx.Properties {
-	return &tx.Properties{
+	props := &tx.Properties{
 		StartTime:       time.Now(),
 		EffectiveCaller: effectiveCaller,
 		ImmediateCaller: immediateCaller,
 		Autocommit:      autocommit,
 		Stats:           tp.txStats,
 	}
+	if tp.tabletType != nil {
+		props.TabletType = tp.tabletType()
+	}
+	return props
 }
 
 // GetAndLock fetches the connection associated to the connID and blocks it from concurrent use
@@ -165,11 +191,45 @@ func (tp *TxPool) NewTxProps(immediateCaller *querypb.VTGateCallerID, effectiveC
 func (tp *TxPool) GetAndLock(connID tx.ConnID, reason string) (*StatefulConnection, error) {
 	conn, err := tp.scp.GetAndLock(connID, reason)
 	if err != nil {
+		if _, ok := tp.forceReleased.Get(strconv.FormatInt(connID, 10)); ok {
+			return nil, vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "reserved connection %d was released by a master demotion: retry with a new connection", connID)
+		}
 		return nil, vterrors.Errorf(vtrpcpb.Code_ABORTED, "transaction %d: %v", connID, err)
 	}
 	return conn, nil
 }
 
+// forcedRelease is the value of TxPool.forceReleased.
+type forcedRelease struct{}
+
+func (forcedRelease) Size() int { return 1 }
+
+// ForceReleaseReserved releases the reserved connections that were
+// reserved before reservedBefore, and rolls back their transactions.
+// The connections in use are skipped. It returns the number of
+// connections it released.
+func (tp *TxPool) ForceReleaseReserved(reservedBefore time.Time) int {
+	ctx := tabletenv.LocalContext()
+	released := 0
+	for _, conn := range mapToTxConn(tp.scp.active.GetAll()) {
+		if !conn.IsTainted() || !conn.reservedProps.StartTime.Before(reservedBefore) {
+			continue
+		}
+		if _, err := tp.scp.GetAndLock(conn.ConnID, "for forced release"); err != nil {
+			// The connection is in use, or was released meanwhile.
+			continue
+		}
+		tp.forceReleased.Set(strconv.FormatInt(conn.ConnID, 10), forcedRelease{})
+		if err := tp.Rollback(ctx, conn); err != nil {
+			log.Errorf("Rollback of reserved connection %d failed before its release: %v", conn.ConnID, err)
+		}
+		conn.Releasef("forced release by a master demotion")
+		tp.env.Stats().KillCounters.Add("ReservedConnectionRelease", 1)
+		released++
+	}
+	return released
+}
+
 // Commit commits the transaction on the connection.
 func (tp *TxPool) Commit(ctx context.Context, txConn *StatefulConnection) (string, error) {
 	if !txConn.IsInTransaction() {
@@ -225,25 +285,45 @@ func (tp *TxPool) Begin(ctx context.Context, options *querypb.ExecuteOptions, re
 	span, ctx := trace.NewSpan(ctx, "TxPool.Begin")
 	defer span.Finish()
 
+	if readOnly {
+		if err := tp.reserveReadOnly(); err != nil {
+			return nil, "", err
+		}
+		defer tp.releaseReadOnly()
+	}
+
 	var conn *StatefulConnection
 	var err error
+	immediateCaller := callerid.ImmediateCallerIDFromContext(ctx)
+	effectiveCaller := callerid.EffectiveCallerIDFromContext(ctx)
+	// releaseLimits releases what the limiters reserved for a new
+	// connection if the transaction can't begin.
+	releaseLimits := func() {}
 	if reservedID != 0 {
 		conn, err = tp.scp.GetAndLock(reservedID, "start transaction on reserve conn")
 	} else {
-		immediateCaller := callerid.ImmediateCallerIDFromContext(ctx)
-		effectiveCaller := callerid.EffectiveCallerIDFromContext(ctx)
 		if !tp.limiter.Get(immediateCaller, effectiveCaller) {
 			return nil, "", vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "per-user transaction pool connection limit exceeded")
 		}
+		if err := tp.callerLimiter.Get(immediateCaller, effectiveCaller); err != nil {
+			tp.limiter.Release(immediateCaller, effectiveCaller)
+			return nil, "", err
+		}
+		releaseLimits = func() {
+			tp.limiter.Release(immediateCaller, effectiveCaller)
+			tp.callerLimiter.Release(immediateCaller, effectiveCaller)
+		}
 		conn, err = tp.createConn(ctx, options)
 	}
 	if err != nil {
+		releaseLimits()
 		return nil, "", err
 	}
 	sql, err := tp.begin(ctx, options, readOnly, conn, preQueries)
 	if err != nil {
 		conn.Close()
 		conn.Release(tx.ConnInitFail)
+		releaseLimits()
 		return nil, "", err
 	}
 	return conn, sql, nil
@@ -258,10 +338,45 @@ func (tp *TxPool) begin(ctx context.Context, options *querypb.ExecuteOptions, re
 	}
 
 	conn.txProps = tp.NewTxProps(immediateCaller, effectiveCaller, autocommit)
+	conn.txProps.ReadOnly = readOnly
 
 	return beginQueries, nil
 }
 
+// reserveReadOnly counts a read-only transaction that's about to
+// begin, or fails if readOnlyCap is reached. The open read-only
+// transactions are counted from their properties, so that those
+// killed or closed without a rollback don't need to be released.
+func (tp *TxPool) reserveReadOnly() error {
+	if tp.readOnlyCap == 0 {
+		return nil
+	}
+	tp.readOnlyMu.Lock()
+	defer tp.readOnlyMu.Unlock()
+	open := tp.readOnlyPending
+	tp.scp.ForAllTxProperties(func(props *tx.Properties) {
+		if props.ReadOnly {
+			open++
+		}
+	})
+	if open >= tp.readOnlyCap {
+		return vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "read-only transaction limit exceeded")
+	}
+	tp.readOnlyPending++
+	return nil
+}
+
+// releaseReadOnly is called once a transaction counted by
+// reserveReadOnly has begun, or failed to.
+func (tp *TxPool) releaseReadOnly() {
+	if tp.readOnlyCap == 0 {
+		return
+	}
+	tp.readOnlyMu.Lock()
+	defer tp.readOnlyMu.Unlock()
+	tp.readOnlyPending--
+}
+
 func (tp *TxPool) createConn(ctx context.Context, options *querypb.ExecuteOptions) (*StatefulConnection, error) {
 	conn, err := tp.scp.NewConn(ctx, options)
 	if err != nil {
@@ -341,5 +456,6 @@ func (tp *TxPool) SetTimeout(timeout time.Duration) {
 func (tp *TxPool) txComplete(conn *StatefulConnection, reason tx.ReleaseReason) {
 	conn.LogTransaction(reason)
 	tp.limiter.Release(conn.TxProperties().ImmediateCaller, conn.TxProperties().EffectiveCaller)
+	tp.callerLimiter.Release(conn.TxProperties().ImmediateCaller, conn.TxProperties().EffectiveCaller)
 	conn.CleanTxState()
 }
@@ -0,0 +1,195 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package txlimiter
+
+import (
+	"sort"
+	"sync"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	// maxCallerLabels is the max number of callers exported by
+	// the stats of a CallerLimiter. The other callers are summed
+	// up under otherCallers.
+	maxCallerLabels = 10
+	// maxTrackedRejections is the max number of callers whose
+	// rejections are tracked. The rejections of the callers
+	// beyond are counted under otherCallers right away.
+	maxTrackedRejections = 100
+	otherCallers         = "other"
+)
+
+// CallerLimiter caps the number of concurrent transactions of the
+// callers that have a cap. Unlike Impl, the caps are absolute numbers
+// set per caller, which can change at any time.
+type CallerLimiter struct {
+	mu         sync.Mutex
+	capOf      func(caller string) (int64, bool)
+	usage      map[string]int64
+	rejections map[string]int64
+
+	resets *stats.Counter
+}
+
+// NewCallerLimiter creates a CallerLimiter. It doesn't limit any
+// caller until InitCaps is called.
+func NewCallerLimiter(env tabletenv.Env) *CallerLimiter {
+	cl := &CallerLimiter{
+		usage:      make(map[string]int64),
+		rejections: make(map[string]int64),
+		resets:     env.Exporter().NewCounter("TxCallerLimiterResets", "Number of times the accounting of the transaction caps per caller was reset"),
+	}
+	env.Exporter().NewGaugesFuncWithMultiLabels("TxCallerLimiterUsage", "Number of transactions in flight of the callers that use the most of them", []string{"caller"}, cl.usageCounts)
+	env.Exporter().NewCountersFuncWithMultiLabels("TxCallerLimiterRejections", "Number of transactions rejected because their caller was at its cap, for the callers rejected the most", []string{"caller"}, cl.rejectionCounts)
+	return cl
+}
+
+// InitCaps sets the function that returns the cap of a caller. It's
+// called at every Get, so that the caps can be reloaded at any time.
+func (cl *CallerLimiter) InitCaps(capOf func(caller string) (int64, bool)) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.capOf = capOf
+}
+
+// Get reserves a transaction for the caller, or returns an error if
+// the caller is at its cap. If it returns nil, Release must be called
+// once the transaction is done.
+func (cl *CallerLimiter) Get(immediate *querypb.VTGateCallerID, effective *vtrpcpb.CallerID) error {
+	caller := callerKey(immediate, effective)
+
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	if cl.capOf != nil {
+		if max, ok := cl.capOf(caller); ok && cl.usage[caller] >= max {
+			cl.addRejectionLocked(caller)
+			return vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "transaction limit exceeded for caller %s: %d concurrent transactions", caller, max)
+		}
+	}
+	cl.usage[caller]++
+	return nil
+}
+
+// Release marks that the caller is done with a transaction.
+func (cl *CallerLimiter) Release(immediate *querypb.VTGateCallerID, effective *vtrpcpb.CallerID) {
+	caller := callerKey(immediate, effective)
+
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	usage, ok := cl.usage[caller]
+	if !ok {
+		return
+	}
+	if usage <= 1 {
+		delete(cl.usage, caller)
+		return
+	}
+	cl.usage[caller] = usage - 1
+}
+
+// Reset forgets the transactions in flight. It's called once the tx
+// pool is closed, so that the transactions whose release was missed
+// don't count against their callers after a transition.
+func (cl *CallerLimiter) Reset() {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	if len(cl.usage) != 0 {
+		log.Warningf("TxCallerLimiter: dropping the transactions in flight of %d callers", len(cl.usage))
+	}
+	cl.usage = make(map[string]int64)
+	cl.resets.Add(1)
+}
+
+// Usage returns the number of transactions in flight of caller.
+func (cl *CallerLimiter) Usage(caller string) int64 {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	return cl.usage[caller]
+}
+
+func (cl *CallerLimiter) addRejectionLocked(caller string) {
+	if _, ok := cl.rejections[caller]; !ok && len(cl.rejections) >= maxTrackedRejections {
+		caller = otherCallers
+	}
+	cl.rejections[caller]++
+}
+
+func (cl *CallerLimiter) usageCounts() map[string]int64 {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	return topCallers(cl.usage)
+}
+
+func (cl *CallerLimiter) rejectionCounts() map[string]int64 {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	return topCallers(cl.rejections)
+}
+
+// topCallers returns the maxCallerLabels callers of counts with the
+// highest counts. The counts of the others are summed up under
+// otherCallers.
+func topCallers(counts map[string]int64) map[string]int64 {
+	callers := make([]string, 0, len(counts))
+	for caller := range counts {
+		if caller != otherCallers {
+			callers = append(callers, caller)
+		}
+	}
+	sort.Slice(callers, func(i, j int) bool {
+		if counts[callers[i]] != counts[callers[j]] {
+			return counts[callers[i]] > counts[callers[j]]
+		}
+		return callers[i] < callers[j]
+	})
+	top := make(map[string]int64, maxCallerLabels+1)
+	if other, ok := counts[otherCallers]; ok {
+		top[otherCallers] = other
+	}
+	for i, caller := range callers {
+		if i < maxCallerLabels {
+			top[caller] = counts[caller]
+			continue
+		}
+		top[otherCallers] += counts[caller]
+	}
+	return top
+}
+
+// callerKey identifies the caller of a transaction by its effective
+// caller ID, or its immediate caller ID if there's none.
+func callerKey(immediate *querypb.VTGateCallerID, effective *vtrpcpb.CallerID) string {
+	if principal := callerid.GetPrincipal(effective); principal != "" {
+		return principal
+	}
+	if username := callerid.GetUsername(immediate); username != "" {
+		return username
+	}
+	return unknown
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package txlimiter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestCallerLimiter(t *testing.T) {
+	cl := NewCallerLimiter(tabletenv.NewEnv(tabletenv.NewDefaultConfig(), "TabletServerTest"))
+	caps := map[string]int64{"batch": 2}
+
+	// No caller is limited before InitCaps.
+	im, ef := createCallers("user", "batch", "", "")
+	for i := 0; i < 3; i++ {
+		require.NoError(t, cl.Get(im, ef))
+	}
+	for i := 0; i < 3; i++ {
+		cl.Release(im, ef)
+	}
+
+	cl.InitCaps(func(caller string) (int64, bool) {
+		max, ok := caps[caller]
+		return max, ok
+	})
+	require.NoError(t, cl.Get(im, ef))
+	require.NoError(t, cl.Get(im, ef))
+	err := cl.Get(im, ef)
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	assert.EqualError(t, err, "transaction limit exceeded for caller batch: 2 concurrent transactions")
+	assert.EqualValues(t, 2, cl.Usage("batch"))
+	assert.Equal(t, map[string]int64{"batch": 1}, cl.rejectionCounts())
+
+	// The other callers aren't limited.
+	webIm, webEf := createCallers("user", "web", "", "")
+	for i := 0; i < 3; i++ {
+		require.NoError(t, cl.Get(webIm, webEf))
+	}
+	for i := 0; i < 3; i++ {
+		cl.Release(webIm, webEf)
+	}
+	// The immediate caller is used if there's no effective caller.
+	batchIm, _ := createCallers("batch", "", "", "")
+	assert.Error(t, cl.Get(batchIm, nil))
+
+	// A cap change takes effect right away.
+	caps["batch"] = 5
+	require.NoError(t, cl.Get(im, ef))
+	assert.EqualValues(t, 3, cl.Usage("batch"))
+	for i := 0; i < 3; i++ {
+		cl.Release(im, ef)
+	}
+	assert.Zero(t, cl.Usage("batch"))
+	assert.Empty(t, cl.usageCounts())
+}
+
+func TestCallerLimiterReset(t *testing.T) {
+	cl := NewCallerLimiter(tabletenv.NewEnv(tabletenv.NewDefaultConfig(), "TabletServerTest"))
+	cl.InitCaps(func(caller string) (int64, bool) { return 1, true })
+	resets := cl.resets.Get()
+
+	im, ef := createCallers("user", "batch", "", "")
+	require.NoError(t, cl.Get(im, ef))
+	assert.Error(t, cl.Get(im, ef))
+	cl.Reset()
+	assert.Equal(t, resets+1, cl.resets.Get())
+	assert.Empty(t, cl.usage)
+	require.NoError(t, cl.Get(im, ef))
+
+	// The release of a transaction from before the reset
+	// doesn't go below 0.
+	cl.Release(im, ef)
+	cl.Release(im, ef)
+	assert.Empty(t, cl.usage)
+}
+
+func TestCallerLimiterLabels(t *testing.T) {
+	cl := NewCallerLimiter(tabletenv.NewEnv(tabletenv.NewDefaultConfig(), "TabletServerTest"))
+	cl.InitCaps(func(caller string) (int64, bool) { return 1, true })
+	for i := 0; i < maxTrackedRejections+5; i++ {
+		im, ef := createCallers("", fmt.Sprintf("caller%03d", i), "", "")
+		require.NoError(t, cl.Get(im, ef))
+		// The first callers are rejected more often.
+		rejections := 1
+		if i < maxCallerLabels {
+			rejections = 2
+		}
+		for j := 0; j < rejections; j++ {
+			assert.Error(t, cl.Get(im, ef))
+		}
+	}
+	assert.Len(t, cl.rejections, maxTrackedRejections+1)
+
+	counts := cl.rejectionCounts()
+	assert.Len(t, counts, maxCallerLabels+1)
+	for i := 0; i < maxCallerLabels; i++ {
+		assert.EqualValues(t, 2, counts[fmt.Sprintf("caller%03d", i)])
+	}
+	assert.EqualValues(t, maxTrackedRejections+5-maxCallerLabels, counts[otherCallers])
+
+	usage := cl.usageCounts()
+	assert.Len(t, usage, maxCallerLabels+1)
+	assert.EqualValues(t, maxTrackedRejections+5-maxCallerLabels, usage[otherCallers])
+}
@@ -19,8 +19,13 @@ limitations under the License.
 package txserializer
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -31,6 +36,7 @@ import (
 	"vitess.io/vitess/go/streamlog"
 	"vitess.io/vitess/go/sync2"
 	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 
@@ -79,6 +85,21 @@ type TxSerializer struct {
 	// globalQueueExceeded is the same as queueExceeded but for the global queue.
 	waits, waitsDryRun, queueExceeded, queueExceededDryRun *stats.CountersWithSingleLabel
 	globalQueueExceeded, globalQueueExceededDryRun         *stats.Counter
+	// failedFast counts per table how many transactions were rejected
+	// instead of queued because failFast was set.
+	failedFast *stats.CountersWithSingleLabel
+	// queueLength is the number of transactions per table that are
+	// currently queued behind another one for the same row (range).
+	// waitTimes records per table how long the transactions waited.
+	// Like the counters above, they're kept when the query engine is
+	// reopened: the TxSerializer is created only once.
+	queueLength *stats.GaugesWithSingleLabel
+	waitTimes   *servenv.TimingsWrapper
+
+	// failFast is set while the tablet is under pressure. Transactions
+	// which would have to wait for a hot row are then rejected right
+	// away instead of queued, to avoid that they pile up.
+	failFast sync2.AtomicBool
 
 	log                          *logutil.ThrottledLogger
 	logDryRun                    *logutil.ThrottledLogger
@@ -117,6 +138,18 @@ func New(env tabletenv.Env) *TxSerializer {
 			"TxSerializerQueueExceededDryRun",
 			"Dry-run Number of transactions that were rejected because the max queue size was exceeded",
 			"table_name"),
+		failedFast: env.Exporter().NewCountersWithSingleLabel(
+			"TxSerializerFailedFast",
+			"Number of transactions that were rejected instead of queued because the tablet was under pressure",
+			"table_name"),
+		queueLength: env.Exporter().NewGaugesWithSingleLabel(
+			"TxSerializerQueueLength",
+			"Number of transactions currently queued because another transaction is in flight for the same row range",
+			"table_name"),
+		waitTimes: env.Exporter().NewTimings(
+			"TxSerializerWaitTimes",
+			"Time transactions waited because another transaction was in flight for the same row range",
+			"table_name"),
 		globalQueueExceeded: env.Exporter().NewCounter(
 			"TxSerializerGlobalQueueExceeded",
 			"Number of transactions that were rejected on the global queue because of exceeding the max queue size per row range"),
@@ -136,6 +169,13 @@ func New(env tabletenv.Env) *TxSerializer {
 // DoneFunc is returned by Wait() and must be called by the caller.
 type DoneFunc func()
 
+// SetFailFast switches between queueing the transactions for a hot row
+// and rejecting them right away. It's set while the tablet is under
+// pressure. It has no effect in dry-run mode.
+func (txs *TxSerializer) SetFailFast(failFast bool) {
+	txs.failFast.Set(failFast)
+}
+
 // Wait blocks if another transaction for the same range is already in flight.
 // It returns when this transaction has its turn.
 // "done" is != nil if err == nil and must be called once the transaction is
@@ -165,7 +205,7 @@ func (txs *TxSerializer) lockLocked(ctx context.Context, key, table string) (boo
 	q, ok := txs.queues[key]
 	if !ok {
 		// First transaction in the queue i.e. we don't wait and return immediately.
-		txs.queues[key] = newQueueForFirstTransaction(txs.concurrentTransactions)
+		txs.queues[key] = newQueueForFirstTransaction(table, txs.concurrentTransactions)
 		txs.globalSize++
 		return false, nil
 	}
@@ -211,6 +251,7 @@ func (txs *TxSerializer) lockLocked(ctx context.Context, key, table string) (boo
 	if q.size > q.max {
 		q.max = q.size
 	}
+	txs.queueLength.Add(table, 1)
 	// Publish the number of waits at /debug/hotrows.
 	txs.Record(key)
 
@@ -233,8 +274,17 @@ func (txs *TxSerializer) lockLocked(ctx context.Context, key, table string) (boo
 	default:
 	}
 
+	if txs.failFast.Get() {
+		// Return waited=true to undo the queue counts like for a
+		// canceled wait.
+		txs.failedFast.Add(table, 1)
+		return true, vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED,
+			"hot row protection: failing fast under pressure, another transaction is in progress for the same row (table + WHERE clause: '%v')", key)
+	}
+
 	// Blocking wait for the next available slot.
 	txs.waits.Add(table, 1)
+	defer txs.waitTimes.Record(table, time.Now())
 	select {
 	case q.availableSlots <- struct{}{}:
 		return true, nil
@@ -254,6 +304,10 @@ func (txs *TxSerializer) unlockLocked(key string, returnSlot bool) {
 	q := txs.queues[key]
 	q.size--
 	txs.globalSize--
+	if q.size > 0 {
+		// Only the transactions after the first one were queued.
+		txs.queueLength.Add(q.table, -1)
+	}
 
 	if q.size == 0 {
 		// This is the last transaction in flight.
@@ -335,6 +389,68 @@ func (txs *TxSerializer) ServeHTTP(response http.ResponseWriter, request *http.R
 	}
 }
 
+// hotKey is an entry of the list served by ServeKeysHTTP.
+type hotKey struct {
+	Table string `json:"table"`
+	// KeyHash identifies the row (range) without leaking the values
+	// of its WHERE clause.
+	KeyHash string `json:"key_hash"`
+	// Waits is the number of transactions that waited for the key
+	// since it became hot, as shown at /debug/hotrows.
+	Waits int64 `json:"waits"`
+	// Queued is the number of transactions currently queued or in
+	// flight for the key.
+	Queued int `json:"queued"`
+}
+
+// maxHotKeys is the max number of keys listed by ServeKeysHTTP.
+const maxHotKeys = 20
+
+// HotKeys returns the keys which had the most waiting transactions,
+// hottest first. The keys are hashed.
+func (txs *TxSerializer) HotKeys() []hotKey {
+	items := txs.Items()
+
+	txs.mu.Lock()
+	keys := make([]hotKey, 0, len(items))
+	for _, item := range items {
+		key := hotKey{
+			// The key starts with the table name. See
+			// TabletServer.computeTxSerializerKey.
+			Table:   strings.SplitN(item.Query, " ", 2)[0],
+			KeyHash: hashKey(item.Query),
+			Waits:   item.Count,
+		}
+		if q, ok := txs.queues[item.Query]; ok {
+			key.Queued = q.size
+		}
+		keys = append(keys, key)
+	}
+	txs.mu.Unlock()
+
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].Waits > keys[j].Waits })
+	if len(keys) > maxHotKeys {
+		keys = keys[:maxHotKeys]
+	}
+	return keys
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ServeKeysHTTP lists the hottest keys as JSON. Unlike /debug/hotrows,
+// it's not redacted: the keys are hashed.
+func (txs *TxSerializer) ServeKeysHTTP(response http.ResponseWriter, request *http.Request) {
+	if err := acl.CheckAccessHTTP(request, acl.DEBUGGING); err != nil {
+		acl.SendError(response, err)
+		return
+	}
+	response.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(response).Encode(txs.HotKeys())
+}
+
 // queue represents the local queue for a particular row (range).
 //
 // Note that we don't use a dedicated queue structure for all waiting
@@ -344,6 +460,9 @@ func (txs *TxSerializer) ServeHTTP(response http.ResponseWriter, request *http.R
 // transactions which can access the tx pool). All queued transactions are
 // competing for these slots and try to add themselves to the channel.
 type queue struct {
+	// table is the table of the row (range).
+	table string
+
 	// NOTE: The following fields are guarded by TxSerializer.mu.
 	// size counts how many transactions are currently queued/in flight (includes
 	// the transactions which are not waiting.)
@@ -364,8 +483,9 @@ type queue struct {
 	availableSlots chan struct{}
 }
 
-func newQueueForFirstTransaction(concurrentTransactions int) *queue {
+func newQueueForFirstTransaction(table string, concurrentTransactions int) *queue {
 	return &queue{
+		table: table,
 		size:  1,
 		count: 1,
 		max:   1,
@@ -25,6 +25,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
 
 	"vitess.io/vitess/go/streamlog"
@@ -41,6 +43,9 @@ func resetVariables(txs *TxSerializer) {
 	txs.queueExceededDryRun.ResetAll()
 	txs.globalQueueExceeded.Reset()
 	txs.globalQueueExceededDryRun.Reset()
+	txs.failedFast.ResetAll()
+	txs.queueLength.ResetAll()
+	txs.waitTimes.Reset()
 }
 
 func TestTxSerializer_NoHotRow(t *testing.T) {
@@ -174,6 +179,51 @@ func TestTxSerializer(t *testing.T) {
 	}
 }
 
+func TestTxSerializerQueueMetrics(t *testing.T) {
+	config := tabletenv.NewDefaultConfig()
+	config.HotRowProtection.MaxQueueSize = 3
+	config.HotRowProtection.MaxGlobalQueueSize = 3
+	config.HotRowProtection.MaxConcurrency = 1
+	txs := New(tabletenv.NewEnv(config, "TxSerializerTest"))
+	resetVariables(txs)
+
+	done1, _, err := txs.Wait(context.Background(), "t1 where1", "t1")
+	require.NoError(t, err)
+	assert.Zero(t, txs.queueLength.Counts()["t1"])
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		done2, waited2, err := txs.Wait(context.Background(), "t1 where1", "t1")
+		assert.NoError(t, err)
+		assert.True(t, waited2)
+		done2()
+	}()
+	require.NoError(t, waitForPending(txs, "t1 where1", 2))
+	assert.EqualValues(t, 1, txs.queueLength.Counts()["t1"])
+
+	// The keys are listed hashed.
+	want := []hotKey{{
+		Table:   "t1",
+		KeyHash: hashKey("t1 where1"),
+		Waits:   2,
+		Queued:  2,
+	}}
+	assert.Equal(t, want, txs.HotKeys())
+	rr := httptest.NewRecorder()
+	txs.ServeKeysHTTP(rr, httptest.NewRequest("GET", "/debug/hotrows/keys", nil))
+	assert.NotContains(t, rr.Body.String(), "where1")
+	assert.Contains(t, rr.Body.String(), hashKey("t1 where1"))
+
+	done1()
+	wg.Wait()
+	assert.Zero(t, txs.queueLength.Counts()["t1"])
+	assert.EqualValues(t, 1, txs.waitTimes.Counts()["TxSerializerTest.t1"])
+	want[0].Queued = 0
+	assert.Equal(t, want, txs.HotKeys())
+}
+
 func TestTxSerializer_ConcurrentTransactions(t *testing.T) {
 	// Allow up to 2 concurrent transactions per hot row.
 	config := tabletenv.NewDefaultConfig()
@@ -510,6 +560,66 @@ func TestTxSerializerGlobalQueueOverflow(t *testing.T) {
 	done2()
 }
 
+func TestTxSerializerFailFast(t *testing.T) {
+	config := tabletenv.NewDefaultConfig()
+	config.HotRowProtection.MaxQueueSize = 3
+	config.HotRowProtection.MaxGlobalQueueSize = 3
+	config.HotRowProtection.MaxConcurrency = 1
+	txs := New(tabletenv.NewEnv(config, "TxSerializerTest"))
+	resetVariables(txs)
+	txs.SetFailFast(true)
+
+	// tx1.
+	done1, waited1, err1 := txs.Wait(context.Background(), "t1 where1", "t1")
+	if err1 != nil {
+		t.Error(err1)
+	}
+	if waited1 {
+		t.Errorf("first transaction must never wait: %v", waited1)
+	}
+
+	// tx2 (same row range as tx1) is rejected instead of queued.
+	_, _, err2 := txs.Wait(context.Background(), "t1 where1", "t1")
+	if got, want := vterrors.Code(err2), vtrpcpb.Code_RESOURCE_EXHAUSTED; got != want {
+		t.Errorf("wrong error code: got = %v, want = %v", got, want)
+	}
+	if got, want := err2.Error(), "hot row protection: failing fast under pressure, another transaction is in progress for the same row (table + WHERE clause: 't1 where1')"; got != want {
+		t.Errorf("transaction rejected with wrong error: got = %v, want = %v", got, want)
+	}
+	if got, want := txs.failedFast.Counts()["t1"], int64(1); got != want {
+		t.Errorf("variable not incremented: got = %v, want = %v", got, want)
+	}
+	if got, want := txs.Pending("t1 where1"), 1; got != want {
+		t.Errorf("rejected transaction must not be pending: got = %v, want = %v", got, want)
+	}
+
+	// Once the pressure is gone, tx3 is queued again.
+	txs.SetFailFast(false)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		done3, waited3, err3 := txs.Wait(context.Background(), "t1 where1", "t1")
+		if err3 != nil {
+			t.Error(err3)
+			return
+		}
+		if !waited3 {
+			t.Error("third transaction must wait")
+		}
+		done3()
+	}()
+	if err := waitForPending(txs, "t1 where1", 2); err != nil {
+		t.Fatal(err)
+	}
+	done1()
+	wg.Wait()
+
+	if got, want := txs.waits.Counts()["t1"], int64(1); got != want {
+		t.Errorf("variable not incremented: got = %v, want = %v", got, want)
+	}
+}
+
 func TestTxSerializerPending(t *testing.T) {
 	config := tabletenv.NewDefaultConfig()
 	config.HotRowProtection.MaxQueueSize = 1
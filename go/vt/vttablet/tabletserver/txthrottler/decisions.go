@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package txthrottler
+
+import (
+	"sync"
+	"time"
+)
+
+// maxThrottleDecisions is the number of decisions kept in the history.
+const maxThrottleDecisions = 100
+
+// ThrottleDecision is a transaction that was throttled, or would
+// have been in dry-run mode.
+type ThrottleDecision struct {
+	Time   time.Time
+	DryRun bool
+	// Tablet is the key of the most lagging replica at the time
+	// of the decision, and ReplicationLag its lag. Tablet is empty
+	// if no replica reported its lag yet.
+	Tablet         string
+	ReplicationLag time.Duration
+}
+
+// decisionLog keeps the last decisions in a ring buffer, and
+// counts all of them.
+type decisionLog struct {
+	mu        sync.Mutex
+	decisions []ThrottleDecision
+	next      int
+	throttled int64
+	dryRun    int64
+}
+
+func newDecisionLog(size int) *decisionLog {
+	return &decisionLog{decisions: make([]ThrottleDecision, 0, size)}
+}
+
+func (dl *decisionLog) add(decision ThrottleDecision) {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	if decision.DryRun {
+		dl.dryRun++
+	} else {
+		dl.throttled++
+	}
+	if len(dl.decisions) < cap(dl.decisions) {
+		dl.decisions = append(dl.decisions, decision)
+		return
+	}
+	dl.decisions[dl.next] = decision
+	dl.next = (dl.next + 1) % len(dl.decisions)
+}
+
+// RecentDecisions returns the last throttle decisions, most recent first.
+func (t *TxThrottler) RecentDecisions() []ThrottleDecision {
+	dl := t.decisions
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	decisions := make([]ThrottleDecision, 0, len(dl.decisions))
+	for i := len(dl.decisions) - 1; i >= 0; i-- {
+		decisions = append(decisions, dl.decisions[(dl.next+i)%len(dl.decisions)])
+	}
+	return decisions
+}
+
+// DecisionCounts returns the number of transactions that were
+// throttled, and the number that would have been in dry-run mode.
+func (t *TxThrottler) DecisionCounts() (throttled, dryRun int64) {
+	dl := t.decisions
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	return dl.throttled, dl.dryRun
+}
@@ -72,6 +72,11 @@ type TxThrottler struct {
 	state *txThrottlerState
 
 	target querypb.Target
+
+	// decisions records the recent throttle decisions. It
+	// outlives the state, so that the history survives a
+	// Close and Open.
+	decisions *decisionLog
 }
 
 // NewTxThrottler tries to construct a TxThrottler from the
@@ -116,6 +121,7 @@ func tryCreateTxThrottler(config *tabletenv.TabletConfig, topoServer *topo.Serve
 
 	return newTxThrottler(&txThrottlerConfig{
 		enabled:          true,
+		dryRun:           config.TxThrottlerDryRun,
 		topoServer:       topoServer,
 		throttlerConfig:  &throttlerConfig,
 		healthCheckCells: healthCheckCells,
@@ -129,6 +135,9 @@ type txThrottlerConfig struct {
 	// of a disabled transaction throttler do nothing and Throttle() always
 	// returns false.
 	enabled bool
+	// dryRun makes Throttle only record the transactions that
+	// would have been throttled. It always returns false.
+	dryRun bool
 
 	topoServer      *topo.Server
 	throttlerConfig *throttlerdatapb.Configuration
@@ -168,6 +177,11 @@ type txThrottlerState struct {
 
 	healthCheck      discovery.LegacyHealthCheck
 	topologyWatchers []TopologyWatcherInterface
+
+	// lagMu protects replicaLag, the last replication lag
+	// reported by each monitored replica, by tablet key.
+	lagMu      sync.Mutex
+	replicaLag map[string]time.Duration
 }
 
 // These vars store the functions used to create the topo server, healthcheck,
@@ -213,7 +227,8 @@ func newTxThrottler(config *txThrottlerConfig) (*TxThrottler, error) {
 		}
 	}
 	return &TxThrottler{
-		config: config,
+		config:    config,
+		decisions: newDecisionLog(maxThrottleDecisions),
 	}, nil
 }
 
@@ -250,6 +265,8 @@ func (t *TxThrottler) Close() {
 // It returns true if the transaction should not proceed (the caller
 // should back off). Throttle requires that Open() was previously called
 // successfully.
+// In dry-run mode, the decision is recorded, but Throttle always
+// returns false.
 func (t *TxThrottler) Throttle() (result bool) {
 	if !t.config.enabled {
 		return false
@@ -257,7 +274,22 @@ func (t *TxThrottler) Throttle() (result bool) {
 	if t.state == nil {
 		panic("BUG: Throttle() called on a closed TxThrottler")
 	}
-	return t.state.throttle()
+	if !t.state.throttle() {
+		return false
+	}
+	tablet, lag := t.state.maxReplicaLag()
+	t.decisions.add(ThrottleDecision{
+		Time:           time.Now(),
+		DryRun:         t.config.dryRun,
+		Tablet:         tablet,
+		ReplicationLag: lag,
+	})
+	return !t.config.dryRun
+}
+
+// DryRun returns true if the throttler only records its decisions.
+func (t *TxThrottler) DryRun() bool {
+	return t.config.enabled && t.config.dryRun
 }
 
 func newTxThrottlerState(config *txThrottlerConfig, keyspace, shard string,
@@ -276,7 +308,8 @@ func newTxThrottlerState(config *txThrottlerConfig, keyspace, shard string,
 		return nil, err
 	}
 	result := &txThrottlerState{
-		throttler: t,
+		throttler:  t,
+		replicaLag: make(map[string]time.Duration),
 	}
 	result.healthCheck = healthCheckFactory()
 	result.healthCheck.SetListener(result, false /* sendDownEvents */)
@@ -336,5 +369,31 @@ func (ts *txThrottlerState) StatsUpdate(tabletStats *discovery.LegacyTabletStats
 	if tabletStats.Target.TabletType != topodatapb.TabletType_REPLICA {
 		return
 	}
+	ts.recordReplicaLag(tabletStats)
 	ts.throttler.RecordReplicationLag(time.Now(), tabletStats)
 }
+
+func (ts *txThrottlerState) recordReplicaLag(tabletStats *discovery.LegacyTabletStats) {
+	ts.lagMu.Lock()
+	defer ts.lagMu.Unlock()
+	if !tabletStats.Up || tabletStats.Stats == nil {
+		delete(ts.replicaLag, tabletStats.Key)
+		return
+	}
+	ts.replicaLag[tabletStats.Key] = time.Duration(tabletStats.Stats.SecondsBehindMaster) * time.Second
+}
+
+// maxReplicaLag returns the key and the replication lag of the
+// most lagging replica, which is what makes the throttler back off.
+func (ts *txThrottlerState) maxReplicaLag() (string, time.Duration) {
+	ts.lagMu.Lock()
+	defer ts.lagMu.Unlock()
+	var tablet string
+	var lag time.Duration
+	for key, l := range ts.replicaLag {
+		if tablet == "" || l > lag || (l == lag && key < tablet) {
+			tablet, lag = key, l
+		}
+	}
+	return tablet, lag
+}
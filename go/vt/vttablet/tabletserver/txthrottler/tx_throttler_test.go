@@ -22,10 +22,14 @@ package txthrottler
 //go:generate mockgen -destination mock_topology_watcher_test.go -package txthrottler vitess.io/vitess/go/vt/vttablet/tabletserver/txthrottler TopologyWatcherInterface
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"vitess.io/vitess/go/vt/discovery"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
@@ -145,3 +149,85 @@ func TestEnabledThrottler(t *testing.T) {
 	}
 	throttler.Close()
 }
+
+func TestDryRunThrottler(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	defer resetTxThrottlerFactories()
+	ts := memorytopo.NewServer("cell1")
+
+	mockHealthCheck := NewMockHealthCheck(mockCtrl)
+	var hcListener discovery.LegacyHealthCheckStatsListener
+	mockHealthCheck.EXPECT().SetListener(gomock.Any(), false /* sendDownEvents */).Do(func(listener discovery.LegacyHealthCheckStatsListener, sendDownEvents bool) {
+		hcListener = listener
+	})
+	mockHealthCheck.EXPECT().Close()
+	healthCheckFactory = func() discovery.LegacyHealthCheck { return mockHealthCheck }
+	topologyWatcherFactory = func(topoServer *topo.Server, tr discovery.LegacyTabletRecorder, cell, keyspace, shard string, refreshInterval time.Duration, topoReadConcurrency int) TopologyWatcherInterface {
+		result := NewMockTopologyWatcherInterface(mockCtrl)
+		result.EXPECT().Stop()
+		return result
+	}
+	mockThrottler := NewMockThrottlerInterface(mockCtrl)
+	throttlerFactory = func(name, unit string, threadCount int, maxRate, maxReplicationLag int64) (ThrottlerInterface, error) {
+		return mockThrottler, nil
+	}
+	mockThrottler.EXPECT().UpdateConfiguration(gomock.Any(), true /* copyZeroValues */)
+	mockThrottler.EXPECT().RecordReplicationLag(gomock.Any(), gomock.Any()).Times(2)
+	mockThrottler.EXPECT().Throttle(0).Return(1 * time.Second).Times(2)
+	mockThrottler.EXPECT().Close()
+
+	config := tabletenv.NewDefaultConfig()
+	config.EnableTxThrottler = true
+	config.TxThrottlerDryRun = true
+	config.TxThrottlerHealthCheckCells = []string{"cell1"}
+
+	throttler, err := tryCreateTxThrottler(config, ts)
+	require.NoError(t, err)
+	assert.True(t, throttler.DryRun())
+	throttler.InitDBConfig(querypb.Target{
+		Keyspace: "keyspace",
+		Shard:    "shard",
+	})
+	require.NoError(t, throttler.Open())
+
+	// The decision is recorded, but not enforced.
+	assert.False(t, throttler.Throttle())
+	for i, lag := range []uint32{3, 12} {
+		hcListener.StatsUpdate(&discovery.LegacyTabletStats{
+			Key:    fmt.Sprintf("replica%d", i),
+			Up:     true,
+			Target: &querypb.Target{TabletType: topodatapb.TabletType_REPLICA},
+			Stats:  &querypb.RealtimeStats{SecondsBehindMaster: lag},
+		})
+	}
+	assert.False(t, throttler.Throttle())
+	throttler.Close()
+
+	throttled, dryRun := throttler.DecisionCounts()
+	assert.Zero(t, throttled)
+	assert.Equal(t, int64(2), dryRun)
+	decisions := throttler.RecentDecisions()
+	require.Len(t, decisions, 2)
+	assert.True(t, decisions[0].DryRun)
+	assert.Equal(t, "replica1", decisions[0].Tablet)
+	assert.Equal(t, 12*time.Second, decisions[0].ReplicationLag)
+	assert.Empty(t, decisions[1].Tablet)
+}
+
+func TestDecisionLog(t *testing.T) {
+	dl := newDecisionLog(3)
+	for i := 1; i <= 5; i++ {
+		dl.add(ThrottleDecision{DryRun: i%2 == 0, ReplicationLag: time.Duration(i)})
+	}
+	throttler := &TxThrottler{decisions: dl}
+	var lags []time.Duration
+	for _, decision := range throttler.RecentDecisions() {
+		lags = append(lags, decision.ReplicationLag)
+	}
+	assert.Equal(t, []time.Duration{5, 4, 3}, lags)
+	throttled, dryRun := throttler.DecisionCounts()
+	assert.Equal(t, int64(3), throttled)
+	assert.Equal(t, int64(2), dryRun)
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2020 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vstreamer
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/dbconfigs"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+	"vitess.io/vitess/go/vt/withddl"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+const createCopyProgressTable = `create table if not exists _vt.vstream_copy_state (
+  stream_key varbinary(64) not null,
+  table_name varbinary(128) not null,
+  pos varbinary(10000) not null,
+  lastpk varbinary(2000) default null,
+  completed tinyint not null default 0,
+  time_updated bigint not null,
+  primary key (stream_key, table_name)
+) engine=InnoDB`
+
+var copyProgressWithDDL = withddl.New([]string{
+	"create database if not exists _vt",
+	createCopyProgressTable,
+})
+
+// copyProgress saves the progress of the copy phase of a vstream in
+// _vt.vstream_copy_state: the position of the stream, and the lastpk
+// of every table it started or completed copying. A consumer that
+// reconnects with the same filter and the position it last received
+// resumes the copy from there, instead of copying all the tables again.
+// The progress is saved with sql_log_bin off, because it belongs to
+// the tablet: it's neither replicated nor sent in vstreams.
+// copyProgress is not thread safe. It's used by the goroutine that runs
+// the copy.
+type copyProgress struct {
+	cp       dbconfigs.Connector
+	key      string
+	interval time.Duration
+
+	pos       string
+	lastpks   map[string]*querypb.QueryResult
+	completed map[string]bool
+	dirty     bool
+	lastSaved time.Time
+}
+
+// copyProgressKey returns the key of the progress of the streams
+// that use filter.
+func copyProgressKey(filter *binlogdatapb.Filter) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(proto.CompactTextString(filter))))
+}
+
+func newCopyProgress(cp dbconfigs.Connector, key string, interval time.Duration) *copyProgress {
+	return &copyProgress{
+		cp:        cp,
+		key:       key,
+		interval:  interval,
+		lastpks:   make(map[string]*querypb.QueryResult),
+		completed: make(map[string]bool),
+		lastSaved: time.Now(),
+	}
+}
+
+// load reads the saved progress. It returns false if there's none,
+// or if it was saved at a position other than pos.
+func (cpr *copyProgress) load(ctx context.Context, pos string) (bool, error) {
+	conn, err := cpr.cp.Connect(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	query := fmt.Sprintf("select table_name, pos, lastpk, completed from _vt.vstream_copy_state where stream_key=%s", encodeString(cpr.key))
+	qr, err := copyProgressWithDDL.ExecIgnore(ctx, query, conn.ExecuteFetch)
+	if err != nil {
+		return false, err
+	}
+	if len(qr.Rows) == 0 {
+		return false, nil
+	}
+	for _, row := range qr.Rows {
+		if row[1].ToString() != pos {
+			return false, nil
+		}
+	}
+	for _, row := range qr.Rows {
+		tableName := row[0].ToString()
+		if completed, _ := evalengine.ToInt64(row[3]); completed != 0 {
+			cpr.completed[tableName] = true
+			continue
+		}
+		if row[2].IsNull() {
+			continue
+		}
+		var lastpk querypb.QueryResult
+		if err := proto.UnmarshalText(row[2].ToString(), &lastpk); err != nil {
+			return false, err
+		}
+		cpr.lastpks[tableName] = &lastpk
+	}
+	cpr.pos = pos
+	return true, nil
+}
+
+// tablePKs returns the lastpks of the tables that weren't
+// completely copied.
+func (cpr *copyProgress) tablePKs() []*binlogdatapb.TableLastPK {
+	var tablePKs []*binlogdatapb.TableLastPK
+	for tableName, lastpk := range cpr.lastpks {
+		tablePKs = append(tablePKs, &binlogdatapb.TableLastPK{
+			TableName: tableName,
+			Lastpk:    lastpk,
+		})
+	}
+	return tablePKs
+}
+
+// update records the progress of the copy. It's saved if the
+// interval has passed since the last save.
+func (cpr *copyProgress) update(ctx context.Context, pos, tableName string, lastpk *querypb.QueryResult) error {
+	cpr.setPos(pos)
+	cpr.lastpks[tableName] = lastpk
+	cpr.dirty = true
+	if time.Since(cpr.lastSaved) < cpr.interval {
+		return nil
+	}
+	return cpr.flush(ctx)
+}
+
+// complete records that a table was completely copied.
+func (cpr *copyProgress) complete(ctx context.Context, pos, tableName string) error {
+	cpr.setPos(pos)
+	delete(cpr.lastpks, tableName)
+	cpr.completed[tableName] = true
+	cpr.dirty = true
+	if time.Since(cpr.lastSaved) < cpr.interval {
+		return nil
+	}
+	return cpr.flush(ctx)
+}
+
+// setPos records the position of the stream. It moves without
+// any table progress while the stream catches up.
+func (cpr *copyProgress) setPos(pos string) {
+	if pos == cpr.pos {
+		return
+	}
+	cpr.pos = pos
+	cpr.dirty = true
+}
+
+// flush saves the progress recorded since the last save, if any.
+func (cpr *copyProgress) flush(ctx context.Context) error {
+	if !cpr.dirty || cpr.pos == "" {
+		return nil
+	}
+	conn, err := cpr.cp.Connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecuteFetch("set @@session.sql_log_bin = 0", 1, false); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	buf := bytes.NewBuffer(nil)
+	buf.WriteString("insert into _vt.vstream_copy_state(stream_key, table_name, pos, lastpk, completed, time_updated) values ")
+	prefix := ""
+	for tableName := range cpr.completed {
+		fmt.Fprintf(buf, "%s(%s, %s, %s, null, 1, %d)", prefix, encodeString(cpr.key), encodeString(tableName), encodeString(cpr.pos), now.Unix())
+		prefix = ", "
+	}
+	for tableName, lastpk := range cpr.lastpks {
+		fmt.Fprintf(buf, "%s(%s, %s, %s, %s, 0, %d)", prefix, encodeString(cpr.key), encodeString(tableName), encodeString(cpr.pos), encodeString(proto.CompactTextString(lastpk)), now.Unix())
+		prefix = ", "
+	}
+	if prefix == "" {
+		return nil
+	}
+
+	if _, err := conn.ExecuteFetch("begin", 1, false); err != nil {
+		return err
+	}
+	if _, err := copyProgressWithDDL.Exec(ctx, fmt.Sprintf("delete from _vt.vstream_copy_state where stream_key=%s", encodeString(cpr.key)), conn.ExecuteFetch); err != nil {
+		conn.ExecuteFetch("rollback", 1, false)
+		return err
+	}
+	if _, err := conn.ExecuteFetch(buf.String(), 1, false); err != nil {
+		conn.ExecuteFetch("rollback", 1, false)
+		return err
+	}
+	if _, err := conn.ExecuteFetch("commit", 1, false); err != nil {
+		return err
+	}
+	cpr.dirty = false
+	cpr.lastSaved = now
+	return nil
+}
+
+// clear deletes the saved progress. It's called once the copy completes.
+func (cpr *copyProgress) clear(ctx context.Context) error {
+	conn, err := cpr.cp.Connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecuteFetch("set @@session.sql_log_bin = 0", 1, false); err != nil {
+		return err
+	}
+	query := fmt.Sprintf("delete from _vt.vstream_copy_state where stream_key=%s", encodeString(cpr.key))
+	if _, err := copyProgressWithDDL.ExecIgnore(ctx, query, conn.ExecuteFetch); err != nil {
+		return err
+	}
+	cpr.dirty = false
+	return nil
+}
+
+func encodeString(in string) string {
+	buf := bytes.NewBuffer(nil)
+	sqltypes.NewVarChar(in).EncodeSQL(buf)
+	return buf.String()
+}
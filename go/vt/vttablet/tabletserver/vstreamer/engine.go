@@ -22,7 +22,9 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"sort"
 	"sync"
+	"time"
 
 	"vitess.io/vitess/go/vt/servenv"
 
@@ -35,11 +37,27 @@ import (
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
 
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 )
 
+// copyThrottlerAppName is the app name the copy phase
+// uses to check the lag throttler.
+const copyThrottlerAppName = "vstreamer-copy"
+
+// throttleCheckInterval is how often a throttled copy phase
+// checks the lag throttler again. It's a var for testing.
+var throttleCheckInterval = 250 * time.Millisecond
+
+// LagThrottler is the part of the tablet's lag throttler that
+// the copy phase of a vstream checks before reading more rows.
+type LagThrottler interface {
+	IsOpen() bool
+	CheckByApp(ctx context.Context, appName string) *throttle.CheckResult
+}
+
 // Engine is the engine for handling vreplication streaming requests.
 type Engine struct {
 	env  tabletenv.Env
@@ -50,6 +68,10 @@ type Engine struct {
 	// keyspace is initialized by InitDBConfig
 	keyspace string
 
+	// throttler is initialized by SetThrottler. If it's nil or
+	// not open, the copy phase uses the configured rate limit.
+	throttler LagThrottler
+
 	// wg is incremented for every Stream, and decremented on end.
 	// Close waits for all current streams to end by waiting on wg.
 	wg sync.WaitGroup
@@ -60,6 +82,9 @@ type Engine struct {
 	streamers       map[int]*uvstreamer
 	rowStreamers    map[int]*rowStreamer
 	resultStreamers map[int]*resultStreamer
+	// copyProgressKeys are the keys of the copy progress saved
+	// by the active streams. Only one stream saves a given key.
+	copyProgressKeys map[string]bool
 
 	// watcherOnce is used for initializing vschema
 	// and setting up the vschema watch. It's guaranteed that
@@ -77,10 +102,12 @@ type Engine struct {
 	vstreamerEventsStreamed  *stats.Counter
 	vstreamerPacketSize      *stats.GaugeFunc
 	vstreamerNumPackets      *stats.Counter
+	vstreamerMaxIdle         *stats.GaugeFunc
 	resultStreamerNumRows    *stats.Counter
 	resultStreamerNumPackets *stats.Counter
 	rowStreamerNumRows       *stats.Counter
 	rowStreamerNumPackets    *stats.Counter
+	copyThrottleWaits        *servenv.TimingsWrapper
 }
 
 // NewEngine creates a new Engine.
@@ -97,6 +124,8 @@ func NewEngine(env tabletenv.Env, ts srvtopo.Server, se *schema.Engine, cell str
 		rowStreamers:    make(map[int]*rowStreamer),
 		resultStreamers: make(map[int]*resultStreamer),
 
+		copyProgressKeys: make(map[string]bool),
+
 		lvschema: &localVSchema{vschema: &vindexes.VSchema{}},
 
 		vschemaErrors:  env.Exporter().NewCounter("VSchemaErrors", "Count of VSchema errors"),
@@ -110,8 +139,11 @@ func NewEngine(env tabletenv.Env, ts srvtopo.Server, se *schema.Engine, cell str
 		resultStreamerNumRows:    env.Exporter().NewCounter("ResultStreamerNumRows", "Number of rows sent in result streamer"),
 		rowStreamerNumPackets:    env.Exporter().NewCounter("RowStreamerNumPackets", "Number of packets in row streamer"),
 		rowStreamerNumRows:       env.Exporter().NewCounter("RowStreamerNumRows", "Number of rows sent in row streamer"),
+		copyThrottleWaits:        env.Exporter().NewTimings("VStreamerCopyThrottleWaits", "Time the copy phase of vstreams waited for the lag throttler or the copy rate limit, by table", "table"),
 	}
+	vse.vstreamerMaxIdle = env.Exporter().NewGaugeFunc("VStreamerMaxSecondsSinceLastEvent", "Max number of seconds since an active vstream last sent an event", vse.maxSecondsSinceLastEvent)
 	env.Exporter().HandleFunc("/debug/tablet_vschema", vse.ServeHTTP)
+	env.Exporter().HandleFunc("/debug/vstreams", vse.serveLiveness)
 	return vse
 }
 
@@ -120,6 +152,24 @@ func (vse *Engine) InitDBConfig(keyspace string) {
 	vse.keyspace = keyspace
 }
 
+// SetThrottler sets the lag throttler that the copy phase
+// of a vstream checks before reading more rows.
+func (vse *Engine) SetThrottler(throttler LagThrottler) {
+	vse.mu.Lock()
+	defer vse.mu.Unlock()
+	vse.throttler = throttler
+}
+
+// lagThrottler returns the lag throttler if it's open.
+func (vse *Engine) lagThrottler() LagThrottler {
+	vse.mu.Lock()
+	defer vse.mu.Unlock()
+	if vse.throttler == nil || !vse.throttler.IsOpen() {
+		return nil
+	}
+	return vse.throttler
+}
+
 // Open starts the Engine service.
 func (vse *Engine) Open() {
 	vse.mu.Lock()
@@ -139,6 +189,8 @@ func (vse *Engine) IsOpen() bool {
 }
 
 // Close closes the Engine service.
+// The streams that are copying tables save their progress
+// before they end, and Close waits for them.
 func (vse *Engine) Close() {
 	func() {
 		vse.mu.Lock()
@@ -165,6 +217,93 @@ func (vse *Engine) Close() {
 	log.Info("VStreamer: closed")
 }
 
+// ActiveStreams returns the number of running streams,
+// which Close terminates.
+func (vse *Engine) ActiveStreams() int {
+	vse.mu.Lock()
+	defer vse.mu.Unlock()
+	return len(vse.streamers) + len(vse.rowStreamers) + len(vse.resultStreamers)
+}
+
+// StreamLiveness describes an active vstream.
+type StreamLiveness struct {
+	ID        int
+	Phase     string
+	StartTime time.Time
+	// SecondsSinceLastEvent is the time since the stream last sent
+	// events, heartbeats included, or since it started.
+	SecondsSinceLastEvent float64
+}
+
+// Liveness returns the liveness of the active vstreams,
+// the ones that sent events the longest ago first.
+func (vse *Engine) Liveness() []StreamLiveness {
+	vse.mu.Lock()
+	streamers := make(map[int]*uvstreamer, len(vse.streamers))
+	for id, s := range vse.streamers {
+		streamers[id] = s
+	}
+	vse.mu.Unlock()
+
+	now := time.Now()
+	liveness := make([]StreamLiveness, 0, len(streamers))
+	for id, s := range streamers {
+		liveness = append(liveness, s.liveness(id, now))
+	}
+	sort.Slice(liveness, func(i, j int) bool {
+		if liveness[i].SecondsSinceLastEvent != liveness[j].SecondsSinceLastEvent {
+			return liveness[i].SecondsSinceLastEvent > liveness[j].SecondsSinceLastEvent
+		}
+		return liveness[i].ID < liveness[j].ID
+	})
+	return liveness
+}
+
+func (vse *Engine) maxSecondsSinceLastEvent() int64 {
+	liveness := vse.Liveness()
+	if len(liveness) == 0 {
+		return 0
+	}
+	return int64(liveness[0].SecondsSinceLastEvent)
+}
+
+// serveLiveness shows the liveness of the active vstreams.
+func (vse *Engine) serveLiveness(response http.ResponseWriter, request *http.Request) {
+	if err := acl.CheckAccessHTTP(request, acl.DEBUGGING); err != nil {
+		acl.SendError(response, err)
+		return
+	}
+	response.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(response).Encode(vse.Liveness())
+}
+
+// claimCopyProgress returns true if no other active
+// stream saves its copy progress under key.
+func (vse *Engine) claimCopyProgress(key string) bool {
+	vse.mu.Lock()
+	defer vse.mu.Unlock()
+	if vse.copyProgressKeys[key] {
+		return false
+	}
+	vse.copyProgressKeys[key] = true
+	return true
+}
+
+func (vse *Engine) releaseCopyProgress(key string) {
+	vse.mu.Lock()
+	defer vse.mu.Unlock()
+	delete(vse.copyProgressKeys, key)
+}
+
+// heartbeatTime returns the interval after which an idle
+// vstream sends a heartbeat.
+func (vse *Engine) heartbeatTime() time.Duration {
+	if d := vse.env.Config().VStreamHeartbeatIntervalSeconds.Get(); d != 0 {
+		return d
+	}
+	return HeartbeatTime
+}
+
 func (vse *Engine) vschema() *vindexes.VSchema {
 	vse.mu.Lock()
 	defer vse.mu.Unlock()
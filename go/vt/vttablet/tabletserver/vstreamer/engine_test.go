@@ -21,8 +21,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
 
+	"vitess.io/vitess/go/vt/dbconfigs"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 )
 
@@ -149,6 +154,56 @@ func TestUpdateVSchema(t *testing.T) {
 	}
 }
 
+func TestEngineLiveness(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	config := env.TabletEnv.Config().Clone()
+	config.VStreamHeartbeatIntervalSeconds = 0.01
+	vse := NewEngine(tabletenv.NewEnv(config, "VStreamerTest"), env.SrvTopo, env.SchemaEngine, env.Cells[0])
+	assert.Equal(t, 10*time.Millisecond, vse.heartbeatTime())
+
+	sent := make(chan []*binlogdatapb.VEvent, 10)
+	send := func(evs []*binlogdatapb.VEvent) error {
+		sent <- evs
+		return nil
+	}
+	fresh := newUVStreamer(context.Background(), vse, dbconfigs.Connector{}, nil, "", nil, nil, nil, send)
+	fresh.setPhase("replicate")
+	stale := newUVStreamer(context.Background(), vse, dbconfigs.Connector{}, nil, "", nil, nil, nil, send)
+	stale.setPhase("copy")
+	stale.lastSent.Set(time.Now().Add(-time.Minute).UnixNano())
+	vse.mu.Lock()
+	vse.streamers[1] = fresh
+	vse.streamers[2] = stale
+	vse.mu.Unlock()
+
+	liveness := vse.Liveness()
+	require.Len(t, liveness, 2)
+	assert.Equal(t, 2, liveness[0].ID)
+	assert.Equal(t, "copy", liveness[0].Phase)
+	assert.True(t, liveness[0].SecondsSinceLastEvent >= 60, "%v", liveness[0])
+	assert.Equal(t, 1, liveness[1].ID)
+	assert.True(t, liveness[1].SecondsSinceLastEvent < 60, "%v", liveness[1])
+	assert.True(t, vse.maxSecondsSinceLastEvent() >= 60)
+	assert.Equal(t, 2, vse.ActiveStreams())
+
+	// An idle copy sends heartbeats.
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		stale.sendCopyHeartbeats(ctx)
+		close(done)
+	}()
+	evs := <-sent
+	cancel()
+	<-done
+	require.Len(t, evs, 1)
+	assert.Equal(t, binlogdatapb.VEventType_HEARTBEAT, evs[0].Type)
+	assert.True(t, vse.Liveness()[0].SecondsSinceLastEvent < 60)
+}
+
 func expectUpdateCount(t *testing.T, wantCount int64) int64 {
 	for i := 0; i < 10; i++ {
 		gotCount := engine.vschemaUpdates.Get()
@@ -19,6 +19,8 @@ package vstreamer
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"time"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/dbconfigs"
@@ -65,6 +67,11 @@ type rowStreamer struct {
 	pkColumns []int
 	sendQuery string
 	vse       *Engine
+
+	// startTime and rowsRead are used to enforce the copy
+	// rate limit when the lag throttler isn't open.
+	startTime time.Time
+	rowsRead  int64
 }
 
 func newRowStreamer(ctx context.Context, cp dbconfigs.Connector, se *schema.Engine, query string, lastpk []sqltypes.Value, vschema *localVSchema, send func(*binlogdatapb.VStreamRowsResponse) error, vse *Engine) *rowStreamer {
@@ -90,7 +97,7 @@ func (rs *rowStreamer) Cancel() {
 func (rs *rowStreamer) Stream() error {
 	// Ensure sh is Open. If vttablet came up in a non_serving role,
 	// the schema engine may not have been initialized.
-	if err := rs.se.Open(); err != nil {
+	if err := rs.se.Open(rs.ctx); err != nil {
 		return err
 	}
 
@@ -237,6 +244,8 @@ func (rs *rowStreamer) streamQuery(conn *snapshotConn, send func(*binlogdatapb.V
 	response := &binlogdatapb.VStreamRowsResponse{}
 	lastpk := make([]sqltypes.Value, len(rs.pkColumns))
 	byteCount := 0
+	rowCount := 0
+	rs.startTime = time.Now()
 	for {
 		//log.Infof("StreamResponse for loop iteration starts")
 		select {
@@ -258,6 +267,7 @@ func (rs *rowStreamer) streamQuery(conn *snapshotConn, send func(*binlogdatapb.V
 		for i, pk := range rs.pkColumns {
 			lastpk[i] = row[pk]
 		}
+		rowCount++
 		// Reuse the vstreamer's filter.
 		ok, filtered, err := rs.plan.filter(row)
 		if err != nil {
@@ -284,6 +294,11 @@ func (rs *rowStreamer) streamQuery(conn *snapshotConn, send func(*binlogdatapb.V
 			// same capacity
 			response.Rows = nil
 			byteCount = 0
+
+			if err := rs.throttle(rowCount); err != nil {
+				return err
+			}
+			rowCount = 0
 		}
 	}
 
@@ -298,3 +313,51 @@ func (rs *rowStreamer) streamQuery(conn *snapshotConn, send func(*binlogdatapb.V
 
 	return nil
 }
+
+// throttle waits until the copy can read more rows. If the lag
+// throttler is open, it waits until the throttler lets the
+// vstreamer-copy app proceed. Otherwise, it waits as long as needed
+// to keep the rows read under -vstream_copy_max_row_rate.
+// rows is the number of rows read since the previous call.
+func (rs *rowStreamer) throttle(rows int) error {
+	rs.rowsRead += int64(rows)
+	start := time.Now()
+	waited := false
+	defer func() {
+		if waited {
+			rs.vse.copyThrottleWaits.Record(rs.plan.Table.Name, start)
+		}
+	}()
+
+	if throttler := rs.vse.lagThrottler(); throttler != nil {
+		for {
+			checkResult := throttler.CheckByApp(rs.ctx, copyThrottlerAppName)
+			if checkResult.StatusCode == http.StatusOK {
+				return nil
+			}
+			waited = true
+			select {
+			case <-rs.ctx.Done():
+				return fmt.Errorf("stream ended while throttled: %v", rs.ctx.Err())
+			case <-time.After(throttleCheckInterval):
+			}
+		}
+	}
+
+	maxRate := rs.vse.env.Config().VStreamCopyMaxRowRate
+	if maxRate <= 0 {
+		return nil
+	}
+	due := rs.startTime.Add(time.Duration(float64(rs.rowsRead) / maxRate * float64(time.Second)))
+	wait := time.Until(due)
+	if wait <= 0 {
+		return nil
+	}
+	waited = true
+	select {
+	case <-rs.ctx.Done():
+		return fmt.Errorf("stream ended while throttled: %v", rs.ctx.Err())
+	case <-time.After(wait):
+	}
+	return nil
+}
@@ -19,15 +19,19 @@ package vstreamer
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"regexp"
+	"sync"
 	"testing"
 	"time"
 
 	"vitess.io/vitess/go/vt/log"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
 
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 )
@@ -385,6 +389,106 @@ func TestStreamRowsCancel(t *testing.T) {
 	}
 }
 
+type fakeThrottler struct {
+	mu        sync.Mutex
+	throttled int
+	checks    int
+}
+
+func (ft *fakeThrottler) IsOpen() bool {
+	return true
+}
+
+func (ft *fakeThrottler) CheckByApp(ctx context.Context, appName string) *throttle.CheckResult {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	ft.checks++
+	if appName == copyThrottlerAppName && ft.throttled > 0 {
+		ft.throttled--
+		return &throttle.CheckResult{StatusCode: http.StatusTooManyRequests}
+	}
+	return &throttle.CheckResult{StatusCode: http.StatusOK}
+}
+
+func TestStreamRowsThrottled(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	savedSize := *PacketSize
+	*PacketSize = 10
+	defer func() { *PacketSize = savedSize }()
+	savedInterval := throttleCheckInterval
+	throttleCheckInterval = 10 * time.Millisecond
+	defer func() { throttleCheckInterval = savedInterval }()
+
+	execStatements(t, []string{
+		"create table t1(id int, val varbinary(128), primary key(id))",
+		"insert into t1 values (1, '234567890'), (2, '234567890')",
+	})
+
+	defer execStatements(t, []string{
+		"drop table t1",
+	})
+	engine.se.Reload(context.Background())
+
+	ft := &fakeThrottler{throttled: 2}
+	engine.SetThrottler(ft)
+	defer engine.SetThrottler(nil)
+	engine.copyThrottleWaits.Reset()
+
+	wantStream := []string{
+		`fields:<name:"id" type:INT32 table:"t1" org_table:"t1" database:"vttest" org_name:"id" column_length:11 charset:63 > fields:<name:"val" type:VARBINARY table:"t1" org_table:"t1" database:"vttest" org_name:"val" column_length:128 charset:63 > pkfields:<name:"id" type:INT32 > `,
+		`rows:<lengths:1 lengths:9 values:"1234567890" > lastpk:<lengths:1 values:"1" > `,
+		`rows:<lengths:1 lengths:9 values:"2234567890" > lastpk:<lengths:1 values:"2" > `,
+	}
+	wantQuery := "select id, val from t1 order by id"
+	checkStream(t, "select * from t1", nil, wantQuery, wantStream)
+
+	// The first packet was throttled twice. The second one wasn't.
+	assert.Equal(t, 4, ft.checks)
+	assert.Equal(t, int64(1), engine.copyThrottleWaits.Counts()["t1"])
+}
+
+func TestStreamRowsCopyRateLimit(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	savedSize := *PacketSize
+	*PacketSize = 10
+	defer func() { *PacketSize = savedSize }()
+	config := engine.env.Config()
+	config.VStreamCopyMaxRowRate = 20
+	defer func() { config.VStreamCopyMaxRowRate = 0 }()
+
+	execStatements(t, []string{
+		"create table t1(id int, val varbinary(128), primary key(id))",
+		"insert into t1 values (1, '234567890'), (2, '234567890'), (3, '234567890')",
+	})
+
+	defer execStatements(t, []string{
+		"drop table t1",
+	})
+	engine.se.Reload(context.Background())
+	engine.copyThrottleWaits.Reset()
+
+	// Without an open lag throttler, every row sent takes 50ms
+	// of the rate limit.
+	start := time.Now()
+	wantStream := []string{
+		`fields:<name:"id" type:INT32 table:"t1" org_table:"t1" database:"vttest" org_name:"id" column_length:11 charset:63 > fields:<name:"val" type:VARBINARY table:"t1" org_table:"t1" database:"vttest" org_name:"val" column_length:128 charset:63 > pkfields:<name:"id" type:INT32 > `,
+		`rows:<lengths:1 lengths:9 values:"1234567890" > lastpk:<lengths:1 values:"1" > `,
+		`rows:<lengths:1 lengths:9 values:"2234567890" > lastpk:<lengths:1 values:"2" > `,
+		`rows:<lengths:1 lengths:9 values:"3234567890" > lastpk:<lengths:1 values:"3" > `,
+	}
+	wantQuery := "select id, val from t1 order by id"
+	checkStream(t, "select * from t1", nil, wantQuery, wantStream)
+
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(150*time.Millisecond))
+	assert.NotZero(t, engine.copyThrottleWaits.Counts()["t1"])
+}
+
 func checkStream(t *testing.T, query string, lastpk []sqltypes.Value, wantQuery string, wantStream []string) {
 	t.Helper()
 
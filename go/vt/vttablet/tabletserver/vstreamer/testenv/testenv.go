@@ -101,7 +101,7 @@ func Init() (*Env, error) {
 	te.Mysqld = mysqlctl.NewMysqld(te.Dbcfgs)
 	te.SchemaEngine = schema.NewEngine(te.TabletEnv)
 	te.SchemaEngine.InitDBConfig(te.Dbcfgs.DbaWithDB())
-	if err := te.SchemaEngine.Open(); err != nil {
+	if err := te.SchemaEngine.Open(ctx); err != nil {
 		return nil, err
 	}
 
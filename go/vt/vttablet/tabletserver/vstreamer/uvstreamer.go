@@ -27,6 +27,7 @@ import (
 	"time"
 
 	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sync2"
 	"vitess.io/vitess/go/vt/dbconfigs"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/log"
@@ -39,6 +40,10 @@ import (
 
 var uvstreamerTestMode = false // Only used for testing
 
+// copyProgressFlushTimeout bounds the time a stream that ends
+// takes to save its copy progress.
+var copyProgressFlushTimeout = 10 * time.Second
+
 type tablePlan struct {
 	tablePK *binlogdatapb.TableLastPK
 	rule    *binlogdatapb.Rule
@@ -81,6 +86,23 @@ type uvstreamer struct {
 	config *uvstreamerConfig
 
 	vs *vstreamer //last vstreamer created in uvstreamer
+
+	// startTime is when the stream started. phase is "copy" while
+	// tables are copied, and "replicate" after. It's protected by mu.
+	// lastSent is the time in ns at which events were last sent, or
+	// startTime if none were. sendMu serializes the sends of the
+	// copy phase heartbeats with the others.
+	startTime time.Time
+	phase     string
+	lastSent  sync2.AtomicInt64
+	sendMu    sync.Mutex
+
+	// progress saves the progress of the copy phase. It's nil if
+	// -vstream_copy_progress_interval is 0, or if another active
+	// stream with the same filter saves it. copied lists the tables
+	// that a resumed stream had already copied.
+	progress *copyProgress
+	copied   map[string]bool
 }
 
 type uvstreamerConfig struct {
@@ -94,15 +116,10 @@ func newUVStreamer(ctx context.Context, vse *Engine, cp dbconfigs.Connector, se
 		MaxReplicationLag: 1 * time.Nanosecond,
 		CatchupRetryTime:  1 * time.Second,
 	}
-	send2 := func(evs []*binlogdatapb.VEvent) error {
-		vse.vstreamerEventsStreamed.Add(int64(len(evs)))
-		return send(evs)
-	}
 	uvs := &uvstreamer{
 		ctx:        ctx,
 		cancel:     cancel,
 		vse:        vse,
-		send:       send2,
 		cp:         cp,
 		se:         se,
 		startPos:   startPos,
@@ -110,6 +127,16 @@ func newUVStreamer(ctx context.Context, vse *Engine, cp dbconfigs.Connector, se
 		vschema:    vschema,
 		config:     config,
 		inTablePKs: tablePKs,
+		startTime:  time.Now(),
+	}
+	uvs.lastSent.Set(uvs.startTime.UnixNano())
+	uvs.send = func(evs []*binlogdatapb.VEvent) error {
+		vse.vstreamerEventsStreamed.Add(int64(len(evs)))
+		uvs.sendMu.Lock()
+		defer uvs.sendMu.Unlock()
+		err := send(evs)
+		uvs.lastSent.Set(time.Now().UnixNano())
+		return err
 	}
 
 	return uvs
@@ -138,6 +165,9 @@ func (uvs *uvstreamer) buildTablePlan() error {
 		}
 	}
 	for tableName := range tables {
+		if uvs.copied[tableName] {
+			continue
+		}
 		rule, err := matchTable(tableName, uvs.filter, tables)
 		if err != nil {
 			return err
@@ -340,6 +370,15 @@ func (uvs *uvstreamer) init() error {
 		if err := uvs.setStreamStartPosition(); err != nil {
 			return err
 		}
+		resumed, err := uvs.resumeCopy()
+		if err != nil {
+			return err
+		}
+		if resumed {
+			if err := uvs.buildTablePlan(); err != nil {
+				return err
+			}
+		}
 	} else if uvs.startPos == "" || len(uvs.inTablePKs) > 0 {
 		if err := uvs.buildTablePlan(); err != nil {
 			return err
@@ -352,26 +391,156 @@ func (uvs *uvstreamer) init() error {
 	return nil
 }
 
+// resumeCopy loads the copy progress saved by a previous stream with
+// the same filter, if it was saved at the start position. It returns
+// true if there is one: the stream then resumes its copy.
+func (uvs *uvstreamer) resumeCopy() (bool, error) {
+	if uvs.progress == nil || uvs.startPos == "current" || len(uvs.inTablePKs) != 0 {
+		return false, nil
+	}
+	ok, err := uvs.progress.load(uvs.ctx, mysql.EncodePosition(uvs.pos))
+	if err != nil || !ok {
+		return false, err
+	}
+	log.Infof("Resuming the copy of stream %s at %v, tables copied: %v", uvs.progress.key, uvs.pos, uvs.progress.completed)
+	uvs.inTablePKs = uvs.progress.tablePKs()
+	uvs.copied = uvs.progress.completed
+	return true, nil
+}
+
+// updateCopyProgress records that the rows of tableName were
+// sent up to lastpk, or all of them if lastpk is nil. Failures
+// to save the progress are logged: they don't fail the stream.
+func (uvs *uvstreamer) updateCopyProgress(tableName string, lastpk *querypb.QueryResult) {
+	if uvs.progress == nil {
+		return
+	}
+	var err error
+	if lastpk == nil {
+		err = uvs.progress.complete(uvs.ctx, mysql.EncodePosition(uvs.pos), tableName)
+	} else {
+		err = uvs.progress.update(uvs.ctx, mysql.EncodePosition(uvs.pos), tableName, lastpk)
+	}
+	if err != nil {
+		log.Warningf("Could not save the copy progress of stream %s: %v", uvs.progress.key, err)
+	}
+}
+
+// flushCopyProgress saves the copy progress of a stream that ends
+// before its copy completes. The stream context may be done already.
+func (uvs *uvstreamer) flushCopyProgress() {
+	if uvs.progress == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), copyProgressFlushTimeout)
+	defer cancel()
+	uvs.progress.setPos(mysql.EncodePosition(uvs.pos))
+	if err := uvs.progress.flush(ctx); err != nil {
+		log.Warningf("Could not save the copy progress of stream %s: %v", uvs.progress.key, err)
+	}
+}
+
+// clearCopyProgress deletes the saved progress once the copy completes.
+func (uvs *uvstreamer) clearCopyProgress() {
+	if uvs.progress == nil {
+		return
+	}
+	// The consumer may end the stream as soon as the copy completes.
+	ctx, cancel := context.WithTimeout(context.Background(), copyProgressFlushTimeout)
+	defer cancel()
+	if err := uvs.progress.clear(ctx); err != nil {
+		log.Warningf("Could not delete the copy progress of stream %s: %v", uvs.progress.key, err)
+	}
+}
+
 // Stream streams binlog events.
 func (uvs *uvstreamer) Stream() error {
 	log.Info("Stream() called")
+	if interval := uvs.vse.env.Config().VStreamCopyProgressIntervalSeconds.Get(); interval != 0 {
+		key := copyProgressKey(uvs.filter)
+		if uvs.vse.claimCopyProgress(key) {
+			defer uvs.vse.releaseCopyProgress(key)
+			uvs.progress = newCopyProgress(uvs.vse.env.Config().DB.DbaWithDB(), key, interval)
+		}
+	}
 	if err := uvs.init(); err != nil {
 		return err
 	}
-	if len(uvs.plans) > 0 {
+	if len(uvs.plans) > 0 || uvs.copied != nil {
 		log.Info("TablePKs is not nil: starting vs.copy()")
-		if err := uvs.copy(uvs.ctx); err != nil {
+		uvs.setPhase("copy")
+		ctx, cancel := context.WithCancel(uvs.ctx)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			uvs.sendCopyHeartbeats(ctx)
+		}()
+		err := uvs.copy(uvs.ctx)
+		cancel()
+		wg.Wait()
+		if err != nil {
 			log.Infof("uvstreamer.Stream() copy returned with err %s", err)
+			uvs.flushCopyProgress()
 			return err
 		}
+		uvs.clearCopyProgress()
 		uvs.sendTestEvent("Copy Done")
 	}
+	uvs.setPhase("replicate")
 	vs := newVStreamer(uvs.ctx, uvs.cp, uvs.se, mysql.EncodePosition(uvs.pos), mysql.EncodePosition(uvs.stopPos), uvs.filter, uvs.getVSchema(), uvs.send, "replicate", uvs.vse)
 
 	uvs.setVs(vs)
 	return vs.Stream()
 }
 
+// sendCopyHeartbeats sends a heartbeat whenever nothing was sent for
+// the heartbeat interval, until ctx is done. The copy phase needs it
+// because the heartbeats of its catchups are filtered out, and a large
+// table can take a while to return its first rows.
+func (uvs *uvstreamer) sendCopyHeartbeats(ctx context.Context) {
+	interval := uvs.vse.heartbeatTime()
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if time.Since(time.Unix(0, uvs.lastSent.Get())) < interval {
+			continue
+		}
+		now := time.Now().UnixNano()
+		if err := uvs.send([]*binlogdatapb.VEvent{{
+			Type:        binlogdatapb.VEventType_HEARTBEAT,
+			Timestamp:   now / 1e9,
+			CurrentTime: now,
+		}}); err != nil {
+			// The copy sees the same error on its next send.
+			return
+		}
+	}
+}
+
+func (uvs *uvstreamer) setPhase(phase string) {
+	uvs.lock("setPhase")
+	defer uvs.unlock("setPhase")
+	uvs.phase = phase
+}
+
+// liveness returns the liveness of the stream.
+func (uvs *uvstreamer) liveness(id int, now time.Time) StreamLiveness {
+	uvs.lock("liveness")
+	defer uvs.unlock("liveness")
+	return StreamLiveness{
+		ID:                    id,
+		Phase:                 uvs.phase,
+		StartTime:             uvs.startTime,
+		SecondsSinceLastEvent: now.Sub(time.Unix(0, uvs.lastSent.Get())).Seconds(),
+	}
+}
+
 func (uvs *uvstreamer) lock(msg string) {
 	uvs.mu.Lock()
 }
@@ -404,6 +573,7 @@ func (uvs *uvstreamer) getVSchema() *localVSchema {
 
 func (uvs *uvstreamer) setCopyState(tableName string, qr *querypb.QueryResult) {
 	uvs.plans[tableName].tablePK.Lastpk = qr
+	uvs.updateCopyProgress(tableName, qr)
 }
 
 // dummy event sent only in test mode
@@ -442,6 +612,7 @@ func (uvs *uvstreamer) copyComplete(tableName string) error {
 
 	delete(uvs.plans, tableName)
 	uvs.tablesToCopy = uvs.tablesToCopy[1:]
+	uvs.updateCopyProgress(tableName, nil)
 	return nil
 }
 
@@ -104,6 +104,8 @@ type streamerPlan struct {
 //   "select * from t where in_keyrange(col1, 'hash', '-80')",
 //   "select col1, col2 from t where...",
 //   "select col1, keyspace_id() from t where...".
+//   A select list acts as a column projection: only the listed columns are sent,
+//   and a DDL that drops one of them fails the stream.
 //   Only "in_keyrange" expressions are supported in the where clause.
 //   Other constructs like joins, group by, etc. are not supported.
 // vschema: the current vschema. This value can later be changed through the SetVSchema method.
@@ -160,7 +162,7 @@ func (vs *vstreamer) Stream() error {
 func (vs *vstreamer) replicate(ctx context.Context) error {
 	// Ensure se is Open. If vttablet came up in a non_serving role,
 	// the schema engine may not have been initialized.
-	if err := vs.se.Open(); err != nil {
+	if err := vs.se.Open(ctx); err != nil {
 		return wrapError(err, vs.pos)
 	}
 
@@ -258,10 +260,11 @@ func (vs *vstreamer) parseEvents(ctx context.Context, events <-chan mysql.Binlog
 	}
 
 	// Main loop: calls bufferAndTransmit as events arrive.
-	timer := time.NewTimer(HeartbeatTime)
+	heartbeatTime := vs.vse.heartbeatTime()
+	timer := time.NewTimer(heartbeatTime)
 	defer timer.Stop()
 	for {
-		timer.Reset(HeartbeatTime)
+		timer.Reset(heartbeatTime)
 		// Drain event if timer fired before reset.
 		select {
 		case <-timer.C:
@@ -437,6 +440,9 @@ func (vs *vstreamer) parseEvent(ev mysql.BinlogEvent) ([]*binlogdatapb.VEvent, e
 				// TODO(sougou): move this back to always load after
 				// the schema reload bug is fixed.
 				vs.se.ReloadAt(context.Background(), vs.pos)
+				if err := vs.checkProjections(); err != nil {
+					return nil, err
+				}
 			} else {
 				// If the DDL need not be sent, send a dummy OTHER event.
 				vevents = append(vevents, &binlogdatapb.VEvent{
@@ -781,6 +787,36 @@ func (vs *vstreamer) rebuildPlans() error {
 	return nil
 }
 
+// checkProjections verifies that the tables streamed so far still have
+// every column their plans project. It's called after a DDL is applied.
+// If a projected column was dropped, the stream fails instead of sending
+// rows that silently omit it.
+func (vs *vstreamer) checkProjections() error {
+	checked := make(map[string]bool)
+	for id, plan := range vs.plans {
+		if plan == nil || id == vs.journalTableID || id == vs.versionTableID {
+			continue
+		}
+		if checked[plan.Table.Name] {
+			continue
+		}
+		checked[plan.Table.Name] = true
+		st, err := vs.se.GetTableForPos(sqlparser.NewTableIdent(plan.Table.Name), mysql.EncodePosition(vs.pos))
+		if err != nil {
+			// The table was dropped or renamed. Its plan won't be used again.
+			continue
+		}
+		table := &Table{
+			Name:   plan.Table.Name,
+			Fields: st.Fields,
+		}
+		if _, err := buildPlan(table, vs.vschema, vs.filter); err != nil {
+			return fmt.Errorf("schema change on table %s is incompatible with the stream's column projection: %v", plan.Table.Name, err)
+		}
+	}
+	return nil
+}
+
 func (vs *vstreamer) extractRowAndFilter(plan *streamerPlan, data []byte, dataColumns, nullColumns mysql.Bitmap) (bool, []sqltypes.Value, error) {
 	if len(data) == 0 {
 		return false, nil, nil
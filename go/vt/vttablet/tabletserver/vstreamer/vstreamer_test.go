@@ -17,7 +17,6 @@ limitations under the License.
 package vstreamer
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -27,7 +26,6 @@ import (
 	"testing"
 	"time"
 
-	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/sqlparser"
 
@@ -36,6 +34,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"vitess.io/vitess/go/mysql"
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
 )
 
 type testcase struct {
@@ -313,6 +312,104 @@ func TestVStreamCopySimpleFlow(t *testing.T) {
 	log.Infof("Pos at end of test: %s", masterPosition(t))
 }
 
+func TestVStreamCopyResume(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	execStatements(t, []string{
+		"create table t1(id11 int, id12 int, primary key(id11))",
+		"create table t2(id21 int, id22 int, primary key(id21))",
+	})
+	insertLotsOfData(t, 10)
+	defer execStatements(t, []string{
+		"drop table t1",
+		"drop table t2",
+		"drop table if exists _vt.vstream_copy_state",
+	})
+	engine.se.Reload(context.Background())
+
+	// Every packet has a few rows, and every one of them is saved.
+	savedSize := *PacketSize
+	*PacketSize = 10
+	defer func() { *PacketSize = savedSize }()
+	config := engine.env.Config()
+	config.VStreamCopyProgressIntervalSeconds.Set(time.Nanosecond)
+	defer func() { config.VStreamCopyProgressIntervalSeconds = 0 }()
+
+	filter := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match:  "t1",
+			Filter: "select * from t1",
+		}, {
+			Match:  "t2",
+			Filter: "select * from t2",
+		}},
+	}
+
+	// copied lists the rows received, by table.
+	copied := make(map[string][]string)
+	// stream runs a stream until stop returns true for an event.
+	stream := func(startPos string, stop func(*binlogdatapb.VEvent) bool) (pos string) {
+		t.Helper()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		err := engine.Stream(ctx, startPos, nil, filter, func(evs []*binlogdatapb.VEvent) error {
+			if ctx.Err() != nil {
+				return io.EOF
+			}
+			for _, ev := range evs {
+				switch ev.Type {
+				case binlogdatapb.VEventType_GTID:
+					pos = ev.Gtid
+				case binlogdatapb.VEventType_ROW:
+					for _, change := range ev.RowEvent.RowChanges {
+						copied[ev.RowEvent.TableName] = append(copied[ev.RowEvent.TableName], fmt.Sprintf("%v", change.After))
+					}
+				}
+				if stop(ev) {
+					cancel()
+				}
+			}
+			return nil
+		})
+		if err != nil && !strings.Contains(err.Error(), "context canceled") && err != io.EOF {
+			t.Fatal(err)
+		}
+		return pos
+	}
+
+	// Kill the stream once t2 started to be copied.
+	pos := stream("", func(ev *binlogdatapb.VEvent) bool {
+		return ev.Type == binlogdatapb.VEventType_ROW && ev.RowEvent.TableName == "t2"
+	})
+	require.Len(t, copied["t1"], 10)
+	sent := len(copied["t2"])
+	require.True(t, sent > 0 && sent < 10, "t2 rows sent before the stream was killed: %d", sent)
+
+	qr, err := env.Mysqld.FetchSuperQuery(context.Background(), "select table_name, completed from _vt.vstream_copy_state order by table_name")
+	require.NoError(t, err)
+	assert.Equal(t, `[[VARBINARY("t1") INT8(1)] [VARBINARY("t2") INT8(0)]]`, fmt.Sprintf("%v", qr.Rows))
+
+	// The resumed stream copies the rest of t2 only.
+	stream(pos, func(ev *binlogdatapb.VEvent) bool {
+		return ev.Type == binlogdatapb.VEventType_LASTPK && ev.LastPKEvent.Completed
+	})
+	assert.Len(t, copied["t1"], 10)
+	require.Len(t, copied["t2"], 10)
+	for i, row := range copied["t2"] {
+		want := fmt.Sprintf("%v", &querypb.Row{
+			Lengths: []int64{int64(len(strconv.Itoa(i + 1))), int64(len(strconv.Itoa((i + 1) * 20)))},
+			Values:  []byte(fmt.Sprintf("%d%d", i+1, (i+1)*20)),
+		})
+		assert.Equal(t, want, row, "row %d", i)
+	}
+
+	// The progress is deleted once the copy completes.
+	qr, err = env.Mysqld.FetchSuperQuery(context.Background(), "select count(*) from _vt.vstream_copy_state")
+	require.NoError(t, err)
+	assert.Equal(t, "[[INT64(0)]]", fmt.Sprintf("%v", qr.Rows))
+}
+
 func TestVStreamCopyWithDifferentFilters(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
@@ -1124,6 +1221,113 @@ func TestDDLDropColumn(t *testing.T) {
 	}
 }
 
+func TestColumnProjection(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	execStatements(t, []string{
+		"create table t1(id int, name varbinary(128), ssn varbinary(128), primary key(id))",
+	})
+	defer execStatements(t, []string{
+		"drop table t1",
+	})
+	engine.se.Reload(context.Background())
+
+	filter := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match:  "t1",
+			Filter: "select id, name from t1",
+		}},
+	}
+
+	testcases := []testcase{{
+		input: []string{
+			"begin",
+			"insert into t1 values (1, 'aaa', '111')",
+			"update t1 set name = 'bbb' where id = 1",
+			// Changes to columns outside the projection still generate
+			// a row event, but with identical before and after images.
+			"update t1 set ssn = '222' where id = 1",
+			"delete from t1 where id = 1",
+			"commit",
+		},
+		output: [][]string{{
+			`begin`,
+			`type:FIELD field_event:<table_name:"t1" fields:<name:"id" type:INT32 table:"t1" org_table:"t1" database:"vttest" org_name:"id" column_length:11 charset:63 > fields:<name:"name" type:VARBINARY table:"t1" org_table:"t1" database:"vttest" org_name:"name" column_length:128 charset:63 > > `,
+			`type:ROW row_event:<table_name:"t1" row_changes:<after:<lengths:1 lengths:3 values:"1aaa" > > > `,
+			`type:ROW row_event:<table_name:"t1" row_changes:<before:<lengths:1 lengths:3 values:"1aaa" > after:<lengths:1 lengths:3 values:"1bbb" > > > `,
+			`type:ROW row_event:<table_name:"t1" row_changes:<before:<lengths:1 lengths:3 values:"1bbb" > after:<lengths:1 lengths:3 values:"1bbb" > > > `,
+			`type:ROW row_event:<table_name:"t1" row_changes:<before:<lengths:1 lengths:3 values:"1bbb" > > > `,
+			`gtid`,
+			`commit`,
+		}},
+	}}
+	runCases(t, filter, testcases, "", nil)
+}
+
+func TestDDLDropProjectedColumn(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	execStatement(t, "create table ddl_test3(id int, name varbinary(128), ssn varbinary(128), primary key(id))")
+	defer execStatement(t, "drop table ddl_test3")
+	engine.se.Reload(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	filter := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match:  "ddl_test3",
+			Filter: "select id, name from ddl_test3",
+		}},
+	}
+
+	pos := masterPosition(t)
+	ch := make(chan []*binlogdatapb.VEvent)
+	errch := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		errch <- vstream(ctx, t, pos, nil, filter, ch)
+	}()
+
+	execStatement(t, "insert into ddl_test3 values(1, 'aaa', '111')")
+	expectLog(ctx, t, "insert", ch, [][]string{{
+		`begin`,
+		`type:FIELD field_event:<table_name:"ddl_test3" fields:<name:"id" type:INT32 table:"ddl_test3" org_table:"ddl_test3" database:"vttest" org_name:"id" column_length:11 charset:63 > fields:<name:"name" type:VARBINARY table:"ddl_test3" org_table:"ddl_test3" database:"vttest" org_name:"name" column_length:128 charset:63 > > `,
+		`type:ROW row_event:<table_name:"ddl_test3" row_changes:<after:<lengths:1 lengths:3 values:"1aaa" > > > `,
+		`gtid`,
+		`commit`,
+	}})
+
+	// Dropping a column outside the projection is allowed.
+	execStatement(t, "alter table ddl_test3 drop column ssn")
+	expectLog(ctx, t, "drop unprojected column", ch, [][]string{{
+		`gtid`,
+		`type:DDL statement:"alter table ddl_test3 drop column ssn" `,
+	}})
+	execStatement(t, "insert into ddl_test3 values(2, 'bbb')")
+	expectLog(ctx, t, "insert after drop", ch, [][]string{{
+		`begin`,
+		`type:FIELD field_event:<table_name:"ddl_test3" fields:<name:"id" type:INT32 table:"ddl_test3" org_table:"ddl_test3" database:"vttest" org_name:"id" column_length:11 charset:63 > fields:<name:"name" type:VARBINARY table:"ddl_test3" org_table:"ddl_test3" database:"vttest" org_name:"name" column_length:128 charset:63 > > `,
+		`type:ROW row_event:<table_name:"ddl_test3" row_changes:<after:<lengths:1 lengths:3 values:"2bbb" > > > `,
+		`gtid`,
+		`commit`,
+	}})
+
+	// Dropping a projected column fails the stream.
+	execStatement(t, "alter table ddl_test3 drop column name")
+	for range ch {
+	}
+	err := <-errch
+	want := "schema change on table ddl_test3 is incompatible with the stream's column projection: column name not found in table ddl_test3"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("err: %v, must contain %s", err, want)
+	}
+}
+
 func TestUnsentDDL(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
@@ -1876,9 +2080,3 @@ func setVSchema(t *testing.T, vschema string) {
 		t.Error("vschema did not get updated")
 	}
 }
-
-func encodeString(in string) string {
-	buf := bytes.NewBuffer(nil)
-	sqltypes.NewVarChar(in).EncodeSQL(buf)
-	return buf.String()
-}
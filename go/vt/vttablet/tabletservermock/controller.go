@@ -29,6 +29,7 @@ import (
 	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/vttablet/queryservice"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/rules"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
@@ -76,9 +77,15 @@ type Controller struct {
 	// SetServingTypeError is the return value for SetServingType.
 	SetServingTypeError error
 
+	// CanTransitionError is the return value for CanTransition.
+	CanTransitionError error
+
 	// TS is the return value for TopoServer.
 	TS *topo.Server
 
+	// TopoIsolationTimeoutValue is the return value for TopoIsolationTimeout.
+	TopoIsolationTimeoutValue time.Duration
+
 	// mu protects the next fields in this structure. They are
 	// accessed by both the methods in this interface, and the
 	// background health check.
@@ -92,6 +99,13 @@ type Controller struct {
 
 	// queryRulesMap has the latest query rules.
 	queryRulesMap map[string]*rules.Rules
+
+	// topoLastSeen is the last value passed to SetTopoLastSeenHealthy.
+	topoLastSeen time.Time
+
+	// transitionErrs are the results of the SetServingTypeAsync
+	// calls. The token of a call is its index plus one.
+	transitionErrs []error
 }
 
 // NewController returns a mock of tabletserver.Controller
@@ -131,8 +145,13 @@ func (tqsc *Controller) InitDBConfig(target querypb.Target, dbcfgs *dbconfigs.DB
 	return nil
 }
 
+// RestoredServingType is part of the tabletserver.Controller interface
+func (tqsc *Controller) RestoredServingType() (topodatapb.TabletType, time.Time, bool, string, bool) {
+	return topodatapb.TabletType_UNKNOWN, time.Time{}, false, "", false
+}
+
 // SetServingType is part of the tabletserver.Controller interface
-func (tqsc *Controller) SetServingType(tabletType topodatapb.TabletType, terTime time.Time, serving bool, reason string) error {
+func (tqsc *Controller) SetServingType(ctx context.Context, tabletType topodatapb.TabletType, terTime time.Time, serving bool, reason string) error {
 	tqsc.mu.Lock()
 	defer tqsc.mu.Unlock()
 
@@ -148,6 +167,35 @@ func (tqsc *Controller) SetServingType(tabletType topodatapb.TabletType, terTime
 	return tqsc.SetServingTypeError
 }
 
+// SetServingTypeAsync is part of the tabletserver.Controller interface.
+// It transitions synchronously, and returns a resolved handle.
+func (tqsc *Controller) SetServingTypeAsync(ctx context.Context, tabletType topodatapb.TabletType, terTime time.Time, serving bool, reason string, waitRetries bool) *tabletserver.TransitionHandle {
+	err := tqsc.SetServingType(ctx, tabletType, terTime, serving, reason)
+	tqsc.mu.Lock()
+	defer tqsc.mu.Unlock()
+	tqsc.transitionErrs = append(tqsc.transitionErrs, err)
+	return tabletserver.NewResolvedTransitionHandle(int64(len(tqsc.transitionErrs)), err)
+}
+
+// TransitionStatus is part of the tabletserver.Controller interface
+func (tqsc *Controller) TransitionStatus(token int64) (tabletserver.AsyncTransitionStatus, bool) {
+	tqsc.mu.Lock()
+	defer tqsc.mu.Unlock()
+	if token <= 0 || token > int64(len(tqsc.transitionErrs)) {
+		return tabletserver.AsyncTransitionStatus{}, false
+	}
+	status := tabletserver.AsyncTransitionStatus{Token: token, Done: true}
+	if err := tqsc.transitionErrs[token-1]; err != nil {
+		status.Error = err.Error()
+	}
+	return status, true
+}
+
+// CanTransition is part of the tabletserver.Controller interface
+func (tqsc *Controller) CanTransition(tabletType topodatapb.TabletType, serving bool) error {
+	return tqsc.CanTransitionError
+}
+
 // IsServing is part of the tabletserver.Controller interface
 func (tqsc *Controller) IsServing() bool {
 	tqsc.mu.Lock()
@@ -170,8 +218,8 @@ func (tqsc *Controller) IsHealthy() error {
 }
 
 // ReloadSchema is part of the tabletserver.Controller interface
-func (tqsc *Controller) ReloadSchema(ctx context.Context) error {
-	return nil
+func (tqsc *Controller) ReloadSchema(ctx context.Context) ([]string, error) {
+	return nil, nil
 }
 
 //ClearQueryPlanCache is part of the tabletserver.Controller interface
@@ -219,6 +267,27 @@ func (tqsc *Controller) TopoServer() *topo.Server {
 	return tqsc.TS
 }
 
+// SetTopoLastSeenHealthy is part of the tabletserver.Controller interface.
+func (tqsc *Controller) SetTopoLastSeenHealthy(lastSeen time.Time) {
+	tqsc.mu.Lock()
+	defer tqsc.mu.Unlock()
+
+	tqsc.topoLastSeen = lastSeen
+}
+
+// TopoIsolationTimeout is part of the tabletserver.Controller interface.
+func (tqsc *Controller) TopoIsolationTimeout() time.Duration {
+	return tqsc.TopoIsolationTimeoutValue
+}
+
+// TopoLastSeenHealthy allows a test to check what was set.
+func (tqsc *Controller) TopoLastSeenHealthy() time.Time {
+	tqsc.mu.Lock()
+	defer tqsc.mu.Unlock()
+
+	return tqsc.topoLastSeen
+}
+
 // EnterLameduck implements tabletserver.Controller.
 func (tqsc *Controller) EnterLameduck() {
 	tqsc.mu.Lock()
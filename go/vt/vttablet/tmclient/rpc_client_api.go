@@ -194,6 +194,11 @@ type TabletManagerClient interface {
 	// To be used if we are unable to promote the chosen new master
 	UndoDemoteMaster(ctx context.Context, tablet *topodatapb.Tablet) error
 
+	// CanTransition checks if the remote tablet could change to
+	// tabletType right now, without changing anything. It returns
+	// an error describing why it couldn't.
+	CanTransition(ctx context.Context, tablet *topodatapb.Tablet, tabletType topodatapb.TabletType) error
+
 	// Deprecated
 	SlaveWasPromoted(ctx context.Context, tablet *topodatapb.Tablet) error
 
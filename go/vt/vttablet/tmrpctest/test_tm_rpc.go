@@ -1160,6 +1160,28 @@ func tmRPCTestUndoDemoteMasterPanic(ctx context.Context, t *testing.T, client tm
 	expectHandleRPCPanic(t, "UndoDemoteMaster", true /*verbose*/, err)
 }
 
+var testCanTransitionValue = topodatapb.TabletType_MASTER
+
+func (fra *fakeRPCTM) CanTransition(ctx context.Context, tabletType topodatapb.TabletType) error {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	compare(fra.t, "CanTransition tabletType", tabletType, testCanTransitionValue)
+	return nil
+}
+
+func tmRPCTestCanTransition(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	err := client.CanTransition(ctx, tablet, testCanTransitionValue)
+	if err != nil {
+		t.Errorf("CanTransition failed: %v", err)
+	}
+}
+
+func tmRPCTestCanTransitionPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	err := client.CanTransition(ctx, tablet, testCanTransitionValue)
+	expectHandleRPCPanic(t, "CanTransition", false /*verbose*/, err)
+}
+
 var testReplicationPositionReturned = "MariaDB/5-567-3456"
 
 var testReplicaWasPromotedCalled = false
@@ -1462,6 +1484,7 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	tmRPCTestInitSlave(ctx, t, client, tablet)
 	tmRPCTestDemoteMaster(ctx, t, client, tablet)
 	tmRPCTestUndoDemoteMaster(ctx, t, client, tablet)
+	tmRPCTestCanTransition(ctx, t, client, tablet)
 	tmRPCTestSlaveWasPromoted(ctx, t, client, tablet)
 	tmRPCTestSetMaster(ctx, t, client, tablet)
 	tmRPCTestSlaveWasRestarted(ctx, t, client, tablet)
@@ -1523,6 +1546,7 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	tmRPCTestInitSlavePanic(ctx, t, client, tablet)
 	tmRPCTestDemoteMasterPanic(ctx, t, client, tablet)
 	tmRPCTestUndoDemoteMasterPanic(ctx, t, client, tablet)
+	tmRPCTestCanTransitionPanic(ctx, t, client, tablet)
 	tmRPCTestSlaveWasPromotedPanic(ctx, t, client, tablet)
 	tmRPCTestSetMasterPanic(ctx, t, client, tablet)
 	tmRPCTestSlaveWasRestartedPanic(ctx, t, client, tablet)